@@ -0,0 +1,348 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	aur "github.com/Jguer/aur"
+	alpm "github.com/Jguer/go-alpm/v2"
+	"github.com/leonelquinteros/gotext"
+
+	"github.com/Jguer/yippee/v12/pkg/db"
+	"github.com/Jguer/yippee/v12/pkg/dep"
+	"github.com/Jguer/yippee/v12/pkg/runtime"
+	"github.com/Jguer/yippee/v12/pkg/settings"
+	"github.com/Jguer/yippee/v12/pkg/text"
+)
+
+const apiShutdownTimeout = 5 * time.Second
+
+// apiPackage is a repository or AUR package as returned by /info.
+type apiPackage struct {
+	Source       string   `json:"source"`
+	Name         string   `json:"name"`
+	Base         string   `json:"base,omitempty"`
+	Version      string   `json:"version"`
+	Description  string   `json:"description"`
+	URL          string   `json:"url,omitempty"`
+	Licenses     []string `json:"licenses,omitempty"`
+	Depends      []string `json:"depends,omitempty"`
+	MakeDepends  []string `json:"makedepends,omitempty"`
+	Maintainer   string   `json:"maintainer,omitempty"`
+	Votes        int      `json:"votes,omitempty"`
+	Popularity   float64  `json:"popularity,omitempty"`
+	OutOfDate    int      `json:"outofdate,omitempty"`
+	LastModified int      `json:"lastmodified,omitempty"`
+	Installed    string   `json:"installed,omitempty"`
+}
+
+// apiUpdate is a pending upgrade as returned by /updates.
+type apiUpdate struct {
+	Source       string `json:"source"`
+	Name         string `json:"name"`
+	LocalVersion string `json:"localversion"`
+	Version      string `json:"version"`
+	Devel        bool   `json:"devel,omitempty"`
+}
+
+// apiStats is the system summary returned by /stats.
+type apiStats struct {
+	Packages         int              `json:"packages"`
+	ExplicitPackages int              `json:"explicitpackages"`
+	ForeignPackages  int              `json:"foreignpackages"`
+	Orphans          int              `json:"orphans"`
+	InstalledSize    int64            `json:"installedsize"`
+	PacmanCaches     map[string]int64 `json:"pacmancaches"`
+	YippeeCache      int64            `json:"yippeecache"`
+}
+
+// apiServer answers the read-only endpoints of --serve-api. The alpm handle
+// is not safe for concurrent use so requests are served one at a time.
+type apiServer struct {
+	run        *runtime.Runtime
+	dbExecutor db.Executor
+	logger     *text.Logger
+	mux        sync.Mutex
+}
+
+func newAPIHandler(run *runtime.Runtime, dbExecutor db.Executor) http.Handler {
+	server := &apiServer{
+		run:        run,
+		dbExecutor: dbExecutor,
+		logger:     run.Logger.Child("api"),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/search", server.handle(server.search))
+	mux.HandleFunc("/info", server.handle(server.info))
+	mux.HandleFunc("/updates", server.handle(server.updates))
+	mux.HandleFunc("/stats", server.handle(server.stats))
+
+	return mux
+}
+
+// handle wraps an endpoint, rejecting anything but GET and encoding the
+// returned value or error as JSON.
+func (s *apiServer) handle(endpoint func(r *http.Request) (any, int, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.logger.Debugln(r.Method, r.URL.String())
+
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			writeAPIError(w, http.StatusMethodNotAllowed, errors.New(gotext.Get("read-only endpoint")))
+
+			return
+		}
+
+		s.mux.Lock()
+		value, status, err := s.serve(endpoint, r)
+		s.mux.Unlock()
+
+		if err != nil {
+			writeAPIError(w, status, err)
+
+			return
+		}
+
+		w.WriteHeader(status)
+
+		if err := json.NewEncoder(w).Encode(value); err != nil {
+			s.logger.Debugln("unable to write response:", err)
+		}
+	}
+}
+
+// serve answers r with endpoint once the databases are refreshed, to pick up
+// installs and database syncs made since the server started.
+func (s *apiServer) serve(endpoint func(r *http.Request) (any, int, error), r *http.Request) (any, int, error) {
+	if err := s.dbExecutor.RefreshHandle(); err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	return endpoint(r)
+}
+
+func writeAPIError(w http.ResponseWriter, status int, err error) {
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
+
+func (s *apiServer) search(r *http.Request) (any, int, error) {
+	terms := r.URL.Query()["q"]
+	if len(terms) == 0 {
+		return nil, http.StatusBadRequest, errors.New(gotext.Get("missing query parameter: %s", "q"))
+	}
+
+	s.run.QueryBuilder.Execute(r.Context(), s.dbExecutor, terms)
+
+	// repository results alone would pass for a complete answer
+	if err := s.run.QueryBuilder.AURError(); err != nil {
+		return nil, http.StatusBadGateway, err
+	}
+
+	return s.run.QueryBuilder.SearchResults(), http.StatusOK, nil
+}
+
+func (s *apiServer) info(r *http.Request) (any, int, error) {
+	targets := r.URL.Query()["pkg"]
+	if len(targets) == 0 {
+		return nil, http.StatusBadRequest, errors.New(gotext.Get("missing query parameter: %s", "pkg"))
+	}
+
+	pkgs := make([]apiPackage, 0, len(targets))
+	aurS, repoS := packageSlices(targets, s.run.Cfg, s.dbExecutor)
+
+	for _, target := range repoS {
+		dbName, name := text.SplitDBFromName(target)
+
+		var pkg alpm.IPackage
+		if dbName != "" {
+			pkg = s.dbExecutor.SyncPackageFromDB(name, dbName)
+		} else {
+			pkg = s.dbExecutor.SyncPackage(name)
+		}
+
+		if pkg != nil {
+			pkgs = append(pkgs, s.repoPackage(pkg))
+		}
+	}
+
+	if len(aurS) != 0 {
+		names := make([]string, 0, len(aurS))
+		for _, target := range aurS {
+			_, name := text.SplitDBFromName(target)
+			names = append(names, name)
+		}
+
+		info, err := s.run.AURClient.Get(r.Context(), &aur.Query{Needles: names, By: aur.Name})
+		if err != nil {
+			return nil, http.StatusBadGateway, err
+		}
+
+		for i := range info {
+			pkgs = append(pkgs, s.aurPackage(&info[i]))
+		}
+	}
+
+	if len(pkgs) == 0 {
+		return nil, http.StatusNotFound, errors.New(gotext.Get("no packages found"))
+	}
+
+	return pkgs, http.StatusOK, nil
+}
+
+func (s *apiServer) installedVersion(name string) string {
+	if local := s.dbExecutor.LocalPackage(name); local != nil {
+		return local.Version()
+	}
+
+	return ""
+}
+
+func (s *apiServer) repoPackage(pkg alpm.IPackage) apiPackage {
+	depends := make([]string, 0)
+	for _, depend := range s.dbExecutor.PackageDepends(pkg) {
+		depends = append(depends, depend.String())
+	}
+
+	return apiPackage{
+		Source:      pkg.DB().Name(),
+		Name:        pkg.Name(),
+		Base:        pkg.Base(),
+		Version:     pkg.Version(),
+		Description: pkg.Description(),
+		URL:         pkg.URL(),
+		Licenses:    pkg.Licenses().Slice(),
+		Depends:     depends,
+		Installed:   s.installedVersion(pkg.Name()),
+	}
+}
+
+func (s *apiServer) aurPackage(pkg *aur.Pkg) apiPackage {
+	return apiPackage{
+		Source:       "aur",
+		Name:         pkg.Name,
+		Base:         pkg.PackageBase,
+		Version:      pkg.Version,
+		Description:  pkg.Description,
+		URL:          pkg.URL,
+		Licenses:     pkg.License,
+		Depends:      pkg.Depends,
+		MakeDepends:  pkg.MakeDepends,
+		Maintainer:   pkg.Maintainer,
+		Votes:        pkg.NumVotes,
+		Popularity:   pkg.Popularity,
+		OutOfDate:    pkg.OutOfDate,
+		LastModified: pkg.LastModified,
+		Installed:    s.installedVersion(pkg.Name),
+	}
+}
+
+func (s *apiServer) updates(r *http.Request) (any, int, error) {
+	logger := text.NewLogger(io.Discard, io.Discard, os.Stdin, s.run.Cfg.Debug, "api")
+	upService := newQuietUpgradeService(s.run, s.dbExecutor, s.run.Cfg, logger)
+
+	// devel packages are checked against the remotes as they are now
+	s.run.VCSStore.ResetCache()
+
+	graph, err := upService.GraphUpgrades(r.Context(), nil, false, nil)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	updates := make([]apiUpdate, 0, graph.Len())
+
+	_ = graph.ForEach(func(pkgName string, ii *dep.InstallInfo) error {
+		if !ii.Upgrade {
+			return nil
+		}
+
+		source := "aur"
+		if ii.Source == dep.Sync {
+			source = "repo"
+			if ii.SyncDBName != nil {
+				source = *ii.SyncDBName
+			}
+		}
+
+		updates = append(updates, apiUpdate{
+			Source:       source,
+			Name:         pkgName,
+			LocalVersion: ii.LocalVersion,
+			Version:      ii.Version,
+			Devel:        ii.Devel,
+		})
+
+		return nil
+	})
+
+	return updates, http.StatusOK, nil
+}
+
+func (s *apiServer) stats(_ *http.Request) (any, int, error) {
+	info := statistics(s.run, s.dbExecutor)
+
+	return apiStats{
+		Packages:         info.Totaln,
+		ExplicitPackages: info.Expln,
+		ForeignPackages:  len(s.dbExecutor.InstalledRemotePackageNames()),
+		Orphans:          len(hangingPackages(false, s.dbExecutor)),
+		InstalledSize:    info.TotalSize,
+		PacmanCaches:     info.pacmanCaches,
+		YippeeCache:      info.yippeeCache,
+	}, http.StatusOK, nil
+}
+
+// serveAPI serves the read-only HTTP API on addr until interrupted.
+func serveAPI(ctx context.Context, run *runtime.Runtime, dbExecutor db.Executor, addr string) error {
+	if host, _, err := net.SplitHostPort(addr); err != nil {
+		return err
+	} else if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		run.Logger.Warnln(gotext.Get("%s is not a loopback address, the API has no authentication", addr))
+	}
+
+	// nobody is around to answer menus
+	settings.NoConfirm = true
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	server := &http.Server{
+		Handler:           newAPIHandler(run, dbExecutor),
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), apiShutdownTimeout)
+		defer cancel()
+
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	run.Logger.OperationInfoln(gotext.Get("Serving the API on http://%s", listener.Addr()))
+
+	if err := server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
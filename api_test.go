@@ -0,0 +1,145 @@
+//go:build !integration
+// +build !integration
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	aur "github.com/Jguer/aur"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jguer/yippee/v12/pkg/db/mock"
+	mockaur "github.com/Jguer/yippee/v12/pkg/dep/mock"
+	"github.com/Jguer/yippee/v12/pkg/query"
+	"github.com/Jguer/yippee/v12/pkg/runtime"
+	"github.com/Jguer/yippee/v12/pkg/settings"
+	"github.com/Jguer/yippee/v12/pkg/settings/parser"
+	"github.com/Jguer/yippee/v12/pkg/text"
+)
+
+func TestAPIHandler(t *testing.T) {
+	t.Parallel()
+
+	dbExecutor := mock.NewExecutor().
+		Local(mock.NewPackage("yippee", "12.0.0-1")).
+		Build()
+
+	run := &runtime.Runtime{
+		Cfg:    &settings.Configuration{Mode: parser.ModeAny},
+		Logger: text.NewLogger(io.Discard, io.Discard, os.Stdin, false, "test"),
+		AURClient: mockaur.NewAUR(query.Pkg{
+			Name: "yippee", PackageBase: "yippee", Version: "12.1.0-1",
+			Description: "AUR helper", Maintainer: "jguer", NumVotes: 42,
+		}),
+	}
+
+	handler := newAPIHandler(run, dbExecutor)
+
+	testCases := []struct {
+		desc       string
+		method     string
+		path       string
+		wantStatus int
+		want       []apiPackage
+	}{
+		{
+			desc:       "aur info",
+			method:     http.MethodGet,
+			path:       "/info?pkg=yippee",
+			wantStatus: http.StatusOK,
+			want: []apiPackage{{
+				Source: "aur", Name: "yippee", Base: "yippee", Version: "12.1.0-1",
+				Description: "AUR helper", Maintainer: "jguer", Votes: 42, Installed: "12.0.0-1",
+			}},
+		},
+		{desc: "missing package", method: http.MethodGet, path: "/info?pkg=nope", wantStatus: http.StatusNotFound},
+		{desc: "missing parameter", method: http.MethodGet, path: "/info", wantStatus: http.StatusBadRequest},
+		{desc: "read-only", method: http.MethodPost, path: "/stats", wantStatus: http.StatusMethodNotAllowed},
+		{desc: "unknown endpoint", method: http.MethodGet, path: "/upgrade", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.path, http.NoBody))
+
+			assert.Equal(t, tc.wantStatus, rec.Code)
+
+			if tc.want != nil {
+				var got []apiPackage
+				require.NoError(t, json.NewDecoder(rec.Body).Decode(&got))
+				assert.Equal(t, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestAPIHandlerRefreshesDatabases(t *testing.T) {
+	t.Parallel()
+
+	refreshes := 0
+	dbExecutor := mock.NewExecutor().Build()
+	dbExecutor.RefreshHandleFn = func() error {
+		refreshes++
+		return nil
+	}
+
+	run := &runtime.Runtime{
+		Cfg:       &settings.Configuration{Mode: parser.ModeAny},
+		Logger:    text.NewLogger(io.Discard, io.Discard, os.Stdin, false, "test"),
+		AURClient: mockaur.NewAUR(),
+	}
+
+	handler := newAPIHandler(run, dbExecutor)
+
+	for i := 0; i < 2; i++ {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/info?pkg=nope", http.NoBody))
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	}
+
+	assert.Equal(t, 2, refreshes)
+
+	dbExecutor.RefreshHandleFn = func() error { return errors.New("unable to lock database") }
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/info?pkg=nope", http.NoBody))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
+
+func TestAPIHandlerSearchAURFailure(t *testing.T) {
+	t.Parallel()
+
+	dbExecutor := mock.NewExecutor().
+		Sync(mock.NewPackage("yippee", "12.0.0-1").WithDB("extra")).
+		Build()
+
+	logger := text.NewLogger(io.Discard, io.Discard, os.Stdin, false, "test")
+	aurClient := &mockaur.MockAUR{GetFn: func(context.Context, *aur.Query) ([]aur.Pkg, error) {
+		return nil, errors.New("aur is down")
+	}}
+
+	run := &runtime.Runtime{
+		Cfg:          &settings.Configuration{Mode: parser.ModeAny},
+		Logger:       logger,
+		AURClient:    aurClient,
+		QueryBuilder: query.NewSourceQueryBuilder(aurClient, logger, "", parser.ModeAny, "", false, false, false),
+	}
+
+	rec := httptest.NewRecorder()
+	newAPIHandler(run, dbExecutor).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/search?q=yippee", http.NoBody))
+
+	assert.Equal(t, http.StatusBadGateway, rec.Code)
+}
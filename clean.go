@@ -117,7 +117,7 @@ func cleanAUR(ctx context.Context, run *runtime.Runtime,
 	cachedPackages := make([]string, 0, len(files))
 
 	for _, file := range files {
-		if !file.IsDir() {
+		if !file.IsDir() || runtime.IsTempDir(file.Name()) {
 			continue
 		}
 
@@ -150,7 +150,7 @@ func cleanAUR(ctx context.Context, run *runtime.Runtime,
 	}
 
 	for _, file := range files {
-		if !file.IsDir() {
+		if !file.IsDir() || runtime.IsTempDir(file.Name()) {
 			continue
 		}
 
@@ -183,7 +183,7 @@ func cleanUntracked(ctx context.Context, run *runtime.Runtime) error {
 	}
 
 	for _, file := range files {
-		if !file.IsDir() {
+		if !file.IsDir() || runtime.IsTempDir(file.Name()) {
 			continue
 		}
 
@@ -50,12 +50,20 @@ New operations:
     yippee {-W --web}         [options] [package(s)]
     yippee {-Y --yippee}         [options] [package(s)]
 
-If no operation is specified 'yippee -Syu' will be performed
-If no operation is specified and targets are provided -Y will be assumed
+If no operation is specified 'yippee -Syu' will be performed, see --defaultop
+If no operation is specified and targets are provided -Y will be assumed, see --defaulttargetsop
 
 New options:
        --repo             Assume targets are from the repositories
     -a --aur              Assume targets are from the AUR
+       --targets-from <file> Read targets from a file, - for stdin
+       --json             Print the warnings of a transaction as JSON once it is over,
+                          and the -Qu updates, -Si info and -Ps stats as JSON
+       --record <dir>     Record the AUR requests and the databases to a directory
+       --replay <dir>     Resolve targets against a recording instead of this system
+       --dry-run          Print the install plan without downloading, building or installing
+       --output <text|ndjson> Also write clone, build and install events as JSON lines
+       --outputfd <fd>    File descriptor the --output=ndjson events go to, 1 by default
 
 Permanent configuration options:
     --save                Causes the following options to be saved back to the
@@ -63,24 +71,51 @@ Permanent configuration options:
 
     --aururl      <url>   Set an alternative AUR URL
     --aurrpcurl   <url>   Set an alternative URL for the AUR /rpc endpoint
+    --proxy       <url>   HTTP(S) or SOCKS5 proxy for network access
+    --noproxy     <hosts> Comma separated hosts to reach without the proxy
+    --cabundle    <file>  CA certificates to trust in addition to the system ones
+    --maxconcurrentdownloads <n> Number of packages to download sources for in parallel
+    --downloadratelimit <rate> Limit the bandwidth of each source download
+    --dateformat  <fmt>   Format of printed dates: iso or a Go time layout
+    --plain               No color or progress bars from yippee, pacman and makepkg
+    --noplain             Print color and progress bars as configured
+    --verbosepkglists     List the packages to install and upgrade in a table
+    --noverbosepkglists   List the packages to install and upgrade inline
+    --loglevels <name=level[:file]> Set the log level and file of parts of yippee
+    --nologlevels         Log every part of yippee at the same level
     --builddir    <dir>   Directory used to download and run PKGBUILDS
     --editor      <file>  Editor to use when editing PKGBUILDs
     --editorflags <flags> Pass arguments to editor
+    --editfiles   <exts>  Extensions of build files to include in the edit menu
     --makepkg     <file>  makepkg command to use
     --mflags      <flags> Pass arguments to makepkg
     --pacman      <file>  pacman command to use
     --git         <file>  git command to use
     --gitflags    <flags> Pass arguments to git
+    --pacmansyncflags     <flags> Pass arguments to pacman -S transactions
+    --pacmanupgradeflags  <flags> Pass arguments to pacman -U transactions
+    --pacmandatabaseflags <flags> Pass arguments to pacman -D transactions
+    --pacmanremoveflags   <flags> Pass arguments to pacman -R transactions
     --gpg         <file>  gpg command to use
     --gpgflags    <flags> Pass arguments to gpg
     --config      <file>  pacman.conf file to use
     --makepkgconf <file>  makepkg.conf file to use
     --nomakepkgconf       Use the default makepkg.conf
+    --makepkgconfextra <file> makepkg.conf fragment to overlay on makepkg.conf
+    --nomakepkgconfextra  Do not overlay a makepkg.conf fragment
+    --no-debug            Do not build debug packages enabled in makepkg.conf
+    --keep-debug          Build debug packages when enabled in makepkg.conf
+    --builduser <user>    Unprivileged user to build as when running as root
+    --nobuilduser         Build as the sudo/doas caller when running as root
+    --rootbuild <mode>    Drop root without a build user: auto/systemd/nobody/error
 
     --requestsplitn <n>   Max amount of packages to query per AUR request
     --completioninterval  <n> Time in days to refresh completion cache
+    --dbmaxage   <days>   Warn before builds when sync databases are older
     --sortby    <field>   Sort AUR results by a specific field during search
     --searchby  <field>   Search for packages using a specified field
+    --defaultop <op>      Operation run without operation or targets: sysupgrade/upgrades/help
+    --defaulttargetsop <op> Operation run with only targets: menu/search/install/help
     --answerclean   <a>   Set a predetermined answer for the clean build menu
     --answerdiff    <a>   Set a predetermined answer for the diff menu
     --answeredit    <a>   Set a predetermined answer for the edit pkgbuild menu
@@ -95,6 +130,7 @@ Permanent configuration options:
     --askremovemake       Ask to remove makedepends after install
     --askyesremovemake    Ask to remove makedepends after install("Y" as default)
     --removemake          Remove makedepends after install
+    --removemake[=ask-per-package] Pick which makedepends to remove after install
     --noremovemake        Don't remove makedepends after install
 
     --cleanafter          Remove package sources after successful install
@@ -105,6 +141,8 @@ Permanent configuration options:
     --doublelineresults   List each search result on two lines, like pacman
 
     --devel               Check development packages during sysupgrade
+    --devellog            Show new commits of development packages in the upgrade menu
+    --devel-strict        Only use commit hashes to decide devel package updates
     --rebuild             Always build target packages
     --rebuildall          Always build all AUR packages
     --norebuild           Skip package build if in cache and up to date
@@ -113,29 +151,83 @@ Permanent configuration options:
     --noredownload        Skip pkgbuild download if in cache and up to date
     --redownloadall       Always download pkgbuilds of all AUR packages
     --provides            Look for matching providers when searching for packages
+    --providerpolicy <policy> Pick repo or AUR providers: repo-first/aur-if-installed/ask
     --pgpfetch            Prompt to import PGP keys from PKGBUILDs
+    --strictchecksums     Fail when a source is not verified by a checksum
+    --nostrictchecksums   Warn when a source is not verified by a checksum
+    --inspectors <names>  Inspect built packages with namcap or other linters
+    --noinspectors        Do not inspect built packages
+    --inspectblock        Do not install packages the inspectors find errors in
+    --noinspectblock      Install packages whatever the inspectors find
     --useask              Automatically resolve conflicts using pacman's ask flag
 
     --sudo                <file>  sudo command to use
     --sudoflags           <flags> Pass arguments to sudo
     --sudoloop            Loop sudo calls in the background to avoid timeout
 
+    --throttlebuilds      Limit makepkg jobs based on available memory
+    --nothrottlebuilds    Do not limit makepkg jobs based on available memory
+    --memorylimit   <n>   Memory in MiB to budget per make job when throttling
+    --shallowclone        Clone PKGBUILD repositories with --depth 1
+    --noshallowclone      Clone the full history of PKGBUILD repositories
+    --pkgbuildfetch <cgit|git> Fetch AUR PKGBUILDs for -Gp through cgit or git
+    --pkgbuildclone <git|snapshot> Clone AUR PKGBUILD repositories with git or from snapshots first
+    --gcinterval    <n>   Days between git gc runs on cached PKGBUILD repos
+    --worktrees           Build AUR packages in a git worktree per version
+    --noworktrees         Build AUR packages in their PKGBUILD clone
+    --versionedbuilddirs  Build AUR packages in a directory per version and keep old builds
+    --noversionedbuilddirs Do not keep a build directory per version
+    --keepversions  <n>   Number of versioned build directories to keep per package
+    --keepversionsdays <days> Days after which old versioned build directories are removed
+    --confirmupfront      Ask every question before downloading and building
+    --noconfirmupfront    Ask questions when they come up
+    --timings             Print how long each phase of the run took
+    --notimings           Do not print a timing report
+
     --timeupdate          Check packages' AUR page for changes during sysupgrade
+    --selfupgradefirst    Offer to upgrade yippee itself before the rest of sysupgrade
+    --noselfupgradefirst  Upgrade yippee along with the other packages
+    --ignorerepo <repos>  Exclude the packages of these repositories from sysupgrade
+    --newsfeeds <urls>    RSS or Atom feeds printed by -Pw
+    --newsonupgrade <gate|show|off> Print unread news before sysupgrade, gate requires acknowledging it
 
 show specific options:
-    -c --complete         Used for completions
+    -c --complete         Used for completions, twice or with --force to rebuild the cache
     -d --defaultconfig    Print default yippee configuration
     -g --currentconfig    Print current yippee configuration
     -s --stats            Display system package statistics
+    -k --check            With --stats, check the files of foreign packages
     -w --news             Print arch news
+       --metrics <path>   Write update status metrics for node_exporter
+       --graph            Print the dependency graph of the targets
+       --graph-format <dot|json|mermaid> Format of the --graph output
 
 yippee specific options:
     -c --clean            Remove unneeded dependencies
        --gendb            Generates development package DB used for updating
+       --refresh-pkgbuilds Pull every cached PKGBUILD repository in the build dir
+       --sync-manifest <file> Install the packages listed in a manifest
+       --install-timer    Install a systemd user timer checking for updates daily
+       --remove-timer     Remove the systemd user timer
+       --forget-providers Forget the remembered provider choices
+       --note             Keep the targets <pkg> <text> as a note on a foreign package
+       --export-notes <file> Write the package notes to a file, - for stdout
+       --import-notes <file> Read package notes from a file, - for stdin
+       --serve-api <addr> Serve a read-only HTTP API on addr, e.g. 127.0.0.1:8390
+       --dbus             Export update checks on the D-Bus session bus
+       --prune            Remove explicit packages missing from the manifest
+       --maintainer <name> List the AUR packages of a maintainer to install from
+
+web specific options:
+    -v --vote             Vote for AUR packages
+    -u --unvote           Remove the vote for AUR packages
+       --flag             Flag AUR packages out-of-date
+       --comment <text>   Comment sent when flagging, e.g. the new upstream version
 
 getpkgbuild specific options:
     -f --force            Force download for existing ABS packages
-    -p --print            Print pkgbuild of packages`)
+    -p --print            Print pkgbuild of packages
+       --vars             Print a summary of the PKGBUILD variables with -p`)
 }
 
 func handleCmd(ctx context.Context, run *runtime.Runtime,
@@ -162,14 +254,14 @@ func handleCmd(ctx context.Context, run *runtime.Runtime,
 	case "Q", "query":
 		return handleQuery(ctx, run, cmdArgs, dbExecutor)
 	case "R", "remove":
-		return handleRemove(ctx, run, cmdArgs, run.VCSStore)
+		return handleRemove(ctx, run, cmdArgs, dbExecutor, run.VCSStore)
 	case "S", "sync":
 		return handleSync(ctx, run, cmdArgs, dbExecutor)
 	case "T", "deptest":
 		return run.CmdBuilder.Show(run.CmdBuilder.BuildPacmanCmd(ctx,
 			cmdArgs, run.Cfg.Mode, settings.NoConfirm))
 	case "U", "upgrade":
-		return handleUpgrade(ctx, run, cmdArgs)
+		return handleUpgrade(ctx, run, cmdArgs, dbExecutor)
 	case "B", "build":
 		return handleBuild(ctx, run, dbExecutor, cmdArgs)
 	case "G", "getpkgbuild":
@@ -188,25 +280,42 @@ func handleCmd(ctx context.Context, run *runtime.Runtime,
 
 // getFilter returns filter function which can keep packages which were only
 // explicitly installed or ones installed as dependencies for showing available
-// updates or their count.
+// updates or their count. --aur-only and --devel-only further restrict the
+// updates to AUR and development package upgrades.
 func getFilter(cmdArgs *parser.Arguments) (upgrade.Filter, error) {
 	deps, explicit := cmdArgs.ExistsArg("d", "deps"), cmdArgs.ExistsArg("e", "explicit")
 
+	reasonFilter := func(pkg *upgrade.Upgrade) bool {
+		return true
+	}
+
 	switch {
 	case deps && explicit:
 		return nil, errors.New(gotext.Get("invalid option: '--deps' and '--explicit' may not be used together"))
 	case deps:
-		return func(pkg *upgrade.Upgrade) bool {
+		reasonFilter = func(pkg *upgrade.Upgrade) bool {
 			return pkg.Reason == alpm.PkgReasonDepend
-		}, nil
+		}
 	case explicit:
-		return func(pkg *upgrade.Upgrade) bool {
+		reasonFilter = func(pkg *upgrade.Upgrade) bool {
 			return pkg.Reason == alpm.PkgReasonExplicit
-		}, nil
+		}
+	}
+
+	aurOnly, develOnly := cmdArgs.ExistsArg("aur-only"), cmdArgs.ExistsArg("devel-only")
+	if !aurOnly && !develOnly {
+		return reasonFilter, nil
 	}
 
 	return func(pkg *upgrade.Upgrade) bool {
-		return true
+		switch pkg.Repository {
+		case "aur":
+			return aurOnly && reasonFilter(pkg)
+		case "devel":
+			return develOnly && reasonFilter(pkg)
+		}
+
+		return false
 	}, nil
 }
 
@@ -231,6 +340,10 @@ func handleQuery(ctx context.Context, run *runtime.Runtime, cmdArgs *parser.Argu
 		return err
 	}
 
+	if cmdArgs.ExistsArg("i", "info") {
+		printNotes(run.Logger, run.Notes, cmdArgs.Targets)
+	}
+
 	return nil
 }
 
@@ -265,13 +378,30 @@ func handlePrint(ctx context.Context, run *runtime.Runtime, cmdArgs *parser.Argu
 		double := cmdArgs.ExistsDouble("w", "news")
 		quiet := cmdArgs.ExistsArg("q", "quiet")
 
-		return news.PrintNewsFeed(ctx, run.HTTPClient, run.Logger,
+		state := news.NewReadState(run.Cfg.NewsStateFilePath)
+		if err := state.Load(); err != nil {
+			run.Logger.Warnln(err)
+		}
+
+		_, err := news.PrintNewsFeed(ctx, run.HTTPClient, run.Logger, run.Cfg.NewsFeedURLs(), state,
 			dbExecutor.LastBuildTime(), run.Cfg.BottomUp, double, quiet)
+		if errS := state.Save(); errS != nil {
+			run.Logger.Warnln(errS)
+		}
+
+		return err
 	case cmdArgs.ExistsArg("c", "complete"):
 		return completion.Show(ctx, run.HTTPClient, dbExecutor,
-			run.Cfg.AURURL, run.Cfg.CompletionPath, run.Cfg.CompletionInterval, cmdArgs.ExistsDouble("c", "complete"))
+			run.Cfg.AURURL, run.Cfg.CompletionPath, run.Cfg.CompletionInterval,
+			cmdArgs.ExistsDouble("c", "complete") || cmdArgs.ExistsArg("f", "force"), run.Logger)
 	case cmdArgs.ExistsArg("s", "stats"):
-		return localStatistics(ctx, run, dbExecutor)
+		return localStatistics(ctx, run, dbExecutor, cmdArgs.ExistsArg("k", "check"))
+	case cmdArgs.ExistsArg("metrics"):
+		path, _, _ := cmdArgs.GetArg("metrics")
+
+		return exportMetrics(ctx, run, dbExecutor, path)
+	case cmdArgs.ExistsArg("graph"):
+		return printDependencyGraph(ctx, run, cmdArgs, dbExecutor)
 	}
 
 	return nil
@@ -284,6 +414,31 @@ func handleYippee(ctx context.Context, run *runtime.Runtime,
 	switch {
 	case cmdArgs.ExistsArg("gendb"):
 		return createDevelDB(ctx, run, dbExecutor)
+	case cmdArgs.ExistsArg("refresh-pkgbuilds"):
+		return refreshPkgbuilds(ctx, run)
+	case cmdArgs.ExistsArg("sync-manifest"):
+		return syncManifest(ctx, run, cmdArgs, dbExecutor)
+	case cmdArgs.ExistsArg("install-timer"):
+		return installTimer(ctx, run, cmdBuilder)
+	case cmdArgs.ExistsArg("remove-timer"):
+		return removeTimer(ctx, run, cmdBuilder)
+	case cmdArgs.ExistsArg("forget-providers"):
+		return forgetProviders(run)
+	case cmdArgs.ExistsArg("note"):
+		return handleNote(run, dbExecutor, cmdArgs.Targets)
+	case cmdArgs.ExistsArg("export-notes"):
+		path, _, _ := cmdArgs.GetArg("export-notes")
+		return exportNotes(run, path)
+	case cmdArgs.ExistsArg("import-notes"):
+		path, _, _ := cmdArgs.GetArg("import-notes")
+		return importNotes(run, path)
+	case cmdArgs.ExistsArg("serve-api"):
+		addr, _, _ := cmdArgs.GetArg("serve-api")
+		return serveAPI(ctx, run, dbExecutor, addr)
+	case cmdArgs.ExistsArg("dbus"):
+		return serveDBus(ctx, run, dbExecutor)
+	case cmdArgs.ExistsArg("maintainer"):
+		return displayMaintainerMenu(ctx, run, cmdArgs, dbExecutor)
 	case cmdArgs.ExistsDouble("c"):
 		return cleanDependencies(ctx, run.Cfg, cmdBuilder, cmdArgs, dbExecutor, true)
 	case cmdArgs.ExistsArg("c", "clean"):
@@ -295,14 +450,28 @@ func handleYippee(ctx context.Context, run *runtime.Runtime,
 	return nil
 }
 
+func forgetProviders(run *runtime.Runtime) error {
+	if err := run.Providers.Forget(); err != nil {
+		return err
+	}
+
+	run.Logger.OperationInfoln(gotext.Get("Forgot the remembered provider choices"))
+
+	return nil
+}
+
 func handleWeb(ctx context.Context, run *runtime.Runtime, cmdArgs *parser.Arguments) error {
 	switch {
 	case cmdArgs.ExistsArg("v", "vote"):
 		return handlePackageVote(ctx, cmdArgs.Targets, run.AURClient, run.Logger,
-			run.VoteClient, true)
+			run.AURWebClient(), true)
 	case cmdArgs.ExistsArg("u", "unvote"):
 		return handlePackageVote(ctx, cmdArgs.Targets, run.AURClient, run.Logger,
-			run.VoteClient, false)
+			run.AURWebClient(), false)
+	case cmdArgs.ExistsArg("flag"):
+		comment, _, _ := cmdArgs.GetArg("comment")
+		return handlePackageFlag(ctx, cmdArgs.Targets, run.AURClient, run.Logger,
+			run.AURWebClient(), comment)
 	}
 
 	return nil
@@ -310,17 +479,26 @@ func handleWeb(ctx context.Context, run *runtime.Runtime, cmdArgs *parser.Argume
 
 func handleGetpkgbuild(ctx context.Context, run *runtime.Runtime, cmdArgs *parser.Arguments, dbExecutor download.DBSearcher) error {
 	if cmdArgs.ExistsArg("p", "print") {
-		return printPkgbuilds(dbExecutor, run.AURClient,
-			run.HTTPClient, run.Logger, cmdArgs.Targets, run.Cfg.Mode, run.Cfg.AURURL)
+		return printPkgbuilds(ctx, dbExecutor, run.AURClient, run.AURMisses,
+			run.HTTPClient, run.CmdBuilder, run.Logger, cmdArgs.Targets, run.Cfg.Mode, run.Cfg.AURURL,
+			run.Cfg.PKGBUILDFetch == "git", cmdArgs.ExistsArg("vars"))
 	}
 
 	return getPkgbuilds(ctx, dbExecutor, run.AURClient, run,
 		cmdArgs.Targets, cmdArgs.ExistsArg("f", "force"))
 }
 
+// handleUpgrade installs package files with pacman, first building the
+// dependencies of the files only the AUR has.
 func handleUpgrade(ctx context.Context,
-	run *runtime.Runtime, cmdArgs *parser.Arguments,
+	run *runtime.Runtime, cmdArgs *parser.Arguments, dbExecutor db.Executor,
 ) error {
+	if run.Cfg.Mode.AtLeastAUR() && !cmdArgs.ExistsArg("d", "nodeps") && !cmdArgs.ExistsArg("p", "print") {
+		if err := installPkgFileDeps(ctx, run, cmdArgs, dbExecutor); err != nil {
+			return err
+		}
+	}
+
 	return run.CmdBuilder.Show(run.CmdBuilder.BuildPacmanCmd(ctx,
 		cmdArgs, run.Cfg.Mode, settings.NoConfirm))
 }
@@ -341,7 +519,10 @@ func handleSync(ctx context.Context, run *runtime.Runtime, cmdArgs *parser.Argum
 
 	switch {
 	case cmdArgs.ExistsArg("s", "search"):
-		return syncSearch(ctx, targets, dbExecutor, run.QueryBuilder, !cmdArgs.ExistsArg("q", "quiet"))
+		printFormat, _, _ := cmdArgs.GetArg("print-format")
+		return syncSearch(ctx, targets, dbExecutor, run.QueryBuilder, !cmdArgs.ExistsArg("q", "quiet"), printFormat)
+	case cmdArgs.ExistsArg("i", "info"):
+		return syncInfo(ctx, run, cmdArgs, targets, dbExecutor)
 	case cmdArgs.ExistsArg("p", "print", "print-format"):
 		return run.CmdBuilder.Show(run.CmdBuilder.BuildPacmanCmd(ctx,
 			cmdArgs, run.Cfg.Mode, settings.NoConfirm))
@@ -352,19 +533,37 @@ func handleSync(ctx context.Context, run *runtime.Runtime, cmdArgs *parser.Argum
 	case cmdArgs.ExistsArg("g", "groups"):
 		return run.CmdBuilder.Show(run.CmdBuilder.BuildPacmanCmd(ctx,
 			cmdArgs, run.Cfg.Mode, settings.NoConfirm))
-	case cmdArgs.ExistsArg("i", "info"):
-		return syncInfo(ctx, run, cmdArgs, targets, dbExecutor)
 	case cmdArgs.ExistsArg("u", "sysupgrade") || len(cmdArgs.Targets) > 0:
 		return syncInstall(ctx, run, cmdArgs, dbExecutor)
 	case cmdArgs.ExistsArg("y", "refresh"):
-		return run.CmdBuilder.Show(run.CmdBuilder.BuildPacmanCmd(ctx,
-			cmdArgs, run.Cfg.Mode, settings.NoConfirm))
+		refreshCmd := run.CmdBuilder.BuildPacmanCmd(ctx, cmdArgs, run.Cfg.Mode, settings.NoConfirm)
+		if run.Cfg.DryRun {
+			run.Logger.Println(refreshCmd.String())
+			return nil
+		}
+
+		return run.CmdBuilder.Show(refreshCmd)
 	}
 
 	return nil
 }
 
-func handleRemove(ctx context.Context, run *runtime.Runtime, cmdArgs *parser.Arguments, localCache vcs.Store) error {
+// handleRemove removes the targets with pacman. With --aur only foreign
+// packages are removed. Foreign packages still required by other foreign
+// packages are warned about first.
+func handleRemove(ctx context.Context, run *runtime.Runtime, cmdArgs *parser.Arguments,
+	dbExecutor db.Executor, localCache vcs.Store,
+) error {
+	if run.Cfg.Mode == parser.ModeAUR {
+		cmdArgs.Targets = foreignTargets(run.Logger, dbExecutor, cmdArgs.Targets)
+		if len(cmdArgs.Targets) == 0 {
+			run.Logger.Println(gotext.Get(" there is nothing to do"))
+			return settings.ErrNothingToDo{}
+		}
+	}
+
+	warnForeignDependents(run.Logger, dbExecutor, cmdArgs.Targets)
+
 	err := run.CmdBuilder.Show(run.CmdBuilder.BuildPacmanCmd(ctx,
 		cmdArgs, run.Cfg.Mode, settings.NoConfirm))
 	if err == nil {
@@ -408,12 +607,28 @@ func displayNumberMenu(ctx context.Context, run *runtime.Runtime, pkgS []string,
 
 	if len(cmdArgs.Targets) == 0 {
 		run.Logger.Println(gotext.Get(" there is nothing to do"))
-		return nil
+		return settings.ErrNothingToDo{}
 	}
 
 	return syncInstall(ctx, run, cmdArgs, dbExecutor)
 }
 
+// displayMaintainerMenu lists the AUR packages of the maintainer given to
+// --maintainer in the number menu and installs the chosen ones.
+func displayMaintainerMenu(ctx context.Context, run *runtime.Runtime,
+	cmdArgs *parser.Arguments, dbExecutor db.Executor,
+) error {
+	maintainer, _, _ := cmdArgs.GetArg("maintainer")
+	cmdArgs.DelArg("maintainer")
+
+	queryBuilder := query.NewSourceQueryBuilder(
+		run.AURClient, run.Logger.Child("maintainer.querybuilder"), run.Cfg.SortBy,
+		parser.ModeAUR, "maintainer",
+		run.Cfg.BottomUp, run.Cfg.SingleLineResults, run.Cfg.SeparateSources)
+
+	return displayNumberMenu(ctx, run, []string{maintainer}, dbExecutor, queryBuilder, cmdArgs)
+}
+
 func syncList(ctx context.Context, run *runtime.Runtime,
 	httpClient *http.Client, cmdArgs *parser.Arguments, dbExecutor db.Executor,
 ) error {
@@ -138,3 +138,41 @@ func TestYogurtMenuAURDB(t *testing.T) {
 		assert.Subset(t, strings.Split(show, " "), strings.Split(wantShow[i], " "), fmt.Sprintf("%d - %s", i, show))
 	}
 }
+
+func TestUsageOptionsRegistered(t *testing.T) {
+	t.Parallel()
+
+	var out strings.Builder
+	usage(text.NewLogger(&out, io.Discard, strings.NewReader(""), false, "test"))
+
+	for _, line := range strings.Split(out.String(), "\n") {
+		fields := strings.Fields(line)
+
+		// operations: yippee {-S --sync} [options]
+		if len(fields) > 2 && fields[0] == "yippee" && strings.HasPrefix(fields[1], "{") {
+			_, ok := parser.LookupOption(strings.TrimSuffix(strings.TrimPrefix(fields[2], "--"), "}"))
+			assert.True(t, ok, line)
+
+			continue
+		}
+
+		// options: [-a] --aur [<value>] description
+		if len(fields) > 1 && len(fields[0]) == 2 && fields[0][0] == '-' {
+			fields = fields[1:]
+		}
+
+		if len(fields) == 0 || !strings.HasPrefix(fields[0], "--") {
+			continue
+		}
+
+		name, _, _ := strings.Cut(fields[0][2:], "[")
+		option, ok := parser.LookupOption(name)
+		if !assert.True(t, ok, "usage lists unregistered option %s", fields[0]) {
+			continue
+		}
+
+		if len(fields) > 1 && strings.HasPrefix(fields[1], "<") {
+			assert.True(t, option.TakesValue(), "%s is shown with a value", fields[0])
+		}
+	}
+}
@@ -0,0 +1,140 @@
+//go:build dbus
+// +build dbus
+
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/introspect"
+	"github.com/leonelquinteros/gotext"
+
+	"github.com/Jguer/yippee/v12/pkg/db"
+	"github.com/Jguer/yippee/v12/pkg/runtime"
+	"github.com/Jguer/yippee/v12/pkg/settings"
+)
+
+const (
+	dbusName      = "io.github.Jguer.Yippee"
+	dbusPath      = "/io/github/Jguer/Yippee"
+	dbusInterface = dbusName + ".Updates"
+
+	// dbusCheckInterval is how often the service checks for updates on its own.
+	dbusCheckInterval = time.Hour
+)
+
+const dbusIntrospection = `<!DOCTYPE node PUBLIC "-//freedesktop//DTD D-BUS Object Introspection 1.0//EN"
+ "http://www.freedesktop.org/standards/dbus/1.0/introspect.dtd">
+<node>
+  <interface name="` + dbusInterface + `">
+    <method name="CheckUpdates">
+      <arg name="repo" type="u" direction="out"/>
+      <arg name="aur" type="u" direction="out"/>
+      <arg name="devel" type="u" direction="out"/>
+    </method>
+    <signal name="UpdatesAvailable">
+      <arg name="repo" type="u"/>
+      <arg name="aur" type="u"/>
+      <arg name="devel" type="u"/>
+    </signal>
+  </interface>
+  <interface name="org.freedesktop.DBus.Introspectable">
+    <method name="Introspect">
+      <arg name="data" type="s" direction="out"/>
+    </method>
+  </interface>
+  <interface name="org.freedesktop.DBus.Peer">
+    <method name="Ping"/>
+  </interface>
+</node>
+`
+
+// dbusUpdates is the object exported on dbusPath.
+type dbusUpdates struct {
+	ctx     context.Context
+	service *updateService
+}
+
+func (u *dbusUpdates) CheckUpdates() (repo, aur, devel uint32, dbusErr *dbus.Error) {
+	counts, err := u.service.checkUpdates(u.ctx)
+	if err != nil {
+		return 0, 0, 0, dbus.MakeFailedError(err)
+	}
+
+	return counts[0], counts[1], counts[2], nil
+}
+
+// serveDBus exports the update checks on the session bus until interrupted.
+func serveDBus(ctx context.Context, run *runtime.Runtime, dbExecutor db.Executor) error {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	reply, err := conn.RequestName(dbusName, dbus.NameFlagDoNotQueue)
+	if err != nil {
+		return err
+	}
+
+	if reply != dbus.RequestNameReplyPrimaryOwner {
+		return errors.New(gotext.Get("%s is already owned on the session bus", dbusName))
+	}
+
+	// nobody is around to answer menus
+	settings.NoConfirm = true
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	service := &updateService{
+		check: func(ctx context.Context) (*updateMetrics, error) {
+			// pick up installs and database syncs made since the last check
+			if err := dbExecutor.RefreshHandle(); err != nil {
+				return nil, err
+			}
+
+			// and commits pushed to the remotes of devel packages
+			run.VCSStore.ResetCache()
+
+			return collectUpdateMetrics(ctx, run, dbExecutor)
+		},
+		emit: func(member string, body ...any) error {
+			return conn.Emit(dbusPath, dbusInterface+"."+member, body...)
+		},
+	}
+
+	if err := conn.Export(&dbusUpdates{ctx: ctx, service: service}, dbusPath, dbusInterface); err != nil {
+		return err
+	}
+
+	if err := conn.Export(introspect.Introspectable(dbusIntrospection), dbusPath,
+		"org.freedesktop.DBus.Introspectable"); err != nil {
+		return err
+	}
+
+	run.Logger.OperationInfoln(gotext.Get("Serving %s on the session bus", dbusName))
+
+	ticker := time.NewTicker(dbusCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := service.checkUpdates(ctx); err != nil {
+			run.Logger.Errorln(gotext.Get("unable to check for updates: %s", err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-conn.Context().Done():
+			return errors.New(gotext.Get("connection to the session bus closed"))
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,19 @@
+//go:build !dbus
+// +build !dbus
+
+package main
+
+import (
+	"context"
+	"errors"
+
+	"github.com/leonelquinteros/gotext"
+
+	"github.com/Jguer/yippee/v12/pkg/db"
+	"github.com/Jguer/yippee/v12/pkg/runtime"
+)
+
+// serveDBus fails, the D-Bus service is only built with the dbus build tag.
+func serveDBus(context.Context, *runtime.Runtime, db.Executor) error {
+	return errors.New(gotext.Get("yippee was built without D-Bus support, rebuild it with the dbus build tag"))
+}
@@ -0,0 +1,37 @@
+//go:build dbus && !integration
+// +build dbus,!integration
+
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDBusUpdatesCheckUpdates(t *testing.T) {
+	t.Parallel()
+
+	updates := &dbusUpdates{ctx: context.Background(), service: &updateService{
+		check: func(context.Context) (*updateMetrics, error) {
+			return &updateMetrics{repoUpdates: 2, develUpdates: 1}, nil
+		},
+		emit: func(string, ...any) error { return nil },
+	}}
+
+	repo, aur, devel, dbusErr := updates.CheckUpdates()
+	assert.Nil(t, dbusErr)
+	assert.Equal(t, []uint32{2, 0, 1}, []uint32{repo, aur, devel})
+
+	updates.service.check = func(context.Context) (*updateMetrics, error) {
+		return nil, errors.New("database locked")
+	}
+
+	_, _, _, dbusErr = updates.CheckUpdates()
+	if assert.NotNil(t, dbusErr) {
+		assert.Equal(t, "org.freedesktop.DBus.Error.Failed", dbusErr.Name)
+		assert.Equal(t, []any{"database locked"}, dbusErr.Body)
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"sync"
+)
+
+// updateService answers CheckUpdates and emits UpdatesAvailable when the
+// number of pending updates changes.
+type updateService struct {
+	check func(ctx context.Context) (*updateMetrics, error)
+	emit  func(member string, body ...any) error
+
+	mux  sync.Mutex
+	last [3]uint32
+}
+
+// checkUpdates counts the pending repository, AUR and devel updates,
+// signaling when there are some and they differ from the last count.
+func (s *updateService) checkUpdates(ctx context.Context) ([3]uint32, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	metrics, err := s.check(ctx)
+	if err != nil {
+		return [3]uint32{}, err
+	}
+
+	counts := [3]uint32{
+		uint32(metrics.repoUpdates),
+		uint32(metrics.aurUpdates),
+		uint32(metrics.develUpdates),
+	}
+
+	if counts != s.last && counts != [3]uint32{} {
+		if err := s.emit("UpdatesAvailable", counts[0], counts[1], counts[2]); err != nil {
+			return [3]uint32{}, err
+		}
+	}
+
+	s.last = counts
+
+	return counts, nil
+}
@@ -0,0 +1,58 @@
+//go:build !integration
+// +build !integration
+
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateService(t *testing.T) {
+	t.Parallel()
+
+	metrics := &updateMetrics{}
+	emitted := [][]any{}
+
+	service := &updateService{
+		check: func(context.Context) (*updateMetrics, error) { return metrics, nil },
+		emit: func(member string, body ...any) error {
+			assert.Equal(t, "UpdatesAvailable", member)
+			emitted = append(emitted, body)
+			return nil
+		},
+	}
+
+	counts, err := service.checkUpdates(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, [3]uint32{0, 0, 0}, counts)
+	assert.Empty(t, emitted, "no signal without updates")
+
+	metrics = &updateMetrics{repoUpdates: 3, aurUpdates: 1}
+	counts, err = service.checkUpdates(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, [3]uint32{3, 1, 0}, counts)
+	_, err = service.checkUpdates(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, [][]any{{uint32(3), uint32(1), uint32(0)}}, emitted, "one signal per change")
+}
+
+func TestUpdateServiceCheckFailed(t *testing.T) {
+	t.Parallel()
+
+	errCheck := errors.New("database locked")
+	service := &updateService{
+		check: func(context.Context) (*updateMetrics, error) { return nil, errCheck },
+		emit: func(string, ...any) error {
+			t.Error("no signal when the check fails")
+			return nil
+		},
+	}
+
+	_, err := service.checkUpdates(context.Background())
+	assert.ErrorIs(t, err, errCheck)
+}
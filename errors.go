@@ -2,8 +2,53 @@ package main
 
 import (
 	"errors"
+	"net"
+	"os/exec"
 
+	"github.com/Jguer/aur"
 	"github.com/leonelquinteros/gotext"
+
+	"github.com/Jguer/yippee/v12/pkg/settings"
+	"github.com/Jguer/yippee/v12/pkg/sync/build"
 )
 
 var ErrPackagesNotFound = errors.New(gotext.Get("could not find all required packages"))
+
+// Exit codes returned by yippee so scripts can branch without parsing the
+// output. Any other code is the exit status of the failed pacman command.
+const (
+	exitOK             = 0
+	exitGeneric        = 1
+	exitNothingToDo    = 2
+	exitPartialFailure = 3
+	exitUserAbort      = 4
+	exitNetworkFailure = 5
+)
+
+// exitCode maps the error returned by an operation to the exit code of the
+// process.
+func exitCode(err error) int {
+	var (
+		exitError *exec.ExitError
+		netError  net.Error
+		failed    *build.FailedIgnoredPkgError
+	)
+
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.As(err, &settings.ErrNothingToDo{}):
+		return exitNothingToDo
+	case errors.As(err, &settings.ErrUserAbort{}), errors.As(err, new(*settings.ErrUserAbort)):
+		return exitUserAbort
+	case errors.As(err, &failed):
+		return exitPartialFailure
+	case errors.As(err, &netError), errors.Is(err, aur.ErrServiceUnavailable):
+		return exitNetworkFailure
+	case errors.As(err, &exitError):
+		// mirror pacman exit code when applicable
+		return exitError.ExitCode()
+	}
+
+	return exitGeneric
+}
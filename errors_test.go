@@ -0,0 +1,59 @@
+//go:build !integration
+// +build !integration
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os/exec"
+	"testing"
+
+	"github.com/Jguer/aur"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jguer/yippee/v12/pkg/multierror"
+	"github.com/Jguer/yippee/v12/pkg/settings"
+	"github.com/Jguer/yippee/v12/pkg/sync/build"
+)
+
+func TestExitCode(t *testing.T) {
+	t.Parallel()
+
+	exitErr := exec.Command("sh", "-c", "exit 7").Run()
+	require.Error(t, exitErr)
+
+	partial := &multierror.MultiError{}
+	partial.Add(&build.FailedIgnoredPkgError{})
+
+	testCases := []struct {
+		desc string
+		err  error
+		want int
+	}{
+		{desc: "success", err: nil, want: exitOK},
+		{desc: "generic", err: errors.New("boom"), want: exitGeneric},
+		{desc: "nothing to do", err: settings.ErrNothingToDo{}, want: exitNothingToDo},
+		{desc: "user abort", err: settings.ErrUserAbort{}, want: exitUserAbort},
+		{desc: "user abort pointer", err: &settings.ErrUserAbort{}, want: exitUserAbort},
+		{desc: "wrapped user abort", err: fmt.Errorf("menu: %w", settings.ErrUserAbort{}), want: exitUserAbort},
+		{desc: "partial failure", err: partial, want: exitPartialFailure},
+		{
+			desc: "network failure",
+			err:  fmt.Errorf("request failed: %w", &net.OpError{Op: "dial", Err: errors.New("refused")}),
+			want: exitNetworkFailure,
+		},
+		{desc: "aur unavailable", err: fmt.Errorf("upgrade: %w", aur.ErrServiceUnavailable), want: exitNetworkFailure},
+		{desc: "pacman exit status", err: exitErr, want: 7},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.want, exitCode(tc.err))
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/Jguer/aur"
+	"github.com/leonelquinteros/gotext"
+
+	"github.com/Jguer/yippee/v12/pkg/aurweb"
+	"github.com/Jguer/yippee/v12/pkg/settings"
+	"github.com/Jguer/yippee/v12/pkg/text"
+)
+
+type ErrAURFlag struct {
+	inner   error
+	pkgName string
+}
+
+func (e *ErrAURFlag) Error() string {
+	return gotext.Get("Unable to flag package out-of-date: %s. err: %s", e.pkgName, e.inner.Error())
+}
+
+// handlePackageFlag flags the targets out-of-date on the AUR once the user
+// confirmed, after showing the packaged version and where upstream releases
+// it. The comment is asked for when not given, the AUR requires one.
+func handlePackageFlag(ctx context.Context,
+	targets []string, aurClient aur.QueryClient, logger *text.Logger,
+	webClient *aurweb.Client, comment string,
+) error {
+	infos, err := aurClient.Get(ctx, &aur.Query{
+		Needles: targets,
+		By:      aur.Name,
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(infos) == 0 {
+		logger.Println(gotext.Get(" there is nothing to do"))
+		return nil
+	}
+
+	flagged := make(map[string]bool, len(infos))
+
+	for i := range infos {
+		info := &infos[i]
+		if flagged[info.PackageBase] {
+			continue // split packages share the flag of their base
+		}
+
+		flagged[info.PackageBase] = true
+
+		if info.OutOfDate != 0 {
+			logger.Warnln(gotext.Get("%s is already flagged out-of-date since %s",
+				text.Cyan(info.PackageBase), text.FormatTime(info.OutOfDate)))
+
+			continue
+		}
+
+		logger.OperationInfoln(text.Bold(info.PackageBase))
+		printInfoValue(logger, gotext.Get("Packaged Version"), info.Version)
+		printInfoValue(logger, gotext.Get("Upstream URL"), info.URL)
+
+		pkgComment := comment
+		if pkgComment == "" {
+			logger.Infoln(gotext.Get("Comment for the maintainer, e.g. the new upstream version:"))
+
+			pkgComment, err = logger.GetInput("", settings.NoConfirm)
+			if err != nil {
+				return err
+			}
+		}
+
+		pkgComment = strings.TrimSpace(pkgComment)
+		if pkgComment == "" {
+			return errors.New(gotext.Get("the AUR requires a comment to flag %s out-of-date", info.PackageBase))
+		}
+
+		printInfoValue(logger, gotext.Get("Comment"), pkgComment)
+
+		if !logger.ContinueTask(gotext.Get("Flag %s out-of-date?", info.PackageBase), true, settings.NoConfirm) {
+			continue
+		}
+
+		if err := webClient.Flag(ctx, info.PackageBase, pkgComment); err != nil {
+			if errors.Is(err, aurweb.ErrNoCredentials) {
+				return errNoAURSession(err, webClient)
+			}
+
+			return &ErrAURFlag{inner: err, pkgName: info.Name}
+		}
+	}
+
+	return nil
+}
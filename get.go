@@ -11,22 +11,55 @@ import (
 	"github.com/leonelquinteros/gotext"
 
 	"github.com/Jguer/yippee/v12/pkg/download"
+	"github.com/Jguer/yippee/v12/pkg/pkgbuild"
+	"github.com/Jguer/yippee/v12/pkg/query"
 	"github.com/Jguer/yippee/v12/pkg/runtime"
+	"github.com/Jguer/yippee/v12/pkg/settings/exe"
 	"github.com/Jguer/yippee/v12/pkg/settings/parser"
 	"github.com/Jguer/yippee/v12/pkg/text"
 )
 
-// yippee -Gp.
-func printPkgbuilds(dbExecutor download.DBSearcher, aurClient aur.QueryClient,
-	httpClient *http.Client, logger *text.Logger, targets []string,
-	mode parser.TargetMode, aurURL string,
+// yippee -Y --refresh-pkgbuilds.
+func refreshPkgbuilds(ctx context.Context, run *runtime.Runtime) error {
+	changed, err := download.RefreshRepos(ctx, run.CmdBuilder, run.Cfg.BuildDir)
+	if err != nil {
+		run.Logger.Errorln(err)
+	}
+
+	if len(changed) == 0 {
+		run.Logger.Println(gotext.Get("No PKGBUILD repository changed."))
+		return err
+	}
+
+	run.Logger.Printf("%s %s\n", text.Bold(text.Cyan("::")),
+		text.Bold(gotext.GetN("%d PKGBUILD repository changed:", "%d PKGBUILD repositories changed:",
+			len(changed), len(changed))))
+
+	for _, repo := range changed {
+		left, right := query.GetVersionDiff(repo.OldVersion, repo.NewVersion)
+		run.Logger.Printf("%s  %s -> %s\n", text.Bold(repo.Base), left, right)
+	}
+
+	return err
+}
+
+// yippee -Gp. With summary set only the summary of each PKGBUILD is printed.
+func printPkgbuilds(ctx context.Context, dbExecutor download.DBSearcher, aurClient aur.QueryClient,
+	misses *download.MissCache, httpClient *http.Client, cmdBuilder exe.GitCmdBuilder, logger *text.Logger, targets []string,
+	mode parser.TargetMode, aurURL string, preferGit, summary bool,
 ) error {
-	pkgbuilds, err := download.PKGBUILDs(dbExecutor, aurClient, httpClient, logger, targets, aurURL, mode)
+	pkgbuilds, err := download.PKGBUILDs(ctx, dbExecutor, aurClient, misses, httpClient, cmdBuilder, logger,
+		targets, aurURL, mode, preferGit)
 	if err != nil {
 		logger.Errorln(err)
 	}
 
 	for target, pkgbuild := range pkgbuilds {
+		if summary {
+			printPkgbuildSummary(logger, target, string(pkgbuild))
+			continue
+		}
+
 		logger.Printf("\n\n# %s\n\n%s", target, string(pkgbuild))
 	}
 
@@ -47,6 +80,20 @@ func printPkgbuilds(dbExecutor download.DBSearcher, aurClient aur.QueryClient,
 	return nil
 }
 
+// printPkgbuildSummary prints the version, dependencies, sources and checksum
+// algorithms of a PKGBUILD, for a quick review without reading the file.
+func printPkgbuildSummary(logger *text.Logger, target, content string) {
+	summary := pkgbuild.Summarize(content)
+
+	printInfoValue(logger, gotext.Get("Name"), target)
+	printInfoValue(logger, gotext.Get("Version"), summary.Version)
+	printInfoValue(logger, gotext.Get("Depends On"), summary.Depends...)
+	printInfoValue(logger, gotext.Get("Make Deps"), summary.MakeDepends...)
+	printInfoValue(logger, gotext.Get("Sources"), summary.Sources...)
+	printInfoValue(logger, gotext.Get("Checksums"), summary.Checksums...)
+	logger.Println()
+}
+
 // yippee -G.
 func getPkgbuilds(ctx context.Context, dbExecutor download.DBSearcher, aurClient aur.QueryClient,
 	run *runtime.Runtime, targets []string, force bool,
@@ -56,8 +103,10 @@ func getPkgbuilds(ctx context.Context, dbExecutor download.DBSearcher, aurClient
 		return err
 	}
 
-	cloned, errD := download.PKGBUILDRepos(ctx, dbExecutor, aurClient,
-		run.CmdBuilder, run.Logger, targets, run.Cfg.Mode, run.Cfg.AURURL, wd, force)
+	snapshots := &download.SnapshotSource{HTTPClient: run.HTTPClient, Prefer: run.Cfg.PKGBUILDClone == "snapshot"}
+
+	cloned, errD := download.PKGBUILDRepos(ctx, dbExecutor, aurClient, run.AURMisses,
+		run.CmdBuilder, run.Logger, run.Events, snapshots, targets, run.Cfg.Mode, run.Cfg.AURURL, wd, force, run.Cfg.ShallowClone)
 	if errD != nil {
 		run.Logger.Errorln(errD)
 	}
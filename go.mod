@@ -3,12 +3,12 @@ module github.com/Jguer/yippee/v12
 require (
 	github.com/Jguer/aur v1.2.3
 	github.com/Jguer/go-alpm/v2 v2.2.2
-	github.com/Jguer/votar v1.0.0
 	github.com/Morganamilo/go-pacmanconf v0.0.0-20210502114700-cff030e927a5
 	github.com/Morganamilo/go-srcinfo v1.0.0
 	github.com/adrg/strutil v0.3.1
 	github.com/bradleyjkemp/cupaloy v2.3.0+incompatible
 	github.com/deckarep/golang-set/v2 v2.6.0
+	github.com/godbus/dbus/v5 v5.1.0
 	github.com/hashicorp/go-multierror v1.1.1
 	github.com/leonelquinteros/gotext v1.5.2
 	github.com/stretchr/testify v1.9.0
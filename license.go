@@ -0,0 +1,91 @@
+package main
+
+import (
+	"os"
+	"strings"
+
+	mapset "github.com/deckarep/golang-set/v2"
+	"github.com/leonelquinteros/gotext"
+)
+
+// spdxLicenseDir holds the texts of the licenses package, one per SPDX
+// identifier.
+const spdxLicenseDir = "/usr/share/licenses/spdx"
+
+// readSPDXLicenses returns the lowercased SPDX identifiers with a text in dir,
+// nil when the licenses package is not installed.
+func readSPDXLicenses(dir string) mapset.Set[string] {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	known := mapset.NewThreadUnsafeSet[string]()
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			known.Add(strings.ToLower(strings.TrimSuffix(entry.Name(), ".txt")))
+		}
+	}
+
+	return known
+}
+
+// licenseIdentifiers returns the licenses of an SPDX license expression such
+// as "MIT OR (Apache-2.0 WITH LLVM-exception)", without the exceptions.
+func licenseIdentifiers(expression string) []string {
+	fields := strings.Fields(strings.NewReplacer("(", " ", ")", " ").Replace(expression))
+	identifiers := make([]string, 0, len(fields))
+
+	for i := 0; i < len(fields); i++ {
+		switch fields[i] {
+		case "AND", "OR":
+			continue
+		case "WITH":
+			i++ // the exception applies to the previous license
+			continue
+		}
+
+		identifiers = append(identifiers, strings.TrimSuffix(fields[i], "+"))
+	}
+
+	return identifiers
+}
+
+// isCustomLicense reports whether identifier names a license of the package
+// itself rather than a well known one.
+func isCustomLicense(identifier string) bool {
+	return identifier == "custom" || strings.HasPrefix(identifier, "custom:") ||
+		strings.HasPrefix(identifier, "LicenseRef-")
+}
+
+// annotateLicenses marks the licenses of a package that are custom, or that
+// are not SPDX identifiers when known is not empty, the AUR asks for SPDX
+// identifiers.
+func annotateLicenses(licenses []string, known mapset.Set[string]) []string {
+	annotated := make([]string, 0, len(licenses))
+
+	for _, license := range licenses {
+		custom, unknown := false, false
+
+		for _, identifier := range licenseIdentifiers(license) {
+			switch {
+			case isCustomLicense(identifier):
+				custom = true
+			case known != nil && known.Cardinality() > 0 && !known.Contains(strings.ToLower(identifier)):
+				unknown = true
+			}
+		}
+
+		switch {
+		case unknown:
+			license = gotext.Get("%s (not an SPDX identifier)", license)
+		case custom:
+			license = gotext.Get("%s (custom)", license)
+		}
+
+		annotated = append(annotated, license)
+	}
+
+	return annotated
+}
@@ -0,0 +1,68 @@
+//go:build !integration
+// +build !integration
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	mapset "github.com/deckarep/golang-set/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnnotateLicenses(t *testing.T) {
+	t.Parallel()
+
+	known := mapset.NewThreadUnsafeSet("mit", "apache-2.0", "gpl-2.0-or-later")
+
+	testCases := []struct {
+		desc     string
+		licenses []string
+		known    mapset.Set[string]
+		want     []string
+	}{
+		{desc: "spdx", licenses: []string{"MIT"}, known: known, want: []string{"MIT"}},
+		{desc: "case insensitive", licenses: []string{"Apache-2.0", "mit"}, known: known, want: []string{"Apache-2.0", "mit"}},
+		{
+			desc: "expression", licenses: []string{"MIT OR (Apache-2.0 WITH LLVM-exception)"}, known: known,
+			want: []string{"MIT OR (Apache-2.0 WITH LLVM-exception)"},
+		},
+		{
+			desc: "custom", licenses: []string{"custom:Foo", "LicenseRef-Bar"}, known: known,
+			want: []string{"custom:Foo (custom)", "LicenseRef-Bar (custom)"},
+		},
+		{desc: "legacy name", licenses: []string{"GPL2"}, known: known, want: []string{"GPL2 (not an SPDX identifier)"}},
+		{
+			desc: "unknown wins over custom", licenses: []string{"custom AND BSD"}, known: known,
+			want: []string{"custom AND BSD (not an SPDX identifier)"},
+		},
+		{desc: "no licenses package", licenses: []string{"GPL2", "custom"}, want: []string{"GPL2", "custom (custom)"}},
+		{desc: "none", known: known, want: []string{}},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.want, annotateLicenses(tc.licenses, tc.known))
+		})
+	}
+}
+
+func TestReadSPDXLicenses(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "MIT.txt"), nil, 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "GPL-2.0-or-later.txt"), nil, 0o644))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "exceptions"), 0o755))
+
+	known := readSPDXLicenses(dir)
+	assert.ElementsMatch(t, []string{"mit", "gpl-2.0-or-later"}, known.ToSlice())
+
+	assert.Nil(t, readSPDXLicenses(filepath.Join(dir, "missing")))
+}
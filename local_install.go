@@ -60,7 +60,7 @@ func installLocalPKGBUILD(
 	dbExecutor db.Executor,
 ) error {
 	aurCache := run.AURClient
-	noCheck := strings.Contains(run.Cfg.MFlags, "--nocheck")
+	noCheck := !exe.MakepkgRunsCheck(strings.Fields(run.Cfg.MFlags), run.Cfg.MakepkgConf, run.Cfg.MakepkgConfExtra)
 
 	if len(cmdArgs.Targets) < 1 {
 		return errors.New(gotext.Get("no target directories specified"))
@@ -80,10 +80,14 @@ func installLocalPKGBUILD(
 		srcInfos[targetDir] = pkgbuild
 	}
 
+	doneResolution := run.Tracer.Start(gotext.Get("resolution"))
 	grapher := dep.NewGrapher(dbExecutor, aurCache, false, settings.NoConfirm,
 		cmdArgs.ExistsDouble("d", "nodeps"), noCheck, cmdArgs.ExistsArg("needed"),
 		run.Logger.Child("grapher"))
+	grapher.SetProviderChoices(run.Providers)
+	grapher.SetProviderPolicy(dep.ProviderPolicy(run.Cfg.ProviderPolicy))
 	graph, err := grapher.GraphFromSrcInfos(ctx, nil, srcInfos)
+	doneResolution()
 	if err != nil {
 		return err
 	}
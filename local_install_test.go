@@ -13,6 +13,7 @@ import (
 	"strings"
 	"sync"
 	"testing"
+	"testing/iotest"
 
 	aur "github.com/Jguer/aur"
 	"github.com/stretchr/testify/assert"
@@ -32,6 +33,19 @@ func newTestLogger() *text.Logger {
 	return text.NewLogger(io.Discard, io.Discard, strings.NewReader(""), true, "test")
 }
 
+// withoutBaseDevel makes base-devel neither a group nor a package of the test
+// databases, so the build preflight has nothing to add.
+func withoutBaseDevel(db *mock.DBExecutor) {
+	db.PackagesFromGroupFn = func(string) []mock.IPackage { return nil }
+	db.SyncPackageFn = func(string) mock.IPackage { return nil }
+}
+
+// withoutLocalPackages leaves the local database empty, so nothing installed
+// conflicts with the AUR packages.
+func withoutLocalPackages(db *mock.DBExecutor) {
+	db.LocalPackagesFn = func() []mock.IPackage { return nil }
+}
+
 func TestIntegrationLocalInstall(t *testing.T) {
 	makepkgBin := t.TempDir() + "/makepkg"
 	pacmanBin := t.TempDir() + "/pacman"
@@ -148,6 +162,8 @@ func TestIntegrationLocalInstall(t *testing.T) {
 		LocalPackageFn:                func(s string) mock.IPackage { return nil },
 		InstalledRemotePackageNamesFn: func() []string { return []string{} },
 	}
+	withoutBaseDevel(db)
+	withoutLocalPackages(db)
 
 	run := &runtime.Runtime{
 		Cfg: &settings.Configuration{
@@ -181,6 +197,167 @@ func TestIntegrationLocalInstall(t *testing.T) {
 	}
 }
 
+// answerReader calls onRead for every byte of the answers read.
+type answerReader struct {
+	r      io.Reader
+	onRead func()
+}
+
+func (a *answerReader) Read(p []byte) (int, error) {
+	n, err := iotest.OneByteReader(a.r).Read(p)
+	if n > 0 {
+		a.onRead()
+	}
+
+	return n, err
+}
+
+func TestIntegrationLocalInstallConfirmUpfront(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		answer    string
+		wantAbort bool
+		wantShows int
+	}{
+		// nothing is excluded from the targets, then the installation is
+		// confirmed or not
+		{desc: "declined", answer: "\nn\n", wantAbort: true},
+		{desc: "accepted", answer: "\ny\n", wantShows: 13},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			makepkgBin := t.TempDir() + "/makepkg"
+			pacmanBin := t.TempDir() + "/pacman"
+			tmpDir := t.TempDir()
+
+			for _, bin := range []string{makepkgBin, pacmanBin} {
+				f, err := os.OpenFile(bin, os.O_RDONLY|os.O_CREATE, 0o755)
+				require.NoError(t, err)
+				require.NoError(t, f.Close())
+			}
+
+			tars := []string{
+				tmpDir + "/jellyfin-10.8.4-1-x86_64.pkg.tar.zst",
+				tmpDir + "/jellyfin-web-10.8.4-1-x86_64.pkg.tar.zst",
+				tmpDir + "/jellyfin-server-10.8.4-1-x86_64.pkg.tar.zst",
+			}
+
+			captureOverride := func(cmd *exec.Cmd) (stdout string, stderr string, err error) {
+				return strings.Join(tars, "\n"), "", nil
+			}
+
+			showOverride := func(cmd *exec.Cmd) error {
+				for _, tar := range tars {
+					f, err := os.OpenFile(tar, os.O_RDONLY|os.O_CREATE, 0o666)
+					require.NoError(t, err)
+					require.NoError(t, f.Close())
+				}
+
+				return nil
+			}
+
+			mockRunner := &exe.MockRunner{CaptureFn: captureOverride, ShowFn: showOverride}
+			cmdBuilder := &exe.CmdBuilder{
+				MakepkgBin:       makepkgBin,
+				SudoBin:          "su",
+				PacmanBin:        pacmanBin,
+				PacmanConfigPath: "/etc/pacman.conf",
+				GitBin:           "git",
+				Runner:           mockRunner,
+			}
+
+			// nothing may be downloaded or built before the last answer
+			showsBeforeAnswer := -1
+			stdin := &answerReader{r: strings.NewReader(tc.answer), onRead: func() {
+				mockRunner.ShowCallsMu.Lock()
+				showsBeforeAnswer = len(mockRunner.ShowCalls)
+				mockRunner.ShowCallsMu.Unlock()
+			}}
+
+			cmdArgs := parser.MakeArguments()
+			cmdArgs.AddArg("B")
+			cmdArgs.AddArg("i")
+			cmdArgs.AddTarget("testdata/jfin")
+
+			db := &mock.DBExecutor{
+				AlpmArchitecturesFn: func() ([]string, error) {
+					return []string{"x86_64"}, nil
+				},
+				LocalSatisfierExistsFn: func(s string) bool {
+					switch s {
+					case "dotnet-sdk>=6", "dotnet-sdk<7", "dotnet-runtime>=6", "dotnet-runtime<7", "jellyfin-server=10.8.4", "jellyfin-web=10.8.4":
+						return false
+					}
+
+					return true
+				},
+				SyncSatisfierFn: func(s string) mock.IPackage {
+					switch s {
+					case "dotnet-runtime>=6", "dotnet-runtime<7":
+						return &mock.Package{
+							PName:    "dotnet-runtime-6.0",
+							PBase:    "dotnet-runtime-6.0",
+							PVersion: "6.0.100-1",
+							PDB:      mock.NewDB("community"),
+						}
+					case "dotnet-sdk>=6", "dotnet-sdk<7":
+						return &mock.Package{
+							PName:    "dotnet-sdk-6.0",
+							PBase:    "dotnet-sdk-6.0",
+							PVersion: "6.0.100-1",
+							PDB:      mock.NewDB("community"),
+						}
+					}
+
+					return nil
+				},
+				LocalPackageFn:                func(s string) mock.IPackage { return nil },
+				InstalledRemotePackageNamesFn: func() []string { return []string{} },
+			}
+			withoutBaseDevel(db)
+			withoutLocalPackages(db)
+
+			run := &runtime.Runtime{
+				Cfg: &settings.Configuration{
+					RemoveMake:     "no",
+					ConfirmUpfront: true,
+				},
+				Logger:     text.NewLogger(io.Discard, io.Discard, stdin, true, "test"),
+				CmdBuilder: cmdBuilder,
+				VCSStore:   &vcs.Mock{},
+				AURClient: &mockaur.MockAUR{
+					GetFn: func(ctx context.Context, query *aur.Query) ([]aur.Pkg, error) {
+						return []aur.Pkg{}, nil
+					},
+				},
+			}
+
+			err := handleCmd(context.Background(), run, cmdArgs, db)
+			if tc.wantAbort {
+				require.ErrorAs(t, err, new(*settings.ErrUserAbort))
+			} else {
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, 0, showsBeforeAnswer)
+			require.Len(t, mockRunner.ShowCalls, tc.wantShows)
+
+			if tc.wantShows > 0 {
+				first := mockRunner.ShowCalls[0].Args[0].(*exec.Cmd).String()
+				assert.Contains(t, first, "--verifysource")
+
+				// the transactions were confirmed already
+				for _, call := range mockRunner.ShowCalls {
+					if show := call.Args[0].(*exec.Cmd).String(); strings.Contains(show, pacmanBin+" -S") {
+						assert.Contains(t, show, "--noconfirm")
+					}
+				}
+			}
+		})
+	}
+}
+
 func TestIntegrationLocalInstallMissingDep(t *testing.T) {
 	wantErr := ErrPackagesNotFound
 	makepkgBin := t.TempDir() + "/makepkg"
@@ -426,6 +603,8 @@ func TestIntegrationLocalInstallNeeded(t *testing.T) {
 		},
 		InstalledRemotePackageNamesFn: func() []string { return []string{} },
 	}
+	withoutBaseDevel(db)
+	withoutLocalPackages(db)
 
 	run := &runtime.Runtime{
 		Cfg: &settings.Configuration{
@@ -590,6 +769,8 @@ func TestIntegrationLocalInstallGenerateSRCINFO(t *testing.T) {
 		},
 		InstalledRemotePackageNamesFn: func() []string { return []string{} },
 	}
+	withoutBaseDevel(db)
+	withoutLocalPackages(db)
 
 	run := &runtime.Runtime{
 		Cfg: &settings.Configuration{
@@ -853,6 +1034,8 @@ func TestIntegrationLocalInstallWithDepsProvides(t *testing.T) {
 		LocalPackageFn:                func(s string) mock.IPackage { return nil },
 		InstalledRemotePackageNamesFn: func() []string { return []string{} },
 	}
+	withoutBaseDevel(db)
+	withoutLocalPackages(db)
 
 	config := &runtime.Runtime{
 		Cfg: &settings.Configuration{
@@ -993,6 +1176,8 @@ func TestIntegrationLocalInstallTwoSrcInfosWithDeps(t *testing.T) {
 		LocalPackageFn:                func(s string) mock.IPackage { return nil },
 		InstalledRemotePackageNamesFn: func() []string { return []string{} },
 	}
+	withoutBaseDevel(db)
+	withoutLocalPackages(db)
 
 	run := &runtime.Runtime{
 		Cfg: &settings.Configuration{
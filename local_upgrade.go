@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/Jguer/aur"
+	"github.com/leonelquinteros/gotext"
+
+	"github.com/Jguer/yippee/v12/pkg/db"
+	"github.com/Jguer/yippee/v12/pkg/dep"
+	"github.com/Jguer/yippee/v12/pkg/multierror"
+	"github.com/Jguer/yippee/v12/pkg/runtime"
+	"github.com/Jguer/yippee/v12/pkg/settings"
+	"github.com/Jguer/yippee/v12/pkg/settings/exe"
+	"github.com/Jguer/yippee/v12/pkg/settings/parser"
+	"github.com/Jguer/yippee/v12/pkg/sync"
+)
+
+// parsePkgInfo reads the name, version, provides and depends of a package
+// from the .PKGINFO of its package file.
+func parsePkgInfo(pkgInfo string) *aur.Pkg {
+	pkg := &aur.Pkg{}
+
+	for _, line := range strings.Split(pkgInfo, "\n") {
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, found := strings.Cut(line, " = ")
+		if !found {
+			continue
+		}
+
+		switch key {
+		case "pkgname":
+			pkg.Name = value
+		case "pkgbase":
+			pkg.PackageBase = value
+		case "pkgver":
+			pkg.Version = value
+		case "provides":
+			pkg.Provides = append(pkg.Provides, value)
+		case "depend":
+			pkg.Depends = append(pkg.Depends, value)
+		}
+	}
+
+	return pkg
+}
+
+func readPkgInfo(ctx context.Context, cmdBuilder exe.ICmdBuilder, pkgFile string) (*aur.Pkg, error) {
+	stdout, stderr, err := cmdBuilder.Capture(exec.CommandContext(ctx, "bsdtar", "-xOqf", pkgFile, ".PKGINFO"))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w - %s", gotext.Get("unable to read package file %s", pkgFile), err, stderr)
+	}
+
+	pkg := parsePkgInfo(stdout)
+	if pkg.Name == "" {
+		return nil, errors.New(gotext.Get("unable to read package file %s", pkgFile))
+	}
+
+	return pkg, nil
+}
+
+// installPkgFileDeps builds and installs the dependencies of the package
+// files given to -U that only the AUR provides, pacman then installs the
+// files. Remote targets are left to pacman.
+func installPkgFileDeps(ctx context.Context,
+	run *runtime.Runtime, cmdArgs *parser.Arguments, dbExecutor db.Executor,
+) error {
+	pkgs := make([]*aur.Pkg, 0, len(cmdArgs.Targets))
+
+	for _, target := range cmdArgs.Targets {
+		if strings.Contains(target, "://") {
+			return nil
+		}
+
+		if _, err := os.Stat(target); err != nil {
+			return nil // pacman reports it
+		}
+
+		pkg, err := readPkgInfo(ctx, run.CmdBuilder, target)
+		if err != nil {
+			return err
+		}
+
+		pkgs = append(pkgs, pkg)
+	}
+
+	noCheck := !exe.MakepkgRunsCheck(strings.Fields(run.Cfg.MFlags), run.Cfg.MakepkgConf, run.Cfg.MakepkgConfExtra)
+
+	doneResolution := run.Tracer.Start(gotext.Get("resolution"))
+	grapher := dep.NewGrapher(dbExecutor, run.AURClient, false, settings.NoConfirm,
+		false, noCheck, cmdArgs.ExistsArg("needed"), run.Logger.Child("grapher"))
+	grapher.SetProviderChoices(run.Providers)
+	grapher.SetProviderPolicy(dep.ProviderPolicy(run.Cfg.ProviderPolicy))
+	graph := grapher.GraphFromPkgFiles(ctx, nil, pkgs)
+	doneResolution()
+
+	multiErr := &multierror.MultiError{}
+	layers := graph.TopoSortedLayerMap(func(name string, ii *dep.InstallInfo) error {
+		if ii != nil && ii.Source == dep.Missing {
+			multiErr.Add(fmt.Errorf("%w: %s %s", ErrPackagesNotFound, name, ii.Version))
+		}
+		return nil
+	})
+
+	if err := multiErr.Return(); err != nil {
+		return err
+	}
+
+	// the package files have no node info, pacman installs them
+	targets := make([]map[string]*dep.InstallInfo, 0, len(layers))
+	for _, layer := range layers {
+		for name, ii := range layer {
+			if ii == nil {
+				delete(layer, name)
+			}
+		}
+
+		if len(layer) > 0 {
+			targets = append(targets, layer)
+		}
+	}
+
+	if len(targets) == 0 {
+		return nil
+	}
+
+	run.Logger.OperationInfoln(gotext.Get("Building AUR dependencies of the package files first"))
+
+	depArgs := cmdArgs.Copy()
+	depArgs.Op = "S"
+	depArgs.ClearTargets()
+
+	opService := sync.NewOperationService(ctx, dbExecutor, run)
+
+	return opService.Run(ctx, run, depArgs, targets, []string{})
+}
@@ -0,0 +1,40 @@
+//go:build !integration
+// +build !integration
+
+package main
+
+import (
+	"testing"
+
+	"github.com/Jguer/aur"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePkgInfo(t *testing.T) {
+	t.Parallel()
+
+	const pkgInfo = `# Generated by makepkg 6.0.2
+# using fakeroot version 1.33
+pkgname = foo-bin
+pkgbase = foo
+pkgver = 1.2.3-1
+pkgdesc = Foo tool = the best
+url = https://example.org
+arch = x86_64
+provides = foo=1.2.3
+depend = glibc
+depend = libbar>=2.0
+optdepend = bash: completion
+makedepend = cmake
+`
+
+	assert.Equal(t, &aur.Pkg{
+		Name:        "foo-bin",
+		PackageBase: "foo",
+		Version:     "1.2.3-1",
+		Provides:    []string{"foo=1.2.3"},
+		Depends:     []string{"glibc", "libbar>=2.0"},
+	}, parsePkgInfo(pkgInfo))
+
+	assert.Equal(t, &aur.Pkg{}, parsePkgInfo(""))
+}
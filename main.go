@@ -4,11 +4,11 @@ import (
 	"context"
 	"errors"
 	"os"
-	"os/exec"
 	"runtime/debug"
 
 	"github.com/leonelquinteros/gotext"
 
+	"github.com/Jguer/yippee/v12/pkg/db"
 	"github.com/Jguer/yippee/v12/pkg/db/ialpm"
 	"github.com/Jguer/yippee/v12/pkg/runtime"
 	"github.com/Jguer/yippee/v12/pkg/settings"
@@ -26,15 +26,25 @@ func initGotext() {
 		localePath = envLocalePath
 	}
 
-	if lc := os.Getenv("LANGUAGE"); lc != "" {
-		gotext.Configure(localePath, lc, "yippee")
-	} else if lc := os.Getenv("LC_ALL"); lc != "" {
-		gotext.Configure(localePath, lc, "yippee")
-	} else if lc := os.Getenv("LC_MESSAGES"); lc != "" {
-		gotext.Configure(localePath, lc, "yippee")
-	} else {
-		gotext.Configure(localePath, os.Getenv("LANG"), "yippee")
+	lc := os.Getenv("LANG")
+
+	for _, env := range []string{"LANGUAGE", "LC_ALL", "LC_MESSAGES"} {
+		if value := os.Getenv(env); value != "" {
+			lc = value
+			break
+		}
 	}
+
+	gotext.Configure(localePath, lc, "yippee")
+	text.SetLanguage(lc)
+}
+
+// cleanup releases what a run holds, the libalpm handle, the log files and
+// the temporary files.
+func cleanup(run *runtime.Runtime, dbExecutor db.Executor) {
+	dbExecutor.Cleanup()
+	run.CmdBuilder.Cleanup()
+	run.Cleanup()
 }
 
 func main() {
@@ -82,6 +92,10 @@ func main() {
 
 	// Parse command line
 	if err = cfg.ParseCommandLine(cmdArgs); err != nil {
+		if errors.Is(err, settings.ErrNoOperation{}) {
+			usage(fallbackLog)
+		}
+
 		if str := err.Error(); str != "" {
 			fallbackLog.Errorln(str)
 		}
@@ -115,17 +129,21 @@ func main() {
 			fallbackLog.Errorln(str)
 		}
 
+		run.Cleanup()
+
 		ret = 1
 
 		return
 	}
 
+	dbExecutor.SetProviderChoices(run.Providers)
+
 	defer func() {
 		if rec := recover(); rec != nil {
 			fallbackLog.Errorln(rec, string(debug.Stack()))
 		}
 
-		dbExecutor.Cleanup()
+		cleanup(run, dbExecutor)
 	}()
 
 	if err = handleCmd(ctx, run, cmdArgs, dbExecutor); err != nil {
@@ -133,14 +151,6 @@ func main() {
 			fallbackLog.Errorln(str)
 		}
 
-		exitError := &exec.ExitError{}
-		if errors.As(err, &exitError) {
-			// mirror pacman exit code when applicable
-			ret = exitError.ExitCode()
-			return
-		}
-
-		// fallback
-		ret = 1
+		ret = exitCode(err)
 	}
 }
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	alpm "github.com/Jguer/go-alpm/v2"
+	mapset "github.com/deckarep/golang-set/v2"
+	"github.com/leonelquinteros/gotext"
+
+	"github.com/Jguer/yippee/v12/pkg/db"
+	"github.com/Jguer/yippee/v12/pkg/dep"
+	"github.com/Jguer/yippee/v12/pkg/runtime"
+	"github.com/Jguer/yippee/v12/pkg/settings"
+	"github.com/Jguer/yippee/v12/pkg/settings/parser"
+	"github.com/Jguer/yippee/v12/pkg/text"
+)
+
+// manifestDrift lists the differences between a package manifest and the
+// installed system.
+type manifestDrift struct {
+	// missing are manifest targets that are not installed.
+	missing []string
+	// extraneous are explicitly installed packages the manifest does not
+	// list nor provide.
+	extraneous []string
+}
+
+func (d *manifestDrift) empty() bool {
+	return len(d.missing) == 0 && len(d.extraneous) == 0
+}
+
+// manifestDiff compares the manifest targets against the local database.
+// Targets may carry a repository prefix such as aur/yippee and are satisfied
+// by any installed package providing them.
+func manifestDiff(manifest []string, dbExecutor db.Executor) manifestDrift {
+	drift := manifestDrift{missing: []string{}, extraneous: []string{}}
+	wanted := mapset.NewThreadUnsafeSet[string]()
+
+	for _, target := range manifest {
+		name := dep.ToTarget(target).Name
+		wanted.Add(name)
+
+		if !dbExecutor.LocalSatisfierExists(name) {
+			drift.missing = append(drift.missing, target)
+		}
+	}
+
+	for _, pkg := range dbExecutor.LocalPackages() {
+		if pkg.Reason() != alpm.PkgReasonExplicit || wanted.Contains(pkg.Name()) {
+			continue
+		}
+
+		provided := false
+
+		for _, provide := range dbExecutor.PackageProvides(pkg) {
+			if wanted.Contains(provide.Name) {
+				provided = true
+				break
+			}
+		}
+
+		if !provided {
+			drift.extraneous = append(drift.extraneous, pkg.Name())
+		}
+	}
+
+	return drift
+}
+
+func printManifestDrift(logger *text.Logger, drift *manifestDrift, prune bool) {
+	logger.OperationInfoln(gotext.Get("Manifest drift:"))
+
+	if len(drift.missing) > 0 {
+		logger.Println(" ", gotext.GetN("Missing package (%d):", "Missing packages (%d):",
+			len(drift.missing), len(drift.missing)),
+			text.Cyan(strings.Join(drift.missing, "  ")))
+	}
+
+	if len(drift.extraneous) > 0 {
+		logger.Println(" ", gotext.GetN("Extraneous package (%d):", "Extraneous packages (%d):",
+			len(drift.extraneous), len(drift.extraneous)),
+			text.Cyan(strings.Join(drift.extraneous, "  ")))
+
+		if !prune {
+			logger.Println(" ", gotext.Get("use %s to remove them", "--prune"))
+		}
+	}
+}
+
+// syncManifest brings the system in line with the package manifest given to
+// --sync-manifest: missing packages are installed and, with --prune,
+// explicitly installed packages not in the manifest are removed.
+func syncManifest(ctx context.Context, run *runtime.Runtime,
+	cmdArgs *parser.Arguments, dbExecutor db.Executor,
+) error {
+	path, _, _ := cmdArgs.GetArg("sync-manifest")
+
+	manifest, err := parser.ReadTargetsFile(path)
+	if err != nil {
+		return err
+	}
+
+	prune := cmdArgs.ExistsArg("prune")
+
+	drift := manifestDiff(manifest, dbExecutor)
+	if drift.empty() {
+		run.Logger.Println(gotext.Get(" there is nothing to do"))
+		return settings.ErrNothingToDo{}
+	}
+
+	printManifestDrift(run.Logger, &drift, prune)
+
+	if prune {
+		if err := cleanRemove(ctx, run.Cfg, run.CmdBuilder, cmdArgs, drift.extraneous); err != nil {
+			return err
+		}
+	}
+
+	if len(drift.missing) == 0 {
+		return nil
+	}
+
+	if prune && len(drift.extraneous) > 0 {
+		// the removal invalidated our handle
+		if err := dbExecutor.RefreshHandle(); err != nil {
+			return err
+		}
+	}
+
+	arguments := cmdArgs.CopyGlobal()
+	if err := arguments.AddArg("S", "needed"); err != nil {
+		return err
+	}
+
+	arguments.AddTarget(drift.missing...)
+
+	return syncInstall(ctx, run, arguments, dbExecutor)
+}
@@ -0,0 +1,52 @@
+//go:build !integration
+// +build !integration
+
+package main
+
+import (
+	"testing"
+
+	alpm "github.com/Jguer/go-alpm/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Jguer/yippee/v12/pkg/db/mock"
+)
+
+func TestManifestDiff(t *testing.T) {
+	t.Parallel()
+
+	local := []mock.IPackage{
+		&mock.Package{PName: "linux", PReason: alpm.PkgReasonExplicit},
+		&mock.Package{PName: "yippee-bin", PReason: alpm.PkgReasonExplicit},
+		&mock.Package{PName: "vim", PReason: alpm.PkgReasonExplicit},
+		&mock.Package{PName: "glibc", PReason: alpm.PkgReasonDepend},
+	}
+
+	dbExecutor := &mock.DBExecutor{
+		LocalPackagesFn: func() []mock.IPackage { return local },
+		LocalSatisfierExistsFn: func(name string) bool {
+			switch name {
+			case "linux", "yippee", "vim", "glibc":
+				return true
+			}
+
+			return false
+		},
+		PackageProvidesFn: func(pkg mock.IPackage) []mock.Depend {
+			if pkg.Name() == "yippee-bin" {
+				return []mock.Depend{{Name: "yippee"}}
+			}
+
+			return nil
+		},
+	}
+
+	drift := manifestDiff([]string{"linux", "aur/yippee", "glibc", "core/bash"}, dbExecutor)
+
+	assert.Equal(t, []string{"core/bash"}, drift.missing)
+	assert.Equal(t, []string{"vim"}, drift.extraneous)
+	assert.False(t, drift.empty())
+
+	drift = manifestDiff([]string{"linux", "yippee", "vim"}, dbExecutor)
+	assert.True(t, drift.empty())
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Jguer/yippee/v12/pkg/db"
+	"github.com/Jguer/yippee/v12/pkg/dep"
+	"github.com/Jguer/yippee/v12/pkg/runtime"
+	"github.com/Jguer/yippee/v12/pkg/settings"
+	"github.com/Jguer/yippee/v12/pkg/text"
+	"github.com/Jguer/yippee/v12/pkg/upgrade"
+)
+
+// updateMetrics is the update status of the system exported by -P --metrics.
+type updateMetrics struct {
+	repoUpdates  int
+	aurUpdates   int
+	develUpdates int
+	orphans      int
+	aurOrphans   int
+	timestamp    time.Time
+}
+
+// collectUpdateMetrics computes the pending upgrades the same way -Qu does,
+// always checking development packages.
+func collectUpdateMetrics(ctx context.Context, run *runtime.Runtime, dbExecutor db.Executor) (*updateMetrics, error) {
+	logger := text.NewLogger(io.Discard, os.Stderr, os.Stdin, run.Cfg.Debug, "metrics")
+	dbExecutor.SetLogger(logger.Child("db"))
+
+	cfg := *run.Cfg
+	cfg.Devel = true
+
+	upService := newQuietUpgradeService(run, dbExecutor, &cfg, logger)
+
+	graph, err := upService.GraphUpgrades(ctx, nil, false, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	metrics := &updateMetrics{
+		orphans:    len(hangingPackages(false, dbExecutor)),
+		aurOrphans: len(upService.AURWarnings.Orphans),
+		timestamp:  time.Now(),
+	}
+
+	_ = graph.ForEach(func(pkgName string, ii *dep.InstallInfo) error {
+		switch {
+		case !ii.Upgrade:
+		case ii.Source == dep.Sync:
+			metrics.repoUpdates++
+		case ii.Source == dep.AUR && ii.Devel:
+			metrics.develUpdates++
+		case ii.Source == dep.AUR:
+			metrics.aurUpdates++
+		}
+
+		return nil
+	})
+
+	return metrics, nil
+}
+
+// newQuietUpgradeService returns an upgrade service resolving upgrades like
+// -Qu does, without ever prompting.
+func newQuietUpgradeService(run *runtime.Runtime, dbExecutor db.Executor,
+	cfg *settings.Configuration, logger *text.Logger,
+) *upgrade.UpgradeService {
+	grapher := dep.NewGrapher(dbExecutor, run.AURClient, false, true,
+		false, false, false, logger.Child("grapher"))
+	upService := upgrade.NewUpgradeService(
+		grapher, run.AURClient, dbExecutor, run.VCSStore,
+		cfg, true, logger.Child("upgrade"))
+	upService.IgnoreGroups = pacmanIgnoreGroups(run)
+
+	return upService
+}
+
+// writeMetrics writes metrics in the Prometheus text exposition format read
+// by the node_exporter textfile collector.
+func writeMetrics(w io.Writer, metrics *updateMetrics) error {
+	_, err := fmt.Fprintf(w, `# HELP yippee_pending_updates Number of installed packages with a pending update.
+# TYPE yippee_pending_updates gauge
+yippee_pending_updates{source="repo"} %d
+yippee_pending_updates{source="aur"} %d
+yippee_pending_updates{source="devel"} %d
+# HELP yippee_orphan_packages Number of packages installed as dependencies that are no longer required.
+# TYPE yippee_orphan_packages gauge
+yippee_orphan_packages %d
+# HELP yippee_aur_orphaned_packages Number of installed AUR packages without a maintainer.
+# TYPE yippee_aur_orphaned_packages gauge
+yippee_aur_orphaned_packages %d
+# HELP yippee_last_run_timestamp_seconds Unix time at which the update status was computed.
+# TYPE yippee_last_run_timestamp_seconds gauge
+yippee_last_run_timestamp_seconds %d
+`, metrics.repoUpdates, metrics.aurUpdates, metrics.develUpdates,
+		metrics.orphans, metrics.aurOrphans, metrics.timestamp.Unix())
+
+	return err
+}
+
+// exportMetrics writes the update status to path. The file is replaced
+// atomically so the collector never reads a partial file.
+func exportMetrics(ctx context.Context, run *runtime.Runtime, dbExecutor db.Executor, path string) error {
+	metrics, err := collectUpdateMetrics(ctx, run, dbExecutor)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".yippee-metrics-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := writeMetrics(tmp, metrics); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
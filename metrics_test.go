@@ -0,0 +1,48 @@
+//go:build !integration
+// +build !integration
+
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteMetrics(t *testing.T) {
+	t.Parallel()
+
+	var out strings.Builder
+
+	require.NoError(t, writeMetrics(&out, &updateMetrics{
+		repoUpdates:  12,
+		aurUpdates:   3,
+		develUpdates: 1,
+		orphans:      4,
+		aurOrphans:   2,
+		timestamp:    time.Unix(1700000000, 0),
+	}))
+
+	got := out.String()
+	for _, sample := range []string{
+		"yippee_pending_updates{source=\"repo\"} 12\n",
+		"yippee_pending_updates{source=\"aur\"} 3\n",
+		"yippee_pending_updates{source=\"devel\"} 1\n",
+		"yippee_orphan_packages 4\n",
+		"yippee_aur_orphaned_packages 2\n",
+		"yippee_last_run_timestamp_seconds 1700000000\n",
+	} {
+		assert.Contains(t, got, sample)
+	}
+
+	for _, line := range strings.Split(strings.TrimSuffix(got, "\n"), "\n") {
+		if strings.HasPrefix(line, "#") {
+			fields := strings.Fields(line)
+			require.GreaterOrEqual(t, len(fields), 3, line)
+			assert.Contains(t, []string{"HELP", "TYPE"}, fields[1], line)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/leonelquinteros/gotext"
+
+	"github.com/Jguer/yippee/v12/pkg/db"
+	"github.com/Jguer/yippee/v12/pkg/news"
+	"github.com/Jguer/yippee/v12/pkg/runtime"
+	"github.com/Jguer/yippee/v12/pkg/settings"
+)
+
+// NewsOnUpgrade modes.
+const (
+	newsOnUpgradeGate = "gate"
+	newsOnUpgradeShow = "show"
+)
+
+func newsOnUpgrade(mode string) bool {
+	return mode == newsOnUpgradeGate || mode == newsOnUpgradeShow
+}
+
+// lastSysupgrade returns when the last successful sysupgrade started, the
+// zero time when unknown.
+func lastSysupgrade(path string) time.Time {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return time.Time{}
+	}
+
+	last, err := time.Parse(time.RFC3339, strings.TrimSpace(string(content)))
+	if err != nil {
+		return time.Time{}
+	}
+
+	return last
+}
+
+func recordSysupgrade(path string, now time.Time) error {
+	return os.WriteFile(path, []byte(now.UTC().Format(time.RFC3339)+"\n"), 0o644)
+}
+
+// newsGate prints the news published since the last successful sysupgrade
+// that was not read yet. In gate mode the upgrade is aborted unless the news
+// is acknowledged. News that can not be fetched does not block the upgrade.
+func newsGate(ctx context.Context, run *runtime.Runtime, dbExecutor db.Executor) error {
+	mode := run.Cfg.NewsOnUpgrade
+	if !newsOnUpgrade(mode) {
+		return nil
+	}
+
+	cutOff := lastSysupgrade(run.Cfg.SysupgradeFilePath)
+	if cutOff.IsZero() {
+		cutOff = dbExecutor.LastBuildTime()
+	}
+
+	state := news.NewReadState(run.Cfg.NewsStateFilePath)
+	if err := state.Load(); err != nil {
+		run.Logger.Warnln(err)
+	}
+
+	printed, err := news.PrintNewsFeed(ctx, run.HTTPClient, run.Logger, run.Cfg.NewsFeedURLs(), state,
+		cutOff, run.Cfg.BottomUp, false, false)
+	if err != nil {
+		run.Logger.Warnln(gotext.Get("unable to fetch news: %s", err))
+	}
+
+	if printed == 0 {
+		return nil
+	}
+
+	if mode == newsOnUpgradeGate {
+		if settings.NoConfirm {
+			run.Logger.Errorln(gotext.Get("the news above must be acknowledged before upgrading, run without --noconfirm"))
+			return settings.ErrUserAbort{}
+		}
+
+		if !run.Logger.ContinueTask(gotext.Get("Have you read the news above? Proceed with the upgrade?"),
+			false, false) {
+			return settings.ErrUserAbort{}
+		}
+	}
+
+	return state.Save()
+}
@@ -0,0 +1,108 @@
+//go:build !integration
+// +build !integration
+
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jguer/yippee/v12/pkg/db/mock"
+	"github.com/Jguer/yippee/v12/pkg/runtime"
+	"github.com/Jguer/yippee/v12/pkg/settings"
+	"github.com/Jguer/yippee/v12/pkg/text"
+)
+
+const gateFeed = `<?xml version="1.0" encoding="utf-8"?>
+<rss version="2.0"><channel><title>Arch Linux: Recent news updates</title>
+<item><title>glibc update requires manual intervention</title><description>Read this.</description><pubDate>Tue, 14 Apr 2020 16:30:30 +0000</pubDate></item>
+</channel></rss>`
+
+func TestSysupgradeRecord(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "last_sysupgrade")
+	assert.True(t, lastSysupgrade(path).IsZero())
+
+	now := time.Date(2020, 4, 14, 18, 0, 0, 0, time.UTC)
+	require.NoError(t, recordSysupgrade(path, now))
+	assert.True(t, now.Equal(lastSysupgrade(path)))
+
+	require.NoError(t, os.WriteFile(path, []byte("garbage"), 0o644))
+	assert.True(t, lastSysupgrade(path).IsZero())
+}
+
+func TestNewsGate(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, gateFeed)
+	}))
+	t.Cleanup(server.Close)
+
+	beforeNews := time.Date(2020, 4, 1, 0, 0, 0, 0, time.UTC)
+	afterNews := time.Date(2020, 4, 15, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		desc       string
+		mode       string
+		input      string
+		lastUpdate time.Time
+		wantErr    error
+		wantNews   bool
+		wantSaved  bool
+	}{
+		{desc: "off", mode: "off", lastUpdate: beforeNews},
+		{desc: "show", mode: newsOnUpgradeShow, lastUpdate: beforeNews, wantNews: true, wantSaved: true},
+		{desc: "gate accepted", mode: newsOnUpgradeGate, input: "y\n", lastUpdate: beforeNews, wantNews: true, wantSaved: true},
+		{desc: "gate refused", mode: newsOnUpgradeGate, input: "n\n", lastUpdate: beforeNews, wantErr: settings.ErrUserAbort{}, wantNews: true},
+		{desc: "gate no unread news", mode: newsOnUpgradeGate, lastUpdate: afterNews},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			stateFile := filepath.Join(dir, "news.json")
+			sysupgradeFile := filepath.Join(dir, "last_sysupgrade")
+			require.NoError(t, recordSysupgrade(sysupgradeFile, tc.lastUpdate))
+
+			var stdout strings.Builder
+
+			run := &runtime.Runtime{
+				Cfg: &settings.Configuration{
+					NewsOnUpgrade:      tc.mode,
+					NewsFeeds:          server.URL,
+					NewsStateFilePath:  stateFile,
+					SysupgradeFilePath: sysupgradeFile,
+				},
+				HTTPClient: server.Client(),
+				Logger:     text.NewLogger(&stdout, io.Discard, strings.NewReader(tc.input), false, "test"),
+			}
+
+			err := newsGate(context.Background(), run, &mock.DBExecutor{})
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, tc.wantNews, strings.Contains(stdout.String(), "glibc update requires manual intervention"))
+
+			_, errStat := os.Stat(stateFile)
+			assert.Equal(t, tc.wantSaved, errStat == nil)
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/leonelquinteros/gotext"
+
+	"github.com/Jguer/yippee/v12/pkg/db"
+	"github.com/Jguer/yippee/v12/pkg/runtime"
+	"github.com/Jguer/yippee/v12/pkg/text"
+)
+
+// handleNote keeps the rest of targets as the note on the installed foreign
+// package targets[0], removing its note when nothing follows. Without targets
+// every note is listed.
+func handleNote(run *runtime.Runtime, dbExecutor db.Executor, targets []string) error {
+	if len(targets) == 0 {
+		listNotes(run.Logger, run.Notes, dbExecutor)
+		return nil
+	}
+
+	pkg, note := targets[0], strings.TrimSpace(strings.Join(targets[1:], " "))
+
+	if note == "" {
+		if _, ok := run.Notes.Get(pkg); !ok {
+			return errors.New(gotext.Get("no note is kept on %s", pkg))
+		}
+
+		if err := run.Notes.Set(pkg, ""); err != nil {
+			return err
+		}
+
+		run.Logger.OperationInfoln(gotext.Get("Removed the note on %s", text.Cyan(pkg)))
+
+		return nil
+	}
+
+	if _, ok := dbExecutor.InstalledRemotePackages()[pkg]; !ok {
+		return errors.New(gotext.Get("%s is not an installed foreign package", pkg))
+	}
+
+	if err := run.Notes.Set(pkg, note); err != nil {
+		return err
+	}
+
+	run.Logger.OperationInfoln(gotext.Get("Kept the note on %s", text.Cyan(pkg)))
+
+	return nil
+}
+
+// listNotes prints every note by package, marking the packages no longer
+// installed.
+func listNotes(logger *text.Logger, notes *db.PackageNotes, dbExecutor db.Executor) {
+	all := notes.All()
+
+	pkgs := make([]string, 0, len(all))
+	for pkg := range all {
+		pkgs = append(pkgs, pkg)
+	}
+
+	sort.Strings(pkgs)
+
+	for _, pkg := range pkgs {
+		name := text.Bold(pkg)
+		if dbExecutor.LocalPackage(pkg) == nil {
+			name += " " + text.Red(gotext.Get("(not installed)"))
+		}
+
+		logger.Println(name + ": " + all[pkg])
+	}
+}
+
+// printNotes prints the notes kept on the packages named, on every noted
+// package when names is empty, after the package info of -Qi.
+func printNotes(logger *text.Logger, notes *db.PackageNotes, names []string) {
+	if len(names) == 0 {
+		for pkg := range notes.All() {
+			names = append(names, pkg)
+		}
+
+		sort.Strings(names)
+	}
+
+	for _, name := range names {
+		if note, ok := notes.Get(name); ok {
+			logger.Infoln(gotext.Get("Note on %s: %s", text.Cyan(name), note))
+		}
+	}
+}
+
+// exportNotes writes every note to path as JSON, to stdout for -.
+func exportNotes(run *runtime.Runtime, path string) error {
+	if path == "-" {
+		return run.Notes.Export(os.Stdout)
+	}
+
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := run.Notes.Export(out); err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
+
+// importNotes reads the notes exported to path, from stdin for -.
+func importNotes(run *runtime.Runtime, path string) error {
+	in := os.Stdin
+
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		in = file
+	}
+
+	count, err := run.Notes.Import(in)
+	if err != nil {
+		return err
+	}
+
+	run.Logger.OperationInfoln(gotext.GetN("Imported %d note", "Imported %d notes", count, count))
+
+	return nil
+}
@@ -0,0 +1,84 @@
+//go:build !integration
+// +build !integration
+
+package main
+
+import (
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jguer/yippee/v12/pkg/db"
+	"github.com/Jguer/yippee/v12/pkg/db/mock"
+	"github.com/Jguer/yippee/v12/pkg/runtime"
+	"github.com/Jguer/yippee/v12/pkg/settings"
+	"github.com/Jguer/yippee/v12/pkg/text"
+)
+
+func TestHandleNote(t *testing.T) {
+	t.Parallel()
+
+	dbExecutor := mock.NewExecutor().
+		Sync(mock.NewPackage("glibc", "2.40-1").WithDB("core")).
+		Local(mock.NewPackage("glibc", "2.40-1"), mock.NewPackage("yippee-git", "12.0.0-1")).
+		Build()
+
+	testCases := []struct {
+		desc    string
+		targets []string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			desc:    "foreign package",
+			targets: []string{"yippee-git", "built", "with", "CFLAGS=-O3"},
+			want:    map[string]string{"yippee-git": "built with CFLAGS=-O3", "zoom": "work"},
+		},
+		{
+			desc:    "repository package",
+			targets: []string{"glibc", "pinned"},
+			want:    map[string]string{"zoom": "work"},
+			wantErr: true,
+		},
+		{
+			desc:    "remove the note of a package no longer installed",
+			targets: []string{"zoom"},
+			want:    map[string]string{},
+		},
+		{
+			desc:    "remove a missing note",
+			targets: []string{"yippee-git"},
+			want:    map[string]string{"zoom": "work"},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			notes := db.NewPackageNotes(filepath.Join(t.TempDir(), "notes.json"))
+			require.NoError(t, notes.Set("zoom", "work"))
+
+			run := &runtime.Runtime{
+				Cfg:    &settings.Configuration{},
+				Logger: text.NewLogger(io.Discard, io.Discard, strings.NewReader(""), false, "test"),
+				Notes:  notes,
+			}
+
+			err := handleNote(run, dbExecutor, tc.targets)
+			if tc.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, tc.want, notes.All())
+		})
+	}
+}
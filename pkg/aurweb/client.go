@@ -0,0 +1,121 @@
+// Package aurweb acts on the AUR web interface on behalf of an account:
+// voting, flagging out-of-date and the other actions the RPC does not offer.
+package aurweb
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+const (
+	// DefaultURL is the AUR the client acts on unless told otherwise.
+	DefaultURL = "https://aur.archlinux.org"
+
+	sessionCookie = "AURSID"
+)
+
+// HTTPRequestDoer performs HTTP requests. The client expects redirects to be
+// returned rather than followed, they tell whether an action succeeded.
+type HTTPRequestDoer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Client acts on the AUR with the session of an account. The session is
+// read from the session file, or opened with the credentials and then saved
+// to the file so later runs do not need them.
+type Client struct {
+	HTTPClient  HTTPRequestDoer
+	BaseURL     string
+	UserAgent   string
+	SessionPath string
+
+	username string
+	password string
+	sid      string
+	mux      sync.Mutex
+}
+
+func NewClient(httpClient HTTPRequestDoer, baseURL, userAgent, sessionPath string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultURL
+	}
+
+	return &Client{
+		HTTPClient:  httpClient,
+		BaseURL:     strings.TrimSuffix(baseURL, "/"),
+		UserAgent:   userAgent,
+		SessionPath: sessionPath,
+	}
+}
+
+// SetCredentials sets the account used to log in when there is no session.
+func (c *Client) SetCredentials(username, password string) {
+	c.username = username
+	c.password = password
+}
+
+func (c *Client) newRequest(ctx context.Context, path, referer string, values url.Values) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.BaseURL+path, strings.NewReader(values.Encode()))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Referer", c.BaseURL+referer)
+	req.Header.Set("Origin", c.BaseURL)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+	req.Header.Set("User-Agent", c.UserAgent)
+
+	return req, nil
+}
+
+// loggedOut reports whether the AUR sent the request to the login page, as
+// it does when the session expired.
+func loggedOut(resp *http.Response) bool {
+	if resp.StatusCode != http.StatusSeeOther && resp.StatusCode != http.StatusFound {
+		return false
+	}
+
+	location, err := url.Parse(resp.Header.Get("Location"))
+
+	return err == nil && strings.HasPrefix(location.Path, "/login")
+}
+
+// post submits a form of the package page referer on the session. A session
+// that expired is opened again once.
+func (c *Client) post(ctx context.Context, path, referer string, values url.Values) (*http.Response, error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	for retried := false; ; retried = true {
+		if err := c.openSession(ctx); err != nil {
+			return nil, err
+		}
+
+		req, err := c.newRequest(ctx, path, referer, values)
+		if err != nil {
+			return nil, err
+		}
+
+		req.AddCookie(&http.Cookie{Name: sessionCookie, Value: c.sid})
+
+		resp, err := c.HTTPClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		if !loggedOut(resp) {
+			return resp, nil
+		}
+
+		resp.Body.Close()
+		c.forgetSession()
+
+		if retried {
+			return nil, ErrLoginFailed{status: resp.StatusCode}
+		}
+	}
+}
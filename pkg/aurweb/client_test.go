@@ -0,0 +1,196 @@
+//go:build !integration
+// +build !integration
+
+package aurweb
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAUR accepts the session validSID and hands it out on login.
+func fakeAUR(t *testing.T, validSID string, logins *int32) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(logins, 1)
+
+		if r.FormValue("user") == "user" && r.FormValue("passwd") == "pass" {
+			http.SetCookie(w, &http.Cookie{Name: sessionCookie, Value: validSID})
+			w.Header().Set("Location", "/packages")
+			w.WriteHeader(http.StatusSeeOther)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/pkgbase/yippee/vote", func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie(sessionCookie)
+		if err != nil || cookie.Value != validSID || r.FormValue("do_Vote") == "" {
+			w.Header().Set("Location", "/login?next=/pkgbase/yippee")
+			w.WriteHeader(http.StatusSeeOther)
+
+			return
+		}
+
+		w.Header().Set("Location", "/pkgbase/yippee")
+		w.WriteHeader(http.StatusSeeOther)
+	})
+
+	mux.HandleFunc("/pkgbase/yippee/flag", func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie(sessionCookie)
+		if err != nil || cookie.Value != validSID {
+			w.Header().Set("Location", "/login?next=/pkgbase/yippee/flag")
+			w.WriteHeader(http.StatusSeeOther)
+
+			return
+		}
+
+		// the flag form is shown again with an error without a comment
+		if r.FormValue("comments") == "" || r.FormValue("do_Flag") == "" {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		w.Header().Set("Location", "/pkgbase/yippee")
+		w.WriteHeader(http.StatusSeeOther)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func noRedirectClient() *http.Client {
+	return &http.Client{CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+}
+
+func TestClient_Vote(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc       string
+		session    string
+		perm       os.FileMode
+		user       string
+		wantErr    error
+		wantLogins int32
+		wantSaved  string
+	}{
+		{desc: "login then session saved", user: "user", wantLogins: 1, wantSaved: "valid\n"},
+		{desc: "saved session reused", session: "valid\n", perm: 0o600, wantSaved: "valid\n"},
+		{desc: "expired session logs in again", session: "expired\n", perm: 0o600, user: "user", wantLogins: 1, wantSaved: "valid\n"},
+		{desc: "expired session without credentials", session: "expired\n", perm: 0o600, wantErr: ErrNoCredentials},
+		{desc: "no session nor credentials", wantErr: ErrNoCredentials},
+		{desc: "rejected login", user: "wrong", wantErr: ErrLoginFailed{status: http.StatusOK}, wantLogins: 1},
+		{desc: "readable session", session: "valid\n", perm: 0o644, wantErr: ErrInsecureSession{}},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var logins int32
+
+			server := fakeAUR(t, "valid", &logins)
+			sessionPath := filepath.Join(t.TempDir(), "aur_session")
+
+			if tc.session != "" {
+				require.NoError(t, os.WriteFile(sessionPath, []byte(tc.session), tc.perm))
+				require.NoError(t, os.Chmod(sessionPath, tc.perm))
+			}
+
+			client := NewClient(noRedirectClient(), server.URL, "test", sessionPath)
+			client.SetCredentials(tc.user, "pass")
+
+			err := client.Vote(context.Background(), "yippee")
+
+			switch want := tc.wantErr.(type) {
+			case nil:
+				require.NoError(t, err)
+			case ErrInsecureSession:
+				assert.True(t, errors.As(err, &want))
+			default:
+				assert.ErrorIs(t, err, tc.wantErr)
+			}
+
+			assert.Equal(t, tc.wantLogins, atomic.LoadInt32(&logins))
+
+			saved, errRead := os.ReadFile(sessionPath)
+			if tc.wantSaved == "" {
+				if tc.perm != 0o644 {
+					assert.True(t, os.IsNotExist(errRead))
+				}
+
+				return
+			}
+
+			require.NoError(t, errRead)
+			assert.Equal(t, tc.wantSaved, string(saved))
+
+			info, errStat := os.Stat(sessionPath)
+			require.NoError(t, errStat)
+			assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+		})
+	}
+}
+
+func TestClient_Flag(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc    string
+		pkgbase string
+		comment string
+		wantErr error
+	}{
+		{desc: "flagged", pkgbase: "yippee", comment: "1.2.0 released"},
+		{
+			desc: "missing comment", pkgbase: "yippee",
+			wantErr: ErrActionFailed{action: "flag", pkgbase: "yippee", status: http.StatusBadRequest},
+		},
+		{
+			desc: "unknown package", pkgbase: "nope", comment: "1.2.0 released",
+			wantErr: ErrActionFailed{action: "flag", pkgbase: "nope", status: http.StatusNotFound},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var logins int32
+
+			server := fakeAUR(t, "valid", &logins)
+
+			client := NewClient(noRedirectClient(), server.URL, "test", filepath.Join(t.TempDir(), "aur_session"))
+			client.SetCredentials("user", "pass")
+
+			err := client.Flag(context.Background(), tc.pkgbase, tc.comment)
+			if tc.wantErr == nil {
+				require.NoError(t, err)
+			} else {
+				assert.Equal(t, tc.wantErr, err)
+			}
+
+			assert.Equal(t, int32(1), atomic.LoadInt32(&logins))
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package aurweb
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/leonelquinteros/gotext"
+)
+
+// ErrNoCredentials is returned when there is neither a session nor
+// credentials to open one.
+var ErrNoCredentials = errors.New(gotext.Get("no AUR session or credentials"))
+
+type ErrLoginFailed struct {
+	status int
+}
+
+func (e ErrLoginFailed) Error() string {
+	return gotext.Get("AUR login failed with status %d", e.status)
+}
+
+// ErrInsecureSession is returned when other users can read the session
+// file, anyone able to read it can act as the account.
+type ErrInsecureSession struct {
+	path string
+}
+
+func (e ErrInsecureSession) Error() string {
+	return gotext.Get("the AUR session file %s is readable by other users, run chmod 600 on it", e.path)
+}
+
+type ErrActionFailed struct {
+	action  string
+	pkgbase string
+	status  int
+}
+
+func (e ErrActionFailed) Error() string {
+	return fmt.Sprintf("%s %s: %s", e.action, e.pkgbase, gotext.Get("unexpected status %d", e.status))
+}
@@ -0,0 +1,31 @@
+package aurweb
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// Flag flags pkgbase out-of-date. The AUR requires a comment, usually the
+// new upstream version and where it was released.
+func (c *Client) Flag(ctx context.Context, pkgbase, comment string) error {
+	packagePath := "/pkgbase/" + url.PathEscape(pkgbase)
+
+	resp, err := c.post(ctx, packagePath+"/flag", packagePath, url.Values{
+		"comments": []string{comment},
+		"do_Flag":  []string{"Flag"},
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode != http.StatusSeeOther {
+		return ErrActionFailed{action: "flag", pkgbase: pkgbase, status: resp.StatusCode}
+	}
+
+	return nil
+}
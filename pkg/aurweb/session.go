@@ -0,0 +1,111 @@
+package aurweb
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// openSession makes sure there is a session, reading the session file or
+// logging in with the credentials.
+func (c *Client) openSession(ctx context.Context) error {
+	if c.sid != "" {
+		return nil
+	}
+
+	sid, err := readSession(c.SessionPath)
+	if err != nil {
+		return err
+	}
+
+	if sid != "" {
+		c.sid = sid
+		return nil
+	}
+
+	return c.login(ctx)
+}
+
+// readSession reads the session kept in path, which only the owner may be
+// able to read. A missing file is no session.
+func readSession(path string) (string, error) {
+	if path == "" {
+		return "", nil
+	}
+
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	if info.Mode().Perm()&0o077 != 0 {
+		return "", ErrInsecureSession{path: path}
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(content)), nil
+}
+
+func (c *Client) login(ctx context.Context) error {
+	if c.username == "" || c.password == "" {
+		return ErrNoCredentials
+	}
+
+	req, err := c.newRequest(ctx, "/login", "/login", url.Values{
+		"user":        []string{c.username},
+		"passwd":      []string{c.password},
+		"referer":     []string{c.BaseURL},
+		"remember_me": []string{"on"},
+		"next":        []string{"packages"},
+	})
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	for _, cookie := range resp.Cookies() {
+		if cookie.Name == sessionCookie && cookie.Value != "" {
+			c.sid = cookie.Value
+			return c.saveSession()
+		}
+	}
+
+	// a rejected login is the login page again, without a session
+	return ErrLoginFailed{status: resp.StatusCode}
+}
+
+// saveSession keeps the session for later runs, readable by the owner only.
+func (c *Client) saveSession() error {
+	if c.SessionPath == "" {
+		return nil
+	}
+
+	return os.WriteFile(c.SessionPath, []byte(c.sid+"\n"), 0o600)
+}
+
+// forgetSession drops a session the AUR no longer accepts.
+func (c *Client) forgetSession() {
+	c.sid = ""
+
+	if c.SessionPath != "" {
+		_ = os.Remove(c.SessionPath)
+	}
+}
@@ -0,0 +1,41 @@
+package aurweb
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+func (c *Client) handleVote(ctx context.Context, pkgbase string, vote bool) error {
+	action, values := "unvote", url.Values{"do_UnVote": []string{"Remove vote"}}
+	if vote {
+		action, values = "vote", url.Values{"do_Vote": []string{"Vote for this package"}}
+	}
+
+	packagePath := "/pkgbase/" + url.PathEscape(pkgbase)
+
+	resp, err := c.post(ctx, packagePath+"/"+action, packagePath, values)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode != http.StatusSeeOther {
+		return ErrActionFailed{action: action, pkgbase: pkgbase, status: resp.StatusCode}
+	}
+
+	return nil
+}
+
+// Vote votes for pkgbase.
+func (c *Client) Vote(ctx context.Context, pkgbase string) error {
+	return c.handleVote(ctx, pkgbase, true)
+}
+
+// Unvote removes the vote for pkgbase.
+func (c *Client) Unvote(ctx context.Context, pkgbase string) error {
+	return c.handleVote(ctx, pkgbase, false)
+}
@@ -50,7 +50,9 @@ func handleCmd(logger *text.Logger) error {
 		cmdArgs.ExistsDouble("d", "nodeps"), false, false,
 		run.Logger.Child("grapher"))
 
-	return graphPackage(context.Background(), grapher, cmdArgs.Targets)
+	format, _, _ := cmdArgs.GetArg("graph-format")
+
+	return graphPackage(context.Background(), grapher, cmdArgs.Targets, dep.GraphFormat(format))
 }
 
 func main() {
@@ -65,6 +67,7 @@ func graphPackage(
 	ctx context.Context,
 	grapher *dep.Grapher,
 	targets []string,
+	format dep.GraphFormat,
 ) error {
 	if len(targets) != 1 {
 		return errors.New(gotext.Get("only one target is allowed"))
@@ -75,8 +78,17 @@ func graphPackage(
 		return err
 	}
 
-	fmt.Fprintln(os.Stdout, graph.String())
-	fmt.Fprintln(os.Stdout, "\nlayers map\n", graph.TopoSortedLayerMap(nil))
+	rendered, err := dep.RenderGraph(graph, format)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(os.Stdout, rendered)
+
+	// the other formats are read by tools, which the layers would confuse
+	if format == dep.GraphDOT || format == "" {
+		fmt.Fprintln(os.Stdout, "\nlayers map\n", graph.TopoSortedLayerMap(nil))
+	}
 
 	return nil
 }
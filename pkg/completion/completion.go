@@ -3,6 +3,7 @@ package completion
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -10,10 +11,14 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/leonelquinteros/gotext"
+
 	"github.com/Jguer/yippee/v12/pkg/db"
+	"github.com/Jguer/yippee/v12/pkg/text"
 )
 
 type PkgSynchronizer interface {
@@ -24,52 +29,135 @@ type httpRequestDoer interface {
 	Do(req *http.Request) (*http.Response, error)
 }
 
-// Show provides completion info for shells.
+// Info describes the completion cache.
+type Info struct {
+	Updated time.Time
+	// Sources counts the packages of the cache by repository, AUR included.
+	Sources map[string]int
+}
+
+// Show provides completion info for shells. When the cache cannot be
+// updated the previous one is served, and the error is only printed when
+// verbose, along with the cache info.
 func Show(ctx context.Context, httpClient httpRequestDoer,
-	dbExecutor PkgSynchronizer, aurURL, completionPath string, interval int, force bool,
+	dbExecutor PkgSynchronizer, aurURL, completionPath string, interval int, force bool, logger *text.Logger,
 ) error {
-	err := Update(ctx, httpClient, dbExecutor, aurURL, completionPath, interval, force)
+	errUpdate := Update(ctx, httpClient, dbExecutor, aurURL, completionPath, interval, force)
+
+	in, err := os.Open(completionPath)
 	if err != nil {
+		if errUpdate != nil {
+			return errUpdate
+		}
+
+		return err
+	}
+	defer in.Close()
+
+	if _, err := io.Copy(os.Stdout, in); err != nil {
 		return err
 	}
 
-	in, err := os.OpenFile(completionPath, os.O_RDWR|os.O_CREATE, 0o644)
+	if logger.Level < text.LevelVerbose {
+		return nil
+	}
+
+	if errUpdate != nil {
+		logger.Verboseln(gotext.Get("unable to update the completion cache:"), errUpdate)
+	}
+
+	info, err := Stat(completionPath)
 	if err != nil {
 		return err
 	}
+
+	logger.Verboseln(gotext.Get("completion cache %s updated %s (%s)", completionPath,
+		text.FormatDateTime(info.Updated), text.FormatAge(time.Since(info.Updated))))
+
+	sources := make([]string, 0, len(info.Sources))
+	for source := range info.Sources {
+		sources = append(sources, source)
+	}
+
+	sort.Strings(sources)
+
+	for _, source := range sources {
+		logger.Verboseln(gotext.GetN("%s: %d package", "%s: %d packages",
+			info.Sources[source], source, info.Sources[source]))
+	}
+
+	return nil
+}
+
+// Stat returns the info of the completion cache at completionPath.
+func Stat(completionPath string) (*Info, error) {
+	in, err := os.Open(completionPath)
+	if err != nil {
+		return nil, err
+	}
 	defer in.Close()
 
-	_, err = io.Copy(os.Stdout, in)
+	stat, err := in.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	info := &Info{Updated: stat.ModTime(), Sources: map[string]int{}}
 
-	return err
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		if _, source, ok := strings.Cut(scanner.Text(), "\t"); ok {
+			info.Sources[source]++
+		}
+	}
+
+	return info, scanner.Err()
 }
 
-// Update updates completion cache to be used by Complete.
+// Update updates completion cache to be used by Complete. The cache is
+// written to a temporary file renamed over the previous one, so that
+// concurrent runs never read it half written, and is left as it was when
+// the AUR list cannot be fetched.
 func Update(ctx context.Context, httpClient httpRequestDoer,
 	dbExecutor PkgSynchronizer, aurURL, completionPath string, interval int, force bool,
 ) error {
 	info, err := os.Stat(completionPath)
 
 	if os.IsNotExist(err) || (interval != -1 && time.Since(info.ModTime()).Hours() >= float64(interval*24)) || force {
+		// the temporary file would otherwise land in the working directory
+		if completionPath == "" {
+			return errors.New(gotext.Get("no completion cache path set"))
+		}
+
 		errd := os.MkdirAll(filepath.Dir(completionPath), 0o755)
 		if errd != nil {
 			return errd
 		}
 
-		out, errf := os.Create(completionPath)
+		out, errf := os.CreateTemp(filepath.Dir(completionPath), filepath.Base(completionPath)+".*")
 		if errf != nil {
 			return errf
 		}
+		defer os.Remove(out.Name())
 
-		if createAURList(ctx, httpClient, aurURL, out) != nil {
-			defer os.Remove(completionPath)
+		errw := createAURList(ctx, httpClient, aurURL, out)
+		if errw == nil {
+			errw = createRepoList(dbExecutor, out)
 		}
 
-		erra := createRepoList(dbExecutor, out)
+		if errc := out.Close(); errw == nil {
+			errw = errc
+		}
 
-		out.Close()
+		if errw != nil {
+			return errw
+		}
+
+		if errc := os.Chmod(out.Name(), 0o644); errc != nil {
+			return errc
+		}
 
-		return erra
+		return os.Rename(out.Name(), completionPath)
 	}
 
 	return nil
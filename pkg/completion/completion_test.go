@@ -9,9 +9,16 @@ import (
 	"errors"
 	"io"
 	"net/http"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jguer/yippee/v12/pkg/db"
+	"github.com/Jguer/yippee/v12/pkg/db/mock"
 )
 
 const samplePackageResp = `
@@ -97,3 +104,109 @@ func Test_createAURListStatusError(t *testing.T) {
 	err := createAURList(context.Background(), doer, "https://aur.archlinux.org", out)
 	assert.EqualError(t, err, "invalid status code: 503")
 }
+
+type testSynchronizer struct {
+	pkgs []db.IPackage
+}
+
+func (s testSynchronizer) SyncPackages(...string) []db.IPackage {
+	return s.pkgs
+}
+
+func TestUpdate(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc       string
+		old        string
+		statusCode int
+		force      bool
+		want       string
+		wantErr    bool
+	}{
+		{
+			desc:       "new cache",
+			statusCode: 200,
+			want:       expectPackageCompletion + "pacman\tcore\n",
+		},
+		{
+			desc:       "fresh cache kept",
+			old:        "old\tAUR\n",
+			statusCode: 200,
+			want:       "old\tAUR\n",
+		},
+		{
+			desc:       "forced rebuild",
+			old:        "old\tAUR\n",
+			statusCode: 200,
+			force:      true,
+			want:       expectPackageCompletion + "pacman\tcore\n",
+		},
+		{
+			desc:       "failed rebuild keeps the cache",
+			old:        "old\tAUR\n",
+			statusCode: 503,
+			force:      true,
+			want:       "old\tAUR\n",
+			wantErr:    true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			completionPath := filepath.Join(dir, "completion.cache")
+
+			if tc.old != "" {
+				require.NoError(t, os.WriteFile(completionPath, []byte(tc.old), 0o644))
+			}
+
+			doer := &mockDoer{
+				t:                t,
+				wantUrl:          "https://aur.archlinux.org/packages.gz",
+				returnStatusCode: tc.statusCode,
+				returnBody:       samplePackageResp,
+			}
+			dbExecutor := testSynchronizer{pkgs: []db.IPackage{mock.NewPackage("pacman", "6.1.0-1").WithDB("core")}}
+
+			err := Update(context.Background(), doer, dbExecutor,
+				"https://aur.archlinux.org", completionPath, 7, tc.force)
+			if tc.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			got, err := os.ReadFile(completionPath)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, string(got))
+
+			entries, err := os.ReadDir(dir)
+			require.NoError(t, err)
+			assert.Len(t, entries, 1, "temporary files are removed")
+		})
+	}
+}
+
+func TestUpdateNoPath(t *testing.T) {
+	t.Parallel()
+
+	// no request is made
+	err := Update(context.Background(), nil, testSynchronizer{}, "https://aur.archlinux.org", "", 7, false)
+	assert.Error(t, err)
+}
+
+func TestStat(t *testing.T) {
+	t.Parallel()
+
+	completionPath := filepath.Join(t.TempDir(), "completion.cache")
+	require.NoError(t, os.WriteFile(completionPath, []byte(expectPackageCompletion+"pacman\tcore\nvim\textra\n"), 0o644))
+
+	info, err := Stat(completionPath)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"AUR": 8, "core": 1, "extra": 1}, info.Sources)
+	assert.WithinDuration(t, time.Now(), info.Updated, time.Minute)
+}
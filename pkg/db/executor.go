@@ -35,9 +35,17 @@ type SyncUpgrade struct {
 	Reason       alpm.PkgReason
 }
 
+// Inconsistency is a breakage of the pacman databases along with how to fix
+// it.
+type Inconsistency struct {
+	Problem string
+	Fix     string
+}
+
 type Executor interface {
 	AlpmArchitectures() ([]string, error)
 	BiggestPackages() []IPackage
+	CheckDatabases() []Inconsistency
 	Cleanup()
 	InstalledRemotePackageNames() []string
 	InstalledRemotePackages() map[string]IPackage
@@ -47,8 +55,10 @@ type Executor interface {
 	LocalPackage(string) IPackage
 	LocalPackages() []IPackage
 	LocalSatisfierExists(string) bool
+	PackageConflicts(IPackage) []Depend
 	PackageDepends(IPackage) []Depend
 	PackageGroups(IPackage) []string
+	PackageLicenses(IPackage) []string
 	PackageOptionalDepends(IPackage) []Depend
 	PackageProvides(IPackage) []Depend
 	PackagesFromGroup(string) []IPackage
@@ -62,6 +72,7 @@ type Executor interface {
 	SyncPackageFromDB(string, string) IPackage
 	SyncPackages(...string) []IPackage
 	SyncSatisfier(string) IPackage
+	SyncReplacer(string) IPackage
 	SyncSatisfierExists(string) bool
 
 	SetLogger(logger *text.Logger)
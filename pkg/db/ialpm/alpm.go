@@ -23,10 +23,12 @@ type AlpmExecutor struct {
 	syncDBsCache []alpm.IDB
 	conf         *pacmanconf.Config
 	log          *text.Logger
+	providers    *db.ProviderChoices
 
 	installedRemotePkgNames []string
 	installedRemotePkgMap   map[string]alpm.IPackage
 	installedSyncPkgNames   []string
+	replacedBy              map[string]alpm.IPackage
 }
 
 func NewExecutor(pacmanConf *pacmanconf.Config, logger *text.Logger) (*AlpmExecutor, error) {
@@ -169,14 +171,30 @@ func (ae *AlpmExecutor) questionCallback() func(question alpm.QuestionAny) {
 			return
 		}
 
-		size := 0
+		names := make([]string, 0)
 
 		_ = qp.Providers(ae.handle).ForEach(func(pkg alpm.IPackage) error {
-			size++
+			names = append(names, pkg.Name())
 			return nil
 		})
 
-		str := text.Bold(gotext.Get("There are %d providers available for %s:", size, qp.Dep()))
+		size := len(names)
+		depName := qp.Dep().Name
+
+		if name, ok := ae.providers.Get(depName); ok {
+			for i := range names {
+				if names[i] == name {
+					ae.log.OperationInfoln(gotext.Get("Using remembered provider %s for %s",
+						text.Cyan(name), text.Bold(depName)))
+					qp.SetUseIndex(i)
+
+					return
+				}
+			}
+		}
+
+		str := text.Bold(gotext.GetN("There is %d provider available for %s:",
+			"There are %d providers available for %s:", size, size, qp.Dep()))
 
 		size = 1
 
@@ -214,6 +232,7 @@ func (ae *AlpmExecutor) questionCallback() func(question alpm.QuestionAny) {
 			}
 
 			if numberBuf == "" {
+				ae.rememberProvider(depName, names[0])
 				break
 			}
 
@@ -223,18 +242,31 @@ func (ae *AlpmExecutor) questionCallback() func(question alpm.QuestionAny) {
 				continue
 			}
 
-			if num < 1 || num > size {
-				ae.log.Errorln(gotext.Get("invalid value: %d is not between %d and %d", num, 1, size))
+			if num < 1 || num >= size {
+				ae.log.Errorln(gotext.Get("invalid value: %d is not between %d and %d", num, 1, size-1))
 				continue
 			}
 
 			qp.SetUseIndex(num - 1)
+			ae.rememberProvider(depName, names[num-1])
 
 			break
 		}
 	}
 }
 
+// SetProviderChoices makes the provider menu remember the chosen providers
+// and skip the menu for dependencies answered before.
+func (ae *AlpmExecutor) SetProviderChoices(providers *db.ProviderChoices) {
+	ae.providers = providers
+}
+
+func (ae *AlpmExecutor) rememberProvider(dep, name string) {
+	if err := ae.providers.Remember(dep, name); err != nil {
+		ae.log.Warnln(gotext.Get("unable to remember provider for %s: %s", dep, err))
+	}
+}
+
 func (ae *AlpmExecutor) RefreshHandle() error {
 	if ae.handle != nil {
 		if errRelease := ae.handle.Release(); errRelease != nil {
@@ -255,6 +287,7 @@ func (ae *AlpmExecutor) RefreshHandle() error {
 	alpmSetLogCallback(alpmHandle, ae.logCallback())
 	ae.handle = alpmHandle
 	ae.syncDBsCache = nil
+	ae.replacedBy = nil
 
 	ae.syncDB, err = alpmHandle.SyncDBs()
 	if err != nil {
@@ -300,6 +333,32 @@ func (ae *AlpmExecutor) SyncSatisfier(pkgName string) alpm.IPackage {
 	return foundPkg
 }
 
+// SyncReplacer returns the sync package named pkgName, providing it or
+// listing it in its replaces, or nil if there is none.
+func (ae *AlpmExecutor) SyncReplacer(pkgName string) alpm.IPackage {
+	if pkg := ae.SyncSatisfier(pkgName); pkg != nil {
+		return pkg
+	}
+
+	if ae.replacedBy == nil {
+		ae.replacedBy = make(map[string]alpm.IPackage)
+
+		_ = ae.syncDB.ForEach(func(alpmDB alpm.IDB) error {
+			return alpmDB.PkgCache().ForEach(func(pkg alpm.IPackage) error {
+				return pkg.Replaces().ForEach(func(dep *alpm.Depend) error {
+					if _, ok := ae.replacedBy[dep.Name]; !ok {
+						ae.replacedBy[dep.Name] = pkg
+					}
+
+					return nil
+				})
+			})
+		})
+	}
+
+	return ae.replacedBy[pkgName]
+}
+
 func (ae *AlpmExecutor) PackagesFromGroup(groupName string) []alpm.IPackage {
 	groupPackages := []alpm.IPackage{}
 	_ = ae.syncDB.FindGroupPkgs(groupName).ForEach(func(pkg alpm.IPackage) error {
@@ -413,6 +472,11 @@ func (ae *AlpmExecutor) PackageDepends(pkg alpm.IPackage) []alpm.Depend {
 	return alpmPackage.Depends().Slice()
 }
 
+func (ae *AlpmExecutor) PackageConflicts(pkg alpm.IPackage) []alpm.Depend {
+	alpmPackage := pkg.(*alpm.Package)
+	return alpmPackage.Conflicts().Slice()
+}
+
 func (ae *AlpmExecutor) PackageOptionalDepends(pkg alpm.IPackage) []alpm.Depend {
 	alpmPackage := pkg.(*alpm.Package)
 	return alpmPackage.OptionalDepends().Slice()
@@ -428,6 +492,11 @@ func (ae *AlpmExecutor) PackageGroups(pkg alpm.IPackage) []string {
 	return alpmPackage.Groups().Slice()
 }
 
+func (ae *AlpmExecutor) PackageLicenses(pkg alpm.IPackage) []string {
+	alpmPackage := pkg.(*alpm.Package)
+	return alpmPackage.Licenses().Slice()
+}
+
 // upRepo gathers local packages and checks if they have new versions.
 // Output: Upgrade type package list.
 func (ae *AlpmExecutor) SyncUpgrades(enableDowngrade bool) (
@@ -506,6 +575,9 @@ func (ae *AlpmExecutor) Cleanup() {
 		if err := ae.handle.Release(); err != nil {
 			fmt.Fprintln(os.Stderr, err)
 		}
+
+		// released before a re-exec, main cleans up again if that fails
+		ae.handle = nil
 	}
 }
 
@@ -0,0 +1,126 @@
+package ialpm
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	alpm "github.com/Jguer/go-alpm/v2"
+	"github.com/leonelquinteros/gotext"
+
+	"github.com/Jguer/yippee/v12/pkg/db"
+)
+
+// localEntry is a package directory of the local database, named
+// <pkgname>-<pkgver>-<pkgrel>.
+type localEntry struct {
+	dir     string
+	name    string
+	version string
+}
+
+func parseLocalEntry(dir string) (localEntry, bool) {
+	base := filepath.Base(dir)
+
+	rel := strings.LastIndex(base, "-")
+	if rel <= 0 {
+		return localEntry{}, false
+	}
+
+	ver := strings.LastIndex(base[:rel], "-")
+	if ver <= 0 {
+		return localEntry{}, false
+	}
+
+	return localEntry{dir: dir, name: base[:ver], version: base[ver+1:]}, true
+}
+
+// CheckDatabases looks for the breakage of the pacman databases that only
+// fails once pacman installs the built packages: local packages with several
+// entries, local entries without a desc file and empty sync databases. The
+// files are only read, no lock is needed.
+func (ae *AlpmExecutor) CheckDatabases() []db.Inconsistency {
+	problems := ae.checkLocalDB(filepath.Join(ae.conf.DBPath, "local"))
+
+	for _, repo := range ae.conf.Repos {
+		path := filepath.Join(ae.conf.DBPath, "sync", repo.Name+".db")
+
+		info, err := os.Stat(path)
+		if err != nil || info.Size() != 0 {
+			continue
+		}
+
+		problems = append(problems, db.Inconsistency{
+			Problem: gotext.Get("the %s sync database is empty", repo.Name),
+			Fix:     gotext.Get("refresh the databases with pacman -Syy"),
+		})
+	}
+
+	return problems
+}
+
+func (ae *AlpmExecutor) checkLocalDB(localDir string) []db.Inconsistency {
+	entries, err := os.ReadDir(localDir)
+	if err != nil {
+		ae.log.Debugln("unable to check the local database:", err)
+		return nil
+	}
+
+	problems := make([]db.Inconsistency, 0)
+	byName := make(map[string][]localEntry)
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue // ALPM_DB_VERSION
+		}
+
+		local, ok := parseLocalEntry(filepath.Join(localDir, entry.Name()))
+		if !ok {
+			continue
+		}
+
+		if info, err := os.Stat(filepath.Join(local.dir, "desc")); err != nil || info.Size() == 0 {
+			problems = append(problems, db.Inconsistency{
+				Problem: gotext.Get("the local database entry of %s has no desc file", local.name),
+				Fix: gotext.Get("reinstall it with pacman -S --dbonly %s, or remove %s if it is not installed",
+					local.name, local.dir),
+			})
+
+			continue
+		}
+
+		byName[local.name] = append(byName[local.name], local)
+	}
+
+	names := make([]string, 0, len(byName))
+	for name := range byName {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		dupes := byName[name]
+		if len(dupes) < 2 {
+			continue
+		}
+
+		// the newest entry is the installed version
+		sort.Slice(dupes, func(i, j int) bool {
+			return alpm.VerCmp(dupes[i].version, dupes[j].version) > 0
+		})
+
+		stale := make([]string, 0, len(dupes)-1)
+		for _, dupe := range dupes[1:] {
+			stale = append(stale, dupe.dir)
+		}
+
+		problems = append(problems, db.Inconsistency{
+			Problem: gotext.Get("%s has %d entries in the local database", name, len(dupes)),
+			Fix:     gotext.Get("remove the outdated entries: %s", strings.Join(stale, " ")),
+		})
+	}
+
+	return problems
+}
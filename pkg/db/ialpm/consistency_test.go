@@ -0,0 +1,106 @@
+//go:build !integration
+// +build !integration
+
+package ialpm
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Morganamilo/go-pacmanconf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jguer/yippee/v12/pkg/db"
+	"github.com/Jguer/yippee/v12/pkg/text"
+)
+
+func TestParseLocalEntry(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		dir  string
+		want localEntry
+		ok   bool
+	}{
+		{dir: "yippee-12.0.4-1", want: localEntry{dir: "yippee-12.0.4-1", name: "yippee", version: "12.0.4-1"}, ok: true},
+		{
+			dir:  "python-foo-bar-1:2.0.r3.gabc-2",
+			want: localEntry{dir: "python-foo-bar-1:2.0.r3.gabc-2", name: "python-foo-bar", version: "1:2.0.r3.gabc-2"},
+			ok:   true,
+		},
+		{dir: "ALPM_DB_VERSION"},
+		{dir: "broken-1"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.dir, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := parseLocalEntry(tc.dir)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestAlpmExecutor_CheckDatabases(t *testing.T) {
+	t.Parallel()
+
+	dbPath := t.TempDir()
+	local := filepath.Join(dbPath, "local")
+
+	for dir, desc := range map[string]string{
+		"foo-1.0-1":   "%NAME%\nfoo\n",
+		"foo-1.1-1":   "%NAME%\nfoo\n",
+		"bar-2.0-1":   "",
+		"baz-3.0-1":   "%NAME%\nbaz\n",
+		"empty-1.0-1": "-",
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Join(local, dir), 0o755))
+
+		switch desc {
+		case "-":
+			require.NoError(t, os.WriteFile(filepath.Join(local, dir, "desc"), nil, 0o644))
+		case "":
+		default:
+			require.NoError(t, os.WriteFile(filepath.Join(local, dir, "desc"), []byte(desc), 0o644))
+		}
+	}
+
+	require.NoError(t, os.WriteFile(filepath.Join(local, "ALPM_DB_VERSION"), []byte("9\n"), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dbPath, "sync"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dbPath, "sync", "core.db"), []byte("db"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dbPath, "sync", "extra.db"), nil, 0o644))
+
+	ae := &AlpmExecutor{
+		conf: &pacmanconf.Config{
+			DBPath: dbPath,
+			Repos:  []pacmanconf.Repository{{Name: "core"}, {Name: "extra"}, {Name: "multilib"}},
+		},
+		log: text.NewLogger(io.Discard, io.Discard, strings.NewReader(""), false, "test"),
+	}
+
+	problems := ae.CheckDatabases()
+
+	got := make([]string, 0, len(problems))
+	for _, problem := range problems {
+		got = append(got, problem.Problem)
+	}
+
+	assert.ElementsMatch(t, []string{
+		"the local database entry of bar has no desc file",
+		"the local database entry of empty has no desc file",
+		"foo has 2 entries in the local database",
+		"the extra sync database is empty",
+	}, got)
+
+	assert.Contains(t, problems, db.Inconsistency{
+		Problem: "foo has 2 entries in the local database",
+		Fix:     "remove the outdated entries: " + filepath.Join(local, "foo-1.0-1"),
+	})
+}
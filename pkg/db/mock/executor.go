@@ -18,6 +18,14 @@ type (
 type DBExecutor struct {
 	db.Executor
 	AlpmArchitecturesFn           func() ([]string, error)
+	BiggestPackagesFn             func() []IPackage
+	CheckDatabasesFn              func() []db.Inconsistency
+	InstalledSyncPackageNamesFn   func() []string
+	LastBuildTimeFn               func() time.Time
+	PackageConflictsFn            func(IPackage) []Depend
+	PackageGroupsFn               func(IPackage) []string
+	PackageLicensesFn             func(IPackage) []string
+	SyncPackageFromDBFn           func(string, string) IPackage
 	InstalledRemotePackageNamesFn func() []string
 	InstalledRemotePackagesFn     func() map[string]IPackage
 	IsCorrectVersionInstalledFn   func(string, string) bool
@@ -34,6 +42,7 @@ type DBExecutor struct {
 	SyncPackageFn                 func(string) IPackage
 	SyncPackagesFn                func(...string) []IPackage
 	SyncSatisfierFn               func(string) IPackage
+	SyncReplacerFn                func(string) IPackage
 	SatisfierFromDBFn             func(string, string) (IPackage, error)
 	SyncUpgradesFn                func(bool) (map[string]db.SyncUpgrade, error)
 	SetLoggerFn                   func(*text.Logger)
@@ -61,13 +70,32 @@ func (t *DBExecutor) AlpmArchitectures() ([]string, error) {
 }
 
 func (t *DBExecutor) BiggestPackages() []IPackage {
+	if t.BiggestPackagesFn != nil {
+		return t.BiggestPackagesFn()
+	}
 	panic("implement me")
 }
 
+// CheckDatabases reports consistent databases unless CheckDatabasesFn is set.
+func (t *DBExecutor) CheckDatabases() []db.Inconsistency {
+	if t.CheckDatabasesFn != nil {
+		return t.CheckDatabasesFn()
+	}
+
+	return nil
+}
+
 func (t *DBExecutor) Cleanup() {
 	panic("implement me")
 }
 
+func (t *DBExecutor) InstalledSyncPackageNames() []string {
+	if t.InstalledSyncPackageNamesFn != nil {
+		return t.InstalledSyncPackageNamesFn()
+	}
+	panic("implement me")
+}
+
 func (t *DBExecutor) IsCorrectVersionInstalled(s, s2 string) bool {
 	if t.IsCorrectVersionInstalledFn != nil {
 		return t.IsCorrectVersionInstalledFn(s, s2)
@@ -76,6 +104,9 @@ func (t *DBExecutor) IsCorrectVersionInstalled(s, s2 string) bool {
 }
 
 func (t *DBExecutor) LastBuildTime() time.Time {
+	if t.LastBuildTimeFn != nil {
+		return t.LastBuildTimeFn()
+	}
 	panic("implement me")
 }
 
@@ -103,6 +134,10 @@ func (t *DBExecutor) LocalSatisfierExists(s string) bool {
 }
 
 func (t *DBExecutor) PackageConflicts(iPackage IPackage) []Depend {
+	if t.PackageConflictsFn != nil {
+		return t.PackageConflictsFn(iPackage)
+	}
+
 	panic("implement me")
 }
 
@@ -115,6 +150,18 @@ func (t *DBExecutor) PackageDepends(iPackage IPackage) []Depend {
 }
 
 func (t *DBExecutor) PackageGroups(iPackage IPackage) []string {
+	if t.PackageGroupsFn != nil {
+		return t.PackageGroupsFn(iPackage)
+	}
+
+	return []string{}
+}
+
+func (t *DBExecutor) PackageLicenses(iPackage IPackage) []string {
+	if t.PackageLicensesFn != nil {
+		return t.PackageLicensesFn(iPackage)
+	}
+
 	return []string{}
 }
 
@@ -184,6 +231,13 @@ func (t *DBExecutor) SyncPackage(s string) IPackage {
 	panic("implement me")
 }
 
+func (t *DBExecutor) SyncPackageFromDB(s, s2 string) IPackage {
+	if t.SyncPackageFromDBFn != nil {
+		return t.SyncPackageFromDBFn(s, s2)
+	}
+	panic("implement me")
+}
+
 func (t *DBExecutor) SyncPackages(s ...string) []IPackage {
 	if t.SyncPackagesFn != nil {
 		return t.SyncPackagesFn(s...)
@@ -198,6 +252,13 @@ func (t *DBExecutor) SyncSatisfier(s string) IPackage {
 	panic("implement me")
 }
 
+func (t *DBExecutor) SyncReplacer(s string) IPackage {
+	if t.SyncReplacerFn != nil {
+		return t.SyncReplacerFn(s)
+	}
+	panic("implement me")
+}
+
 func (t *DBExecutor) SyncSatisfierExists(s string) bool {
 	if t.SyncSatisfierFn != nil {
 		return t.SyncSatisfierFn(s) != nil
@@ -0,0 +1,450 @@
+// Package mock provides an in-memory db.Executor for tests. Packages are
+// described with NewPackage and served with NewExecutor:
+//
+//	dbExecutor := mock.NewExecutor().
+//		Local(mock.NewPackage("libfoo", "1.0-1")).
+//		Sync(mock.NewPackage("libfoo", "1.1-1").WithDB("core")).
+//		Build()
+//
+// Fields of the returned DBExecutor can be replaced afterwards to script
+// single calls.
+package mock
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	alpm "github.com/Jguer/go-alpm/v2"
+
+	"github.com/Jguer/yippee/v12/pkg/db"
+	"github.com/Jguer/yippee/v12/pkg/text"
+)
+
+// NewPackage returns a package fixture installed explicitly. The With
+// methods fill in the rest and can be chained:
+//
+//	mock.NewPackage("yippee", "12.0.0-1").WithDB("extra").WithDepends("pacman>6.1")
+func NewPackage(name, version string) *Package {
+	return &Package{
+		PName:    name,
+		PBase:    name,
+		PVersion: version,
+		PReason:  alpm.PkgReasonExplicit,
+	}
+}
+
+// WithBase sets the package base.
+func (p *Package) WithBase(base string) *Package {
+	p.PBase = base
+	return p
+}
+
+// WithDB sets the name of the database the package belongs to.
+func (p *Package) WithDB(name string) *Package {
+	p.PDB = NewDB(name)
+	return p
+}
+
+// WithDepends sets the dependencies, given in PKGBUILD syntax.
+func (p *Package) WithDepends(depends ...string) *Package {
+	p.PDepends = ParseDepends(depends...)
+	return p
+}
+
+// WithOptionalDepends sets the optional dependencies, given in PKGBUILD syntax.
+func (p *Package) WithOptionalDepends(depends ...string) *Package {
+	p.POptionalDepends = ParseDepends(depends...)
+	return p
+}
+
+// WithProvides sets the provides, given in PKGBUILD syntax.
+func (p *Package) WithProvides(provides ...string) *Package {
+	p.PProvides = ParseDepends(provides...)
+	return p
+}
+
+// WithConflicts sets the conflicts, given in PKGBUILD syntax.
+func (p *Package) WithConflicts(conflicts ...string) *Package {
+	p.PConflicts = ParseDepends(conflicts...)
+	return p
+}
+
+// WithReplaces sets the replaces, given in PKGBUILD syntax.
+func (p *Package) WithReplaces(replaces ...string) *Package {
+	p.PReplaces = ParseDepends(replaces...)
+	return p
+}
+
+// WithGroups sets the groups of the package.
+func (p *Package) WithGroups(groups ...string) *Package {
+	p.PGroups = groups
+	return p
+}
+
+// WithLicenses sets the licenses of the package.
+func (p *Package) WithLicenses(licenses ...string) *Package {
+	p.PLicenses = licenses
+	return p
+}
+
+// WithBuildDate sets the build date of the package.
+func (p *Package) WithBuildDate(date time.Time) *Package {
+	p.PBuildDate = date
+	return p
+}
+
+// WithSize sets the download and installed size of the package.
+func (p *Package) WithSize(size, isize int64) *Package {
+	p.PSize, p.PISize = size, isize
+	return p
+}
+
+// WithArch sets the architecture the package is built for.
+func (p *Package) WithArch(arch string) *Package {
+	p.PArchitecture = arch
+	return p
+}
+
+// AsDependency marks the package as installed as a dependency.
+func (p *Package) AsDependency() *Package {
+	p.PReason = alpm.PkgReasonDepend
+	return p
+}
+
+// Ignored marks the package as ignored by pacman.conf.
+func (p *Package) Ignored() *Package {
+	p.PShouldIgnore = true
+	return p
+}
+
+// ParseDepend parses a dependency in PKGBUILD syntax such as "foo>=1.0".
+func ParseDepend(depend string) Depend {
+	for _, op := range []struct {
+		str string
+		mod alpm.DepMod
+	}{
+		{">=", alpm.DepModGE},
+		{"<=", alpm.DepModLE},
+		{"=", alpm.DepModEq},
+		{">", alpm.DepModGT},
+		{"<", alpm.DepModLT},
+	} {
+		if name, version, ok := strings.Cut(depend, op.str); ok {
+			return Depend{Name: name, Version: version, Mod: op.mod}
+		}
+	}
+
+	return Depend{Name: depend, Mod: alpm.DepModAny}
+}
+
+// ParseDepends parses dependencies in PKGBUILD syntax into a dependency list.
+func ParseDepends(depends ...string) DependList {
+	list := DependList{Depends: make([]Depend, 0, len(depends))}
+	for _, depend := range depends {
+		list.Depends = append(list.Depends, ParseDepend(depend))
+	}
+
+	return list
+}
+
+// ExecutorBuilder builds a DBExecutor answering from in-memory local and
+// sync databases, for tests that do not need to script every call.
+type ExecutorBuilder struct {
+	local []*Package
+	sync  []*Package
+}
+
+// NewExecutor returns an empty ExecutorBuilder.
+func NewExecutor() *ExecutorBuilder {
+	return &ExecutorBuilder{}
+}
+
+// Local adds installed packages. Packages not found in a sync database are
+// foreign.
+func (b *ExecutorBuilder) Local(pkgs ...*Package) *ExecutorBuilder {
+	b.local = append(b.local, pkgs...)
+	return b
+}
+
+// Sync adds sync database packages. Packages without a database are put in
+// "extra".
+func (b *ExecutorBuilder) Sync(pkgs ...*Package) *ExecutorBuilder {
+	for _, pkg := range pkgs {
+		if pkg.PDB == nil {
+			pkg.PDB = NewDB("extra")
+		}
+	}
+
+	b.sync = append(b.sync, pkgs...)
+
+	return b
+}
+
+// satisfies reports whether pkg is named or provides depend.
+func satisfies(pkg *Package, depend Depend) bool {
+	if pkg.PName == depend.Name {
+		return depend.Mod == alpm.DepModAny || versionSatisfies(pkg.PVersion, depend)
+	}
+
+	for _, provide := range slice(pkg.PProvides) {
+		if provide.Name == depend.Name {
+			return depend.Mod == alpm.DepModAny || versionSatisfies(provide.Version, depend)
+		}
+	}
+
+	return false
+}
+
+func versionSatisfies(version string, depend Depend) bool {
+	if version == "" {
+		return false
+	}
+
+	cmp := db.VerCmp(version, depend.Version)
+
+	switch depend.Mod {
+	case alpm.DepModEq:
+		return cmp == 0
+	case alpm.DepModGE:
+		return cmp >= 0
+	case alpm.DepModLE:
+		return cmp <= 0
+	case alpm.DepModGT:
+		return cmp > 0
+	case alpm.DepModLT:
+		return cmp < 0
+	}
+
+	return true
+}
+
+// slice returns the dependencies in list, which may be nil.
+func slice(list alpm.IDependList) []Depend {
+	if list == nil {
+		return []Depend{}
+	}
+
+	return list.Slice()
+}
+
+func findPackage(pkgs []*Package, match func(*Package) bool) IPackage {
+	for _, pkg := range pkgs {
+		if match(pkg) {
+			return pkg
+		}
+	}
+
+	return nil
+}
+
+func toIPackages(pkgs []*Package) []IPackage {
+	ipkgs := make([]IPackage, 0, len(pkgs))
+	for _, pkg := range pkgs {
+		ipkgs = append(ipkgs, pkg)
+	}
+
+	return ipkgs
+}
+
+// Build returns a DBExecutor answering from the packages added so far.
+func (b *ExecutorBuilder) Build() *DBExecutor {
+	local, sync := b.local, b.sync
+
+	synced := make(map[string]bool, len(sync))
+	repos := make([]string, 0)
+
+	for _, pkg := range sync {
+		synced[pkg.PName] = true
+
+		if name := pkg.PDB.Name(); !containsString(repos, name) {
+			repos = append(repos, name)
+		}
+	}
+
+	foreign := make(map[string]IPackage)
+	foreignNames := make([]string, 0)
+	syncNames := make([]string, 0)
+
+	for _, pkg := range local {
+		if synced[pkg.PName] {
+			syncNames = append(syncNames, pkg.PName)
+			continue
+		}
+
+		foreign[pkg.PName] = pkg
+		foreignNames = append(foreignNames, pkg.PName)
+	}
+
+	sort.Strings(foreignNames)
+	sort.Strings(syncNames)
+
+	byName := func(name string) func(*Package) bool {
+		return func(pkg *Package) bool { return pkg.PName == name }
+	}
+
+	bySatisfier := func(depend string) func(*Package) bool {
+		parsed := ParseDepend(depend)
+		return func(pkg *Package) bool { return satisfies(pkg, parsed) }
+	}
+
+	dependsOf := func(list func(*Package) alpm.IDependList) func(IPackage) []Depend {
+		return func(pkg IPackage) []Depend {
+			if p, ok := pkg.(*Package); ok {
+				return slice(list(p))
+			}
+
+			return []Depend{}
+		}
+	}
+
+	return &DBExecutor{
+		AlpmArchitecturesFn:           func() ([]string, error) { return []string{"x86_64"}, nil },
+		BiggestPackagesFn:             func() []IPackage { return biggest(local) },
+		InstalledRemotePackageNamesFn: func() []string { return foreignNames },
+		InstalledRemotePackagesFn:     func() map[string]IPackage { return foreign },
+		InstalledSyncPackageNamesFn:   func() []string { return syncNames },
+		IsCorrectVersionInstalledFn: func(name, version string) bool {
+			pkg := findPackage(local, byName(name))
+			return pkg != nil && pkg.Version() == version
+		},
+		LastBuildTimeFn: func() time.Time { return lastBuildTime(local) },
+		LocalPackageFn:  func(name string) IPackage { return findPackage(local, byName(name)) },
+		LocalPackagesFn: func() []IPackage { return toIPackages(local) },
+		LocalSatisfierExistsFn: func(depend string) bool {
+			return findPackage(local, bySatisfier(depend)) != nil
+		},
+		PackageConflictsFn:       dependsOf(func(p *Package) alpm.IDependList { return p.PConflicts }),
+		PackageDependsFn:         dependsOf(func(p *Package) alpm.IDependList { return p.PDepends }),
+		PackageOptionalDependsFn: dependsOf(func(p *Package) alpm.IDependList { return p.POptionalDepends }),
+		PackageProvidesFn:        dependsOf(func(p *Package) alpm.IDependList { return p.PProvides }),
+		PackageGroupsFn: func(pkg IPackage) []string {
+			if p, ok := pkg.(*Package); ok && p.PGroups != nil {
+				return p.PGroups
+			}
+
+			return []string{}
+		},
+		PackageLicensesFn: func(pkg IPackage) []string {
+			if p, ok := pkg.(*Package); ok && p.PLicenses != nil {
+				return p.PLicenses
+			}
+
+			return []string{}
+		},
+		PackagesFromGroupFn: func(group string) []IPackage {
+			pkgs := make([]IPackage, 0)
+			for _, pkg := range sync {
+				if containsString(pkg.PGroups, group) {
+					pkgs = append(pkgs, pkg)
+				}
+			}
+
+			return pkgs
+		},
+		PackagesFromGroupAndDBFn: func(group, dbName string) ([]IPackage, error) {
+			pkgs := make([]IPackage, 0)
+			for _, pkg := range sync {
+				if containsString(pkg.PGroups, group) && pkg.PDB.Name() == dbName {
+					pkgs = append(pkgs, pkg)
+				}
+			}
+
+			return pkgs, nil
+		},
+		RefreshHandleFn: func() error { return nil },
+		ReposFn:         func() []string { return repos },
+		SatisfierFromDBFn: func(depend, dbName string) (IPackage, error) {
+			satisfier := bySatisfier(depend)
+			return findPackage(sync, func(pkg *Package) bool {
+				return pkg.PDB.Name() == dbName && satisfier(pkg)
+			}), nil
+		},
+		SetLoggerFn:    func(*text.Logger) {},
+		SyncPackageFn:  func(name string) IPackage { return findPackage(sync, byName(name)) },
+		SyncPackagesFn: func(names ...string) []IPackage { return syncPackages(sync, names) },
+		SyncPackageFromDBFn: func(name, dbName string) IPackage {
+			return findPackage(sync, func(pkg *Package) bool {
+				return pkg.PName == name && pkg.PDB.Name() == dbName
+			})
+		},
+		SyncReplacerFn: func(name string) IPackage {
+			return findPackage(sync, func(pkg *Package) bool {
+				for _, replace := range slice(pkg.PReplaces) {
+					if replace.Name == name {
+						return true
+					}
+				}
+
+				return false
+			})
+		},
+		SyncSatisfierFn: func(depend string) IPackage { return findPackage(sync, bySatisfier(depend)) },
+		SyncUpgradesFn: func(enableDowngrade bool) (map[string]db.SyncUpgrade, error) {
+			return syncUpgrades(local, sync, enableDowngrade), nil
+		},
+	}
+}
+
+func containsString(list []string, needle string) bool {
+	for _, s := range list {
+		if s == needle {
+			return true
+		}
+	}
+
+	return false
+}
+
+func syncPackages(sync []*Package, names []string) []IPackage {
+	pkgs := make([]IPackage, 0, len(names))
+	for _, name := range names {
+		if pkg := findPackage(sync, func(p *Package) bool { return p.PName == name }); pkg != nil {
+			pkgs = append(pkgs, pkg)
+		}
+	}
+
+	return pkgs
+}
+
+// syncUpgrades returns the installed packages with a different version in
+// the first sync database that has them, like pacman -Su.
+func syncUpgrades(local, sync []*Package, enableDowngrade bool) map[string]db.SyncUpgrade {
+	ups := make(map[string]db.SyncUpgrade)
+
+	for _, localPkg := range local {
+		pkg := findPackage(sync, func(p *Package) bool { return p.PName == localPkg.PName })
+		if pkg == nil || localPkg.PShouldIgnore {
+			continue
+		}
+
+		cmp := db.VerCmp(pkg.Version(), localPkg.PVersion)
+		if cmp > 0 || (enableDowngrade && cmp < 0) {
+			ups[localPkg.PName] = db.SyncUpgrade{
+				Package:      pkg,
+				LocalVersion: localPkg.PVersion,
+				Reason:       localPkg.PReason,
+			}
+		}
+	}
+
+	return ups
+}
+
+func biggest(local []*Package) []IPackage {
+	pkgs := toIPackages(local)
+	sort.SliceStable(pkgs, func(i, j int) bool { return pkgs[i].ISize() > pkgs[j].ISize() })
+
+	return pkgs
+}
+
+func lastBuildTime(local []*Package) time.Time {
+	var last time.Time
+	for _, pkg := range local {
+		if pkg.PBuildDate.After(last) {
+			last = pkg.PBuildDate
+		}
+	}
+
+	return last
+}
@@ -0,0 +1,78 @@
+//go:build !integration
+// +build !integration
+
+package mock
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutorBuilder(t *testing.T) {
+	t.Parallel()
+
+	dbExecutor := NewExecutor().
+		Local(
+			NewPackage("libfoo", "1.0-1").WithProvides("libfoo.so=1-64"),
+			NewPackage("bar-git", "r10.abc-1").WithDepends("libfoo>=1.0", "libfoo.so"),
+			NewPackage("held", "1.0-1").Ignored(),
+		).
+		Sync(
+			NewPackage("libfoo", "1.1-1").WithDB("core").WithProvides("libfoo.so=2-64"),
+			NewPackage("held", "2.0-1"),
+			NewPackage("fonts", "1.0-1").WithGroups("xorg"),
+		).
+		Build()
+
+	assert.Equal(t, []string{"bar-git"}, dbExecutor.InstalledRemotePackageNames())
+	assert.Equal(t, []string{"held", "libfoo"}, dbExecutor.InstalledSyncPackageNames())
+	assert.Equal(t, []string{"core", "extra"}, dbExecutor.Repos())
+
+	assert.True(t, dbExecutor.LocalSatisfierExists("libfoo.so"))
+	assert.False(t, dbExecutor.LocalSatisfierExists("libfoo>1.0"))
+
+	satisfier := dbExecutor.SyncSatisfier("libfoo.so=2-64")
+	require.NotNil(t, satisfier)
+	assert.Equal(t, "1.1-1", satisfier.Version())
+
+	assert.Nil(t, dbExecutor.SyncPackageFromDB("libfoo", "extra"))
+	assert.Len(t, dbExecutor.PackagesFromGroup("xorg"), 1)
+
+	ups, err := dbExecutor.SyncUpgrades(false)
+	require.NoError(t, err)
+	require.Len(t, ups, 1)
+	assert.Equal(t, "1.0-1", ups["libfoo"].LocalVersion)
+
+	depends := dbExecutor.PackageDepends(dbExecutor.LocalPackage("bar-git"))
+	require.Len(t, depends, 2)
+	assert.Equal(t, "libfoo", depends[0].Name)
+	assert.Equal(t, "1.0", depends[0].Version)
+}
+
+func TestParseDepend(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		depend  string
+		name    string
+		version string
+	}{
+		{depend: "pacman", name: "pacman"},
+		{depend: "pacman>=6.1", name: "pacman", version: "6.1"},
+		{depend: "libalpm.so=15-64", name: "libalpm.so", version: "15-64"},
+		{depend: "glibc<2.40", name: "glibc", version: "2.40"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.depend, func(t *testing.T) {
+			t.Parallel()
+
+			depend := ParseDepend(tc.depend)
+			assert.Equal(t, tc.name, depend.Name)
+			assert.Equal(t, tc.version, depend.Version)
+		})
+	}
+}
@@ -39,6 +39,16 @@ type Package struct {
 	PReason       alpm.PkgReason
 	PDepends      alpm.IDependList
 	PProvides     alpm.IDependList
+
+	POptionalDepends alpm.IDependList
+	PConflicts       alpm.IDependList
+	PReplaces        alpm.IDependList
+	PGroups          []string
+	PLicenses        []string
+	PArchitecture    string
+	PURL             string
+	PRequiredBy      []string
+	PFiles           []alpm.File
 }
 
 func (p *Package) Base() string {
@@ -95,7 +105,7 @@ func (p *Package) Validation() alpm.Validation {
 
 // Architecture returns the package target Architecture.
 func (p *Package) Architecture() string {
-	panic("not implemented")
+	return p.PArchitecture
 }
 
 // Backup returns a list of package backups.
@@ -105,7 +115,10 @@ func (p *Package) Backup() alpm.BackupList {
 
 // Conflicts returns the conflicts of the package as a DependList.
 func (p *Package) Conflicts() alpm.IDependList {
-	panic("not implemented")
+	if p.PConflicts != nil {
+		return p.PConflicts
+	}
+	return DependList{}
 }
 
 // Depends returns the package's dependency list.
@@ -118,7 +131,10 @@ func (p *Package) Depends() alpm.IDependList {
 
 // Depends returns the package's optional dependency list.
 func (p *Package) OptionalDepends() alpm.IDependList {
-	panic("not implemented")
+	if p.POptionalDepends != nil {
+		return p.POptionalDepends
+	}
+	return DependList{}
 }
 
 // Depends returns the package's check dependency list.
@@ -133,7 +149,7 @@ func (p *Package) MakeDepends() alpm.IDependList {
 
 // Files returns the file list of the package.
 func (p *Package) Files() []alpm.File {
-	panic("not implemented")
+	return p.PFiles
 }
 
 // ContainsFile checks if the path is in the package filelist.
@@ -186,17 +202,20 @@ func (p *Package) Origin() alpm.PkgFrom {
 
 // Replaces returns a DependList with the packages this package replaces.
 func (p *Package) Replaces() alpm.IDependList {
-	panic("not implemented")
+	if p.PReplaces != nil {
+		return p.PReplaces
+	}
+	return DependList{}
 }
 
 // URL returns the upstream URL of the package.
 func (p *Package) URL() string {
-	panic("not implemented")
+	return p.PURL
 }
 
 // ComputeRequiredBy returns the names of reverse dependencies of a package.
 func (p *Package) ComputeRequiredBy() []string {
-	panic("not implemented")
+	return p.PRequiredBy
 }
 
 // ComputeOptionalFor returns the names of packages that optionally
@@ -0,0 +1,138 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// PackageNotes holds the notes kept on installed packages, e.g. why one was
+// installed or the flags it was built with. A nil *PackageNotes holds no
+// notes.
+type PackageNotes struct {
+	FilePath string
+
+	notes map[string]string
+	mux   sync.Mutex
+}
+
+func NewPackageNotes(filePath string) *PackageNotes {
+	return &PackageNotes{
+		FilePath: filePath,
+		notes:    make(map[string]string),
+	}
+}
+
+// Load reads the notes from disk. A missing file is not an error.
+func (n *PackageNotes) Load() error {
+	content, err := os.ReadFile(n.FilePath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to open notes file '%s': %w", n.FilePath, err)
+	}
+
+	n.mux.Lock()
+	defer n.mux.Unlock()
+
+	if err := json.Unmarshal(content, &n.notes); err != nil {
+		return fmt.Errorf("failed to read notes file '%s': %w", n.FilePath, err)
+	}
+
+	return nil
+}
+
+// Get returns the note kept on pkg.
+func (n *PackageNotes) Get(pkg string) (string, bool) {
+	if n == nil {
+		return "", false
+	}
+
+	n.mux.Lock()
+	defer n.mux.Unlock()
+
+	note, ok := n.notes[pkg]
+
+	return note, ok
+}
+
+// All returns a copy of every note by package.
+func (n *PackageNotes) All() map[string]string {
+	all := make(map[string]string)
+	if n == nil {
+		return all
+	}
+
+	n.mux.Lock()
+	defer n.mux.Unlock()
+
+	for pkg, note := range n.notes {
+		all[pkg] = note
+	}
+
+	return all
+}
+
+// Set keeps note on pkg and saves the notes to disk. An empty note removes
+// the one kept on pkg.
+func (n *PackageNotes) Set(pkg, note string) error {
+	n.mux.Lock()
+	defer n.mux.Unlock()
+
+	if note == "" {
+		delete(n.notes, pkg)
+	} else {
+		n.notes[pkg] = note
+	}
+
+	return n.save()
+}
+
+// Export writes every note to w as a JSON object of package names to notes.
+func (n *PackageNotes) Export(w io.Writer) error {
+	marshalled, err := json.MarshalIndent(n.All(), "", "\t")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(w, string(marshalled))
+
+	return err
+}
+
+// Import reads notes written by Export from r and saves them along with the
+// ones already kept, replacing those on the same packages. It returns how
+// many notes were read.
+func (n *PackageNotes) Import(r io.Reader) (int, error) {
+	imported := make(map[string]string)
+	if err := json.NewDecoder(r).Decode(&imported); err != nil {
+		return 0, fmt.Errorf("failed to read notes: %w", err)
+	}
+
+	n.mux.Lock()
+	defer n.mux.Unlock()
+
+	count := 0
+
+	for pkg, note := range imported {
+		if note != "" {
+			n.notes[pkg] = note
+			count++
+		}
+	}
+
+	return count, n.save()
+}
+
+func (n *PackageNotes) save() error {
+	marshalled, err := json.MarshalIndent(n.notes, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(n.FilePath, marshalled, 0o644)
+}
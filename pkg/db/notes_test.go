@@ -0,0 +1,52 @@
+//go:build !integration
+// +build !integration
+
+package db
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackageNotes(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "notes.json")
+
+	notes := NewPackageNotes(path)
+	require.NoError(t, notes.Load())
+	require.NoError(t, notes.Set("yippee-git", "built with CFLAGS=-O3"))
+	require.NoError(t, notes.Set("vosk-api", "needed by the dictation setup"))
+	require.NoError(t, notes.Set("vosk-api", ""))
+
+	loaded := NewPackageNotes(path)
+	require.NoError(t, loaded.Load())
+	assert.Equal(t, map[string]string{"yippee-git": "built with CFLAGS=-O3"}, loaded.All())
+
+	_, ok := loaded.Get("vosk-api")
+	assert.False(t, ok)
+
+	var exported strings.Builder
+	require.NoError(t, loaded.Export(&exported))
+
+	other := NewPackageNotes(filepath.Join(t.TempDir(), "notes.json"))
+	require.NoError(t, other.Set("yippee-git", "stale note"))
+	require.NoError(t, other.Set("zoom", "work"))
+
+	count, err := other.Import(strings.NewReader(exported.String()))
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, map[string]string{"yippee-git": "built with CFLAGS=-O3", "zoom": "work"}, other.All())
+
+	_, err = other.Import(strings.NewReader("not json"))
+	assert.Error(t, err)
+
+	var nilNotes *PackageNotes
+	_, ok = nilNotes.Get("yippee-git")
+	assert.False(t, ok)
+	assert.Empty(t, nilNotes.All())
+}
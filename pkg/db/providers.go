@@ -0,0 +1,97 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// ProviderChoices remembers the provider picked for each virtual dependency
+// so the provider menus are only shown once. A nil *ProviderChoices
+// remembers nothing.
+type ProviderChoices struct {
+	FilePath string
+
+	choices map[string]string
+	mux     sync.Mutex
+}
+
+func NewProviderChoices(filePath string) *ProviderChoices {
+	return &ProviderChoices{
+		FilePath: filePath,
+		choices:  make(map[string]string),
+	}
+}
+
+// Load reads the remembered choices from disk. A missing file is not an error.
+func (p *ProviderChoices) Load() error {
+	content, err := os.ReadFile(p.FilePath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to open providers file '%s': %w", p.FilePath, err)
+	}
+
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	if err := json.Unmarshal(content, &p.choices); err != nil {
+		return fmt.Errorf("failed to read providers file '%s': %w", p.FilePath, err)
+	}
+
+	return nil
+}
+
+// Get returns the provider remembered for dep.
+func (p *ProviderChoices) Get(dep string) (string, bool) {
+	if p == nil {
+		return "", false
+	}
+
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	provider, ok := p.choices[dep]
+
+	return provider, ok
+}
+
+// Remember stores provider as the choice for dep and saves it to disk.
+func (p *ProviderChoices) Remember(dep, provider string) error {
+	if p == nil {
+		return nil
+	}
+
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	if p.choices[dep] == provider {
+		return nil
+	}
+
+	p.choices[dep] = provider
+
+	marshalled, err := json.MarshalIndent(p.choices, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(p.FilePath, marshalled, 0o644)
+}
+
+// Forget drops every remembered choice and removes the file.
+func (p *ProviderChoices) Forget() error {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	p.choices = make(map[string]string)
+
+	if err := os.Remove(p.FilePath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package db
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// SyncDBRefresh is when a sync database was last refreshed, read from the
+// modification time of its file. Refreshed is zero when the database was
+// never downloaded.
+type SyncDBRefresh struct {
+	Repo      string
+	Refreshed time.Time
+}
+
+// SyncDBRefreshes returns the refresh time of the sync database of each of
+// repos in dbPath, in the order of repos.
+func SyncDBRefreshes(dbPath string, repos []string) []SyncDBRefresh {
+	refreshes := make([]SyncDBRefresh, 0, len(repos))
+
+	for _, repo := range repos {
+		refresh := SyncDBRefresh{Repo: repo}
+
+		if info, err := os.Stat(filepath.Join(dbPath, "sync", repo+".db")); err == nil {
+			refresh.Refreshed = info.ModTime()
+		}
+
+		refreshes = append(refreshes, refresh)
+	}
+
+	return refreshes
+}
+
+// StaleSyncDBs returns the databases of refreshes not refreshed since
+// maxAge before now.
+func StaleSyncDBs(refreshes []SyncDBRefresh, maxAge time.Duration, now time.Time) []SyncDBRefresh {
+	stale := make([]SyncDBRefresh, 0)
+
+	for _, refresh := range refreshes {
+		if now.Sub(refresh.Refreshed) > maxAge {
+			stale = append(stale, refresh)
+		}
+	}
+
+	return stale
+}
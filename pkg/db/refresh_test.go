@@ -0,0 +1,46 @@
+//go:build !integration
+// +build !integration
+
+package db
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncDBRefreshes(t *testing.T) {
+	t.Parallel()
+
+	dbPath := t.TempDir()
+	now := time.Now().Truncate(time.Second)
+	refreshed := map[string]time.Time{
+		"core":  now.Add(-2 * time.Hour),
+		"extra": now.Add(-10 * 24 * time.Hour),
+	}
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dbPath, "sync"), 0o755))
+
+	for repo, mtime := range refreshed {
+		path := filepath.Join(dbPath, "sync", repo+".db")
+		require.NoError(t, os.WriteFile(path, []byte("db"), 0o644))
+		require.NoError(t, os.Chtimes(path, mtime, mtime))
+	}
+
+	refreshes := SyncDBRefreshes(dbPath, []string{"core", "extra", "multilib"})
+	assert.Equal(t, []SyncDBRefresh{
+		{Repo: "core", Refreshed: refreshed["core"]},
+		{Repo: "extra", Refreshed: refreshed["extra"]},
+		{Repo: "multilib"},
+	}, refreshes)
+
+	stale := StaleSyncDBs(refreshes, 7*24*time.Hour, now)
+	assert.Equal(t, []SyncDBRefresh{
+		{Repo: "extra", Refreshed: refreshed["extra"]},
+		{Repo: "multilib"},
+	}, stale)
+}
@@ -26,6 +26,11 @@ type InstallInfo struct {
 	SrcinfoPath  *string
 	AURBase      *string
 	SyncDBName   *string
+	// LastModified is when the AUR package was last pushed, as a unix time.
+	LastModified int
+	// Arch is the architecture an AUR target was qualified with, checked
+	// against the .SRCINFO once the PKGBUILD is downloaded.
+	Arch string
 
 	IsGroup bool
 	Upgrade bool
@@ -96,6 +101,10 @@ var colorMap = map[Reason]string{
 type Grapher struct {
 	logger        *text.Logger
 	providerCache map[string][]aur.Pkg
+	providers     *db.ProviderChoices
+
+	providerPolicy ProviderPolicy
+	targetArchs    map[string]string // AUR target name to the architecture it is qualified with
 
 	dbExecutor  db.Executor
 	aurClient   aurc.QueryClient
@@ -119,10 +128,17 @@ func NewGrapher(dbExecutor db.Executor, aurCache aurc.QueryClient,
 		noCheckDeps:   noCheckDeps,
 		needed:        needed,
 		providerCache: make(map[string][]aurc.Pkg, 5),
+		targetArchs:   make(map[string]string),
 		logger:        logger,
 	}
 }
 
+// SetProviderChoices makes the provider menu remember the chosen providers
+// and skip the menu for dependencies answered before.
+func (g *Grapher) SetProviderChoices(providers *db.ProviderChoices) {
+	g.providers = providers
+}
+
 func NewGraph() *topo.Graph[string, *InstallInfo] {
 	return topo.New[string, *InstallInfo]()
 }
@@ -142,6 +158,10 @@ func (g *Grapher) GraphFromTargets(ctx context.Context,
 		switch target.DB {
 		case "": // unspecified db
 			if pkg := g.dbExecutor.SyncSatisfier(target.Name); pkg != nil {
+				if g.archMismatch(target, pkg) {
+					continue
+				}
+
 				g.GraphSyncPkg(ctx, graph, pkg, nil)
 
 				continue
@@ -158,12 +178,20 @@ func (g *Grapher) GraphFromTargets(ctx context.Context,
 			fallthrough
 		case "aur":
 			aurTargets = append(aurTargets, target.Name)
+
+			if target.Arch != "" {
+				g.targetArchs[target.Name] = target.Arch
+			}
 		default:
 			pkg, err := g.dbExecutor.SatisfierFromDB(target.Name, target.DB)
 			if err != nil {
 				return nil, err
 			}
 			if pkg != nil {
+				if g.archMismatch(target, pkg) {
+					continue
+				}
+
 				g.GraphSyncPkg(ctx, graph, pkg, nil)
 
 				continue
@@ -192,6 +220,19 @@ func (g *Grapher) GraphFromTargets(ctx context.Context,
 	return graph, nil
 }
 
+// archMismatch tells whether the repository package pkg is not built for
+// the architecture target is qualified with, warning that it is skipped.
+func (g *Grapher) archMismatch(target Target, pkg db.IPackage) bool {
+	if target.Arch == "" || pkg.Architecture() == target.Arch {
+		return false
+	}
+
+	g.logger.Warnln(gotext.Get("%s is built for %s, not %s -- skipping",
+		text.Cyan(pkg.Name()), pkg.Architecture(), target.Arch))
+
+	return true
+}
+
 func (g *Grapher) pickSrcInfoPkgs(pkgs []*aurc.Pkg) ([]*aurc.Pkg, error) {
 	final := make([]*aurc.Pkg, 0, len(pkgs))
 	for i := range pkgs {
@@ -243,10 +284,10 @@ func (g *Grapher) GraphFromSrcInfos(ctx context.Context, graph *topo.Graph[strin
 		graph = NewGraph()
 	}
 
-	aurPkgsAdded := []*aurc.Pkg{}
-	for pkgBuildDir, pkgbuild := range srcInfos {
-		pkgBuildDir := pkgBuildDir
+	chosen := []*aurc.Pkg{}
+	pkgBuildDirs := make(map[*aurc.Pkg]string)
 
+	for pkgBuildDir, pkgbuild := range srcInfos {
 		aurPkgs, err := makeAURPKGFromSrcinfo(g.dbExecutor, pkgbuild)
 		if err != nil {
 			return nil, err
@@ -260,32 +301,48 @@ func (g *Grapher) GraphFromSrcInfos(ctx context.Context, graph *topo.Graph[strin
 			}
 		}
 
+		// a base is only skipped when none of its packages need installing,
+		// the installer skips the up to date packages of the others
+		if g.allUpToDate(aurPkgs) {
+			for _, pkg := range aurPkgs {
+				g.warnUpToDate(pkg)
+			}
+
+			continue
+		}
+
 		for _, pkg := range aurPkgs {
-			pkg := pkg
+			pkgBuildDirs[pkg] = pkgBuildDir
+		}
 
-			reason := Explicit
-			if pkg := g.dbExecutor.LocalPackage(pkg.Name); pkg != nil {
-				reason = Reason(pkg.Reason())
-			}
+		chosen = append(chosen, aurPkgs...)
+	}
 
-			graph.AddNode(pkg.Name)
+	aurPkgsAdded := g.resolveTargetConflicts(chosen)
 
-			g.addAurPkgProvides(pkg, graph)
+	for _, pkg := range aurPkgsAdded {
+		pkgBuildDir := pkgBuildDirs[pkg]
 
-			g.ValidateAndSetNodeInfo(graph, pkg.Name, &topo.NodeInfo[*InstallInfo]{
-				Color:      colorMap[reason],
-				Background: bgColorMap[AUR],
-				Value: &InstallInfo{
-					Source:      SrcInfo,
-					Reason:      reason,
-					SrcinfoPath: &pkgBuildDir,
-					AURBase:     &pkg.PackageBase,
-					Version:     pkg.Version,
-				},
-			})
+		reason := Explicit
+		if pkg := g.dbExecutor.LocalPackage(pkg.Name); pkg != nil {
+			reason = Reason(pkg.Reason())
 		}
 
-		aurPkgsAdded = append(aurPkgsAdded, aurPkgs...)
+		graph.AddNode(pkg.Name)
+
+		g.addAurPkgProvides(pkg, graph)
+
+		g.ValidateAndSetNodeInfo(graph, pkg.Name, &topo.NodeInfo[*InstallInfo]{
+			Color:      colorMap[reason],
+			Background: bgColorMap[AUR],
+			Value: &InstallInfo{
+				Source:      SrcInfo,
+				Reason:      reason,
+				SrcinfoPath: &pkgBuildDir,
+				AURBase:     &pkg.PackageBase,
+				Version:     pkg.Version,
+			},
+		})
 	}
 
 	g.AddDepsForPkgs(ctx, aurPkgsAdded, graph)
@@ -293,6 +350,51 @@ func (g *Grapher) GraphFromSrcInfos(ctx context.Context, graph *topo.Graph[strin
 	return graph, nil
 }
 
+// GraphFromPkgFiles adds the dependencies of already built packages, read
+// from their package files, that only the AUR can satisfy. Dependencies
+// installed, in the sync databases or provided by another of the files are
+// left to pacman. The packages themselves stay in the graph without node
+// info as pacman -U installs them from the files.
+func (g *Grapher) GraphFromPkgFiles(ctx context.Context, graph *topo.Graph[string, *InstallInfo],
+	pkgs []*aurc.Pkg,
+) *topo.Graph[string, *InstallInfo] {
+	if graph == nil {
+		graph = NewGraph()
+	}
+
+	for _, pkg := range pkgs {
+		deps := make([]string, 0, len(pkg.Depends))
+
+		for _, depString := range pkg.Depends {
+			if g.dbExecutor.LocalSatisfierExists(depString) || g.dbExecutor.SyncSatisfierExists(depString) {
+				continue
+			}
+
+			if pkgsSatisfy(pkgs, depString) {
+				continue
+			}
+
+			deps = append(deps, depString)
+		}
+
+		if len(deps) > 0 {
+			g.addNodes(ctx, graph, pkg.Name, deps, Dep)
+		}
+	}
+
+	return graph
+}
+
+func pkgsSatisfy(pkgs []*aurc.Pkg, dep string) bool {
+	for _, pkg := range pkgs {
+		if satisfiesAur(dep, pkg) {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (g *Grapher) AddDepsForPkgs(ctx context.Context, pkgs []*aur.Pkg, graph *topo.Graph[string, *InstallInfo]) {
 	for _, pkg := range pkgs {
 		g.addDepNodes(ctx, pkg, graph)
@@ -392,6 +494,8 @@ func (g *Grapher) GraphAURTarget(ctx context.Context,
 
 	g.addAurPkgProvides(pkg, graph)
 
+	instalInfo.LastModified = pkg.LastModified
+
 	g.ValidateAndSetNodeInfo(graph, pkg.Name, &topo.NodeInfo[*InstallInfo]{
 		Color:      colorMap[instalInfo.Reason],
 		Background: bgColorMap[AUR],
@@ -425,7 +529,8 @@ func (g *Grapher) GraphFromAUR(ctx context.Context,
 		}
 	}
 
-	aurPkgsAdded := []*aurc.Pkg{}
+	chosen := make([]*aurc.Pkg, 0, len(targets))
+	archs := make(map[string]string, len(g.targetArchs))
 
 	for _, target := range targets {
 		if cachedProvidePkg, ok := g.providerCache[target]; ok {
@@ -446,21 +551,28 @@ func (g *Grapher) GraphFromAUR(ctx context.Context,
 
 		aurPkg := &aurPkgs[0]
 		if len(aurPkgs) > 1 {
-			chosen := g.provideMenu(target, aurPkgs)
-			aurPkg = chosen
+			aurPkg = g.provideMenu(target, aurPkgs)
 			g.providerCache[target] = []aurc.Pkg{*aurPkg}
 		}
 
+		chosen = append(chosen, aurPkg)
+
+		if arch, ok := g.targetArchs[target]; ok {
+			archs[aurPkg.Name] = arch
+		}
+	}
+
+	aurPkgsAdded := []*aurc.Pkg{}
+
+	for _, aurPkg := range g.resolveTargetConflicts(chosen) {
+		if g.upToDate(aurPkg) {
+			g.warnUpToDate(aurPkg)
+			continue
+		}
+
 		reason := Explicit
 		if pkg := g.dbExecutor.LocalPackage(aurPkg.Name); pkg != nil {
 			reason = Reason(pkg.Reason())
-
-			if g.needed {
-				if db.VerCmp(pkg.Version(), aurPkg.Version) >= 0 {
-					g.logger.Warnln(gotext.Get("%s is up to date -- skipping", text.Cyan(pkg.Name()+"-"+pkg.Version())))
-					continue
-				}
-			}
 		}
 
 		graph = g.GraphAURTarget(ctx, graph, aurPkg, &InstallInfo{
@@ -468,6 +580,7 @@ func (g *Grapher) GraphFromAUR(ctx context.Context,
 			Reason:  reason,
 			Source:  AUR,
 			Version: aurPkg.Version,
+			Arch:    archs[aurPkg.Name],
 		})
 		aurPkgsAdded = append(aurPkgsAdded, aurPkg)
 	}
@@ -477,6 +590,32 @@ func (g *Grapher) GraphFromAUR(ctx context.Context,
 	return graph, nil
 }
 
+// upToDate reports whether a target must be skipped under --needed: like
+// pacman, only when the exact same version is installed.
+func (g *Grapher) upToDate(aurPkg *aurc.Pkg) bool {
+	if !g.needed {
+		return false
+	}
+
+	pkg := g.dbExecutor.LocalPackage(aurPkg.Name)
+
+	return pkg != nil && db.VerCmp(pkg.Version(), aurPkg.Version) == 0
+}
+
+func (g *Grapher) allUpToDate(aurPkgs []*aurc.Pkg) bool {
+	for _, aurPkg := range aurPkgs {
+		if !g.upToDate(aurPkg) {
+			return false
+		}
+	}
+
+	return len(aurPkgs) > 0
+}
+
+func (g *Grapher) warnUpToDate(aurPkg *aurc.Pkg) {
+	g.logger.Warnln(gotext.Get("%s is up to date -- skipping", text.Cyan(aurPkg.Name+"-"+aurPkg.Version)))
+}
+
 // Removes found deps from the deps mapset and returns the found deps.
 func (g *Grapher) findDepsFromAUR(ctx context.Context,
 	deps mapset.Set[string],
@@ -581,6 +720,19 @@ func (g *Grapher) ValidateAndSetNodeInfo(graph *topo.Graph[string, *InstallInfo]
 	graph.SetNodeInfo(node, nodeInfo)
 }
 
+// promoteReason raises the reason of node, already in graph, to depType when
+// it is stronger. A make dependency of one package that another one depends
+// on must stay installed once the make dependencies are removed.
+func promoteReason(graph *topo.Graph[string, *InstallInfo], node string, depType Reason) {
+	info := graph.GetNodeInfo(node)
+	if info == nil || info.Value == nil || info.Value.Reason <= depType {
+		return
+	}
+
+	info.Value.Reason = depType
+	info.Color = colorMap[depType]
+}
+
 func (g *Grapher) addNodes(
 	ctx context.Context,
 	graph *topo.Graph[string, *InstallInfo],
@@ -601,6 +753,7 @@ func (g *Grapher) addNodes(
 				g.logger.Warnln(depString, parentPkgName, err)
 			}
 
+			promoteReason(graph, depName, depType)
 			targetsToFind.Remove(depString)
 		}
 
@@ -610,6 +763,8 @@ func (g *Grapher) addNodes(
 					g.logger.Warnln(p.Provider, parentPkgName, err)
 				}
 
+				promoteReason(graph, p.Provider, depType)
+
 				targetsToFind.Remove(depString)
 			}
 		}
@@ -639,7 +794,7 @@ func (g *Grapher) addNodes(
 	// Check Sync
 	for _, depString := range targetsToFind.ToSlice() {
 		alpmPkg := g.dbExecutor.SyncSatisfier(depString)
-		if alpmPkg == nil {
+		if alpmPkg == nil || g.preferAURProvider(ctx, depString, alpmPkg) {
 			continue
 		}
 
@@ -688,10 +843,11 @@ func (g *Grapher) addNodes(
 				Color:      colorMap[depType],
 				Background: bgColorMap[AUR],
 				Value: &InstallInfo{
-					Source:  AUR,
-					Reason:  depType,
-					AURBase: &aurPkg.PackageBase,
-					Version: aurPkg.Version,
+					Source:       AUR,
+					Reason:       depType,
+					AURBase:      &aurPkg.PackageBase,
+					Version:      aurPkg.Version,
+					LastModified: aurPkg.LastModified,
 				},
 			})
 
@@ -723,7 +879,22 @@ func (g *Grapher) provideMenu(dep string, options []aur.Pkg) *aur.Pkg {
 		return &options[0]
 	}
 
-	str := text.Bold(gotext.Get("There are %d providers available for %s:", size, dep))
+	// choices are remembered per dependency name, whatever the version asked
+	depName, _, _ := splitDep(dep)
+
+	if name, ok := g.providers.Get(depName); ok {
+		for i := range options {
+			if options[i].Name == name {
+				g.logger.OperationInfoln(gotext.Get("Using remembered provider %s for %s",
+					text.Cyan(name), text.Bold(dep)))
+
+				return &options[i]
+			}
+		}
+	}
+
+	str := text.Bold(gotext.GetN("There is %d provider available for %s:",
+		"There are %d providers available for %s:", size, size, dep))
 	str += "\n"
 
 	size = 1
@@ -753,6 +924,8 @@ func (g *Grapher) provideMenu(dep string, options []aur.Pkg) *aur.Pkg {
 		}
 
 		if numberBuf == "" {
+			g.rememberProvider(depName, options[0].Name)
+
 			return &options[0]
 		}
 
@@ -770,10 +943,18 @@ func (g *Grapher) provideMenu(dep string, options []aur.Pkg) *aur.Pkg {
 			continue
 		}
 
+		g.rememberProvider(depName, options[num-1].Name)
+
 		return &options[num-1]
 	}
 }
 
+func (g *Grapher) rememberProvider(dep, name string) {
+	if err := g.providers.Remember(dep, name); err != nil {
+		g.logger.Warnln(gotext.Get("unable to remember provider for %s: %s", dep, err))
+	}
+}
+
 func makeAURPKGFromSrcinfo(dbExecutor db.Executor, srcInfo *gosrc.Srcinfo) ([]*aur.Pkg, error) {
 	pkgs := make([]*aur.Pkg, 0, 1)
 
@@ -806,7 +987,7 @@ func makeAURPKGFromSrcinfo(dbExecutor db.Executor, srcInfo *gosrc.Srcinfo) ([]*a
 			Depends: append(archStringToString(alpmArch, pkg.Depends),
 				archStringToString(alpmArch, srcInfo.Package.Depends)...),
 			MakeDepends:  archStringToString(alpmArch, srcInfo.PackageBase.MakeDepends),
-			CheckDepends: archStringToString(alpmArch, srcInfo.PackageBase.CheckDepends),
+			CheckDepends: checkDepends(alpmArch, srcInfo),
 			Conflicts: append(archStringToString(alpmArch, pkg.Conflicts),
 				archStringToString(alpmArch, srcInfo.Package.Conflicts)...),
 			Provides: append(archStringToString(alpmArch, pkg.Provides),
@@ -823,6 +1004,21 @@ func makeAURPKGFromSrcinfo(dbExecutor db.Executor, srcInfo *gosrc.Srcinfo) ([]*a
 	return pkgs, nil
 }
 
+// checkDepends returns the checkdepends of srcInfo, none when its options
+// disable check().
+func checkDepends(alpmArches []string, srcInfo *gosrc.Srcinfo) []string {
+	for i := len(srcInfo.Options) - 1; i >= 0; i-- {
+		switch srcInfo.Options[i] {
+		case "check":
+			return archStringToString(alpmArches, srcInfo.CheckDepends)
+		case "!check":
+			return []string{}
+		}
+	}
+
+	return archStringToString(alpmArches, srcInfo.CheckDepends)
+}
+
 func archStringToString(alpmArches []string, archString []gosrc.ArchString) []string {
 	pkgs := make([]string, 0, len(archString))
 
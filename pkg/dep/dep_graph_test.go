@@ -128,10 +128,11 @@ func TestGrapher_GraphFromTargets_jellyfin(t *testing.T) {
 			want: []map[string]*InstallInfo{
 				{
 					"jellyfin": {
-						Source:  AUR,
-						Reason:  Explicit,
-						Version: "10.8.8-1",
-						AURBase: ptrString("jellyfin"),
+						Source:       AUR,
+						Reason:       Explicit,
+						Version:      "10.8.8-1",
+						AURBase:      ptrString("jellyfin"),
+						LastModified: 1669830147,
 					},
 				},
 				{
@@ -159,24 +160,27 @@ func TestGrapher_GraphFromTargets_jellyfin(t *testing.T) {
 			want: []map[string]*InstallInfo{
 				{
 					"jellyfin": {
-						Source:  AUR,
-						Reason:  Explicit,
-						Version: "10.8.8-1",
-						AURBase: ptrString("jellyfin"),
+						Source:       AUR,
+						Reason:       Explicit,
+						Version:      "10.8.8-1",
+						AURBase:      ptrString("jellyfin"),
+						LastModified: 1669830147,
 					},
 				},
 				{
 					"jellyfin-web": {
-						Source:  AUR,
-						Reason:  Dep,
-						Version: "10.8.8-1",
-						AURBase: ptrString("jellyfin"),
+						Source:       AUR,
+						Reason:       Dep,
+						Version:      "10.8.8-1",
+						AURBase:      ptrString("jellyfin"),
+						LastModified: 1669830147,
 					},
 					"jellyfin-server": {
-						Source:  AUR,
-						Reason:  Dep,
-						Version: "10.8.8-1",
-						AURBase: ptrString("jellyfin"),
+						Source:       AUR,
+						Reason:       Dep,
+						Version:      "10.8.8-1",
+						AURBase:      ptrString("jellyfin"),
+						LastModified: 1669830147,
 					},
 				},
 				{
@@ -292,10 +296,11 @@ func TestGrapher_GraphProvides_androidsdk(t *testing.T) {
 			want: []map[string]*InstallInfo{
 				{
 					"android-sdk": {
-						Source:  AUR,
-						Reason:  Explicit,
-						Version: "26.1.1-2",
-						AURBase: ptrString("android-sdk"),
+						Source:       AUR,
+						Reason:       Explicit,
+						Version:      "26.1.1-2",
+						AURBase:      ptrString("android-sdk"),
+						LastModified: 1647982720,
 					},
 				},
 				{
@@ -0,0 +1,168 @@
+package dep
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/leonelquinteros/gotext"
+
+	"github.com/Jguer/yippee/v12/pkg/dep/topo"
+)
+
+// GraphFormat is a format RenderGraph renders a dependency graph in.
+type GraphFormat string
+
+const (
+	// GraphDOT is the GraphViz language, e.g. for dot -Tsvg.
+	GraphDOT GraphFormat = "dot"
+	// GraphJSON lists the nodes and the edges as JSON, for tooling.
+	GraphJSON GraphFormat = "json"
+	// GraphMermaid is a Mermaid flowchart, rendered by many forges.
+	GraphMermaid GraphFormat = "mermaid"
+)
+
+// graphNode is a package of a rendered graph.
+type graphNode struct {
+	Name       string `json:"name"`
+	Version    string `json:"version"`
+	Source     string `json:"source"`
+	Reason     string `json:"reason"`
+	Repository string `json:"repository,omitempty"`
+
+	color      string
+	background string
+}
+
+// graphEdge is an edge from a package to one of its dependencies.
+type graphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// RenderGraph renders graph in format with the nodes and edges sorted, so a
+// graph renders the same every time. Edges go from a package to its
+// dependencies.
+func RenderGraph(graph *topo.Graph[string, *InstallInfo], format GraphFormat) (string, error) {
+	nodes, edges := graphElements(graph)
+
+	switch format {
+	case GraphDOT, "":
+		return renderDOT(nodes, edges), nil
+	case GraphJSON:
+		marshalled, err := json.Marshal(struct {
+			Nodes []graphNode `json:"nodes"`
+			Edges []graphEdge `json:"edges"`
+		}{nodes, edges})
+
+		return string(marshalled), err
+	case GraphMermaid:
+		return renderMermaid(nodes, edges), nil
+	}
+
+	return "", errors.New(gotext.Get("unknown graph format: %s", format))
+}
+
+func graphElements(graph *topo.Graph[string, *InstallInfo]) ([]graphNode, []graphEdge) {
+	nodes := make([]graphNode, 0, graph.Len())
+	edges := make([]graphEdge, 0)
+
+	_ = graph.ForEach(func(name string, ii *InstallInfo) error {
+		node := graphNode{Name: name}
+
+		if ii != nil {
+			node.Version = ii.Version
+			node.Source = SourceNames[ii.Source]
+			node.Reason = ReasonNames[ii.Reason]
+
+			if ii.SyncDBName != nil {
+				node.Repository = *ii.SyncDBName
+			}
+		}
+
+		if info := graph.GetNodeInfo(name); info != nil {
+			node.color, node.background = info.Color, info.Background
+		}
+
+		nodes = append(nodes, node)
+
+		// the graph links a dependency to the packages requiring it
+		for requirer := range graph.ImmediateDependencies(name) {
+			edges = append(edges, graphEdge{From: requirer, To: name})
+		}
+
+		return nil
+	})
+
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Name < nodes[j].Name })
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].From != edges[j].From {
+			return edges[i].From < edges[j].From
+		}
+
+		return edges[i].To < edges[j].To
+	})
+
+	return nodes, edges
+}
+
+func renderDOT(nodes []graphNode, edges []graphEdge) string {
+	var sb strings.Builder
+
+	sb.WriteString("digraph dependencies {\n")
+	sb.WriteString("\tnode [shape = record];\n")
+
+	for i := range nodes {
+		node := &nodes[i]
+		label := node.Name
+		if node.Version != "" {
+			label += "\\n" + node.Version
+		}
+
+		extra := ""
+		if node.color != "" || node.background != "" {
+			extra = fmt.Sprintf(", color = %q, style = filled, fillcolor = %q", node.color, node.background)
+		}
+
+		fmt.Fprintf(&sb, "\t%q [label = %q%s];\n", node.Name, label, extra)
+	}
+
+	for _, edge := range edges {
+		fmt.Fprintf(&sb, "\t%q -> %q;\n", edge.From, edge.To)
+	}
+
+	sb.WriteString("}")
+
+	return sb.String()
+}
+
+// renderMermaid names the nodes by index, package names may hold characters
+// Mermaid does not accept in identifiers.
+func renderMermaid(nodes []graphNode, edges []graphEdge) string {
+	var sb strings.Builder
+
+	ids := make(map[string]string, len(nodes))
+
+	sb.WriteString("graph TD\n")
+
+	for i := range nodes {
+		node := &nodes[i]
+		ids[node.Name] = "n" + strconv.Itoa(i)
+
+		label := node.Name
+		if node.Version != "" {
+			label += " " + node.Version
+		}
+
+		fmt.Fprintf(&sb, "    %s[\"%s\"]\n", ids[node.Name], strings.ReplaceAll(label, `"`, "#quot;"))
+	}
+
+	for _, edge := range edges {
+		fmt.Fprintf(&sb, "    %s --> %s\n", ids[edge.From], ids[edge.To])
+	}
+
+	return strings.TrimSuffix(sb.String(), "\n")
+}
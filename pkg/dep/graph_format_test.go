@@ -0,0 +1,94 @@
+//go:build !integration
+// +build !integration
+
+package dep
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jguer/yippee/v12/pkg/dep/topo"
+)
+
+func TestRenderGraph(t *testing.T) {
+	t.Parallel()
+
+	extra := "extra"
+	graph := topo.New[string, *InstallInfo]()
+
+	require.NoError(t, graph.DependOn("libfoo", "foo"))
+	require.NoError(t, graph.DependOn("make-dep", "foo"))
+	graph.SetNodeInfo("foo", &topo.NodeInfo[*InstallInfo]{
+		Color:      "lightblue",
+		Background: "lightskyblue",
+		Value:      &InstallInfo{Version: "1.0-1", Source: AUR, Reason: Explicit},
+	})
+	graph.SetNodeInfo("libfoo", &topo.NodeInfo[*InstallInfo]{
+		Value: &InstallInfo{Version: "2.1-3", Source: Sync, Reason: Dep, SyncDBName: &extra},
+	})
+	graph.SetNodeInfo("make-dep", &topo.NodeInfo[*InstallInfo]{
+		Value: &InstallInfo{Version: "0.1-1", Source: Sync, Reason: MakeDep, SyncDBName: &extra},
+	})
+
+	testCases := []struct {
+		desc    string
+		format  GraphFormat
+		want    string
+		wantErr bool
+	}{
+		{
+			desc:   "dot",
+			format: GraphDOT,
+			want: `digraph dependencies {
+	node [shape = record];
+	"foo" [label = "foo\\n1.0-1", color = "lightblue", style = filled, fillcolor = "lightskyblue"];
+	"libfoo" [label = "libfoo\\n2.1-3"];
+	"make-dep" [label = "make-dep\\n0.1-1"];
+	"foo" -> "libfoo";
+	"foo" -> "make-dep";
+}`,
+		},
+		{
+			desc:   "json",
+			format: GraphJSON,
+			want: `{"nodes":[` +
+				`{"name":"foo","version":"1.0-1","source":"AUR","reason":"Explicit"},` +
+				`{"name":"libfoo","version":"2.1-3","source":"Sync","reason":"Dependency","repository":"extra"},` +
+				`{"name":"make-dep","version":"0.1-1","source":"Sync","reason":"Make Dependency","repository":"extra"}],` +
+				`"edges":[{"from":"foo","to":"libfoo"},{"from":"foo","to":"make-dep"}]}`,
+		},
+		{
+			desc:   "mermaid",
+			format: GraphMermaid,
+			want: `graph TD
+    n0["foo 1.0-1"]
+    n1["libfoo 2.1-3"]
+    n2["make-dep 0.1-1"]
+    n0 --> n1
+    n0 --> n2`,
+		},
+		{
+			desc:    "unknown format",
+			format:  "svg",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := RenderGraph(graph, tc.format)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
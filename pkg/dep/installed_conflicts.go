@@ -0,0 +1,95 @@
+package dep
+
+import (
+	"sort"
+
+	gosrc "github.com/Morganamilo/go-srcinfo"
+
+	"github.com/Jguer/yippee/v12/pkg/db"
+	aur "github.com/Jguer/yippee/v12/pkg/query"
+)
+
+// InstalledConflict is an installed package pacman asks to remove when
+// installing an AUR package, because one conflicts with the other.
+type InstalledConflict struct {
+	Name      string // AUR package to install
+	Installed string // installed package in conflict
+}
+
+// InstalledConflicts returns the installed packages the AUR packages of
+// targets conflict with. Pacman only asks about them once everything is
+// built and answers no under --noconfirm, so they are better known up
+// front. Installed packages that are targets themselves are upgraded and
+// not in conflict.
+func InstalledConflicts(dbExecutor db.Executor, srcInfos map[string]*gosrc.Srcinfo,
+	targets []map[string]*InstallInfo,
+) ([]InstalledConflict, error) {
+	installing := make(map[string]bool)
+
+	for _, layer := range targets {
+		for name := range layer {
+			installing[name] = true
+		}
+	}
+
+	pkgs := make([]*aur.Pkg, 0, len(srcInfos))
+
+	for _, srcInfo := range srcInfos {
+		basePkgs, err := makeAURPKGFromSrcinfo(dbExecutor, srcInfo)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, pkg := range basePkgs {
+			if installing[pkg.Name] {
+				pkgs = append(pkgs, pkg)
+			}
+		}
+	}
+
+	conflicts := make([]InstalledConflict, 0)
+
+	for _, local := range dbExecutor.LocalPackages() {
+		if installing[local.Name()] {
+			continue
+		}
+
+		for _, pkg := range pkgs {
+			if conflictsWithInstalled(dbExecutor, pkg, local) {
+				conflicts = append(conflicts, InstalledConflict{Name: pkg.Name, Installed: local.Name()})
+			}
+		}
+	}
+
+	sort.Slice(conflicts, func(i, j int) bool {
+		if conflicts[i].Name != conflicts[j].Name {
+			return conflicts[i].Name < conflicts[j].Name
+		}
+
+		return conflicts[i].Installed < conflicts[j].Installed
+	})
+
+	return conflicts, nil
+}
+
+func conflictsWithInstalled(dbExecutor db.Executor, pkg *aur.Pkg, local db.IPackage) bool {
+	for _, conflict := range pkg.Conflicts {
+		if pkgSatisfies(local.Name(), local.Version(), conflict) {
+			return true
+		}
+
+		for _, provide := range dbExecutor.PackageProvides(local) {
+			if provideSatisfies(provide.String(), conflict, local.Version()) {
+				return true
+			}
+		}
+	}
+
+	for _, conflict := range dbExecutor.PackageConflicts(local) {
+		if satisfiesAur(conflict.String(), pkg) {
+			return true
+		}
+	}
+
+	return false
+}
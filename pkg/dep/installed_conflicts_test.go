@@ -0,0 +1,81 @@
+//go:build !integration
+// +build !integration
+
+package dep
+
+import (
+	"testing"
+
+	gosrc "github.com/Morganamilo/go-srcinfo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jguer/yippee/v12/pkg/db/mock"
+)
+
+func TestInstalledConflicts(t *testing.T) {
+	t.Parallel()
+
+	srcInfos := map[string]*gosrc.Srcinfo{
+		"foo-git": {
+			PackageBase: gosrc.PackageBase{Pkgbase: "foo-git", Pkgver: "2.0", Pkgrel: "1"},
+			Package: gosrc.Package{
+				Conflicts: []gosrc.ArchString{{Value: "foo"}, {Value: "libbar<2"}},
+				Provides:  []gosrc.ArchString{{Value: "foo=2.0"}},
+			},
+			Packages: []gosrc.Package{{Pkgname: "foo-git"}},
+		},
+	}
+
+	targets := []map[string]*InstallInfo{{"foo-git": {Source: SrcInfo, Reason: Explicit}}}
+
+	testCases := []struct {
+		desc      string
+		installed []*mock.Package
+		want      []InstalledConflict
+	}{
+		{
+			desc:      "nothing in conflict",
+			installed: []*mock.Package{mock.NewPackage("baz", "1.0-1")},
+			want:      []InstalledConflict{},
+		},
+		{
+			desc:      "conflicts with an installed package",
+			installed: []*mock.Package{mock.NewPackage("foo", "1.0-1")},
+			want:      []InstalledConflict{{Name: "foo-git", Installed: "foo"}},
+		},
+		{
+			desc:      "conflicts with what an installed package provides",
+			installed: []*mock.Package{mock.NewPackage("libbar-legacy", "1.0-1").WithProvides("libbar=1.5")},
+			want:      []InstalledConflict{{Name: "foo-git", Installed: "libbar-legacy"}},
+		},
+		{
+			desc:      "versioned conflict not matched",
+			installed: []*mock.Package{mock.NewPackage("libbar", "2.1-1")},
+			want:      []InstalledConflict{},
+		},
+		{
+			desc:      "installed package conflicts with the target",
+			installed: []*mock.Package{mock.NewPackage("foo-bin", "1.0-1").WithConflicts("foo")},
+			want:      []InstalledConflict{{Name: "foo-git", Installed: "foo-bin"}},
+		},
+		{
+			desc:      "older version of the target is upgraded",
+			installed: []*mock.Package{mock.NewPackage("foo-git", "1.0-1").WithConflicts("foo")},
+			want:      []InstalledConflict{},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			dbExecutor := mock.NewExecutor().Local(tc.installed...).Build()
+
+			conflicts, err := InstalledConflicts(dbExecutor, srcInfos, targets)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, conflicts)
+		})
+	}
+}
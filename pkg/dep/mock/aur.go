@@ -1,7 +1,10 @@
+// Package mock provides an in-memory AUR query client for tests, either
+// scripted through GetFn or backed by a fixed set of packages with NewAUR.
 package mock
 
 import (
 	"context"
+	"strings"
 
 	"github.com/Jguer/aur"
 )
@@ -12,6 +15,28 @@ type MockAUR struct {
 	GetFn GetFunc
 }
 
+// NewAUR returns a MockAUR answering queries from pkgs. Queries by name,
+// provides, name and description, maintainer, submitter, groups, keywords and
+// co-maintainers are supported, other fields fall back to the name.
+func NewAUR(pkgs ...aur.Pkg) *MockAUR {
+	return &MockAUR{
+		GetFn: func(ctx context.Context, query *aur.Query) ([]aur.Pkg, error) {
+			found := make([]aur.Pkg, 0)
+
+			for i := range pkgs {
+				for _, needle := range query.Needles {
+					if matches(&pkgs[i], query, needle) {
+						found = append(found, pkgs[i])
+						break
+					}
+				}
+			}
+
+			return found, nil
+		},
+	}
+}
+
 func (m *MockAUR) Get(ctx context.Context, query *aur.Query) ([]aur.Pkg, error) {
 	if m.GetFn != nil {
 		return m.GetFn(ctx, query)
@@ -19,3 +44,53 @@ func (m *MockAUR) Get(ctx context.Context, query *aur.Query) ([]aur.Pkg, error)
 
 	panic("implement me")
 }
+
+// matches reports whether pkg matches needle for the field of query.
+func matches(pkg *aur.Pkg, query *aur.Query, needle string) bool {
+	match := func(value string) bool {
+		if query.Contains {
+			return strings.Contains(value, needle)
+		}
+
+		return value == needle
+	}
+
+	matchAny := func(values []string) bool {
+		for _, value := range values {
+			if match(value) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	switch query.By {
+	case aur.Provides:
+		if match(pkg.Name) {
+			return true
+		}
+
+		for _, provide := range pkg.Provides {
+			if match(strings.SplitN(provide, "=", 2)[0]) {
+				return true
+			}
+		}
+
+		return false
+	case aur.NameDesc:
+		return strings.Contains(pkg.Name, needle) || strings.Contains(pkg.Description, needle)
+	case aur.Maintainer:
+		return match(pkg.Maintainer)
+	case aur.Submitter:
+		return match(pkg.Submitter)
+	case aur.Groups:
+		return matchAny(pkg.Groups)
+	case aur.Keywords:
+		return matchAny(pkg.Keywords)
+	case aur.CoMaintainers:
+		return matchAny(pkg.CoMaintainers)
+	default:
+		return match(pkg.Name)
+	}
+}
@@ -0,0 +1,67 @@
+//go:build !integration
+// +build !integration
+
+package mock
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Jguer/aur"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAUR(t *testing.T) {
+	t.Parallel()
+
+	client := NewAUR(
+		aur.Pkg{Name: "yippee", Description: "AUR helper", Maintainer: "jguer"},
+		aur.Pkg{Name: "yippee-bin", Provides: []string{"yippee=12.0.0"}, Maintainer: "jguer"},
+		aur.Pkg{Name: "paru", Description: "Feature packed AUR helper", Maintainer: "morganamilo"},
+	)
+
+	testCases := []struct {
+		desc  string
+		query aur.Query
+		want  []string
+	}{
+		{
+			desc:  "name",
+			query: aur.Query{By: aur.Name, Needles: []string{"yippee", "missing"}},
+			want:  []string{"yippee"},
+		},
+		{
+			desc:  "provides",
+			query: aur.Query{By: aur.Provides, Needles: []string{"yippee"}},
+			want:  []string{"yippee", "yippee-bin"},
+		},
+		{
+			desc:  "name and description",
+			query: aur.Query{By: aur.NameDesc, Needles: []string{"AUR helper"}},
+			want:  []string{"yippee", "paru"},
+		},
+		{
+			desc:  "maintainer",
+			query: aur.Query{By: aur.Maintainer, Needles: []string{"morganamilo"}},
+			want:  []string{"paru"},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			pkgs, err := client.Get(context.Background(), &tc.query)
+			require.NoError(t, err)
+
+			names := make([]string, 0, len(pkgs))
+			for _, pkg := range pkgs {
+				names = append(names, pkg.Name)
+			}
+
+			assert.Equal(t, tc.want, names)
+		})
+	}
+}
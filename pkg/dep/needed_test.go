@@ -0,0 +1,159 @@
+//go:build !integration
+// +build !integration
+
+package dep
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	gosrc "github.com/Morganamilo/go-srcinfo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jguer/yippee/v12/pkg/db/mock"
+	mockaur "github.com/Jguer/yippee/v12/pkg/dep/mock"
+	"github.com/Jguer/yippee/v12/pkg/dep/topo"
+	aur "github.com/Jguer/yippee/v12/pkg/query"
+	"github.com/Jguer/yippee/v12/pkg/text"
+)
+
+func graphNodes(t *testing.T, graph *topo.Graph[string, *InstallInfo]) []string {
+	nodes := []string{}
+
+	require.NoError(t, graph.ForEach(func(name string, _ *InstallInfo) error {
+		nodes = append(nodes, name)
+		return nil
+	}))
+
+	return nodes
+}
+
+func TestGrapher_GraphFromAUR_Needed(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc      string
+		installed string
+		needed    bool
+		want      []string
+	}{
+		{desc: "not installed", needed: true, want: []string{"foo"}},
+		{desc: "same version", installed: "1.0-1", needed: true, want: []string{}},
+		{desc: "older version", installed: "0.9-1", needed: true, want: []string{"foo"}},
+		{desc: "newer version", installed: "1.1-1", needed: true, want: []string{"foo"}},
+		{desc: "without needed", installed: "1.0-1", want: []string{"foo"}},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			builder := mock.NewExecutor()
+			if tc.installed != "" {
+				builder.Local(mock.NewPackage("foo", tc.installed))
+			}
+
+			var out strings.Builder
+
+			g := NewGrapher(builder.Build(),
+				mockaur.NewAUR(aur.Pkg{Name: "foo", PackageBase: "foo", Version: "1.0-1"}),
+				false, true, false, false, tc.needed,
+				text.NewLogger(&out, &out, strings.NewReader(""), true, "test"))
+
+			graph, err := g.GraphFromAUR(context.Background(), nil, []string{"foo"})
+			require.NoError(t, err)
+
+			assert.ElementsMatch(t, tc.want, graphNodes(t, graph))
+			assert.Equal(t, len(tc.want) == 0, strings.Contains(out.String(), "is up to date -- skipping"))
+		})
+	}
+}
+
+func TestGrapher_GraphFromSrcInfos_Needed(t *testing.T) {
+	t.Parallel()
+
+	srcInfo := &gosrc.Srcinfo{
+		PackageBase: gosrc.PackageBase{Pkgbase: "foo", Pkgver: "1.0", Pkgrel: "1"},
+		Packages:    []gosrc.Package{{Pkgname: "foo"}, {Pkgname: "foo-docs"}},
+	}
+
+	testCases := []struct {
+		desc      string
+		installed []*mock.Package
+		want      []string
+	}{
+		{
+			desc:      "whole base up to date",
+			installed: []*mock.Package{mock.NewPackage("foo", "1.0-1"), mock.NewPackage("foo-docs", "1.0-1")},
+			want:      []string{},
+		},
+		{
+			desc:      "split package out of date",
+			installed: []*mock.Package{mock.NewPackage("foo", "1.0-1"), mock.NewPackage("foo-docs", "0.9-1")},
+			want:      []string{"foo", "foo-docs"},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			g := NewGrapher(mock.NewExecutor().Local(tc.installed...).Build(), mockaur.NewAUR(),
+				false, true, false, false, true,
+				text.NewLogger(io.Discard, io.Discard, strings.NewReader(""), true, "test"))
+
+			graph, err := g.GraphFromSrcInfos(context.Background(), nil,
+				map[string]*gosrc.Srcinfo{"/tmp/foo": srcInfo})
+			require.NoError(t, err)
+
+			assert.ElementsMatch(t, tc.want, graphNodes(t, graph))
+		})
+	}
+}
+
+func TestGrapher_GraphFromSrcInfos_CheckDepends(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc    string
+		options []string
+		noCheck bool
+		want    []string
+	}{
+		{desc: "check runs", want: []string{"foo", "python-pytest"}},
+		{desc: "nocheck", noCheck: true, want: []string{"foo"}},
+		{desc: "check disabled by the PKGBUILD", options: []string{"!check"}, want: []string{"foo"}},
+		{desc: "check enabled by the PKGBUILD", options: []string{"!check", "check"}, want: []string{"foo", "python-pytest"}},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			srcInfo := &gosrc.Srcinfo{
+				PackageBase: gosrc.PackageBase{
+					Pkgbase: "foo", Pkgver: "1.0", Pkgrel: "1",
+					CheckDepends: []gosrc.ArchString{{Value: "python-pytest"}},
+				},
+				Package:  gosrc.Package{Options: tc.options},
+				Packages: []gosrc.Package{{Pkgname: "foo"}},
+			}
+
+			g := NewGrapher(mock.NewExecutor().Sync(mock.NewPackage("python-pytest", "8.0-1")).Build(),
+				mockaur.NewAUR(), false, true, false, tc.noCheck, false,
+				text.NewLogger(io.Discard, io.Discard, strings.NewReader(""), true, "test"))
+
+			graph, err := g.GraphFromSrcInfos(context.Background(), nil,
+				map[string]*gosrc.Srcinfo{"/tmp/foo": srcInfo})
+			require.NoError(t, err)
+
+			assert.ElementsMatch(t, tc.want, graphNodes(t, graph))
+		})
+	}
+}
@@ -0,0 +1,85 @@
+//go:build !integration
+// +build !integration
+
+package dep
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Jguer/yippee/v12/pkg/db/mock"
+	mockaur "github.com/Jguer/yippee/v12/pkg/dep/mock"
+	aur "github.com/Jguer/yippee/v12/pkg/query"
+	"github.com/Jguer/yippee/v12/pkg/text"
+)
+
+func TestGrapher_GraphFromPkgFiles(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc  string
+		files []*aur.Pkg
+		want  map[string]Source
+	}{
+		{
+			desc:  "repository and installed dependencies are left to pacman",
+			files: []*aur.Pkg{{Name: "app", Version: "1.0-1", Depends: []string{"glibc", "cmake"}}},
+			want:  map[string]Source{},
+		},
+		{
+			desc:  "AUR dependency and its dependencies",
+			files: []*aur.Pkg{{Name: "app", Version: "1.0-1", Depends: []string{"glibc", "libfoo>=1.0"}}},
+			want:  map[string]Source{"libfoo": AUR, "cmake": Sync},
+		},
+		{
+			desc: "dependency provided by another file",
+			files: []*aur.Pkg{
+				{Name: "app", Version: "1.0-1", Depends: []string{"libfoo"}},
+				{Name: "libfoo-git", Version: "2.0-1", Provides: []string{"libfoo=2.0"}},
+			},
+			want: map[string]Source{},
+		},
+		{
+			desc:  "dependency nowhere to be found",
+			files: []*aur.Pkg{{Name: "app", Version: "1.0-1", Depends: []string{"nothere"}}},
+			want:  map[string]Source{"nothere": Missing},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			dbExecutor := mock.NewExecutor().
+				Local(mock.NewPackage("glibc", "2.39-1")).
+				Sync(mock.NewPackage("cmake", "3.28-1")).
+				Build()
+
+			aurCache := mockaur.NewAUR(
+				aur.Pkg{Name: "libfoo", PackageBase: "libfoo", Version: "1.2-1", Depends: []string{"cmake"}},
+			)
+
+			g := NewGrapher(dbExecutor, aurCache, false, true, false, false, false,
+				text.NewLogger(io.Discard, io.Discard, strings.NewReader(""), true, "test"))
+
+			graph := g.GraphFromPkgFiles(context.Background(), nil, tc.files)
+
+			got := map[string]Source{}
+			for _, layer := range graph.TopoSortedLayerMap(nil) {
+				for name, info := range layer {
+					// the files themselves have no node info
+					if info != nil {
+						got[name] = info.Source
+					}
+				}
+			}
+
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -0,0 +1,56 @@
+//go:build !integration
+// +build !integration
+
+package dep
+
+import (
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jguer/yippee/v12/pkg/db"
+	"github.com/Jguer/yippee/v12/pkg/db/mock"
+	aur "github.com/Jguer/yippee/v12/pkg/query"
+	"github.com/Jguer/yippee/v12/pkg/text"
+)
+
+func TestProvideMenu_RemembersChoice(t *testing.T) {
+	t.Parallel()
+
+	options := []aur.Pkg{{Name: "jdk-bin"}, {Name: "jdk-git"}, {Name: "jdk-openj9"}}
+	path := filepath.Join(t.TempDir(), "providers.json")
+
+	newGrapher := func(input string, noConfirm bool) *Grapher {
+		providers := db.NewProviderChoices(path)
+		require.NoError(t, providers.Load())
+
+		g := NewGrapher(mock.NewExecutor().Build(), nil, false, noConfirm, false, false, false,
+			text.NewLogger(io.Discard, io.Discard, strings.NewReader(input), true, "test"))
+		g.SetProviderChoices(providers)
+
+		return g
+	}
+
+	// noconfirm answers are not remembered
+	assert.Equal(t, "jdk-bin", newGrapher("", true).provideMenu("jdk", options).Name)
+	_, ok := db.NewProviderChoices(path).Get("jdk")
+	assert.False(t, ok)
+
+	assert.Equal(t, "jdk-openj9", newGrapher("3\n", false).provideMenu("jdk>=17", options).Name)
+
+	// the menu is skipped, there is nothing left to read
+	assert.Equal(t, "jdk-openj9", newGrapher("", false).provideMenu("jdk", options).Name)
+
+	// a remembered provider that is no longer offered asks again
+	assert.Equal(t, "jdk-git", newGrapher("2\n", false).provideMenu("jdk", options[:2]).Name)
+	assert.Equal(t, "jdk-git", newGrapher("", false).provideMenu("jdk", options).Name)
+
+	providers := newGrapher("", false).providers
+	require.NoError(t, providers.Forget())
+	assert.NoFileExists(t, path)
+	assert.Equal(t, "jdk-bin", newGrapher("\n", false).provideMenu("jdk", options).Name)
+}
@@ -0,0 +1,176 @@
+package dep
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	aurc "github.com/Jguer/aur"
+	"github.com/leonelquinteros/gotext"
+
+	"github.com/Jguer/yippee/v12/pkg/db"
+	"github.com/Jguer/yippee/v12/pkg/text"
+)
+
+// ProviderPolicy decides between the repositories and the AUR when both
+// provide a dependency under another name.
+type ProviderPolicy string
+
+const (
+	// ProviderRepoFirst always uses the repository provider.
+	ProviderRepoFirst ProviderPolicy = "repo-first"
+	// ProviderAURIfInstalled uses the AUR provider when it is installed.
+	ProviderAURIfInstalled ProviderPolicy = "aur-if-installed"
+	// ProviderAsk asks which provider to use and remembers the answer.
+	ProviderAsk ProviderPolicy = "ask"
+)
+
+// SetProviderPolicy sets how a dependency provided by both a repository and
+// the AUR is resolved. The repository provider is used by default.
+func (g *Grapher) SetProviderPolicy(policy ProviderPolicy) {
+	g.providerPolicy = policy
+}
+
+// preferAURProvider tells whether depString, which the repository package
+// repoPkg provides under another name, is to be resolved from the AUR
+// instead. The AUR provider chosen is cached for findDepsFromAUR.
+func (g *Grapher) preferAURProvider(ctx context.Context, depString string, repoPkg db.IPackage) bool {
+	depName, _, _ := splitDep(depString)
+
+	switch g.providerPolicy {
+	case ProviderAURIfInstalled, ProviderAsk:
+	default:
+		return false
+	}
+
+	if repoPkg.Name() == depName {
+		return false
+	}
+
+	aurPkgs, err := g.aurClient.Get(ctx, &aurc.Query{By: aurc.Provides, Needles: []string{depName}, Contains: true})
+	if err != nil {
+		g.logger.Debugln("unable to find AUR providers of", depName+":", err)
+		return false
+	}
+
+	candidates := make([]aurc.Pkg, 0, len(aurPkgs))
+
+	for i := range aurPkgs {
+		if satisfiesAur(depString, &aurPkgs[i]) {
+			candidates = append(candidates, aurPkgs[i])
+		}
+	}
+
+	if len(candidates) == 0 {
+		return false
+	}
+
+	chosen := g.chooseProvider(depString, repoPkg, candidates)
+	if chosen == nil {
+		g.logger.Debugln(gotext.Get("provider policy %s: using %s from the repositories for %s",
+			g.providerPolicy, repoPkg.Name(), depString))
+
+		return false
+	}
+
+	g.logger.OperationInfoln(gotext.Get("Using %s from the AUR for %s instead of %s (provider policy %s)",
+		text.Cyan(chosen.Name), text.Bold(depString), repoPkg.Name(), g.providerPolicy))
+
+	g.providerCache[depString] = []aurc.Pkg{*chosen}
+
+	return true
+}
+
+// chooseProvider returns the AUR provider to use, nil for the repository
+// one. A provider remembered for the dependency, by yippee or by the pacman
+// provider menu, is used when it is still offered.
+func (g *Grapher) chooseProvider(depString string, repoPkg db.IPackage, candidates []aurc.Pkg) *aurc.Pkg {
+	depName, _, _ := splitDep(depString)
+
+	if name, ok := g.providers.Get(depName); ok {
+		if name == repoPkg.Name() {
+			return nil
+		}
+
+		for i := range candidates {
+			if candidates[i].Name == name {
+				return &candidates[i]
+			}
+		}
+	}
+
+	if g.providerPolicy == ProviderAURIfInstalled {
+		for i := range candidates {
+			if g.dbExecutor.LocalPackage(candidates[i].Name) != nil {
+				return &candidates[i]
+			}
+		}
+
+		return nil
+	}
+
+	return g.providerSourceMenu(depString, repoPkg, candidates)
+}
+
+// providerSourceMenu asks whether the repository or one of the AUR packages
+// provides depString. The repository provider is the default.
+func (g *Grapher) providerSourceMenu(depString string, repoPkg db.IPackage, candidates []aurc.Pkg) *aurc.Pkg {
+	depName, _, _ := splitDep(depString)
+	size := len(candidates) + 1
+
+	str := text.Bold(gotext.GetN("There is %d provider available for %s:",
+		"There are %d providers available for %s:", size, size, depString))
+	str += "\n"
+	str += g.logger.SprintOperationInfo(gotext.Get("Repository"), " ", repoPkg.DB().Name(), "\n    ")
+	str += fmt.Sprintf("%d) %s\n", 1, repoPkg.Name())
+	str += g.logger.SprintOperationInfo(gotext.Get("Repository AUR"), "\n    ")
+
+	for i := range candidates {
+		str += fmt.Sprintf("%d) %s ", i+2, candidates[i].Name)
+	}
+
+	g.logger.OperationInfoln(str)
+
+	for {
+		g.logger.Println(gotext.Get("\nEnter a number (default=1): "))
+
+		if g.noConfirm {
+			g.logger.Println("1")
+
+			return nil
+		}
+
+		numberBuf, err := g.logger.GetInput("", false)
+		if err != nil {
+			g.logger.Errorln(err)
+
+			return nil
+		}
+
+		num := 1
+		if numberBuf != "" {
+			num, err = strconv.Atoi(numberBuf)
+			if err != nil {
+				g.logger.Errorln(gotext.Get("invalid number: %s", numberBuf))
+
+				continue
+			}
+		}
+
+		if num < 1 || num > size {
+			g.logger.Errorln(gotext.Get("invalid value: %d is not between %d and %d", num, 1, size))
+
+			continue
+		}
+
+		if num == 1 {
+			g.rememberProvider(depName, repoPkg.Name())
+
+			return nil
+		}
+
+		g.rememberProvider(depName, candidates[num-2].Name)
+
+		return &candidates[num-2]
+	}
+}
@@ -0,0 +1,137 @@
+//go:build !integration
+// +build !integration
+
+package dep
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	aurc "github.com/Jguer/aur"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jguer/yippee/v12/pkg/db"
+	"github.com/Jguer/yippee/v12/pkg/db/mock"
+	mockaur "github.com/Jguer/yippee/v12/pkg/dep/mock"
+	"github.com/Jguer/yippee/v12/pkg/text"
+)
+
+func TestGrapher_PreferAURProvider(t *testing.T) {
+	t.Parallel()
+
+	repoPkg := mock.NewPackage("jre-openjdk", "21.0.1-1").WithDB("extra").WithProvides("java-runtime=21")
+	aurPkgs := []aurc.Pkg{
+		{Name: "jdk-bin", PackageBase: "jdk-bin", Version: "21.0.1-1", Provides: []string{"java-runtime=21"}},
+		{Name: "jdk17-bin", PackageBase: "jdk17-bin", Version: "17.0.9-1", Provides: []string{"java-runtime=17"}},
+	}
+
+	tests := []struct {
+		name       string
+		policy     ProviderPolicy
+		depString  string
+		installed  []*mock.Package
+		remembered string
+		input      string
+		want       string
+	}{
+		{
+			name:      "repo first",
+			policy:    ProviderRepoFirst,
+			depString: "java-runtime",
+			installed: []*mock.Package{mock.NewPackage("jdk-bin", "21.0.0-1")},
+		},
+		{
+			name:      "installed AUR provider",
+			policy:    ProviderAURIfInstalled,
+			depString: "java-runtime",
+			installed: []*mock.Package{mock.NewPackage("jdk17-bin", "17.0.8-1")},
+			want:      "jdk17-bin",
+		},
+		{
+			name:      "installed AUR provider not satisfying",
+			policy:    ProviderAURIfInstalled,
+			depString: "java-runtime>=21",
+			installed: []*mock.Package{mock.NewPackage("jdk17-bin", "17.0.8-1")},
+		},
+		{
+			name:      "no AUR provider installed",
+			policy:    ProviderAURIfInstalled,
+			depString: "java-runtime",
+		},
+		{
+			name:       "remembered repo provider",
+			policy:     ProviderAURIfInstalled,
+			depString:  "java-runtime",
+			installed:  []*mock.Package{mock.NewPackage("jdk17-bin", "17.0.8-1")},
+			remembered: "jre-openjdk",
+		},
+		{
+			name:      "ask picks AUR",
+			policy:    ProviderAsk,
+			depString: "java-runtime",
+			input:     "3\n",
+			want:      "jdk17-bin",
+		},
+		{
+			name:      "ask defaults to repo",
+			policy:    ProviderAsk,
+			depString: "java-runtime",
+			input:     "\n",
+		},
+		{
+			name:       "ask remembered",
+			policy:     ProviderAsk,
+			depString:  "java-runtime",
+			remembered: "jdk-bin",
+			want:       "jdk-bin",
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			providers := db.NewProviderChoices(filepath.Join(t.TempDir(), "providers.json"))
+			if tc.remembered != "" {
+				require.NoError(t, providers.Remember("java-runtime", tc.remembered))
+			}
+
+			mockAUR := &mockaur.MockAUR{GetFn: func(ctx context.Context, query *aurc.Query) ([]aurc.Pkg, error) {
+				assert.Equal(t, aurc.Provides, query.By)
+				assert.Equal(t, []string{"java-runtime"}, query.Needles)
+
+				return aurPkgs, nil
+			}}
+
+			g := NewGrapher(mock.NewExecutor().Sync(repoPkg).Local(tc.installed...).Build(), mockAUR,
+				false, false, false, false, false,
+				text.NewLogger(io.Discard, io.Discard, strings.NewReader(tc.input), true, "test"))
+			g.SetProviderChoices(providers)
+			g.SetProviderPolicy(tc.policy)
+
+			got := g.preferAURProvider(context.Background(), tc.depString, repoPkg)
+			assert.Equal(t, tc.want != "", got)
+
+			if tc.want != "" {
+				require.Len(t, g.providerCache[tc.depString], 1)
+				assert.Equal(t, tc.want, g.providerCache[tc.depString][0].Name)
+			}
+
+			if tc.input != "" {
+				name, ok := providers.Get("java-runtime")
+				require.True(t, ok)
+
+				if tc.want == "" {
+					assert.Equal(t, "jre-openjdk", name)
+				} else {
+					assert.Equal(t, tc.want, name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+//go:build !integration
+// +build !integration
+
+package dep
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jguer/yippee/v12/pkg/db/mock"
+	mockaur "github.com/Jguer/yippee/v12/pkg/dep/mock"
+	aur "github.com/Jguer/yippee/v12/pkg/query"
+	"github.com/Jguer/yippee/v12/pkg/text"
+)
+
+func TestGrapher_SharedMakeDepReason(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc    string
+		targets []string
+		want    map[string]Reason
+	}{
+		{
+			desc:    "make dependency only",
+			targets: []string{"builder"},
+			want:    map[string]Reason{"builder": Explicit, "cmake": MakeDep, "ninja": MakeDep},
+		},
+		{
+			desc:    "make dependency of one target and dependency of another",
+			targets: []string{"builder", "runner"},
+			want:    map[string]Reason{"builder": Explicit, "runner": Explicit, "cmake": Dep, "ninja": MakeDep},
+		},
+		{
+			desc:    "in the other order",
+			targets: []string{"runner", "builder"},
+			want:    map[string]Reason{"builder": Explicit, "runner": Explicit, "cmake": Dep, "ninja": MakeDep},
+		},
+		{
+			desc:    "make dependency and dependency of the same target",
+			targets: []string{"both"},
+			want:    map[string]Reason{"both": Explicit, "cmake": Dep},
+		},
+		{
+			desc:    "dependency satisfied by the provider of a make dependency",
+			targets: []string{"builder", "provided"},
+			want:    map[string]Reason{"builder": Explicit, "provided": Explicit, "cmake": MakeDep, "ninja": Dep},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			dbExecutor := mock.NewExecutor().Sync(
+				mock.NewPackage("cmake", "3.28-1"),
+				mock.NewPackage("ninja", "1.11-1").WithProvides("ninja-build=1.11"),
+			).Build()
+
+			aurCache := mockaur.NewAUR(
+				aur.Pkg{Name: "builder", PackageBase: "builder", Version: "1.0-1", MakeDepends: []string{"cmake", "ninja"}},
+				aur.Pkg{Name: "runner", PackageBase: "runner", Version: "1.0-1", Depends: []string{"cmake"}},
+				aur.Pkg{
+					Name: "both", PackageBase: "both", Version: "1.0-1",
+					MakeDepends: []string{"cmake"}, Depends: []string{"cmake"},
+				},
+				aur.Pkg{Name: "provided", PackageBase: "provided", Version: "1.0-1", Depends: []string{"ninja-build"}},
+			)
+
+			g := NewGrapher(dbExecutor, aurCache, false, true, false, false, false,
+				text.NewLogger(io.Discard, io.Discard, strings.NewReader(""), true, "test"))
+
+			graph, err := g.GraphFromAUR(context.Background(), nil, tc.targets)
+			require.NoError(t, err)
+
+			got := map[string]Reason{}
+			require.NoError(t, graph.ForEach(func(name string, info *InstallInfo) error {
+				got[name] = info.Reason
+				return nil
+			}))
+
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
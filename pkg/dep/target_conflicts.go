@@ -0,0 +1,116 @@
+package dep
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/leonelquinteros/gotext"
+
+	aur "github.com/Jguer/yippee/v12/pkg/query"
+	"github.com/Jguer/yippee/v12/pkg/text"
+)
+
+// targetsConflict reports whether a and b can not be installed together
+// because one conflicts with the other or with something it provides, like
+// the -bin and -git variants of a package.
+func targetsConflict(a, b *aur.Pkg) bool {
+	if a.Name == b.Name {
+		return false
+	}
+
+	for _, conflict := range a.Conflicts {
+		if satisfiesAur(conflict, b) {
+			return true
+		}
+	}
+
+	for _, conflict := range b.Conflicts {
+		if satisfiesAur(conflict, a) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// resolveTargetConflicts asks which package to keep for each pair of
+// conflicting targets, so the choice happens before anything is built.
+func (g *Grapher) resolveTargetConflicts(pkgs []*aur.Pkg) []*aur.Pkg {
+	kept := make([]*aur.Pkg, 0, len(pkgs))
+
+	for _, pkg := range pkgs {
+		keep := true
+
+		for i := 0; i < len(kept); i++ {
+			if !targetsConflict(kept[i], pkg) {
+				continue
+			}
+
+			if g.conflictMenu(kept[i], pkg) == kept[i] {
+				keep = false
+				break
+			}
+
+			kept = append(kept[:i], kept[i+1:]...)
+			i--
+		}
+
+		if keep {
+			kept = append(kept, pkg)
+		}
+	}
+
+	return kept
+}
+
+// conflictMenu asks which of two conflicting targets to install.
+func (g *Grapher) conflictMenu(a, b *aur.Pkg) *aur.Pkg {
+	options := []*aur.Pkg{a, b}
+
+	str := text.Bold(gotext.Get("%s and %s are in conflict, choose one to install:",
+		text.Cyan(a.Name), text.Cyan(b.Name)))
+	str += "\n    "
+
+	for i, option := range options {
+		str += fmt.Sprintf("%d) %s ", i+1, option.Name)
+	}
+
+	g.logger.OperationInfoln(str)
+
+	for {
+		g.logger.Println(gotext.Get("\nEnter a number (default=1): "))
+
+		if g.noConfirm {
+			g.logger.Println("1")
+
+			return a
+		}
+
+		numberBuf, err := g.logger.GetInput("", false)
+		if err != nil {
+			g.logger.Errorln(err)
+
+			return a
+		}
+
+		if numberBuf == "" {
+			return a
+		}
+
+		num, err := strconv.Atoi(numberBuf)
+		if err != nil {
+			g.logger.Errorln(gotext.Get("invalid number: %s", numberBuf))
+
+			continue
+		}
+
+		if num < 1 || num > len(options) {
+			g.logger.Errorln(gotext.Get("invalid value: %d is not between %d and %d",
+				num, 1, len(options)))
+
+			continue
+		}
+
+		return options[num-1]
+	}
+}
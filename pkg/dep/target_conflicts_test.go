@@ -0,0 +1,77 @@
+//go:build !integration
+// +build !integration
+
+package dep
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jguer/yippee/v12/pkg/db/mock"
+	mockaur "github.com/Jguer/yippee/v12/pkg/dep/mock"
+	aur "github.com/Jguer/yippee/v12/pkg/query"
+	"github.com/Jguer/yippee/v12/pkg/text"
+)
+
+func TestTargetsConflict(t *testing.T) {
+	t.Parallel()
+
+	bin := &aur.Pkg{Name: "foo-bin", Version: "1.0-1", Provides: []string{"foo=1.0"}, Conflicts: []string{"foo"}}
+	git := &aur.Pkg{Name: "foo-git", Version: "r1.abc-1", Provides: []string{"foo"}, Conflicts: []string{"foo"}}
+	old := &aur.Pkg{Name: "foo-old", Version: "0.9-1", Conflicts: []string{"foo<1.0"}}
+	bar := &aur.Pkg{Name: "bar", Version: "1.0-1", Depends: []string{"foo"}}
+
+	assert.True(t, targetsConflict(bin, git))
+	assert.True(t, targetsConflict(git, bin))
+	assert.False(t, targetsConflict(old, bin))
+	assert.False(t, targetsConflict(bin, bar))
+	assert.False(t, targetsConflict(bin, bin))
+}
+
+func TestGraphFromAUR_ConflictingTargets(t *testing.T) {
+	t.Parallel()
+
+	aurClient := mockaur.NewAUR(
+		aur.Pkg{Name: "foo-bin", PackageBase: "foo-bin", Version: "1.0-1", Provides: []string{"foo"}, Conflicts: []string{"foo"}},
+		aur.Pkg{Name: "foo-git", PackageBase: "foo-git", Version: "r1.abc-1", Provides: []string{"foo"}, Conflicts: []string{"foo"}},
+		aur.Pkg{Name: "bar", PackageBase: "bar", Version: "1.0-1"},
+	)
+
+	testCases := []struct {
+		desc      string
+		input     string
+		noConfirm bool
+		want      []string
+	}{
+		{desc: "noconfirm keeps the first", noConfirm: true, want: []string{"bar", "foo-bin"}},
+		{desc: "default keeps the first", input: "\n", want: []string{"bar", "foo-bin"}},
+		{desc: "pick the second", input: "2\n", want: []string{"bar", "foo-git"}},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			dbExecutor := mock.NewExecutor().Build()
+			g := NewGrapher(dbExecutor, aurClient, false, tc.noConfirm, true, false, false,
+				text.NewLogger(io.Discard, io.Discard, strings.NewReader(tc.input), true, "test"))
+
+			graph, err := g.GraphFromAUR(context.Background(), nil, []string{"foo-bin", "bar", "foo-git"})
+			require.NoError(t, err)
+
+			names := []string{}
+			_ = graph.ForEach(func(name string, _ *InstallInfo) error {
+				names = append(names, name)
+				return nil
+			})
+
+			assert.ElementsMatch(t, tc.want, names)
+		})
+	}
+}
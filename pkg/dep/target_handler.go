@@ -1,23 +1,37 @@
 package dep
 
-import "github.com/Jguer/yippee/v12/pkg/text"
+import (
+	"strings"
+
+	"github.com/Jguer/yippee/v12/pkg/text"
+)
 
 type Target struct {
 	DB      string
 	Name    string
 	Mod     string
 	Version string
+	// Arch is the architecture of a pkgname:arch target, only a package
+	// built for it is installed.
+	Arch string
 }
 
 func ToTarget(pkg string) Target {
 	dbName, depString := text.SplitDBFromName(pkg)
 	name, mod, depVersion := splitDep(depString)
 
+	// pkgnames have no colon, in a version it is the epoch
+	arch := ""
+	if mod == "" {
+		name, arch, _ = strings.Cut(name, ":")
+	}
+
 	return Target{
 		DB:      dbName,
 		Name:    name,
 		Mod:     mod,
 		Version: depVersion,
+		Arch:    arch,
 	}
 }
 
@@ -26,9 +40,14 @@ func (t Target) DepString() string {
 }
 
 func (t Target) String() string {
+	str := t.DepString()
+	if t.Arch != "" {
+		str += ":" + t.Arch
+	}
+
 	if t.DB != "" {
-		return t.DB + "/" + t.DepString()
+		return t.DB + "/" + str
 	}
 
-	return t.DepString()
+	return str
 }
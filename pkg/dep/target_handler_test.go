@@ -0,0 +1,65 @@
+//go:build !integration
+// +build !integration
+
+package dep
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jguer/yippee/v12/pkg/db/mock"
+	mockaur "github.com/Jguer/yippee/v12/pkg/dep/mock"
+	aur "github.com/Jguer/yippee/v12/pkg/query"
+	"github.com/Jguer/yippee/v12/pkg/text"
+)
+
+func TestToTarget(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		target string
+		want   Target
+	}{
+		{target: "yippee", want: Target{Name: "yippee"}},
+		{target: "aur/yippee", want: Target{DB: "aur", Name: "yippee"}},
+		{target: "yippee:any", want: Target{Name: "yippee", Arch: "any"}},
+		{target: "extra/yippee:x86_64", want: Target{DB: "extra", Name: "yippee", Arch: "x86_64"}},
+		{target: "yippee>=1:12.0", want: Target{Name: "yippee", Mod: ">=", Version: "1:12.0"}},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.target, func(t *testing.T) {
+			t.Parallel()
+
+			got := ToTarget(tc.target)
+			assert.Equal(t, tc.want, got)
+			assert.Equal(t, tc.target, got.String())
+		})
+	}
+}
+
+func TestGrapher_GraphFromTargets_Arch(t *testing.T) {
+	t.Parallel()
+
+	dbExecutor := mock.NewExecutor().Sync(
+		mock.NewPackage("glibc", "2.38-7").WithDB("core").WithArch("x86_64"),
+		mock.NewPackage("tzdata", "2024a-1").WithDB("core").WithArch("any"),
+	).Build()
+
+	g := NewGrapher(dbExecutor, mockaur.NewAUR(aur.Pkg{Name: "yippee-bin", PackageBase: "yippee-bin", Version: "12.0-1"}),
+		false, true, false, false, false, text.NewLogger(io.Discard, io.Discard, strings.NewReader(""), true, "test"))
+
+	graph, err := g.GraphFromTargets(context.Background(), nil,
+		[]string{"glibc:any", "core/tzdata:any", "yippee-bin:x86_64"})
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, []string{"tzdata", "yippee-bin"}, graphNodes(t, graph))
+	assert.Equal(t, "x86_64", graph.GetNodeInfo("yippee-bin").Value.Arch)
+	assert.Empty(t, graph.GetNodeInfo("tzdata").Value.Arch)
+}
@@ -0,0 +1,229 @@
+package dirsize
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+)
+
+// dirEntry is what is remembered of a directory: the size of itself and of
+// the files directly in it, and its subdirectories.
+type dirEntry struct {
+	ModTime int64    `json:"mtime"`
+	Size    int64    `json:"size"`
+	Dirs    []string `json:"dirs,omitempty"`
+}
+
+// Cache sizes directory trees, remembering every directory walked by its
+// modification time. Creating, removing or renaming an entry of a directory
+// changes that time, so an unchanged directory is not read again, only
+// stated. A file rewritten in place is missed until its directory changes,
+// which caches of package archives and PKGBUILD clones rarely do. A cache
+// with no FilePath is not saved.
+type Cache struct {
+	FilePath string
+
+	dirs map[string]dirEntry
+	seen map[string]bool
+	mux  sync.Mutex
+}
+
+func NewCache(filePath string) *Cache {
+	return &Cache{
+		FilePath: filePath,
+		dirs:     make(map[string]dirEntry),
+		seen:     make(map[string]bool),
+	}
+}
+
+// Load reads the sizes from disk. A missing file is not an error.
+func (c *Cache) Load() error {
+	if c.FilePath == "" {
+		return nil
+	}
+
+	content, err := os.ReadFile(c.FilePath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to open directory sizes file '%s': %w", c.FilePath, err)
+	}
+
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	if err := json.Unmarshal(content, &c.dirs); err != nil {
+		return fmt.Errorf("failed to read directory sizes file '%s': %w", c.FilePath, err)
+	}
+
+	return nil
+}
+
+// Save writes the directories walked since Load to disk, the others are
+// forgotten.
+func (c *Cache) Save() error {
+	if c.FilePath == "" {
+		return nil
+	}
+
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	walked := make(map[string]dirEntry, len(c.seen))
+	for path := range c.seen {
+		walked[path] = c.dirs[path]
+	}
+
+	marshalled, err := json.Marshal(walked)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(c.FilePath, marshalled, 0o644)
+}
+
+// Size returns the size of the tree at path, directories included, 0 when
+// it does not exist.
+func (c *Cache) Size(path string) int64 {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return 0
+	}
+
+	if !info.IsDir() {
+		return info.Size()
+	}
+
+	return c.dirSize(path, info)
+}
+
+// Sizes returns the size of the tree at every path, walking up to one tree
+// per CPU at once.
+func (c *Cache) Sizes(paths []string) map[string]int64 {
+	var (
+		mux sync.Mutex
+		wg  sync.WaitGroup
+	)
+
+	sizes := make(map[string]int64, len(paths))
+	sem := make(chan uint8, runtime.NumCPU())
+
+	for _, path := range paths {
+		sem <- 1
+
+		wg.Add(1)
+
+		go func(path string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			size := c.Size(path)
+
+			mux.Lock()
+			sizes[path] = size
+			mux.Unlock()
+		}(path)
+	}
+
+	wg.Wait()
+
+	return sizes
+}
+
+// Breakdown returns the size of the tree at dir along with the size of each
+// of its subdirectories by name, sized in parallel.
+func (c *Cache) Breakdown(dir string) (total int64, byName map[string]int64) {
+	byName = make(map[string]int64)
+
+	info, err := os.Lstat(dir)
+	if err != nil || !info.IsDir() {
+		return c.Size(dir), byName
+	}
+
+	entry := c.entry(dir, info)
+
+	paths := make([]string, 0, len(entry.Dirs))
+	for _, name := range entry.Dirs {
+		paths = append(paths, filepath.Join(dir, name))
+	}
+
+	total = entry.Size
+
+	for path, size := range c.Sizes(paths) {
+		byName[filepath.Base(path)] = size
+		total += size
+	}
+
+	return total, byName
+}
+
+func (c *Cache) dirSize(path string, info fs.FileInfo) int64 {
+	entry := c.entry(path, info)
+	size := entry.Size
+
+	for _, name := range entry.Dirs {
+		sub := filepath.Join(path, name)
+
+		subInfo, err := os.Lstat(sub)
+		if err != nil || !subInfo.IsDir() {
+			continue
+		}
+
+		size += c.dirSize(sub, subInfo)
+	}
+
+	return size
+}
+
+// entry returns what is known of the directory at path, reading it again
+// when it changed since.
+func (c *Cache) entry(path string, info fs.FileInfo) dirEntry {
+	modTime := info.ModTime().UnixNano()
+
+	c.mux.Lock()
+	entry, ok := c.dirs[path]
+	c.mux.Unlock()
+
+	if !ok || entry.ModTime != modTime {
+		entry = readDir(path, info)
+	}
+
+	c.mux.Lock()
+	c.dirs[path] = entry
+	c.seen[path] = true
+	c.mux.Unlock()
+
+	return entry
+}
+
+func readDir(path string, info fs.FileInfo) dirEntry {
+	entry := dirEntry{ModTime: info.ModTime().UnixNano(), Size: info.Size()}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		// read again next time
+		entry.ModTime = 0
+		return entry
+	}
+
+	for _, child := range entries {
+		if child.IsDir() {
+			entry.Dirs = append(entry.Dirs, child.Name())
+			continue
+		}
+
+		if childInfo, err := child.Info(); err == nil {
+			entry.Size += childInfo.Size()
+		}
+	}
+
+	return entry
+}
@@ -0,0 +1,97 @@
+//go:build !integration
+// +build !integration
+
+package dirsize
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func walkSize(t *testing.T, path string) (size int64) {
+	t.Helper()
+
+	require.NoError(t, filepath.WalkDir(path, func(p string, entry fs.DirEntry, err error) error {
+		require.NoError(t, err)
+
+		info, err := entry.Info()
+		require.NoError(t, err)
+
+		size += info.Size()
+
+		return nil
+	}))
+
+	return size
+}
+
+func writeFile(t *testing.T, path string, size int) {
+	t.Helper()
+
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, make([]byte, size), 0o644))
+}
+
+func TestCacheSize(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "foo", "PKGBUILD"), 10)
+	writeFile(t, filepath.Join(dir, "foo", "src", "foo.tar.gz"), 1000)
+	writeFile(t, filepath.Join(dir, "bar", "PKGBUILD"), 20)
+	writeFile(t, filepath.Join(dir, "notes"), 5)
+
+	cacheFile := filepath.Join(t.TempDir(), "dir_sizes.json")
+
+	cache := NewCache(cacheFile)
+	require.NoError(t, cache.Load())
+
+	total, byName := cache.Breakdown(dir)
+	assert.Equal(t, walkSize(t, dir), total)
+	assert.Equal(t, map[string]int64{
+		"foo": walkSize(t, filepath.Join(dir, "foo")),
+		"bar": walkSize(t, filepath.Join(dir, "bar")),
+	}, byName)
+	require.NoError(t, cache.Save())
+
+	// a loaded cache reads the changed directories again
+	writeFile(t, filepath.Join(dir, "bar", "bar.pkg.tar.zst"), 300)
+
+	barDir := filepath.Join(dir, "bar")
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(barDir, later, later))
+
+	cache = NewCache(cacheFile)
+	require.NoError(t, cache.Load())
+
+	sizes := cache.Sizes([]string{dir, filepath.Join(dir, "missing")})
+	assert.Equal(t, map[string]int64{dir: walkSize(t, dir), filepath.Join(dir, "missing"): 0}, sizes)
+}
+
+func TestCacheUnchangedDirectory(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "foo", "PKGBUILD"), 10)
+
+	cache := NewCache("")
+	want := cache.Size(dir)
+
+	// the size of a file rewritten in place is remembered until its
+	// directory changes
+	fooDir := filepath.Join(dir, "foo")
+	info, err := os.Stat(fooDir)
+	require.NoError(t, err)
+
+	writeFile(t, filepath.Join(fooDir, "PKGBUILD"), 50)
+	require.NoError(t, os.Chtimes(fooDir, info.ModTime(), info.ModTime()))
+
+	assert.Equal(t, want, cache.Size(dir))
+	assert.Equal(t, want, NewCache("").Size(dir)-40)
+}
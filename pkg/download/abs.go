@@ -82,11 +82,12 @@ func ABSPKGBUILD(httpClient httpRequestDoer, dbName, pkgName string) ([]byte, er
 }
 
 // ABSPKGBUILDRepo retrieves the PKGBUILD repository to a dest directory.
+// Shallow clones only fetch the latest commit.
 func ABSPKGBUILDRepo(ctx context.Context, cmdBuilder exe.GitCmdBuilder,
-	dbName, pkgName, dest string, force bool,
+	dbName, pkgName, dest string, force, shallow bool,
 ) (bool, error) {
 	pkgURL := getPackageRepoURL(pkgName)
 
 	return downloadGitRepo(ctx, cmdBuilder, pkgURL,
-		pkgName, dest, force, "--single-branch")
+		pkgName, dest, force, cloneArgs(shallow, "--single-branch")...)
 }
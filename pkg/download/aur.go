@@ -2,19 +2,25 @@ package download
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"net/http"
 	"net/url"
+	"os"
+	"path/filepath"
 	"sync"
 
 	"github.com/leonelquinteros/gotext"
 
+	"github.com/Jguer/yippee/v12/pkg/events"
 	"github.com/Jguer/yippee/v12/pkg/multierror"
 	"github.com/Jguer/yippee/v12/pkg/settings/exe"
 	"github.com/Jguer/yippee/v12/pkg/text"
 )
 
+// AURPKGBUILD fetches a PKGBUILD through the AUR cgit interface.
 func AURPKGBUILD(httpClient httpRequestDoer, pkgName, aurURL string) ([]byte, error) {
 	values := url.Values{}
 	values.Set("h", pkgName)
@@ -25,12 +31,15 @@ func AURPKGBUILD(httpClient httpRequestDoer, pkgName, aurURL string) ([]byte, er
 		return nil, err
 	}
 
-	if resp.StatusCode != http.StatusOK {
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode >= http.StatusInternalServerError:
+		return nil, ErrAURCgitUnavailable{pkgName: pkgName, status: resp.StatusCode}
+	case resp.StatusCode != http.StatusOK:
 		return nil, ErrAURPackageNotFound{pkgName: pkgName}
 	}
 
-	defer resp.Body.Close()
-
 	pkgBuild, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, err
@@ -39,17 +48,118 @@ func AURPKGBUILD(httpClient httpRequestDoer, pkgName, aurURL string) ([]byte, er
 	return pkgBuild, nil
 }
 
+// AURPKGBUILDGit fetches a PKGBUILD through the AUR git interface, with a
+// shallow clone of the package repository to a temporary directory.
+func AURPKGBUILDGit(ctx context.Context, cmdBuilder exe.GitCmdBuilder, pkgName, aurURL string) ([]byte, error) {
+	dir, err := os.MkdirTemp("", "yippee-pkgbuild-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	if _, err := downloadGitRepo(ctx, cmdBuilder, aurRepoURL(aurURL, pkgName),
+		pkgName, dir, false, cloneArgs(true)...); err != nil {
+		return nil, err
+	}
+
+	// the AUR serves an empty repository for unknown packages
+	pkgbuild, err := os.ReadFile(filepath.Join(dir, pkgName, "PKGBUILD"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrAURPackageNotFound{pkgName: pkgName}
+	}
+
+	return pkgbuild, err
+}
+
+// fetchAURPKGBUILD fetches a PKGBUILD through cgit, or through git when
+// preferGit is set, falling back to the other interface when the first one
+// fails for another reason than the package not existing.
+func fetchAURPKGBUILD(ctx context.Context, httpClient httpRequestDoer, cmdBuilder exe.GitCmdBuilder,
+	logger *text.Logger, pkgName, aurURL string, preferGit bool,
+) ([]byte, error) {
+	fromCgit := func() ([]byte, error) { return AURPKGBUILD(httpClient, pkgName, aurURL) }
+	fromGit := func() ([]byte, error) { return AURPKGBUILDGit(ctx, cmdBuilder, pkgName, aurURL) }
+
+	fetch, fallback := fromCgit, fromGit
+	if preferGit {
+		fetch, fallback = fromGit, fromCgit
+	}
+
+	pkgbuild, err := fetch()
+	if err == nil || errors.As(err, &ErrAURPackageNotFound{}) {
+		return pkgbuild, err
+	}
+
+	logger.Debugln("falling back to the other AUR interface for", pkgName+":", err)
+
+	return fallback()
+}
+
+func aurRepoURL(aurURL, pkgName string) string {
+	return fmt.Sprintf("%s/%s.git", aurURL, pkgName)
+}
+
 // AURPkgbuildRepo retrieves the PKGBUILD repository to a dest directory.
-func AURPKGBUILDRepo(ctx context.Context, cmdBuilder exe.GitCmdBuilder, aurURL, pkgName, dest string, force bool) (bool, error) {
-	pkgURL := fmt.Sprintf("%s/%s.git", aurURL, pkgName)
+// It warns when the repository does not contain the requested pkgbase.
+// Shallow clones only fetch the latest commit.
+// With snapshots, a repository that cannot be cloned is made of the AUR
+// snapshot instead, or the other way around when snapshots are preferred.
+// A repository made of snapshots is updated with snapshots.
+func AURPKGBUILDRepo(ctx context.Context, cmdBuilder exe.GitCmdBuilder, logger *text.Logger,
+	snapshots *SnapshotSource, aurURL, pkgName, dest string, force, shallow bool,
+) (bool, error) {
+	dir := filepath.Join(dest, pkgName)
+	fromSnapshot := isSnapshotRepo(dir)
+
+	// a repository made of snapshots has no remote to pull from
+	fromGit := func() (bool, error) {
+		return downloadGitRepo(ctx, cmdBuilder, aurRepoURL(aurURL, pkgName), pkgName, dest,
+			force || isSnapshotRepo(dir), cloneArgs(shallow)...)
+	}
+
+	var (
+		newClone bool
+		err      error
+	)
+
+	if snapshots == nil {
+		newClone, err = fromGit()
+	} else {
+		_, errStat := os.Stat(filepath.Join(dir, ".git"))
+		cloning := force || fromSnapshot || os.IsNotExist(errStat)
+
+		fromTarball := func() (bool, error) {
+			return snapshots.AURSnapshotRepo(ctx, cmdBuilder, aurURL, pkgName, dest, force)
+		}
 
-	return downloadGitRepo(ctx, cmdBuilder, pkgURL, pkgName, dest, force)
+		fetch, fallback := fromGit, fromTarball
+		if snapshots.Prefer || (fromSnapshot && !force) {
+			fetch, fallback = fromTarball, fromGit
+		}
+
+		newClone, err = fetch()
+		if err != nil && cloning && !errors.As(err, &ErrAURPackageNotFound{}) {
+			logger.Debugln("falling back to the other AUR repository source for", pkgName+":", err)
+
+			newClone, err = fallback()
+		}
+	}
+
+	if err != nil {
+		return newClone, err
+	}
+
+	if reason := verifyPKGBUILDRepo(filepath.Join(dest, pkgName), pkgName); reason != "" {
+		logger.Warnln(gotext.Get("AUR repository of %s may be wrong: %s", text.Cyan(pkgName), reason))
+	}
+
+	return newClone, nil
 }
 
 func AURPKGBUILDRepos(
 	ctx context.Context,
-	cmdBuilder exe.GitCmdBuilder, logger *text.Logger,
-	targets []string, aurURL, dest string, force bool,
+	cmdBuilder exe.GitCmdBuilder, logger *text.Logger, emitter *events.Emitter, snapshots *SnapshotSource,
+	targets []string, aurURL, dest string, force, shallow bool,
 ) (map[string]bool, error) {
 	cloned := make(map[string]bool, len(targets))
 
@@ -71,26 +181,30 @@ func AURPKGBUILDRepos(
 				wg.Done()
 			}()
 
-			newClone, err := AURPKGBUILDRepo(ctx, cmdBuilder, aurURL, target, dest, force)
+			emitter.Emit(events.CloneStarted, target)
+
+			newClone, err := AURPKGBUILDRepo(ctx, cmdBuilder, logger, snapshots, aurURL, target, dest, force, shallow)
 
 			mux.Lock()
 			progress := len(cloned)
 			if err != nil {
 				errs.Add(err)
 				mux.Unlock()
+				emitter.EmitError(events.StageClone, target, err)
 				logger.OperationInfoln(
-					gotext.Get("(%d/%d) Failed to download PKGBUILD: %s",
-						progress, len(targets), text.Cyan(target)))
+					gotext.Get("%s Failed to download PKGBUILD: %s",
+						text.Progress(progress, len(targets)), text.Cyan(text.Isolate(target))))
 				return
 			}
 
 			cloned[target] = newClone
 			progress = len(cloned)
 			mux.Unlock()
+			emitter.Emit(events.CloneFinished, target)
 
 			logger.OperationInfoln(
-				gotext.Get("(%d/%d) Downloaded PKGBUILD: %s",
-					progress, len(targets), text.Cyan(target)))
+				gotext.Get("%s Downloaded PKGBUILD: %s",
+					text.Progress(progress, len(targets)), text.Cyan(text.Isolate(target))))
 		}(target)
 	}
 
@@ -9,9 +9,12 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/Jguer/yippee/v12/pkg/settings/exe"
 )
@@ -53,6 +56,17 @@ func TestGetAURPkgbuild(t *testing.T) {
 			want:    "",
 			wantErr: true,
 		},
+		{
+			name: "cgit unavailable",
+			args: args{
+				body:    "",
+				status:  503,
+				pkgName: "git-extras",
+				wantURL: "https://aur.archlinux.org/cgit/aur.git/plain/PKGBUILD?h=git-extras",
+			},
+			want:    "",
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		tt := tt
@@ -101,7 +115,7 @@ func TestAURPKGBUILDRepo(t *testing.T) {
 			GitFlags: []string{"--no-replace-objects"},
 		},
 	}
-	newCloned, err := AURPKGBUILDRepo(context.Background(), cmdBuilder, "https://aur.archlinux.org", "yippee-bin", "/tmp/doesnt-exist", false)
+	newCloned, err := AURPKGBUILDRepo(context.Background(), cmdBuilder, newTestLogger(), nil, "https://aur.archlinux.org", "yippee-bin", "/tmp/doesnt-exist", false, false)
 	assert.NoError(t, err)
 	assert.Equal(t, true, newCloned)
 }
@@ -135,7 +149,7 @@ func TestAURPKGBUILDRepoExistsPerms(t *testing.T) {
 			GitFlags: []string{"--no-replace-objects"},
 		},
 	}
-	cloned, err := AURPKGBUILDRepo(context.Background(), cmdBuilder, "https://aur.archlinux.org", "yippee-bin", dir, false)
+	cloned, err := AURPKGBUILDRepo(context.Background(), cmdBuilder, newTestLogger(), nil, "https://aur.archlinux.org", "yippee-bin", dir, false, false)
 	assert.NoError(t, err)
 	assert.Equal(t, false, cloned)
 }
@@ -158,8 +172,145 @@ func TestAURPKGBUILDRepos(t *testing.T) {
 			GitFlags: []string{},
 		},
 	}
-	cloned, err := AURPKGBUILDRepos(context.Background(), cmdBuilder, newTestLogger(), targets, "https://aur.archlinux.org", dir, false)
+	cloned, err := AURPKGBUILDRepos(context.Background(), cmdBuilder, newTestLogger(), nil, nil, targets, "https://aur.archlinux.org", dir, false, false)
 
 	assert.NoError(t, err)
 	assert.EqualValues(t, map[string]bool{"yippee": true, "yippee-bin": false, "yippee-git": true}, cloned)
 }
+
+func TestVerifyPKGBUILDRepo(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	assert.NotEmpty(t, verifyPKGBUILDRepo(dir, "yippee-bin"))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "PKGBUILD"), []byte(""), 0o600))
+	assert.Empty(t, verifyPKGBUILDRepo(dir, "yippee-bin"))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".SRCINFO"),
+		[]byte("pkgbase = yippee-bin\n\tpkgver = 12.0.4\n\tpkgrel = 1\n\tarch = x86_64\n\npkgname = yippee-bin\n"), 0o600))
+	assert.Empty(t, verifyPKGBUILDRepo(dir, "yippee-bin"))
+	assert.Contains(t, verifyPKGBUILDRepo(dir, "yippee"), "yippee-bin")
+}
+
+type recordingGitBuilder struct {
+	mux  sync.Mutex
+	cmds []string
+}
+
+func (r *recordingGitBuilder) BuildGitCmd(ctx context.Context, dir string, extraArgs ...string) *exec.Cmd {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	r.cmds = append(r.cmds, filepath.Base(dir)+": "+strings.Join(extraArgs, " "))
+
+	return exec.CommandContext(ctx, "git", extraArgs...)
+}
+
+func (r *recordingGitBuilder) Show(cmd *exec.Cmd) error {
+	return nil
+}
+
+func (r *recordingGitBuilder) Capture(cmd *exec.Cmd) (stdout, stderr string, err error) {
+	return "", "", nil
+}
+
+func TestGCRepos(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "deep", ".git"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "shallow", ".git"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "shallow", ".git", "shallow"), []byte{}, 0o600))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "not-a-repo"), 0o755))
+
+	cmdBuilder := &recordingGitBuilder{}
+	require.NoError(t, GCRepos(context.Background(), cmdBuilder, newTestLogger(), dir, true))
+
+	assert.ElementsMatch(t, []string{
+		"deep: fetch --quiet --depth=1",
+		"deep: reflog expire --expire=now --all",
+		"deep: gc --quiet --prune=now",
+		"shallow: gc --quiet --prune=now",
+	}, cmdBuilder.cmds)
+}
+
+func TestAURPKGBUILDRepoShallow(t *testing.T) {
+	t.Parallel()
+
+	cmdBuilder := &recordingGitBuilder{}
+	_, err := AURPKGBUILDRepo(context.Background(), cmdBuilder, newTestLogger(), nil,
+		"https://aur.archlinux.org", "yippee-bin", "/tmp/doesnt-exist", false, true)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"doesnt-exist: clone --no-progress --depth=1 https://aur.archlinux.org/yippee-bin.git yippee-bin",
+	}, cmdBuilder.cmds)
+}
+
+// cloneBuilder fakes git clones by writing pkgbuild to the cloned directory.
+type cloneBuilder struct {
+	testRunner
+	pkgbuild string
+}
+
+func (c *cloneBuilder) BuildGitCmd(ctx context.Context, dir string, extraArgs ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "git", extraArgs...)
+	cmd.Dir = dir
+
+	return cmd
+}
+
+func (c *cloneBuilder) Capture(cmd *exec.Cmd) (stdout, stderr string, err error) {
+	if cmd.Args[1] != "clone" || c.pkgbuild == "" {
+		return "", "", nil
+	}
+
+	dir := filepath.Join(cmd.Dir, cmd.Args[len(cmd.Args)-1])
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", "", err
+	}
+
+	return "", "", os.WriteFile(filepath.Join(dir, "PKGBUILD"), []byte(c.pkgbuild), 0o600)
+}
+
+func TestFetchAURPKGBUILD(t *testing.T) {
+	t.Parallel()
+
+	cgitURL := "https://aur.archlinux.org/cgit/aur.git/plain/PKGBUILD?h=git-extras"
+
+	tests := []struct {
+		name      string
+		status    int
+		gitBody   string
+		preferGit bool
+		want      string
+		wantErr   bool
+	}{
+		{name: "cgit", status: 200, want: "from cgit"},
+		{name: "cgit unavailable falls back to git", status: 503, gitBody: "from git", want: "from git"},
+		{name: "cgit not found does not fall back", status: 404, gitBody: "from git", wantErr: true},
+		{name: "git preferred", status: 200, gitBody: "from git", preferGit: true, want: "from git"},
+		{name: "git without PKGBUILD does not fall back", status: 200, preferGit: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			httpClient := &testClient{t: t, wantURL: cgitURL, body: "from cgit", status: tt.status}
+
+			got, err := fetchAURPKGBUILD(context.Background(), httpClient, &cloneBuilder{pkgbuild: tt.gitBody},
+				newTestLogger(), "git-extras", "https://aur.archlinux.org", tt.preferGit)
+			if tt.wantErr {
+				assert.ErrorAs(t, err, &ErrAURPackageNotFound{})
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
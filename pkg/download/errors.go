@@ -15,6 +15,17 @@ func (e ErrAURPackageNotFound) Error() string {
 	return fmt.Sprintln(gotext.Get("package not found in AUR"), ":", e.pkgName)
 }
 
+// ErrAURCgitUnavailable means that the AUR cgit interface is down or rate
+// limiting requests.
+type ErrAURCgitUnavailable struct {
+	pkgName string
+	status  int
+}
+
+func (e ErrAURCgitUnavailable) Error() string {
+	return gotext.Get("AUR cgit unavailable (HTTP %d) fetching %s", e.status, e.pkgName)
+}
+
 type ErrGetPKGBUILDRepo struct {
 	inner   error
 	pkgName string
@@ -0,0 +1,89 @@
+package download
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/leonelquinteros/gotext"
+
+	"github.com/Jguer/yippee/v12/pkg/multierror"
+	"github.com/Jguer/yippee/v12/pkg/settings/exe"
+	"github.com/Jguer/yippee/v12/pkg/text"
+)
+
+// MaxConcurrentGC is the amount of repositories garbage collected at once.
+const MaxConcurrentGC = 4
+
+// GCRepos runs git gc on every PKGBUILD repository in dir. With shallow,
+// deep clones are converted to shallow clones before being collected.
+func GCRepos(ctx context.Context, cmdBuilder exe.GitCmdBuilder, logger *text.Logger,
+	dir string, shallow bool,
+) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	var (
+		errs multierror.MultiError
+		wg   sync.WaitGroup
+	)
+
+	sem := make(chan uint8, MaxConcurrentGC)
+
+	for _, entry := range entries {
+		repoDir := filepath.Join(dir, entry.Name())
+		if _, err := os.Stat(filepath.Join(repoDir, ".git")); !entry.IsDir() || err != nil {
+			continue
+		}
+
+		sem <- 1
+
+		wg.Add(1)
+
+		go func(repoDir string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			if err := gcRepo(ctx, cmdBuilder, repoDir, shallow); err != nil {
+				errs.Add(err)
+				return
+			}
+
+			logger.Debugln("collected garbage of", repoDir)
+		}(repoDir)
+	}
+
+	wg.Wait()
+
+	return errs.Return()
+}
+
+func gcRepo(ctx context.Context, cmdBuilder exe.GitCmdBuilder, repoDir string, shallow bool) error {
+	steps := [][]string{}
+
+	if _, err := os.Stat(filepath.Join(repoDir, ".git", "shallow")); shallow && os.IsNotExist(err) {
+		steps = append(steps,
+			[]string{"fetch", "--quiet", "--depth=1"},
+			[]string{"reflog", "expire", "--expire=now", "--all"})
+	}
+
+	steps = append(steps, []string{"gc", "--quiet", "--prune=now"})
+
+	for _, args := range steps {
+		cmd := cmdBuilder.BuildGitCmd(ctx, repoDir, args...)
+		if _, stderr, err := cmdBuilder.Capture(cmd); err != nil {
+			return ErrGetPKGBUILDRepo{
+				inner:   err,
+				pkgName: filepath.Base(repoDir),
+				errOut:  gotext.Get("error collecting garbage: %s", stderr),
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package download
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// aurMissTTL is how long a target found missing from the AUR is not looked
+// up again.
+const aurMissTTL = 15 * time.Minute
+
+// MissCache remembers the targets recently found missing from the AUR, so
+// repeated lookups of them do not reach the RPC. A nil *MissCache remembers
+// nothing.
+type MissCache struct {
+	FilePath string
+
+	misses map[string]time.Time
+	mux    sync.Mutex
+	now    func() time.Time
+}
+
+func NewMissCache(filePath string) *MissCache {
+	return &MissCache{
+		FilePath: filePath,
+		misses:   make(map[string]time.Time),
+		now:      time.Now,
+	}
+}
+
+// Load reads the cached misses from disk. A missing file is not an error.
+func (c *MissCache) Load() error {
+	content, err := os.ReadFile(c.FilePath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to open AUR misses file '%s': %w", c.FilePath, err)
+	}
+
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	if err := json.Unmarshal(content, &c.misses); err != nil {
+		return fmt.Errorf("failed to read AUR misses file '%s': %w", c.FilePath, err)
+	}
+
+	return nil
+}
+
+// Missing reports whether name was found missing from the AUR recently.
+func (c *MissCache) Missing(name string) bool {
+	if c == nil {
+		return false
+	}
+
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	missed, ok := c.misses[name]
+
+	return ok && c.now().Sub(missed) < aurMissTTL
+}
+
+// Add records name as missing from the AUR and saves the cache to disk,
+// dropping the expired entries.
+func (c *MissCache) Add(name string) error {
+	if c == nil {
+		return nil
+	}
+
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	now := c.now()
+	c.misses[name] = now
+
+	for missName, missed := range c.misses {
+		if now.Sub(missed) >= aurMissTTL {
+			delete(c.misses, missName)
+		}
+	}
+
+	marshalled, err := json.MarshalIndent(c.misses, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(c.FilePath, marshalled, 0o644)
+}
@@ -0,0 +1,74 @@
+//go:build !integration
+// +build !integration
+
+package download
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jguer/aur"
+
+	mockaur "github.com/Jguer/yippee/v12/pkg/dep/mock"
+	"github.com/Jguer/yippee/v12/pkg/settings/parser"
+)
+
+func TestGetPackageUsableName_MissCache(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "aur_misses.json")
+	now := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	queries := 0
+	mockClient := &mockaur.MockAUR{
+		GetFn: func(ctx context.Context, query *aur.Query) ([]aur.Pkg, error) {
+			queries++
+			if query.Needles[0] == "yippee" {
+				return []aur.Pkg{{Name: "yippee"}}, nil
+			}
+
+			return []aur.Pkg{}, nil
+		},
+	}
+
+	newCache := func() *MissCache {
+		misses := NewMissCache(path)
+		misses.now = func() time.Time { return now }
+		require.NoError(t, misses.Load())
+
+		return misses
+	}
+
+	lookup := func(misses *MissCache, target string) bool {
+		_, _, _, toSkip := getPackageUsableName(&testDBSearcher{}, mockClient, misses,
+			newTestLogger(), target, parser.ModeAny)
+
+		return toSkip
+	}
+
+	assert.True(t, lookup(newCache(), "nope"))
+	assert.Equal(t, 1, queries)
+
+	// the miss is remembered across runs
+	assert.True(t, lookup(newCache(), "nope"))
+	assert.Equal(t, 1, queries)
+
+	// found packages are not cached
+	assert.False(t, lookup(newCache(), "yippee"))
+	assert.False(t, lookup(newCache(), "yippee"))
+	assert.Equal(t, 3, queries)
+
+	now = now.Add(aurMissTTL)
+
+	assert.True(t, lookup(newCache(), "nope"))
+	assert.Equal(t, 4, queries)
+
+	// without a cache every lookup reaches the AUR
+	assert.True(t, lookup(nil, "nope"))
+	assert.Equal(t, 5, queries)
+}
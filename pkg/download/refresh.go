@@ -0,0 +1,122 @@
+package download
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	gosrc "github.com/Morganamilo/go-srcinfo"
+
+	"github.com/Jguer/yippee/v12/pkg/multierror"
+	"github.com/Jguer/yippee/v12/pkg/settings/exe"
+)
+
+// RefreshedRepo describes a PKGBUILD repository that changed upstream.
+type RefreshedRepo struct {
+	Base       string
+	OldVersion string
+	NewVersion string
+}
+
+// RefreshRepos pulls every PKGBUILD repository in dir concurrently and
+// returns the repositories that received new commits, sorted by base.
+func RefreshRepos(ctx context.Context, cmdBuilder exe.GitCmdBuilder, dir string) ([]RefreshedRepo, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		mux     sync.Mutex
+		errs    multierror.MultiError
+		wg      sync.WaitGroup
+		changed = make([]RefreshedRepo, 0)
+	)
+
+	sem := make(chan uint8, MaxConcurrentFetch)
+
+	for _, entry := range entries {
+		repoDir := filepath.Join(dir, entry.Name())
+		if _, err := os.Stat(filepath.Join(repoDir, ".git")); !entry.IsDir() || err != nil {
+			continue
+		}
+
+		sem <- 1
+
+		wg.Add(1)
+
+		go func(repoDir string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			refreshed, err := refreshRepo(ctx, cmdBuilder, repoDir)
+			if err != nil {
+				errs.Add(err)
+				return
+			}
+
+			if refreshed != nil {
+				mux.Lock()
+				changed = append(changed, *refreshed)
+				mux.Unlock()
+			}
+		}(repoDir)
+	}
+
+	wg.Wait()
+
+	sort.Slice(changed, func(i, j int) bool { return changed[i].Base < changed[j].Base })
+
+	return changed, errs.Return()
+}
+
+// refreshRepo pulls repoDir and returns nil if HEAD did not move.
+func refreshRepo(ctx context.Context, cmdBuilder exe.GitCmdBuilder, repoDir string) (*RefreshedRepo, error) {
+	pkgBase := filepath.Base(repoDir)
+
+	head := func() (string, error) {
+		stdout, stderr, err := cmdBuilder.Capture(cmdBuilder.BuildGitCmd(ctx, repoDir, "rev-parse", "HEAD"))
+		if err != nil {
+			return "", ErrGetPKGBUILDRepo{inner: err, pkgName: pkgBase, errOut: stderr}
+		}
+
+		return strings.TrimSpace(stdout), nil
+	}
+
+	before, err := head()
+	if err != nil {
+		return nil, err
+	}
+
+	oldVersion := srcinfoVersion(repoDir)
+
+	cmd := cmdBuilder.BuildGitCmd(ctx, repoDir, "pull", "--rebase", "--autostash")
+	if _, stderr, err := cmdBuilder.Capture(cmd); err != nil {
+		return nil, ErrGetPKGBUILDRepo{inner: err, pkgName: pkgBase, errOut: stderr}
+	}
+
+	after, err := head()
+	if err != nil {
+		return nil, err
+	}
+
+	if before == after {
+		return nil, nil
+	}
+
+	return &RefreshedRepo{Base: pkgBase, OldVersion: oldVersion, NewVersion: srcinfoVersion(repoDir)}, nil
+}
+
+func srcinfoVersion(repoDir string) string {
+	srcinfo, err := gosrc.ParseFile(filepath.Join(repoDir, ".SRCINFO"))
+	if err != nil {
+		return ""
+	}
+
+	return srcinfo.Version()
+}
@@ -0,0 +1,79 @@
+//go:build !integration
+// +build !integration
+
+package download
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pullingGitBuilder moves HEAD of the repositories in updated on pull.
+type pullingGitBuilder struct {
+	mux     sync.Mutex
+	updated map[string]bool
+	pulled  map[string]bool
+}
+
+func (p *pullingGitBuilder) BuildGitCmd(ctx context.Context, dir string, extraArgs ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "git", extraArgs...)
+	cmd.Dir = dir
+
+	return cmd
+}
+
+func (p *pullingGitBuilder) Show(cmd *exec.Cmd) error {
+	return nil
+}
+
+func (p *pullingGitBuilder) Capture(cmd *exec.Cmd) (stdout, stderr string, err error) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	base := filepath.Base(cmd.Dir)
+
+	switch strings.Join(cmd.Args[1:], " ") {
+	case "pull --rebase --autostash":
+		p.pulled[base] = true
+		if p.updated[base] {
+			err = os.WriteFile(filepath.Join(cmd.Dir, ".SRCINFO"),
+				[]byte("pkgbase = "+base+"\n\tpkgver = 2.0\n\tpkgrel = 1\n\tarch = any\n\npkgname = "+base+"\n"), 0o600)
+		}
+	case "rev-parse HEAD":
+		if p.pulled[base] && p.updated[base] {
+			return "bbbbbbb\n", "", nil
+		}
+
+		return "aaaaaaa\n", "", nil
+	}
+
+	return "", "", err
+}
+
+func TestRefreshRepos(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	for _, base := range []string{"yippee", "yippee-bin"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, base, ".git"), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, base, ".SRCINFO"),
+			[]byte("pkgbase = "+base+"\n\tpkgver = 1.0\n\tpkgrel = 1\n\tarch = any\n\npkgname = "+base+"\n"), 0o600))
+	}
+
+	cmdBuilder := &pullingGitBuilder{updated: map[string]bool{"yippee-bin": true}, pulled: map[string]bool{}}
+
+	changed, err := RefreshRepos(context.Background(), cmdBuilder, dir)
+	require.NoError(t, err)
+
+	assert.Equal(t, []RefreshedRepo{{Base: "yippee-bin", OldVersion: "1.0-1", NewVersion: "2.0-1"}}, changed)
+	assert.Equal(t, map[string]bool{"yippee": true, "yippee-bin": true}, cmdBuilder.pulled)
+}
@@ -0,0 +1,247 @@
+package download
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/leonelquinteros/gotext"
+
+	"github.com/Jguer/yippee/v12/pkg/settings/exe"
+)
+
+const (
+	// snapshotBranch holds the imported snapshots, it is the upstream of the
+	// checked out branch like origin/master is for a clone.
+	snapshotBranch = "aur-snapshot"
+	// snapshotMarker, in the .git directory, tells a repository of imported
+	// snapshots from a clone.
+	snapshotMarker = "yippee-snapshot"
+)
+
+// SnapshotSource downloads the snapshot tarballs of the AUR cgit interface,
+// for when git cannot reach the AUR. Prefer tries the snapshots before git.
+type SnapshotSource struct {
+	HTTPClient httpRequestDoer
+	Prefer     bool
+}
+
+func isSnapshotRepo(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, ".git", snapshotMarker))
+	return err == nil
+}
+
+// AURSnapshotRepo downloads the AUR snapshot of pkgName and commits it to a
+// repository in dest. The snapshots are imported on a branch the checked
+// out branch tracks, pulling then works like it does for a clone, and so do
+// the diffs of the reviewed changes.
+func (s *SnapshotSource) AURSnapshotRepo(ctx context.Context, cmdBuilder exe.GitCmdBuilder,
+	aurURL, pkgName, dest string, force bool,
+) (bool, error) {
+	dir := filepath.Join(dest, pkgName)
+
+	if force {
+		if err := os.RemoveAll(dir); err != nil {
+			return false, ErrGetPKGBUILDRepo{inner: err, pkgName: pkgName, errOut: ""}
+		}
+	}
+
+	tmpDir, err := os.MkdirTemp(dest, "."+pkgName+"-snapshot-")
+	if err != nil {
+		return false, ErrGetPKGBUILDRepo{inner: err, pkgName: pkgName, errOut: ""}
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if err := s.downloadSnapshot(aurURL, pkgName, tmpDir); err != nil {
+		return false, err
+	}
+
+	newRepo := false
+
+	if _, err := os.Stat(filepath.Join(dir, ".git")); os.IsNotExist(err) {
+		if err := initSnapshotRepo(ctx, cmdBuilder, dir); err != nil {
+			return false, ErrGetPKGBUILDRepo{inner: err, pkgName: pkgName, errOut: ""}
+		}
+
+		newRepo = true
+	}
+
+	if err := importSnapshot(ctx, cmdBuilder, dir, tmpDir, newRepo); err != nil {
+		return false, ErrGetPKGBUILDRepo{inner: err, pkgName: pkgName, errOut: err.Error()}
+	}
+
+	return newRepo, nil
+}
+
+func (s *SnapshotSource) downloadSnapshot(aurURL, pkgName, dest string) error {
+	resp, err := s.HTTPClient.Get(aurURL + "/cgit/aur.git/snapshot/" + url.PathEscape(pkgName) + ".tar.gz")
+	if err != nil {
+		return ErrGetPKGBUILDRepo{inner: err, pkgName: pkgName, errOut: ""}
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode >= http.StatusInternalServerError:
+		return ErrAURCgitUnavailable{pkgName: pkgName, status: resp.StatusCode}
+	case resp.StatusCode != http.StatusOK:
+		return ErrAURPackageNotFound{pkgName: pkgName}
+	}
+
+	if err := extractSnapshot(resp.Body, dest); err != nil {
+		return ErrGetPKGBUILDRepo{inner: err, pkgName: pkgName, errOut: ""}
+	}
+
+	return nil
+}
+
+// extractSnapshot extracts the files of a snapshot tarball to dest, without
+// the pkgbase directory they are in. Only files and directories are kept.
+func extractSnapshot(r io.Reader, dest string) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		_, name, _ := strings.Cut(filepath.ToSlash(header.Name), "/")
+		if name == "" {
+			continue
+		}
+
+		if filepath.IsAbs(name) || !filepath.IsLocal(name) {
+			return errors.New(gotext.Get("invalid path in snapshot: %s", header.Name))
+		}
+
+		path := filepath.Join(dest, name)
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(path, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := writeSnapshotFile(path, tr, header.FileInfo().Mode().Perm()); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func writeSnapshotFile(path string, r io.Reader, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+func initSnapshotRepo(ctx context.Context, cmdBuilder exe.GitCmdBuilder, dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	if err := runGit(ctx, cmdBuilder, dir, "init", "--quiet"); err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dir, ".git", snapshotMarker), []byte{}, 0o644)
+}
+
+// importSnapshot commits the files in snapshotDir on top of the snapshot
+// branch of the repository in dir, then brings the checked out branch up to
+// date with it.
+func importSnapshot(ctx context.Context, cmdBuilder exe.GitCmdBuilder, dir, snapshotDir string, newRepo bool) error {
+	if err := runGit(ctx, cmdBuilder, dir, "--work-tree="+snapshotDir, "add", "--all"); err != nil {
+		return err
+	}
+
+	tree, err := captureGit(ctx, cmdBuilder, dir, "write-tree")
+	if err != nil {
+		return err
+	}
+
+	args := []string{"-c", "user.name=yippee", "-c", "user.email=yippee@localhost", "commit-tree", tree}
+
+	parent, errParent := captureGit(ctx, cmdBuilder, dir, "rev-parse", "--quiet", "--verify", snapshotBranch)
+	if errParent == nil && parent != "" {
+		parentTree, err := captureGit(ctx, cmdBuilder, dir, "rev-parse", parent+"^{tree}")
+		if err != nil {
+			return err
+		}
+
+		args = append(args, "-p", parent)
+
+		if parentTree == tree {
+			args = nil // nothing changed since the last snapshot
+		}
+	}
+
+	if args != nil {
+		commit, err := captureGit(ctx, cmdBuilder, dir, append(args, "-m", "AUR snapshot")...)
+		if err != nil {
+			return err
+		}
+
+		if err := runGit(ctx, cmdBuilder, dir, "update-ref", "refs/heads/"+snapshotBranch, commit); err != nil {
+			return err
+		}
+	}
+
+	if newRepo {
+		if err := runGit(ctx, cmdBuilder, dir, "reset", "--quiet", "--hard", snapshotBranch); err != nil {
+			return err
+		}
+
+		return runGit(ctx, cmdBuilder, dir, "branch", "--quiet", "--set-upstream-to="+snapshotBranch)
+	}
+
+	// the index holds the snapshot, pulling would take it for local changes
+	if err := runGit(ctx, cmdBuilder, dir, "read-tree", "HEAD"); err != nil {
+		return err
+	}
+
+	return runGit(ctx, cmdBuilder, dir, "pull", "--quiet", "--rebase", "--autostash")
+}
+
+func captureGit(ctx context.Context, cmdBuilder exe.GitCmdBuilder, dir string, args ...string) (string, error) {
+	stdout, stderr, err := cmdBuilder.Capture(cmdBuilder.BuildGitCmd(ctx, dir, args...))
+	if err != nil {
+		return "", errors.New(gotext.Get("git %s failed: %s", strings.Join(args, " "), strings.TrimSpace(stderr)))
+	}
+
+	return strings.TrimSpace(stdout), nil
+}
+
+func runGit(ctx context.Context, cmdBuilder exe.GitCmdBuilder, dir string, args ...string) error {
+	_, err := captureGit(ctx, cmdBuilder, dir, args...)
+	return err
+}
@@ -0,0 +1,156 @@
+//go:build !integration
+// +build !integration
+
+package download
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jguer/yippee/v12/pkg/settings/exe"
+)
+
+func makeSnapshot(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "yippee-bin/", Typeflag: tar.TypeDir, Mode: 0o755}))
+
+	for name, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{
+			Name: name, Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(content)),
+		}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+
+	return buf.String()
+}
+
+func TestExtractSnapshot(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		files   map[string]string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name: "files without the pkgbase directory",
+			files: map[string]string{
+				"yippee-bin/PKGBUILD":       "pkgname=yippee-bin",
+				"yippee-bin/.SRCINFO":       "pkgbase = yippee-bin",
+				"yippee-bin/patches/a.diff": "diff",
+			},
+			want: map[string]string{
+				"PKGBUILD":       "pkgname=yippee-bin",
+				".SRCINFO":       "pkgbase = yippee-bin",
+				"patches/a.diff": "diff",
+			},
+		},
+		{
+			name:    "path out of the directory",
+			files:   map[string]string{"yippee-bin/../../evil": "evil"},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			err := extractSnapshot(strings.NewReader(makeSnapshot(t, tc.files)), dir)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+
+			for name, content := range tc.want {
+				got, err := os.ReadFile(filepath.Join(dir, name))
+				require.NoError(t, err)
+				assert.Equal(t, content, string(got))
+			}
+		})
+	}
+}
+
+// GIVEN the AUR cannot be cloned with git
+// WHEN AURPKGBUILDRepo is called with snapshots
+// THEN the snapshot is committed to a new repository
+func TestAURPKGBUILDRepoSnapshotFallback(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	ran := make([]string, 0)
+
+	cmdBuilder := &exe.MockBuilder{Runner: &exe.MockRunner{
+		CaptureFn: func(cmd *exec.Cmd) (string, string, error) {
+			args := strings.Join(cmd.Args[1:], " ")
+			ran = append(ran, args)
+
+			switch {
+			case strings.HasPrefix(args, "clone"):
+				return "", "fatal: unable to access", errors.New("exit status 128")
+			case args == "init --quiet":
+				return "", "", os.MkdirAll(filepath.Join(dir, "yippee-bin", ".git"), 0o755)
+			case strings.HasPrefix(args, "rev-parse"):
+				return "", "", errors.New("exit status 1")
+			case args == "write-tree":
+				return "tree\n", "", nil
+			case strings.Contains(args, "commit-tree"):
+				return "commit\n", "", nil
+			}
+
+			return "", "", nil
+		},
+	}}
+
+	snapshots := &SnapshotSource{HTTPClient: &testClient{
+		t:       t,
+		wantURL: "https://aur.archlinux.org/cgit/aur.git/snapshot/yippee-bin.tar.gz",
+		body:    makeSnapshot(t, map[string]string{"yippee-bin/PKGBUILD": "pkgname=yippee-bin"}),
+		status:  200,
+	}}
+
+	cloned, err := AURPKGBUILDRepo(context.Background(), cmdBuilder, newTestLogger(), snapshots,
+		"https://aur.archlinux.org", "yippee-bin", dir, false, false)
+	require.NoError(t, err)
+	assert.True(t, cloned)
+	assert.True(t, isSnapshotRepo(filepath.Join(dir, "yippee-bin")))
+
+	require.Len(t, ran, 9)
+	assert.True(t, strings.HasPrefix(ran[0], "clone --no-progress"))
+	assert.Regexp(t, "^--work-tree=.+ add --all$", ran[2])
+	assert.Equal(t, []string{
+		"init --quiet",
+		"write-tree",
+		"rev-parse --quiet --verify aur-snapshot",
+		"-c user.name=yippee -c user.email=yippee@localhost commit-tree tree -m AUR snapshot",
+		"update-ref refs/heads/aur-snapshot commit",
+		"reset --quiet --hard aur-snapshot",
+		"branch --quiet --set-upstream-to=aur-snapshot",
+	}, append([]string{ran[1]}, ran[3:]...))
+}
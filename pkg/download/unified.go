@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 
 	"github.com/leonelquinteros/gotext"
@@ -12,6 +13,7 @@ import (
 	"github.com/Jguer/aur"
 
 	"github.com/Jguer/yippee/v12/pkg/db"
+	"github.com/Jguer/yippee/v12/pkg/events"
 	"github.com/Jguer/yippee/v12/pkg/multierror"
 	"github.com/Jguer/yippee/v12/pkg/settings/exe"
 	"github.com/Jguer/yippee/v12/pkg/settings/parser"
@@ -72,6 +74,15 @@ func downloadGitRepo(ctx context.Context, cmdBuilder exe.GitCmdBuilder,
 	return newClone, nil
 }
 
+// cloneArgs returns the extra git clone arguments.
+func cloneArgs(shallow bool, args ...string) []string {
+	if shallow {
+		args = append(args, "--depth=1")
+	}
+
+	return args
+}
+
 func getURLName(pkg db.IPackage) string {
 	name := pkg.Base()
 	if name == "" {
@@ -81,8 +92,14 @@ func getURLName(pkg db.IPackage) string {
 	return name
 }
 
-func PKGBUILDs(dbExecutor DBSearcher, aurClient aur.QueryClient, httpClient *http.Client,
-	logger *text.Logger, targets []string, aurURL string, mode parser.TargetMode,
+// PKGBUILDs fetches the PKGBUILDs of targets from the ABS and the AUR. The
+// AUR is queried through cgit unless preferGit is set, each interface being
+// the fallback of the other. Targets recently missing from the AUR are
+// skipped through misses.
+func PKGBUILDs(ctx context.Context, dbExecutor DBSearcher, aurClient aur.QueryClient, misses *MissCache,
+	httpClient *http.Client,
+	cmdBuilder exe.GitCmdBuilder, logger *text.Logger, targets []string, aurURL string, mode parser.TargetMode,
+	preferGit bool,
 ) (map[string][]byte, error) {
 	pkgbuilds := make(map[string][]byte, len(targets))
 
@@ -96,7 +113,7 @@ func PKGBUILDs(dbExecutor DBSearcher, aurClient aur.QueryClient, httpClient *htt
 
 	for _, target := range targets {
 		// Probably replaceable by something in query.
-		dbName, name, isAUR, toSkip := getPackageUsableName(dbExecutor, aurClient, logger, target, mode)
+		dbName, name, isAUR, toSkip := getPackageUsableName(dbExecutor, aurClient, misses, logger, target, mode)
 		if toSkip {
 			continue
 		}
@@ -112,7 +129,7 @@ func PKGBUILDs(dbExecutor DBSearcher, aurClient aur.QueryClient, httpClient *htt
 			)
 
 			if aur {
-				pkgbuild, err = AURPKGBUILD(httpClient, pkgName, aurURL)
+				pkgbuild, err = fetchAURPKGBUILD(ctx, httpClient, cmdBuilder, logger, pkgName, aurURL, preferGit)
 			} else {
 				pkgbuild, err = ABSPKGBUILD(httpClient, dbName, pkgName)
 			}
@@ -135,9 +152,9 @@ func PKGBUILDs(dbExecutor DBSearcher, aurClient aur.QueryClient, httpClient *htt
 	return pkgbuilds, errs.Return()
 }
 
-func PKGBUILDRepos(ctx context.Context, dbExecutor DBSearcher, aurClient aur.QueryClient,
-	cmdBuilder exe.GitCmdBuilder, logger *text.Logger,
-	targets []string, mode parser.TargetMode, aurURL, dest string, force bool,
+func PKGBUILDRepos(ctx context.Context, dbExecutor DBSearcher, aurClient aur.QueryClient, misses *MissCache,
+	cmdBuilder exe.GitCmdBuilder, logger *text.Logger, emitter *events.Emitter, snapshots *SnapshotSource,
+	targets []string, mode parser.TargetMode, aurURL, dest string, force, shallow bool,
 ) (map[string]bool, error) {
 	cloned := make(map[string]bool, len(targets))
 
@@ -147,65 +164,100 @@ func PKGBUILDRepos(ctx context.Context, dbExecutor DBSearcher, aurClient aur.Que
 		wg   sync.WaitGroup
 	)
 
-	sem := make(chan uint8, MaxConcurrentFetch)
+	// resolve every target first so progress is counted against the
+	// targets actually downloaded
+	type repoTarget struct {
+		target, dbName, pkgName string
+		aur                     bool
+	}
+
+	toFetch := make([]repoTarget, 0, len(targets))
+	skipped := make([]string, 0)
 
 	for _, target := range targets {
 		// Probably replaceable by something in query.
-		dbName, name, isAUR, toSkip := getPackageUsableName(dbExecutor, aurClient, logger, target, mode)
+		dbName, name, isAUR, toSkip := getPackageUsableName(dbExecutor, aurClient, misses, logger, target, mode)
 		if toSkip {
+			skipped = append(skipped, target)
 			continue
 		}
 
+		toFetch = append(toFetch, repoTarget{target: target, dbName: dbName, pkgName: name, aur: isAUR})
+	}
+
+	sem := make(chan uint8, MaxConcurrentFetch)
+	done := 0
+
+	for _, repo := range toFetch {
 		sem <- 1
 
 		wg.Add(1)
 
-		go func(target, dbName, pkgName string, aur bool) {
+		go func(repo repoTarget) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
 			var (
 				err      error
 				newClone bool
 			)
 
-			if aur {
-				newClone, err = AURPKGBUILDRepo(ctx, cmdBuilder, aurURL, pkgName, dest, force)
+			emitter.Emit(events.CloneStarted, repo.pkgName)
+
+			if repo.aur {
+				newClone, err = AURPKGBUILDRepo(ctx, cmdBuilder, logger, snapshots, aurURL, repo.pkgName, dest, force, shallow)
 			} else {
-				newClone, err = ABSPKGBUILDRepo(ctx, cmdBuilder, dbName, pkgName, dest, force)
+				newClone, err = ABSPKGBUILDRepo(ctx, cmdBuilder, repo.dbName, repo.pkgName, dest, force, shallow)
 			}
 
-			progress := 0
+			mux.Lock()
+			done++
+			progress := text.Progress(done, len(toFetch))
 
 			if err != nil {
 				errs.Add(err)
 			} else {
-				mux.Lock()
-				cloned[target] = newClone
-				progress = len(cloned)
-				mux.Unlock()
+				cloned[repo.target] = newClone
 			}
+			mux.Unlock()
 
-			if aur {
-				logger.OperationInfoln(
-					gotext.Get("(%d/%d) Downloaded PKGBUILD: %s",
-						progress, len(targets), text.Cyan(pkgName)))
+			if err != nil {
+				emitter.EmitError(events.StageClone, repo.pkgName, err)
 			} else {
-				logger.OperationInfoln(
-					gotext.Get("(%d/%d) Downloaded PKGBUILD from ABS: %s",
-						progress, len(targets), text.Cyan(pkgName)))
+				emitter.Emit(events.CloneFinished, repo.pkgName)
 			}
 
-			<-sem
-
-			wg.Done()
-		}(target, dbName, name, isAUR)
+			switch {
+			case err != nil:
+				logger.OperationInfoln(
+					gotext.Get("%s Failed to download PKGBUILD: %s",
+						progress, text.Cyan(text.Isolate(repo.pkgName))))
+			case repo.aur:
+				logger.OperationInfoln(
+					gotext.Get("%s Downloaded PKGBUILD: %s",
+						progress, text.Cyan(text.Isolate(repo.pkgName))))
+			default:
+				logger.OperationInfoln(
+					gotext.Get("%s Downloaded PKGBUILD from ABS: %s",
+						progress, text.Cyan(text.Isolate(repo.pkgName))))
+			}
+		}(repo)
 	}
 
 	wg.Wait()
 
+	if len(skipped) != 0 {
+		logger.Warnln(gotext.GetN("Skipped %d target:", "Skipped %d targets:", len(skipped), len(skipped)),
+			" ", strings.Join(skipped, ", "))
+	}
+
 	return cloned, errs.Return()
 }
 
 // TODO: replace with dep.ResolveTargets.
-func getPackageUsableName(dbExecutor DBSearcher, aurClient aur.QueryClient,
+func getPackageUsableName(dbExecutor DBSearcher, aurClient aur.QueryClient, misses *MissCache,
 	logger *text.Logger, target string, mode parser.TargetMode,
 ) (dbname, pkgname string, isAUR, toSkip bool) {
 	dbName, name := text.SplitDBFromName(target)
@@ -229,7 +281,7 @@ func getPackageUsableName(dbExecutor DBSearcher, aurClient aur.QueryClient,
 		}
 	}
 
-	if mode == parser.ModeRepo {
+	if mode == parser.ModeRepo || misses.Missing(name) {
 		return dbName, name, true, true
 	}
 
@@ -244,6 +296,10 @@ func getPackageUsableName(dbExecutor DBSearcher, aurClient aur.QueryClient,
 	}
 
 	if len(pkgs) == 0 {
+		if err := misses.Add(name); err != nil {
+			logger.Debugln(err)
+		}
+
 		return dbName, name, true, true
 	}
 
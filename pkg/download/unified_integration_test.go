@@ -41,9 +41,9 @@ func TestIntegrationPKGBUILDReposDefinedDBClone(t *testing.T) {
 	searcher := &testDBSearcher{
 		absPackagesDB: map[string]string{"linux": "core"},
 	}
-	cloned, err := PKGBUILDRepos(context.Background(), searcher, mockClient,
-		cmdBuilder, testLogger.Child("test"),
-		targets, parser.ModeAny, "https://aur.archlinux.org", dir, false)
+	cloned, err := PKGBUILDRepos(context.Background(), searcher, mockClient, nil,
+		cmdBuilder, testLogger.Child("test"), nil, nil,
+		targets, parser.ModeAny, "https://aur.archlinux.org", dir, false, false)
 
 	assert.NoError(t, err)
 	assert.EqualValues(t, map[string]bool{"core/linux": true, "yippee-bin": true, "yippee-git": true}, cloned)
@@ -70,9 +70,9 @@ func TestIntegrationPKGBUILDReposNotExist(t *testing.T) {
 	searcher := &testDBSearcher{
 		absPackagesDB: map[string]string{"yippee": "core"},
 	}
-	cloned, err := PKGBUILDRepos(context.Background(), searcher, mockClient,
-		cmdBuilder, testLogger.Child("test"),
-		targets, parser.ModeAny, "https://aur.archlinux.org", dir, false)
+	cloned, err := PKGBUILDRepos(context.Background(), searcher, mockClient, nil,
+		cmdBuilder, testLogger.Child("test"), nil, nil,
+		targets, parser.ModeAny, "https://aur.archlinux.org", dir, false, false)
 
 	assert.Error(t, err)
 	assert.EqualValues(t, map[string]bool{"yippee-bin": true, "yippee-git": true}, cloned)
@@ -94,8 +94,8 @@ func TestIntegrationPKGBUILDFull(t *testing.T) {
 		absPackagesDB: map[string]string{"linux": "core"},
 	}
 
-	fetched, err := PKGBUILDs(searcher, mockClient, &http.Client{}, testLogger.Child("test"),
-		targets, "https://aur.archlinux.org", parser.ModeAny)
+	fetched, err := PKGBUILDs(context.Background(), searcher, mockClient, nil, &http.Client{}, nil, testLogger.Child("test"),
+		targets, "https://aur.archlinux.org", parser.ModeAny, false)
 
 	assert.NoError(t, err)
 
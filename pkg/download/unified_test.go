@@ -18,6 +18,7 @@ import (
 	"github.com/Jguer/aur"
 
 	mockaur "github.com/Jguer/yippee/v12/pkg/dep/mock"
+	"github.com/Jguer/yippee/v12/pkg/events"
 	"github.com/Jguer/yippee/v12/pkg/settings/exe"
 	"github.com/Jguer/yippee/v12/pkg/settings/parser"
 	"github.com/Jguer/yippee/v12/pkg/text"
@@ -60,9 +61,9 @@ func TestPKGBUILDReposDefinedDBPull(t *testing.T) {
 	searcher := &testDBSearcher{
 		absPackagesDB: map[string]string{"yippee": "core"},
 	}
-	cloned, err := PKGBUILDRepos(context.Background(), searcher, mockClient,
-		cmdBuilder, newTestLogger(),
-		targets, parser.ModeAny, "https://aur.archlinux.org", dir, false)
+	cloned, err := PKGBUILDRepos(context.Background(), searcher, mockClient, nil,
+		cmdBuilder, newTestLogger(), nil, nil,
+		targets, parser.ModeAny, "https://aur.archlinux.org", dir, false, false)
 
 	assert.NoError(t, err)
 	assert.EqualValues(t, map[string]bool{"core/yippee": false, "yippee-bin": true, "yippee-git": true}, cloned)
@@ -94,9 +95,9 @@ func TestPKGBUILDReposDefinedDBClone(t *testing.T) {
 	searcher := &testDBSearcher{
 		absPackagesDB: map[string]string{"yippee": "core"},
 	}
-	cloned, err := PKGBUILDRepos(context.Background(), searcher, mockClient,
-		cmdBuilder, newTestLogger(),
-		targets, parser.ModeAny, "https://aur.archlinux.org", dir, false)
+	cloned, err := PKGBUILDRepos(context.Background(), searcher, mockClient, nil,
+		cmdBuilder, newTestLogger(), nil, nil,
+		targets, parser.ModeAny, "https://aur.archlinux.org", dir, false, false)
 
 	assert.NoError(t, err)
 	assert.EqualValues(t, map[string]bool{"core/yippee": true, "yippee-bin": true, "yippee-git": true}, cloned)
@@ -128,9 +129,9 @@ func TestPKGBUILDReposClone(t *testing.T) {
 	searcher := &testDBSearcher{
 		absPackagesDB: map[string]string{"yippee": "core"},
 	}
-	cloned, err := PKGBUILDRepos(context.Background(), searcher, mockClient,
-		cmdBuilder, newTestLogger(),
-		targets, parser.ModeAny, "https://aur.archlinux.org", dir, false)
+	cloned, err := PKGBUILDRepos(context.Background(), searcher, mockClient, nil,
+		cmdBuilder, newTestLogger(), nil, nil,
+		targets, parser.ModeAny, "https://aur.archlinux.org", dir, false, false)
 
 	assert.NoError(t, err)
 	assert.EqualValues(t, map[string]bool{"yippee": true, "yippee-bin": true, "yippee-git": true}, cloned)
@@ -162,9 +163,9 @@ func TestPKGBUILDReposNotFound(t *testing.T) {
 	searcher := &testDBSearcher{
 		absPackagesDB: map[string]string{"yippee": "core"},
 	}
-	cloned, err := PKGBUILDRepos(context.Background(), searcher, mockClient,
-		cmdBuilder, newTestLogger(),
-		targets, parser.ModeAny, "https://aur.archlinux.org", dir, false)
+	cloned, err := PKGBUILDRepos(context.Background(), searcher, mockClient, nil,
+		cmdBuilder, newTestLogger(), nil, nil,
+		targets, parser.ModeAny, "https://aur.archlinux.org", dir, false, false)
 
 	assert.NoError(t, err)
 	assert.EqualValues(t, map[string]bool{"yippee-bin": true, "yippee-git": true}, cloned)
@@ -196,9 +197,9 @@ func TestPKGBUILDReposRepoMode(t *testing.T) {
 	searcher := &testDBSearcher{
 		absPackagesDB: map[string]string{"yippee": "core"},
 	}
-	cloned, err := PKGBUILDRepos(context.Background(), searcher, mockClient,
-		cmdBuilder, newTestLogger(),
-		targets, parser.ModeRepo, "https://aur.archlinux.org", dir, false)
+	cloned, err := PKGBUILDRepos(context.Background(), searcher, mockClient, nil,
+		cmdBuilder, newTestLogger(), nil, nil,
+		targets, parser.ModeRepo, "https://aur.archlinux.org", dir, false, false)
 
 	assert.NoError(t, err)
 	assert.EqualValues(t, map[string]bool{"yippee": true}, cloned)
@@ -235,8 +236,8 @@ func TestPKGBUILDFull(t *testing.T) {
 		absPackagesDB: map[string]string{"yippee": "core"},
 	}
 
-	fetched, err := PKGBUILDs(searcher, mockClient, &http.Client{}, newTestLogger(),
-		targets, "https://aur.archlinux.org", parser.ModeAny)
+	fetched, err := PKGBUILDs(context.Background(), searcher, mockClient, nil, &http.Client{}, nil, newTestLogger(),
+		targets, "https://aur.archlinux.org", parser.ModeAny, false)
 
 	assert.NoError(t, err)
 	assert.EqualValues(t, map[string][]byte{
@@ -272,10 +273,52 @@ func TestPKGBUILDReposMissingAUR(t *testing.T) {
 	searcher := &testDBSearcher{
 		absPackagesDB: map[string]string{"yippee": "core"},
 	}
-	cloned, err := PKGBUILDRepos(context.Background(), searcher, mockClient,
-		cmdBuilder, newTestLogger(),
-		targets, parser.ModeAny, "https://aur.archlinux.org", dir, false)
+	cloned, err := PKGBUILDRepos(context.Background(), searcher, mockClient, nil,
+		cmdBuilder, newTestLogger(), nil, nil,
+		targets, parser.ModeAny, "https://aur.archlinux.org", dir, false, false)
 
 	assert.NoError(t, err)
 	assert.EqualValues(t, map[string]bool{"core/yippee": true}, cloned)
 }
+
+// GIVEN 2 aur packages and 1 in repo
+// WHEN aur packages are not found
+// THEN progress should only count the repo package and the rest be reported as skipped
+func TestPKGBUILDReposProgressSkipped(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+
+	mockClient := &mockaur.MockAUR{
+		GetFn: func(ctx context.Context, query *aur.Query) ([]aur.Pkg, error) {
+			return []aur.Pkg{}, nil
+		},
+	}
+	targets := []string{"core/yippee", "aur/yippee-bin", "yippee-git"}
+	cmdBuilder := &testGitBuilder{
+		index: 0,
+		test:  t,
+		parentBuilder: &exe.CmdBuilder{
+			Runner:   &testRunner{},
+			GitBin:   "/usr/local/bin/git",
+			GitFlags: []string{},
+		},
+	}
+	searcher := &testDBSearcher{
+		absPackagesDB: map[string]string{"yippee": "core"},
+	}
+
+	var out, stream strings.Builder
+
+	_, err := PKGBUILDRepos(context.Background(), searcher, mockClient, nil,
+		cmdBuilder, text.NewLogger(&out, &out, strings.NewReader(""), true, "test"), events.NewEmitter(&stream), nil,
+		targets, parser.ModeAny, "https://aur.archlinux.org", dir, false, false)
+
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "(1/1) Downloaded PKGBUILD from ABS")
+	assert.Contains(t, out.String(), "Skipped 2 targets: aur/yippee-bin, yippee-git")
+
+	lines := strings.Split(strings.TrimSpace(stream.String()), "\n")
+	assert.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"type":"clone_started","package":"yippee"`)
+	assert.Contains(t, lines[1], `"type":"clone_finished","package":"yippee"`)
+}
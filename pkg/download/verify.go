@@ -0,0 +1,32 @@
+package download
+
+import (
+	"os"
+	"path/filepath"
+
+	gosrc "github.com/Morganamilo/go-srcinfo"
+	"github.com/leonelquinteros/gotext"
+
+	"github.com/Jguer/yippee/v12/pkg/text"
+)
+
+// verifyPKGBUILDRepo checks that the repository cloned for pkgBase contains
+// a PKGBUILD and, when a .SRCINFO is present, that it declares pkgBase.
+// It returns a user facing reason when the repository looks wrong.
+func verifyPKGBUILDRepo(dir, pkgBase string) string {
+	if _, err := os.Stat(filepath.Join(dir, "PKGBUILD")); err != nil {
+		return gotext.Get("%s does not contain a PKGBUILD", dir)
+	}
+
+	srcinfo, err := gosrc.ParseFile(filepath.Join(dir, ".SRCINFO"))
+	if err != nil {
+		return ""
+	}
+
+	if srcinfo.Pkgbase != pkgBase {
+		return gotext.Get("requested %s but the repository contains pkgbase %s",
+			text.Cyan(pkgBase), text.Cyan(srcinfo.Pkgbase))
+	}
+
+	return ""
+}
@@ -0,0 +1,97 @@
+package events
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// Type names what an Event reports.
+type Type string
+
+const (
+	CloneStarted    Type = "clone_started"
+	CloneFinished   Type = "clone_finished"
+	BuildStarted    Type = "build_started"
+	BuildFinished   Type = "build_finished"
+	InstallStarted  Type = "install_started"
+	InstallFinished Type = "install_finished"
+	// Error reports the failure of the step named by Stage.
+	Error Type = "error"
+)
+
+// Stages of the Error events.
+const (
+	StageClone   = "clone"
+	StageBuild   = "build"
+	StageInspect = "inspect"
+	StageInstall = "install"
+)
+
+// Event is one line of the stream. Clones and builds are reported by package
+// base, installs by the packages installed together.
+type Event struct {
+	Time     time.Time `json:"time"`
+	Type     Type      `json:"type"`
+	Stage    string    `json:"stage,omitempty"`
+	Package  string    `json:"package,omitempty"`
+	Packages []string  `json:"packages,omitempty"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// Emitter writes events as newline-delimited JSON, for GUIs and other tools
+// following a long operation. It is safe for concurrent use.
+// A nil Emitter is valid and emits nothing, so callers don't have to check
+// whether the stream is enabled.
+type Emitter struct {
+	w   io.Writer
+	now func() time.Time
+	mux sync.Mutex
+	// set once a write failed, the reader is likely gone
+	broken bool
+}
+
+func NewEmitter(w io.Writer) *Emitter {
+	return &Emitter{w: w, now: time.Now}
+}
+
+// Emit reports that the step typ started or finished for pkg.
+func (e *Emitter) Emit(typ Type, pkg string) {
+	e.emit(Event{Type: typ, Package: pkg})
+}
+
+// EmitInstall reports that the step typ started or finished for the packages
+// installed together.
+func (e *Emitter) EmitInstall(typ Type, pkgs []string) {
+	e.emit(Event{Type: typ, Packages: pkgs})
+}
+
+// EmitError reports that stage failed for pkg, which may be empty, with err.
+func (e *Emitter) EmitError(stage, pkg string, err error) {
+	e.emit(Event{Type: Error, Stage: stage, Package: pkg, Error: err.Error()})
+}
+
+func (e *Emitter) emit(event Event) {
+	if e == nil {
+		return
+	}
+
+	e.mux.Lock()
+	defer e.mux.Unlock()
+
+	if e.broken {
+		return
+	}
+
+	event.Time = e.now().UTC()
+
+	line, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+
+	if _, err := e.w.Write(append(line, '\n')); err != nil {
+		e.broken = true
+	}
+}
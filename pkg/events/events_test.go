@@ -0,0 +1,64 @@
+//go:build !integration
+// +build !integration
+
+package events
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmitter(t *testing.T) {
+	t.Parallel()
+
+	var out strings.Builder
+
+	emitter := NewEmitter(&out)
+	emitter.now = func() time.Time { return time.Unix(0, 0) }
+
+	emitter.Emit(BuildStarted, "foo")
+	emitter.EmitError(StageBuild, "foo", errors.New("missing dependency"))
+	emitter.EmitInstall(InstallFinished, []string{"bar", "bar-debug"})
+
+	assert.Equal(t, `{"time":"1970-01-01T00:00:00Z","type":"build_started","package":"foo"}
+{"time":"1970-01-01T00:00:00Z","type":"error","stage":"build","package":"foo","error":"missing dependency"}
+{"time":"1970-01-01T00:00:00Z","type":"install_finished","packages":["bar","bar-debug"]}
+`, out.String())
+}
+
+type failingWriter struct {
+	writes int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return 0, errors.New("broken pipe")
+}
+
+func TestEmitterStopsOnceBroken(t *testing.T) {
+	t.Parallel()
+
+	w := &failingWriter{}
+	emitter := NewEmitter(w)
+
+	emitter.Emit(CloneStarted, "foo")
+	emitter.Emit(CloneFinished, "foo")
+
+	assert.Equal(t, 1, w.writes)
+}
+
+func TestNilEmitter(t *testing.T) {
+	t.Parallel()
+
+	var emitter *Emitter
+
+	assert.NotPanics(t, func() {
+		emitter.Emit(CloneStarted, "foo")
+		emitter.EmitInstall(InstallStarted, []string{"foo"})
+		emitter.EmitError(StageInstall, "", errors.New("failed"))
+	})
+}
@@ -4,7 +4,9 @@
 package intrange
 
 import (
+	"strings"
 	"testing"
+	"unicode"
 
 	mapset "github.com/deckarep/golang-set/v2"
 	"github.com/stretchr/testify/assert"
@@ -192,3 +194,34 @@ func TestIntRanges_Get(t *testing.T) {
 		})
 	}
 }
+
+// FuzzParseNumberMenu checks that every word of a number menu ends up in
+// exactly one of the results and that ranges are ordered.
+func FuzzParseNumberMenu(f *testing.F) {
+	for _, seed := range []string{
+		"1 2 3 4 5",
+		"1-10 ^3-5",
+		"^1,^2,abort",
+		"A B C ^D",
+		"-3 4- ^ ^^1 --",
+		"99999999999999999999 1-99999999999999999999",
+		"１ ２ 3",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		include, exclude, otherInclude, otherExclude := ParseNumberMenu(input)
+
+		for _, r := range append(include, exclude...) {
+			assert.LessOrEqual(t, r.min, r.max)
+			assert.True(t, r.Get(r.min))
+			assert.True(t, r.Get(r.max))
+		}
+
+		words := len(strings.FieldsFunc(input, func(c rune) bool {
+			return unicode.IsSpace(c) || c == ','
+		}))
+		assert.LessOrEqual(t, len(include)+len(exclude)+otherInclude.Cardinality()+otherExclude.Cardinality(), words)
+	})
+}
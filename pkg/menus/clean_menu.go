@@ -59,7 +59,8 @@ func CleanFn(ctx context.Context, run *runtime.Runtime, w io.Writer,
 
 	for i, base := range toClean {
 		dir := pkgbuildDirsByBase[base]
-		run.Logger.OperationInfoln(gotext.Get("Deleting (%d/%d): %s", i+1, len(toClean), text.Cyan(dir)))
+		run.Logger.OperationInfoln(gotext.Get("Deleting %s: %s",
+			text.Progress(i+1, len(toClean)), text.Cyan(text.Isolate(dir))))
 
 		if err := run.CmdBuilder.Show(run.CmdBuilder.BuildGitCmd(ctx, dir, "reset", "--hard", "origin/HEAD")); err != nil {
 			run.Logger.Warnln(gotext.Get("Unable to clean:"), dir)
@@ -72,6 +73,15 @@ func CleanFn(ctx context.Context, run *runtime.Runtime, w io.Writer,
 
 			return err
 		}
+
+		// clean removed the build worktrees, forget about them
+		if run.Cfg.Worktrees || run.Cfg.VersionedBuildDirs {
+			if err := run.CmdBuilder.Show(run.CmdBuilder.BuildGitCmd(ctx, dir, "worktree", "prune")); err != nil {
+				run.Logger.Warnln(gotext.Get("Unable to clean:"), dir)
+
+				return err
+			}
+		}
 	}
 
 	return nil
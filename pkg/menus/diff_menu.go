@@ -64,6 +64,8 @@ func showPkgbuildDiffs(ctx context.Context, cmdBuilder exe.ICmdBuilder, logger *
 		}
 
 		_ = cmdBuilder.Show(cmdBuilder.BuildGitCmd(ctx, dir, args...))
+
+		showSensitiveChanges(ctx, cmdBuilder, logger, pkg, dir, start)
 	}
 
 	return errMulti.Return()
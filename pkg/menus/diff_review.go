@@ -0,0 +1,158 @@
+package menus
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/leonelquinteros/gotext"
+
+	"github.com/Jguer/yippee/v12/pkg/pkgbuild"
+	"github.com/Jguer/yippee/v12/pkg/settings/exe"
+	"github.com/Jguer/yippee/v12/pkg/text"
+)
+
+// sensitiveChange is a PKGBUILD variable or install script that changed
+// between the last reviewed revision and upstream.
+type sensitiveChange struct {
+	name    string
+	added   []string
+	removed []string
+}
+
+// isSensitiveVar reports whether a PKGBUILD variable controls what gets
+// downloaded, verified or run as root on install.
+func isSensitiveVar(name string) bool {
+	switch {
+	case name == "url", name == "install":
+		return true
+	case name == "source", strings.HasPrefix(name, "source_"):
+		return true
+	case strings.HasSuffix(name, "sums"), strings.Contains(name, "sums_"):
+		return true
+	}
+
+	return false
+}
+
+// diffWords returns the words only present in newWords and only present in
+// oldWords, preserving order.
+func diffWords(oldWords, newWords []string) (added, removed []string) {
+	count := make(map[string]int, len(oldWords))
+	for _, word := range oldWords {
+		count[word]++
+	}
+
+	for _, word := range newWords {
+		if count[word] > 0 {
+			count[word]--
+			continue
+		}
+
+		added = append(added, word)
+	}
+
+	for _, word := range oldWords {
+		if count[word] > 0 {
+			count[word]--
+			removed = append(removed, word)
+		}
+	}
+
+	return added, removed
+}
+
+// sensitiveChanges compares the security sensitive variables of two
+// PKGBUILDs and the list of files changed between them.
+func sensitiveChanges(oldPKGBUILD, newPKGBUILD string, changedFiles []string) []sensitiveChange {
+	oldVars := pkgbuild.ParseVars(oldPKGBUILD)
+	newVars := pkgbuild.ParseVars(newPKGBUILD)
+
+	names := make([]string, 0)
+	seen := make(map[string]bool)
+
+	for _, vars := range []map[string][]string{newVars, oldVars} {
+		for name := range vars {
+			if isSensitiveVar(name) && !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+
+	sort.Strings(names)
+
+	changes := make([]sensitiveChange, 0)
+
+	for _, name := range names {
+		added, removed := diffWords(oldVars[name], newVars[name])
+		if len(added) == 0 && len(removed) == 0 {
+			continue
+		}
+
+		changes = append(changes, sensitiveChange{name: name, added: added, removed: removed})
+	}
+
+	installScripts := make(map[string]bool)
+	for _, vars := range []map[string][]string{newVars, oldVars} {
+		for _, script := range vars["install"] {
+			installScripts[script] = true
+		}
+	}
+
+	for _, file := range changedFiles {
+		if strings.HasSuffix(file, ".install") || installScripts[file] {
+			changes = append(changes, sensitiveChange{name: file})
+		}
+	}
+
+	return changes
+}
+
+// showSensitiveChanges prints a callout listing the changes to sources,
+// checksums, url and install scripts since the last reviewed revision.
+func showSensitiveChanges(ctx context.Context, cmdBuilder exe.ICmdBuilder, logger *text.Logger,
+	pkg, dir, start string,
+) {
+	if start == gitEmptyTree {
+		return
+	}
+
+	oldPKGBUILD, _, _ := cmdBuilder.Capture(cmdBuilder.BuildGitCmd(ctx, dir, "show", start+":PKGBUILD"))
+	newPKGBUILD, _, err := cmdBuilder.Capture(cmdBuilder.BuildGitCmd(ctx, dir, "show", "HEAD@{upstream}:PKGBUILD"))
+	if err != nil {
+		logger.Debugln("unable to read upstream PKGBUILD:", err)
+		return
+	}
+
+	stdout, _, err := cmdBuilder.Capture(cmdBuilder.BuildGitCmd(ctx, dir,
+		"diff", "--name-only", start, "HEAD@{upstream}"))
+	if err != nil {
+		logger.Debugln("unable to list changed files:", err)
+		return
+	}
+
+	changes := sensitiveChanges(oldPKGBUILD, newPKGBUILD, strings.Fields(stdout))
+	if len(changes) == 0 {
+		return
+	}
+
+	logger.Warnln(gotext.Get("%s: security sensitive changes, review carefully:", text.Cyan(pkg)))
+
+	for _, change := range changes {
+		if len(change.added) == 0 && len(change.removed) == 0 {
+			logger.Println("   ", text.Bold(text.Red(change.name)), gotext.Get("(install script changed)"))
+			continue
+		}
+
+		logger.Println("   ", text.Bold(text.Red(change.name)))
+
+		for _, word := range change.removed {
+			logger.Println("      ", text.Red("- "+word))
+		}
+
+		for _, word := range change.added {
+			logger.Println("      ", text.Green("+ "+word))
+		}
+	}
+}
@@ -0,0 +1,71 @@
+//go:build !integration
+// +build !integration
+
+package menus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const oldReviewPKGBUILD = `# Maintainer: someone
+pkgname=yippee
+pkgver=12.0.3
+url="https://github.com/Jguer/yippee"
+source=("${pkgname}-${pkgver}.tar.gz::https://github.com/Jguer/yippee/archive/v${pkgver}.tar.gz"
+        'fix.patch') # patches
+sha256sums=('aaaa'
+            'bbbb')
+
+build() {
+  url=ignored
+}
+`
+
+const newReviewPKGBUILD = `# Maintainer: someone
+pkgname=yippee
+pkgver=12.0.4
+url="https://example.com/yippee"
+install=yippee.install
+source=("${pkgname}-${pkgver}.tar.gz::https://github.com/Jguer/yippee/archive/v${pkgver}.tar.gz"
+        'fix.patch'
+        # extra payload
+        'https://example.com/payload.sh')
+sha256sums=('cccc'
+            'bbbb'
+            'SKIP')
+`
+
+func TestSensitiveChanges(t *testing.T) {
+	t.Parallel()
+
+	changes := sensitiveChanges(oldReviewPKGBUILD, newReviewPKGBUILD,
+		[]string{"PKGBUILD", "yippee.install", "README.md"})
+
+	assert.Equal(t, []sensitiveChange{
+		{name: "install", added: []string{"yippee.install"}},
+		{name: "sha256sums", added: []string{"cccc", "SKIP"}, removed: []string{"aaaa"}},
+		{name: "source", added: []string{"https://example.com/payload.sh"}},
+		{name: "url", added: []string{"https://example.com/yippee"}, removed: []string{"https://github.com/Jguer/yippee"}},
+		{name: "yippee.install"},
+	}, changes)
+
+	assert.Empty(t, sensitiveChanges(oldReviewPKGBUILD, oldReviewPKGBUILD, []string{"README.md"}))
+}
+
+func TestSensitiveChangesAppended(t *testing.T) {
+	t.Parallel()
+
+	appended := oldReviewPKGBUILD + `
+if [[ $CARCH == x86_64 ]]; then
+  source+=('https://example.com/payload.sh')
+  sha256sums+=('SKIP')
+fi
+`
+
+	assert.Equal(t, []sensitiveChange{
+		{name: "sha256sums", added: []string{"SKIP"}},
+		{name: "source", added: []string{"https://example.com/payload.sh"}},
+	}, sensitiveChanges(oldReviewPKGBUILD, appended, []string{"PKGBUILD"}))
+}
@@ -4,6 +4,7 @@ package menus
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"os"
 	"os/exec"
@@ -14,6 +15,7 @@ import (
 	mapset "github.com/deckarep/golang-set/v2"
 	"github.com/leonelquinteros/gotext"
 
+	"github.com/Jguer/yippee/v12/pkg/intrange"
 	"github.com/Jguer/yippee/v12/pkg/runtime"
 	"github.com/Jguer/yippee/v12/pkg/settings"
 	"github.com/Jguer/yippee/v12/pkg/text"
@@ -82,22 +84,124 @@ func editor(log *text.Logger, editorConfig, editorFlags string, noConfirm bool)
 	}
 }
 
+// buildFiles returns the files of a PKGBUILD directory worth reviewing: the
+// PKGBUILD, its install scripts and the files matching one of exts.
+func buildFiles(dir string, exts []string) []string {
+	files := []string{filepath.Join(dir, "PKGBUILD")}
+	seen := mapset.NewThreadUnsafeSet(files[0])
+
+	add := func(name string) {
+		path := filepath.Join(dir, name)
+		if seen.Contains(path) {
+			return
+		}
+
+		if _, err := os.Stat(path); err != nil {
+			return
+		}
+
+		seen.Add(path)
+		files = append(files, path)
+	}
+
+	if srcinfo, err := gosrc.ParseFile(filepath.Join(dir, ".SRCINFO")); err == nil {
+		for _, splitPkg := range srcinfo.SplitPackages() {
+			if splitPkg.Install != "" {
+				add(splitPkg.Install)
+			}
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return files
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		for _, ext := range exts {
+			if strings.HasSuffix(entry.Name(), ext) {
+				add(entry.Name())
+				break
+			}
+		}
+	}
+
+	return files
+}
+
+// fileSelectionMenu lets the user pick which of the build files to open.
+// An empty answer selects every file.
+func fileSelectionMenu(logger *text.Logger, buildDir string, files []string, noConfirm bool) ([]string, error) {
+	if len(files) < 2 {
+		return files, nil
+	}
+
+	toPrint := ""
+
+	for n, file := range files {
+		name := file
+		if rel, err := filepath.Rel(buildDir, file); err == nil {
+			name = rel
+		}
+
+		toPrint += fmt.Sprintf(text.Magenta("%3d")+" %s\n", len(files)-n, text.Bold(name))
+	}
+
+	logger.Print(toPrint)
+	logger.Infoln(gotext.Get("Files to edit?"))
+	logger.Infoln(gotext.Get("%s [N]one [Ab]ort or (1 2 3, 1-3, ^4)", text.Cyan(gotext.Get("[A]ll"))))
+
+	selectInput, err := logger.GetInput("", noConfirm)
+	if err != nil {
+		return nil, err
+	}
+
+	include, exclude, otherInclude, _ := intrange.ParseNumberMenu(selectInput)
+
+	switch {
+	case otherInclude.Contains("abort") || otherInclude.Contains("ab"):
+		return nil, settings.ErrUserAbort{}
+	case otherInclude.Contains("n") || otherInclude.Contains("none"):
+		return []string{}, nil
+	case strings.TrimSpace(selectInput) == "" || otherInclude.Contains("a") || otherInclude.Contains("all"):
+		return files, nil
+	}
+
+	selected := make([]string, 0, len(files))
+
+	for n, file := range files {
+		if len(exclude) != 0 {
+			if !exclude.Get(len(files) - n) {
+				selected = append(selected, file)
+			}
+
+			continue
+		}
+
+		if include.Get(len(files) - n) {
+			selected = append(selected, file)
+		}
+	}
+
+	return selected, nil
+}
+
 func editPkgbuilds(log *text.Logger, pkgbuildDirs map[string]string, bases []string, editorConfig,
-	editorFlags string, srcinfos map[string]*gosrc.Srcinfo, noConfirm bool,
+	editorFlags string, exts []string, buildDir string, noConfirm bool,
 ) error {
 	pkgbuilds := make([]string, 0, len(bases))
 
 	for _, pkg := range bases {
-		dir := pkgbuildDirs[pkg]
-		pkgbuilds = append(pkgbuilds, filepath.Join(dir, "PKGBUILD"))
-
-		if srcinfos != nil {
-			for _, splitPkg := range srcinfos[pkg].SplitPackages() {
-				if splitPkg.Install != "" {
-					pkgbuilds = append(pkgbuilds, filepath.Join(dir, splitPkg.Install))
-				}
-			}
-		}
+		pkgbuilds = append(pkgbuilds, buildFiles(pkgbuildDirs[pkg], exts)...)
+	}
+
+	pkgbuilds, err := fileSelectionMenu(log, buildDir, pkgbuilds, noConfirm)
+	if err != nil {
+		return err
 	}
 
 	if len(pkgbuilds) > 0 {
@@ -132,9 +236,8 @@ func EditFn(ctx context.Context, run *runtime.Runtime, w io.Writer,
 		return errMenu
 	}
 
-	// TOFIX: remove or use srcinfo data
-	if errEdit := editPkgbuilds(run.Logger, pkgbuildDirsByBase,
-		toEdit, run.Cfg.Editor, run.Cfg.EditorFlags, nil, settings.NoConfirm); errEdit != nil {
+	if errEdit := editPkgbuilds(run.Logger, pkgbuildDirsByBase, toEdit, run.Cfg.Editor, run.Cfg.EditorFlags,
+		strings.Fields(run.Cfg.EditFiles), run.Cfg.BuildDir, settings.NoConfirm); errEdit != nil {
 		return errEdit
 	}
 
@@ -0,0 +1,87 @@
+//go:build !integration
+// +build !integration
+
+package menus
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jguer/yippee/v12/pkg/settings"
+	"github.com/Jguer/yippee/v12/pkg/text"
+)
+
+func TestBuildFiles(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"PKGBUILD":       "pkgname=yippee\n",
+		".SRCINFO":       "pkgbase = yippee\n\tpkgver = 1\n\tpkgrel = 1\n\tarch = any\n\tinstall = hooks.sh.in\n\npkgname = yippee\n",
+		"hooks.sh.in":    "post_install() { :; }\n",
+		"yippee.install": "post_install() { :; }\n",
+		"fix.patch":      "--- a\n+++ b\n",
+		"run.sh":         "#!/bin/sh\n",
+		"README.md":      "readme\n",
+		".hidden.sh":     "#!/bin/sh\n",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+
+	assert.Equal(t, []string{
+		filepath.Join(dir, "PKGBUILD"),
+		filepath.Join(dir, "hooks.sh.in"),
+		filepath.Join(dir, "fix.patch"),
+		filepath.Join(dir, "run.sh"),
+		filepath.Join(dir, "yippee.install"),
+	}, buildFiles(dir, []string{".install", ".patch", ".sh"}))
+
+	assert.Equal(t, []string{
+		filepath.Join(dir, "PKGBUILD"),
+		filepath.Join(dir, "hooks.sh.in"),
+	}, buildFiles(dir, nil))
+}
+
+func TestFileSelectionMenu(t *testing.T) {
+	t.Parallel()
+
+	files := []string{"/build/a/PKGBUILD", "/build/a/a.install", "/build/b/PKGBUILD"}
+
+	testCases := []struct {
+		desc    string
+		input   string
+		want    []string
+		wantErr error
+	}{
+		{desc: "default selects all", input: "\n", want: files},
+		{desc: "all", input: "a\n", want: files},
+		{desc: "none", input: "n\n", want: []string{}},
+		{desc: "include", input: "1 3\n", want: []string{"/build/a/PKGBUILD", "/build/b/PKGBUILD"}},
+		{desc: "exclude", input: "^2\n", want: []string{"/build/a/PKGBUILD", "/build/b/PKGBUILD"}},
+		{desc: "abort", input: "ab\n", wantErr: settings.ErrUserAbort{}},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			logger := text.NewLogger(io.Discard, io.Discard, strings.NewReader(tc.input), false, "test")
+
+			got, err := fileSelectionMenu(logger, "/build", files, false)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -30,6 +30,12 @@ func (err *MultiError) Add(e error) {
 	err.mux.Unlock()
 }
 
+// Unwrap returns the accumulated errors so errors.Is and errors.As can
+// inspect them.
+func (err *MultiError) Unwrap() []error {
+	return err.Errors
+}
+
 // Return is used as a wrapper on return on whether to return the
 // MultiError Structure if errors exist or nil instead of delivering an empty structure.
 func (err *MultiError) Return() error {
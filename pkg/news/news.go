@@ -4,41 +4,47 @@ import (
 	"bytes"
 	"context"
 	"encoding/xml"
+	"fmt"
 	"html"
 	"io"
 	"net/http"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/Jguer/yippee/v12/pkg/multierror"
 	"github.com/Jguer/yippee/v12/pkg/text"
 )
 
+// ArchNewsURL is the feed of the Arch Linux news, the default news source.
+const ArchNewsURL = "https://archlinux.org/feeds/news"
+
 type item struct {
 	Title       string `xml:"title"`
 	Link        string `xml:"link"`
 	Description string `xml:"description"`
 	PubDate     string `xml:"pubDate"`
 	Creator     string `xml:"dc:creator"`
+
+	// set once the feed is read
+	date    time.Time
+	hasDate bool
+	feed    string
+	source  string
 }
 
-func (item *item) printNews(logger *text.Logger, buildTime time.Time, all, quiet bool) {
+func (item *item) printNews(logger *text.Logger, showSource, quiet bool) {
 	var fd string
+	if item.hasDate {
+		fd = text.FormatTime(int(item.date.Unix()))
+	}
 
-	date, err := time.Parse(time.RFC1123Z, item.PubDate)
-
-	if err != nil {
-		logger.Errorln(err)
+	if showSource {
+		logger.Println(text.Bold(text.Magenta(fd)), text.Cyan("["+item.source+"]"), text.Bold(strings.TrimSpace(item.Title)))
 	} else {
-		fd = text.FormatTime(int(date.Unix()))
-		if !all && !buildTime.IsZero() {
-			if buildTime.After(date) {
-				return
-			}
-		}
+		logger.Println(text.Bold(text.Magenta(fd)), text.Bold(strings.TrimSpace(item.Title)))
 	}
 
-	logger.Println(text.Bold(text.Magenta(fd)), text.Bold(strings.TrimSpace(item.Title)))
-
 	if !quiet {
 		desc := strings.TrimSpace(parseNews(item.Description))
 		logger.Println(desc)
@@ -54,48 +60,173 @@ type channel struct {
 	Items         []item `xml:"item"`
 }
 
-type rss struct {
-	Channel channel `xml:"channel"`
+type atomLink struct {
+	Href string `xml:"href,attr"`
 }
 
-func PrintNewsFeed(ctx context.Context, client *http.Client, logger *text.Logger,
-	cutOffDate time.Time, bottomUp, all, quiet bool,
-) error {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://archlinux.org/feeds/news", http.NoBody)
+type atomEntry struct {
+	Title     string   `xml:"title"`
+	Link      atomLink `xml:"link"`
+	Summary   string   `xml:"summary"`
+	Content   string   `xml:"content"`
+	Updated   string   `xml:"updated"`
+	Published string   `xml:"published"`
+}
+
+// document is either an RSS feed or an Atom feed, told apart by XMLName.
+type document struct {
+	XMLName xml.Name
+	Channel channel     `xml:"channel"`
+	Title   string      `xml:"title"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+// items returns the items of the feed at feedURL, RSS items and Atom entries
+// alike, with their dates parsed.
+func (doc *document) items(logger *text.Logger, feedURL string) []item {
+	if doc.XMLName.Local != "feed" {
+		items := doc.Channel.Items
+		for i := range items {
+			items[i].feed, items[i].source = feedURL, doc.Channel.Title
+
+			date, err := time.Parse(time.RFC1123Z, items[i].PubDate)
+			if err != nil {
+				date, err = time.Parse(time.RFC1123, items[i].PubDate)
+			}
+
+			if err != nil {
+				logger.Errorln(err)
+				continue
+			}
+
+			items[i].date, items[i].hasDate = date, true
+		}
+
+		return items
+	}
+
+	items := make([]item, 0, len(doc.Entries))
+
+	for i := range doc.Entries {
+		entry := &doc.Entries[i]
+
+		it := item{
+			Title:       entry.Title,
+			Link:        entry.Link.Href,
+			Description: entry.Summary,
+			PubDate:     entry.Published,
+			feed:        feedURL,
+			source:      doc.Title,
+		}
+
+		if it.Description == "" {
+			it.Description = entry.Content
+		}
+
+		if it.PubDate == "" {
+			it.PubDate = entry.Updated
+		}
+
+		if date, err := time.Parse(time.RFC3339, it.PubDate); err != nil {
+			logger.Errorln(err)
+		} else {
+			it.date, it.hasDate = date, true
+		}
+
+		items = append(items, it)
+	}
+
+	return items
+}
+
+func fetchFeed(ctx context.Context, client *http.Client, logger *text.Logger, feedURL string) ([]item, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, http.NoBody)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	rssGot := rss{}
+	doc := document{}
 
 	d := xml.NewDecoder(bytes.NewReader(body))
-	if err := d.Decode(&rssGot); err != nil {
-		return err
+	if err := d.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", feedURL, err)
 	}
 
+	return doc.items(logger, feedURL), nil
+}
+
+// PrintNewsFeed prints the news of feeds merged together, newest first unless
+// bottomUp is set, and returns how many items were printed. Unless all is set
+// only the items published since cutOffDate and not yet seen according to
+// state are printed. The newest printed item of each feed is marked in state,
+// which is left for the caller to save.
+func PrintNewsFeed(ctx context.Context, client *http.Client, logger *text.Logger,
+	feeds []string, state *ReadState, cutOffDate time.Time, bottomUp, all, quiet bool,
+) (int, error) {
+	errs := multierror.MultiError{}
+	items := make([]item, 0)
+
+	for _, feedURL := range feeds {
+		feedItems, err := fetchFeed(ctx, client, logger, feedURL)
+		if err != nil {
+			errs.Add(err)
+			continue
+		}
+
+		items = append(items, feedItems...)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].date.After(items[j].date)
+	})
+
 	if bottomUp {
-		for i := len(rssGot.Channel.Items) - 1; i >= 0; i-- {
-			rssGot.Channel.Items[i].printNews(logger, cutOffDate, all, quiet)
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
 		}
-	} else {
-		for i := 0; i < len(rssGot.Channel.Items); i++ {
-			rssGot.Channel.Items[i].printNews(logger, cutOffDate, all, quiet)
+	}
+
+	read := make(map[string]time.Time, len(feeds))
+	for _, feedURL := range feeds {
+		read[feedURL] = state.Read(feedURL)
+	}
+
+	printed := 0
+
+	for i := range items {
+		it := &items[i]
+
+		if !all && it.hasDate {
+			if cutOffDate.After(it.date) {
+				continue
+			}
+
+			if lastRead := read[it.feed]; !lastRead.IsZero() && !it.date.After(lastRead) {
+				continue
+			}
+		}
+
+		it.printNews(logger, len(feeds) > 1, quiet)
+		printed++
+
+		if it.hasDate {
+			state.Mark(it.feed, it.date)
 		}
 	}
 
-	return nil
+	return printed, errs.Return()
 }
 
 // Crude html parsing, good enough for the arch news
@@ -8,12 +8,14 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/bradleyjkemp/cupaloy"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"gopkg.in/h2non/gock.v1"
 
 	"github.com/Jguer/yippee/v12/pkg/text"
@@ -141,7 +143,7 @@ func TestPrintNewsFeed(t *testing.T) {
 			r, w, _ := os.Pipe()
 			logger := text.NewLogger(w, w, strings.NewReader(""), false, "logger")
 
-			err := PrintNewsFeed(context.Background(), &http.Client{}, logger,
+			_, err := PrintNewsFeed(context.Background(), &http.Client{}, logger, []string{ArchNewsURL}, nil,
 				tt.args.cutOffDate, tt.args.bottomUp, tt.args.all, tt.args.quiet)
 			assert.NoError(t, err)
 
@@ -169,7 +171,7 @@ func TestPrintNewsFeedSameDay(t *testing.T) {
 	r, w, _ := os.Pipe()
 	logger := text.NewLogger(w, w, strings.NewReader(""), false, "logger")
 
-	err := PrintNewsFeed(context.Background(), &http.Client{}, logger,
+	_, err := PrintNewsFeed(context.Background(), &http.Client{}, logger, []string{ArchNewsURL}, nil,
 		lastNewsTime, true, false, false)
 	assert.NoError(t, err)
 
@@ -177,3 +179,61 @@ func TestPrintNewsFeedSameDay(t *testing.T) {
 	out, _ := io.ReadAll(r)
 	cupaloy.SnapshotT(t, out)
 }
+
+const atomNews = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>Derivative news</title>
+  <entry>
+    <title>Mirror change</title>
+    <link href="https://example.org/news/mirror"/>
+    <summary>&lt;p&gt;Switch mirrors.&lt;/p&gt;</summary>
+    <updated>2020-04-13T20:00:00Z</updated>
+  </entry>
+</feed>
+`
+
+// GIVEN an RSS and an Atom feed
+// WHEN printing their news twice
+// THEN the news should be merged by date and only printed the first time
+func TestPrintNewsFeedMultipleSources(t *testing.T) {
+	t.Setenv("TZ", "UTC")
+
+	cutOff, _ := time.Parse(time.RFC3339, "2020-04-13T00:00:00Z")
+	state := NewReadState(filepath.Join(t.TempDir(), "news.json"))
+	feeds := []string{ArchNewsURL, "https://example.org/news.atom"}
+
+	printFeeds := func() string {
+		gock.New("https://archlinux.org").Get("/feeds/news").Reply(200).BodyString(sampleNews)
+		gock.New("https://example.org").Get("/news.atom").Reply(200).BodyString(atomNews)
+
+		defer gock.Off()
+
+		var out strings.Builder
+		logger := text.NewLogger(&out, &out, strings.NewReader(""), false, "logger")
+
+		_, err := PrintNewsFeed(context.Background(), &http.Client{}, logger, feeds, state,
+			cutOff, false, false, true)
+		require.NoError(t, err)
+		require.NoError(t, state.Save())
+
+		return out.String()
+	}
+
+	out := printFeeds()
+
+	zn := strings.Index(out, "zn_poly")
+	mirror := strings.Index(out, "[Derivative news]")
+	nss := strings.Index(out, "nss>=3.51.1-1")
+
+	require.True(t, zn >= 0 && mirror >= 0 && nss >= 0, out)
+	assert.Less(t, zn, mirror)
+	assert.Less(t, mirror, nss)
+	assert.Contains(t, out, "Mirror change")
+	assert.NotContains(t, out, "hplip")
+
+	assert.Empty(t, printFeeds())
+
+	reloaded := NewReadState(state.FilePath)
+	require.NoError(t, reloaded.Load())
+	assert.Equal(t, "2020-04-13T20:00:00Z", reloaded.Read("https://example.org/news.atom").Format(time.RFC3339))
+}
@@ -0,0 +1,103 @@
+package news
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// ReadState remembers, for each feed, the publication date of the newest
+// item already printed so it is not printed again. A nil *ReadState
+// remembers nothing.
+type ReadState struct {
+	FilePath string
+
+	read    map[string]time.Time
+	changed bool
+	mux     sync.Mutex
+}
+
+func NewReadState(filePath string) *ReadState {
+	return &ReadState{
+		FilePath: filePath,
+		read:     make(map[string]time.Time),
+	}
+}
+
+// Load reads the state from disk. A missing file is not an error.
+func (s *ReadState) Load() error {
+	content, err := os.ReadFile(s.FilePath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to open news state file '%s': %w", s.FilePath, err)
+	}
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if err := json.Unmarshal(content, &s.read); err != nil {
+		return fmt.Errorf("failed to read news state file '%s': %w", s.FilePath, err)
+	}
+
+	return nil
+}
+
+// Read returns the date of the newest item of feed already printed, the zero
+// time when there is none.
+func (s *ReadState) Read(feed string) time.Time {
+	if s == nil {
+		return time.Time{}
+	}
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	return s.read[feed]
+}
+
+// Mark records that the items of feed up to date were printed.
+func (s *ReadState) Mark(feed string, date time.Time) {
+	if s == nil {
+		return
+	}
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if date.After(s.read[feed]) {
+		s.read[feed] = date
+		s.changed = true
+	}
+}
+
+// Save writes the state to disk if it changed since it was loaded.
+func (s *ReadState) Save() error {
+	if s == nil {
+		return nil
+	}
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if !s.changed {
+		return nil
+	}
+
+	marshalled, err := json.MarshalIndent(s.read, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(s.FilePath, marshalled, 0o644); err != nil {
+		return err
+	}
+
+	s.changed = false
+
+	return nil
+}
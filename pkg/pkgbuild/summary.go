@@ -0,0 +1,93 @@
+package pkgbuild
+
+import (
+	"os"
+	"sort"
+	"strings"
+)
+
+// Summary is the overview of a PKGBUILD printed instead of the full file.
+type Summary struct {
+	Version     string
+	Depends     []string
+	MakeDepends []string
+	Sources     []string
+	// Checksums are the checksum algorithms used, e.g. sha256 or b2.
+	Checksums []string
+}
+
+// Summarize parses pkgbuild and summarizes its version, dependencies,
+// sources and checksum algorithms. Architecture specific arrays are merged
+// into their generic counterpart and references to scalar variables are
+// expanded.
+func Summarize(pkgbuild string) Summary {
+	vars := ParseVars(pkgbuild)
+
+	version := first(vars, "pkgver")
+	if epoch := first(vars, "epoch"); epoch != "" {
+		version = epoch + ":" + version
+	}
+
+	if pkgrel := first(vars, "pkgrel"); pkgrel != "" {
+		version += "-" + pkgrel
+	}
+
+	summary := Summary{Version: expand(version, vars)}
+
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	algorithms := make(map[string]bool)
+
+	for _, name := range names {
+		base, _, _ := strings.Cut(name, "_")
+
+		switch {
+		case base == "depends":
+			summary.Depends = appendExpanded(summary.Depends, vars[name], vars)
+		case base == "makedepends":
+			summary.MakeDepends = appendExpanded(summary.MakeDepends, vars[name], vars)
+		case base == "source":
+			summary.Sources = appendExpanded(summary.Sources, vars[name], vars)
+		case strings.HasSuffix(base, "sums") && base != "sums":
+			if algorithm := strings.TrimSuffix(base, "sums"); !algorithms[algorithm] {
+				algorithms[algorithm] = true
+				summary.Checksums = append(summary.Checksums, algorithm)
+			}
+		}
+	}
+
+	return summary
+}
+
+func first(vars map[string][]string, name string) string {
+	if values := vars[name]; len(values) != 0 {
+		return values[0]
+	}
+
+	return ""
+}
+
+func appendExpanded(dst, words []string, vars map[string][]string) []string {
+	for _, word := range words {
+		dst = append(dst, expand(word, vars))
+	}
+
+	return dst
+}
+
+// expand replaces references to scalar variables. Anything else, such as
+// arrays or parameter expansions, is kept as written.
+func expand(word string, vars map[string][]string) string {
+	return os.Expand(word, func(name string) string {
+		if values, ok := vars[name]; ok && len(values) == 1 {
+			return values[0]
+		}
+
+		return "${" + name + "}"
+	})
+}
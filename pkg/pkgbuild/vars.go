@@ -0,0 +1,205 @@
+// Package pkgbuild reads PKGBUILD files without executing them.
+package pkgbuild
+
+import (
+	"strings"
+)
+
+// ParseVars extracts the assignments of a PKGBUILD outside of functions
+// without running bash, including the ones nested in if and case blocks.
+// Array values are split into their elements, quotes are stripped. Appends
+// with += add to the previous value, assignments in every branch are kept.
+func ParseVars(pkgbuild string) map[string][]string {
+	vars := make(map[string][]string)
+	lines := strings.Split(pkgbuild, "\n")
+
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimLeft(lines[i], " \t")
+		if line == "" || line[0] == '#' {
+			continue
+		}
+
+		if isFunctionStart(line) {
+			i = functionEnd(lines, i)
+			continue
+		}
+
+		name, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+
+		name, appending := strings.CutSuffix(name, "+")
+		if !isIdentifier(name) {
+			continue
+		}
+
+		if !strings.HasPrefix(value, "(") {
+			words := splitWords(value)
+			if appending && len(vars[name]) > 0 {
+				// like bash, appending a string to an array extends its first element
+				vars[name][0] += strings.Join(words, " ")
+				continue
+			}
+
+			vars[name] = words
+
+			continue
+		}
+
+		value = value[1:]
+		end := arrayEnd(value)
+
+		for end < 0 && i+1 < len(lines) {
+			i++
+			value += "\n" + lines[i]
+			end = arrayEnd(value)
+		}
+
+		if end >= 0 {
+			value = value[:end]
+		}
+
+		if appending {
+			vars[name] = append(vars[name], splitWords(value)...)
+			continue
+		}
+
+		vars[name] = splitWords(value)
+	}
+
+	return vars
+}
+
+// isFunctionStart reports whether line, without indentation, starts a
+// function definition like "build() {" or "function build {".
+func isFunctionStart(line string) bool {
+	if rest, ok := strings.CutPrefix(line, "function "); ok {
+		name, _, _ := strings.Cut(strings.TrimSpace(rest), " ")
+		return name != ""
+	}
+
+	name, rest, ok := strings.Cut(line, "(")
+	if !ok || !isFunctionName(strings.TrimSpace(name)) {
+		return false
+	}
+
+	return strings.HasPrefix(strings.TrimSpace(rest), ")")
+}
+
+// functionEnd returns the index of the line closing the function started at
+// lines[start], the last line when it is not closed. A function written on a
+// single line ends where it starts.
+func functionEnd(lines []string, start int) int {
+	header := strings.TrimRight(lines[start], " \t")
+	if strings.HasSuffix(header, "}") {
+		return start
+	}
+
+	indent := len(lines[start]) - len(strings.TrimLeft(lines[start], " \t"))
+
+	for i := start + 1; i < len(lines); i++ {
+		trimmed := strings.TrimLeft(lines[i], " \t")
+		if strings.HasPrefix(trimmed, "}") && len(lines[i])-len(trimmed) <= indent {
+			return i
+		}
+	}
+
+	return len(lines) - 1
+}
+
+// isFunctionName reports whether name can be a bash function name as found
+// in PKGBUILDs, e.g. package_foo-bar.
+func isFunctionName(name string) bool {
+	return isIdentifier(strings.ReplaceAll(strings.ReplaceAll(name, "-", "_"), ".", "_"))
+}
+
+func isIdentifier(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for i, r := range name {
+		switch {
+		case r == '_', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case i > 0 && r >= '0' && r <= '9':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// arrayEnd returns the index of the first closing parenthesis outside of
+// quotes and comments, or -1 if the array is not closed yet.
+func arrayEnd(value string) int {
+	var quote rune
+
+	comment := false
+
+	for i, r := range value {
+		switch {
+		case comment:
+			comment = r != '\n'
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '#':
+			comment = true
+		case r == ')':
+			return i
+		}
+	}
+
+	return -1
+}
+
+// splitWords splits a shell value into words honoring quotes and dropping
+// comments.
+func splitWords(value string) []string {
+	var (
+		words   []string
+		current strings.Builder
+		quote   rune
+		inWord  bool
+		comment bool
+	)
+
+	for _, r := range value {
+		switch {
+		case comment:
+			comment = r != '\n'
+		case quote != 0:
+			if r == quote {
+				quote = 0
+				continue
+			}
+
+			current.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == '#' && !inWord:
+			comment = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, current.String())
+				current.Reset()
+				inWord = false
+			}
+		default:
+			current.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if inWord {
+		words = append(words, current.String())
+	}
+
+	return words
+}
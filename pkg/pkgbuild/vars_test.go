@@ -0,0 +1,103 @@
+//go:build !integration
+// +build !integration
+
+package pkgbuild
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testPKGBUILD = `# Maintainer: someone
+pkgname=yippee
+pkgver=12.0.3
+pkgrel=2
+epoch=1
+url="https://github.com/Jguer/yippee"
+depends=('pacman>6.1' 'git')
+makedepends=('go')
+source=("${pkgname}-${pkgver}.tar.gz::https://github.com/Jguer/yippee/archive/v${pkgver}.tar.gz"
+        'fix.patch') # patches
+source_x86_64=("${pkgname%-git}.conf")
+sha256sums=('aaaa'
+            'bbbb')
+sha256sums_x86_64=('cccc')
+b2sums=('dddd' 'eeee')
+
+build() {
+  url=ignored
+}
+`
+
+func TestParseVars(t *testing.T) {
+	t.Parallel()
+
+	vars := ParseVars(testPKGBUILD)
+
+	assert.Equal(t, []string{"https://github.com/Jguer/yippee"}, vars["url"])
+	assert.Equal(t, []string{
+		"${pkgname}-${pkgver}.tar.gz::https://github.com/Jguer/yippee/archive/v${pkgver}.tar.gz",
+		"fix.patch",
+	}, vars["source"])
+	assert.Equal(t, []string{"aaaa", "bbbb"}, vars["sha256sums"])
+}
+
+func TestSummarize(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, Summary{
+		Version:     "1:12.0.3-2",
+		Depends:     []string{"pacman>6.1", "git"},
+		MakeDepends: []string{"go"},
+		Sources: []string{
+			"yippee-12.0.3.tar.gz::https://github.com/Jguer/yippee/archive/v12.0.3.tar.gz",
+			"fix.patch",
+			"${pkgname%-git}.conf",
+		},
+		Checksums: []string{"b2", "sha256"},
+	}, Summarize(testPKGBUILD))
+}
+
+func TestParseVarsNested(t *testing.T) {
+	t.Parallel()
+
+	vars := ParseVars(`pkgname=foo
+pkgdesc="A foo"
+pkgdesc+=" tool"
+source=('foo.tar.gz')
+source+=('extra.patch'
+         'more.patch')
+sha256sums=('aaaa' 'bbbb' 'cccc')
+
+if [[ $CARCH == x86_64 ]]; then
+  source+=("https://example.com/payload.sh")
+  sha256sums+=('SKIP')
+fi
+
+case "$CARCH" in
+  aarch64)
+    depends=('glibc')
+    ;;
+esac
+
+prepare()
+{
+  source+=('ignored')
+}
+
+package_foo-bin() {
+    if true; then
+        url=ignored
+    fi
+}
+`)
+
+	assert.Equal(t, []string{"A foo tool"}, vars["pkgdesc"])
+	assert.Equal(t, []string{
+		"foo.tar.gz", "extra.patch", "more.patch", "https://example.com/payload.sh",
+	}, vars["source"])
+	assert.Equal(t, []string{"aaaa", "bbbb", "cccc", "SKIP"}, vars["sha256sums"])
+	assert.Equal(t, []string{"glibc"}, vars["depends"])
+	assert.NotContains(t, vars, "url")
+}
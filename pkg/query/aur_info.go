@@ -0,0 +1,86 @@
+package query
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Jguer/aur"
+
+	"github.com/Jguer/yippee/v12/pkg/multierror"
+)
+
+// maxConcurrentInfo is the number of info requests sent to the AUR at once.
+const maxConcurrentInfo = 4
+
+// AURInfo gets the AUR packages named in names, splitting the lookup in
+// requests of at most splitN packages sent concurrently. Each name is asked
+// for once and the packages found are returned in the order of names. The
+// packages of the requests that succeeded are returned along with the errors
+// of the others.
+func AURInfo(ctx context.Context, aurClient aur.QueryClient, names []string, splitN int) ([]aur.Pkg, error) {
+	unique := make([]string, 0, len(names))
+	seen := make(map[string]bool, len(names))
+
+	for _, name := range names {
+		if !seen[name] {
+			seen[name] = true
+			unique = append(unique, name)
+		}
+	}
+
+	if splitN <= 0 {
+		splitN = len(unique)
+	}
+
+	var (
+		mux    sync.Mutex
+		errs   multierror.MultiError
+		wg     sync.WaitGroup
+		byName = make(map[string]aur.Pkg, len(unique))
+	)
+
+	sem := make(chan uint8, maxConcurrentInfo)
+
+	for start := 0; start < len(unique); start += splitN {
+		end := start + splitN
+		if end > len(unique) {
+			end = len(unique)
+		}
+
+		sem <- 1
+
+		wg.Add(1)
+
+		go func(needles []string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			pkgs, err := aurClient.Get(ctx, &aur.Query{
+				Needles: needles,
+				By:      aur.Name,
+			})
+			if err != nil {
+				errs.Add(err)
+				return
+			}
+
+			mux.Lock()
+			for i := range pkgs {
+				byName[pkgs[i].Name] = pkgs[i]
+			}
+			mux.Unlock()
+		}(unique[start:end])
+	}
+
+	wg.Wait()
+
+	pkgs := make([]aur.Pkg, 0, len(byName))
+
+	for _, name := range unique {
+		if pkg, ok := byName[name]; ok {
+			pkgs = append(pkgs, pkg)
+		}
+	}
+
+	return pkgs, errs.Return()
+}
@@ -0,0 +1,112 @@
+//go:build !integration
+// +build !integration
+
+package query
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/Jguer/aur"
+	"github.com/stretchr/testify/assert"
+
+	mockaur "github.com/Jguer/yippee/v12/pkg/dep/mock"
+)
+
+func TestAURInfo(t *testing.T) {
+	t.Parallel()
+
+	errRPC := errors.New("rpc failed")
+
+	testCases := []struct {
+		desc         string
+		names        []string
+		splitN       int
+		wantNames    []string
+		wantRequests [][]string
+		wantErr      error
+	}{
+		{
+			desc:         "single request",
+			names:        []string{"c", "a", "b"},
+			wantNames:    []string{"c", "a", "b"},
+			wantRequests: [][]string{{"c", "a", "b"}},
+		},
+		{
+			desc:         "split and deduplicated",
+			names:        []string{"c", "a", "c", "b", "a", "d", "e"},
+			splitN:       2,
+			wantNames:    []string{"c", "a", "b", "d", "e"},
+			wantRequests: [][]string{{"c", "a"}, {"b", "d"}, {"e"}},
+		},
+		{
+			desc:         "missing packages are left out",
+			names:        []string{"a", "missing", "b"},
+			splitN:       1,
+			wantNames:    []string{"a", "b"},
+			wantRequests: [][]string{{"a"}, {"missing"}, {"b"}},
+		},
+		{
+			desc:         "failed request keeps the others",
+			names:        []string{"a", "fail", "b"},
+			splitN:       2,
+			wantNames:    []string{"b"},
+			wantRequests: [][]string{{"a", "fail"}, {"b"}},
+			wantErr:      errRPC,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				mux      sync.Mutex
+				requests [][]string
+			)
+
+			aurClient := &mockaur.MockAUR{GetFn: func(ctx context.Context, query *aur.Query) ([]aur.Pkg, error) {
+				mux.Lock()
+				requests = append(requests, query.Needles)
+				mux.Unlock()
+
+				pkgs := make([]aur.Pkg, 0, len(query.Needles))
+
+				for _, needle := range query.Needles {
+					switch needle {
+					case "fail":
+						return nil, errRPC
+					case "missing":
+						continue
+					}
+
+					pkgs = append(pkgs, aur.Pkg{Name: needle})
+				}
+
+				return pkgs, nil
+			}}
+
+			pkgs, err := AURInfo(context.Background(), aurClient, tc.names, tc.splitN)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			names := make([]string, 0, len(pkgs))
+			for i := range pkgs {
+				names = append(names, pkgs[i].Name)
+			}
+
+			assert.Equal(t, tc.wantNames, names)
+
+			sort.Slice(requests, func(i, j int) bool { return requests[i][0] < requests[j][0] })
+			sort.Slice(tc.wantRequests, func(i, j int) bool { return tc.wantRequests[i][0] < tc.wantRequests[j][0] })
+			assert.Equal(t, tc.wantRequests, requests)
+		})
+	}
+}
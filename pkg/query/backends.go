@@ -0,0 +1,146 @@
+package query
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Jguer/aur"
+	mapset "github.com/deckarep/golang-set/v2"
+	"github.com/leonelquinteros/gotext"
+
+	"github.com/Jguer/yippee/v12/pkg/multierror"
+	"github.com/Jguer/yippee/v12/pkg/text"
+)
+
+// Backend is an AUR compatible endpoint, the AUR itself or e.g. a private
+// aurweb instance.
+type Backend struct {
+	Name string
+	// URL is the web interface of the backend, PKGBUILDs are cloned from it.
+	URL    string
+	Client aur.QueryClient
+}
+
+// Backends queries AUR compatible backends in order and remembers which one
+// every package came from. A package looked up by name comes from the first
+// backend having it, searches list the matches of every backend with the ones
+// of earlier backends shadowing the others.
+type Backends struct {
+	backends []Backend
+	logger   *text.Logger
+
+	mux     sync.RWMutex
+	origins map[string]*Backend
+	warned  mapset.Set[string]
+}
+
+// NewBackends returns a client querying backends in the order given.
+func NewBackends(logger *text.Logger, backends ...Backend) *Backends {
+	return &Backends{
+		backends: backends,
+		logger:   logger,
+		origins:  make(map[string]*Backend),
+		warned:   mapset.NewSet[string](),
+	}
+}
+
+// Get queries every backend in turn. A backend failing is only a warning as
+// long as another one answers, the error is returned when none does.
+func (b *Backends) Get(ctx context.Context, query *aur.Query) ([]aur.Pkg, error) {
+	var errs multierror.MultiError
+
+	pkgs := make([]aur.Pkg, 0)
+	seen := mapset.NewThreadUnsafeSet[string]()
+	needles := query.Needles
+	failed := make([]*Backend, 0)
+
+	for i := range b.backends {
+		backend := &b.backends[i]
+
+		// names found on a backend are not looked up on the next ones
+		if query.By == aur.Name && len(needles) == 0 {
+			break
+		}
+
+		backendQuery := *query
+		backendQuery.Needles = needles
+
+		found, err := backend.Client.Get(ctx, &backendQuery)
+		if err != nil {
+			errs.Add(err)
+			failed = append(failed, backend)
+
+			continue
+		}
+
+		for j := range found {
+			if seen.Contains(found[j].Name) {
+				continue
+			}
+
+			seen.Add(found[j].Name)
+			pkgs = append(pkgs, found[j])
+			b.setOrigin(&found[j], backend)
+		}
+
+		if query.By == aur.Name {
+			remaining := make([]string, 0, len(needles))
+
+			for _, needle := range needles {
+				if !seen.Contains(needle) {
+					remaining = append(remaining, needle)
+				}
+			}
+
+			needles = remaining
+		}
+	}
+
+	if len(failed) == len(b.backends) {
+		return pkgs, errs.Return()
+	}
+
+	for i, backend := range failed {
+		if b.warned.Add(backend.Name) {
+			b.logger.Warnln(gotext.Get("unable to query %s: %s", backend.Name, errs.Errors[i]))
+		}
+	}
+
+	return pkgs, nil
+}
+
+func (b *Backends) setOrigin(pkg *aur.Pkg, backend *Backend) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	b.origins[pkg.Name] = backend
+
+	// PKGBUILDs are cloned by base, which has to find its backend as well
+	if _, ok := b.origins[pkg.PackageBase]; !ok && pkg.PackageBase != "" {
+		b.origins[pkg.PackageBase] = backend
+	}
+}
+
+// Origin returns the backend the package or package base name was last
+// returned from.
+func (b *Backends) Origin(name string) (*Backend, bool) {
+	b.mux.RLock()
+	defer b.mux.RUnlock()
+
+	backend, ok := b.origins[name]
+
+	return backend, ok
+}
+
+// Origin returns the name and the URL of the backend the package or package
+// base name came from. Unless aurClient queries several backends that is the
+// AUR at aurURL.
+func Origin(aurClient aur.QueryClient, name, aurURL string) (origin, url string) {
+	if backends, ok := aurClient.(*Backends); ok {
+		if backend, found := backends.Origin(name); found {
+			return backend.Name, backend.URL
+		}
+	}
+
+	return sourceAUR, aurURL
+}
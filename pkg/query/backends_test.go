@@ -0,0 +1,127 @@
+//go:build !integration
+// +build !integration
+
+package query
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/Jguer/aur"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mockaur "github.com/Jguer/yippee/v12/pkg/dep/mock"
+	"github.com/Jguer/yippee/v12/pkg/text"
+)
+
+// backendClient answers the name lookups and searches of a backend from pkgs.
+func backendClient(pkgs ...aur.Pkg) *mockaur.MockAUR {
+	return &mockaur.MockAUR{GetFn: func(ctx context.Context, query *aur.Query) ([]aur.Pkg, error) {
+		found := make([]aur.Pkg, 0)
+
+		for _, pkg := range pkgs {
+			for _, needle := range query.Needles {
+				if pkg.Name == needle || (query.By != aur.Name && strings.Contains(pkg.Name, needle)) {
+					found = append(found, pkg)
+				}
+			}
+		}
+
+		return found, nil
+	}}
+}
+
+func TestBackends(t *testing.T) {
+	t.Parallel()
+
+	private := backendClient(
+		aur.Pkg{Name: "yippee", Version: "99-1"},
+		aur.Pkg{Name: "internal-tool", PackageBase: "internal", Version: "1-1"})
+	official := backendClient(
+		aur.Pkg{Name: "yippee", Version: "12-1"},
+		aur.Pkg{Name: "yippee-bin", Version: "12-1"})
+
+	testCases := []struct {
+		desc        string
+		query       *aur.Query
+		wantVersion map[string]string
+		wantOrigin  map[string]string
+	}{
+		{
+			desc:        "name lookup shadows later backends",
+			query:       &aur.Query{Needles: []string{"yippee", "yippee-bin", "internal-tool"}, By: aur.Name},
+			wantVersion: map[string]string{"yippee": "99-1", "yippee-bin": "12-1", "internal-tool": "1-1"},
+			wantOrigin: map[string]string{
+				"yippee": "private", "yippee-bin": "aur", "internal-tool": "private", "internal": "private",
+			},
+		},
+		{
+			desc:        "search lists every backend",
+			query:       &aur.Query{Needles: []string{"yippee"}, By: aur.NameDesc, Contains: true},
+			wantVersion: map[string]string{"yippee": "99-1", "yippee-bin": "12-1"},
+			wantOrigin:  map[string]string{"yippee": "private", "yippee-bin": "aur"},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			backends := NewBackends(text.NewLogger(io.Discard, io.Discard, strings.NewReader(""), false, "test"),
+				Backend{Name: "private", URL: "https://aur.example.com", Client: private},
+				Backend{Name: "aur", URL: "https://aur.archlinux.org", Client: official})
+
+			pkgs, err := backends.Get(context.Background(), tc.query)
+			require.NoError(t, err)
+
+			versions := make(map[string]string, len(pkgs))
+			for _, pkg := range pkgs {
+				versions[pkg.Name] = pkg.Version
+			}
+
+			assert.Equal(t, tc.wantVersion, versions)
+
+			for name, want := range tc.wantOrigin {
+				origin, _ := Origin(backends, name, "")
+				assert.Equal(t, want, origin, name)
+			}
+		})
+	}
+}
+
+func TestBackendsUnavailable(t *testing.T) {
+	t.Parallel()
+
+	down := &mockaur.MockAUR{GetFn: func(ctx context.Context, query *aur.Query) ([]aur.Pkg, error) {
+		return nil, aur.ErrServiceUnavailable
+	}}
+
+	var logged strings.Builder
+
+	backends := NewBackends(text.NewLogger(&logged, io.Discard, strings.NewReader(""), false, "test"),
+		Backend{Name: "private", URL: "https://aur.example.com", Client: down},
+		Backend{Name: "aur", URL: "https://aur.archlinux.org", Client: backendClient(aur.Pkg{Name: "yippee"})})
+
+	for i := 0; i < 2; i++ {
+		pkgs, err := backends.Get(context.Background(), &aur.Query{Needles: []string{"yippee"}, By: aur.Name})
+		require.NoError(t, err)
+		assert.Len(t, pkgs, 1)
+	}
+
+	assert.Equal(t, 1, strings.Count(logged.String(), "unable to query private"))
+
+	origin, aurURL := Origin(backendClient(), "yippee", "https://aur.archlinux.org")
+	assert.Equal(t, "aur", origin)
+	assert.Equal(t, "https://aur.archlinux.org", aurURL)
+
+	allDown := NewBackends(text.NewLogger(io.Discard, io.Discard, strings.NewReader(""), false, "test"),
+		Backend{Name: "private", Client: down}, Backend{Name: "aur", Client: down})
+
+	_, err := allDown.Get(context.Background(), &aur.Query{Needles: []string{"yippee"}, By: aur.Name})
+	assert.True(t, errors.Is(err, aur.ErrServiceUnavailable))
+}
@@ -0,0 +1,41 @@
+package query
+
+import (
+	"strconv"
+
+	"github.com/Jguer/aur"
+
+	"github.com/Jguer/yippee/v12/pkg/db"
+	"github.com/Jguer/yippee/v12/pkg/text"
+)
+
+// AURPrintFields are the --print-format fields of an AUR package: %n the
+// name, %v the version, %r the backend it came from, %e the base, %d the
+// description, %u the upstream URL, %m the maintainer, %w the votes and %p
+// the popularity.
+func AURPrintFields(pkg *aur.Pkg, origin string) text.PrintFields {
+	return text.PrintFields{
+		'n': pkg.Name,
+		'v': pkg.Version,
+		'r': origin,
+		'e': pkg.PackageBase,
+		'd': pkg.Description,
+		'u': pkg.URL,
+		'm': pkg.Maintainer,
+		'w': strconv.Itoa(pkg.NumVotes),
+		'p': strconv.FormatFloat(pkg.Popularity, 'f', 2, 64),
+	}
+}
+
+// SyncPrintFields are the --print-format fields of a repository package,
+// the ones of AURPrintFields a repository package has.
+func SyncPrintFields(pkg db.IPackage) text.PrintFields {
+	return text.PrintFields{
+		'n': pkg.Name(),
+		'v': pkg.Version(),
+		'r': pkg.DB().Name(),
+		'e': pkg.Base(),
+		'd': pkg.Description(),
+		'u': pkg.URL(),
+	}
+}
@@ -35,6 +35,9 @@ type Builder interface {
 	Len() int
 	Execute(ctx context.Context, dbExecutor db.Executor, pkgS []string)
 	Results(dbExecutor db.Executor, verboseSearch SearchVerbosity) error
+	FormatResults(format string)
+	SearchResults() []Result
+	AURError() error
 	GetTargets(include, exclude intrange.IntRanges, otherExclude mapset.Set[string]) ([]string, error)
 }
 
@@ -47,6 +50,8 @@ type SourceQueryBuilder struct {
 	bottomUp          bool
 	singleLineResults bool
 	separateSources   bool
+	// aurErr is the error of the AUR query of the last Execute
+	aurErr error
 
 	aurClient aur.QueryClient
 	logger    *text.Logger
@@ -76,6 +81,18 @@ func NewSourceQueryBuilder(
 	}
 }
 
+// Result is a search result in the order Results prints them.
+type Result struct {
+	Source      string   `json:"source"`
+	Name        string   `json:"name"`
+	Version     string   `json:"version"`
+	Description string   `json:"description"`
+	Votes       int      `json:"votes,omitempty"`
+	Provides    []string `json:"provides,omitempty"`
+	// Origin is the backend an AUR result came from.
+	Origin string `json:"origin,omitempty"`
+}
+
 type abstractResult struct {
 	source      string
 	name        string
@@ -147,6 +164,8 @@ func (s *SourceQueryBuilder) Execute(ctx context.Context, dbExecutor db.Executor
 		separateSourceCache: map[string]float64{},
 	}
 
+	by := getSearchBy(s.searchBy)
+
 	if s.targetMode.AtLeastAUR() {
 		var aurResults []aur.Pkg
 		aurResults, aurErr = queryAUR(ctx, s.aurClient, pkgS, s.searchBy)
@@ -157,7 +176,6 @@ func (s *SourceQueryBuilder) Execute(ctx context.Context, dbExecutor db.Executor
 				s.queryMap[dbName] = map[string]interface{}{}
 			}
 
-			by := getSearchBy(s.searchBy)
 			if (by == aur.NameDesc || by == aur.None || by == aur.Name) &&
 				!matchesSearch(&aurResults[i], pkgS) {
 				continue
@@ -175,8 +193,10 @@ func (s *SourceQueryBuilder) Execute(ctx context.Context, dbExecutor db.Executor
 		}
 	}
 
+	// only AUR packages have accounts, searching the repositories by
+	// package name for them would list unrelated packages
 	var repoResults []alpm.IPackage
-	if s.targetMode.AtLeastRepo() {
+	if s.targetMode.AtLeastRepo() && !isAccountField(by) {
 		repoResults = dbExecutor.SyncPackages(pkgS...)
 
 		for i := range repoResults {
@@ -206,6 +226,7 @@ func (s *SourceQueryBuilder) Execute(ctx context.Context, dbExecutor db.Executor
 
 	sort.Sort(sortableResults)
 	s.results = sortableResults.results
+	s.aurErr = aurErr
 
 	if aurErr != nil {
 		s.logger.Errorln(ErrAURSearch{inner: aurErr})
@@ -237,7 +258,8 @@ func (s *SourceQueryBuilder) Results(dbExecutor db.Executor, verboseSearch Searc
 
 		switch pPkg := pkg.(type) {
 		case aur.Pkg:
-			toPrint += aurPkgSearchString(&pPkg, dbExecutor, s.singleLineResults)
+			origin, _ := Origin(s.aurClient, pPkg.Name, "")
+			toPrint += aurPkgSearchString(&pPkg, origin, dbExecutor, s.singleLineResults)
 		case alpm.IPackage:
 			toPrint += syncPkgSearchString(pPkg, dbExecutor, s.singleLineResults)
 		}
@@ -248,6 +270,53 @@ func (s *SourceQueryBuilder) Results(dbExecutor db.Executor, verboseSearch Searc
 	return nil
 }
 
+// FormatResults prints a line per result expanding the --print-format
+// placeholders of format, see AURPrintFields and SyncPrintFields.
+func (s *SourceQueryBuilder) FormatResults(format string) {
+	for i := range s.results {
+		switch pPkg := s.queryMap[s.results[i].source][s.results[i].name].(type) {
+		case aur.Pkg:
+			origin, _ := Origin(s.aurClient, pPkg.Name, "")
+			s.logger.Println(text.PrintFormat(format, AURPrintFields(&pPkg, origin)))
+		case alpm.IPackage:
+			s.logger.Println(text.PrintFormat(format, SyncPrintFields(pPkg)))
+		}
+	}
+}
+
+// AURError returns why the AUR could not be searched by the last Execute, nil
+// when it was searched or not needed. The results then only hold repository
+// packages.
+func (s *SourceQueryBuilder) AURError() error {
+	return s.aurErr
+}
+
+func (s *SourceQueryBuilder) SearchResults() []Result {
+	results := make([]Result, 0, len(s.results))
+
+	for i := range s.results {
+		result := Result{
+			Source:      s.results[i].source,
+			Name:        s.results[i].name,
+			Description: s.results[i].description,
+			Votes:       max(s.results[i].votes, 0),
+			Provides:    s.results[i].provides,
+		}
+
+		switch pPkg := s.queryMap[s.results[i].source][s.results[i].name].(type) {
+		case aur.Pkg:
+			result.Version = pPkg.Version
+			result.Origin, _ = Origin(s.aurClient, pPkg.Name, "")
+		case alpm.IPackage:
+			result.Version = pPkg.Version()
+		}
+
+		results = append(results, result)
+	}
+
+	return results
+}
+
 func (s *SourceQueryBuilder) Len() int {
 	return len(s.results)
 }
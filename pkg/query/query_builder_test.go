@@ -31,6 +31,7 @@ func TestSourceQueryBuilder(t *testing.T) {
 		targetMode        parser.TargetMode
 		singleLineResults bool
 		searchBy          string
+		printFormat       string
 		wantResults       []string
 		wantOutput        []string
 	}
@@ -274,6 +275,29 @@ func TestSourceQueryBuilder(t *testing.T) {
 				"\x1b[1m\x1b[34maur\x1b[0m\x1b[0m/\x1b[1mlinux-ck\x1b[0m \x1b[36m5.16.12-1\x1b[0m\x1b[1m (+450\x1b[0m \x1b[1m1.51) \x1b[0m\n    The Linux-ck kernel and modules with ck's hrtimer patches\n",
 			},
 		},
+		{
+			desc:        "search-by-maintainer skips repositories",
+			search:      []string{"graysky"},
+			sortBy:      "name",
+			verbosity:   Minimal,
+			searchBy:    "maintainer",
+			wantResults: []string{"linux-ck"},
+			wantOutput:  []string{"linux-ck\n"},
+		},
+		{
+			desc:            "sort-by-name print-format",
+			search:          []string{"linux"},
+			bottomUp:        true,
+			separateSources: true,
+			sortBy:          "name",
+			printFormat:     "%r/%n %v %w %m",
+			wantResults:     []string{"linux-ck", "linux", "linux-zen"},
+			wantOutput: []string{
+				"aur/linux-ck 5.16.12-1 450 graysky\n",
+				"core/linux 5.16.0 %w %m\n",
+				"core/linux-zen 5.16.0 %w %m\n",
+			},
+		},
 		{
 			desc:            "only-aur search-by-several-terms",
 			search:          []string{"linux-ck", "hrtimer"},
@@ -354,7 +378,11 @@ func TestSourceQueryBuilder(t *testing.T) {
 				assert.Equal(t, name, queryBuilder.results[i].name)
 			}
 
-			queryBuilder.Results(mockDB, tc.verbosity)
+			if tc.printFormat != "" {
+				queryBuilder.FormatResults(tc.printFormat)
+			} else {
+				queryBuilder.Results(mockDB, tc.verbosity)
+			}
 
 			assert.Equal(t, strings.Join(tc.wantOutput, ""), w.String())
 		})
@@ -47,12 +47,18 @@ func getSearchBy(value string) aur.By {
 	}
 }
 
+// isAccountField reports whether by searches the AUR accounts of packages.
+func isAccountField(by aur.By) bool {
+	return by == aur.Maintainer || by == aur.Submitter || by == aur.CoMaintainers
+}
+
 func aurPkgSearchString(
 	pkg *aur.Pkg,
+	origin string,
 	dbExecutor db.Executor,
 	singleLineResults bool,
 ) string {
-	toPrint := text.Bold(text.ColorHash("aur")) + "/" + text.Bold(pkg.Name) +
+	toPrint := text.Bold(text.ColorHash(origin)) + "/" + text.Bold(pkg.Name) +
 		" " + text.Cyan(pkg.Version) +
 		text.Bold(" (+"+strconv.Itoa(pkg.NumVotes)) +
 		" " + text.Bold(strconv.FormatFloat(pkg.Popularity, 'f', 2, 64)+") ")
@@ -0,0 +1,74 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+
+	"github.com/Jguer/aur"
+	"github.com/leonelquinteros/gotext"
+
+	"github.com/Jguer/yippee/v12/pkg/query"
+	"github.com/Jguer/yippee/v12/pkg/settings"
+	"github.com/Jguer/yippee/v12/pkg/text"
+)
+
+// officialBackend names the AUR among the AUR compatible backends.
+const officialBackend = "aur"
+
+// withAURBackends puts the AUR compatible backends of cfg, ordered by
+// priority, around the clients searching the AUR and fetching its package
+// info. Either way the other backends are queried through their RPC.
+func withAURBackends(cfg *settings.Configuration, httpClient *http.Client,
+	userAgentFn aur.RequestEditorFn, logger *text.Logger,
+	search, info aur.QueryClient,
+) (searchBackends, infoBackends aur.QueryClient, err error) {
+	configs := append([]settings.AURBackend{{Name: officialBackend, URL: cfg.AURURL}}, cfg.AURBackends...)
+	names := make(map[string]bool, len(configs))
+
+	for i := range configs {
+		switch {
+		case configs[i].Name == "" || configs[i].URL == "":
+			return nil, nil, errors.New(gotext.Get("aur backends need a name and a url"))
+		case names[configs[i].Name]:
+			return nil, nil, errors.New(gotext.Get("aur backend %s is defined twice", configs[i].Name))
+		}
+
+		names[configs[i].Name] = true
+	}
+
+	// the AUR comes first among the backends of the same priority
+	sort.SliceStable(configs, func(i, j int) bool { return configs[i].Priority > configs[j].Priority })
+
+	searchList := make([]query.Backend, 0, len(configs))
+	infoList := make([]query.Backend, 0, len(configs))
+
+	for i := range configs {
+		backend := configs[i]
+
+		if backend.Name == officialBackend {
+			searchList = append(searchList, query.Backend{Name: backend.Name, URL: backend.URL, Client: search})
+			infoList = append(infoList, query.Backend{Name: backend.Name, URL: backend.URL, Client: info})
+
+			continue
+		}
+
+		editorFn := userAgentFn
+		if backend.Auth != "" {
+			editorFn = func(ctx context.Context, req *http.Request) error {
+				req.Header.Set("Authorization", backend.Auth)
+				return userAgentFn(ctx, req)
+			}
+		}
+
+		client := &lazyQueryClient{newFn: func() (aur.QueryClient, error) {
+			return newRPCClient(backend.RPCURL, backend.Name, httpClient, editorFn, logger)
+		}}
+
+		searchList = append(searchList, query.Backend{Name: backend.Name, URL: backend.URL, Client: client})
+		infoList = append(infoList, query.Backend{Name: backend.Name, URL: backend.URL, Client: client})
+	}
+
+	return query.NewBackends(logger.Child("aur"), searchList...), query.NewBackends(logger.Child("aur"), infoList...), nil
+}
@@ -0,0 +1,68 @@
+//go:build !integration
+// +build !integration
+
+package runtime
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Jguer/aur"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jguer/yippee/v12/pkg/query"
+	"github.com/Jguer/yippee/v12/pkg/settings"
+	"github.com/Jguer/yippee/v12/pkg/text"
+)
+
+func TestWithAURBackends(t *testing.T) {
+	t.Parallel()
+
+	var gotAuth string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		_, _ = w.Write([]byte(`{"version":5,"type":"multiinfo","resultcount":1,` +
+			`"results":[{"Name":"yippee","PackageBase":"yippee","Version":"99-1"}]}`))
+	}))
+	defer server.Close()
+
+	cfg := &settings.Configuration{
+		AURURL: "https://aur.archlinux.org",
+		AURBackends: []settings.AURBackend{
+			{Name: "mirror", URL: "https://mirror.example.com", RPCURL: "https://mirror.example.com/rpc?", Priority: -1},
+			{Name: "private", URL: server.URL, RPCURL: server.URL + "/rpc?", Auth: "Bearer secret", Priority: 10},
+		},
+	}
+	logger := text.NewLogger(io.Discard, io.Discard, strings.NewReader(""), false, "test")
+	official := fakeQueryClient{pkgs: []aur.Pkg{{Name: "yippee", Version: "12-1"}}}
+	userAgentFn := func(ctx context.Context, req *http.Request) error { return nil }
+
+	_, info, err := withAURBackends(cfg, server.Client(), userAgentFn, logger, official, official)
+	require.NoError(t, err)
+
+	pkgs, err := info.Get(context.Background(), &aur.Query{Needles: []string{"yippee"}, By: aur.Name})
+	require.NoError(t, err)
+	require.Len(t, pkgs, 1)
+	assert.Equal(t, "99-1", pkgs[0].Version)
+	assert.Equal(t, "Bearer secret", gotAuth)
+
+	origin, aurURL := query.Origin(info, "yippee", cfg.AURURL)
+	assert.Equal(t, "private", origin)
+	assert.Equal(t, server.URL, aurURL)
+
+	for _, backends := range [][]settings.AURBackend{
+		{{Name: "aur", URL: "https://aur.example.com"}},
+		{{Name: "private"}},
+	} {
+		cfg := &settings.Configuration{AURURL: "https://aur.archlinux.org", AURBackends: backends}
+
+		_, _, err := withAURBackends(cfg, server.Client(), userAgentFn, logger, official, official)
+		assert.Error(t, err)
+	}
+}
@@ -0,0 +1,63 @@
+package runtime
+
+import (
+	"errors"
+	"os"
+	"strings"
+
+	"github.com/leonelquinteros/gotext"
+
+	"github.com/Jguer/yippee/v12/pkg/text"
+)
+
+var levelNames = map[string]text.Level{
+	"quiet":   text.LevelQuiet,
+	"normal":  text.LevelNormal,
+	"verbose": text.LevelVerbose,
+	"trace":   text.LevelTrace,
+	"debug":   text.LevelTrace,
+}
+
+// configureLoggers sets the levels of the child loggers from spec, a comma
+// or space separated list of name=level[:file] like grapher=debug or
+// db=trace:/tmp/db.log. The log files opened are returned to be closed once
+// the run is over.
+func configureLoggers(logger *text.Logger, spec string) ([]*os.File, error) {
+	files := make([]*os.File, 0)
+
+	for _, entry := range strings.FieldsFunc(spec, func(r rune) bool { return r == ',' || r == ' ' }) {
+		name, value, ok := strings.Cut(entry, "=")
+		levelName, path, _ := strings.Cut(value, ":")
+
+		level, known := levelNames[levelName]
+		if !ok || name == "" || !known {
+			closeLogFiles(files)
+
+			return nil, errors.New(gotext.Get("invalid log level: %s", entry))
+		}
+
+		config := text.ChildConfig{Level: level, Debug: levelName == "debug"}
+
+		if path != "" {
+			file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+			if err != nil {
+				closeLogFiles(files)
+
+				return nil, err
+			}
+
+			files = append(files, file)
+			config.Output = file
+		}
+
+		logger.ConfigureChild(name, config)
+	}
+
+	return files, nil
+}
+
+func closeLogFiles(files []*os.File) {
+	for _, file := range files {
+		file.Close()
+	}
+}
@@ -0,0 +1,83 @@
+//go:build !integration
+// +build !integration
+
+package runtime
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jguer/yippee/v12/pkg/text"
+)
+
+func TestConfigureLoggers(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	testCases := []struct {
+		name    string
+		spec    string
+		wantErr bool
+	}{
+		{name: "empty", spec: ""},
+		{name: "levels", spec: "grapher=debug, db=trace"},
+		{name: "file", spec: "grapher=verbose:" + filepath.Join(dir, "grapher.log")},
+		{name: "unknown level", spec: "grapher=loud", wantErr: true},
+		{name: "missing level", spec: "grapher", wantErr: true},
+		{name: "missing name", spec: "=debug", wantErr: true},
+		{name: "unwritable file", spec: "db=trace:" + filepath.Join(dir, "missing", "db.log"), wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			logger := text.NewLogger(io.Discard, io.Discard, strings.NewReader(""), false, "test")
+
+			files, err := configureLoggers(logger, tc.spec)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			closeLogFiles(files)
+		})
+	}
+}
+
+func TestConfigureLoggersFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "grapher.log")
+
+	var stderr strings.Builder
+
+	logger := text.NewLogger(io.Discard, &stderr, strings.NewReader(""), false, "test")
+
+	files, err := configureLoggers(logger, "grapher=verbose:"+path+",db=quiet")
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	assert.Equal(t, text.LevelQuiet, logger.Child("db").Level)
+
+	grapher := logger.Child("grapher")
+	assert.Equal(t, text.LevelVerbose, grapher.Level)
+	assert.False(t, grapher.Debug)
+
+	grapher.Verboseln("resolving")
+	closeLogFiles(files)
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "resolving")
+	assert.Empty(t, stderr.String())
+}
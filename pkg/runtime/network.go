@@ -0,0 +1,84 @@
+package runtime
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/leonelquinteros/gotext"
+
+	"github.com/Jguer/yippee/v12/pkg/settings"
+)
+
+// newTransport returns the HTTP transport honoring the configured proxy, the
+// hosts to reach without it and the extra CA bundle. Without any of them the
+// proxy environment variables apply, as for http.DefaultTransport.
+func newTransport(cfg *settings.Configuration) (http.RoundTripper, error) {
+	if cfg.Proxy == "" && cfg.CABundle == "" {
+		return http.DefaultTransport, nil
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if cfg.Proxy != "" {
+		proxyURL, err := url.Parse(cfg.Proxy)
+		if err != nil || proxyURL.Host == "" {
+			return nil, errors.New(gotext.Get("invalid proxy URL: %s", cfg.Proxy))
+		}
+
+		switch proxyURL.Scheme {
+		case "http", "https", "socks5":
+		default:
+			return nil, errors.New(gotext.Get("unsupported proxy scheme: %s", proxyURL.Scheme))
+		}
+
+		noProxy := strings.Split(cfg.NoProxy, ",")
+		transport.Proxy = func(req *http.Request) (*url.URL, error) {
+			if bypassProxy(req.URL.Hostname(), noProxy) {
+				return nil, nil
+			}
+
+			return proxyURL, nil
+		}
+	}
+
+	if cfg.CABundle != "" {
+		bundle, err := os.ReadFile(cfg.CABundle)
+		if err != nil {
+			return nil, err
+		}
+
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+
+		if !pool.AppendCertsFromPEM(bundle) {
+			return nil, errors.New(gotext.Get("no certificates found in %s", cfg.CABundle))
+		}
+
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	}
+
+	return transport, nil
+}
+
+// bypassProxy reports whether host matches a no proxy entry. An entry matches
+// the host and its subdomains, "*" matches every host.
+func bypassProxy(host string, noProxy []string) bool {
+	for _, entry := range noProxy {
+		entry = strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(entry), "*."), ".")
+
+		switch {
+		case entry == "":
+		case entry == "*", host == entry, strings.HasSuffix(host, "."+entry):
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,74 @@
+//go:build !integration
+// +build !integration
+
+package runtime
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jguer/yippee/v12/pkg/settings"
+)
+
+func TestBypassProxy(t *testing.T) {
+	t.Parallel()
+
+	noProxy := []string{"localhost", " .corp.example", "*.internal", ""}
+
+	testCases := []struct {
+		host string
+		want bool
+	}{
+		{host: "localhost", want: true},
+		{host: "git.corp.example", want: true},
+		{host: "corp.example", want: true},
+		{host: "mirror.internal", want: true},
+		{host: "aur.archlinux.org", want: false},
+		{host: "notcorp.example", want: false},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.want, bypassProxy(tc.host, noProxy), tc.host)
+	}
+
+	assert.True(t, bypassProxy("aur.archlinux.org", []string{"*"}))
+}
+
+func TestNewTransport(t *testing.T) {
+	t.Parallel()
+
+	transport, err := newTransport(&settings.Configuration{})
+	require.NoError(t, err)
+	assert.Equal(t, http.DefaultTransport, transport)
+
+	transport, err = newTransport(&settings.Configuration{Proxy: "socks5://proxy:1080", NoProxy: "localhost"})
+	require.NoError(t, err)
+
+	proxy := transport.(*http.Transport).Proxy
+
+	req, err := http.NewRequest(http.MethodGet, "https://aur.archlinux.org/rpc", http.NoBody)
+	require.NoError(t, err)
+	proxyURL, err := proxy(req)
+	require.NoError(t, err)
+	assert.Equal(t, "socks5://proxy:1080", proxyURL.String())
+
+	req, err = http.NewRequest(http.MethodGet, "http://localhost:8080", http.NoBody)
+	require.NoError(t, err)
+	proxyURL, err = proxy(req)
+	require.NoError(t, err)
+	assert.Nil(t, proxyURL)
+
+	_, err = newTransport(&settings.Configuration{Proxy: "ftp://proxy:21"})
+	assert.Error(t, err)
+
+	bundle := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(bundle, []byte("not a certificate"), 0o600))
+
+	_, err = newTransport(&settings.Configuration{CABundle: bundle})
+	assert.Error(t, err)
+}
@@ -0,0 +1,197 @@
+package runtime
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/Jguer/aur"
+	"github.com/Jguer/go-alpm/v2"
+	gosrc "github.com/Morganamilo/go-srcinfo"
+
+	"github.com/Jguer/yippee/v12/pkg/aurweb"
+	"github.com/Jguer/yippee/v12/pkg/settings/exe"
+	"github.com/Jguer/yippee/v12/pkg/text"
+	"github.com/Jguer/yippee/v12/pkg/vcs"
+)
+
+// Option replaces a part of the Runtime built by NewRuntime, the default
+// is not built at all.
+type Option func(*Runtime)
+
+func WithLogger(logger *text.Logger) Option {
+	return func(r *Runtime) {
+		r.Logger = logger
+	}
+}
+
+// WithHTTPClient sets the client used for every request. The AUR clients
+// share its transport but never follow redirects.
+func WithHTTPClient(client *http.Client) Option {
+	return func(r *Runtime) {
+		r.HTTPClient = client
+	}
+}
+
+// WithAURClient sets the client used both for AUR metadata and searches.
+func WithAURClient(client aur.QueryClient) Option {
+	return func(r *Runtime) {
+		r.AURClient = client
+	}
+}
+
+func WithAURWebClient(client *aurweb.Client) Option {
+	return func(r *Runtime) {
+		r.aurWebClient = &lazyAURWebClient{client: client}
+	}
+}
+
+func WithCmdBuilder(cmdBuilder exe.ICmdBuilder) Option {
+	return func(r *Runtime) {
+		r.CmdBuilder = cmdBuilder
+	}
+}
+
+// WithVCSStore sets the VCS store, it is used as given and not loaded.
+func WithVCSStore(store vcs.Store) Option {
+	return func(r *Runtime) {
+		r.VCSStore = store
+	}
+}
+
+// lazyQueryClient builds its client on the first query, so operations that
+// never reach the AUR do not pay for it.
+type lazyQueryClient struct {
+	newFn func() (aur.QueryClient, error)
+
+	once   sync.Once
+	client aur.QueryClient
+	err    error
+}
+
+func (c *lazyQueryClient) Get(ctx context.Context, query *aur.Query) ([]aur.Pkg, error) {
+	c.once.Do(func() {
+		c.client, c.err = c.newFn()
+	})
+
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	return c.client.Get(ctx, query)
+}
+
+// lazyAURWebClient builds its client on first use, only votes and flags
+// need it.
+type lazyAURWebClient struct {
+	newFn func() *aurweb.Client
+
+	once   sync.Once
+	client *aurweb.Client
+}
+
+func (c *lazyAURWebClient) get() *aurweb.Client {
+	c.once.Do(func() {
+		if c.client == nil {
+			c.client = c.newFn()
+		}
+	})
+
+	return c.client
+}
+
+// lazyVCSStore loads the VCS store on first use, most operations never look
+// at it. A store that can not be read is left alone: it tracks nothing and
+// its file is not overwritten.
+type lazyVCSStore struct {
+	newFn  func() *vcs.InfoStore
+	logger *text.Logger
+
+	once  sync.Once
+	store *vcs.InfoStore
+	err   error
+}
+
+// load returns the loaded store, nil if it could not be read.
+func (s *lazyVCSStore) load() *vcs.InfoStore {
+	s.once.Do(func() {
+		store := s.newFn()
+		if s.err = store.Load(); s.err != nil {
+			s.logger.Errorln(s.err)
+			return
+		}
+
+		s.store = store
+	})
+
+	return s.store
+}
+
+func (s *lazyVCSStore) Tracked(pkgName string) bool {
+	if store := s.load(); store != nil {
+		return store.Tracked(pkgName)
+	}
+
+	return false
+}
+
+func (s *lazyVCSStore) ToUpgrade(ctx context.Context, pkgName string) bool {
+	if store := s.load(); store != nil {
+		return store.ToUpgrade(ctx, pkgName)
+	}
+
+	return false
+}
+
+func (s *lazyVCSStore) ToUpgradeAll(ctx context.Context, pkgNames []string) map[string]bool {
+	if store := s.load(); store != nil {
+		return store.ToUpgradeAll(ctx, pkgNames)
+	}
+
+	return map[string]bool{}
+}
+
+func (s *lazyVCSStore) CommitLog(ctx context.Context, pkgName string) []string {
+	if store := s.load(); store != nil {
+		return store.CommitLog(ctx, pkgName)
+	}
+
+	return nil
+}
+
+func (s *lazyVCSStore) Update(ctx context.Context, pkgName string, sources []gosrc.ArchString) {
+	if store := s.load(); store != nil {
+		store.Update(ctx, pkgName, sources)
+	}
+}
+
+func (s *lazyVCSStore) RemovePackages(pkgs []string) {
+	if store := s.load(); store != nil {
+		store.RemovePackages(pkgs)
+	}
+}
+
+func (s *lazyVCSStore) CleanOrphans(pkgs map[string]alpm.IPackage) {
+	if store := s.load(); store != nil {
+		store.CleanOrphans(pkgs)
+	}
+}
+
+func (s *lazyVCSStore) ResetCache() {
+	if store := s.load(); store != nil {
+		store.ResetCache()
+	}
+}
+
+func (s *lazyVCSStore) Load() error {
+	s.load()
+	return s.err
+}
+
+func (s *lazyVCSStore) Save() error {
+	if store := s.load(); store != nil {
+		return store.Save()
+	}
+
+	return s.err
+}
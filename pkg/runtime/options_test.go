@@ -0,0 +1,122 @@
+//go:build !integration
+// +build !integration
+
+package runtime
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Jguer/aur"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jguer/yippee/v12/pkg/aurweb"
+	"github.com/Jguer/yippee/v12/pkg/settings/exe"
+	"github.com/Jguer/yippee/v12/pkg/text"
+	"github.com/Jguer/yippee/v12/pkg/vcs"
+)
+
+func TestLazyQueryClient(t *testing.T) {
+	t.Parallel()
+
+	built := 0
+	client := &lazyQueryClient{newFn: func() (aur.QueryClient, error) {
+		built++
+		return fakeQueryClient{pkgs: []aur.Pkg{{Name: "yippee"}}}, nil
+	}}
+
+	assert.Equal(t, 0, built)
+
+	for i := 0; i < 2; i++ {
+		pkgs, err := client.Get(context.Background(), &aur.Query{Needles: []string{"yippee"}})
+		assert.NoError(t, err)
+		assert.Len(t, pkgs, 1)
+	}
+
+	assert.Equal(t, 1, built)
+
+	errBuild := errors.New("bad url")
+	failing := &lazyQueryClient{newFn: func() (aur.QueryClient, error) {
+		return nil, errBuild
+	}}
+
+	_, err := failing.Get(context.Background(), &aur.Query{})
+	assert.ErrorIs(t, err, errBuild)
+}
+
+func TestLazyAURWebClient(t *testing.T) {
+	t.Parallel()
+
+	built := 0
+	client := &lazyAURWebClient{newFn: func() *aurweb.Client {
+		built++
+		return aurweb.NewClient(nil, "", "", "")
+	}}
+
+	assert.Equal(t, 0, built)
+	assert.Same(t, client.get(), client.get())
+	assert.Equal(t, 1, built)
+
+	given := aurweb.NewClient(nil, "", "", "")
+	assert.Same(t, given, (&lazyAURWebClient{client: given}).get())
+}
+
+func TestLazyVCSStore(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc        string
+		content     string
+		wantTracked bool
+		wantErr     bool
+	}{
+		{desc: "readable", content: `{"foo": {}}`, wantTracked: true},
+		{desc: "unreadable", content: "not json", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "vcs.json")
+			require.NoError(t, os.WriteFile(path, []byte(tc.content), 0o644))
+
+			built := 0
+			logger := text.NewLogger(io.Discard, io.Discard, strings.NewReader(""), false, "test")
+			store := &lazyVCSStore{
+				newFn: func() *vcs.InfoStore {
+					built++
+					return vcs.NewInfoStore(path, &exe.MockBuilder{}, logger)
+				},
+				logger: logger,
+			}
+
+			assert.Equal(t, 0, built)
+			assert.Equal(t, tc.wantTracked, store.Tracked("foo"))
+			assert.Equal(t, 1, built)
+
+			store.RemovePackages([]string{"foo"})
+			assert.False(t, store.Tracked("foo"))
+
+			if !tc.wantErr {
+				assert.NoError(t, store.Load())
+				return
+			}
+
+			// what could not be read is not overwritten
+			assert.Error(t, store.Load())
+			assert.Error(t, store.Save())
+
+			content, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, tc.content, string(content))
+		})
+	}
+}
@@ -1,16 +1,72 @@
 package runtime
 
 import (
+	"bufio"
 	"fmt"
 	"os"
+	"os/exec"
+	"strconv"
+	"strings"
 
 	"github.com/Jguer/yippee/v12/pkg/settings/parser"
+	"github.com/Jguer/yippee/v12/pkg/text"
 
 	pacmanconf "github.com/Morganamilo/go-pacmanconf"
 	"golang.org/x/term"
 )
 
-func retrievePacmanConfig(cmdArgs *parser.Arguments, pacmanConfigPath string) (*pacmanconf.Config, bool, error) {
+// PacmanOptions holds the pacman.conf options yippee mirrors that
+// go-pacmanconf does not read.
+type PacmanOptions struct {
+	ParallelDownloads int
+	ILoveCandy        bool
+}
+
+// parsePacmanOptions reads PacmanOptions from the output of pacman-conf.
+func parsePacmanOptions(output string) PacmanOptions {
+	options := PacmanOptions{ParallelDownloads: 1}
+	section := ""
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = line[1 : len(line)-1]
+			continue
+		}
+
+		if section != "options" {
+			continue
+		}
+
+		key, value, _ := strings.Cut(line, "=")
+
+		switch strings.TrimSpace(key) {
+		case "ParallelDownloads":
+			if n, err := strconv.Atoi(strings.TrimSpace(value)); err == nil && n > 0 {
+				options.ParallelDownloads = n
+			}
+		case "ILoveCandy":
+			options.ILoveCandy = true
+		}
+	}
+
+	return options
+}
+
+// retrievePacmanOptions runs pacman-conf like retrievePacmanConfig for
+// the options it leaves out.
+func retrievePacmanOptions(pacmanConfigPath, root string) (PacmanOptions, error) {
+	output, err := exec.Command("pacman-conf", "--config", pacmanConfigPath, "--root", root).Output()
+	if err != nil {
+		return PacmanOptions{ParallelDownloads: 1}, err
+	}
+
+	return parsePacmanOptions(string(output)), nil
+}
+
+func retrievePacmanConfig(cmdArgs *parser.Arguments, pacmanConfigPath, color string) (*pacmanconf.Config, bool, error) {
 	root := "/"
 	if value, _, exists := cmdArgs.GetArg("root", "r"); exists {
 		root = value
@@ -50,15 +106,13 @@ func retrievePacmanConfig(cmdArgs *parser.Arguments, pacmanConfigPath string) (*
 		pacmanConf.GPGDir = gpgDir
 	}
 
-	useColor := pacmanConf.Color && term.IsTerminal(int(os.Stdout.Fd()))
+	if value, _, exists := cmdArgs.GetArg("color"); exists {
+		color = value
+	}
 
-	switch value, _, _ := cmdArgs.GetArg("color"); value {
-	case "always":
-		useColor = true
-	case "auto":
-		useColor = term.IsTerminal(int(os.Stdout.Fd()))
-	case "never":
-		useColor = false
+	useColor, err := text.ShouldUseColor(color, pacmanConf.Color, term.IsTerminal(int(os.Stdout.Fd())))
+	if err != nil {
+		return nil, false, err
 	}
 
 	return pacmanConf, useColor, nil
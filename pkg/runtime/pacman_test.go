@@ -51,9 +51,43 @@ func TestPacmanConf(t *testing.T) {
 		},
 	}
 
-	pacmanConf, color, err := retrievePacmanConfig(parser.MakeArguments(), absPath)
+	pacmanConf, color, err := retrievePacmanConfig(parser.MakeArguments(), absPath, "")
 	assert.Nil(t, err)
 	assert.NotNil(t, pacmanConf)
 	assert.Equal(t, color, false)
 	assert.EqualValues(t, expectedPacmanConf, pacmanConf)
 }
+
+func TestParsePacmanOptions(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc   string
+		output string
+		want   PacmanOptions
+	}{
+		{
+			desc:   "defaults",
+			output: "[options]\nRootDir = /\nCheckSpace\n",
+			want:   PacmanOptions{ParallelDownloads: 1},
+		},
+		{
+			desc:   "set",
+			output: "[options]\nParallelDownloads = 5\nILoveCandy\n[core]\nServer = https://mirror/core\n",
+			want:   PacmanOptions{ParallelDownloads: 5, ILoveCandy: true},
+		},
+		{
+			desc:   "repository sections are ignored",
+			output: "[options]\nParallelDownloads = nope\n[custom]\nILoveCandy\n",
+			want:   PacmanOptions{ParallelDownloads: 1},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.want, parsePacmanOptions(tc.output))
+		})
+	}
+}
@@ -0,0 +1,318 @@
+package runtime
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/leonelquinteros/gotext"
+
+	"github.com/Jguer/yippee/v12/pkg/settings/parser"
+)
+
+const (
+	// exchangesFile holds the HTTP exchanges of a recording.
+	exchangesFile = "http.json"
+	// dbDir holds the copy of the pacman databases of a recording.
+	dbDir = "db"
+)
+
+// exchange is an HTTP request of a recording along with its response.
+type exchange struct {
+	Method      string      `json:"method"`
+	URL         string      `json:"url"`
+	RequestBody []byte      `json:"requestBody,omitempty"`
+	Status      int         `json:"status"`
+	Header      http.Header `json:"header"`
+	Body        []byte      `json:"body"`
+}
+
+func (e *exchange) matches(req *http.Request, body []byte) bool {
+	return e.Method == req.Method && e.URL == req.URL.String() && bytes.Equal(e.RequestBody, redactBody(body))
+}
+
+// sensitiveHeaders are left out of recordings, which end up in bug reports.
+var sensitiveHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// sensitiveFields are the form fields of request bodies redacted in
+// recordings.
+var sensitiveFields = []string{"user", "passwd", "password", "token", "token_secret"}
+
+const redacted = "REDACTED"
+
+// redactHeader returns header without the credentials it carries.
+func redactHeader(header http.Header) http.Header {
+	header = header.Clone()
+	for _, key := range sensitiveHeaders {
+		header.Del(key)
+	}
+
+	return header
+}
+
+// redactBody returns the form encoded body with the values of its sensitive
+// fields replaced. Other bodies are returned as is.
+func redactBody(body []byte) []byte {
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return body
+	}
+
+	found := false
+
+	for _, key := range sensitiveFields {
+		if form.Has(key) {
+			form.Set(key, redacted)
+
+			found = true
+		}
+	}
+
+	if !found {
+		return body
+	}
+
+	return []byte(form.Encode())
+}
+
+// readRequestBody returns the body of req and leaves it readable again.
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+
+	if err != nil {
+		return nil, err
+	}
+
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	return body, nil
+}
+
+// recordingTransport saves every HTTP exchange to the recording in dir. The
+// file is written again after each one so an interrupted run is recorded up
+// to where it stopped. Credentials are redacted, still the file is only
+// readable by its owner.
+type recordingTransport struct {
+	next      http.RoundTripper
+	dir       string
+	mux       sync.Mutex
+	exchanges []exchange
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	t.exchanges = append(t.exchanges, exchange{
+		Method:      req.Method,
+		URL:         req.URL.String(),
+		RequestBody: redactBody(reqBody),
+		Status:      resp.StatusCode,
+		Header:      redactHeader(resp.Header),
+		Body:        body,
+	})
+
+	marshalled, err := json.MarshalIndent(t.exchanges, "", "\t")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.WriteFile(filepath.Join(t.dir, exchangesFile), marshalled, 0o600); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// replayTransport answers HTTP requests with the responses of a recording,
+// without reaching the network.
+type replayTransport struct {
+	exchanges []exchange
+}
+
+func newReplayTransport(dir string) (*replayTransport, error) {
+	content, err := os.ReadFile(filepath.Join(dir, exchangesFile))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return &replayTransport{}, nil
+		}
+
+		return nil, err
+	}
+
+	t := &replayTransport{}
+	if err := json.Unmarshal(content, &t.exchanges); err != nil {
+		return nil, fmt.Errorf(gotext.Get("invalid recording %s", dir)+": %w", err)
+	}
+
+	return t, nil
+}
+
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range t.exchanges {
+		if e := &t.exchanges[i]; e.matches(req, body) {
+			return &http.Response{
+				Status:        fmt.Sprintf("%d %s", e.Status, http.StatusText(e.Status)),
+				StatusCode:    e.Status,
+				Proto:         "HTTP/1.1",
+				ProtoMajor:    1,
+				ProtoMinor:    1,
+				Header:        e.Header.Clone(),
+				Body:          io.NopCloser(bytes.NewReader(e.Body)),
+				ContentLength: int64(len(e.Body)),
+				Request:       req,
+			}, nil
+		}
+	}
+
+	return nil, errors.New(gotext.Get("%s %s is not part of the recording", req.Method, req.URL.Redacted()))
+}
+
+// recordDatabases copies the local database and the sync databases of
+// dbPath to the recording in dir.
+func recordDatabases(dbPath, dir string) error {
+	dest := filepath.Join(dir, dbDir)
+
+	if err := os.RemoveAll(dest); err != nil {
+		return err
+	}
+
+	if err := copyTree(filepath.Join(dbPath, "local"), filepath.Join(dest, "local")); err != nil {
+		return err
+	}
+
+	syncDBs, err := filepath.Glob(filepath.Join(dbPath, "sync", "*.db"))
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Join(dest, "sync"), 0o755); err != nil {
+		return err
+	}
+
+	for _, syncDB := range syncDBs {
+		if err := copyFile(syncDB, filepath.Join(dest, "sync", filepath.Base(syncDB))); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func copyTree(src, dest string) error {
+	return filepath.WalkDir(src, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() {
+			return os.MkdirAll(filepath.Join(dest, rel), 0o755)
+		}
+
+		return copyFile(path, filepath.Join(dest, rel))
+	})
+}
+
+func copyFile(src, dest string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
+
+// prepareRecording checks the --record and --replay options and points the
+// run at the databases of a replayed recording. A replay stops once the
+// targets are resolved, only the operations resolving targets that way are
+// allowed.
+func prepareRecording(recordDir, replayDir string, cmdArgs *parser.Arguments) error {
+	switch {
+	case recordDir != "" && replayDir != "":
+		return errors.New(gotext.Get("--record and --replay cannot be used together"))
+	case recordDir != "":
+		if err := os.MkdirAll(recordDir, 0o755); err != nil {
+			return err
+		}
+
+		// a new recording replaces the previous one, with its permissions
+		if err := os.Remove(filepath.Join(recordDir, exchangesFile)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+
+		return nil
+	case replayDir == "":
+		return nil
+	}
+
+	switch cmdArgs.Op {
+	case "S", "sync", "Q", "query", "Y", "yippee":
+	default:
+		return errors.New(gotext.Get("--replay only works with -S, -Q and -Y"))
+	}
+
+	if _, err := os.Stat(filepath.Join(replayDir, dbDir, "local")); err != nil {
+		return fmt.Errorf(gotext.Get("invalid recording %s", replayDir)+": %w", err)
+	}
+
+	// the recorded databases are the ones to resolve against, refreshing
+	// them would defeat the purpose
+	cmdArgs.DelArg("y", "refresh", "b", "dbpath")
+	cmdArgs.CreateOrAppendOption("dbpath", filepath.Join(replayDir, dbDir))
+	cmdArgs.Options["dbpath"].Global = true
+
+	return nil
+}
@@ -0,0 +1,195 @@
+//go:build !integration
+// +build !integration
+
+package runtime
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jguer/yippee/v12/pkg/settings/parser"
+)
+
+func TestRecordReplay(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"query":"` + r.URL.Query().Get("arg") + `","body":"` + string(body) + `"}`))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+
+	recorder := &http.Client{Transport: &recordingTransport{next: http.DefaultTransport, dir: dir}}
+
+	resp, err := recorder.Get(srv.URL + "/rpc?arg=yippee")
+	require.NoError(t, err)
+	recorded, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	resp, err = recorder.Post(srv.URL+"/rpc", "application/x-www-form-urlencoded", strings.NewReader("arg=paru"))
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	srv.Close()
+
+	replay, err := newReplayTransport(dir)
+	require.NoError(t, err)
+	require.Len(t, replay.exchanges, 2)
+
+	replayer := &http.Client{Transport: replay}
+
+	resp, err = replayer.Get(srv.URL + "/rpc?arg=yippee")
+	require.NoError(t, err)
+	replayed, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	assert.Equal(t, string(recorded), string(replayed))
+
+	resp, err = replayer.Post(srv.URL+"/rpc", "application/x-www-form-urlencoded", strings.NewReader("arg=paru"))
+	require.NoError(t, err)
+	replayed, err = io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, `{"query":"","body":"arg=paru"}`, string(replayed))
+
+	_, err = replayer.Post(srv.URL+"/rpc", "application/x-www-form-urlencoded", strings.NewReader("arg=yay"))
+	require.ErrorContains(t, err, "is not part of the recording")
+}
+
+func TestRecordDatabases(t *testing.T) {
+	t.Parallel()
+
+	dbPath := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dbPath, "local", "yippee-12.0.0-1"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dbPath, "local", "ALPM_DB_VERSION"), []byte("9\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dbPath, "local", "yippee-12.0.0-1", "desc"), []byte("%NAME%\nyippee\n"), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dbPath, "sync"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dbPath, "sync", "core.db"), []byte("core"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dbPath, "sync", "core.files"), []byte("files"), 0o644))
+
+	dir := t.TempDir()
+	require.NoError(t, recordDatabases(dbPath, dir))
+
+	desc, err := os.ReadFile(filepath.Join(dir, dbDir, "local", "yippee-12.0.0-1", "desc"))
+	require.NoError(t, err)
+	assert.Equal(t, "%NAME%\nyippee\n", string(desc))
+
+	assert.FileExists(t, filepath.Join(dir, dbDir, "local", "ALPM_DB_VERSION"))
+	assert.FileExists(t, filepath.Join(dir, dbDir, "sync", "core.db"))
+	assert.NoFileExists(t, filepath.Join(dir, dbDir, "sync", "core.files"))
+}
+
+func TestPrepareRecording(t *testing.T) {
+	t.Parallel()
+
+	recording := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(recording, dbDir, "local"), 0o755))
+
+	testCases := []struct {
+		name       string
+		args       []string
+		record     string
+		replay     string
+		wantErr    bool
+		wantDBPath string
+	}{
+		{name: "none", args: []string{"S"}},
+		{name: "record", args: []string{"S"}, record: filepath.Join(t.TempDir(), "rec")},
+		{name: "both", args: []string{"S"}, record: t.TempDir(), replay: recording, wantErr: true},
+		{name: "replay sync", args: []string{"S", "y"}, replay: recording, wantDBPath: filepath.Join(recording, dbDir)},
+		{name: "replay query", args: []string{"Q", "u"}, replay: recording, wantDBPath: filepath.Join(recording, dbDir)},
+		{name: "replay remove", args: []string{"R"}, replay: recording, wantErr: true},
+		{name: "replay no recording", args: []string{"S"}, replay: t.TempDir(), wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			cmdArgs := parser.MakeArguments()
+			require.NoError(t, cmdArgs.AddArg(tc.args...))
+
+			err := prepareRecording(tc.record, tc.replay, cmdArgs)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+
+			if tc.record != "" {
+				assert.DirExists(t, tc.record)
+			}
+
+			dbPath, _, _ := cmdArgs.GetArg("dbpath", "b")
+			assert.Equal(t, tc.wantDBPath, dbPath)
+
+			if tc.replay != "" {
+				assert.False(t, cmdArgs.ExistsArg("y", "refresh"))
+				assert.Contains(t, cmdArgs.FormatGlobals(), "--dbpath")
+			}
+		})
+	}
+}
+
+func TestRecordRedactsCredentials(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "AURSID", Value: "secret-session"})
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+
+	recorder := &http.Client{Transport: &recordingTransport{next: http.DefaultTransport, dir: dir}}
+
+	resp, err := recorder.Post(srv.URL+"/login", "application/x-www-form-urlencoded",
+		strings.NewReader("user=jguer&passwd=hunter2&next=%2F"))
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	info, err := os.Stat(filepath.Join(dir, exchangesFile))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	content, err := os.ReadFile(filepath.Join(dir, exchangesFile))
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), "hunter2")
+	assert.NotContains(t, string(content), "jguer")
+	assert.NotContains(t, string(content), "secret-session")
+
+	srv.Close()
+
+	replay, err := newReplayTransport(dir)
+	require.NoError(t, err)
+	require.Len(t, replay.exchanges, 1)
+	assert.Empty(t, replay.exchanges[0].Header.Values("Set-Cookie"))
+	assert.Equal(t, "text/html", replay.exchanges[0].Header.Get("Content-Type"))
+
+	// the redacted request is still found when replayed
+	resp, err = (&http.Client{Transport: replay}).Post(srv.URL+"/login", "application/x-www-form-urlencoded",
+		strings.NewReader("user=jguer&passwd=hunter2&next=%2F"))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
@@ -2,6 +2,7 @@ package runtime
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -9,118 +10,347 @@ import (
 
 	"github.com/leonelquinteros/gotext"
 
+	"github.com/Jguer/yippee/v12/pkg/aurweb"
+	"github.com/Jguer/yippee/v12/pkg/db"
+	"github.com/Jguer/yippee/v12/pkg/download"
+	"github.com/Jguer/yippee/v12/pkg/events"
 	"github.com/Jguer/yippee/v12/pkg/query"
 	"github.com/Jguer/yippee/v12/pkg/settings"
 	"github.com/Jguer/yippee/v12/pkg/settings/exe"
 	"github.com/Jguer/yippee/v12/pkg/settings/parser"
 	"github.com/Jguer/yippee/v12/pkg/text"
+	"github.com/Jguer/yippee/v12/pkg/timing"
 	"github.com/Jguer/yippee/v12/pkg/vcs"
 
 	"github.com/Jguer/aur"
 	"github.com/Jguer/aur/metadata"
 	"github.com/Jguer/aur/rpc"
-	"github.com/Jguer/votar/pkg/vote"
 	"github.com/Morganamilo/go-pacmanconf"
+	"golang.org/x/term"
 )
 
 type Runtime struct {
 	Cfg          *settings.Configuration
 	QueryBuilder query.Builder
 	PacmanConf   *pacmanconf.Config
+	PacmanOpts   PacmanOptions
 	VCSStore     vcs.Store
+	Providers    *db.ProviderChoices
+	Notes        *db.PackageNotes
+	AURMisses    *download.MissCache
 	CmdBuilder   exe.ICmdBuilder
 	HTTPClient   *http.Client
-	VoteClient   *vote.Client
 	AURClient    aur.QueryClient
 	Logger       *text.Logger
+	Tracer       *timing.Tracer
+	Events       *events.Emitter
+
+	logFiles []*os.File
+
+	aurWebClient *lazyAURWebClient
+}
+
+// AURWebClient returns the client acting on the AUR web interface, built on
+// first use.
+func (r *Runtime) AURWebClient() *aurweb.Client {
+	if r.aurWebClient == nil {
+		return nil
+	}
+
+	return r.aurWebClient.get()
 }
 
-func NewRuntime(cfg *settings.Configuration, cmdArgs *parser.Arguments, version string) (*Runtime, error) {
-	logger := text.NewLogger(os.Stdout, os.Stderr, os.Stdin, cfg.Debug, "runtime")
-	runner := exe.NewOSRunner(logger.Child("runner"))
+// NewRuntime builds the runtime of a yippee invocation. The parts given as
+// options are used as is. The AUR clients, the AUR web client and the VCS
+// store are only built once used.
+func NewRuntime(cfg *settings.Configuration, cmdArgs *parser.Arguments, version string,
+	opts ...Option,
+) (*Runtime, error) {
+	run := &Runtime{Cfg: cfg}
+	for _, opt := range opts {
+		opt(run)
+	}
+
+	if run.Logger == nil {
+		run.Logger = text.NewLogger(os.Stdout, os.Stderr, os.Stdin, cfg.Debug, "runtime")
+		run.Logger.Level = verbosity(cmdArgs)
+	}
+
+	logger := run.Logger
+
+	logFiles, err := configureLoggers(logger, cfg.LogLevels)
+	if err != nil {
+		return nil, err
+	}
+
+	run.logFiles = logFiles
+
+	if err := prepareRecording(cfg.Record, cfg.Replay, cmdArgs); err != nil {
+		return nil, err
+	}
+
+	// the metadata cache would answer from disk, recordings only hold the
+	// requests to the RPC
+	if cfg.Record != "" || cfg.Replay != "" {
+		cfg.UseRPC = true
+	}
+
+	// nobody can answer prompts without a terminal, unless told otherwise
+	// run unattended as cron jobs and scripts expect
+	if !cmdArgs.ExistsArg("confirm") && !term.IsTerminal(int(os.Stdin.Fd())) {
+		logger.NonInteractive = true
+		settings.NoConfirm = true
+	}
+
+	if run.HTTPClient == nil {
+		transport, err := newTransport(cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case cfg.Replay != "":
+			replay, errReplay := newReplayTransport(cfg.Replay)
+			if errReplay != nil {
+				return nil, errReplay
+			}
+
+			transport = replay
+		}
+
+		if httpLog := logger.Child("http"); httpLog.Debug || httpLog.Level >= text.LevelTrace {
+			transport = &tracingTransport{next: transport, log: httpLog}
+		}
+
+		run.HTTPClient = &http.Client{Transport: transport}
+	}
 
 	httpClient := &http.Client{
+		Transport: run.HTTPClient.Transport,
+		Timeout:   run.HTTPClient.Timeout,
 		CheckRedirect: func(req *http.Request, via []*http.Request) error {
 			return http.ErrUseLastResponse
 		},
 	}
 
-	userAgent := fmt.Sprintf("Yippee/%s", version)
-	voteClient, errVote := vote.NewClient(vote.WithUserAgent(userAgent),
-		vote.WithHTTPClient(httpClient))
-	if errVote != nil {
-		return nil, errVote
+	// only the AUR queries are recorded, the web client carries credentials
+	// and session cookies
+	queryHTTPClient := httpClient
+	if cfg.Record != "" {
+		queryHTTPClient = &http.Client{
+			Transport:     &recordingTransport{next: httpClient.Transport, dir: cfg.Record},
+			Timeout:       httpClient.Timeout,
+			CheckRedirect: httpClient.CheckRedirect,
+		}
 	}
 
-	voteClient.SetCredentials(
-		os.Getenv("AUR_USERNAME"),
-		os.Getenv("AUR_PASSWORD"))
+	userAgent := fmt.Sprintf("Yippee/%s", version)
+
+	if run.aurWebClient == nil {
+		run.aurWebClient = &lazyAURWebClient{newFn: func() *aurweb.Client {
+			client := aurweb.NewClient(httpClient, cfg.AURURL, userAgent, cfg.AURSessionFilePath)
+			client.SetCredentials(
+				os.Getenv("AUR_USERNAME"),
+				os.Getenv("AUR_PASSWORD"))
+
+			return client
+		}}
+	}
 
 	userAgentFn := func(ctx context.Context, req *http.Request) error {
 		req.Header.Set("User-Agent", userAgent)
 		return nil
 	}
 
-	var aurCache aur.QueryClient
-	aurCache, errAURCache := metadata.New(
-		metadata.WithHTTPClient(httpClient),
-		metadata.WithCacheFilePath(filepath.Join(cfg.BuildDir, "aur.json")),
-		metadata.WithRequestEditorFn(userAgentFn),
-		metadata.WithBaseURL(cfg.AURURL),
-		metadata.WithDebugLogger(logger.Debugln),
-	)
-	if errAURCache != nil {
-		return nil, fmt.Errorf(gotext.Get("failed to retrieve aur Cache")+": %w", errAURCache)
-	}
+	rpcClient := run.AURClient
+	if rpcClient == nil {
+		rpcClient = &lazyQueryClient{newFn: func() (aur.QueryClient, error) {
+			return newRPCClient(cfg.AURRPCURL, "rpc", queryHTTPClient, userAgentFn, logger)
+		}}
 
-	aurClient, errAUR := rpc.NewClient(
-		rpc.WithHTTPClient(httpClient),
-		rpc.WithBaseURL(cfg.AURRPCURL),
-		rpc.WithRequestEditorFn(userAgentFn),
-		rpc.WithLogFn(logger.Debugln))
-	if errAUR != nil {
-		return nil, errAUR
+		run.AURClient = rpcClient
+		if !cfg.UseRPC {
+			run.AURClient = &lazyQueryClient{newFn: func() (aur.QueryClient, error) {
+				return newMetadataClient(cfg, queryHTTPClient, userAgentFn, logger)
+			}}
+		}
+
+		if len(cfg.AURBackends) != 0 {
+			rpcClient, run.AURClient, err = withAURBackends(cfg, queryHTTPClient, userAgentFn, logger,
+				rpcClient, run.AURClient)
+			if err != nil {
+				return nil, err
+			}
+		}
 	}
 
-	if cfg.UseRPC {
-		aurCache = aurClient
+	// plain output is meant for logs and CI, where escape codes and redrawn
+	// progress bars only get in the way
+	if cfg.Plain {
+		cfg.Color = "never"
+		cmdArgs.DelArg("color")
 	}
 
-	pacmanConf, useColor, err := retrievePacmanConfig(cmdArgs, cfg.PacmanConf)
+	pacmanConf, useColor, err := retrievePacmanConfig(cmdArgs, cfg.PacmanConf, cfg.Color)
 	if err != nil {
 		return nil, err
 	}
 
+	if cfg.Record != "" {
+		if err := recordDatabases(pacmanConf.DBPath, cfg.Record); err != nil {
+			return nil, err
+		}
+	}
+
+	// pacman gets the configured color setting unless given on the command line
+	if cfg.Color != "" && !cmdArgs.ExistsArg("color") {
+		cmdArgs.CreateOrAppendOption("color", cfg.Color)
+		cmdArgs.Options["color"].Global = true
+	}
+
+	root := "/"
+	if value, _, exists := cmdArgs.GetArg("root", "r"); exists {
+		root = value
+	}
+
+	pacmanOpts, err := retrievePacmanOptions(cfg.PacmanConf, root)
+	if err != nil {
+		logger.Debugln("unable to read pacman options:", err)
+	}
+
 	// FIXME: get rid of global
 	text.UseColor = useColor
+	text.UseCandy = pacmanOpts.ILoveCandy && !cfg.Plain && !cmdArgs.ExistsArg("noprogressbar")
+	text.SetDateFormat(cfg.DateFormat)
 
-	cmdBuilder := exe.NewCmdBuilder(cfg, runner, logger.Child("cmdbuilder"), pacmanConf.DBPath)
+	if cfg.TempDir == "" {
+		tempDir, err := newTempDir(cfg.BuildDir, logger)
+		if err != nil {
+			return nil, err
+		}
 
-	vcsStore := vcs.NewInfoStore(
-		cfg.VCSFilePath, cmdBuilder,
-		logger.Child("vcs"))
+		cfg.TempDir = tempDir
+	}
 
-	if err := vcsStore.Load(); err != nil {
-		return nil, err
+	if run.CmdBuilder == nil {
+		runner := exe.NewOSRunner(logger.Child("runner"))
+		run.CmdBuilder = exe.NewCmdBuilder(cfg, runner, logger.Child("cmdbuilder"), pacmanConf.DBPath)
 	}
 
-	queryBuilder := query.NewSourceQueryBuilder(
-		aurClient,
+	if run.VCSStore == nil {
+		cmdBuilder := run.CmdBuilder
+		run.VCSStore = &lazyVCSStore{
+			newFn: func() *vcs.InfoStore {
+				return vcs.NewInfoStore(cfg.VCSFilePath, cmdBuilder, logger.Child("vcs"))
+			},
+			logger: logger.Child("vcs"),
+		}
+	}
+
+	run.Providers = db.NewProviderChoices(cfg.ProvidersFilePath)
+	if err := run.Providers.Load(); err != nil {
+		logger.Warnln(err)
+	}
+
+	run.Notes = db.NewPackageNotes(cfg.NotesFilePath)
+	if err := run.Notes.Load(); err != nil {
+		logger.Warnln(err)
+	}
+
+	run.AURMisses = download.NewMissCache(cfg.AURMissesFilePath)
+	if err := run.AURMisses.Load(); err != nil {
+		logger.Warnln(err)
+	}
+
+	run.QueryBuilder = query.NewSourceQueryBuilder(
+		rpcClient,
 		logger.Child("mixed.querybuilder"), cfg.SortBy,
 		cfg.Mode, cfg.SearchBy,
 		cfg.BottomUp, cfg.SingleLineResults, cfg.SeparateSources)
 
-	run := &Runtime{
-		Cfg:          cfg,
-		QueryBuilder: queryBuilder,
-		PacmanConf:   pacmanConf,
-		VCSStore:     vcsStore,
-		CmdBuilder:   cmdBuilder,
-		HTTPClient:   &http.Client{},
-		VoteClient:   voteClient,
-		AURClient:    aurCache,
-		Logger:       logger,
+	run.PacmanConf = pacmanConf
+	run.PacmanOpts = pacmanOpts
+
+	if cfg.Timings || logger.Level >= text.LevelVerbose {
+		run.Tracer = timing.NewTracer()
 	}
 
+	emitter, err := newEmitter(cfg.Output, cfg.OutputFD)
+	if err != nil {
+		return nil, err
+	}
+
+	run.Events = emitter
+
 	return run, nil
 }
+
+// newEmitter returns the emitter of the events given by output to the file
+// descriptor fd, nil when only text is printed.
+func newEmitter(output string, fd int) (*events.Emitter, error) {
+	switch output {
+	case "", "text":
+		return nil, nil
+	case "ndjson":
+	default:
+		return nil, errors.New(gotext.Get("invalid value for %s: '%s', expected one of %s",
+			"output", output, "text, ndjson"))
+	}
+
+	file := os.NewFile(uintptr(fd), "events")
+	if file == nil {
+		return nil, errors.New(gotext.Get("file descriptor %d is not open", fd))
+	}
+
+	if _, err := file.Stat(); err != nil {
+		return nil, errors.New(gotext.Get("file descriptor %d is not open", fd))
+	}
+
+	return events.NewEmitter(file), nil
+}
+
+// newRPCClient returns a client of the /rpc endpoint at rpcURL, source names
+// it in debug mode.
+func newRPCClient(rpcURL, source string, httpClient *http.Client,
+	userAgentFn aur.RequestEditorFn, logger *text.Logger,
+) (aur.QueryClient, error) {
+	aurLog := logger.Child("aur")
+
+	rpcClient, err := rpc.NewClient(
+		rpc.WithHTTPClient(httpClient),
+		rpc.WithBaseURL(rpcURL),
+		rpc.WithRequestEditorFn(userAgentFn),
+		rpc.WithLogFn(aurLog.Traceln))
+	if err != nil {
+		return nil, err
+	}
+
+	if aurLog.Debug {
+		return &annotatedClient{next: rpcClient, source: source, log: aurLog}, nil
+	}
+
+	return rpcClient, nil
+}
+
+func newMetadataClient(cfg *settings.Configuration, httpClient *http.Client,
+	userAgentFn aur.RequestEditorFn, logger *text.Logger,
+) (aur.QueryClient, error) {
+	aurLog := logger.Child("aur")
+
+	aurCache, err := metadata.New(
+		metadata.WithHTTPClient(httpClient),
+		metadata.WithCacheFilePath(filepath.Join(cfg.BuildDir, "aur.json")),
+		metadata.WithRequestEditorFn(userAgentFn),
+		metadata.WithBaseURL(cfg.AURURL),
+		metadata.WithDebugLogger(aurLog.Traceln),
+	)
+	if err != nil {
+		return nil, fmt.Errorf(gotext.Get("failed to retrieve aur Cache")+": %w", err)
+	}
+
+	if aurLog.Debug {
+		return &annotatedClient{next: aurCache, source: "metadata cache", log: aurLog}, nil
+	}
+
+	return aurCache, nil
+}
@@ -4,15 +4,23 @@
 package runtime_test
 
 import (
+	"context"
+	"io"
+	"net/http"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/Jguer/aur"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/Jguer/yippee/v12/pkg/runtime"
 	"github.com/Jguer/yippee/v12/pkg/settings"
+	"github.com/Jguer/yippee/v12/pkg/settings/exe"
 	"github.com/Jguer/yippee/v12/pkg/settings/parser"
+	"github.com/Jguer/yippee/v12/pkg/text"
+	"github.com/Jguer/yippee/v12/pkg/vcs"
 )
 
 func TestBuildRuntime(t *testing.T) {
@@ -38,6 +46,7 @@ func TestBuildRuntime(t *testing.T) {
 	// Call the function being tested
 	run, err := runtime.NewRuntime(cfg, cmdArgs, version)
 	require.NoError(t, err)
+	defer run.Cleanup()
 
 	// Assert the function's output
 	assert.NotNil(t, run)
@@ -46,7 +55,50 @@ func TestBuildRuntime(t *testing.T) {
 	assert.NotNil(t, run.VCSStore)
 	assert.NotNil(t, run.CmdBuilder)
 	assert.NotNil(t, run.HTTPClient)
-	assert.NotNil(t, run.VoteClient)
+	assert.NotNil(t, run.AURWebClient())
 	assert.NotNil(t, run.AURClient)
 	assert.NotNil(t, run.Logger)
 }
+
+type fakeAURClient struct{}
+
+func (fakeAURClient) Get(ctx context.Context, query *aur.Query) ([]aur.Pkg, error) {
+	return []aur.Pkg{{Name: "yippee"}}, nil
+}
+
+func TestBuildRuntimeOptions(t *testing.T) {
+	t.Parallel()
+
+	absPath, err := filepath.Abs("../../testdata/pacman.conf")
+	require.NoError(t, err)
+
+	cfg := &settings.Configuration{
+		AURURL:     "https://aur.archlinux.org",
+		AURRPCURL:  "https://aur.archlinux.org/rpc",
+		BuildDir:   t.TempDir(),
+		PacmanConf: absPath,
+	}
+
+	logger := text.NewLogger(io.Discard, io.Discard, strings.NewReader(""), false, "test")
+	httpClient := &http.Client{}
+	aurClient := fakeAURClient{}
+	vcsStore := &vcs.Mock{}
+	cmdBuilder := &exe.MockBuilder{}
+
+	run, err := runtime.NewRuntime(cfg, parser.MakeArguments(), "1.0.0",
+		runtime.WithLogger(logger),
+		runtime.WithHTTPClient(httpClient),
+		runtime.WithAURClient(aurClient),
+		runtime.WithVCSStore(vcsStore),
+		runtime.WithCmdBuilder(cmdBuilder))
+	require.NoError(t, err)
+	defer run.Cleanup()
+
+	assert.Same(t, logger, run.Logger)
+	assert.Same(t, httpClient, run.HTTPClient)
+	assert.Equal(t, aurClient, run.AURClient)
+	assert.Same(t, vcsStore, run.VCSStore)
+	assert.Same(t, cmdBuilder, run.CmdBuilder)
+	assert.NotNil(t, run.AURWebClient())
+	assert.NotNil(t, run.QueryBuilder)
+}
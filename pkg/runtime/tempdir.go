@@ -0,0 +1,97 @@
+package runtime
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/Jguer/yippee/v12/pkg/text"
+)
+
+const tempDirPrefix = ".tmp-"
+
+// newTempDir creates the temporary directory of this run, .tmp-<pid>-<random>
+// in buildDir, and removes the ones left behind by runs no longer alive. The
+// system temporary directory is used when buildDir does not exist yet. The
+// random suffix makes sure nobody else prepared the directory, makepkg sources
+// the makepkg.conf kept in it.
+func newTempDir(buildDir string, logger *text.Logger) (string, error) {
+	parent := buildDir
+	if info, err := os.Stat(buildDir); err != nil || !info.IsDir() {
+		parent = os.TempDir()
+	} else {
+		removeStaleTempDirs(buildDir, logger)
+	}
+
+	dir, err := os.MkdirTemp(parent, tempDirPrefix+strconv.Itoa(os.Getpid())+"-*")
+	if err != nil {
+		return "", err
+	}
+
+	// the build user reads the files when makepkg does not run as us
+	if err := os.Chmod(dir, 0o755); err != nil {
+		os.Remove(dir)
+		return "", err
+	}
+
+	return dir, nil
+}
+
+// removeStaleTempDirs removes the temporary directories in parent whose run
+// crashed or was killed before cleaning up.
+func removeStaleTempDirs(parent string, logger *text.Logger) {
+	entries, err := os.ReadDir(parent)
+	if err != nil {
+		return
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() || !IsTempDir(entry.Name()) {
+			continue
+		}
+
+		pidStr, _, _ := strings.Cut(strings.TrimPrefix(entry.Name(), tempDirPrefix), "-")
+
+		pid, err := strconv.Atoi(pidStr)
+		if err != nil {
+			continue
+		}
+
+		if pid <= 0 || pid == os.Getpid() || processAlive(pid) {
+			continue
+		}
+
+		if err := os.RemoveAll(filepath.Join(parent, entry.Name())); err != nil {
+			logger.Debugln("unable to remove stale temporary directory:", err)
+		}
+	}
+}
+
+// IsTempDir reports whether name, an entry of the build directory, is the
+// temporary directory of a run rather than a package.
+func IsTempDir(name string) bool {
+	return strings.HasPrefix(name, tempDirPrefix)
+}
+
+func processAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// Cleanup removes the temporary directory of the run and closes its log
+// files.
+func (r *Runtime) Cleanup() {
+	closeLogFiles(r.logFiles)
+
+	if r.Cfg.TempDir == "" {
+		return
+	}
+
+	if err := os.RemoveAll(r.Cfg.TempDir); err != nil {
+		r.Logger.Debugln("unable to remove temporary directory:", err)
+	}
+}
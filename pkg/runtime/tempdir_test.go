@@ -0,0 +1,85 @@
+//go:build !integration
+// +build !integration
+
+package runtime
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jguer/yippee/v12/pkg/settings"
+	"github.com/Jguer/yippee/v12/pkg/text"
+)
+
+func TestNewTempDir(t *testing.T) {
+	t.Parallel()
+
+	buildDir := t.TempDir()
+	logger := text.NewLogger(io.Discard, io.Discard, strings.NewReader(""), false, "test")
+
+	// pid 1 is always alive, the largest pid can not be
+	alive := filepath.Join(buildDir, tempDirPrefix+"1-123")
+	stale := filepath.Join(buildDir, tempDirPrefix+strconv.Itoa(1<<22+1)+"-123")
+	staleUnsuffixed := filepath.Join(buildDir, tempDirPrefix+strconv.Itoa(1<<22+1))
+	unrelated := filepath.Join(buildDir, ".tmp-notapid")
+
+	for _, dir := range []string{alive, stale, staleUnsuffixed, unrelated} {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "sub"), 0o755))
+	}
+
+	dir, err := newTempDir(buildDir, logger)
+	require.NoError(t, err)
+
+	assert.Equal(t, buildDir, filepath.Dir(dir))
+	assert.True(t, strings.HasPrefix(filepath.Base(dir), tempDirPrefix+strconv.Itoa(os.Getpid())+"-"))
+	assert.DirExists(t, dir)
+	assert.DirExists(t, alive)
+	assert.NoDirExists(t, stale)
+	assert.NoDirExists(t, staleUnsuffixed)
+	assert.DirExists(t, unrelated)
+
+	run := &Runtime{Cfg: &settings.Configuration{TempDir: dir}, Logger: logger}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "makepkg.conf"), []byte(""), 0o644))
+	run.Cleanup()
+
+	assert.NoDirExists(t, dir)
+}
+
+func TestNewTempDirMissingBuildDir(t *testing.T) {
+	t.Parallel()
+
+	logger := text.NewLogger(io.Discard, io.Discard, strings.NewReader(""), false, "test")
+
+	dir, err := newTempDir(filepath.Join(t.TempDir(), "missing"), logger)
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	assert.Equal(t, os.TempDir(), filepath.Dir(dir))
+}
+
+func TestNewTempDirUnique(t *testing.T) {
+	t.Parallel()
+
+	buildDir := t.TempDir()
+	logger := text.NewLogger(io.Discard, io.Discard, strings.NewReader(""), false, "test")
+
+	// a directory prepared by someone else under the name of this run
+	prepared := filepath.Join(buildDir, tempDirPrefix+strconv.Itoa(os.Getpid()))
+	require.NoError(t, os.Mkdir(prepared, 0o777))
+
+	dir, err := newTempDir(buildDir, logger)
+	require.NoError(t, err)
+
+	assert.NotEqual(t, prepared, dir)
+
+	info, err := os.Stat(dir)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o755), info.Mode().Perm())
+}
@@ -0,0 +1,78 @@
+package runtime
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/Jguer/aur"
+
+	"github.com/Jguer/yippee/v12/pkg/settings/parser"
+	"github.com/Jguer/yippee/v12/pkg/text"
+)
+
+// verbosity maps -q, -v and -vv to a logger level.
+func verbosity(cmdArgs *parser.Arguments) text.Level {
+	switch {
+	case cmdArgs.ExistsDouble("v", "verbose"):
+		return text.LevelTrace
+	case cmdArgs.ExistsArg("v", "verbose"):
+		return text.LevelVerbose
+	case cmdArgs.ExistsArg("q", "quiet"):
+		return text.LevelQuiet
+	}
+
+	return text.LevelNormal
+}
+
+// tracingTransport logs every HTTP request with its outcome and duration.
+type tracingTransport struct {
+	next http.RoundTripper
+	log  *text.Logger
+}
+
+func (t *tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	elapsed := time.Since(start).Round(time.Millisecond)
+
+	if err != nil {
+		t.log.Traceln(req.Method, req.URL.Redacted(), "failed after", elapsed, err)
+		return nil, err
+	}
+
+	t.log.Traceln(req.Method, req.URL.Redacted(), resp.Status, elapsed)
+
+	return resp, nil
+}
+
+// annotatedClient reports in debug mode which source served the metadata of
+// each AUR package and how long the request took. Searches are summarized
+// instead of listing every result.
+type annotatedClient struct {
+	next   aur.QueryClient
+	source string
+	log    *text.Logger
+}
+
+func (c *annotatedClient) Get(ctx context.Context, query *aur.Query) ([]aur.Pkg, error) {
+	start := time.Now()
+	pkgs, err := c.next.Get(ctx, query)
+	elapsed := time.Since(start).Round(time.Millisecond)
+
+	if err != nil {
+		c.log.Debugln("aur query from", c.source, "failed after", elapsed, err)
+		return pkgs, err
+	}
+
+	if query.By != aur.Name {
+		c.log.Debugln("aur search from", c.source, "returned", len(pkgs), "packages in", elapsed)
+		return pkgs, nil
+	}
+
+	for i := range pkgs {
+		c.log.Debugln(pkgs[i].Name+":", "from", c.source, "in", elapsed)
+	}
+
+	return pkgs, nil
+}
@@ -0,0 +1,97 @@
+//go:build !integration
+// +build !integration
+
+package runtime
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Jguer/aur"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jguer/yippee/v12/pkg/settings/parser"
+	"github.com/Jguer/yippee/v12/pkg/text"
+)
+
+func TestVerbosity(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		args []string
+		want text.Level
+	}{
+		{args: []string{}, want: text.LevelNormal},
+		{args: []string{"q"}, want: text.LevelQuiet},
+		{args: []string{"v"}, want: text.LevelVerbose},
+		{args: []string{"v", "v"}, want: text.LevelTrace},
+		{args: []string{"q", "v"}, want: text.LevelVerbose},
+	}
+
+	for _, tc := range testCases {
+		cmdArgs := parser.MakeArguments()
+		require.NoError(t, cmdArgs.AddArg(tc.args...))
+		assert.Equal(t, tc.want, verbosity(cmdArgs), tc.args)
+	}
+}
+
+func TestTracingTransport(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer server.Close()
+
+	var stderr strings.Builder
+
+	logger := text.NewLogger(io.Discard, &stderr, strings.NewReader(""), false, "test")
+	logger.Level = text.LevelTrace
+
+	client := &http.Client{Transport: &tracingTransport{next: http.DefaultTransport, log: logger}}
+
+	resp, err := client.Get(server.URL + "/rpc?v=5")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Contains(t, stderr.String(), "GET "+server.URL+"/rpc?v=5 418 I'm a teapot")
+}
+
+type fakeQueryClient struct {
+	pkgs []aur.Pkg
+}
+
+func (c fakeQueryClient) Get(ctx context.Context, query *aur.Query) ([]aur.Pkg, error) {
+	return c.pkgs, nil
+}
+
+func TestAnnotatedClient(t *testing.T) {
+	t.Parallel()
+
+	var stdout strings.Builder
+
+	logger := text.NewLogger(&stdout, io.Discard, strings.NewReader(""), true, "test")
+	client := &annotatedClient{
+		next:   fakeQueryClient{pkgs: []aur.Pkg{{Name: "yippee"}, {Name: "yippee-bin"}}},
+		source: "rpc",
+		log:    logger,
+	}
+
+	pkgs, err := client.Get(context.Background(), &aur.Query{Needles: []string{"yippee", "yippee-bin"}, By: aur.Name})
+	require.NoError(t, err)
+	assert.Len(t, pkgs, 2)
+	assert.Contains(t, stdout.String(), "yippee: from rpc in")
+	assert.Contains(t, stdout.String(), "yippee-bin: from rpc in")
+
+	stdout.Reset()
+
+	_, err = client.Get(context.Background(), &aur.Query{Needles: []string{"yippee"}, By: aur.NameDesc})
+	require.NoError(t, err)
+	assert.Contains(t, stdout.String(), "aur search from rpc returned 2 packages in")
+	assert.NotContains(t, stdout.String(), "yippee: from")
+}
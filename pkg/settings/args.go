@@ -1,12 +1,40 @@
 package settings
 
 import (
+	"errors"
 	"strconv"
 	"strings"
 
+	"github.com/leonelquinteros/gotext"
+
 	"github.com/Jguer/yippee/v12/pkg/settings/parser"
 )
 
+// defaultOps are the arguments of the operations yippee can run when only
+// targets or nothing at all are given, by config value. An empty one refuses
+// to guess.
+var (
+	defaultOps = map[string]string{
+		"sysupgrade": "-Syu",
+		"upgrades":   "-Qu",
+		"help":       "",
+	}
+	defaultTargetsOps = map[string]string{
+		"menu":    "-Y",
+		"search":  "-Ss",
+		"install": "-S",
+		"help":    "",
+	}
+)
+
+// rootBuilds are the strategies exe can build as root with.
+var rootBuilds = map[string]bool{
+	"auto":    true,
+	"systemd": true,
+	"nobody":  true,
+	"error":   true,
+}
+
 func (c *Configuration) ParseCommandLine(a *parser.Arguments) error {
 	if err := a.Parse(); err != nil {
 		return err
@@ -14,9 +42,59 @@ func (c *Configuration) ParseCommandLine(a *parser.Arguments) error {
 
 	c.extractYippeeOptions(a)
 
+	if err := c.validateRootBuild(); err != nil {
+		return err
+	}
+
+	return c.applyDefaultOp(a)
+}
+
+// validateRootBuild refuses a RootBuild exe does not know rather than
+// silently building with the automatic strategy.
+func (c *Configuration) validateRootBuild() error {
+	if !rootBuilds[c.RootBuild] {
+		return errors.New(gotext.Get("invalid value for %s: '%s', expected one of %s",
+			"rootbuild", c.RootBuild, "auto, systemd, nobody, error"))
+	}
+
 	return nil
 }
 
+// applyDefaultOp gives a the operation set by DefaultOp or DefaultTargetsOp
+// when none was given.
+func (c *Configuration) applyDefaultOp(a *parser.Arguments) error {
+	args, ok := defaultOps[c.DefaultOp]
+	if !ok {
+		return errors.New(gotext.Get("invalid value for %s: '%s', expected one of %s",
+			"defaultop", c.DefaultOp, "sysupgrade, upgrades, help"))
+	}
+
+	targetsArgs, ok := defaultTargetsOps[c.DefaultTargetsOp]
+	if !ok {
+		return errors.New(gotext.Get("invalid value for %s: '%s', expected one of %s",
+			"defaulttargetsop", c.DefaultTargetsOp, "menu, search, install, help"))
+	}
+
+	if a.Op != "" {
+		return nil
+	}
+
+	if err := a.ApplyDefaultOp(args, targetsArgs); err != nil {
+		return err
+	}
+
+	switch {
+	case a.Op != "":
+		return nil
+	case a.ExistsArg("h", "help"):
+		// the usage is what was asked for
+		a.Op = "Y"
+		return nil
+	}
+
+	return ErrNoOperation{}
+}
+
 func (c *Configuration) extractYippeeOptions(a *parser.Arguments) {
 	for option, value := range a.Options {
 		if c.handleOption(option, value.First()) {
@@ -24,21 +102,32 @@ func (c *Configuration) extractYippeeOptions(a *parser.Arguments) {
 		}
 	}
 
-	c.AURURL = strings.TrimRight(c.AURURL, "/")
+	c.AURURL, c.AURRPCURL = normalizeAURURLs(c.AURURL, c.AURRPCURL)
 
-	// if AurRPCURL is set, use that for /rpc calls
-	if c.AURRPCURL == "" {
-		c.AURRPCURL = c.AURURL + "/rpc?"
-		return
+	for i := range c.AURBackends {
+		backend := &c.AURBackends[i]
+		backend.URL, backend.RPCURL = normalizeAURURLs(backend.URL, backend.RPCURL)
 	}
+}
 
-	if !strings.HasSuffix(c.AURRPCURL, "?") {
-		if strings.HasSuffix(c.AURRPCURL, "/rpc") {
-			c.AURRPCURL += "?"
+// normalizeAURURLs trims aurURL and points rpcURL at the /rpc endpoint,
+// the one of aurURL unless rpcURL is set.
+func normalizeAURURLs(aurURL, rpcURL string) (web, rpc string) {
+	aurURL = strings.TrimRight(aurURL, "/")
+
+	if rpcURL == "" {
+		return aurURL, aurURL + "/rpc?"
+	}
+
+	if !strings.HasSuffix(rpcURL, "?") {
+		if strings.HasSuffix(rpcURL, "/rpc") {
+			rpcURL += "?"
 		} else {
-			c.AURRPCURL = strings.TrimRight(c.AURRPCURL, "/") + "/rpc?"
+			rpcURL = strings.TrimRight(rpcURL, "/") + "/rpc?"
 		}
 	}
+
+	return aurURL, rpcURL
 }
 
 func (c *Configuration) handleOption(option, value string) bool {
@@ -52,6 +141,12 @@ func (c *Configuration) handleOption(option, value string) bool {
 		c.AURURL = value
 	case "aurrpcurl":
 		c.AURRPCURL = value
+	case "proxy":
+		c.Proxy = value
+	case "noproxy":
+		c.NoProxy = value
+	case "cabundle":
+		c.CABundle = value
 	case "save":
 		c.SaveConfig = boolValue
 	case "afterclean", "cleanafter":
@@ -63,8 +158,22 @@ func (c *Configuration) handleOption(option, value string) bool {
 		return !boolValue
 	case "devel":
 		c.Devel = boolValue
+	case "devellog":
+		c.DevelLog = boolValue
+	case "devel-strict":
+		c.DevelStrict = boolValue
 	case "timeupdate":
 		c.TimeUpdate = boolValue
+	case "selfupgradefirst":
+		c.SelfUpgradeFirst = boolValue
+	case "noselfupgradefirst":
+		c.SelfUpgradeFirst = false
+	case "ignorerepo":
+		c.IgnoreRepo = value
+	case "newsfeeds":
+		c.NewsFeeds = value
+	case "newsonupgrade":
+		c.NewsOnUpgrade = value
 	case "topdown":
 		c.BottomUp = false
 	case "bottomup":
@@ -78,10 +187,19 @@ func (c *Configuration) handleOption(option, value string) bool {
 		if err == nil {
 			c.CompletionInterval = n
 		}
+	case "dbmaxage":
+		n, err := strconv.Atoi(value)
+		if err == nil && n >= 0 {
+			c.DBMaxAge = n
+		}
 	case "sortby":
 		c.SortBy = value
 	case "searchby":
 		c.SearchBy = value
+	case "defaultop":
+		c.DefaultOp = value
+	case "defaulttargetsop":
+		c.DefaultTargetsOp = value
 	case "noconfirm":
 		NoConfirm = boolValue
 	case "config":
@@ -124,6 +242,14 @@ func (c *Configuration) handleOption(option, value string) bool {
 		c.MFlags = value
 	case "gitflags":
 		c.GitFlags = value
+	case "pacmansyncflags":
+		c.PacmanSyncFlags = value
+	case "pacmanupgradeflags":
+		c.PacmanUpgradeFlags = value
+	case "pacmandatabaseflags":
+		c.PacmanDatabaseFlags = value
+	case "pacmanremoveflags":
+		c.PacmanRemoveFlags = value
 	case "builddir":
 		c.BuildDir = value
 	case "editor":
@@ -136,6 +262,20 @@ func (c *Configuration) handleOption(option, value string) bool {
 		c.MakepkgConf = value
 	case "nomakepkgconf":
 		c.MakepkgConf = ""
+	case "makepkgconfextra":
+		c.MakepkgConfExtra = value
+	case "nomakepkgconfextra":
+		c.MakepkgConfExtra = ""
+	case "no-debug":
+		c.NoDebug = boolValue
+	case "keep-debug":
+		c.NoDebug = false
+	case "builduser":
+		c.BuildUser = value
+	case "nobuilduser":
+		c.BuildUser = ""
+	case "rootbuild":
+		c.RootBuild = value
 	case "pacman":
 		c.PacmanBin = value
 	case "git":
@@ -153,10 +293,100 @@ func (c *Configuration) handleOption(option, value string) bool {
 		}
 	case "sudoloop":
 		c.SudoLoop = boolValue
+	case "throttlebuilds":
+		c.ThrottleBuilds = boolValue
+	case "nothrottlebuilds":
+		c.ThrottleBuilds = false
+	case "memorylimit":
+		n, err := strconv.Atoi(value)
+		if err == nil && n > 0 {
+			c.MemoryLimit = n
+		}
+	case "maxconcurrentdownloads":
+		n, err := strconv.Atoi(value)
+		if err == nil && n >= 0 {
+			c.MaxConcurrentDownloads = n
+		}
+	case "downloadratelimit":
+		c.DownloadRateLimit = value
+	case "pkgbuildfetch":
+		c.PKGBUILDFetch = value
+	case "pkgbuildclone":
+		c.PKGBUILDClone = value
+	case "shallowclone":
+		c.ShallowClone = boolValue
+	case "noshallowclone":
+		c.ShallowClone = false
+	case "gcinterval":
+		n, err := strconv.Atoi(value)
+		if err == nil && n >= 0 {
+			c.GCInterval = n
+		}
+	case "worktrees":
+		c.Worktrees = boolValue
+	case "noworktrees":
+		c.Worktrees = false
+	case "versionedbuilddirs":
+		c.VersionedBuildDirs = boolValue
+	case "noversionedbuilddirs":
+		c.VersionedBuildDirs = false
+	case "keepversions":
+		n, err := strconv.Atoi(value)
+		if err == nil && n > 0 {
+			c.KeepVersions = n
+		}
+	case "keepversionsdays":
+		n, err := strconv.Atoi(value)
+		if err == nil && n >= 0 {
+			c.KeepVersionsDays = n
+		}
+	case "editfiles":
+		c.EditFiles = value
+	case "confirmupfront":
+		c.ConfirmUpfront = boolValue
+	case "noconfirmupfront":
+		c.ConfirmUpfront = false
+	case "color":
+		// also passed on to pacman
+		c.Color = value
+
+		return false
+	case "dateformat":
+		c.DateFormat = value
+	case "plain":
+		c.Plain = boolValue
+	case "noplain":
+		c.Plain = false
+	case "verbosepkglists":
+		c.VerbosePkgLists = boolValue
+	case "noverbosepkglists":
+		c.VerbosePkgLists = false
+	case "loglevels":
+		c.LogLevels = value
+	case "nologlevels":
+		c.LogLevels = ""
+	case "timings":
+		c.Timings = boolValue
+	case "notimings":
+		c.Timings = false
 	case "provides":
 		c.Provides = boolValue
+	case "providerpolicy":
+		c.ProviderPolicy = value
 	case "pgpfetch":
 		c.PGPFetch = boolValue
+	case "strictchecksums":
+		c.StrictChecksums = boolValue
+	case "nostrictchecksums":
+		c.StrictChecksums = false
+	case "inspectors":
+		c.Inspectors = value
+	case "noinspectors":
+		c.Inspectors = ""
+	case "inspectblock":
+		c.InspectBlock = boolValue
+	case "noinspectblock":
+		c.InspectBlock = false
 	case "cleanmenu":
 		c.CleanMenu = boolValue
 	case "diffmenu":
@@ -171,8 +401,26 @@ func (c *Configuration) handleOption(option, value string) bool {
 		c.Mode = parser.ModeAUR
 	case "repo":
 		c.Mode = parser.ModeRepo
+	case "json":
+		c.JSON = boolValue
+	case "record":
+		c.Record = value
+	case "replay":
+		c.Replay = value
+	case "dry-run":
+		c.DryRun = boolValue
+	case "output":
+		c.Output = value
+	case "outputfd":
+		n, err := strconv.Atoi(value)
+		if err == nil && n >= 0 {
+			c.OutputFD = n
+		}
 	case "removemake":
 		c.RemoveMake = "yes"
+		if value != "" {
+			c.RemoveMake = value
+		}
 	case "noremovemake":
 		c.RemoveMake = "no"
 	case "askremovemake":
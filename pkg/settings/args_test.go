@@ -0,0 +1,141 @@
+//go:build !integration
+// +build !integration
+
+package settings
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Jguer/yippee/v12/pkg/settings/parser"
+)
+
+func TestConfiguration_applyDefaultOp(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		defaultOp string
+		help      bool
+		wantOp    string
+		wantErr   bool
+		wantNoOp  bool
+	}{
+		{name: "sysupgrade", defaultOp: "sysupgrade", wantOp: "S"},
+		{name: "upgrades", defaultOp: "upgrades", wantOp: "Q"},
+		{name: "refuse", defaultOp: "help", wantNoOp: true},
+		{name: "refuse asking for help", defaultOp: "help", help: true, wantOp: "Y"},
+		{name: "invalid", defaultOp: "-Syu", wantErr: true},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			c := DefaultConfig("v1.0.0")
+			c.DefaultOp = tc.defaultOp
+
+			a := parser.MakeArguments()
+			if tc.help {
+				assert.NoError(t, a.AddArg("h"))
+			}
+
+			err := c.applyDefaultOp(a)
+			switch {
+			case tc.wantNoOp:
+				assert.ErrorIs(t, err, ErrNoOperation{})
+			case tc.wantErr:
+				assert.Error(t, err)
+				assert.NotErrorIs(t, err, ErrNoOperation{})
+			default:
+				assert.NoError(t, err)
+				assert.Equal(t, tc.wantOp, a.Op)
+			}
+		})
+	}
+}
+
+func TestConfiguration_applyDefaultOpInvalidTargetsOp(t *testing.T) {
+	t.Parallel()
+	c := DefaultConfig("v1.0.0")
+	c.DefaultTargetsOp = "sync"
+
+	a := parser.MakeArguments()
+	a.Op = "S"
+
+	assert.Error(t, c.applyDefaultOp(a))
+}
+
+func TestConfiguration_validateRootBuild(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		rootBuild string
+		wantErr   bool
+	}{
+		{rootBuild: "auto"},
+		{rootBuild: "systemd"},
+		{rootBuild: "nobody"},
+		{rootBuild: "error"},
+		{rootBuild: "sytemd", wantErr: true},
+		{rootBuild: "", wantErr: true},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.rootBuild, func(t *testing.T) {
+			t.Parallel()
+			c := DefaultConfig("v1.0.0")
+			assert.True(t, c.handleOption("rootbuild", tc.rootBuild))
+
+			err := c.validateRootBuild()
+			if tc.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestConfiguration_handleOptionBoolValue(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		option string
+		get    func(c *Configuration) bool
+	}{
+		{option: "no-debug", get: func(c *Configuration) bool { return c.NoDebug }},
+		{option: "shallowclone", get: func(c *Configuration) bool { return c.ShallowClone }},
+		{option: "worktrees", get: func(c *Configuration) bool { return c.Worktrees }},
+		{option: "versionedbuilddirs", get: func(c *Configuration) bool { return c.VersionedBuildDirs }},
+		{option: "confirmupfront", get: func(c *Configuration) bool { return c.ConfirmUpfront }},
+		{option: "plain", get: func(c *Configuration) bool { return c.Plain }},
+		{option: "verbosepkglists", get: func(c *Configuration) bool { return c.VerbosePkgLists }},
+		{option: "timings", get: func(c *Configuration) bool { return c.Timings }},
+		{option: "strictchecksums", get: func(c *Configuration) bool { return c.StrictChecksums }},
+		{option: "inspectblock", get: func(c *Configuration) bool { return c.InspectBlock }},
+		{option: "json", get: func(c *Configuration) bool { return c.JSON }},
+		{option: "dry-run", get: func(c *Configuration) bool { return c.DryRun }},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.option, func(t *testing.T) {
+			t.Parallel()
+			c := DefaultConfig("v1.0.0")
+
+			assert.True(t, c.handleOption(tc.option, ""))
+			assert.True(t, tc.get(c))
+
+			assert.True(t, c.handleOption(tc.option, "false"))
+			assert.False(t, tc.get(c))
+		})
+	}
+}
+
+func TestConfiguration_handleOptionMakepkgConfExtra(t *testing.T) {
+	t.Parallel()
+	c := DefaultConfig("v1.0.0")
+
+	assert.True(t, c.handleOption("makepkgconfextra", "/etc/yippee/makepkg.conf"))
+	assert.Equal(t, "/etc/yippee/makepkg.conf", c.MakepkgConfExtra)
+
+	assert.True(t, c.handleOption("nomakepkgconfextra", ""))
+	assert.Empty(t, c.MakepkgConfExtra)
+}
@@ -21,17 +21,41 @@ var HideMenus = false
 // NoConfirm indicates if user input should be skipped.
 var NoConfirm = false
 
+// AURBackend is an AUR compatible endpoint queried along with the AUR, e.g.
+// a private aurweb instance.
+type AURBackend struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	RPCURL string `json:"rpcurl,omitempty"`
+	// Auth is sent as the Authorization header of the RPC requests.
+	Auth string `json:"auth,omitempty"`
+	// Priority orders the backends, higher first. The AUR has priority 0.
+	Priority int `json:"priority"`
+}
+
 // Configuration stores yippee's config.
 type Configuration struct {
 	AURURL                 string `json:"aururl"`
 	AURRPCURL              string `json:"aurrpcurl"`
+	Proxy                  string `json:"proxy"`
+	NoProxy                string `json:"noproxy"`
+	CABundle               string `json:"cabundle"`
 	BuildDir               string `json:"buildDir"`
 	Editor                 string `json:"editor"`
 	EditorFlags            string `json:"editorflags"`
+	EditFiles              string `json:"editfiles"`
 	MakepkgBin             string `json:"makepkgbin"`
 	MakepkgConf            string `json:"makepkgconf"`
+	MakepkgConfExtra       string `json:"makepkgconfextra"`
+	BuildUser              string `json:"builduser"`
+	RootBuild              string `json:"rootbuild"`
 	PacmanBin              string `json:"pacmanbin"`
 	PacmanConf             string `json:"pacmanconf"`
+	Color                  string `json:"color"`
+	DateFormat             string `json:"dateformat"`
+	Plain                  bool   `json:"plain"`
+	VerbosePkgLists        bool   `json:"verbosepkglists"`
+	LogLevels              string `json:"loglevels"`
 	ReDownload             string `json:"redownload"`
 	AnswerClean            string `json:"answerclean"`
 	AnswerDiff             string `json:"answerdiff"`
@@ -43,22 +67,52 @@ type Configuration struct {
 	MFlags                 string `json:"mflags"`
 	SortBy                 string `json:"sortby"`
 	SearchBy               string `json:"searchby"`
+	DefaultOp              string `json:"defaultop"`
+	DefaultTargetsOp       string `json:"defaulttargetsop"`
 	GitFlags               string `json:"gitflags"`
+	PacmanSyncFlags        string `json:"pacmansyncflags"`
+	PacmanUpgradeFlags     string `json:"pacmanupgradeflags"`
+	PacmanDatabaseFlags    string `json:"pacmandatabaseflags"`
+	PacmanRemoveFlags      string `json:"pacmanremoveflags"`
 	RemoveMake             string `json:"removemake"`
+	IgnoreRepo             string `json:"ignorerepo"`
+	NewsFeeds              string `json:"newsfeeds"`
+	NewsOnUpgrade          string `json:"newsonupgrade"`
 	SudoBin                string `json:"sudobin"`
 	SudoFlags              string `json:"sudoflags"`
 	Version                string `json:"version"`
 	RequestSplitN          int    `json:"requestsplitn"`
 	CompletionInterval     int    `json:"completionrefreshtime"`
+	DBMaxAge               int    `json:"dbmaxage"`
 	MaxConcurrentDownloads int    `json:"maxconcurrentdownloads"`
+	DownloadRateLimit      string `json:"downloadratelimit"`
+	MemoryLimit            int    `json:"memorylimit"`
+	ShallowClone           bool   `json:"shallowclone"`
+	PKGBUILDFetch          string `json:"pkgbuildfetch"`
+	PKGBUILDClone          string `json:"pkgbuildclone"`
+	GCInterval             int    `json:"gcinterval"`
+	Worktrees              bool   `json:"worktrees"`
+	VersionedBuildDirs     bool   `json:"versionedbuilddirs"`
+	KeepVersions           int    `json:"keepversions"`
+	KeepVersionsDays       int    `json:"keepversionsdays"`
+	ConfirmUpfront         bool   `json:"confirmupfront"`
+	Timings                bool   `json:"timings"`
 	BottomUp               bool   `json:"bottomup"`
 	SudoLoop               bool   `json:"sudoloop"`
 	TimeUpdate             bool   `json:"timeupdate"`
+	SelfUpgradeFirst       bool   `json:"selfupgradefirst"`
 	Devel                  bool   `json:"devel"`
+	DevelLog               bool   `json:"devellog"`
+	DevelStrict            bool   `json:"develstrict"`
 	CleanAfter             bool   `json:"cleanAfter"`
 	KeepSrc                bool   `json:"keepSrc"`
 	Provides               bool   `json:"provides"`
+	ProviderPolicy         string `json:"providerpolicy"`
 	PGPFetch               bool   `json:"pgpfetch"`
+	StrictChecksums        bool   `json:"strictchecksums"`
+	Inspectors             string `json:"inspectors"`
+	InspectBlock           bool   `json:"inspectblock"`
+	NoDebug                bool   `json:"nodebug"`
 	CleanMenu              bool   `json:"cleanmenu"`
 	DiffMenu               bool   `json:"diffmenu"`
 	EditMenu               bool   `json:"editmenu"`
@@ -70,12 +124,32 @@ type Configuration struct {
 	Debug                  bool   `json:"debug"`
 	UseRPC                 bool   `json:"rpc"`
 	DoubleConfirm          bool   `json:"doubleconfirm"` // confirm install before and after build
-
-	CompletionPath string `json:"-"`
-	VCSFilePath    string `json:"-"`
+	ThrottleBuilds         bool   `json:"throttlebuilds"`
+
+	AURBackends []AURBackend `json:"aurbackends,omitempty"`
+
+	CompletionPath     string `json:"-"`
+	VCSFilePath        string `json:"-"`
+	ProvidersFilePath  string `json:"-"`
+	RemoveMakeFilePath string `json:"-"`
+	AURMissesFilePath  string `json:"-"`
+	NewsStateFilePath  string `json:"-"`
+	SysupgradeFilePath string `json:"-"`
+	AURSessionFilePath string `json:"-"`
+	NotesFilePath      string `json:"-"`
+	DirSizesFilePath   string `json:"-"`
+	BuildStatsFilePath string `json:"-"`
+	// TempDir holds the files only needed during this run, removed on exit.
+	TempDir string `json:"-"`
 	// ConfigPath     string `json:"-"`
 	SaveConfig bool               `json:"-"`
 	Mode       parser.TargetMode  `json:"-"`
+	JSON       bool               `json:"-"`
+	Record     string             `json:"-"`
+	Replay     string             `json:"-"`
+	DryRun     bool               `json:"-"`
+	Output     string             `json:"-"`
+	OutputFD   int                `json:"-"`
 	ReBuild    parser.RebuildMode `json:"rebuild"`
 }
 
@@ -113,16 +187,31 @@ func (c *Configuration) Save(configPath, version string) error {
 func (c *Configuration) expandEnv() {
 	c.AURURL = os.ExpandEnv(c.AURURL)
 	c.AURRPCURL = os.ExpandEnv(c.AURRPCURL)
+
+	for i := range c.AURBackends {
+		c.AURBackends[i].URL = os.ExpandEnv(c.AURBackends[i].URL)
+		c.AURBackends[i].RPCURL = os.ExpandEnv(c.AURBackends[i].RPCURL)
+		c.AURBackends[i].Auth = os.ExpandEnv(c.AURBackends[i].Auth)
+	}
+
+	c.Proxy = os.ExpandEnv(c.Proxy)
+	c.NoProxy = os.ExpandEnv(c.NoProxy)
+	c.CABundle = expandEnvOrHome(c.CABundle)
 	c.BuildDir = expandEnvOrHome(c.BuildDir)
 	c.Editor = expandEnvOrHome(c.Editor)
 	c.EditorFlags = os.ExpandEnv(c.EditorFlags)
 	c.MakepkgBin = expandEnvOrHome(c.MakepkgBin)
 	c.MakepkgConf = expandEnvOrHome(c.MakepkgConf)
+	c.MakepkgConfExtra = expandEnvOrHome(c.MakepkgConfExtra)
 	c.PacmanBin = expandEnvOrHome(c.PacmanBin)
 	c.PacmanConf = expandEnvOrHome(c.PacmanConf)
 	c.GpgFlags = os.ExpandEnv(c.GpgFlags)
 	c.MFlags = os.ExpandEnv(c.MFlags)
 	c.GitFlags = os.ExpandEnv(c.GitFlags)
+	c.PacmanSyncFlags = os.ExpandEnv(c.PacmanSyncFlags)
+	c.PacmanUpgradeFlags = os.ExpandEnv(c.PacmanUpgradeFlags)
+	c.PacmanDatabaseFlags = os.ExpandEnv(c.PacmanDatabaseFlags)
+	c.PacmanRemoveFlags = os.ExpandEnv(c.PacmanRemoveFlags)
 	c.SortBy = os.ExpandEnv(c.SortBy)
 	c.SearchBy = os.ExpandEnv(c.SearchBy)
 	c.GitBin = expandEnvOrHome(c.GitBin)
@@ -136,6 +225,34 @@ func (c *Configuration) expandEnv() {
 	c.AnswerEdit = os.ExpandEnv(c.AnswerEdit)
 	c.AnswerUpgrade = os.ExpandEnv(c.AnswerUpgrade)
 	c.RemoveMake = os.ExpandEnv(c.RemoveMake)
+	c.IgnoreRepo = os.ExpandEnv(c.IgnoreRepo)
+	c.NewsFeeds = os.ExpandEnv(c.NewsFeeds)
+	c.Inspectors = os.ExpandEnv(c.Inspectors)
+	c.LogLevels = os.ExpandEnv(c.LogLevels)
+}
+
+// IgnoredRepos returns the repositories excluded from sysupgrade, IgnoreRepo
+// is a comma or space separated list.
+func (c *Configuration) IgnoredRepos() []string {
+	return strings.FieldsFunc(c.IgnoreRepo, func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+}
+
+// NewsFeedURLs returns the RSS and Atom feeds printed by -Pw, NewsFeeds is a
+// comma or space separated list.
+func (c *Configuration) NewsFeedURLs() []string {
+	return strings.FieldsFunc(c.NewsFeeds, func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+}
+
+// InspectorNames returns the inspectors run on built packages, Inspectors is
+// a comma or space separated list.
+func (c *Configuration) InspectorNames() []string {
+	return strings.FieldsFunc(c.Inspectors, func(r rune) bool {
+		return r == ',' || r == ' '
+	})
 }
 
 func expandEnvOrHome(path string) string {
@@ -197,20 +314,55 @@ func DefaultConfig(version string) *Configuration {
 		KeepSrc:                false,
 		Editor:                 "",
 		EditorFlags:            "",
+		EditFiles:              ".install .patch .sh",
 		Devel:                  false,
+		DevelLog:               false,
+		DevelStrict:            false,
 		MakepkgBin:             "makepkg",
 		MakepkgConf:            "",
+		MakepkgConfExtra:       "",
+		BuildUser:              "",
+		RootBuild:              "auto",
 		PacmanBin:              "pacman",
 		PGPFetch:               true,
+		StrictChecksums:        false,
+		Inspectors:             "",
+		InspectBlock:           false,
+		NoDebug:                false,
 		PacmanConf:             "/etc/pacman.conf",
+		Color:                  "",
+		DateFormat:             "",
+		Plain:                  false,
+		VerbosePkgLists:        false,
+		LogLevels:              "",
 		GpgFlags:               "",
 		MFlags:                 "",
 		GitFlags:               "",
+		PacmanSyncFlags:        "",
+		PacmanUpgradeFlags:     "",
+		PacmanDatabaseFlags:    "",
+		PacmanRemoveFlags:      "",
 		BottomUp:               true,
 		CompletionInterval:     7,
+		DBMaxAge:               7,
 		MaxConcurrentDownloads: 1,
+		DownloadRateLimit:      "",
+		MemoryLimit:            2048,
+		ShallowClone:           false,
+		PKGBUILDFetch:          "cgit",
+		PKGBUILDClone:          "git",
+		GCInterval:             0,
+		Worktrees:              false,
+		VersionedBuildDirs:     false,
+		KeepVersions:           3,
+		KeepVersionsDays:       0,
+		ConfirmUpfront:         false,
+		SelfUpgradeFirst:       false,
+		Timings:                false,
 		SortBy:                 "votes",
 		SearchBy:               "name-desc",
+		DefaultOp:              "sysupgrade",
+		DefaultTargetsOp:       "menu",
 		SudoLoop:               false,
 		GitBin:                 "git",
 		GpgBin:                 "gpg",
@@ -225,8 +377,12 @@ func DefaultConfig(version string) *Configuration {
 		AnswerDiff:             "",
 		AnswerEdit:             "",
 		AnswerUpgrade:          "",
+		IgnoreRepo:             "",
+		NewsFeeds:              "https://archlinux.org/feeds/news",
+		NewsOnUpgrade:          "off",
 		RemoveMake:             "ask",
 		Provides:               true,
+		ProviderPolicy:         "repo-first",
 		CleanMenu:              true,
 		DiffMenu:               true,
 		EditMenu:               false,
@@ -237,7 +393,10 @@ func DefaultConfig(version string) *Configuration {
 		Debug:                  false,
 		UseRPC:                 true,
 		DoubleConfirm:          true,
+		ThrottleBuilds:         false,
 		Mode:                   parser.ModeAny,
+		Output:                 "text",
+		OutputFD:               1,
 	}
 }
 
@@ -252,6 +411,16 @@ func NewConfig(logger *text.Logger, configPath, version string) (*Configuration,
 	newConfig.BuildDir = cacheHome
 	newConfig.CompletionPath = filepath.Join(cacheHome, completionFileName)
 	newConfig.VCSFilePath = filepath.Join(cacheHome, vcsFileName)
+	newConfig.ProvidersFilePath = filepath.Join(cacheHome, providersFileName)
+	newConfig.RemoveMakeFilePath = filepath.Join(cacheHome, removeMakeFileName)
+	stateHome := getStateHome(cacheHome)
+	newConfig.AURMissesFilePath = filepath.Join(stateHome, aurMissesFileName)
+	newConfig.NewsStateFilePath = filepath.Join(stateHome, newsStateFileName)
+	newConfig.SysupgradeFilePath = filepath.Join(stateHome, sysupgradeFileName)
+	newConfig.AURSessionFilePath = filepath.Join(stateHome, aurSessionFileName)
+	newConfig.NotesFilePath = filepath.Join(stateHome, notesFileName)
+	newConfig.DirSizesFilePath = filepath.Join(stateHome, dirSizesFileName)
+	newConfig.BuildStatsFilePath = filepath.Join(stateHome, buildStatsFileName)
 	newConfig.load(configPath)
 
 	if aurdest := os.Getenv("AURDEST"); aurdest != "" {
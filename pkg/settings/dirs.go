@@ -9,6 +9,15 @@ const (
 	configFileName     string = "config.json" // configFileName holds the name of the config file.
 	vcsFileName        string = "vcs.json"    // vcsFileName holds the name of the vcs file.
 	completionFileName string = "completion.cache"
+	providersFileName  string = "providers.json"    // providersFileName holds the remembered provider choices.
+	removeMakeFileName string = "removemake.json"   // removeMakeFileName holds the remembered make dependency removals.
+	aurMissesFileName  string = "aur_misses.json"   // aurMissesFileName holds the targets recently missing from the AUR.
+	newsStateFileName  string = "news.json"         // newsStateFileName holds the newest news item seen per feed.
+	sysupgradeFileName string = "last_sysupgrade"   // sysupgradeFileName holds the time of the last successful sysupgrade.
+	aurSessionFileName string = "aur_session"       // aurSessionFileName holds the AUR web session of the account.
+	notesFileName      string = "notes.json"        // notesFileName holds the notes kept on installed packages.
+	dirSizesFileName   string = "dir_sizes.json"    // dirSizesFileName holds the sizes of the directories walked by -Ps.
+	buildStatsFileName string = "build_stats.json"  // buildStatsFileName holds the peak memory of the last build of each package.
 	systemdCache       string = "/var/cache/yippee" // systemd should handle cache creation
 )
 
@@ -56,6 +65,30 @@ func getCacheHome() (string, error) {
 	return tmpDir, initDir(tmpDir)
 }
 
+// getStateHome returns the directory of state kept between runs, falling back
+// to the cache directory when there is none.
+func getStateHome(cacheHome string) string {
+	if os.Geteuid() == 0 {
+		return cacheHome
+	}
+
+	if stateHome := os.Getenv("XDG_STATE_HOME"); stateHome != "" {
+		stateDir := filepath.Join(stateHome, "yippee")
+		if err := initDir(stateDir); err == nil {
+			return stateDir
+		}
+	}
+
+	if home := os.Getenv("HOME"); home != "" {
+		stateDir := filepath.Join(home, ".local", "state", "yippee")
+		if err := initDir(stateDir); err == nil {
+			return stateDir
+		}
+	}
+
+	return cacheHome
+}
+
 func initDir(dir string) error {
 	if _, err := os.Stat(dir); os.IsNotExist(err) {
 		if err = os.MkdirAll(dir, 0o755); err != nil {
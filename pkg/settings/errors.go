@@ -28,3 +28,20 @@ type ErrUserAbort struct{}
 func (e ErrUserAbort) Error() string {
 	return gotext.Get("aborting due to user")
 }
+
+// ErrNothingToDo is returned when there is nothing to install or upgrade.
+// The message is printed where the condition is detected, so the error is
+// silent.
+type ErrNothingToDo struct{}
+
+func (e ErrNothingToDo) Error() string {
+	return ""
+}
+
+// ErrNoOperation is returned when neither an operation nor targets, or only
+// targets, are given and the configured default is to refuse.
+type ErrNoOperation struct{}
+
+func (e ErrNoOperation) Error() string {
+	return gotext.Get("no operation specified (use -h for help)")
+}
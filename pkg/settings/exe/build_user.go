@@ -0,0 +1,128 @@
+package exe
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/leonelquinteros/gotext"
+)
+
+// buildUser is the unprivileged user commands are handed off to when
+// yippee runs as root.
+type buildUser struct {
+	name string
+	home string
+	uid  int
+	gid  int
+}
+
+func lookupBuildUser(name string) (*buildUser, error) {
+	found, err := user.Lookup(name)
+	if err != nil {
+		return nil, err
+	}
+
+	uid, err := strconv.Atoi(found.Uid)
+	if err != nil {
+		return nil, err
+	}
+
+	gid, err := strconv.Atoi(found.Gid)
+	if err != nil {
+		return nil, err
+	}
+
+	return &buildUser{name: found.Username, home: found.HomeDir, uid: uid, gid: gid}, nil
+}
+
+// buildUserName returns the user to build as. The user that invoked sudo or
+// doas is never picked implicitly, it has to be configured like any other.
+func (c *CmdBuilder) buildUserName() string {
+	return c.BuildUser
+}
+
+// apply makes cmd run with the credentials and environment of the user.
+func (b *buildUser) apply(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Credential: &syscall.Credential{Uid: uint32(b.uid), Gid: uint32(b.gid)},
+	}
+
+	env := cmd.Env
+	if env == nil {
+		env = os.Environ()
+	}
+
+	cmd.Env = append(env, "HOME="+b.home, "USER="+b.name, "LOGNAME="+b.name)
+}
+
+// handOffBuildDir gives the user ownership of dir, which must be inside
+// buildDir. Anything else, such as the working directory -G clones into,
+// belongs to whoever runs yippee and is never handed over.
+func (b *buildUser) handOffBuildDir(buildDir, dir string) error {
+	resolved, err := resolvePath(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	resolvedBuildDir, err := resolvePath(buildDir)
+	if err != nil {
+		return err
+	}
+
+	rel, err := filepath.Rel(resolvedBuildDir, resolved)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return errors.New(gotext.Get("%s is outside the build directory %s", dir, buildDir))
+	}
+
+	return b.handOff(resolved)
+}
+
+// resolvePath returns the absolute path of path with symlinks resolved.
+func resolvePath(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.EvalSymlinks(abs)
+}
+
+// handOff gives the user ownership of path and its contents. Subtrees
+// already owned by the user are skipped as they were created by it.
+func (b *buildUser) handOff(path string) error {
+	return filepath.WalkDir(path, func(current string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+
+			return err
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		if stat, ok := info.Sys().(*syscall.Stat_t); ok && int(stat.Uid) == b.uid {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		return os.Lchown(current, b.uid, b.gid)
+	})
+}
@@ -0,0 +1,85 @@
+package exe
+
+import (
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildUserName(t *testing.T) {
+	t.Setenv("SUDO_USER", "sudoer")
+	t.Setenv("DOAS_USER", "doaser")
+
+	assert.Equal(t, "yippee-build", (&CmdBuilder{BuildUser: "yippee-build"}).buildUserName())
+	assert.Equal(t, "", (&CmdBuilder{}).buildUserName())
+}
+
+func TestBuildUserApply(t *testing.T) {
+	t.Parallel()
+
+	current, err := user.Current()
+	require.NoError(t, err)
+
+	buildUser, err := lookupBuildUser(current.Username)
+	require.NoError(t, err)
+
+	cmd := exec.Command("makepkg")
+	cmd.Env = []string{"GIT_TERMINAL_PROMPT=0"}
+	buildUser.apply(cmd)
+
+	require.NotNil(t, cmd.SysProcAttr.Credential)
+	assert.EqualValues(t, buildUser.uid, cmd.SysProcAttr.Credential.Uid)
+	assert.EqualValues(t, buildUser.gid, cmd.SysProcAttr.Credential.Gid)
+	assert.Equal(t, []string{
+		"GIT_TERMINAL_PROMPT=0",
+		"HOME=" + current.HomeDir,
+		"USER=" + current.Username,
+		"LOGNAME=" + current.Username,
+	}, cmd.Env)
+
+	_, err = lookupBuildUser("yippee-no-such-user")
+	assert.Error(t, err)
+}
+
+func TestBuildUserHandOffOwned(t *testing.T) {
+	t.Parallel()
+
+	current, err := user.Current()
+	require.NoError(t, err)
+
+	buildUser, err := lookupBuildUser(current.Username)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "PKGBUILD"), []byte{}, 0o600))
+
+	assert.NoError(t, buildUser.handOff(dir))
+	assert.NoError(t, buildUser.handOff(filepath.Join(dir, "missing")))
+}
+
+func TestBuildUserHandOffBuildDir(t *testing.T) {
+	t.Parallel()
+
+	current, err := user.Current()
+	require.NoError(t, err)
+
+	buildUser, err := lookupBuildUser(current.Username)
+	require.NoError(t, err)
+
+	buildDir := t.TempDir()
+	outside := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(buildDir, "foo"), 0o755))
+	require.NoError(t, os.Symlink(outside, filepath.Join(buildDir, "escape")))
+
+	assert.NoError(t, buildUser.handOffBuildDir(buildDir, buildDir))
+	assert.NoError(t, buildUser.handOffBuildDir(buildDir, filepath.Join(buildDir, "foo")))
+	assert.NoError(t, buildUser.handOffBuildDir(buildDir, filepath.Join(buildDir, "missing")))
+	assert.Error(t, buildUser.handOffBuildDir(buildDir, outside))
+	assert.Error(t, buildUser.handOffBuildDir(buildDir, filepath.Join(buildDir, "escape")))
+	assert.Error(t, buildUser.handOffBuildDir(buildDir, filepath.Join(buildDir, "..")))
+}
@@ -2,14 +2,13 @@ package exe
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
-	"os/user"
 	"path/filepath"
-	"strconv"
 	"strings"
-	"syscall"
+	"sync"
 	"time"
 
 	mapset "github.com/deckarep/golang-set/v2"
@@ -37,19 +36,26 @@ type ICmdBuilder interface {
 	BuildGitCmd(ctx context.Context, dir string, extraArgs ...string) *exec.Cmd
 	BuildGPGCmd(ctx context.Context, extraArgs ...string) *exec.Cmd
 	BuildMakepkgCmd(ctx context.Context, dir string, extraArgs ...string) *exec.Cmd
+	BuildThrottledMakepkgCmd(ctx context.Context, dir string, jobs int, extraArgs ...string) *exec.Cmd
+	BuildInspectorCmd(ctx context.Context, dir, bin string, extraArgs ...string) *exec.Cmd
 	BuildPacmanCmd(ctx context.Context, args *parser.Arguments, mode parser.TargetMode, noConfirm bool) *exec.Cmd
 	AddMakepkgFlag(string)
 	GetKeepSrc() bool
 	SudoLoop()
+	Cleanup()
 }
 
 type CmdBuilder struct {
 	GitBin           string
 	GitFlags         []string
+	BuildUser        string
+	BuildDir         string
+	RootBuild        string
 	GPGBin           string
 	GPGFlags         []string
 	MakepkgFlags     []string
 	MakepkgConfPath  string
+	MakepkgConfExtra string
 	MakepkgBin       string
 	SudoBin          string
 	SudoFlags        []string
@@ -57,19 +63,39 @@ type CmdBuilder struct {
 	PacmanBin        string
 	PacmanConfigPath string
 	PacmanDBPath     string
+	NetworkEnv       []string
+	RateLimit        string
+	NoDebug          bool
+	NoProgressBar    bool
+	TempDir          string
+	// PacmanPhaseFlags are extra arguments for pacman transactions keyed by
+	// operation: "S", "U", "D" and "R".
+	PacmanPhaseFlags map[string][]string
 	KeepSrc          bool
 	Runner           Runner
 	Log              *text.Logger
+
+	// mergedConfs are the makepkg.conf overlays by job count, 0 when unlimited
+	mergedConfsMu sync.Mutex
+	mergedConfs   map[int]string
+
+	rootBuildOnce    sync.Once
+	rootBuild        *rootBuild
+	systemdAvailable func() bool
 }
 
 func NewCmdBuilder(cfg *settings.Configuration, runner Runner, logger *text.Logger, dbPath string) *CmdBuilder {
 	return &CmdBuilder{
 		GitBin:           cfg.GitBin,
 		GitFlags:         strings.Fields(cfg.GitFlags),
+		BuildUser:        cfg.BuildUser,
+		BuildDir:         cfg.BuildDir,
+		RootBuild:        cfg.RootBuild,
 		GPGBin:           cfg.GpgBin,
 		GPGFlags:         strings.Fields(cfg.GpgFlags),
 		MakepkgFlags:     strings.Fields(cfg.MFlags),
 		MakepkgConfPath:  cfg.MakepkgConf,
+		MakepkgConfExtra: cfg.MakepkgConfExtra,
 		MakepkgBin:       cfg.MakepkgBin,
 		SudoBin:          cfg.SudoBin,
 		SudoFlags:        strings.Fields(cfg.SudoFlags),
@@ -80,6 +106,17 @@ func NewCmdBuilder(cfg *settings.Configuration, runner Runner, logger *text.Logg
 		KeepSrc:          cfg.KeepSrc,
 		Runner:           runner,
 		Log:              logger,
+		NetworkEnv:       NetworkEnv(cfg.Proxy, cfg.NoProxy, cfg.CABundle),
+		RateLimit:        cfg.DownloadRateLimit,
+		NoDebug:          cfg.NoDebug,
+		NoProgressBar:    cfg.Plain,
+		TempDir:          cfg.TempDir,
+		PacmanPhaseFlags: map[string][]string{
+			"S": strings.Fields(cfg.PacmanSyncFlags),
+			"U": strings.Fields(cfg.PacmanUpgradeFlags),
+			"D": strings.Fields(cfg.PacmanDatabaseFlags),
+			"R": strings.Fields(cfg.PacmanRemoveFlags),
+		},
 	}
 }
 
@@ -93,7 +130,7 @@ func (c *CmdBuilder) BuildGPGCmd(ctx context.Context, extraArgs ...string) *exec
 
 	cmd := exec.CommandContext(ctx, c.GPGBin, args...)
 
-	cmd = c.deElevateCommand(ctx, cmd)
+	cmd = c.deElevateCommand(ctx, cmd, "")
 
 	return cmd
 }
@@ -127,9 +164,9 @@ func (c *CmdBuilder) BuildGitCmd(ctx context.Context, dir string, extraArgs ...s
 
 	cmd := exec.CommandContext(ctx, c.GitBin, args...)
 
-	cmd.Env = gitFilteredEnv()
+	cmd.Env = append(gitFilteredEnv(), c.NetworkEnv...)
 
-	cmd = c.deElevateCommand(ctx, cmd)
+	cmd = c.deElevateCommand(ctx, cmd, dir)
 
 	return cmd
 }
@@ -139,11 +176,26 @@ func (c *CmdBuilder) AddMakepkgFlag(flag string) {
 }
 
 func (c *CmdBuilder) BuildMakepkgCmd(ctx context.Context, dir string, extraArgs ...string) *exec.Cmd {
+	return c.buildMakepkgCmd(ctx, dir, 0, extraArgs...)
+}
+
+// BuildThrottledMakepkgCmd builds makepkg limited to jobs make jobs. The job
+// count is set as MAKEFLAGS in the makepkg.conf overlay, where it overrides
+// the one of makepkg.conf and reaches builds run by systemd-run.
+func (c *CmdBuilder) BuildThrottledMakepkgCmd(ctx context.Context, dir string, jobs int, extraArgs ...string) *exec.Cmd {
+	return c.buildMakepkgCmd(ctx, dir, jobs, extraArgs...)
+}
+
+func (c *CmdBuilder) buildMakepkgCmd(ctx context.Context, dir string, jobs int, extraArgs ...string) *exec.Cmd {
 	args := make([]string, len(c.MakepkgFlags), len(c.MakepkgFlags)+len(extraArgs))
 	copy(args, c.MakepkgFlags)
 
-	if c.MakepkgConfPath != "" {
-		args = append(args, "--config", c.MakepkgConfPath)
+	if confPath := c.makepkgConf(jobs); confPath != "" {
+		args = append(args, "--config", confPath)
+	}
+
+	if !text.UseColor {
+		args = append(args, "--nocolor")
 	}
 
 	if len(extraArgs) > 0 {
@@ -153,33 +205,109 @@ func (c *CmdBuilder) BuildMakepkgCmd(ctx context.Context, dir string, extraArgs
 	cmd := exec.CommandContext(ctx, c.MakepkgBin, args...)
 	cmd.Dir = dir
 
-	cmd = c.deElevateCommand(ctx, cmd)
+	if len(c.NetworkEnv) != 0 {
+		cmd.Env = append(os.Environ(), c.NetworkEnv...)
+	}
+
+	cmd = c.deElevateCommand(ctx, cmd, dir)
 
 	return cmd
 }
 
-// deElevateCommand, `systemd-run` code based on pikaur.
-func (c *CmdBuilder) deElevateCommand(ctx context.Context, cmd *exec.Cmd) *exec.Cmd {
+// BuildInspectorCmd builds the command of a linter run on the PKGBUILD in
+// dir. Linters like namcap source the PKGBUILD, so they drop root privileges
+// like makepkg does.
+func (c *CmdBuilder) BuildInspectorCmd(ctx context.Context, dir, bin string, extraArgs ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, bin, extraArgs...)
+	cmd.Dir = dir
+
+	return c.deElevateCommand(ctx, cmd, dir)
+}
+
+// makepkgConf returns the makepkg.conf to pass to makepkg. When a fragment,
+// a download rate limit, no debug packages or a job count are configured it
+// is overlaid on the regular config in a temporary file generated on first
+// use for each job count.
+func (c *CmdBuilder) makepkgConf(jobs int) string {
+	if c.MakepkgConfExtra == "" && c.RateLimit == "" && !c.NoDebug && jobs <= 0 {
+		return c.MakepkgConfPath
+	}
+
+	c.mergedConfsMu.Lock()
+	defer c.mergedConfsMu.Unlock()
+
+	merged, ok := c.mergedConfs[jobs]
+	if !ok {
+		var err error
+
+		merged, err = mergeMakepkgConf(c.TempDir, c.MakepkgConfPath, c.MakepkgConfExtra, c.RateLimit, c.NoDebug, jobs)
+		if err != nil {
+			c.Log.Errorln(gotext.Get("unable to overlay makepkg.conf: %s", err))
+		}
+
+		if c.mergedConfs == nil {
+			c.mergedConfs = make(map[int]string)
+		}
+
+		c.mergedConfs[jobs] = merged
+	}
+
+	if merged == "" {
+		return c.MakepkgConfPath
+	}
+
+	return merged
+}
+
+// Cleanup removes temporary files created by the builder.
+func (c *CmdBuilder) Cleanup() {
+	c.mergedConfsMu.Lock()
+	defer c.mergedConfsMu.Unlock()
+
+	for _, merged := range c.mergedConfs {
+		if merged != "" {
+			os.Remove(merged)
+		}
+	}
+}
+
+// deElevateCommand makes cmd drop root privileges according to the root
+// build strategy. dir is handed over to the build user first, commands in a
+// dir outside the build directory are refused. Commands that can not be
+// de-elevated fail on start with guidance on how to proceed.
+func (c *CmdBuilder) deElevateCommand(ctx context.Context, cmd *exec.Cmd, dir string) *exec.Cmd {
 	if os.Geteuid() != 0 {
 		return cmd
 	}
 
-	ogCaller := ""
-	if caller := os.Getenv("SUDO_USER"); caller != "" {
-		ogCaller = caller
-	} else if caller := os.Getenv("DOAS_USER"); caller != "" {
-		ogCaller = caller
-	}
+	rootBuild := c.rootBuildStrategy()
+
+	switch {
+	case rootBuild.user != nil:
+		if dir != "" {
+			if err := rootBuild.user.handOffBuildDir(c.BuildDir, dir); err != nil {
+				cmd.Err = errors.New(gotext.Get("unable to hand %s over to build user %s: %s",
+					dir, rootBuild.user.name, err))
+
+				return cmd
+			}
+		}
 
-	if userFound, err := user.Lookup(ogCaller); err == nil {
-		cmd.SysProcAttr = &syscall.SysProcAttr{}
-		uid, _ := strconv.Atoi(userFound.Uid)
-		gid, _ := strconv.Atoi(userFound.Gid)
-		cmd.SysProcAttr.Credential = &syscall.Credential{Uid: uint32(uid), Gid: uint32(gid)}
+		rootBuild.user.apply(cmd)
+
+		return cmd
+	case rootBuild.err != nil:
+		cmd.Err = rootBuild.err
 
 		return cmd
 	}
 
+	return systemdRunCommand(ctx, cmd, c.NetworkEnv)
+}
+
+// systemdRunCommand wraps cmd in a `systemd-run` DynamicUser, code based on pikaur.
+// extraEnv is set in the environment of the wrapped command.
+func systemdRunCommand(ctx context.Context, cmd *exec.Cmd, extraEnv []string) *exec.Cmd {
 	cmdArgs := []string{
 		"--service-type=oneshot",
 		"--pipe", "--wait", "--pty", "--quiet",
@@ -198,6 +326,10 @@ func (c *CmdBuilder) deElevateCommand(ctx context.Context, cmd *exec.Cmd) *exec.
 		}
 	}
 
+	for _, envVar := range extraEnv {
+		cmdArgs = append(cmdArgs, "-E", envVar)
+	}
+
 	path, _ := exec.LookPath(cmd.Args[0])
 
 	cmdArgs = append(cmdArgs, path)
@@ -229,6 +361,14 @@ func (c *CmdBuilder) BuildPacmanCmd(ctx context.Context, args *parser.Arguments,
 	argArr = append(argArr, args.FormatGlobals()...)
 	argArr = append(argArr, args.FormatArgs()...)
 
+	if needsRoot {
+		argArr = append(argArr, c.phaseFlags(args)...)
+
+		if c.NoProgressBar && isTransaction(args) && !args.ExistsArg("noprogressbar") {
+			argArr = append(argArr, "--noprogressbar")
+		}
+	}
+
 	if noConfirm {
 		argArr = append(argArr, "--noconfirm")
 	}
@@ -247,6 +387,38 @@ func (c *CmdBuilder) BuildPacmanCmd(ctx context.Context, args *parser.Arguments,
 	return exec.CommandContext(ctx, argArr[0], argArr[1:]...)
 }
 
+// phaseFlags returns the configured extra arguments for the transaction
+// performed by args.
+func (c *CmdBuilder) phaseFlags(args *parser.Arguments) []string {
+	switch args.Op {
+	case "S", "sync":
+		if args.ExistsArg("c", "clean") {
+			return nil
+		}
+
+		return c.PacmanPhaseFlags["S"]
+	case "U", "upgrade":
+		return c.PacmanPhaseFlags["U"]
+	case "D", "database":
+		return c.PacmanPhaseFlags["D"]
+	case "R", "remove":
+		return c.PacmanPhaseFlags["R"]
+	}
+
+	return nil
+}
+
+// isTransaction reports whether args is a pacman transaction, the only
+// operations taking --noprogressbar.
+func isTransaction(args *parser.Arguments) bool {
+	switch args.Op {
+	case "S", "sync", "U", "upgrade", "R", "remove":
+		return true
+	}
+
+	return false
+}
+
 // waitLock will lock yippee checking the status of db.lck until it does not exist.
 func (c *CmdBuilder) waitLock(dbPath string) {
 	lockDBPath := filepath.Join(dbPath, "db.lck")
@@ -0,0 +1,192 @@
+//go:build !integration
+// +build !integration
+
+package exe
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jguer/yippee/v12/pkg/settings"
+	"github.com/Jguer/yippee/v12/pkg/settings/parser"
+	"github.com/Jguer/yippee/v12/pkg/text"
+)
+
+func TestBuildPacmanCmdPhaseFlags(t *testing.T) {
+	t.Parallel()
+
+	cfg := &settings.Configuration{
+		PacmanBin:          "pacman",
+		PacmanConf:         "/etc/pacman.conf",
+		SudoBin:            "sudo",
+		PacmanSyncFlags:    "--disable-download-timeout",
+		PacmanUpgradeFlags: "--overwrite /opt/foo/*",
+		PacmanRemoveFlags:  "--nosave",
+	}
+
+	testCases := []struct {
+		desc string
+		args []string
+		want []string
+	}{
+		{desc: "sync install", args: []string{"S"}, want: []string{"--disable-download-timeout"}},
+		{desc: "upgrade install", args: []string{"U"}, want: []string{"--overwrite", "/opt/foo/*"}},
+		{desc: "removal", args: []string{"R", "s"}, want: []string{"--nosave"}},
+		{desc: "database", args: []string{"D", "asdeps"}, want: []string{}},
+		{desc: "search", args: []string{"S", "s"}, want: []string{}},
+		{desc: "clean", args: []string{"S", "c"}, want: []string{}},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			builder := NewCmdBuilder(cfg, nil, text.NewLogger(nil, nil, nil, false, "test"), t.TempDir())
+
+			args := parser.MakeArguments()
+			require.NoError(t, args.AddArg(tc.args...))
+			args.AddTarget("foo")
+
+			cmd := builder.BuildPacmanCmd(context.Background(), args, parser.ModeAny, false)
+
+			for _, flag := range tc.want {
+				assert.Contains(t, cmd.Args, flag)
+			}
+
+			for _, flag := range []string{"--disable-download-timeout", "--overwrite", "--nosave"} {
+				if !slices.Contains(tc.want, flag) {
+					assert.NotContains(t, cmd.Args, flag)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildPacmanCmdNoProgressBar(t *testing.T) {
+	t.Parallel()
+
+	cfg := &settings.Configuration{
+		PacmanBin:  "pacman",
+		PacmanConf: "/etc/pacman.conf",
+		SudoBin:    "sudo",
+		Plain:      true,
+	}
+
+	testCases := []struct {
+		desc string
+		args []string
+		want bool
+	}{
+		{desc: "sync install", args: []string{"S"}, want: true},
+		{desc: "refresh", args: []string{"S", "y"}, want: true},
+		{desc: "upgrade install", args: []string{"U"}, want: true},
+		{desc: "removal", args: []string{"R"}, want: true},
+		{desc: "database", args: []string{"D", "asdeps"}, want: false},
+		{desc: "query", args: []string{"Q"}, want: false},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			builder := NewCmdBuilder(cfg, nil, text.NewLogger(nil, nil, nil, false, "test"), t.TempDir())
+
+			args := parser.MakeArguments()
+			require.NoError(t, args.AddArg(tc.args...))
+			args.AddTarget("foo")
+
+			cmd := builder.BuildPacmanCmd(context.Background(), args, parser.ModeAny, false)
+			if tc.want {
+				assert.Contains(t, cmd.Args, "--noprogressbar")
+			} else {
+				assert.NotContains(t, cmd.Args, "--noprogressbar")
+			}
+		})
+	}
+}
+
+func TestNetworkEnv(t *testing.T) {
+	t.Parallel()
+
+	assert.Empty(t, NetworkEnv("", "", ""))
+
+	env := NetworkEnv("socks5://proxy:1080", "localhost,.corp", "/etc/ca.pem")
+	assert.Contains(t, env, "https_proxy=socks5://proxy:1080")
+	assert.Contains(t, env, "all_proxy=socks5://proxy:1080")
+	assert.Contains(t, env, "no_proxy=localhost,.corp")
+	assert.Contains(t, env, "GIT_SSL_CAINFO=/etc/ca.pem")
+	assert.Contains(t, env, "CURL_CA_BUNDLE=/etc/ca.pem")
+
+	cmd := exec.CommandContext(context.Background(), "git", "clone")
+
+	wrapped := systemdRunCommand(context.Background(), cmd, env)
+	assert.Contains(t, wrapped.Args, "https_proxy=socks5://proxy:1080")
+	assert.Contains(t, wrapped.Args, "GIT_SSL_CAINFO=/etc/ca.pem")
+}
+
+func TestBuildInspectorCmd(t *testing.T) {
+	t.Parallel()
+
+	builder := &CmdBuilder{
+		RootBuild:        RootBuildError,
+		Log:              text.NewLogger(io.Discard, io.Discard, strings.NewReader(""), false, "test"),
+		systemdAvailable: func() bool { return false },
+	}
+
+	cmd := builder.BuildInspectorCmd(context.Background(), "/tmp/yippee", "sh", "PKGBUILD")
+
+	assert.Equal(t, []string{"sh", "PKGBUILD"}, cmd.Args)
+	assert.Equal(t, "/tmp/yippee", cmd.Dir)
+	// as root the linter is refused, like makepkg, instead of running as root
+	assert.Equal(t, os.Geteuid() == 0, cmd.Err != nil)
+}
+
+func TestBuildThrottledMakepkgCmd(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	base := filepath.Join(dir, "makepkg.conf")
+	require.NoError(t, os.WriteFile(base, []byte(`MAKEFLAGS="-j16"`), 0o600))
+
+	builder := &CmdBuilder{
+		MakepkgBin:      "makepkg",
+		MakepkgConfPath: base,
+		TempDir:         dir,
+		Log:             text.NewLogger(io.Discard, io.Discard, strings.NewReader(""), false, "test"),
+	}
+	t.Cleanup(builder.Cleanup)
+
+	configOf := func(cmd *exec.Cmd) string {
+		i := slices.Index(cmd.Args, "--config")
+		require.NotEqual(t, -1, i)
+
+		return cmd.Args[i+1]
+	}
+
+	ctx := context.Background()
+
+	assert.Equal(t, base, configOf(builder.BuildMakepkgCmd(ctx, dir)))
+
+	throttled := configOf(builder.BuildThrottledMakepkgCmd(ctx, dir, 2))
+	assert.NotEqual(t, base, throttled)
+	assert.Equal(t, throttled, configOf(builder.BuildThrottledMakepkgCmd(ctx, dir, 2)))
+	assert.NotEqual(t, throttled, configOf(builder.BuildThrottledMakepkgCmd(ctx, dir, 1)))
+
+	content, err := os.ReadFile(throttled)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `MAKEFLAGS="${MAKEFLAGS} -j2"`)
+
+	builder.Cleanup()
+	assert.NoFileExists(t, throttled)
+}
@@ -2,6 +2,7 @@ package exe
 
 import (
 	"errors"
+	"io"
 	"os"
 	"os/exec"
 	"strings"
@@ -23,17 +24,24 @@ func NewOSRunner(log *text.Logger) *OSRunner {
 	return &OSRunner{log}
 }
 
+// Show runs cmd attached to the terminal. A Stderr set by the caller receives
+// a copy of the error output.
 func (r *OSRunner) Show(cmd *exec.Cmd) error {
-	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	stderr := io.Writer(os.Stderr)
+	if cmd.Stderr != nil {
+		stderr = io.MultiWriter(os.Stderr, cmd.Stderr)
+	}
+
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, stderr
 	cmd.SysProcAttr = &syscall.SysProcAttr{
 		Pdeathsig: syscall.SIGTERM,
 	}
-	r.Log.Debugln("running", cmd.String())
+	r.Log.Verboseln("running", cmd.String())
 	return cmd.Run()
 }
 
 func (r *OSRunner) Capture(cmd *exec.Cmd) (stdout, stderr string, err error) {
-	r.Log.Debugln("capturing", cmd.String())
+	r.Log.Traceln("capturing", cmd.String())
 	cmd.SysProcAttr = &syscall.SysProcAttr{
 		Pdeathsig: syscall.SIGTERM,
 	}
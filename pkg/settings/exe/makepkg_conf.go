@@ -0,0 +1,190 @@
+package exe
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const defaultMakepkgConf = "/etc/makepkg.conf"
+
+// makepkgConfSources returns the files makepkg would read for the given
+// config path, in the order they are sourced.
+func makepkgConfSources(confPath string) []string {
+	userConf := confPath == ""
+	if userConf {
+		confPath = defaultMakepkgConf
+	}
+
+	sources := []string{confPath}
+
+	if dropIns, err := filepath.Glob(filepath.Join(confPath+".d", "*.conf")); err == nil {
+		sources = append(sources, dropIns...)
+	}
+
+	// makepkg skips the user config when --config is given
+	if userConf {
+		if configHome := os.Getenv("XDG_CONFIG_HOME"); configHome != "" {
+			sources = append(sources, filepath.Join(configHome, "pacman", "makepkg.conf"))
+		}
+
+		if home := os.Getenv("HOME"); home != "" {
+			sources = append(sources, filepath.Join(home, ".makepkg.conf"))
+		}
+	}
+
+	return sources
+}
+
+// rateLimitPattern matches the rates understood by curl and wget.
+var rateLimitPattern = regexp.MustCompile(`^[0-9]+[kKmMgG]?$`)
+
+// rateLimitDLAgents returns the makepkg.conf lines limiting the bandwidth of
+// the curl and wget download agents to rate.
+func rateLimitDLAgents(rate string) (string, error) {
+	if !rateLimitPattern.MatchString(rate) {
+		return "", fmt.Errorf("invalid download rate limit %q", rate)
+	}
+
+	return fmt.Sprintf("DLAGENTS=(\"${DLAGENTS[@]/curl /curl --limit-rate %[1]s }\")\n"+
+		"DLAGENTS=(\"${DLAGENTS[@]/wget /wget --limit-rate=%[1]s }\")\n", rate), nil
+}
+
+// makepkgConfOption looks up option in the array of the config at confPath
+// overlaid with fragment. Like makepkg, the last mention of the option wins.
+// found is false when it is not mentioned, unreadable files are ignored.
+func makepkgConfOption(confPath, fragment, array, option string) (enabled, found bool) {
+	sources := makepkgConfSources(confPath)
+	if fragment != "" {
+		sources = append(sources, fragment)
+	}
+
+	pattern := regexp.MustCompile(`(?m)^\s*` + array + `(\+?)=\(([^)]*)\)`)
+	values := []string{}
+
+	for _, source := range sources {
+		content, err := os.ReadFile(source)
+		if err != nil {
+			continue
+		}
+
+		for _, match := range pattern.FindAllStringSubmatch(string(content), -1) {
+			if match[1] == "" {
+				values = values[:0]
+			}
+
+			for _, value := range strings.Fields(match[2]) {
+				values = append(values, strings.Trim(value, `'"`))
+			}
+		}
+	}
+
+	for i := len(values) - 1; i >= 0; i-- {
+		switch values[i] {
+		case option:
+			return true, true
+		case "!" + option:
+			return false, true
+		}
+	}
+
+	return false, false
+}
+
+// MakepkgDebugEnabled reports whether makepkg builds debug packages with the
+// config at confPath overlaid with fragment.
+func MakepkgDebugEnabled(confPath, fragment string) bool {
+	enabled, _ := makepkgConfOption(confPath, fragment, "OPTIONS", "debug")
+	return enabled
+}
+
+// MakepkgRunsCheck reports whether makepkg runs check() when given
+// makepkgFlags and the config at confPath overlaid with fragment. The flags
+// take precedence over BUILDENV, which is assumed to enable check when it
+// can not be read.
+func MakepkgRunsCheck(makepkgFlags []string, confPath, fragment string) bool {
+	for i := len(makepkgFlags) - 1; i >= 0; i-- {
+		switch makepkgFlags[i] {
+		case "--check":
+			return true
+		case "--nocheck":
+			return false
+		}
+	}
+
+	enabled, found := makepkgConfOption(confPath, fragment, "BUILDENV", "check")
+
+	return enabled || !found
+}
+
+// mergeMakepkgConf concatenates the sources of confPath with the fragment
+// into a temporary makepkg.conf in tempDir and returns its path. An empty
+// fragment is skipped. When rateLimit is set the download agents are limited
+// to it, noDebug disables debug packages and a positive jobs limits the make
+// jobs.
+func mergeMakepkgConf(tempDir, confPath, fragment, rateLimit string, noDebug bool, jobs int) (string, error) {
+	var buf bytes.Buffer
+
+	buf.WriteString("# Generated by yippee, do not edit.\n")
+
+	sources := makepkgConfSources(confPath)
+	if fragment != "" {
+		sources = append(sources, fragment)
+	}
+
+	for _, source := range sources {
+		content, err := os.ReadFile(source)
+		if err != nil {
+			if os.IsNotExist(err) && source != fragment {
+				continue
+			}
+
+			return "", err
+		}
+
+		fmt.Fprintf(&buf, "\n# %s\n", source)
+		buf.Write(content)
+		buf.WriteByte('\n')
+	}
+
+	if rateLimit != "" {
+		dlAgents, err := rateLimitDLAgents(rateLimit)
+		if err != nil {
+			return "", err
+		}
+
+		buf.WriteString("\n# download rate limit\n")
+		buf.WriteString(dlAgents)
+	}
+
+	if noDebug {
+		buf.WriteString("\n# no debug packages\nOPTIONS+=(!debug)\n")
+	}
+
+	if jobs > 0 {
+		// the last -j given to make wins, other flags are kept
+		fmt.Fprintf(&buf, "\n# build throttling\nMAKEFLAGS=\"${MAKEFLAGS} -j%d\"\n", jobs)
+	}
+
+	merged, err := os.CreateTemp(tempDir, "yippee-makepkg-*.conf")
+	if err != nil {
+		return "", err
+	}
+	defer merged.Close()
+
+	if _, err := merged.Write(buf.Bytes()); err != nil {
+		os.Remove(merged.Name())
+		return "", err
+	}
+
+	// makepkg may run as a different user, keep the file readable
+	if err := merged.Chmod(0o644); err != nil {
+		os.Remove(merged.Name())
+		return "", err
+	}
+
+	return merged.Name(), nil
+}
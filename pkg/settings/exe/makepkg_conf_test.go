@@ -0,0 +1,167 @@
+package exe
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeMakepkgConf(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	base := filepath.Join(dir, "makepkg.conf")
+	fragment := filepath.Join(dir, "extra.conf")
+
+	require.NoError(t, os.WriteFile(base, []byte("CFLAGS=\"-O2\"\nPACKAGER=\"nobody\""), 0o600))
+	require.NoError(t, os.Mkdir(base+".d", 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(base+".d", "rust.conf"), []byte("RUSTFLAGS=\"\""), 0o600))
+	require.NoError(t, os.WriteFile(fragment, []byte("PACKAGER=\"me <me@example.com>\""), 0o600))
+
+	merged, err := mergeMakepkgConf("", base, fragment, "", false, 0)
+	require.NoError(t, err)
+	t.Cleanup(func() { os.Remove(merged) })
+
+	content, err := os.ReadFile(merged)
+	require.NoError(t, err)
+
+	got := string(content)
+	assert.Contains(t, got, "CFLAGS=\"-O2\"")
+	assert.Contains(t, got, "RUSTFLAGS=\"\"")
+	assert.Less(t, strings.Index(got, "PACKAGER=\"nobody\""), strings.Index(got, "PACKAGER=\"me <me@example.com>\""))
+
+	_, err = mergeMakepkgConf("", base, filepath.Join(dir, "missing.conf"), "", false, 0)
+	assert.Error(t, err)
+}
+
+func TestMergeMakepkgConfRateLimit(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	base := filepath.Join(dir, "makepkg.conf")
+
+	require.NoError(t, os.WriteFile(base, []byte(
+		"DLAGENTS=('https::/usr/bin/curl -qgb \"\" -fLC - --retry 3 --retry-delay 3 -o %o %u')"), 0o600))
+
+	merged, err := mergeMakepkgConf("", base, "", "500k", false, 0)
+	require.NoError(t, err)
+	t.Cleanup(func() { os.Remove(merged) })
+
+	content, err := os.ReadFile(merged)
+	require.NoError(t, err)
+
+	got := string(content)
+	assert.Less(t, strings.Index(got, "DLAGENTS=('https::"), strings.Index(got, "--limit-rate 500k"))
+	assert.Contains(t, got, "--limit-rate=500k")
+
+	_, err = mergeMakepkgConf("", base, "", "fast", false, 0)
+	assert.Error(t, err)
+}
+
+func TestMergeMakepkgConfNoDebug(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	base := filepath.Join(dir, "makepkg.conf")
+
+	require.NoError(t, os.WriteFile(base, []byte("OPTIONS=(strip docs !libtool debug lto)"), 0o600))
+	assert.True(t, MakepkgDebugEnabled(base, ""))
+
+	merged, err := mergeMakepkgConf("", base, "", "", true, 0)
+	require.NoError(t, err)
+	t.Cleanup(func() { os.Remove(merged) })
+
+	assert.False(t, MakepkgDebugEnabled(merged, ""))
+}
+
+func TestMakepkgDebugEnabled(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc     string
+		conf     string
+		fragment string
+		want     bool
+	}{
+		{desc: "enabled", conf: "OPTIONS=(strip docs debug lto)", want: true},
+		{desc: "disabled", conf: "OPTIONS=(strip docs !debug lto)", want: false},
+		{desc: "not set", conf: "OPTIONS=(strip docs lto)", want: false},
+		{desc: "multi-line", conf: "OPTIONS=(\n  'strip'\n  'debug'\n)", want: true},
+		{desc: "appended", conf: "OPTIONS=(strip)\nOPTIONS+=(debug)", want: true},
+		{desc: "reassigned", conf: "OPTIONS=(debug)\nOPTIONS=(strip)", want: false},
+		{desc: "commented", conf: "#OPTIONS=(debug)\nOPTIONS=(strip)", want: false},
+		{desc: "fragment disables", conf: "OPTIONS=(debug)", fragment: "OPTIONS+=(!debug)", want: false},
+		{desc: "fragment enables", conf: "OPTIONS=(!debug)", fragment: "OPTIONS=(debug)", want: true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			base := filepath.Join(dir, "makepkg.conf")
+			require.NoError(t, os.WriteFile(base, []byte(tc.conf), 0o600))
+
+			fragment := ""
+			if tc.fragment != "" {
+				fragment = filepath.Join(dir, "extra.conf")
+				require.NoError(t, os.WriteFile(fragment, []byte(tc.fragment), 0o600))
+			}
+
+			assert.Equal(t, tc.want, MakepkgDebugEnabled(base, fragment))
+		})
+	}
+}
+
+func TestMakepkgRunsCheck(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc  string
+		flags []string
+		conf  string
+		want  bool
+	}{
+		{desc: "enabled", conf: "BUILDENV=(!distcc color !ccache check !sign)", want: true},
+		{desc: "disabled", conf: "BUILDENV=(!distcc color !ccache !check !sign)", want: false},
+		{desc: "not set", conf: "BUILDENV=(color)", want: true},
+		{desc: "nocheck flag", flags: []string{"--nocheck"}, conf: "BUILDENV=(check)", want: false},
+		{desc: "check flag", flags: []string{"--check"}, conf: "BUILDENV=(!check)", want: true},
+		{desc: "last flag wins", flags: []string{"--check", "--nocheck"}, conf: "BUILDENV=(check)", want: false},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			base := filepath.Join(t.TempDir(), "makepkg.conf")
+			require.NoError(t, os.WriteFile(base, []byte(tc.conf), 0o600))
+
+			assert.Equal(t, tc.want, MakepkgRunsCheck(tc.flags, base, ""))
+		})
+	}
+}
+
+func TestMergeMakepkgConfJobs(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	base := filepath.Join(dir, "makepkg.conf")
+
+	require.NoError(t, os.WriteFile(base, []byte(`MAKEFLAGS="-j16 -l16"`), 0o600))
+
+	merged, err := mergeMakepkgConf("", base, "", "", false, 2)
+	require.NoError(t, err)
+	t.Cleanup(func() { os.Remove(merged) })
+
+	out, err := exec.Command("bash", "-c", `source "$1" && echo "$MAKEFLAGS"`, "bash", merged).Output()
+	require.NoError(t, err)
+	assert.Equal(t, "-j16 -l16 -j2\n", string(out))
+}
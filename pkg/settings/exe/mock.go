@@ -3,7 +3,9 @@ package exe
 import (
 	"context"
 	"fmt"
+	"io"
 	"os/exec"
+	"strings"
 	"sync"
 
 	"github.com/Jguer/yippee/v12/pkg/settings/parser"
@@ -35,6 +37,106 @@ type MockRunner struct {
 	CaptureCalls   []Call
 	ShowFn         func(cmd *exec.Cmd) error
 	CaptureFn      func(cmd *exec.Cmd) (stdout string, stderr string, err error)
+
+	expectMu     sync.Mutex
+	expectations []*Expectation
+	unexpected   []string
+}
+
+// Expectation is a scripted result of MockRunner for a command, see
+// MockRunner.Expect.
+type Expectation struct {
+	Cmd    string
+	Stdout string
+	Stderr string
+	Err    error
+
+	times int
+	used  int
+}
+
+// Return sets the output and the error of the expected command.
+func (e *Expectation) Return(stdout, stderr string, err error) *Expectation {
+	e.Stdout, e.Stderr, e.Err = stdout, stderr, err
+	return e
+}
+
+// Times sets how many runs of the command are expected, 0 for any number.
+func (e *Expectation) Times(n int) *Expectation {
+	e.times = n
+	return e
+}
+
+// TestingT is the part of testing.T used to report unmet expectations.
+type TestingT interface {
+	Errorf(format string, args ...interface{})
+}
+
+// Expect scripts the result of the next command whose command line starts
+// with cmd, for example "git clone" or "pacman -S". Expectations are matched
+// in the order they were added and are used once unless Times says
+// otherwise. Once an expectation is set, commands that match none fail.
+// ShowFn and CaptureFn take precedence over expectations.
+func (m *MockRunner) Expect(cmd string) *Expectation {
+	m.expectMu.Lock()
+	defer m.expectMu.Unlock()
+
+	expectation := &Expectation{Cmd: cmd, times: 1}
+	m.expectations = append(m.expectations, expectation)
+
+	return expectation
+}
+
+// AssertExpectations reports the expected commands that did not run as
+// often as expected and the commands that were not expected.
+func (m *MockRunner) AssertExpectations(t TestingT) bool {
+	m.expectMu.Lock()
+	defer m.expectMu.Unlock()
+
+	ok := true
+
+	for _, expectation := range m.expectations {
+		if expectation.times > 0 && expectation.used < expectation.times {
+			t.Errorf("expected %q to run %d times, ran %d times",
+				expectation.Cmd, expectation.times, expectation.used)
+			ok = false
+		}
+	}
+
+	for _, cmd := range m.unexpected {
+		t.Errorf("unexpected command %q", cmd)
+		ok = false
+	}
+
+	return ok
+}
+
+// scripted returns the result of the first expectation matching cmd. handled
+// is false when no expectation was set.
+func (m *MockRunner) scripted(cmd *exec.Cmd) (stdout, stderr string, err error, handled bool) {
+	m.expectMu.Lock()
+	defer m.expectMu.Unlock()
+
+	if len(m.expectations) == 0 {
+		return "", "", nil, false
+	}
+
+	cmdLine := strings.Join(cmd.Args, " ")
+
+	for _, expectation := range m.expectations {
+		if expectation.times > 0 && expectation.used >= expectation.times {
+			continue
+		}
+
+		if strings.HasPrefix(cmdLine, expectation.Cmd) {
+			expectation.used++
+			return expectation.Stdout, expectation.Stderr, expectation.Err, true
+		}
+	}
+
+	m.unexpected = append(m.unexpected, cmdLine)
+
+	return "", "", fmt.Errorf("unexpected command: %s", cmdLine), true
 }
 
 func (m *MockBuilder) BuildGPGCmd(ctx context.Context, extraArgs ...string) *exec.Cmd {
@@ -63,9 +165,23 @@ func (m *MockBuilder) BuildMakepkgCmd(ctx context.Context, dir string, extraArgs
 	return res
 }
 
+func (m *MockBuilder) BuildThrottledMakepkgCmd(ctx context.Context, dir string, jobs int, extraArgs ...string) *exec.Cmd {
+	return m.BuildMakepkgCmd(ctx, dir, extraArgs...)
+}
+
+func (m *MockBuilder) BuildInspectorCmd(ctx context.Context, dir, bin string, extraArgs ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, bin, extraArgs...)
+	cmd.Dir = dir
+
+	return cmd
+}
+
 func (m *MockBuilder) AddMakepkgFlag(flag string) {
 }
 
+func (m *MockBuilder) Cleanup() {
+}
+
 func (m *MockBuilder) BuildGitCmd(ctx context.Context, dir string, extraArgs ...string) *exec.Cmd {
 	return exec.CommandContext(ctx, "git", extraArgs...)
 }
@@ -114,6 +230,10 @@ func (m *MockRunner) Capture(cmd *exec.Cmd) (stdout, stderr string, err error) {
 		return m.CaptureFn(cmd)
 	}
 
+	if stdout, stderr, err, ok := m.scripted(cmd); ok {
+		return stdout, stderr, err
+	}
+
 	return "", "", nil
 }
 
@@ -121,6 +241,12 @@ func (m *MockRunner) Show(cmd *exec.Cmd) error {
 	var err error
 	if m.ShowFn != nil {
 		err = m.ShowFn(cmd)
+	} else if _, stderr, errScripted, ok := m.scripted(cmd); ok {
+		err = errScripted
+
+		if cmd.Stderr != nil {
+			_, _ = io.WriteString(cmd.Stderr, stderr)
+		}
 	}
 
 	m.ShowCallsMu.Lock()
@@ -0,0 +1,61 @@
+//go:build !integration
+// +build !integration
+
+package exe
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingT struct {
+	errors []string
+}
+
+func (r *recordingT) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestMockRunnerExpect(t *testing.T) {
+	t.Parallel()
+
+	errClone := errors.New("clone failed")
+
+	runner := &MockRunner{}
+	runner.Expect("git clone").Return("", "fatal", errClone)
+	runner.Expect("git -C").Return("abc\n", "", nil).Times(2)
+
+	_, stderr, err := runner.Capture(exec.Command("git", "clone", "https://aur.archlinux.org/yippee.git"))
+	assert.ErrorIs(t, err, errClone)
+	assert.Equal(t, "fatal", stderr)
+
+	for i := 0; i < 2; i++ {
+		stdout, _, err := runner.Capture(exec.Command("git", "-C", "yippee", "rev-parse", "HEAD"))
+		assert.NoError(t, err)
+		assert.Equal(t, "abc\n", stdout)
+	}
+
+	assert.True(t, runner.AssertExpectations(t))
+
+	assert.Error(t, runner.Show(exec.Command("makepkg", "-si")))
+	assert.Len(t, runner.ShowCalls, 1)
+
+	recorder := &recordingT{}
+	assert.False(t, runner.AssertExpectations(recorder))
+	assert.Equal(t, []string{`unexpected command "makepkg -si"`}, recorder.errors)
+}
+
+func TestMockRunnerUnmetExpectation(t *testing.T) {
+	t.Parallel()
+
+	runner := &MockRunner{}
+	runner.Expect("pacman -S")
+
+	recorder := &recordingT{}
+	assert.False(t, runner.AssertExpectations(recorder))
+	assert.Equal(t, []string{`expected "pacman -S" to run 1 times, ran 0 times`}, recorder.errors)
+}
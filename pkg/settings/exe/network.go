@@ -0,0 +1,27 @@
+package exe
+
+// NetworkEnv returns the environment variables that pass a proxy, the hosts
+// to reach without it and a CA bundle to git, makepkg and the downloaders
+// they run. Empty settings are left out.
+func NetworkEnv(proxy, noProxy, caBundle string) []string {
+	env := make([]string, 0, 10)
+
+	if proxy != "" {
+		// curl ignores HTTP_PROXY, the lower case variant is the one honored
+		for _, name := range [...]string{"http_proxy", "https_proxy", "HTTPS_PROXY", "all_proxy", "ALL_PROXY"} {
+			env = append(env, name+"="+proxy)
+		}
+	}
+
+	if noProxy != "" {
+		env = append(env, "no_proxy="+noProxy, "NO_PROXY="+noProxy)
+	}
+
+	if caBundle != "" {
+		for _, name := range [...]string{"GIT_SSL_CAINFO", "CURL_CA_BUNDLE", "SSL_CERT_FILE"} {
+			env = append(env, name+"="+caBundle)
+		}
+	}
+
+	return env
+}
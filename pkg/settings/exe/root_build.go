@@ -0,0 +1,92 @@
+package exe
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+
+	"github.com/leonelquinteros/gotext"
+)
+
+// Strategies to drop privileges with when building as root.
+const (
+	RootBuildAuto    = "auto"
+	RootBuildSystemd = "systemd"
+	RootBuildNobody  = "nobody"
+	RootBuildError   = "error"
+)
+
+const nobodyUser = "nobody"
+
+// rootBuild is the resolved way commands are de-elevated when running as root.
+type rootBuild struct {
+	strategy string
+	user     *buildUser
+	err      error
+}
+
+// systemdRunAvailable reports whether systemd-run can be found.
+func systemdRunAvailable() bool {
+	_, err := exec.LookPath("systemd-run")
+
+	return err == nil
+}
+
+// resolveRootBuild picks the strategy used to build as root. A configured
+// build user is always preferred.
+func (c *CmdBuilder) resolveRootBuild() *rootBuild {
+	if name := c.buildUserName(); name != "" {
+		buildUser, err := lookupBuildUser(name)
+		if err == nil {
+			return &rootBuild{strategy: "user " + buildUser.name, user: buildUser}
+		}
+
+		c.Log.Warnln(gotext.Get("build user %s not found: %s", name, err))
+	}
+
+	available := c.systemdAvailable
+	if available == nil {
+		available = systemdRunAvailable
+	}
+
+	switch c.RootBuild {
+	case RootBuildSystemd:
+		if !available() {
+			return &rootBuild{strategy: RootBuildError, err: errors.New(
+				gotext.Get("rootbuild is set to systemd but systemd-run is unavailable"))}
+		}
+
+		return &rootBuild{strategy: RootBuildSystemd}
+	case RootBuildNobody:
+		nobody, err := lookupBuildUser(nobodyUser)
+		if err != nil {
+			return &rootBuild{strategy: RootBuildError, err: err}
+		}
+
+		// nobody has no usable home
+		nobody.home = os.TempDir()
+
+		return &rootBuild{strategy: RootBuildNobody, user: nobody}
+	case RootBuildError:
+	default:
+		if available() {
+			return &rootBuild{strategy: RootBuildSystemd}
+		}
+	}
+
+	return &rootBuild{strategy: RootBuildError, err: errors.New(gotext.Get(
+		"refusing to build as root: systemd-run is unavailable. " +
+			"Run yippee as a regular user, set --builduser <user> or use --rootbuild nobody"))}
+}
+
+// rootBuildStrategy resolves the root build strategy once per builder.
+func (c *CmdBuilder) rootBuildStrategy() *rootBuild {
+	c.rootBuildOnce.Do(func() {
+		c.rootBuild = c.resolveRootBuild()
+		if c.Log != nil {
+			c.Log.Debugln("root build strategy:", c.rootBuild.strategy)
+		}
+	})
+
+	return c.rootBuild
+}
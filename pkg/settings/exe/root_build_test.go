@@ -0,0 +1,55 @@
+package exe
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Jguer/yippee/v12/pkg/text"
+)
+
+func TestResolveRootBuild(t *testing.T) {
+	t.Setenv("SUDO_USER", "")
+	t.Setenv("DOAS_USER", "")
+
+	testCases := []struct {
+		desc      string
+		rootBuild string
+		sudoUser  string
+		systemd   bool
+		want      string
+		wantErr   bool
+	}{
+		{desc: "auto with systemd", rootBuild: RootBuildAuto, systemd: true, want: RootBuildSystemd},
+		{desc: "auto without systemd", rootBuild: RootBuildAuto, systemd: false, want: RootBuildError, wantErr: true},
+		{desc: "systemd without systemd", rootBuild: RootBuildSystemd, systemd: false, want: RootBuildError, wantErr: true},
+		{desc: "error with systemd", rootBuild: RootBuildError, systemd: true, want: RootBuildError, wantErr: true},
+		{desc: "nobody", rootBuild: RootBuildNobody, systemd: true, want: RootBuildNobody},
+		{
+			desc: "error ignores the sudo user", rootBuild: RootBuildError, sudoUser: "root",
+			systemd: true, want: RootBuildError, wantErr: true,
+		},
+		{desc: "systemd ignores the sudo user", rootBuild: RootBuildSystemd, sudoUser: "root", systemd: true, want: RootBuildSystemd},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Setenv("SUDO_USER", tc.sudoUser)
+			systemd := tc.systemd
+			builder := &CmdBuilder{
+				RootBuild:        tc.rootBuild,
+				Log:              text.NewLogger(os.Stdout, os.Stderr, os.Stdin, false, "test"),
+				systemdAvailable: func() bool { return systemd },
+			}
+
+			rootBuild := builder.resolveRootBuild()
+			if tc.want == RootBuildNobody && rootBuild.err != nil {
+				t.Skip("no nobody user in this environment")
+			}
+
+			assert.Equal(t, tc.want, rootBuild.strategy)
+			assert.Equal(t, tc.wantErr, rootBuild.err != nil)
+		})
+	}
+}
@@ -46,6 +46,10 @@ type Arguments struct {
 	Op      string
 	Options map[string]*Option
 	Targets []string
+
+	// whether targets were given on the command line when the operation was
+	// not, see ApplyDefaultOp
+	defaultOpTargets bool
 }
 
 func (a *Arguments) String() string {
@@ -64,9 +68,9 @@ func (a *Arguments) CreateOrAppendOption(option string, values ...string) {
 
 func MakeArguments() *Arguments {
 	return &Arguments{
-		"",
-		make(map[string]*Option),
-		make([]string, 0),
+		Op:      "",
+		Options: make(map[string]*Option),
+		Targets: make([]string, 0),
 	}
 }
 
@@ -258,12 +262,8 @@ func (a *Arguments) FormatArgs() (args []string) {
 			continue
 		}
 
-		formattedOption := formatArg(option)
 		for _, value := range arg.Args {
-			args = append(args, formattedOption)
-			if hasParam(option) {
-				args = append(args, value)
-			}
+			args = append(args, formatOption(option, value)...)
 		}
 	}
 
@@ -276,268 +276,39 @@ func (a *Arguments) FormatGlobals() (args []string) {
 			continue
 		}
 
-		formattedOption := formatArg(option)
-
 		for _, value := range arg.Args {
-			args = append(args, formattedOption)
-			if hasParam(option) {
-				args = append(args, value)
-			}
+			args = append(args, formatOption(option, value)...)
 		}
 	}
 
 	return args
 }
 
-func formatArg(arg string) string {
-	if len(arg) > 1 {
-		arg = "--" + arg
-	} else {
-		arg = "-" + arg
+// formatOption returns the arguments passing value to option.
+func formatOption(option, value string) []string {
+	switch {
+	case hasParam(option):
+		return []string{formatArg(option), value}
+	case value != "" && hasOptionalParam(option):
+		return []string{formatArg(option) + "=" + value}
 	}
 
-	return arg
+	return []string{formatArg(option)}
 }
 
-func isArg(arg string) bool {
-	switch arg {
-	case "-", "--":
-	case "ask":
-	case "D", "database":
-	case "Q", "query":
-	case "R", "remove":
-	case "S", "sync":
-	case "T", "deptest":
-	case "U", "upgrade":
-	case "F", "files":
-	case "V", "version":
-	case "h", "help":
-	case "Y", "yippee":
-	case "W", "web":
-	case "P", "show":
-	case "B", "build":
-	case "G", "getpkgbuild":
-	case "b", "dbpath":
-	case "r", "root":
-	case "v", "verbose":
-	case "arch":
-	case "cachedir":
-	case "color":
-	case "config":
-	case "debug":
-	case "gpgdir":
-	case "hookdir":
-	case "logfile":
-	case "noconfirm":
-	case "confirm":
-	case "disable-download-timeout":
-	case "sysroot":
-	case "d", "nodeps":
-	case "assume-installed":
-	case "dbonly":
-	case "noprogressbar":
-	case "numberupgrades":
-	case "noscriptlet":
-	case "p", "print":
-	case "print-format":
-	case "asdeps":
-	case "asexplicit":
-	case "ignore":
-	case "ignoregroup":
-	case "needed":
-	case "overwrite":
-	case "f", "force":
-	case "c", "changelog":
-	case "deps":
-	case "e", "explicit":
-	case "g", "groups":
-	case "i", "info":
-	case "k", "check":
-	case "l", "list":
-	case "m", "foreign":
-	case "n", "native":
-	case "o", "owns":
-	case "file":
-	case "q", "quiet":
-	case "s", "search":
-	case "t", "unrequired":
-	case "u", "upgrades":
-	case "cascade":
-	case "nosave":
-	case "recursive":
-	case "unneeded":
-	case "clean":
-	case "sysupgrade":
-	case "w", "downloadonly":
-	case "y", "refresh":
-	case "x", "regex":
-	case "machinereadable":
-	// yippee options
-	case "aururl":
-	case "aurrpcurl":
-	case "save":
-	case "afterclean", "cleanafter":
-	case "keepsrc":
-	case "devel":
-	case "timeupdate":
-	case "topdown":
-	case "bottomup":
-	case "completioninterval":
-	case "sortby":
-	case "searchby":
-	case "redownload":
-	case "redownloadall":
-	case "noredownload":
-	case "rebuild":
-	case "rebuildall":
-	case "rebuildtree":
-	case "norebuild":
-	case "batchinstall":
-	case "answerclean":
-	case "noanswerclean":
-	case "answerdiff":
-	case "noanswerdiff":
-	case "answeredit":
-	case "noansweredit":
-	case "answerupgrade":
-	case "noanswerupgrade":
-	case "gpgflags":
-	case "mflags":
-	case "gitflags":
-	case "builddir":
-	case "editor":
-	case "editorflags":
-	case "makepkg":
-	case "makepkgconf":
-	case "nomakepkgconf":
-	case "pacman":
-	case "git":
-	case "gpg":
-	case "sudo":
-	case "sudoflags":
-	case "requestsplitn":
-	case "sudoloop":
-	case "provides":
-	case "pgpfetch":
-	case "cleanmenu":
-	case "diffmenu":
-	case "editmenu":
-	case "useask":
-	case "combinedupgrade":
-	case "a", "aur":
-	case "repo":
-	case "removemake":
-	case "noremovemake":
-	case "askremovemake":
-	case "askyesremovemake":
-	case "complete":
-	case "stats":
-	case "news":
-	case "gendb":
-	case "currentconfig":
-	case "defaultconfig":
-	case "singlelineresults":
-	case "doublelineresults":
-	case "separatesources":
-	default:
-		return false
-	}
-
-	return true
-}
-
-func isOp(op string) bool {
-	switch op {
-	case "V", "version":
-	case "D", "database":
-	case "F", "files":
-	case "Q", "query":
-	case "R", "remove":
-	case "S", "sync":
-	case "T", "deptest":
-	case "U", "upgrade":
-	// yippee specific
-	case "Y", "yippee":
-	case "W", "web":
-	case "B", "build":
-	case "P", "show":
-	case "G", "getpkgbuild":
-	default:
-		return false
-	}
-
-	return true
-}
-
-func isGlobal(op string) bool {
-	switch op {
-	case "b", "dbpath":
-	case "r", "root":
-	case "v", "verbose":
-	case "arch":
-	case "cachedir":
-	case "color":
-	case "config":
-	case "debug":
-	case "gpgdir":
-	case "hookdir":
-	case "logfile":
-	case "noconfirm":
-	case "confirm":
-	default:
-		return false
+func formatArg(arg string) string {
+	// stdin and end of options markers are stored as is
+	if arg == "-" || arg == "--" {
+		return arg
 	}
 
-	return true
-}
-
-func hasParam(arg string) bool {
-	switch arg {
-	case "dbpath", "b":
-	case "root", "r":
-	case "sysroot":
-	case "config":
-	case "ignore":
-	case "assume-installed":
-	case "overwrite":
-	case "ask":
-	case "cachedir":
-	case "hookdir":
-	case "logfile":
-	case "ignoregroup":
-	case "arch":
-	case "print-format":
-	case "gpgdir":
-	case "color":
-	// yippee params
-	case "aururl":
-	case "aurrpcurl":
-	case "mflags":
-	case "gpgflags":
-	case "gitflags":
-	case "builddir":
-	case "editor":
-	case "editorflags":
-	case "makepkg":
-	case "makepkgconf":
-	case "pacman":
-	case "git":
-	case "gpg":
-	case "sudo":
-	case "sudoflags":
-	case "requestsplitn":
-	case "answerclean":
-	case "answerdiff":
-	case "answeredit":
-	case "answerupgrade":
-	case "completioninterval":
-	case "sortby":
-	case "searchby":
-	default:
-		return false
+	if len(arg) > 1 {
+		arg = "--" + arg
+	} else {
+		arg = "-" + arg
 	}
 
-	return true
+	return arg
 }
 
 // Parses short hand options such as:
@@ -582,16 +353,23 @@ func (a *Arguments) parseLongOption(arg, param string) (usedNext bool, err error
 		return
 	}
 
-	arg = arg[2:]
+	name, value, hasValue := strings.Cut(arg[2:], "=")
+
+	name, err = resolveLongOption(name)
+	if err != nil {
+		return
+	}
 
-	switch split := strings.SplitN(arg, "=", 2); {
-	case len(split) == 2:
-		err = a.addParam(split[0], split[1])
-	case hasParam(arg):
-		err = a.addParam(arg, param)
+	switch {
+	case hasValue && !hasParam(name) && !hasOptionalParam(name):
+		err = errors.New(gotext.Get("option '%s' doesn't allow an argument", "--"+name))
+	case hasValue:
+		err = a.addParam(name, value)
+	case hasParam(name):
+		err = a.addParam(name, param)
 		usedNext = true
 	default:
-		err = a.AddArg(arg)
+		err = a.AddArg(name)
 	}
 
 	return
@@ -618,8 +396,9 @@ func (a *Arguments) parseStdin() error {
 	return os.Stdin.Close()
 }
 
-func (a *Arguments) Parse() error {
-	args := os.Args[1:]
+// parseCommandLine parses the options and targets in args, without
+// defaulting the operation or reading targets from stdin.
+func (a *Arguments) parseCommandLine(args []string) error {
 	usedNext := false
 
 	for k, arg := range args {
@@ -649,15 +428,110 @@ func (a *Arguments) Parse() error {
 		if err != nil {
 			return err
 		}
+
+		if usedNext && k+1 >= len(args) {
+			return errors.New(gotext.Get("option '%s' requires an argument", arg))
+		}
 	}
 
-	if a.Op == "" {
-		if len(a.Targets) > 0 {
-			a.Op = "Y"
-		} else {
-			if _, err := a.parseShortOption("-Syu", ""); err != nil {
-				return err
+	return nil
+}
+
+// WithoutArg returns the command line args, as given to Parse, without the
+// option short or long, e.g. to run the same command again without
+// refreshing the databases. Values and targets are kept as they are.
+func WithoutArg(args []string, short, long string) []string {
+	out := make([]string, 0, len(args))
+	usedNext := false
+	ended := false
+
+	for _, arg := range args {
+		switch {
+		case usedNext, ended, arg == "-" || !strings.HasPrefix(arg, "-"):
+			usedNext = false
+		case arg == "--":
+			ended = true
+		case strings.HasPrefix(arg, "--"):
+			name, _, hasValue := strings.Cut(arg[2:], "=")
+			if resolved, err := resolveLongOption(name); err == nil {
+				name = resolved
 			}
+
+			if name == long {
+				continue
+			}
+
+			usedNext = !hasValue && hasParam(name)
+		default:
+			kept := "-"
+
+			for k, char := range arg[1:] {
+				if hasParam(string(char)) {
+					kept += arg[k+1:]
+					usedNext = k == len(arg)-2
+
+					break
+				}
+
+				if string(char) != short {
+					kept += string(char)
+				}
+			}
+
+			if kept == "-" {
+				continue
+			}
+
+			arg = kept
+		}
+
+		out = append(out, arg)
+	}
+
+	return out
+}
+
+// ApplyDefaultOp parses args, e.g. "-Syu", when neither an operation nor
+// targets were given and targetsArgs when only targets were, leaving the
+// operation unset for empty ones. Targets read from stdin or --targets-from
+// do not count.
+func (a *Arguments) ApplyDefaultOp(args, targetsArgs string) error {
+	if a.Op != "" {
+		return nil
+	}
+
+	if a.defaultOpTargets {
+		args = targetsArgs
+	}
+
+	if args == "" {
+		return nil
+	}
+
+	_, err := a.parseShortOption(args, "")
+
+	return err
+}
+
+func (a *Arguments) Parse() error {
+	if err := a.parseCommandLine(os.Args[1:]); err != nil {
+		return err
+	}
+
+	// --serve-api alone runs the server rather than the default operation,
+	// which is left to ApplyDefaultOp once the yippee options are known
+	if a.Op == "" && a.ExistsArg("serve-api") {
+		a.Op = "Y"
+	}
+
+	a.defaultOpTargets = len(a.Targets) > 0
+
+	usedStdin := false
+
+	if a.ExistsArg("targets-from") {
+		var err error
+		if usedStdin, err = a.parseTargetsFrom(); err != nil {
+			return err
 		}
 	}
 
@@ -668,6 +542,10 @@ func (a *Arguments) Parse() error {
 
 		a.DelArg("-")
 
+		usedStdin = true
+	}
+
+	if usedStdin {
 		file, err := os.Open("/dev/tty")
 		if err != nil {
 			return err
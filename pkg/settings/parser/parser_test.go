@@ -5,6 +5,7 @@ package parser
 
 import (
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -263,6 +264,16 @@ func TestArguments_FormatArgs(t *testing.T) {
 			Options: map[string]*Option{"overwrite": {Args: []string{"/tmp/a", "/tmp/b", "/tmp/c"}}, "needed": {Args: []string{""}}},
 			Targets: []string{},
 		}, wantArgs: []string{"-Y", "--overwrite", "/tmp/a", "--overwrite", "/tmp/b", "--overwrite", "/tmp/c", "--needed"}},
+		{name: "optional value", fields: fields{
+			Op:      "S",
+			Options: map[string]*Option{"removemake": {Args: []string{"ask-per-package"}}},
+			Targets: []string{},
+		}, wantArgs: []string{"-S", "--removemake=ask-per-package"}},
+		{name: "optional value not given", fields: fields{
+			Op:      "S",
+			Options: map[string]*Option{"removemake": {Args: []string{""}}},
+			Targets: []string{},
+		}, wantArgs: []string{"-S", "--removemake"}},
 	}
 	for _, tt := range tests {
 		tt := tt
@@ -371,3 +382,101 @@ func TestArguments_ParseStdin_broken_pipe(t *testing.T) {
 	err = args.parseStdin()
 	assert.Error(t, err)
 }
+
+// FuzzParseCommandLine checks that any command line either fails to parse or
+// survives a round trip through FormatGlobals and FormatArgs. Arguments are
+// separated by NUL bytes.
+func FuzzParseCommandLine(f *testing.F) {
+	for _, seed := range []string{
+		"-Syu",
+		"-S\x00--needed\x00yippee",
+		"-Sbx\x00--",
+		"--dbpath=/tmp\x00-Qi\x00--\x00-not-an-option",
+		"-Rns\x00yippee-git",
+		"--config\x00/etc/pacman.conf\x00-Ss\x00foo",
+		"--sy\x00--refresh\x00-yy",
+		"--ignore\x00a,b,c\x00-Su",
+		"-Y\x00--gendb",
+		"-S\x00--removemake=ask-per-package\x00--removemake\x00yippee",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		a := MakeArguments()
+		if err := a.parseCommandLine(strings.Split(input, "\x00")); err != nil {
+			return
+		}
+
+		args := append(a.FormatGlobals(), a.FormatArgs()...)
+		args = append(args, "--")
+		args = append(args, a.Targets...)
+
+		b := MakeArguments()
+		require.NoError(t, b.parseCommandLine(args), args)
+
+		a.DelArg("--")
+		b.DelArg("--")
+
+		assert.Equal(t, a.Op, b.Op)
+		assert.Equal(t, a.Options, b.Options)
+		assert.Equal(t, a.Targets, b.Targets)
+	})
+}
+
+func TestArguments_ApplyDefaultOp(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name        string
+		op          string
+		targets     []string
+		wantOp      string
+		wantOptions []string
+	}{
+		{name: "bare", wantOp: "S", wantOptions: []string{"y", "u"}},
+		{name: "targets", targets: []string{"foo"}, wantOp: "Y"},
+		{name: "op given", op: "Q", targets: []string{"foo"}, wantOp: "Q"},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			a := MakeArguments()
+			a.Op = tc.op
+			a.AddTarget(tc.targets...)
+			a.defaultOpTargets = tc.op == "" && len(tc.targets) > 0
+
+			require.NoError(t, a.ApplyDefaultOp("-Syu", "-Y"))
+			assert.Equal(t, tc.wantOp, a.Op)
+			assert.Len(t, a.Options, len(tc.wantOptions))
+			for _, option := range tc.wantOptions {
+				assert.True(t, a.ExistsArg(option), option)
+			}
+		})
+	}
+}
+
+func TestWithoutArg(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{name: "short", args: []string{"-Syu"}, want: []string{"-Su"}},
+		{name: "twice", args: []string{"-Syyu", "--needed"}, want: []string{"-Su", "--needed"}},
+		{name: "alone", args: []string{"-S", "-y", "-u"}, want: []string{"-S", "-u"}},
+		{name: "long", args: []string{"--sync", "--refresh", "--sysupgrade"}, want: []string{"--sync", "--sysupgrade"}},
+		{name: "short value", args: []string{"-Syub", "/tmp/y"}, want: []string{"-Sub", "/tmp/y"}},
+		{name: "attached value", args: []string{"-Syb/tmp/y"}, want: []string{"-Sb/tmp/y"}},
+		{name: "long value", args: []string{"-Syu", "--dbpath", "-y"}, want: []string{"-Su", "--dbpath", "-y"}},
+		{name: "targets", args: []string{"-Sy", "y", "--", "-y"}, want: []string{"-S", "y", "--", "-y"}},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.want, WithoutArg(tc.args, "y", "refresh"))
+		})
+	}
+}
@@ -0,0 +1,347 @@
+package parser
+
+import "strings"
+
+// OptionInfo describes an operation or option understood by the parser.
+type OptionInfo struct {
+	// Short is the single letter form, empty when there is none.
+	Short string
+	// Long is the name used after a double dash.
+	Long string
+	// Aliases are alternative long names accepted for the option.
+	Aliases []string
+	// Value names the argument taken by the option, empty for flags.
+	Value string
+	// OptionalValue names the argument a flag takes when given as
+	// --option=value.
+	OptionalValue string
+	// Description is a one line summary of the option.
+	Description string
+	// Operation is set for operations such as -S or -Y.
+	Operation bool
+	// Global options are passed on to every pacman invocation.
+	Global bool
+}
+
+// TakesValue reports whether the option expects an argument.
+func (o OptionInfo) TakesValue() bool {
+	return o.Value != ""
+}
+
+// Names returns every name the option is known by, short form first.
+func (o OptionInfo) Names() []string {
+	names := make([]string, 0, len(o.Aliases)+2)
+	if o.Short != "" {
+		names = append(names, o.Short)
+	}
+
+	names = append(names, o.Long)
+
+	return append(names, o.Aliases...)
+}
+
+// Usage returns the option as shown in a usage line, e.g. "-b --dbpath <path>".
+func (o OptionInfo) Usage() string {
+	var usage strings.Builder
+
+	if o.Short != "" {
+		usage.WriteString("-" + o.Short + " ")
+	}
+
+	usage.WriteString("--" + o.Long)
+
+	if o.Value != "" {
+		usage.WriteString(" <" + o.Value + ">")
+	}
+
+	if o.OptionalValue != "" {
+		usage.WriteString("[=<" + o.OptionalValue + ">]")
+	}
+
+	return usage.String()
+}
+
+// registry is the single source of truth for the operations and options
+// accepted on the command line, pacman's included.
+var registry = []OptionInfo{
+	{Long: "ask", Value: "number", Description: "Pass an answer to pacman questions"},
+	{Short: "D", Long: "database", Operation: true, Description: "Operate on the package database"},
+	{Short: "Q", Long: "query", Operation: true, Description: "Query the package database"},
+	{Short: "R", Long: "remove", Operation: true, Description: "Remove packages from the system"},
+	{Short: "S", Long: "sync", Operation: true, Description: "Synchronize packages"},
+	{Short: "T", Long: "deptest", Operation: true, Description: "Check dependencies"},
+	{Short: "U", Long: "upgrade", Operation: true, Description: "Upgrade or add packages from files"},
+	{Short: "F", Long: "files", Operation: true, Description: "Query the files database"},
+	{Short: "V", Long: "version", Operation: true, Description: "Display version and exit"},
+	{Short: "h", Long: "help", Description: "Display help for an operation"},
+	{Short: "Y", Long: "yippee", Operation: true, Description: "Perform yippee specific operations"},
+	{Short: "W", Long: "web", Operation: true, Description: "Perform web operations on AUR packages"},
+	{Short: "P", Long: "show", Operation: true, Description: "Perform yippee specific print operations"},
+	{Short: "B", Long: "build", Operation: true, Description: "Build a PKGBUILD in a given directory"},
+	{Short: "G", Long: "getpkgbuild", Operation: true, Description: "Download PKGBUILDs from ABS or AUR"},
+	{Short: "b", Long: "dbpath", Value: "path", Global: true, Description: "Set an alternate database location"},
+	{Short: "r", Long: "root", Value: "path", Global: true, Description: "Set an alternate installation root"},
+	{Short: "v", Long: "verbose", Global: true, Description: "Be verbose"},
+	{Long: "arch", Value: "arch", Global: true, Description: "Set an alternate architecture"},
+	{Long: "cachedir", Value: "dir", Global: true, Description: "Set an alternate package cache location"},
+	{Long: "color", Value: "when", Global: true, Description: "Colorize the output"},
+	{Long: "config", Value: "file", Global: true, Description: "pacman.conf file to use"},
+	{Long: "debug", Global: true, Description: "Display debug messages"},
+	{Long: "gpgdir", Value: "dir", Global: true, Description: "Set an alternate home directory for GnuPG"},
+	{Long: "hookdir", Value: "dir", Global: true, Description: "Set an alternate hook location"},
+	{Long: "logfile", Value: "file", Global: true, Description: "Set an alternate log file"},
+	{Long: "noconfirm", Global: true, Description: "Do not ask for any confirmation"},
+	{Long: "confirm", Global: true, Description: "Always ask for confirmation"},
+	{Long: "disable-download-timeout", Description: "Use relaxed timeouts for downloads"},
+	{Long: "sysroot", Value: "dir", Description: "Operate on a mounted guest system"},
+	{Short: "d", Long: "nodeps", Description: "Skip dependency version checks"},
+	{Long: "assume-installed", Value: "package=version", Description: "Add a virtual package to satisfy dependencies"},
+	{Long: "dbonly", Description: "Only modify database entries, not package files"},
+	{Long: "noprogressbar", Description: "Do not show a progress bar when downloading files"},
+	{Long: "numberupgrades", Description: "Number the packages of the upgrade menu"},
+	{Long: "noscriptlet", Description: "Do not execute the install scriptlet if one exists"},
+	{Short: "p", Long: "print", Description: "Print pkgbuild of packages"},
+	{Long: "print-format", Value: "string", Description: "Specify how the targets should be printed"},
+	{Long: "asdeps", Description: "Install packages as non-explicitly installed"},
+	{Long: "asexplicit", Description: "Install packages as explicitly installed"},
+	{Long: "ignore", Value: "package", Description: "Ignore a package upgrade"},
+	{Long: "ignoregroup", Value: "group", Description: "Ignore a group upgrade"},
+	{Long: "needed", Description: "Do not reinstall up to date packages"},
+	{Long: "overwrite", Value: "glob", Description: "Overwrite conflicting files"},
+	{Short: "f", Long: "force", Description: "Force download for existing ABS packages"},
+	{Long: "vars", Description: "Print a summary of the PKGBUILD variables with -p"},
+	{Short: "c", Long: "changelog", Description: "View the changelog of a package"},
+	{Long: "deps", Description: "List packages installed as dependencies"},
+	{Short: "e", Long: "explicit", Description: "List packages explicitly installed"},
+	{Short: "g", Long: "groups", Description: "View all members of a package group"},
+	{Short: "i", Long: "info", Description: "View package information"},
+	{Short: "k", Long: "check", Description: "Check that package files exist"},
+	{Short: "l", Long: "list", Description: "List the contents of a package or repository"},
+	{Short: "m", Long: "foreign", Description: "List installed packages not found in sync databases"},
+	{Short: "n", Long: "native", Description: "List installed packages only found in sync databases"},
+	{Short: "o", Long: "owns", Description: "Query the package that owns a file"},
+	{Long: "file", Description: "Query a package file instead of the database"},
+	{Short: "q", Long: "quiet", Description: "Show less information"},
+	{Short: "s", Long: "search", Description: "Search packages for matching strings"},
+	{Short: "t", Long: "unrequired", Description: "List packages not required by any package"},
+	{Short: "u", Long: "upgrades", Description: "List outdated packages"},
+	{Long: "aur-only", Description: "Only list AUR updates"},
+	{Long: "devel-only", Description: "Only list development package updates"},
+	{Long: "cascade", Description: "Remove packages and all packages that depend on them"},
+	{Long: "nosave", Description: "Remove configuration files"},
+	{Long: "recursive", Description: "Remove unnecessary dependencies"},
+	{Long: "unneeded", Description: "Remove unneeded packages"},
+	{Long: "clean", Description: "Remove unneeded dependencies"},
+	{Long: "sysupgrade", Description: "Upgrade installed packages"},
+	{Short: "w", Long: "downloadonly", Description: "Download packages but do not install or build anything"},
+	{Short: "y", Long: "refresh", Description: "Download fresh package databases"},
+	{Short: "x", Long: "regex", Description: "Enable searching using regular expressions"},
+	{Long: "machinereadable", Description: "Produce machine-readable output"},
+	// yippee options
+	{Long: "aururl", Value: "url", Description: "Set an alternative AUR URL"},
+	{Long: "aurrpcurl", Value: "url", Description: "Set an alternative URL for the AUR /rpc endpoint"},
+	{Long: "proxy", Value: "url", Description: "HTTP(S) or SOCKS5 proxy for network access"},
+	{Long: "noproxy", Value: "hosts", Description: "Comma separated hosts to reach without the proxy"},
+	{Long: "cabundle", Value: "file", Description: "CA certificates to trust in addition to the system ones"},
+	{Long: "maxconcurrentdownloads", Value: "n", Description: "Number of packages to download sources for in parallel"},
+	{Long: "downloadratelimit", Value: "rate", Description: "Limit the bandwidth of each source download"},
+	{Long: "save", Description: "Save the following options back to the config file"},
+	{Long: "cleanafter", Aliases: []string{"afterclean"}, Description: "Remove package sources after successful install"},
+	{Long: "keepsrc", Description: "Keep pkg/ and src/ after building packages"},
+	{Long: "devel", Description: "Check development packages during sysupgrade"},
+	{Long: "devellog", Description: "Show new commits of development packages in the upgrade menu"},
+	{Long: "devel-strict", Description: "Only use commit hashes to decide devel package updates"},
+	{Long: "timeupdate", Description: "Check packages' AUR page for changes during sysupgrade"},
+	{Long: "selfupgradefirst", Description: "Offer to upgrade yippee itself before the rest of sysupgrade"},
+	{Long: "noselfupgradefirst", Description: "Upgrade yippee along with the other packages"},
+	{Long: "ignorerepo", Value: "repos", Description: "Exclude the packages of these repositories from sysupgrade"},
+	{Long: "newsfeeds", Value: "urls", Description: "RSS or Atom feeds printed by -Pw"},
+	{Long: "newsonupgrade", Value: "gate|show|off", Description: "Print unread news before sysupgrade, gate requires acknowledging it"},
+	{Long: "defaultop", Value: "sysupgrade|upgrades|help", Description: "Operation run when neither an operation nor targets are given"},
+	{Long: "defaulttargetsop", Value: "menu|search|install|help", Description: "Operation run when only targets are given"},
+	{Long: "dateformat", Value: "format", Description: "Format of printed dates: iso or a Go time layout"},
+	{Long: "plain", Description: "No color or progress bars from yippee, pacman and makepkg"},
+	{Long: "noplain", Description: "Print color and progress bars as configured"},
+	{Long: "verbosepkglists", Description: "List the packages to install and upgrade in a table"},
+	{Long: "noverbosepkglists", Description: "List the packages to install and upgrade inline"},
+	{Long: "loglevels", Value: "name=level", Description: "Set the log level and file of parts of yippee"},
+	{Long: "nologlevels", Description: "Log every part of yippee at the same level"},
+	{Long: "topdown", Description: "Shows repository's packages first and then AUR's"},
+	{Long: "bottomup", Description: "Shows AUR's packages first and then repository's"},
+	{Long: "completioninterval", Value: "n", Description: "Time in days to refresh completion cache"},
+	{Long: "dbmaxage", Value: "days", Description: "Warn before builds when sync databases are older"},
+	{Long: "sortby", Value: "field", Description: "Sort AUR results by a specific field during search"},
+	{Long: "searchby", Value: "field", Description: "Search for packages using a specified field"},
+	{Long: "redownload", Description: "Always download pkgbuilds of targets"},
+	{Long: "redownloadall", Description: "Always download pkgbuilds of all AUR packages"},
+	{Long: "noredownload", Description: "Skip pkgbuild download if in cache and up to date"},
+	{Long: "rebuild", Description: "Always build target packages"},
+	{Long: "rebuildall", Description: "Always build all AUR packages"},
+	{Long: "rebuildtree", Description: "Always build all AUR packages even if installed"},
+	{Long: "norebuild", Description: "Skip package build if in cache and up to date"},
+	{Long: "batchinstall", Description: "Build multiple AUR packages then install them together"},
+	{Long: "answerclean", Value: "a", Description: "Set a predetermined answer for the clean build menu"},
+	{Long: "noanswerclean", Description: "Unset the answer for the clean build menu"},
+	{Long: "answerdiff", Value: "a", Description: "Set a predetermined answer for the diff menu"},
+	{Long: "noanswerdiff", Description: "Unset the answer for the edit diff menu"},
+	{Long: "answeredit", Value: "a", Description: "Set a predetermined answer for the edit pkgbuild menu"},
+	{Long: "noansweredit", Description: "Unset the answer for the edit pkgbuild menu"},
+	{Long: "answerupgrade", Value: "a", Description: "Set a predetermined answer for the upgrade menu"},
+	{Long: "noanswerupgrade", Description: "Unset the answer for the upgrade menu"},
+	{Long: "gpgflags", Value: "flags", Description: "Pass arguments to gpg"},
+	{Long: "mflags", Value: "flags", Description: "Pass arguments to makepkg"},
+	{Long: "gitflags", Value: "flags", Description: "Pass arguments to git"},
+	{Long: "pacmansyncflags", Value: "flags", Description: "Pass arguments to pacman for -S installs and upgrades"},
+	{Long: "pacmanupgradeflags", Value: "flags", Description: "Pass arguments to pacman for -U installs of built packages"},
+	{Long: "pacmandatabaseflags", Value: "flags", Description: "Pass arguments to pacman for -D install reason changes"},
+	{Long: "pacmanremoveflags", Value: "flags", Description: "Pass arguments to pacman for -R removals"},
+	{Long: "builddir", Value: "dir", Description: "Directory used to download and run PKGBUILDS"},
+	{Long: "editor", Value: "file", Description: "Editor to use when editing PKGBUILDs"},
+	{Long: "editorflags", Value: "flags", Description: "Pass arguments to editor"},
+	{Long: "editfiles", Value: "exts", Description: "Extensions of build files to include in the edit menu"},
+	{Long: "makepkg", Value: "file", Description: "makepkg command to use"},
+	{Long: "makepkgconf", Value: "file", Description: "makepkg.conf file to use"},
+	{Long: "nomakepkgconf", Description: "Use the default makepkg.conf"},
+	{Long: "makepkgconfextra", Value: "file", Description: "makepkg.conf fragment to overlay on makepkg.conf"},
+	{Long: "nomakepkgconfextra", Description: "Do not overlay a makepkg.conf fragment"},
+	{Long: "no-debug", Description: "Do not build debug packages enabled in makepkg.conf"},
+	{Long: "keep-debug", Description: "Build debug packages when enabled in makepkg.conf"},
+	{Long: "builduser", Value: "user", Description: "Unprivileged user to build as when running as root"},
+	{Long: "nobuilduser", Description: "Build as the sudo/doas caller when running as root"},
+	{Long: "rootbuild", Value: "mode", Description: "Drop root without a build user: auto/systemd/nobody/error"},
+	{Long: "pacman", Value: "file", Description: "pacman command to use"},
+	{Long: "git", Value: "file", Description: "git command to use"},
+	{Long: "gpg", Value: "file", Description: "gpg command to use"},
+	{Long: "sudo", Value: "file", Description: "sudo command to use"},
+	{Long: "sudoflags", Value: "flags", Description: "Pass arguments to sudo"},
+	{Long: "requestsplitn", Value: "n", Description: "Max amount of packages to query per AUR request"},
+	{Long: "sudoloop", Description: "Loop sudo calls in the background to avoid timeout"},
+	{Long: "throttlebuilds", Description: "Limit makepkg jobs based on available memory"},
+	{Long: "nothrottlebuilds", Description: "Do not limit makepkg jobs based on available memory"},
+	{Long: "memorylimit", Value: "n", Description: "Memory in MiB to budget per make job when throttling"},
+	{Long: "shallowclone", Description: "Clone PKGBUILD repositories with --depth 1"},
+	{Long: "noshallowclone", Description: "Clone the full history of PKGBUILD repositories"},
+	{Long: "pkgbuildfetch", Value: "cgit|git", Description: "Fetch AUR PKGBUILDs for -Gp through cgit or git"},
+	{Long: "pkgbuildclone", Value: "git|snapshot", Description: "Clone AUR PKGBUILD repositories with git or from snapshots first"},
+	{Long: "gcinterval", Value: "n", Description: "Days between git gc runs on cached PKGBUILD repos"},
+	{Long: "worktrees", Description: "Build AUR packages in a git worktree per version"},
+	{Long: "noworktrees", Description: "Build AUR packages in their PKGBUILD clone"},
+	{Long: "versionedbuilddirs", Description: "Build AUR packages in a directory per version and keep old builds"},
+	{Long: "noversionedbuilddirs", Description: "Do not keep a build directory per version"},
+	{Long: "keepversions", Value: "n", Description: "Number of versioned build directories to keep per package"},
+	{Long: "keepversionsdays", Value: "days", Description: "Days after which old versioned build directories are removed"},
+	{Long: "confirmupfront", Description: "Ask every question before downloading and building"},
+	{Long: "noconfirmupfront", Description: "Ask questions when they come up"},
+	{Long: "json", Description: "Print transaction warnings, -Qu updates, -Si info and -Ps stats as JSON"},
+	{Long: "record", Value: "dir", Description: "Record the AUR requests and the databases to a directory"},
+	{Long: "replay", Value: "dir", Description: "Resolve targets against a recording instead of this system"},
+	{Long: "dry-run", Description: "Print the install plan without downloading, building or installing"},
+	{Long: "output", Value: "text|ndjson", Description: "Also write progress events as newline-delimited JSON with ndjson"},
+	{Long: "outputfd", Value: "fd", Description: "File descriptor the --output=ndjson events are written to"},
+	{Long: "timings", Description: "Print how long each phase of the run took"},
+	{Long: "notimings", Description: "Do not print a timing report"},
+	{Long: "provides", Description: "Look for matching providers when searching for packages"},
+	{Long: "providerpolicy", Value: "policy", Description: "Pick repo or AUR providers: repo-first/aur-if-installed/ask"},
+	{Long: "pgpfetch", Description: "Prompt to import PGP keys from PKGBUILDs"},
+	{Long: "strictchecksums", Description: "Fail when a source is not verified by a checksum"},
+	{Long: "nostrictchecksums", Description: "Warn when a source is not verified by a checksum"},
+	{Long: "inspectors", Value: "names", Description: "Inspect built packages with namcap or other linters"},
+	{Long: "noinspectors", Description: "Do not inspect built packages"},
+	{Long: "inspectblock", Description: "Do not install packages the inspectors find errors in"},
+	{Long: "noinspectblock", Description: "Install packages whatever the inspectors find"},
+	{Long: "cleanmenu", Description: "Give the option to clean build PKGBUILDS"},
+	{Long: "diffmenu", Description: "Give the option to show diffs for build files"},
+	{Long: "editmenu", Description: "Give the option to edit/view PKGBUILDS"},
+	{Long: "useask", Description: "Automatically resolve conflicts using pacman's ask flag"},
+	{Long: "combinedupgrade", Description: "Refresh then perform the repo and AUR upgrade together"},
+	{Short: "a", Long: "aur", Description: "Assume targets are from the AUR"},
+	{Long: "repo", Description: "Assume targets are from the repositories"},
+	{Long: "targets-from", Value: "file", Description: "Read targets from a file, - for stdin"},
+	{Long: "removemake", OptionalValue: "mode", Description: "Remove makedepends after install"},
+	{Long: "noremovemake", Description: "Don't remove makedepends after install"},
+	{Long: "askremovemake", Description: "Ask to remove makedepends after install"},
+	{Long: "askyesremovemake", Description: "Ask to remove makedepends after install(\"Y\" as default"},
+	{Long: "complete", Description: "Used for completions"},
+	{Long: "stats", Description: "Display system package statistics"},
+	{Long: "news", Description: "Print arch news"},
+	{Long: "metrics", Value: "path", Description: "Write update status metrics for node_exporter"},
+	{Long: "graph", Description: "Print the dependency graph of the targets"},
+	{Long: "graph-format", Value: "format", Description: "Format of the --graph output: dot, json or mermaid"},
+	{Long: "gendb", Description: "Generates development package DB used for updating"},
+	{Long: "refresh-pkgbuilds", Description: "Pull every cached PKGBUILD repository in the build dir"},
+	{Long: "sync-manifest", Value: "file", Description: "Install the packages listed in a manifest"},
+	{Long: "install-timer", Description: "Install a systemd user timer checking for updates daily"},
+	{Long: "remove-timer", Description: "Remove the systemd user timer"},
+	{Long: "forget-providers", Description: "Forget the remembered provider choices"},
+	{Long: "note", Description: "Keep a note on an installed foreign package"},
+	{Long: "export-notes", Value: "file", Description: "Write the package notes to a file, - for stdout"},
+	{Long: "import-notes", Value: "file", Description: "Read package notes from a file, - for stdin"},
+	{Long: "serve-api", Value: "addr", Description: "Serve a read-only HTTP API on addr, e.g. 127.0.0.1:8390"},
+	{Long: "dbus", Description: "Export update checks on the D-Bus session bus"},
+	{Long: "prune", Description: "Remove explicit packages missing from the manifest"},
+	{Long: "maintainer", Value: "name", Description: "List the AUR packages of a maintainer to install from"},
+	{Long: "currentconfig", Description: "Print current yippee configuration"},
+	{Long: "defaultconfig", Description: "Print default yippee configuration"},
+	{Long: "singlelineresults", Description: "List each search result on its own line"},
+	{Long: "doublelineresults", Description: "List each search result on two lines, like pacman"},
+	{Long: "separatesources", Description: "Show repository and AUR results separately"},
+	{Long: "vote", Description: "Vote for AUR packages"},
+	{Long: "unvote", Description: "Remove the vote for AUR packages"},
+	{Long: "flag", Description: "Flag AUR packages out-of-date"},
+	{Long: "comment", Value: "text", Description: "Comment sent when flagging, e.g. the new upstream version"},
+}
+
+var registryByName = indexRegistry(registry)
+
+func indexRegistry(options []OptionInfo) map[string]OptionInfo {
+	byName := make(map[string]OptionInfo, len(options)*2)
+
+	for _, option := range options {
+		for _, name := range option.Names() {
+			byName[name] = option
+		}
+	}
+
+	return byName
+}
+
+// Options returns every registered operation and option in registry order.
+func Options() []OptionInfo {
+	options := make([]OptionInfo, len(registry))
+	copy(options, registry)
+
+	return options
+}
+
+// LookupOption returns the operation or option known by name, short or long.
+func LookupOption(name string) (OptionInfo, bool) {
+	option, ok := registryByName[name]
+
+	return option, ok
+}
+
+func isArg(arg string) bool {
+	if arg == "-" || arg == "--" {
+		return true
+	}
+
+	_, ok := registryByName[arg]
+
+	return ok
+}
+
+func isOp(op string) bool {
+	return registryByName[op].Operation
+}
+
+func isGlobal(op string) bool {
+	return registryByName[op].Global
+}
+
+func hasParam(arg string) bool {
+	return registryByName[arg].TakesValue()
+}
+
+func hasOptionalParam(arg string) bool {
+	return registryByName[arg].OptionalValue != ""
+}
@@ -0,0 +1,49 @@
+//go:build !integration
+// +build !integration
+
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistryNamesUnique(t *testing.T) {
+	t.Parallel()
+
+	seen := make(map[string]string)
+
+	for _, option := range Options() {
+		assert.NotEmpty(t, option.Description, option.Long)
+
+		for _, name := range option.Names() {
+			if other, ok := seen[name]; ok {
+				t.Errorf("%s is registered by both --%s and --%s", name, other, option.Long)
+			}
+
+			seen[name] = option.Long
+		}
+	}
+}
+
+func TestLookupOption(t *testing.T) {
+	t.Parallel()
+
+	option, ok := LookupOption("b")
+	assert.True(t, ok)
+	assert.Equal(t, "-b --dbpath <path>", option.Usage())
+	assert.True(t, option.Global)
+
+	option, ok = LookupOption("afterclean")
+	assert.True(t, ok)
+	assert.Equal(t, "cleanafter", option.Long)
+
+	option, ok = LookupOption("S")
+	assert.True(t, ok)
+	assert.True(t, option.Operation)
+	assert.False(t, option.TakesValue())
+
+	_, ok = LookupOption("zorg")
+	assert.False(t, ok)
+}
@@ -0,0 +1,98 @@
+package parser
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/leonelquinteros/gotext"
+)
+
+// longOptions returns the sorted long forms of the known options.
+func longOptions() []string {
+	options := make([]string, 0, len(registry))
+
+	for _, option := range registry {
+		options = append(options, option.Long)
+		options = append(options, option.Aliases...)
+	}
+
+	sort.Strings(options)
+
+	return options
+}
+
+// resolveLongOption returns the long option named by name. Like getopt, an
+// unambiguous prefix of a long option is accepted for the full option.
+func resolveLongOption(name string) (string, error) {
+	if isArg(name) {
+		return name, nil
+	}
+
+	if name == "" {
+		return "", errors.New(gotext.Get("invalid option '%s'", "--"))
+	}
+
+	matches := make([]string, 0)
+
+	for _, option := range longOptions() {
+		if strings.HasPrefix(option, name) {
+			matches = append(matches, "--"+option)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		if suggestion := suggestOption(name); suggestion != "" {
+			return "", errors.New(gotext.Get("invalid option '%s', did you mean '%s'?", "--"+name, "--"+suggestion))
+		}
+
+		return "", errors.New(gotext.Get("invalid option '%s'", "--"+name))
+	case 1:
+		return matches[0][2:], nil
+	default:
+		return "", errors.New(gotext.Get("option '%s' is ambiguous; possibilities: %s",
+			"--"+name, strings.Join(matches, " ")))
+	}
+}
+
+// suggestOption returns the known long option closest to name, or an empty
+// string if none of them is close enough to be a likely typo.
+func suggestOption(name string) string {
+	best, bestDistance := "", max(2, len(name)/3)+1
+
+	for _, option := range longOptions() {
+		if distance := editDistance(name, option); distance < bestDistance {
+			best, bestDistance = option, distance
+		}
+	}
+
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+
+		prev, cur = cur, prev
+	}
+
+	return prev[len(b)]
+}
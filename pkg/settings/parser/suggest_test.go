@@ -0,0 +1,76 @@
+//go:build !integration
+// +build !integration
+
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveLongOption(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		want    string
+		wantErr string
+	}{
+		{name: "builddir", want: "builddir"},
+		{name: "devel", want: "devel"},
+		{name: "sudol", want: "sudoloop"},
+		{name: "bulddir", wantErr: "invalid option '--bulddir', did you mean '--builddir'?"},
+		{name: "zorgzorg", wantErr: "invalid option '--zorgzorg'"},
+		{name: "answer", wantErr: "option '--answer' is ambiguous; possibilities: --answerclean --answerdiff --answeredit --answerupgrade"},
+		{name: "", wantErr: "invalid option '--'"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := resolveLongOption(tc.name)
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestArguments_parseLongOption(t *testing.T) {
+	t.Parallel()
+
+	args := MakeArguments()
+
+	usedNext, err := args.parseLongOption("--sudol", "yippee")
+	require.NoError(t, err)
+	assert.False(t, usedNext)
+	assert.True(t, args.ExistsArg("sudoloop"))
+
+	usedNext, err = args.parseLongOption("--buildd", "/tmp/build")
+	require.NoError(t, err)
+	assert.True(t, usedNext)
+	assert.Equal(t, "/tmp/build", args.Options["builddir"].First())
+
+	_, err = args.parseLongOption("--needed=yes", "")
+	assert.EqualError(t, err, "option '--needed' doesn't allow an argument")
+
+	_, err = args.parseLongOption("--neded", "")
+	assert.EqualError(t, err, "invalid option '--neded', did you mean '--needed'?")
+}
+
+func TestEditDistance(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, 0, editDistance("devel", "devel"))
+	assert.Equal(t, 1, editDistance("bulddir", "builddir"))
+	assert.Equal(t, 3, editDistance("kitten", "sitting"))
+	assert.Equal(t, 5, editDistance("", "devel"))
+}
@@ -0,0 +1,71 @@
+package parser
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/leonelquinteros/gotext"
+)
+
+// readTargets reads newline separated targets. Blank lines and everything
+// following a '#' are ignored.
+func readTargets(r io.Reader) ([]string, error) {
+	targets := make([]string, 0)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		if line = strings.TrimSpace(line); line != "" {
+			targets = append(targets, line)
+		}
+	}
+
+	return targets, scanner.Err()
+}
+
+// parseTargetsFrom adds the targets listed in the files given to
+// --targets-from, '-' reading them from stdin. It reports whether stdin was
+// consumed.
+func (a *Arguments) parseTargetsFrom() (usedStdin bool, err error) {
+	switch a.Op {
+	case "S", "sync", "R", "remove", "G", "getpkgbuild":
+	default:
+		return false, errors.New(gotext.Get("'%s' is only valid with %s", "--targets-from", "-S, -R, -G"))
+	}
+
+	for _, path := range a.GetArgs("targets-from") {
+		var targets []string
+
+		if path == "-" {
+			usedStdin = true
+			targets, err = readTargets(os.Stdin)
+		} else {
+			targets, err = ReadTargetsFile(path)
+		}
+
+		if err != nil {
+			return usedStdin, err
+		}
+
+		a.AddTarget(targets...)
+	}
+
+	a.DelArg("targets-from")
+
+	return usedStdin, nil
+}
+
+// ReadTargetsFile reads the targets listed in a file in the format accepted
+// by --targets-from.
+func ReadTargetsFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return readTargets(file)
+}
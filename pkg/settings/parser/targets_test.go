@@ -0,0 +1,60 @@
+//go:build !integration
+// +build !integration
+
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadTargets(t *testing.T) {
+	t.Parallel()
+
+	targets, err := readTargets(strings.NewReader(`# base system
+linux
+  linux-firmware   # trailing comment
+
+aur/yippee
+#commented-out
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"linux", "linux-firmware", "aur/yippee"}, targets)
+}
+
+func TestArguments_ParseTargetsFrom(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "pkglist")
+	require.NoError(t, os.WriteFile(path, []byte("foo\n# bar\nbaz\n"), 0o600))
+
+	args := MakeArguments()
+	require.NoError(t, args.addOP("S"))
+	require.NoError(t, args.addParam("targets-from", path))
+	args.AddTarget("qux")
+
+	usedStdin, err := args.parseTargetsFrom()
+	require.NoError(t, err)
+	assert.False(t, usedStdin)
+	assert.Equal(t, []string{"qux", "foo", "baz"}, args.Targets)
+	assert.False(t, args.ExistsArg("targets-from"))
+
+	args = MakeArguments()
+	require.NoError(t, args.addOP("Q"))
+	require.NoError(t, args.addParam("targets-from", path))
+
+	_, err = args.parseTargetsFrom()
+	assert.Error(t, err)
+
+	args = MakeArguments()
+	require.NoError(t, args.addOP("R"))
+	require.NoError(t, args.addParam("targets-from", filepath.Join(t.TempDir(), "missing")))
+
+	_, err = args.parseTargetsFrom()
+	assert.Error(t, err)
+}
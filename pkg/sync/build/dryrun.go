@@ -0,0 +1,157 @@
+package build
+
+import (
+	"context"
+	"sort"
+
+	"github.com/leonelquinteros/gotext"
+
+	"github.com/Jguer/yippee/v12/pkg/dep"
+	"github.com/Jguer/yippee/v12/pkg/settings"
+	"github.com/Jguer/yippee/v12/pkg/settings/parser"
+	"github.com/Jguer/yippee/v12/pkg/text"
+)
+
+// PrintPlan prints the commands Install would run for targets, layer by layer
+// in install order, without running any of them. The PKGBUILDs are not
+// cloned yet, so every AUR package is shown as built and the archives as
+// patterns.
+func (installer *Installer) PrintPlan(ctx context.Context,
+	cmdArgs *parser.Arguments,
+	targets []map[string]*dep.InstallInfo,
+	pkgBuildDirs map[string]string,
+	excluded []string,
+	manualConfirmRequired bool,
+) error {
+	installer.manualConfirmRequired = manualConfirmRequired
+
+	installer.log.OperationInfoln(gotext.Get("Install plan:"))
+
+	for i, step := len(targets)-1, 1; i >= 0; i, step = i-1, step+1 {
+		installer.log.Println(text.Bold(gotext.Get("Step %d of %d", step, len(targets))))
+
+		commands, err := installer.layerPlan(ctx, cmdArgs, targets[i], pkgBuildDirs, excluded)
+		if err != nil {
+			return err
+		}
+
+		for _, command := range commands {
+			installer.log.Println("    " + command)
+		}
+	}
+
+	return nil
+}
+
+// layerPlan returns the command lines installing layer, mirroring
+// handleLayer.
+func (installer *Installer) layerPlan(ctx context.Context,
+	cmdArgs *parser.Arguments,
+	layer map[string]*dep.InstallInfo,
+	pkgBuildDirs map[string]string,
+	excluded []string,
+) ([]string, error) {
+	split := splitLayer(cmdArgs, layer)
+	commands := make([]string, 0)
+
+	repoTargets := split.syncDeps.Union(split.syncExp).Union(split.syncGroups).ToSlice()
+	if len(repoTargets) > 0 || split.upgradeSync {
+		sort.Strings(repoTargets)
+
+		commands = append(commands, installer.exeCmd.BuildPacmanCmd(ctx,
+			installer.syncArguments(cmdArgs, repoTargets, excluded),
+			installer.targetMode, installer.appendNoConfirm()).String())
+
+		reasons, err := installer.reasonPlan(ctx, cmdArgs, split.syncDeps.ToSlice(), split.syncExp.ToSlice())
+		if err != nil {
+			return nil, err
+		}
+
+		commands = append(commands, reasons...)
+	}
+
+	names := split.aurDeps.Union(split.aurExp).ToSlice()
+	if len(names) == 0 {
+		return commands, nil
+	}
+
+	sort.Strings(names)
+
+	builtBases := make(map[string]bool, len(names))
+	deps, exps := make([]string, 0, len(names)), make([]string, 0, len(names))
+	pkgArchives := make([]string, 0, len(names))
+
+	for _, name := range names {
+		base := split.nameToBase[name]
+
+		if !builtBases[base] {
+			builtBases[base] = true
+
+			dir := pkgBuildDirs[base]
+			buildArgs := installer.buildArgs(true)
+
+			if !installer.exeCmd.GetKeepSrc() {
+				buildArgs = append(buildArgs, "-c")
+			}
+
+			commands = append(commands,
+				installer.exeCmd.BuildMakepkgCmd(ctx, dir, installer.extractArgs(true)...).String()+
+					" "+gotext.Get("(in %s)", dir),
+				installer.exeCmd.BuildMakepkgCmd(ctx, dir, buildArgs...).String()+
+					" "+gotext.Get("(in %s)", dir))
+		}
+
+		pkgArchives = append(pkgArchives, name+"-"+layer[name].Version+"-*.pkg.tar.*")
+
+		if installer.isDep(cmdArgs, split.aurExp, name) {
+			deps = append(deps, name)
+		} else {
+			exps = append(exps, name)
+		}
+	}
+
+	installArgs := cmdArgs
+	if installer.answerConflicts {
+		installArgs = withConflictAnswer(cmdArgs)
+	}
+
+	commands = append(commands, installer.exeCmd.BuildPacmanCmd(ctx,
+		archiveArguments(installArgs, pkgArchives),
+		installer.targetMode, installer.appendNoConfirm()).String())
+
+	reasons, err := installer.reasonPlan(ctx, cmdArgs, deps, exps)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(commands, reasons...), nil
+}
+
+// reasonPlan returns the command lines marking deps as dependencies and exps
+// as explicitly installed.
+func (installer *Installer) reasonPlan(ctx context.Context,
+	cmdArgs *parser.Arguments, deps, exps []string,
+) ([]string, error) {
+	commands := make([]string, 0, 2)
+
+	for _, reason := range []struct {
+		pkgs []string
+		exp  bool
+	}{{deps, false}, {exps, true}} {
+		if len(reason.pkgs) == 0 {
+			continue
+		}
+
+		sort.Strings(reason.pkgs)
+
+		arguments, err := reasonArguments(cmdArgs, reason.pkgs, reason.exp)
+		if err != nil {
+			return nil, err
+		}
+
+		commands = append(commands, installer.exeCmd.BuildPacmanCmd(ctx,
+			arguments, installer.targetMode, settings.NoConfirm).String())
+	}
+
+	return commands, nil
+}
@@ -0,0 +1,92 @@
+//go:build !integration
+// +build !integration
+
+package build
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jguer/yippee/v12/pkg/db/mock"
+	"github.com/Jguer/yippee/v12/pkg/dep"
+	"github.com/Jguer/yippee/v12/pkg/settings/exe"
+	"github.com/Jguer/yippee/v12/pkg/settings/parser"
+	"github.com/Jguer/yippee/v12/pkg/text"
+	"github.com/Jguer/yippee/v12/pkg/vcs"
+)
+
+func TestInstaller_PrintPlan(t *testing.T) {
+	t.Parallel()
+
+	mockRunner := &exe.MockRunner{}
+	cmdBuilder := &exe.CmdBuilder{
+		MakepkgBin:       "makepkg",
+		SudoBin:          "su",
+		PacmanBin:        "pacman",
+		PacmanConfigPath: "/etc/pacman.conf",
+		Runner:           mockRunner,
+		SudoLoopEnabled:  false,
+	}
+
+	out := &strings.Builder{}
+	logger := text.NewLogger(out, out, strings.NewReader(""), false, "test")
+
+	installer := NewInstaller(&mock.DBExecutor{}, cmdBuilder, &vcs.Mock{}, parser.ModeAny,
+		parser.RebuildModeNo, false, logger)
+
+	cmdArgs := parser.MakeArguments()
+	cmdArgs.AddArg("S")
+	cmdArgs.AddTarget("yippee")
+
+	targets := []map[string]*dep.InstallInfo{
+		{
+			"yippee": {
+				Source:  dep.AUR,
+				Reason:  dep.Explicit,
+				Version: "91.0.0-1",
+				AURBase: ptrString("yippee"),
+			},
+		},
+		{
+			"go": {
+				Source:     dep.Sync,
+				Reason:     dep.MakeDep,
+				Version:    "2:1.22.0-1",
+				SyncDBName: ptrString("extra"),
+			},
+		},
+	}
+
+	pkgBuildDirs := map[string]string{"yippee": "/testdir/yippee"}
+
+	require.NoError(t, installer.PrintPlan(context.Background(), cmdArgs, targets,
+		pkgBuildDirs, []string{}, false))
+
+	assert.Empty(t, mockRunner.ShowCalls)
+	assert.Empty(t, mockRunner.CaptureCalls)
+
+	wantSteps := [][]string{
+		{"Step 1 of 2"},
+		{"pacman", "-S", "--noconfirm", "--", "extra/go"},
+		{"pacman", "-D", "--asdeps", "--", "go"},
+		{"Step 2 of 2"},
+		{"--nobuild", "-f", "-C", "--ignorearch", "(in", "/testdir/yippee)"},
+		{"-f", "--noconfirm", "--noextract", "--noprepare", "--holdver", "--ignorearch", "-c"},
+		{"pacman", "-U", "--noconfirm", "--", "yippee-91.0.0-1-*.pkg.tar.*"},
+		{"pacman", "-D", "--asexplicit", "--", "yippee"},
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, len(wantSteps)+1, out.String())
+
+	// makepkg may run through systemd-run or sudo, only the arguments matter
+	for i, want := range wantSteps {
+		for _, field := range want {
+			assert.Contains(t, lines[i+1], field)
+		}
+	}
+}
@@ -60,3 +60,31 @@ type NoPkgDestsFoundError struct {
 func (e *NoPkgDestsFoundError) Error() string {
 	return gotext.Get("could not find any package archives listed in %s", e.dir)
 }
+
+type InspectorError struct {
+	name   string
+	errOut string
+	inner  error
+}
+
+func (e *InspectorError) Error() string {
+	if e.errOut != "" {
+		return gotext.Get("%s failed: %s", e.name, e.errOut)
+	}
+
+	return gotext.Get("%s failed: %s", e.name, e.inner)
+}
+
+func (e *InspectorError) Unwrap() error {
+	return e.inner
+}
+
+type InspectionFailedError struct {
+	base  string
+	count int
+}
+
+func (e *InspectionFailedError) Error() string {
+	return gotext.GetN("inspection of %s found %d error", "inspection of %s found %d errors",
+		e.count, e.base, e.count)
+}
@@ -0,0 +1,201 @@
+package build
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/leonelquinteros/gotext"
+
+	"github.com/Jguer/yippee/v12/pkg/settings/exe"
+	"github.com/Jguer/yippee/v12/pkg/text"
+)
+
+// Severity tells how bad an inspection finding is. Only errors may block the
+// installation.
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityError
+)
+
+// Finding is a problem an inspector reported about a PKGBUILD or a package.
+type Finding struct {
+	Target   string
+	Severity Severity
+	Message  string
+}
+
+// Inspector checks a PKGBUILD and the packages built from it once the build
+// is over, before they are installed. dir is the PKGBUILD directory. Commands
+// are built with cmdBuilder.BuildInspectorCmd, so they never run as root.
+type Inspector interface {
+	Name() string
+	Inspect(ctx context.Context, cmdBuilder exe.ICmdBuilder, dir string, pkgArchives []string) ([]Finding, error)
+}
+
+var (
+	inspectorsMu sync.Mutex
+	inspectors   = map[string]func() Inspector{
+		"namcap": func() Inspector { return namcapInspector{bin: "namcap"} },
+	}
+)
+
+// RegisterInspector makes an inspector available to NewInspectors by name,
+// replacing the one registered before under that name.
+func RegisterInspector(name string, newInspector func() Inspector) {
+	inspectorsMu.Lock()
+	defer inspectorsMu.Unlock()
+
+	inspectors[name] = newInspector
+}
+
+// NewInspectors returns the inspectors of names. A name that is not
+// registered is a command, run with the PKGBUILD and the packages.
+func NewInspectors(names []string) []Inspector {
+	inspectorsMu.Lock()
+	defer inspectorsMu.Unlock()
+
+	list := make([]Inspector, 0, len(names))
+
+	for _, name := range names {
+		if newInspector, ok := inspectors[name]; ok {
+			list = append(list, newInspector())
+			continue
+		}
+
+		list = append(list, commandInspector{bin: name})
+	}
+
+	return list
+}
+
+// namcapInspector runs namcap, whose lines read "<target> <E|W|I>: <message>".
+type namcapInspector struct {
+	bin string
+}
+
+var namcapTags = []struct {
+	tag      string
+	severity Severity
+}{
+	{" E: ", SeverityError},
+	{" W: ", SeverityWarning},
+	{" I: ", SeverityInfo},
+}
+
+func (n namcapInspector) Name() string {
+	return "namcap"
+}
+
+func (n namcapInspector) Inspect(ctx context.Context, cmdBuilder exe.ICmdBuilder,
+	dir string, pkgArchives []string,
+) ([]Finding, error) {
+	cmd := cmdBuilder.BuildInspectorCmd(ctx, dir, n.bin, append([]string{"PKGBUILD"}, pkgArchives...)...)
+
+	stdout, stderr, err := cmdBuilder.Capture(cmd)
+	if err != nil {
+		return nil, &InspectorError{name: n.Name(), errOut: strings.TrimSpace(stderr), inner: err}
+	}
+
+	return parseNamcap(stdout), nil
+}
+
+func parseNamcap(output string) []Finding {
+	findings := make([]Finding, 0)
+
+	for _, line := range strings.Split(output, "\n") {
+		for _, tag := range namcapTags {
+			if target, message, ok := strings.Cut(line, tag.tag); ok {
+				findings = append(findings, Finding{Target: target, Severity: tag.severity, Message: message})
+				break
+			}
+		}
+	}
+
+	return findings
+}
+
+// commandInspector runs any linter. Every line it prints is a warning, and
+// it failing is an error.
+type commandInspector struct {
+	bin string
+}
+
+func (c commandInspector) Name() string {
+	return c.bin
+}
+
+func (c commandInspector) Inspect(ctx context.Context, cmdBuilder exe.ICmdBuilder,
+	dir string, pkgArchives []string,
+) ([]Finding, error) {
+	cmd := cmdBuilder.BuildInspectorCmd(ctx, dir, c.bin, append([]string{"PKGBUILD"}, pkgArchives...)...)
+
+	stdout, stderr, err := cmdBuilder.Capture(cmd)
+
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return nil, &InspectorError{name: c.Name(), errOut: strings.TrimSpace(stderr), inner: err}
+	}
+
+	findings := make([]Finding, 0)
+
+	for _, line := range strings.Split(stdout+"\n"+stderr, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			findings = append(findings, Finding{Target: "PKGBUILD", Severity: SeverityWarning, Message: line})
+		}
+	}
+
+	if err != nil {
+		findings = append(findings, Finding{
+			Target:   "PKGBUILD",
+			Severity: SeverityError,
+			Message:  gotext.Get("%s exited with status %d", c.bin, exitErr.ExitCode()),
+		})
+	}
+
+	return findings, nil
+}
+
+// inspect runs the inspectors on the PKGBUILD of base and its packages and
+// prints what they found. It fails on errors when inspection blocks.
+func (installer *Installer) inspect(ctx context.Context, base, dir string, pkgArchives []string) error {
+	errCount := 0
+
+	for _, inspector := range installer.inspectors {
+		findings, err := inspector.Inspect(ctx, installer.exeCmd, dir, pkgArchives)
+		if err != nil {
+			installer.log.Warnln(gotext.Get("unable to inspect %s:", text.Cyan(base)), err)
+			continue
+		}
+
+		if len(findings) == 0 {
+			continue
+		}
+
+		installer.log.OperationInfoln(gotext.Get("%s findings for %s:", inspector.Name(), text.Cyan(base)))
+
+		for _, finding := range findings {
+			switch finding.Severity {
+			case SeverityError:
+				errCount++
+
+				installer.log.Println(text.Red("  E"), finding.Target+":", finding.Message)
+			case SeverityWarning:
+				installer.log.Println(text.Magenta("  W"), finding.Target+":", finding.Message)
+			default:
+				installer.log.Println(text.Blue("  I"), finding.Target+":", finding.Message)
+			}
+		}
+	}
+
+	if errCount > 0 && installer.inspectBlock {
+		return &InspectionFailedError{base: base, count: errCount}
+	}
+
+	return nil
+}
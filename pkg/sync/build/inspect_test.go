@@ -0,0 +1,176 @@
+//go:build !integration
+// +build !integration
+
+package build
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jguer/yippee/v12/pkg/db/mock"
+	"github.com/Jguer/yippee/v12/pkg/settings/exe"
+	"github.com/Jguer/yippee/v12/pkg/settings/parser"
+	"github.com/Jguer/yippee/v12/pkg/vcs"
+)
+
+func TestParseNamcap(t *testing.T) {
+	t.Parallel()
+
+	output := `PKGBUILD (yippee) W: Reference to x86_64 should be changed to $CARCH
+yippee E: Dependency glibc detected and not included (libraries ['usr/lib/libc.so.6'] needed in files ['usr/bin/yippee'])
+yippee I: Depends as namcap sees them: depends=(pacman git)
+
+`
+
+	assert.Equal(t, []Finding{
+		{Target: "PKGBUILD (yippee)", Severity: SeverityWarning, Message: "Reference to x86_64 should be changed to $CARCH"},
+		{
+			Target:   "yippee",
+			Severity: SeverityError,
+			Message:  "Dependency glibc detected and not included (libraries ['usr/lib/libc.so.6'] needed in files ['usr/bin/yippee'])",
+		},
+		{Target: "yippee", Severity: SeverityInfo, Message: "Depends as namcap sees them: depends=(pacman git)"},
+	}, parseNamcap(output))
+}
+
+func TestNewInspectors(t *testing.T) {
+	t.Parallel()
+
+	list := NewInspectors([]string{"namcap", "pkgcheck"})
+	require.Len(t, list, 2)
+	assert.Equal(t, "namcap", list[0].Name())
+	assert.Equal(t, "pkgcheck", list[1].Name())
+}
+
+func TestCommandInspector(t *testing.T) {
+	t.Parallel()
+
+	exitErr := exec.Command("sh", "-c", "exit 3").Run()
+
+	tests := []struct {
+		name    string
+		stdout  string
+		err     error
+		want    []Finding
+		wantErr bool
+	}{
+		{
+			name:   "lines are warnings",
+			stdout: "missing license\n\n",
+			want:   []Finding{{Target: "PKGBUILD", Severity: SeverityWarning, Message: "missing license"}},
+		},
+		{
+			name:   "failure is an error",
+			stdout: "bad checksum\n",
+			err:    exitErr,
+			want: []Finding{
+				{Target: "PKGBUILD", Severity: SeverityWarning, Message: "bad checksum"},
+				{Target: "PKGBUILD", Severity: SeverityError, Message: "lint exited with status 3"},
+			},
+		},
+		{
+			name:    "not runnable",
+			err:     exec.ErrNotFound,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var args []string
+
+			runner := &exe.MockRunner{CaptureFn: func(cmd *exec.Cmd) (string, string, error) {
+				args = cmd.Args
+				return tc.stdout, "", tc.err
+			}}
+
+			findings, err := commandInspector{bin: "lint"}.Inspect(context.Background(), &exe.MockBuilder{Runner: runner},
+				"/tmp/yippee", []string{"/tmp/yippee/yippee-1-1-x86_64.pkg.tar.zst"})
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, []string{"lint", "PKGBUILD", "/tmp/yippee/yippee-1-1-x86_64.pkg.tar.zst"}, args)
+			assert.Equal(t, tc.want, findings)
+		})
+	}
+}
+
+type testInspector struct {
+	findings []Finding
+	err      error
+}
+
+func (i testInspector) Name() string {
+	return "test"
+}
+
+func (i testInspector) Inspect(context.Context, exe.ICmdBuilder, string, []string) ([]Finding, error) {
+	return i.findings, i.err
+}
+
+func TestInstaller_Inspect(t *testing.T) {
+	t.Parallel()
+
+	errorFinding := Finding{Target: "yippee", Severity: SeverityError, Message: "broken"}
+	warningFinding := Finding{Target: "yippee", Severity: SeverityWarning, Message: "odd"}
+
+	tests := []struct {
+		name       string
+		inspectors []Inspector
+		block      bool
+		wantErr    bool
+	}{
+		{
+			name:       "errors block",
+			inspectors: []Inspector{testInspector{findings: []Finding{warningFinding, errorFinding}}},
+			block:      true,
+			wantErr:    true,
+		},
+		{
+			name:       "errors without blocking",
+			inspectors: []Inspector{testInspector{findings: []Finding{errorFinding}}},
+		},
+		{
+			name:       "warnings do not block",
+			inspectors: []Inspector{testInspector{findings: []Finding{warningFinding}}},
+			block:      true,
+		},
+		{
+			name:       "inspector failing does not block",
+			inspectors: []Inspector{testInspector{err: errors.New("namcap: command not found")}},
+			block:      true,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			installer := NewInstaller(&mock.DBExecutor{}, &exe.MockBuilder{Runner: &exe.MockRunner{}},
+				&vcs.Mock{}, parser.ModeAny, parser.RebuildModeNo, false, newTestLogger())
+			installer.SetInspectors(tc.inspectors, tc.block)
+
+			err := installer.inspect(context.Background(), "yippee", "/tmp/yippee", nil)
+			if tc.wantErr {
+				var errInspection *InspectionFailedError
+				require.ErrorAs(t, err, &errInspection)
+
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
@@ -4,14 +4,19 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 
 	"github.com/Jguer/yippee/v12/pkg/db"
 	"github.com/Jguer/yippee/v12/pkg/dep"
+	"github.com/Jguer/yippee/v12/pkg/events"
 	"github.com/Jguer/yippee/v12/pkg/multierror"
 	"github.com/Jguer/yippee/v12/pkg/settings"
 	"github.com/Jguer/yippee/v12/pkg/settings/exe"
 	"github.com/Jguer/yippee/v12/pkg/settings/parser"
 	"github.com/Jguer/yippee/v12/pkg/text"
+	"github.com/Jguer/yippee/v12/pkg/timing"
 	"github.com/Jguer/yippee/v12/pkg/vcs"
 
 	mapset "github.com/deckarep/golang-set/v2"
@@ -21,18 +26,28 @@ import (
 type (
 	PostInstallHookFunc func(ctx context.Context) error
 	Installer           struct {
-		dbExecutor       db.Executor
-		postInstallHooks []PostInstallHookFunc
-		failedAndIgnored map[string]error
-		exeCmd           exe.ICmdBuilder
-		vcsStore         vcs.Store
-		targetMode       parser.TargetMode
-		rebuildMode      parser.RebuildMode
-		origTargets      mapset.Set[string]
-		downloadOnly     bool
-		log              *text.Logger
+		dbExecutor        db.Executor
+		postInstallHooks  []PostInstallHookFunc
+		failedAndIgnored  map[string]error
+		exeCmd            exe.ICmdBuilder
+		vcsStore          vcs.Store
+		targetMode        parser.TargetMode
+		rebuildMode       parser.RebuildMode
+		origTargets       mapset.Set[string]
+		downloadOnly      bool
+		memoryLimit       int
+		buildStats        *StatsStore
+		parallelDownloads int
+		tracer            *timing.Tracer
+		events            *events.Emitter
+		inspectors        []Inspector
+		inspectBlock      bool
+		lookPath          func(file string) (string, error)
+		log               *text.Logger
 
 		manualConfirmRequired bool
+		answerConflicts       bool
+		unattended            bool
 	}
 )
 
@@ -49,6 +64,7 @@ func NewInstaller(dbExecutor db.Executor,
 		targetMode:            targetMode,
 		rebuildMode:           rebuildMode,
 		downloadOnly:          downloadOnly,
+		lookPath:              exec.LookPath,
 		log:                   logger,
 		manualConfirmRequired: true,
 	}
@@ -64,6 +80,52 @@ func (installer *Installer) CompileFailedAndIgnored() (map[string]error, error)
 	}
 }
 
+// SetBuildThrottle limits makepkg parallelism so that each make job has at
+// least memoryLimit MiB of available memory. A value <= 0 disables throttling.
+func (installer *Installer) SetBuildThrottle(memoryLimit int) {
+	installer.memoryLimit = memoryLimit
+}
+
+// SetBuildStats records the largest process of the builds in stats, which tells
+// the heavy packages apart when throttling builds.
+func (installer *Installer) SetBuildStats(stats *StatsStore) {
+	installer.buildStats = stats
+}
+
+// SetParallelDownloads records the ParallelDownloads setting of pacman.conf.
+func (installer *Installer) SetParallelDownloads(parallelDownloads int) {
+	installer.parallelDownloads = parallelDownloads
+}
+
+// SetAnswerConflicts makes pacman remove the installed packages in conflict
+// with the AUR packages without asking.
+func (installer *Installer) SetAnswerConflicts(answer bool) {
+	installer.answerConflicts = answer
+}
+
+// SetUnattended makes the questions coming up while building take their
+// default, everything was answered before the run started.
+func (installer *Installer) SetUnattended(unattended bool) {
+	installer.unattended = unattended
+}
+
+// SetTracer records the time spent building and installing in tracer.
+func (installer *Installer) SetTracer(tracer *timing.Tracer) {
+	installer.tracer = tracer
+}
+
+// SetEvents reports the builds and installs to emitter.
+func (installer *Installer) SetEvents(emitter *events.Emitter) {
+	installer.events = emitter
+}
+
+// SetInspectors runs inspectors on every AUR package built. When block is set
+// a package they find errors in is not installed.
+func (installer *Installer) SetInspectors(inspectors []Inspector, block bool) {
+	installer.inspectors = inspectors
+	installer.inspectBlock = block
+}
+
 func (installer *Installer) AddPostInstallHook(hook PostInstallHookFunc) {
 	if hook == nil {
 		return
@@ -75,6 +137,8 @@ func (installer *Installer) AddPostInstallHook(hook PostInstallHookFunc) {
 func (installer *Installer) RunPostInstallHooks(ctx context.Context) error {
 	var errMulti multierror.MultiError
 
+	defer installer.tracer.Start(gotext.Get("hooks"))()
+
 	for _, hook := range installer.postInstallHooks {
 		if err := hook(ctx); err != nil {
 			errMulti.Add(err)
@@ -144,66 +208,85 @@ func (installer *Installer) handleLayer(ctx context.Context,
 	lastLayer bool,
 	excluded []string,
 ) error {
-	// Install layer
-	nameToBaseMap := make(map[string]string, 0)
-	syncDeps, syncExp, syncGroups := mapset.NewThreadUnsafeSet[string](),
-		mapset.NewThreadUnsafeSet[string](), mapset.NewThreadUnsafeSet[string]()
-	aurDeps, aurExp := mapset.NewThreadUnsafeSet[string](), mapset.NewThreadUnsafeSet[string]()
+	split := splitLayer(cmdArgs, layer)
+
+	installer.log.Debugln("syncDeps", split.syncDeps, "SyncExp", split.syncExp,
+		"aurDeps", split.aurDeps, "aurExp", split.aurExp, "upgrade", split.upgradeSync)
+
+	errShow := installer.installSyncPackages(ctx, cmdArgs, split.syncDeps, split.syncExp, split.syncGroups,
+		excluded, split.upgradeSync, installer.appendNoConfirm())
+	if errShow != nil {
+		return ErrInstallRepoPkgs
+	}
+
+	errAur := installer.installAURPackages(ctx, cmdArgs, split.aurDeps, split.aurExp,
+		split.nameToBase, pkgBuildDirs, true, lastLayer, installer.appendNoConfirm())
+
+	return errAur
+}
+
+// layerTargets are the packages of a layer by the way they are installed.
+// Repository packages are named repo/name.
+type layerTargets struct {
+	nameToBase                    map[string]string
+	syncDeps, syncExp, syncGroups mapset.Set[string]
+	aurDeps, aurExp               mapset.Set[string]
+	// upgradeSync is set when pacman upgrades repository packages of the
+	// layer on its own.
+	upgradeSync bool
+}
+
+func splitLayer(cmdArgs *parser.Arguments, layer map[string]*dep.InstallInfo) *layerTargets {
+	split := &layerTargets{
+		nameToBase: make(map[string]string, 0),
+		syncDeps:   mapset.NewThreadUnsafeSet[string](),
+		syncExp:    mapset.NewThreadUnsafeSet[string](),
+		syncGroups: mapset.NewThreadUnsafeSet[string](),
+		aurDeps:    mapset.NewThreadUnsafeSet[string](),
+		aurExp:     mapset.NewThreadUnsafeSet[string](),
+	}
 
-	upgradeSync := false
 	for name, info := range layer {
 		switch info.Source {
 		case dep.AUR, dep.SrcInfo:
-			nameToBaseMap[name] = *info.AURBase
+			split.nameToBase[name] = *info.AURBase
 
 			switch info.Reason {
 			case dep.Explicit:
 				if cmdArgs.ExistsArg("asdeps", "asdep") {
-					aurDeps.Add(name)
+					split.aurDeps.Add(name)
 				} else {
-					aurExp.Add(name)
+					split.aurExp.Add(name)
 				}
 			case dep.Dep, dep.MakeDep, dep.CheckDep:
-				aurDeps.Add(name)
+				split.aurDeps.Add(name)
 			}
 		case dep.Sync:
 			if info.Upgrade {
-				upgradeSync = true
+				split.upgradeSync = true
 				continue // do not add to targets, let pacman handle it
 			}
 			compositePkgName := fmt.Sprintf("%s/%s", *info.SyncDBName, name)
 
 			if info.IsGroup {
-				syncGroups.Add(compositePkgName)
+				split.syncGroups.Add(compositePkgName)
 				continue
 			}
 
 			switch info.Reason {
 			case dep.Explicit:
 				if cmdArgs.ExistsArg("asdeps", "asdep") {
-					syncDeps.Add(compositePkgName)
+					split.syncDeps.Add(compositePkgName)
 				} else {
-					syncExp.Add(compositePkgName)
+					split.syncExp.Add(compositePkgName)
 				}
 			case dep.Dep, dep.MakeDep, dep.CheckDep:
-				syncDeps.Add(compositePkgName)
+				split.syncDeps.Add(compositePkgName)
 			}
 		}
 	}
 
-	installer.log.Debugln("syncDeps", syncDeps, "SyncExp", syncExp,
-		"aurDeps", aurDeps, "aurExp", aurExp, "upgrade", upgradeSync)
-
-	errShow := installer.installSyncPackages(ctx, cmdArgs, syncDeps, syncExp, syncGroups,
-		excluded, upgradeSync, installer.appendNoConfirm())
-	if errShow != nil {
-		return ErrInstallRepoPkgs
-	}
-
-	errAur := installer.installAURPackages(ctx, cmdArgs, aurDeps, aurExp,
-		nameToBaseMap, pkgBuildDirs, true, lastLayer, installer.appendNoConfirm())
-
-	return errAur
+	return split
 }
 
 func (installer *Installer) installAURPackages(ctx context.Context,
@@ -226,9 +309,15 @@ func (installer *Installer) installAURPackages(ctx context.Context,
 		base := nameToBase[name]
 		dir := pkgBuildDirsByBase[base]
 
+		doneBuild := installer.tracer.Start(gotext.Get("build %s", base))
+		installer.events.Emit(events.BuildStarted, base)
 		pkgdests, errMake := installer.buildPkg(ctx, dir, base,
 			installIncompatible, cmdArgs.ExistsArg("needed"), installer.origTargets.Contains(name))
+		doneBuild()
+
 		if errMake != nil {
+			installer.events.EmitError(events.StageBuild, base, errMake)
+
 			if !lastLayer {
 				return fmt.Errorf("%s - %w", gotext.Get("error making: %s", base), errMake)
 			}
@@ -238,6 +327,8 @@ func (installer *Installer) installAURPackages(ctx context.Context,
 			continue
 		}
 
+		installer.events.Emit(events.BuildFinished, base)
+
 		if len(pkgdests) == 0 {
 			installer.log.Warnln(gotext.Get("nothing to install for %s", text.Cyan(base)))
 			continue
@@ -248,6 +339,18 @@ func (installer *Installer) installAURPackages(ctx context.Context,
 			return err
 		}
 
+		if errInspect := installer.inspect(ctx, base, dir, newPKGArchives); errInspect != nil {
+			installer.events.EmitError(events.StageInspect, base, errInspect)
+
+			if !lastLayer {
+				return errInspect
+			}
+
+			installer.failedAndIgnored[name] = errInspect
+			installer.log.Errorln(errInspect)
+			continue
+		}
+
 		pkgArchives = append(pkgArchives, newPKGArchives...)
 
 		if isDep := installer.isDep(cmdArgs, aurExpNames, name); isDep {
@@ -261,15 +364,34 @@ func (installer *Installer) installAURPackages(ctx context.Context,
 		}
 	}
 
+	doneInstall := installer.tracer.Start(gotext.Get("install"))
+	defer doneInstall()
+
+	if len(pkgArchives) == 0 {
+		return nil
+	}
+
+	installArgs := cmdArgs
+	if installer.answerConflicts {
+		installArgs = withConflictAnswer(cmdArgs)
+	}
+
+	installed := append(append(make([]string, 0, len(deps)+len(exps)), deps...), exps...)
+	installer.events.EmitInstall(events.InstallStarted, installed)
+
 	if err := installPkgArchive(ctx, installer.exeCmd, installer.targetMode,
-		installer.vcsStore, cmdArgs, pkgArchives, noConfirm); err != nil {
+		installer.vcsStore, installArgs, pkgArchives, noConfirm); err != nil {
+		installer.events.EmitError(events.StageInstall, "", err)
 		return fmt.Errorf("%s - %w", fmt.Sprintf(gotext.Get("error installing:")+" %v", pkgArchives), err)
 	}
 
 	if err := setInstallReason(ctx, installer.exeCmd, installer.targetMode, cmdArgs, deps, exps); err != nil {
+		installer.events.EmitError(events.StageInstall, "", err)
 		return fmt.Errorf("%s - %w", fmt.Sprintf(gotext.Get("error installing:")+" %v", pkgArchives), err)
 	}
 
+	installer.events.EmitInstall(events.InstallFinished, installed)
+
 	return nil
 }
 
@@ -277,19 +399,8 @@ func (installer *Installer) buildPkg(ctx context.Context,
 	dir, base string,
 	installIncompatible, needed, isTarget bool,
 ) (map[string]string, error) {
-	args := []string{"--nobuild", "-f"}
-
-	if !installer.exeCmd.GetKeepSrc() {
-		args = append(args, "-C")
-	}
-
-	if installIncompatible {
-		args = append(args, "--ignorearch")
-	}
-
 	// pkgver bump
-	if err := installer.exeCmd.Show(
-		installer.exeCmd.BuildMakepkgCmd(ctx, dir, args...)); err != nil {
+	if err := installer.extractSources(ctx, dir, base, installer.extractArgs(installIncompatible)); err != nil {
 		return nil, err
 	}
 
@@ -298,6 +409,10 @@ func (installer *Installer) buildPkg(ctx context.Context,
 		return nil, errList
 	}
 
+	var args []string
+
+	built := false
+
 	switch {
 	case needed && installer.pkgsAreAlreadyInstalled(pkgdests, pkgVersion) || installer.downloadOnly:
 		args = []string{"--nobuild", "--noextract", "--ignorearch"}
@@ -307,23 +422,40 @@ func (installer *Installer) buildPkg(ctx context.Context,
 		args = []string{"--nobuild", "--noextract", "--ignorearch"}
 		installer.log.Warnln(gotext.Get("%s already made -- skipping build", text.Cyan(base+"-"+pkgVersion)))
 	default:
-		args = []string{"-f", "--noconfirm", "--noextract", "--noprepare", "--holdver"}
-		if installIncompatible {
-			args = append(args, "--ignorearch")
-		}
+		args = installer.buildArgs(installIncompatible)
+		built = true
 	}
 
 	if !installer.exeCmd.GetKeepSrc() {
 		args = append(args, "-c")
 	}
 
-	errMake := installer.exeCmd.Show(
-		installer.exeCmd.BuildMakepkgCmd(ctx,
-			dir, args...))
+	jobs := 0
+	if built {
+		jobs = installer.throttleJobs(base, pkgdests)
+	}
+
+	var makepkgCmd *exec.Cmd
+	if jobs > 0 {
+		makepkgCmd = installer.exeCmd.BuildThrottledMakepkgCmd(ctx, dir, jobs, args...)
+	} else {
+		makepkgCmd = installer.exeCmd.BuildMakepkgCmd(ctx, dir, args...)
+	}
+
+	errMake := installer.exeCmd.Show(makepkgCmd)
 	if errMake != nil {
 		return nil, errMake
 	}
 
+	// builds run by systemd-run are not children of the command, its usage
+	// says nothing about them
+	maxRSS, ok := maxProcessRSS(makepkgCmd.ProcessState)
+	if built && ok && filepath.Base(makepkgCmd.Path) != "systemd-run" {
+		if err := installer.buildStats.Record(base, maxRSS); err != nil {
+			installer.log.Debugln("unable to record build stats:", err)
+		}
+	}
+
 	if installer.downloadOnly {
 		return map[string]string{}, nil
 	}
@@ -331,6 +463,33 @@ func (installer *Installer) buildPkg(ctx context.Context,
 	return pkgdests, nil
 }
 
+// extractArgs are the makepkg arguments downloading and extracting the
+// sources, which runs pkgver().
+func (installer *Installer) extractArgs(installIncompatible bool) []string {
+	args := []string{"--nobuild", "-f"}
+
+	if !installer.exeCmd.GetKeepSrc() {
+		args = append(args, "-C")
+	}
+
+	if installIncompatible {
+		args = append(args, "--ignorearch")
+	}
+
+	return args
+}
+
+// buildArgs are the makepkg arguments building extracted sources, short of
+// the cleanup flag.
+func (installer *Installer) buildArgs(installIncompatible bool) []string {
+	args := []string{"-f", "--noconfirm", "--noextract", "--noprepare", "--holdver"}
+	if installIncompatible {
+		args = append(args, "--ignorearch")
+	}
+
+	return args
+}
+
 func (installer *Installer) pkgsAreAlreadyInstalled(pkgdests map[string]string, pkgVersion string) bool {
 	for pkgName := range pkgdests {
 		if !installer.dbExecutor.IsCorrectVersionInstalled(pkgName, pkgVersion) {
@@ -421,6 +580,48 @@ func (installer *Installer) installSyncPackages(ctx context.Context, cmdArgs *pa
 		return nil
 	}
 
+	arguments := installer.syncArguments(cmdArgs, repoTargets, excluded)
+
+	defer installer.tracer.Start(gotext.Get("install"))()
+
+	if installer.parallelDownloads > 1 && len(repoTargets) > 1 {
+		installer.log.Verboseln("downloading with up to", installer.parallelDownloads, "parallel downloads")
+	}
+
+	var stderr strings.Builder
+
+	cmd := installer.exeCmd.BuildPacmanCmd(ctx, arguments, installer.targetMode, noConfirm)
+	cmd.Stderr = &stderr
+
+	installer.events.EmitInstall(events.InstallStarted, repoTargets)
+
+	if errShow := installer.exeCmd.Show(cmd); errShow != nil {
+		if errRetry := installer.retryFailedDownloads(ctx, arguments, stderr.String(), noConfirm); errRetry != nil {
+			installer.log.Debugln("retrying failed downloads:", errRetry)
+			installer.events.EmitError(events.StageInstall, "", errShow)
+			return errShow
+		}
+	}
+
+	if errD := asdeps(ctx, installer.exeCmd, installer.targetMode, cmdArgs, syncDeps.ToSlice()); errD != nil {
+		installer.events.EmitError(events.StageInstall, "", errD)
+		return errD
+	}
+
+	if errE := asexp(ctx, installer.exeCmd, installer.targetMode, cmdArgs, syncExp.ToSlice()); errE != nil {
+		installer.events.EmitError(events.StageInstall, "", errE)
+		return errE
+	}
+
+	installer.events.EmitInstall(events.InstallFinished, repoTargets)
+
+	return nil
+}
+
+// syncArguments are the pacman arguments installing the repository targets.
+func (installer *Installer) syncArguments(cmdArgs *parser.Arguments,
+	repoTargets, excluded []string,
+) *parser.Arguments {
 	arguments := cmdArgs.Copy()
 	arguments.DelArg("asdeps", "asdep")
 	arguments.DelArg("asexplicit", "asexp")
@@ -438,19 +639,5 @@ func (installer *Installer) installSyncPackages(ctx context.Context, cmdArgs *pa
 		arguments.CreateOrAppendOption("ignore", excluded...)
 	}
 
-	errShow := installer.exeCmd.Show(installer.exeCmd.BuildPacmanCmd(ctx,
-		arguments, installer.targetMode, noConfirm))
-	if errShow != nil {
-		return errShow
-	}
-
-	if errD := asdeps(ctx, installer.exeCmd, installer.targetMode, cmdArgs, syncDeps.ToSlice()); errD != nil {
-		return errD
-	}
-
-	if errE := asexp(ctx, installer.exeCmd, installer.targetMode, cmdArgs, syncExp.ToSlice()); errE != nil {
-		return errE
-	}
-
-	return nil
+	return arguments
 }
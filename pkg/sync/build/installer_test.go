@@ -1441,3 +1441,38 @@ func TestInstaller_KeepSrc(t *testing.T) {
 		})
 	}
 }
+
+func TestWithConflictAnswer(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc string
+		ask  string
+		want string
+	}{
+		{desc: "no answers given", want: "4"},
+		{desc: "keeps the answers given", ask: "16", want: "20"},
+		{desc: "already answered", ask: "4", want: "4"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			cmdArgs := parser.MakeArguments()
+			if tc.ask != "" {
+				cmdArgs.CreateOrAppendOption("ask", tc.ask)
+			}
+
+			got := withConflictAnswer(cmdArgs)
+
+			value, _, exists := got.GetArg("ask")
+			assert.True(t, exists)
+			assert.Equal(t, tc.want, value)
+
+			value, _, _ = cmdArgs.GetArg("ask")
+			assert.Equal(t, tc.ask, value)
+		})
+	}
+}
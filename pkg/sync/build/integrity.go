@@ -0,0 +1,187 @@
+package build
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	gosrc "github.com/Morganamilo/go-srcinfo"
+	"github.com/leonelquinteros/gotext"
+
+	"github.com/Jguer/yippee/v12/pkg/settings"
+	"github.com/Jguer/yippee/v12/pkg/text"
+)
+
+// integrityFailedMsg is printed by makepkg when a source does not match its
+// checksum.
+const integrityFailedMsg = "did not pass the validity check"
+
+// failedSourceRe matches the sources makepkg reports failing verification.
+var failedSourceRe = regexp.MustCompile(`(?m)^\s+(\S.*?) \.\.\. (?:FAILED|NOT FOUND)`)
+
+var vcsProtocols = []string{"bzr", "fossil", "git", "hg", "svn"}
+
+// integrityRecovery is a way out of a failed integrity check.
+type integrityRecovery int
+
+const (
+	recoveryAbort integrityRecovery = iota
+	recoveryRefresh
+	recoveryUpdateSums
+	recoverySkip
+)
+
+func (r integrityRecovery) String() string {
+	switch r {
+	case recoveryRefresh:
+		return gotext.Get("Download the failed sources again")
+	case recoveryUpdateSums:
+		return gotext.Get("Regenerate the checksums with updpkgsums")
+	case recoverySkip:
+		return gotext.Get("Skip the integrity checks")
+	default:
+		return gotext.Get("Abort")
+	}
+}
+
+// failedSources returns the files makepkg reported failing verification.
+func failedSources(output string) []string {
+	files := make([]string, 0)
+
+	for _, match := range failedSourceRe.FindAllStringSubmatch(text.StripFormatting(output), -1) {
+		if !slices.Contains(files, match[1]) {
+			files = append(files, match[1])
+		}
+	}
+
+	return files
+}
+
+// hasVCSSource reports whether the PKGBUILD in dir builds from a repository,
+// whose tags and tarballs tend to move upstream.
+func hasVCSSource(dir string) bool {
+	srcinfo, err := gosrc.ParseFile(filepath.Join(dir, ".SRCINFO"))
+	if err != nil {
+		return false
+	}
+
+	for _, source := range srcinfo.Source {
+		_, url, named := strings.Cut(source.Value, "::")
+		if !named {
+			url = source.Value
+		}
+
+		scheme, _, ok := strings.Cut(url, "://")
+		if !ok {
+			continue
+		}
+
+		protocol, _, _ := strings.Cut(scheme, "+")
+		if slices.Contains(vcsProtocols, protocol) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// extractSources runs makepkg with args to download, verify and extract the
+// sources in dir. When a VCS package fails its integrity checks the user is
+// offered to download the failed sources again, to regenerate the checksums
+// or to skip the checks, instead of giving up on the build.
+func (installer *Installer) extractSources(ctx context.Context, dir, base string, args []string) error {
+	runArgs := args
+
+	for {
+		var stderr strings.Builder
+
+		cmd := installer.exeCmd.BuildMakepkgCmd(ctx, dir, runArgs...)
+		cmd.Stderr = &stderr
+
+		err := installer.exeCmd.Show(cmd)
+		if err == nil || !strings.Contains(text.StripFormatting(stderr.String()), integrityFailedMsg) ||
+			!hasVCSSource(dir) {
+			return err
+		}
+
+		switch installer.integrityMenu(base) {
+		case recoveryRefresh:
+			for _, file := range failedSources(stderr.String()) {
+				if errRm := os.Remove(filepath.Join(dir, file)); errRm != nil && !os.IsNotExist(errRm) {
+					return errRm
+				}
+			}
+		case recoveryUpdateSums:
+			updpkgsums := exec.CommandContext(ctx, "updpkgsums")
+			updpkgsums.Dir = dir
+
+			if errSums := installer.exeCmd.Show(updpkgsums); errSums != nil {
+				return errSums
+			}
+		case recoverySkip:
+			runArgs = append(slices.Clip(args), "--skipinteg")
+		default:
+			return err
+		}
+	}
+}
+
+// integrityMenu asks how to recover from the failed integrity checks of base.
+// Skipping them needs to be confirmed, aborting is the default.
+func (installer *Installer) integrityMenu(base string) integrityRecovery {
+	options := []integrityRecovery{recoveryRefresh}
+	if _, err := installer.lookPath("updpkgsums"); err == nil {
+		options = append(options, recoveryUpdateSums)
+	}
+
+	options = append(options, recoverySkip, recoveryAbort)
+
+	str := text.Bold(gotext.Get("Integrity checks of %s failed, the sources of VCS packages often change upstream:",
+		text.Cyan(base))) + "\n"
+	for i, option := range options {
+		str += "    " + strconv.Itoa(i+1) + ") " + option.String() + "\n"
+	}
+
+	installer.log.OperationInfo(str)
+
+	for {
+		installer.log.Println(gotext.Get("\nEnter a number (default=%d): ", len(options)))
+
+		numberBuf, err := installer.log.GetInput("", settings.NoConfirm || installer.unattended)
+		if err != nil {
+			installer.log.Errorln(err)
+
+			return recoveryAbort
+		}
+
+		if numberBuf == "" {
+			return recoveryAbort
+		}
+
+		num, err := strconv.Atoi(numberBuf)
+		if err != nil {
+			installer.log.Errorln(gotext.Get("invalid number: %s", numberBuf))
+
+			continue
+		}
+
+		if num < 1 || num > len(options) {
+			installer.log.Errorln(gotext.Get("invalid value: %d is not between %d and %d", num, 1, len(options)))
+
+			continue
+		}
+
+		option := options[num-1]
+		if option == recoverySkip && !installer.log.ContinueTask(
+			gotext.Get("Build %s without verifying its sources?", text.Cyan(base)), false, settings.NoConfirm) {
+			continue
+		}
+
+		return option
+	}
+}
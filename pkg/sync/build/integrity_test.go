@@ -0,0 +1,138 @@
+//go:build !integration
+// +build !integration
+
+package build
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jguer/yippee/v12/pkg/db/mock"
+	"github.com/Jguer/yippee/v12/pkg/settings/exe"
+	"github.com/Jguer/yippee/v12/pkg/settings/parser"
+	"github.com/Jguer/yippee/v12/pkg/text"
+	"github.com/Jguer/yippee/v12/pkg/vcs"
+)
+
+const integrityFailure = `==> Validating source files with sha256sums...
+    yippee-git ... Skipped
+    fix-build.patch ... FAILED
+==> ERROR: One or more files did not pass the validity check!
+`
+
+func TestFailedSources(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []string{"fix-build.patch"}, failedSources(integrityFailure))
+	assert.Empty(t, failedSources("==> Validating source files with sha256sums...\n    a.tar.gz ... Passed\n"))
+}
+
+func TestInstaller_ExtractSources(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		source     string
+		input      string
+		wantArgs   [][]string
+		wantErr    bool
+		removed    bool
+		unattended bool
+	}{
+		{
+			name:     "not a VCS package",
+			source:   "https://example.org/yippee-1.0.tar.gz",
+			wantArgs: [][]string{{"--nobuild"}},
+			wantErr:  true,
+		},
+		{
+			name:     "abort by default",
+			source:   "git+https://github.com/Jguer/yippee.git",
+			input:    "\n",
+			wantArgs: [][]string{{"--nobuild"}},
+			wantErr:  true,
+		},
+		{
+			name:     "download again",
+			source:   "git+https://github.com/Jguer/yippee.git",
+			input:    "1\n",
+			wantArgs: [][]string{{"--nobuild"}, {"--nobuild"}},
+			removed:  true,
+		},
+		{
+			name:     "skip after confirming",
+			source:   "git+https://github.com/Jguer/yippee.git",
+			input:    "2\ny\n",
+			wantArgs: [][]string{{"--nobuild"}, {"--nobuild", "--skipinteg"}},
+		},
+		{
+			name:     "skip refused",
+			source:   "git+https://github.com/Jguer/yippee.git",
+			input:    "2\nn\n3\n",
+			wantArgs: [][]string{{"--nobuild"}},
+			wantErr:  true,
+		},
+		{
+			name:       "unattended aborts without asking",
+			source:     "git+https://github.com/Jguer/yippee.git",
+			input:      "1\n",
+			wantArgs:   [][]string{{"--nobuild"}},
+			wantErr:    true,
+			unattended: true,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(dir, ".SRCINFO"), []byte(
+				"pkgbase = yippee-git\n\tpkgver = 12.0.0\n\tpkgrel = 1\n\tarch = x86_64\n"+
+					"\tsource = "+tc.source+"\n\tsource = fix-build.patch\n\npkgname = yippee-git\n"), 0o644))
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "fix-build.patch"), []byte("diff"), 0o644))
+
+			gotArgs := make([][]string, 0)
+			runner := &exe.MockRunner{ShowFn: func(cmd *exec.Cmd) error {
+				gotArgs = append(gotArgs, cmd.Args[1:])
+				if len(gotArgs) > 1 {
+					return nil
+				}
+
+				_, _ = io.WriteString(cmd.Stderr, integrityFailure)
+
+				return errors.New("exit status 1")
+			}}
+
+			logger := text.NewLogger(io.Discard, io.Discard,
+				iotest.OneByteReader(strings.NewReader(tc.input)), false, "test")
+			installer := NewInstaller(&mock.DBExecutor{}, &exe.MockBuilder{Runner: runner},
+				&vcs.Mock{}, parser.ModeAny, parser.RebuildModeNo, false, logger)
+			installer.lookPath = func(string) (string, error) { return "", exec.ErrNotFound }
+			installer.SetUnattended(tc.unattended)
+
+			err := installer.extractSources(context.Background(), dir, "yippee-git", []string{"--nobuild"})
+			if tc.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, tc.wantArgs, gotArgs)
+
+			_, errStat := os.Stat(filepath.Join(dir, "fix-build.patch"))
+			assert.Equal(t, tc.removed, os.IsNotExist(errStat))
+		})
+	}
+}
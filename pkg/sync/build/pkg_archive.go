@@ -6,8 +6,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
+	alpm "github.com/Jguer/go-alpm/v2"
 	"github.com/leonelquinteros/gotext"
 
 	"github.com/Jguer/yippee/v12/pkg/settings"
@@ -28,6 +30,20 @@ func installPkgArchive(ctx context.Context,
 		return nil
 	}
 
+	if errShow := cmdBuilder.Show(cmdBuilder.BuildPacmanCmd(ctx,
+		archiveArguments(cmdArgs, pkgArchives), mode, noConfirm)); errShow != nil {
+		return errShow
+	}
+
+	if errStore := vcsStore.Save(); errStore != nil {
+		fmt.Fprintln(os.Stderr, errStore)
+	}
+
+	return nil
+}
+
+// archiveArguments are the pacman arguments installing pkgArchives.
+func archiveArguments(cmdArgs *parser.Arguments, pkgArchives []string) *parser.Arguments {
 	arguments := cmdArgs.Copy()
 	arguments.ClearTargets()
 	arguments.Op = "U"
@@ -44,16 +60,25 @@ func installPkgArchive(ctx context.Context,
 
 	arguments.AddTarget(pkgArchives...)
 
-	if errShow := cmdBuilder.Show(cmdBuilder.BuildPacmanCmd(ctx,
-		arguments, mode, noConfirm)); errShow != nil {
-		return errShow
-	}
+	return arguments
+}
 
-	if errStore := vcsStore.Save(); errStore != nil {
-		fmt.Fprintln(os.Stderr, errStore)
+// withConflictAnswer returns a copy of cmdArgs making pacman answer yes when
+// asked to remove conflicting packages, on top of the answers given by --ask.
+func withConflictAnswer(cmdArgs *parser.Arguments) *parser.Arguments {
+	ask := alpm.QuestionTypeConflictPkg
+
+	if value, _, exists := cmdArgs.GetArg("ask"); exists {
+		if given, err := strconv.ParseUint(value, 10, 32); err == nil {
+			ask |= alpm.QuestionType(given)
+		}
 	}
 
-	return nil
+	arguments := cmdArgs.Copy()
+	arguments.DelArg("ask")
+	arguments.CreateOrAppendOption("ask", strconv.FormatUint(uint64(ask), 10))
+
+	return arguments
 }
 
 func setInstallReason(ctx context.Context,
@@ -80,14 +105,30 @@ func setPkgReason(ctx context.Context,
 		return nil
 	}
 
+	arguments, err := reasonArguments(cmdArgs, pkgs, exp)
+	if err != nil {
+		return err
+	}
+
+	if err := cmdBuilder.Show(cmdBuilder.BuildPacmanCmd(ctx,
+		arguments, mode, settings.NoConfirm)); err != nil {
+		return &SetPkgReasonError{exp: exp}
+	}
+
+	return nil
+}
+
+// reasonArguments are the pacman arguments marking pkgs, named either name
+// or repo/name, as explicitly installed with exp or as dependencies.
+func reasonArguments(cmdArgs *parser.Arguments, pkgs []string, exp bool) (*parser.Arguments, error) {
 	cmdArgs = cmdArgs.CopyGlobal()
 	if exp {
 		if err := cmdArgs.AddArg("q", "D", "asexplicit"); err != nil {
-			return err
+			return nil, err
 		}
 	} else {
 		if err := cmdArgs.AddArg("q", "D", "asdeps"); err != nil {
-			return err
+			return nil, err
 		}
 	}
 
@@ -102,12 +143,7 @@ func setPkgReason(ctx context.Context,
 		cmdArgs.AddTarget(pkgName)
 	}
 
-	if err := cmdBuilder.Show(cmdBuilder.BuildPacmanCmd(ctx,
-		cmdArgs, mode, settings.NoConfirm)); err != nil {
-		return &SetPkgReasonError{exp: exp}
-	}
-
-	return nil
+	return cmdArgs, nil
 }
 
 func asdeps(ctx context.Context,
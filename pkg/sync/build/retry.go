@@ -0,0 +1,94 @@
+package build
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/leonelquinteros/gotext"
+
+	"github.com/Jguer/yippee/v12/pkg/settings/parser"
+)
+
+// syncDownloadRetries is how many times the packages pacman failed to
+// download are fetched again before giving up on a layer.
+const syncDownloadRetries = 2
+
+var (
+	failedRetrievalRe    = regexp.MustCompile(`failed retrieving file '([^']+)'`)
+	errNoFailedDownloads = errors.New("no failed downloads")
+)
+
+// pkgNameFromFile returns the package name of a package archive or its
+// signature, named name-pkgver-pkgrel-arch.pkg.tar.*.
+func pkgNameFromFile(file string) string {
+	idx := strings.Index(file, ".pkg.tar")
+	if idx < 0 {
+		return ""
+	}
+
+	parts := strings.Split(file[:idx], "-")
+	if len(parts) < 4 {
+		return ""
+	}
+
+	return strings.Join(parts[:len(parts)-3], "-")
+}
+
+// failedDownloads returns the packages pacman reported it could not retrieve.
+func failedDownloads(stderr string) []string {
+	names := make([]string, 0)
+	seen := make(map[string]bool)
+
+	for _, match := range failedRetrievalRe.FindAllStringSubmatch(stderr, -1) {
+		if name := pkgNameFromFile(match[1]); name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// retryFailedDownloads downloads again the packages of a failed repository
+// transaction that pacman could not retrieve, then runs the transaction
+// again once they are cached. It fails when the transaction did not fail on
+// downloads or the downloads keep failing.
+func (installer *Installer) retryFailedDownloads(ctx context.Context, arguments *parser.Arguments,
+	stderr string, noConfirm bool,
+) error {
+	failed := failedDownloads(stderr)
+	if len(failed) == 0 {
+		return errNoFailedDownloads
+	}
+
+	for attempt := 1; attempt <= syncDownloadRetries; attempt++ {
+		installer.log.Warnln(gotext.Get("Retrying download of %s (%d/%d)",
+			strings.Join(failed, ", "), attempt, syncDownloadRetries))
+
+		download := arguments.Copy()
+		download.DelArg("u", "sysupgrade")
+		download.DelArg("y", "refresh")
+		download.AddArg("w")
+		download.ClearTargets()
+		download.AddTarget(failed...)
+
+		var downloadErr strings.Builder
+
+		cmd := installer.exeCmd.BuildPacmanCmd(ctx, download, installer.targetMode, true)
+		cmd.Stderr = &downloadErr
+
+		errDownload := installer.exeCmd.Show(cmd)
+		if errDownload == nil {
+			return installer.exeCmd.Show(installer.exeCmd.BuildPacmanCmd(ctx,
+				arguments, installer.targetMode, noConfirm))
+		}
+
+		if failed = failedDownloads(downloadErr.String()); len(failed) == 0 {
+			return errDownload
+		}
+	}
+
+	return errors.New(gotext.Get("failed to download %s", strings.Join(failed, ", ")))
+}
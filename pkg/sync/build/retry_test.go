@@ -0,0 +1,111 @@
+//go:build !integration
+// +build !integration
+
+package build
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	mapset "github.com/deckarep/golang-set/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Jguer/yippee/v12/pkg/db/mock"
+	"github.com/Jguer/yippee/v12/pkg/settings/exe"
+	"github.com/Jguer/yippee/v12/pkg/settings/parser"
+	"github.com/Jguer/yippee/v12/pkg/vcs"
+)
+
+func TestFailedDownloads(t *testing.T) {
+	t.Parallel()
+
+	stderr := `error: failed retrieving file 'lib32-foo-bar-2:1.2.3-4-x86_64.pkg.tar.zst' from mirror.example.org : Operation too slow
+error: failed retrieving file 'core.db' from mirror.example.org : 404
+error: failed retrieving file 'lib32-foo-bar-2:1.2.3-4-x86_64.pkg.tar.zst.sig' from mirror.example.org : 404
+error: failed retrieving file 'python-3.12.3-1-x86_64.pkg.tar.zst' from other.example.org : Connection reset
+warning: too many errors from mirror.example.org, skipping for the remainder of this transaction
+error: failed to commit transaction (failed to retrieve some files)`
+
+	assert.Equal(t, []string{"lib32-foo-bar", "python"}, failedDownloads(stderr))
+	assert.Empty(t, failedDownloads("error: target not found: nope"))
+}
+
+func TestInstaller_RetryFailedDownloads(t *testing.T) {
+	t.Parallel()
+
+	const (
+		install  = "pacman -S --config /etc/pacman.conf -- extra/foo"
+		download = "pacman -S -w --noconfirm --config /etc/pacman.conf -- "
+		failFoo  = "error: failed retrieving file 'foo-1.0-1-x86_64.pkg.tar.zst' from mirror : timeout\n"
+		failBar  = "error: failed retrieving file 'bar-2.0-1-any.pkg.tar.zst' from mirror : timeout\n"
+	)
+
+	errPacman := errors.New("exit status 1")
+
+	testCases := []struct {
+		desc    string
+		script  func(m *exe.MockRunner)
+		wantErr bool
+	}{
+		{
+			desc: "retry the failed packages then the transaction",
+			script: func(m *exe.MockRunner) {
+				m.Expect(install).Return("", failFoo+failBar, errPacman)
+				m.Expect(download+"foo bar").Return("", failBar, errPacman)
+				m.Expect(download + "bar")
+				m.Expect(install)
+				m.Expect("pacman -D")
+			},
+		},
+		{
+			desc: "downloads keep failing",
+			script: func(m *exe.MockRunner) {
+				m.Expect(install).Return("", failFoo, errPacman)
+				m.Expect(download+"foo").Return("", failFoo, errPacman).Times(syncDownloadRetries)
+			},
+			wantErr: true,
+		},
+		{
+			desc: "other failures are not retried",
+			script: func(m *exe.MockRunner) {
+				m.Expect(install).Return("", "error: failed to prepare transaction (conflicting dependencies)", errPacman)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			mockRunner := &exe.MockRunner{}
+			tc.script(mockRunner)
+
+			cmdBuilder := &exe.CmdBuilder{
+				SudoBin:          "su",
+				PacmanBin:        "pacman",
+				PacmanConfigPath: "/etc/pacman.conf",
+				Runner:           mockRunner,
+			}
+
+			installer := NewInstaller(&mock.DBExecutor{}, cmdBuilder, &vcs.Mock{}, parser.ModeAny,
+				parser.RebuildModeNo, false, newTestLogger())
+
+			cmdArgs := parser.MakeArguments()
+			cmdArgs.AddArg("S")
+
+			err := installer.installSyncPackages(context.Background(), cmdArgs,
+				mapset.NewThreadUnsafeSet[string](), mapset.NewThreadUnsafeSet("extra/foo"),
+				mapset.NewThreadUnsafeSet[string](), nil, false, false)
+			if tc.wantErr {
+				assert.ErrorIs(t, err, errPacman)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			mockRunner.AssertExpectations(t)
+		})
+	}
+}
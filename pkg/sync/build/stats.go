@@ -0,0 +1,101 @@
+package build
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"syscall"
+)
+
+// StatsStore remembers the resident memory of the largest process of the last
+// build of each package base, so heavy packages are known before they are
+// built again. It is the max RSS the kernel reports for the children of
+// makepkg, not the memory of all build processes at once: a parallel build of
+// small compiler processes looks lighter than it is. A nil *StatsStore
+// remembers nothing.
+type StatsStore struct {
+	FilePath string
+
+	maxRSS map[string]int64
+	mux    sync.Mutex
+}
+
+func NewStatsStore(filePath string) *StatsStore {
+	return &StatsStore{
+		FilePath: filePath,
+		maxRSS:   make(map[string]int64),
+	}
+}
+
+// Load reads the recorded stats from disk. A missing file is not an error.
+func (s *StatsStore) Load() error {
+	content, err := os.ReadFile(s.FilePath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to open build stats file '%s': %w", s.FilePath, err)
+	}
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if err := json.Unmarshal(content, &s.maxRSS); err != nil {
+		return fmt.Errorf("failed to read build stats file '%s': %w", s.FilePath, err)
+	}
+
+	return nil
+}
+
+// MaxProcessRSS returns the resident memory in bytes of the largest process
+// recorded for the last build of base.
+func (s *StatsStore) MaxProcessRSS(base string) (int64, bool) {
+	if s == nil {
+		return 0, false
+	}
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	maxRSS, ok := s.maxRSS[base]
+
+	return maxRSS, ok
+}
+
+// Record sets the resident memory of the largest process of the build of base
+// and saves the stats to disk.
+func (s *StatsStore) Record(base string, maxRSS int64) error {
+	if s == nil {
+		return nil
+	}
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.maxRSS[base] = maxRSS
+
+	marshalled, err := json.MarshalIndent(s.maxRSS, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(s.FilePath, marshalled, 0o644)
+}
+
+// maxProcessRSS returns the resident memory in bytes of the largest of the
+// process that exited with state and the children it waited for.
+func maxProcessRSS(state *os.ProcessState) (int64, bool) {
+	if state == nil {
+		return 0, false
+	}
+
+	rusage, ok := state.SysUsage().(*syscall.Rusage)
+	if !ok || rusage.Maxrss <= 0 {
+		return 0, false
+	}
+
+	// Maxrss is in kilobytes on Linux
+	return rusage.Maxrss * 1024, true
+}
@@ -0,0 +1,44 @@
+package build
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatsStore(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "build_stats.json")
+
+	stats := NewStatsStore(path)
+	require.NoError(t, stats.Load())
+
+	_, ok := stats.MaxProcessRSS("foo")
+	assert.False(t, ok)
+
+	require.NoError(t, stats.Record("foo", 1024))
+
+	loaded := NewStatsStore(path)
+	require.NoError(t, loaded.Load())
+
+	maxRSS, ok := loaded.MaxProcessRSS("foo")
+	assert.True(t, ok)
+	assert.Equal(t, int64(1024), maxRSS)
+
+	require.NoError(t, os.WriteFile(path, []byte("not json"), 0o600))
+	assert.Error(t, NewStatsStore(path).Load())
+}
+
+func TestNilStatsStore(t *testing.T) {
+	t.Parallel()
+
+	var stats *StatsStore
+
+	_, ok := stats.MaxProcessRSS("foo")
+	assert.False(t, ok)
+	assert.NoError(t, stats.Record("foo", 1024))
+}
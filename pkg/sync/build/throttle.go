@@ -0,0 +1,100 @@
+package build
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+const (
+	memInfoPath = "/proc/meminfo"
+	mebibyte    = 1024 * 1024
+)
+
+// memAvailable returns the MemAvailable entry of a meminfo file in bytes.
+func memAvailable(path string) (int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[0] != "MemAvailable:" {
+			continue
+		}
+
+		kib, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return 0, err
+		}
+
+		return kib * 1024, nil
+	}
+
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+
+	return 0, fmt.Errorf("MemAvailable not found in %s", path)
+}
+
+// throttledJobs returns the amount of make jobs that fit in the available
+// memory given a per job budget. The result is clamped between 1 and cpus.
+func throttledJobs(available, perJob int64, cpus int) int {
+	if perJob <= 0 {
+		return cpus
+	}
+
+	jobs := int(available / perJob)
+
+	return max(1, min(jobs, cpus))
+}
+
+// throttleJobs returns the amount of make jobs to build base with when build
+// throttling is enabled, 0 when makepkg.conf decides.
+func (installer *Installer) throttleJobs(base string, pkgdests map[string]string) int {
+	if installer.memoryLimit <= 0 {
+		return 0
+	}
+
+	available, err := memAvailable(memInfoPath)
+	if err != nil {
+		installer.log.Debugln("unable to read available memory:", err)
+		return 0
+	}
+
+	perJob := int64(installer.memoryLimit) * mebibyte
+	jobs := throttledJobs(available, perJob, runtime.NumCPU())
+
+	if installer.heavyBuild(base, pkgdests, available) {
+		installer.log.Debugln("serializing build of heavy package:", base)
+		jobs = 1
+	}
+
+	installer.log.Debugln("throttling build to", jobs, "jobs with", available/mebibyte, "MiB available")
+
+	return jobs
+}
+
+// heavyBuild reports whether building base needs more than half of the
+// available memory. The largest process of its last build tells, packages
+// never built here fall back to the installed size of the previous version.
+func (installer *Installer) heavyBuild(base string, pkgdests map[string]string, available int64) bool {
+	if maxRSS, ok := installer.buildStats.MaxProcessRSS(base); ok {
+		return maxRSS > available/2
+	}
+
+	for pkgName := range pkgdests {
+		if pkg := installer.dbExecutor.LocalPackage(pkgName); pkg != nil && pkg.ISize() > available/2 {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,98 @@
+package build
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jguer/yippee/v12/pkg/db/mock"
+	"github.com/Jguer/yippee/v12/pkg/settings/parser"
+)
+
+func TestMemAvailable(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "meminfo")
+	require.NoError(t, os.WriteFile(path, []byte(
+		"MemTotal:        8000000 kB\nMemFree:          100000 kB\nMemAvailable:    4096000 kB\n"), 0o600))
+
+	available, err := memAvailable(path)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4096000*1024), available)
+
+	require.NoError(t, os.WriteFile(path, []byte("MemTotal:        8000000 kB\n"), 0o600))
+
+	_, err = memAvailable(path)
+	assert.Error(t, err)
+}
+
+func TestThrottledJobs(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc      string
+		available int64
+		perJob    int64
+		cpus      int
+		want      int
+	}{
+		{desc: "plenty of memory", available: 64 * 1024 * mebibyte, perJob: 2048 * mebibyte, cpus: 8, want: 8},
+		{desc: "limited memory", available: 6 * 1024 * mebibyte, perJob: 2048 * mebibyte, cpus: 8, want: 3},
+		{desc: "not enough memory for one job", available: 512 * mebibyte, perJob: 2048 * mebibyte, cpus: 8, want: 1},
+		{desc: "no budget", available: 512 * mebibyte, perJob: 0, cpus: 4, want: 4},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.want, throttledJobs(tc.available, tc.perJob, tc.cpus))
+		})
+	}
+}
+
+func TestHeavyBuild(t *testing.T) {
+	t.Parallel()
+
+	available := int64(4096 * mebibyte)
+
+	testCases := []struct {
+		desc    string
+		maxRSS  int64
+		isize   int64
+		install bool
+		want    bool
+	}{
+		{desc: "recorded heavy, not installed yet", maxRSS: 3072 * mebibyte, want: true},
+		{desc: "recorded light, large installed size", maxRSS: 512 * mebibyte, isize: 3072 * mebibyte, install: true, want: false},
+		{desc: "not recorded, large installed size", isize: 3072 * mebibyte, install: true, want: true},
+		{desc: "not recorded, not installed yet", want: false},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			builder := mock.NewExecutor()
+			if tc.install {
+				builder.Local(mock.NewPackage("chromium", "1-1").WithSize(0, tc.isize))
+			}
+
+			installer := NewInstaller(builder.Build(), nil, nil, parser.ModeAny, parser.RebuildModeNo, false, newTestLogger())
+
+			stats := NewStatsStore(filepath.Join(t.TempDir(), "build_stats.json"))
+			if tc.maxRSS != 0 {
+				require.NoError(t, stats.Record("chromium", tc.maxRSS))
+			}
+
+			installer.SetBuildStats(stats)
+
+			assert.Equal(t, tc.want, installer.heavyBuild("chromium",
+				map[string]string{"chromium": "/tmp/chromium-1-1-x86_64.pkg.tar.zst"}, available))
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package sync
+
+import (
+	"context"
+
+	"github.com/leonelquinteros/gotext"
+
+	"github.com/Jguer/yippee/v12/pkg/db"
+	"github.com/Jguer/yippee/v12/pkg/dep"
+	"github.com/Jguer/yippee/v12/pkg/settings/parser"
+	"github.com/Jguer/yippee/v12/pkg/sync/build"
+	"github.com/Jguer/yippee/v12/pkg/sync/workdir"
+	"github.com/Jguer/yippee/v12/pkg/text"
+)
+
+// printPlan shows what installing targets would do for --dry-run: the
+// packages, the commands in install order and the sizes involved. Nothing
+// is cloned, downloaded, built or installed.
+func (o *OperationService) printPlan(ctx context.Context,
+	preparer *workdir.Preparer, installer *build.Installer,
+	cmdArgs *parser.Arguments,
+	targets []map[string]*dep.InstallInfo, excluded []string,
+) error {
+	preparer.Present(targets)
+
+	if err := installer.PrintPlan(ctx, cmdArgs, targets, preparer.PKGBUILDDirs(targets),
+		excluded, o.manualConfirmRequired(cmdArgs)); err != nil {
+		return err
+	}
+
+	if size := repoDownloadSize(o.dbExecutor, targets); size > 0 {
+		o.logger.Println(gotext.Get("Repository download size: %s", text.Cyan(text.Human(size))))
+	}
+
+	if size := estimatedBuildSpace(o.dbExecutor, targets); size > 0 {
+		o.logger.Println(gotext.Get("Estimated build space: %s", text.Cyan(text.Human(size))))
+	}
+
+	o.logger.OperationInfoln(gotext.Get("Dry run, nothing was downloaded, built or installed"))
+
+	return nil
+}
+
+// repoDownloadSize sums the sizes of the repository packages in targets.
+// Groups are left out, pacman resolves their members itself.
+func repoDownloadSize(dbExecutor db.Executor, targets []map[string]*dep.InstallInfo) int64 {
+	var size int64
+
+	for _, layer := range targets {
+		for name, info := range layer {
+			if info.Source != dep.Sync || info.IsGroup {
+				continue
+			}
+
+			if pkg := dbExecutor.SyncPackage(name); pkg != nil {
+				size += pkg.Size()
+			}
+		}
+	}
+
+	return size
+}
@@ -0,0 +1,234 @@
+package sync
+
+import (
+	"time"
+
+	"github.com/leonelquinteros/gotext"
+	"golang.org/x/sys/unix"
+
+	"github.com/Jguer/yippee/v12/pkg/db"
+	"github.com/Jguer/yippee/v12/pkg/dep"
+	"github.com/Jguer/yippee/v12/pkg/settings"
+	"github.com/Jguer/yippee/v12/pkg/text"
+)
+
+const baseDevel = "base-devel"
+
+// missingBaseDevel returns the members of base-devel that are not installed.
+// base-devel used to be a group and is now a meta package, both are handled.
+func missingBaseDevel(dbExecutor db.Executor) []db.IPackage {
+	members := dbExecutor.PackagesFromGroup(baseDevel)
+	if len(members) == 0 {
+		if pkg := dbExecutor.SyncPackage(baseDevel); pkg != nil {
+			members = []db.IPackage{pkg}
+		}
+	}
+
+	missing := make([]db.IPackage, 0, len(members))
+
+	for _, pkg := range members {
+		if !dbExecutor.LocalSatisfierExists(pkg.Name()) {
+			missing = append(missing, pkg)
+		}
+	}
+
+	return missing
+}
+
+func needsBuild(targets []map[string]*dep.InstallInfo) bool {
+	for _, layer := range targets {
+		for _, info := range layer {
+			if info.Source == dep.AUR || info.Source == dep.SrcInfo {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func inTargets(targets []map[string]*dep.InstallInfo, name string) bool {
+	for _, layer := range targets {
+		if _, ok := layer[name]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// baseDevelPreflight warns about missing base-devel members before building
+// and offers to install them as a repo layer at the start of the transaction.
+func (o *OperationService) baseDevelPreflight(targets []map[string]*dep.InstallInfo) []map[string]*dep.InstallInfo {
+	if !needsBuild(targets) {
+		return targets
+	}
+
+	layer := map[string]*dep.InstallInfo{}
+
+	for _, pkg := range missingBaseDevel(o.dbExecutor) {
+		if inTargets(targets, pkg.Name()) {
+			continue
+		}
+
+		dbName := pkg.DB().Name()
+		layer[pkg.Name()] = &dep.InstallInfo{
+			Source:     dep.Sync,
+			Reason:     dep.Explicit,
+			Version:    pkg.Version(),
+			SyncDBName: &dbName,
+		}
+	}
+
+	if len(layer) == 0 {
+		return targets
+	}
+
+	o.logger.Warnln(gotext.Get("The following %s members are not installed and may be required to build:",
+		text.Cyan(baseDevel)))
+
+	for name := range layer {
+		o.logger.Print("  " + text.Cyan(name))
+	}
+
+	o.logger.Println()
+
+	// a dry run only lists them in the plan
+	if !o.cfg.DryRun && !o.logger.ContinueTask(gotext.Get("Install them before building?"), true, settings.NoConfirm) {
+		return targets
+	}
+
+	// layers are installed from last to first
+	return append(targets, layer)
+}
+
+// dbPreflight reports breakage of the pacman databases before building, as
+// pacman would only refuse the built packages once installing them.
+func (o *OperationService) dbPreflight(targets []map[string]*dep.InstallInfo) error {
+	if !needsBuild(targets) {
+		return nil
+	}
+
+	problems := o.dbExecutor.CheckDatabases()
+	if len(problems) == 0 {
+		return nil
+	}
+
+	o.logger.Warnln(gotext.Get("The pacman databases look inconsistent, installing may fail:"))
+
+	for _, problem := range problems {
+		o.logger.Println("  " + problem.Problem)
+		o.logger.Println("    " + text.Cyan(problem.Fix))
+	}
+
+	if !o.logger.ContinueTask(gotext.Get("Proceed anyway?"), false, settings.NoConfirm) {
+		return &settings.ErrUserAbort{}
+	}
+
+	return nil
+}
+
+// dbAgePreflight warns before building when sync databases were not
+// refreshed for DBMaxAge days, dependencies installed from them may be gone
+// from the mirrors. Refreshing is left to -Syu to avoid partial upgrades.
+func (o *OperationService) dbAgePreflight(dbPath string, targets []map[string]*dep.InstallInfo) {
+	if o.cfg.DBMaxAge <= 0 || !needsBuild(targets) {
+		return
+	}
+
+	maxAge := time.Duration(o.cfg.DBMaxAge) * 24 * time.Hour
+	stale := db.StaleSyncDBs(db.SyncDBRefreshes(dbPath, o.dbExecutor.Repos()), maxAge, time.Now())
+
+	for _, refresh := range stale {
+		if refresh.Refreshed.IsZero() {
+			o.logger.Warnln(gotext.Get("the %s sync database was never refreshed", text.Cyan(refresh.Repo)))
+			continue
+		}
+
+		o.logger.Warnln(gotext.Get("the %s sync database was refreshed %s",
+			text.Cyan(refresh.Repo), text.FormatAge(time.Since(refresh.Refreshed))))
+	}
+
+	if len(stale) > 0 {
+		o.logger.Infoln(gotext.Get("Run %s first to build against current dependencies", text.Bold("yippee -Syu")))
+	}
+}
+
+// buildSpaceFactor accounts for the sources and the build tree next to the
+// built package when estimating the space a build needs.
+const buildSpaceFactor = 2
+
+// estimatedBuildSpace estimates the space needed to build the AUR targets
+// from the installed size of the versions they replace. Packages that are
+// not installed yet are not counted.
+func estimatedBuildSpace(dbExecutor db.Executor, targets []map[string]*dep.InstallInfo) int64 {
+	var size int64
+
+	for _, layer := range targets {
+		for name, info := range layer {
+			if info.Source != dep.AUR && info.Source != dep.SrcInfo {
+				continue
+			}
+
+			if pkg := dbExecutor.LocalPackage(name); pkg != nil {
+				size += pkg.ISize() * buildSpaceFactor
+			}
+		}
+	}
+
+	return size
+}
+
+// availableSpace returns the bytes available to unprivileged users on the
+// filesystem holding path.
+func availableSpace(path string) (int64, error) {
+	var stat unix.Statfs_t
+	if err := unix.Statfs(path, &stat); err != nil {
+		return 0, err
+	}
+
+	return int64(stat.Bavail) * stat.Bsize, nil
+}
+
+// spacePreflight mirrors the CheckSpace option of pacman for the builds,
+// asking to continue when the build directory looks too small for them.
+func (o *OperationService) spacePreflight(targets []map[string]*dep.InstallInfo) error {
+	needed := estimatedBuildSpace(o.dbExecutor, targets)
+	if needed == 0 {
+		return nil
+	}
+
+	available, err := availableSpace(o.cfg.BuildDir)
+	if err != nil {
+		o.logger.Debugln("unable to check free space:", err)
+		return nil
+	}
+
+	if available >= needed {
+		return nil
+	}
+
+	o.logger.Warnln(gotext.Get("Building may need %s in %s but only %s is available",
+		text.Cyan(text.Human(needed)), text.Cyan(o.cfg.BuildDir), text.Cyan(text.Human(available))))
+
+	if !o.logger.ContinueTask(gotext.Get("Proceed anyway?"), false, settings.NoConfirm) {
+		return &settings.ErrUserAbort{}
+	}
+
+	return nil
+}
+
+// repoTargets counts the repository packages in targets.
+func repoTargets(targets []map[string]*dep.InstallInfo) int {
+	count := 0
+
+	for _, layer := range targets {
+		for _, info := range layer {
+			if info.Source == dep.Sync {
+				count++
+			}
+		}
+	}
+
+	return count
+}
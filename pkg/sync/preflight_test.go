@@ -0,0 +1,129 @@
+//go:build !integration
+// +build !integration
+
+package sync
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Jguer/yippee/v12/pkg/db/mock"
+	"github.com/Jguer/yippee/v12/pkg/dep"
+	"github.com/Jguer/yippee/v12/pkg/settings"
+	"github.com/Jguer/yippee/v12/pkg/text"
+)
+
+func pkgNames(pkgs []mock.IPackage) []string {
+	out := make([]string, 0, len(pkgs))
+	for _, pkg := range pkgs {
+		out = append(out, pkg.Name())
+	}
+
+	return out
+}
+
+func TestMissingBaseDevel(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc    string
+		builder *mock.ExecutorBuilder
+		want    []string
+	}{
+		{
+			desc: "missing group member",
+			builder: mock.NewExecutor().
+				Local(mock.NewPackage("make", "4.4-1")).
+				Sync(
+					mock.NewPackage("make", "4.4-1").WithDB("core").WithGroups("base-devel"),
+					mock.NewPackage("patch", "2.7-1").WithDB("core").WithGroups("base-devel"),
+				),
+			want: []string{"patch"},
+		},
+		{
+			desc: "all group members installed",
+			builder: mock.NewExecutor().
+				Local(mock.NewPackage("make", "4.4-1"), mock.NewPackage("patch", "2.7-1")).
+				Sync(
+					mock.NewPackage("make", "4.4-1").WithDB("core").WithGroups("base-devel"),
+					mock.NewPackage("patch", "2.7-1").WithDB("core").WithGroups("base-devel"),
+				),
+			want: []string{},
+		},
+		{
+			desc: "missing meta package",
+			builder: mock.NewExecutor().
+				Sync(mock.NewPackage("base-devel", "1-2").WithDB("core")),
+			want: []string{"base-devel"},
+		},
+		{
+			desc: "meta package installed",
+			builder: mock.NewExecutor().
+				Local(mock.NewPackage("base-devel", "1-2")).
+				Sync(mock.NewPackage("base-devel", "1-2").WithDB("core")),
+			want: []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.want, pkgNames(missingBaseDevel(tc.builder.Build())))
+		})
+	}
+}
+
+func TestBaseDevelPreflight(t *testing.T) {
+	t.Parallel()
+
+	aurBase := "foo"
+	targets := []map[string]*dep.InstallInfo{
+		{"foo": {Source: dep.AUR, Reason: dep.Explicit, AURBase: &aurBase}},
+	}
+
+	testCases := []struct {
+		desc      string
+		installed bool
+		dryRun    bool
+		input     string
+		wantLen   int
+	}{
+		{desc: "missing member is installed first", input: "y\n", wantLen: 2},
+		{desc: "missing member declined", input: "n\n", wantLen: 1},
+		{desc: "dry run lists without asking", dryRun: true, input: "n\n", wantLen: 2},
+		{desc: "all members installed", installed: true, input: "y\n", wantLen: 1},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			builder := mock.NewExecutor().
+				Sync(mock.NewPackage("patch", "2.7-1").WithDB("core").WithGroups("base-devel"))
+			if tc.installed {
+				builder.Local(mock.NewPackage("patch", "2.7-1"))
+			}
+
+			o := &OperationService{
+				cfg:        &settings.Configuration{DryRun: tc.dryRun},
+				dbExecutor: builder.Build(),
+				logger:     text.NewLogger(io.Discard, os.Stderr, strings.NewReader(tc.input), false, "test"),
+			}
+
+			got := o.baseDevelPreflight(targets)
+			assert.Len(t, got, tc.wantLen)
+
+			if tc.wantLen == 2 {
+				assert.Contains(t, got[1], "patch")
+				assert.Equal(t, dep.Sync, got[1]["patch"].Source)
+			}
+		})
+	}
+}
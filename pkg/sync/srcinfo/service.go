@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"path/filepath"
+	"sort"
+	"time"
 
 	gosrc "github.com/Morganamilo/go-srcinfo"
 	"github.com/leonelquinteros/gotext"
@@ -68,6 +70,155 @@ nextpkg:
 	return incompatible, nil
 }
 
+// InstalledConflicts returns the installed packages the AUR packages of
+// targets conflict with.
+func (s *Service) InstalledConflicts(targets []map[string]*dep.InstallInfo) ([]dep.InstalledConflict, error) {
+	return dep.InstalledConflicts(s.dbExecutor, s.srcInfos, targets)
+}
+
+// DebugPkgs returns the pkgbases makepkg builds a -debug package for, given
+// whether makepkg.conf enables debug packages. The options of the PKGBUILD
+// take precedence and packages only built for any have nothing to strip.
+func (s *Service) DebugPkgs(makepkgDebug bool) []string {
+	debug := make([]string, 0)
+
+nextpkg:
+	for base, srcinfo := range s.srcInfos {
+		enabled := makepkgDebug
+
+		for i := len(srcinfo.Options) - 1; i >= 0; i-- {
+			if srcinfo.Options[i] == "debug" || srcinfo.Options[i] == "!debug" {
+				enabled = srcinfo.Options[i] == "debug"
+				break
+			}
+		}
+
+		if !enabled {
+			continue
+		}
+
+		for _, arch := range srcinfo.Arch {
+			if arch != "any" {
+				debug = append(debug, base)
+				continue nextpkg
+			}
+		}
+	}
+
+	sort.Strings(debug)
+
+	return debug
+}
+
+// NameMismatch is a fetched PKGBUILD whose pkgbase or pkgnames differ from the
+// ones dependency resolution expected.
+type NameMismatch struct {
+	Base      string   // pkgbase expected
+	FoundBase string   // pkgbase of the fetched .SRCINFO
+	Missing   []string // expected pkgnames the .SRCINFO no longer builds
+}
+
+// UnexpectedNames compares the parsed .SRCINFO files with the AUR packages
+// of targets. AUR packages are resolved from the RPC, so a clone producing
+// other packages than announced was tampered with or renamed upstream.
+func (s *Service) UnexpectedNames(targets []map[string]*dep.InstallInfo) []NameMismatch {
+	expected := make(map[string][]string)
+
+	for _, layer := range targets {
+		for name, info := range layer {
+			if info.Source == dep.AUR && info.AURBase != nil {
+				expected[*info.AURBase] = append(expected[*info.AURBase], name)
+			}
+		}
+	}
+
+	mismatches := make([]NameMismatch, 0)
+
+	for base, names := range expected {
+		srcinfo, ok := s.srcInfos[base]
+		if !ok {
+			continue
+		}
+
+		built := make(map[string]bool, len(srcinfo.Packages))
+		for i := range srcinfo.Packages {
+			built[srcinfo.Packages[i].Pkgname] = true
+		}
+
+		missing := make([]string, 0)
+		for _, name := range names {
+			if !built[name] {
+				missing = append(missing, name)
+			}
+		}
+
+		if srcinfo.Pkgbase == base && len(missing) == 0 {
+			continue
+		}
+
+		sort.Strings(missing)
+		mismatches = append(mismatches, NameMismatch{Base: base, FoundBase: srcinfo.Pkgbase, Missing: missing})
+	}
+
+	sort.Slice(mismatches, func(i, j int) bool { return mismatches[i].Base < mismatches[j].Base })
+
+	return mismatches
+}
+
+// ArchMismatch is an AUR target qualified with an architecture the .SRCINFO
+// does not build it for.
+type ArchMismatch struct {
+	Base   string
+	Name   string
+	Arch   string   // architecture of the target
+	Arches []string // architectures of the .SRCINFO
+}
+
+// ArchMismatches returns the AUR targets whose architecture qualifier is not
+// in the arch array of their package in the .SRCINFO.
+func (s *Service) ArchMismatches(targets []map[string]*dep.InstallInfo) []ArchMismatch {
+	mismatches := make([]ArchMismatch, 0)
+
+	for _, layer := range targets {
+		for name, info := range layer {
+			if info.Arch == "" || info.Source != dep.AUR || info.AURBase == nil {
+				continue
+			}
+
+			srcinfo, ok := s.srcInfos[*info.AURBase]
+			if !ok {
+				continue
+			}
+
+			pkg, err := srcinfo.SplitPackage(name)
+			if err != nil {
+				continue // reported by UnexpectedNames
+			}
+
+			found := false
+			for _, arch := range pkg.Arch {
+				found = found || arch == info.Arch
+			}
+
+			if !found {
+				mismatches = append(mismatches, ArchMismatch{
+					Base: *info.AURBase, Name: name, Arch: info.Arch, Arches: pkg.Arch,
+				})
+			}
+		}
+	}
+
+	sort.Slice(mismatches, func(i, j int) bool { return mismatches[i].Name < mismatches[j].Name })
+
+	return mismatches
+}
+
+// Exclude forgets the .SRCINFO of base so it is neither checked nor tracked.
+func (s *Service) Exclude(base string) {
+	delete(s.srcInfos, base)
+	delete(s.pkgBuildDirs, base)
+}
+
 func (s *Service) CheckPGPKeys(ctx context.Context) error {
 	_, errCPK := pgp.CheckPgpKeys(ctx, s.log.Child("pgp"), s.pkgBuildDirs, s.srcInfos, s.cmdBuilder, settings.NoConfirm)
 	return errCPK
@@ -106,8 +257,10 @@ func ParseSrcinfoFilesByBase(logger *text.Logger, pkgBuildDirs map[string]string
 
 	k := 0
 	for base, dir := range pkgBuildDirs {
-		logger.OperationInfoln(gotext.Get("(%d/%d) Parsing SRCINFO: %s", k+1, len(pkgBuildDirs), text.Cyan(base)))
+		logger.OperationInfoln(gotext.Get("%s Parsing SRCINFO: %s",
+			text.Progress(k+1, len(pkgBuildDirs)), text.Cyan(text.Isolate(base))))
 
+		start := time.Now()
 		pkgbuild, err := gosrc.ParseFile(filepath.Join(dir, ".SRCINFO"))
 		if err != nil {
 			if !errIsFatal {
@@ -118,6 +271,8 @@ func ParseSrcinfoFilesByBase(logger *text.Logger, pkgBuildDirs map[string]string
 			return nil, errors.New(gotext.Get("failed to parse %s: %s", base, err))
 		}
 
+		logger.Debugln(base+":", "from local clone in", time.Since(start).Round(time.Microsecond))
+
 		srcinfos[base] = pkgbuild
 		k++
 	}
@@ -130,3 +130,103 @@ func TestService_UpdateVCSStore(t *testing.T) {
 	err := srv.UpdateVCSStore(context.Background(), targets, ignore)
 	assert.NoError(t, err)
 }
+
+func TestService_UnexpectedNames(t *testing.T) {
+	base := func(s string) *string { return &s }
+
+	srv := &Service{
+		srcInfos: map[string]*gosrc.Srcinfo{
+			"good": {
+				PackageBase: gosrc.PackageBase{Pkgbase: "good"},
+				Packages:    []gosrc.Package{{Pkgname: "good"}, {Pkgname: "good-docs"}},
+			},
+			"renamed": {
+				PackageBase: gosrc.PackageBase{Pkgbase: "evil"},
+				Packages:    []gosrc.Package{{Pkgname: "renamed"}},
+			},
+			"split": {
+				PackageBase: gosrc.PackageBase{Pkgbase: "split"},
+				Packages:    []gosrc.Package{{Pkgname: "split-a"}, {Pkgname: "split-x"}},
+			},
+			"local": {
+				PackageBase: gosrc.PackageBase{Pkgbase: "local"},
+				Packages:    []gosrc.Package{{Pkgname: "other"}},
+			},
+		},
+		pkgBuildDirs: map[string]string{"renamed": "/tmp/renamed"},
+	}
+
+	targets := []map[string]*dep.InstallInfo{
+		{
+			"good":     {Source: dep.AUR, AURBase: base("good")},
+			"renamed":  {Source: dep.AUR, AURBase: base("renamed")},
+			"split-a":  {Source: dep.AUR, AURBase: base("split")},
+			"local":    {Source: dep.SrcInfo, AURBase: base("local")},
+			"glibc":    {Source: dep.Sync},
+			"split-b":  {Source: dep.AUR, AURBase: base("split")},
+			"not-here": {Source: dep.AUR, AURBase: base("not-here")},
+		},
+	}
+
+	assert.Equal(t, []NameMismatch{
+		{Base: "renamed", FoundBase: "evil", Missing: []string{}},
+		{Base: "split", FoundBase: "split", Missing: []string{"split-b"}},
+	}, srv.UnexpectedNames(targets))
+
+	srv.Exclude("renamed")
+	assert.NotContains(t, srv.srcInfos, "renamed")
+	assert.NotContains(t, srv.pkgBuildDirs, "renamed")
+}
+
+func TestService_ArchMismatches(t *testing.T) {
+	base := func(s string) *string { return &s }
+
+	srv := &Service{
+		srcInfos: map[string]*gosrc.Srcinfo{
+			"any": {
+				PackageBase: gosrc.PackageBase{Pkgbase: "any"},
+				Packages:    []gosrc.Package{{Pkgname: "any", Arch: []string{"any"}}},
+			},
+			"split": {
+				PackageBase: gosrc.PackageBase{Pkgbase: "split"},
+				Package:     gosrc.Package{Arch: []string{"x86_64", "aarch64"}},
+				Packages:    []gosrc.Package{{Pkgname: "split-bin"}, {Pkgname: "split-docs", Arch: []string{"any"}}},
+			},
+		},
+	}
+
+	targets := []map[string]*dep.InstallInfo{
+		{
+			"any":        {Source: dep.AUR, AURBase: base("any"), Arch: "any"},
+			"split-bin":  {Source: dep.AUR, AURBase: base("split"), Arch: "any"},
+			"split-docs": {Source: dep.AUR, AURBase: base("split"), Arch: "any"},
+			"glibc":      {Source: dep.Sync, Arch: "any"},
+		},
+	}
+
+	assert.Equal(t, []ArchMismatch{
+		{Base: "split", Name: "split-bin", Arch: "any", Arches: []string{"x86_64", "aarch64"}},
+	}, srv.ArchMismatches(targets))
+}
+
+func TestService_DebugPkgs(t *testing.T) {
+	srv := &Service{
+		srcInfos: map[string]*gosrc.Srcinfo{
+			"compiled": {
+				Package: gosrc.Package{Arch: []string{"x86_64"}},
+			},
+			"script": {
+				Package: gosrc.Package{Arch: []string{"any"}},
+			},
+			"nodebug": {
+				Package: gosrc.Package{Arch: []string{"x86_64"}, Options: []string{"!strip", "!debug"}},
+			},
+			"debug": {
+				Package: gosrc.Package{Arch: []string{"x86_64"}, Options: []string{"debug"}},
+			},
+		},
+	}
+
+	assert.Equal(t, []string{"compiled", "debug"}, srv.DebugPkgs(true))
+	assert.Equal(t, []string{"debug"}, srv.DebugPkgs(false))
+}
@@ -2,6 +2,8 @@ package sync
 
 import (
 	"context"
+	"errors"
+	"strings"
 
 	"github.com/Jguer/yippee/v12/pkg/completion"
 	"github.com/Jguer/yippee/v12/pkg/db"
@@ -9,11 +11,13 @@ import (
 	"github.com/Jguer/yippee/v12/pkg/multierror"
 	"github.com/Jguer/yippee/v12/pkg/runtime"
 	"github.com/Jguer/yippee/v12/pkg/settings"
+	"github.com/Jguer/yippee/v12/pkg/settings/exe"
 	"github.com/Jguer/yippee/v12/pkg/settings/parser"
 	"github.com/Jguer/yippee/v12/pkg/sync/build"
 	"github.com/Jguer/yippee/v12/pkg/sync/srcinfo"
 	"github.com/Jguer/yippee/v12/pkg/sync/workdir"
 	"github.com/Jguer/yippee/v12/pkg/text"
+	"github.com/Jguer/yippee/v12/pkg/timing"
 
 	"github.com/leonelquinteros/gotext"
 )
@@ -23,6 +27,7 @@ type OperationService struct {
 	cfg        *settings.Configuration
 	dbExecutor db.Executor
 	logger     *text.Logger
+	tracer     *timing.Tracer
 }
 
 func NewOperationService(ctx context.Context,
@@ -34,6 +39,7 @@ func NewOperationService(ctx context.Context,
 		cfg:        run.Cfg,
 		dbExecutor: dbExecutor,
 		logger:     run.Logger.Child("operation"),
+		tracer:     run.Tracer,
 	}
 }
 
@@ -43,19 +49,79 @@ func (o *OperationService) Run(ctx context.Context, run *runtime.Runtime,
 ) error {
 	if len(targets) == 0 {
 		o.logger.Println("", gotext.Get("there is nothing to do"))
-		return nil
+		return settings.ErrNothingToDo{}
 	}
+
+	defer reportWarnings(o.logger, o.cfg.JSON)
+	defer o.tracer.Report(o.logger)
+
+	if !cmdArgs.ExistsArg("w", "downloadonly") {
+		if err := o.dbPreflight(targets); err != nil {
+			return err
+		}
+
+		if run.PacmanConf != nil {
+			o.dbAgePreflight(run.PacmanConf.DBPath, targets)
+		}
+
+		targets = o.baseDevelPreflight(targets)
+
+		if run.PacmanConf != nil && run.PacmanConf.CheckSpace {
+			if err := o.spacePreflight(targets); err != nil {
+				return err
+			}
+		}
+	}
+
 	preparer := workdir.NewPreparer(o.dbExecutor, run.CmdBuilder, o.cfg, o.logger.Child("workdir"))
+
+	// building and installing what was resolved for the recorded system
+	// would only change this one
+	if o.cfg.Replay != "" {
+		preparer.Present(targets)
+		o.logger.OperationInfoln(gotext.Get("Replayed from %s, nothing was built or installed", o.cfg.Replay))
+
+		return nil
+	}
 	installer := build.NewInstaller(o.dbExecutor, run.CmdBuilder,
 		run.VCSStore, o.cfg.Mode, o.cfg.ReBuild,
 		cmdArgs.ExistsArg("w", "downloadonly"), run.Logger.Child("installer"))
 
+	if o.cfg.ThrottleBuilds {
+		installer.SetBuildThrottle(o.cfg.MemoryLimit)
+	}
+
+	buildStats := build.NewStatsStore(o.cfg.BuildStatsFilePath)
+	if err := buildStats.Load(); err != nil {
+		o.logger.Warnln(err)
+	}
+
+	installer.SetBuildStats(buildStats)
+
+	installer.SetParallelDownloads(run.PacmanOpts.ParallelDownloads)
+	installer.SetInspectors(build.NewInspectors(o.cfg.InspectorNames()), o.cfg.InspectBlock)
+	installer.SetTracer(o.tracer)
+	installer.SetEvents(run.Events)
+
+	if o.cfg.DryRun {
+		return o.printPlan(ctx, preparer, installer, cmdArgs, targets, excluded)
+	}
+
+	if count := repoTargets(targets); count > 0 && run.PacmanOpts.ParallelDownloads > 1 {
+		o.logger.OperationInfoln(gotext.GetN("Downloading %d repository package with up to %d parallel downloads",
+			"Downloading %d repository packages with up to %d parallel downloads",
+			count, count, run.PacmanOpts.ParallelDownloads))
+	}
+
+	doneDownload := o.tracer.Start(gotext.Get("download"))
 	pkgBuildDirs, errInstall := preparer.Run(ctx, run, targets)
+	doneDownload()
+
 	if errInstall != nil {
 		return errInstall
 	}
 
-	if cleanFunc := preparer.ShouldCleanMakeDeps(run, cmdArgs); cleanFunc != nil {
+	if cleanFunc := preparer.ShouldCleanMakeDeps(run, cmdArgs, pkgBuildDirs); cleanFunc != nil {
 		installer.AddPostInstallHook(cleanFunc)
 	}
 
@@ -63,6 +129,10 @@ func (o *OperationService) Run(ctx context.Context, run *runtime.Runtime,
 		installer.AddPostInstallHook(cleanAURDirsFunc)
 	}
 
+	if gcFunc := preparer.ShouldGCBuildDir(run); gcFunc != nil {
+		installer.AddPostInstallHook(gcFunc)
+	}
+
 	go func() {
 		errComp := completion.Update(ctx, run.HTTPClient, o.dbExecutor,
 			o.cfg.AURURL, o.cfg.CompletionPath, o.cfg.CompletionInterval, false)
@@ -77,6 +147,16 @@ func (o *OperationService) Run(ctx context.Context, run *runtime.Runtime,
 		return errInstall
 	}
 
+	targets, errInstall = o.dropUnexpectedNames(srcInfo, targets)
+	if errInstall != nil {
+		return errInstall
+	}
+
+	targets, errInstall = o.dropArchMismatches(srcInfo, targets)
+	if errInstall != nil {
+		return errInstall
+	}
+
 	incompatible, errInstall := srcInfo.IncompatiblePkgs(ctx)
 	if errInstall != nil {
 		return errInstall
@@ -90,8 +170,49 @@ func (o *OperationService) Run(ctx context.Context, run *runtime.Runtime,
 		return errPGP
 	}
 
+	if !o.cfg.NoDebug {
+		makepkgDebug := exe.MakepkgDebugEnabled(o.cfg.MakepkgConf, o.cfg.MakepkgConfExtra)
+		if debug := srcInfo.DebugPkgs(makepkgDebug); len(debug) > 0 {
+			o.logger.Warnln(gotext.Get("debug packages will also be built for: %s (use --no-debug to skip them)",
+				strings.Join(debug, ", ")))
+		}
+	}
+
+	conflicts, errInstall := srcInfo.InstalledConflicts(targets)
+	if errInstall != nil {
+		return errInstall
+	}
+
+	answerConflicts, errConflicts := confirmConflicts(o.logger, conflicts, o.cfg.UseAsk)
+	if errConflicts != nil {
+		return errConflicts
+	}
+
+	installer.SetAnswerConflicts(answerConflicts)
+
+	manualConfirmRequired := o.manualConfirmRequired(cmdArgs)
+
+	if o.cfg.ConfirmUpfront {
+		// last question of the run, pacman transactions no longer ask
+		if manualConfirmRequired && !o.logger.ContinueTask(gotext.Get("Proceed with install?"), true, settings.NoConfirm) {
+			return &settings.ErrUserAbort{}
+		}
+
+		manualConfirmRequired = false
+
+		installer.SetUnattended(true)
+
+		doneDownload := o.tracer.Start(gotext.Get("download"))
+		errDownload := preparer.DownloadSources(ctx, pkgBuildDirs)
+		doneDownload()
+
+		if errDownload != nil {
+			return errDownload
+		}
+	}
+
 	if errInstall := installer.Install(ctx, cmdArgs, targets, pkgBuildDirs,
-		excluded, o.manualConfirmRequired(cmdArgs)); errInstall != nil {
+		excluded, manualConfirmRequired); errInstall != nil {
 		return errInstall
 	}
 
@@ -115,10 +236,120 @@ func (o *OperationService) Run(ctx context.Context, run *runtime.Runtime,
 	return multiErr.Return()
 }
 
+// confirmConflicts settles before anything is built whether the installed
+// packages in conflict may be removed, pacman would only ask once the AUR
+// packages are built. Under --noconfirm pacman answers no, so the removal is
+// only allowed with --useask. It returns whether pacman must answer yes.
+func confirmConflicts(logger *text.Logger, conflicts []dep.InstalledConflict, useAsk bool) (bool, error) {
+	if len(conflicts) == 0 {
+		return false, nil
+	}
+
+	logger.Warnln(gotext.Get("The following installed packages are in conflict with the packages to install:"))
+
+	for _, conflict := range conflicts {
+		logger.Println("  " + gotext.Get("%s conflicts with %s", text.Cyan(conflict.Name), text.Cyan(conflict.Installed)))
+	}
+
+	logger.Println()
+
+	if settings.NoConfirm && !useAsk {
+		return false, errors.New(gotext.Get("conflicting packages can not be removed with --noconfirm, use --useask to remove them"))
+	}
+
+	if !logger.ContinueTask(gotext.Get("Remove the conflicting packages?"), useAsk, settings.NoConfirm) {
+		return false, &settings.ErrUserAbort{}
+	}
+
+	return true, nil
+}
+
 func (o *OperationService) manualConfirmRequired(cmdArgs *parser.Arguments) bool {
 	return (!cmdArgs.ExistsArg("u", "sysupgrade") && cmdArgs.Op != "Y") || o.cfg.DoubleConfirm
 }
 
+// dropUnexpectedNames removes from targets the packages whose fetched
+// PKGBUILD builds other packages than resolved. The run is aborted when such
+// a package is a dependency of other targets.
+func (o *OperationService) dropUnexpectedNames(srcInfo *srcinfo.Service,
+	targets []map[string]*dep.InstallInfo,
+) ([]map[string]*dep.InstallInfo, error) {
+	for _, mismatch := range srcInfo.UnexpectedNames(targets) {
+		if mismatch.FoundBase != mismatch.Base {
+			o.logger.Errorln(gotext.Get("%s: the PKGBUILD now has pkgbase %s, it may have been tampered with",
+				text.Cyan(mismatch.Base), text.Bold(text.Red(mismatch.FoundBase))))
+		}
+
+		if len(mismatch.Missing) > 0 {
+			o.logger.Errorln(gotext.Get("%s: the PKGBUILD no longer builds %s, it may have been tampered with",
+				text.Cyan(mismatch.Base), text.Bold(text.Red(strings.Join(mismatch.Missing, ", ")))))
+		}
+
+		srcInfo.Exclude(mismatch.Base)
+
+		for i, layer := range targets {
+			for name, info := range layer {
+				if info.AURBase == nil || *info.AURBase != mismatch.Base {
+					continue
+				}
+
+				// layers are installed from last to first, later layers hold dependencies
+				if i > 0 {
+					return nil, errors.New(gotext.Get("%s is required by other targets, aborting", mismatch.Base))
+				}
+
+				delete(layer, name)
+			}
+		}
+
+		o.logger.Warnln(gotext.Get("Skipping %s, review its PKGBUILD before installing it", text.Cyan(mismatch.Base)))
+	}
+
+	return targets, nil
+}
+
+// dropArchMismatches drops the AUR targets qualified with an architecture
+// their .SRCINFO does not build them for, before they are found incompatible.
+func (o *OperationService) dropArchMismatches(srcInfo *srcinfo.Service,
+	targets []map[string]*dep.InstallInfo,
+) ([]map[string]*dep.InstallInfo, error) {
+	for _, mismatch := range srcInfo.ArchMismatches(targets) {
+		for i, layer := range targets {
+			if _, ok := layer[mismatch.Name]; !ok {
+				continue
+			}
+
+			// layers are installed from last to first, later layers hold dependencies
+			if i > 0 {
+				return nil, errors.New(gotext.Get("%s is required by other targets, aborting", mismatch.Name))
+			}
+
+			delete(layer, mismatch.Name)
+		}
+
+		o.logger.Warnln(gotext.Get("%s is built for %s, not %s -- skipping",
+			text.Cyan(mismatch.Name), strings.Join(mismatch.Arches, " "), mismatch.Arch))
+
+		if !baseInTargets(targets, mismatch.Base) {
+			srcInfo.Exclude(mismatch.Base)
+		}
+	}
+
+	return targets, nil
+}
+
+func baseInTargets(targets []map[string]*dep.InstallInfo, base string) bool {
+	for _, layer := range targets {
+		for _, info := range layer {
+			if info.AURBase != nil && *info.AURBase == base {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
 func confirmIncompatible(logger *text.Logger, incompatible []string) error {
 	if len(incompatible) > 0 {
 		logger.Warnln(gotext.Get("The following packages are not compatible with your architecture:"))
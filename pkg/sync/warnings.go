@@ -0,0 +1,41 @@
+package sync
+
+import (
+	"encoding/json"
+
+	"github.com/leonelquinteros/gotext"
+
+	"github.com/Jguer/yippee/v12/pkg/text"
+)
+
+// reportWarnings repeats the warnings printed during the run once it is over,
+// as they scroll off the screen during long builds. With asJSON they are
+// printed as a JSON object instead.
+func reportWarnings(logger *text.Logger, asJSON bool) {
+	warnings := logger.Warnings()
+
+	if asJSON {
+		marshalled, err := json.Marshal(struct {
+			Warnings []string `json:"warnings"`
+		}{warnings})
+		if err != nil {
+			logger.Errorln(err)
+			return
+		}
+
+		logger.Println(string(marshalled))
+
+		return
+	}
+
+	if len(warnings) == 0 {
+		return
+	}
+
+	logger.OperationInfoln(gotext.GetN("%d warning during this run:", "%d warnings during this run:",
+		len(warnings), len(warnings)))
+
+	for _, warning := range warnings {
+		logger.Println(logger.SprintWarn(warning))
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"runtime"
+	"strings"
 	"sync"
 
 	mapset "github.com/deckarep/golang-set/v2"
@@ -29,9 +30,11 @@ func (e *ErrDownloadSource) Unwrap() error {
 	return e.inner
 }
 
+// downloadPKGBUILDSource downloads and verifies the sources of the PKGBUILD
+// in pkgBuildDir, returning the verification of each source.
 func downloadPKGBUILDSource(ctx context.Context,
 	cmdBuilder exe.ICmdBuilder, pkgBuildDir string, installIncompatible bool,
-) error {
+) ([]sourceCheck, error) {
 	args := []string{"--verifysource", "--skippgpcheck", "-f"}
 
 	if !cmdBuilder.GetKeepSrc() {
@@ -42,41 +45,60 @@ func downloadPKGBUILDSource(ctx context.Context,
 		args = append(args, "--ignorearch")
 	}
 
-	err := cmdBuilder.Show(
-		cmdBuilder.BuildMakepkgCmd(ctx, pkgBuildDir, args...))
+	var stderr strings.Builder
+
+	cmd := cmdBuilder.BuildMakepkgCmd(ctx, pkgBuildDir, args...)
+	cmd.Stderr = &stderr
+
+	err := cmdBuilder.Show(cmd)
+	checks := parseChecksumReport(stderr.String())
+
 	if err != nil {
-		return ErrDownloadSource{inner: err, pkgName: pkgBuildDir}
+		return checks, ErrDownloadSource{inner: err, pkgName: pkgBuildDir}
 	}
 
-	return nil
+	return checks, nil
+}
+
+// sourceReport is the verification of the sources of a PKGBUILD directory.
+type sourceReport struct {
+	dir    string
+	checks []sourceCheck
 }
 
 func downloadPKGBUILDSourceWorker(ctx context.Context, wg *sync.WaitGroup,
-	dirChannel <-chan string, valOut chan<- string, errOut chan<- error,
+	dirChannel <-chan string, valOut chan<- sourceReport, errOut chan<- error,
 	cmdBuilder exe.ICmdBuilder, incompatible bool,
 ) {
 	for pkgBuildDir := range dirChannel {
-		err := downloadPKGBUILDSource(ctx, cmdBuilder, pkgBuildDir, incompatible)
+		checks, err := downloadPKGBUILDSource(ctx, cmdBuilder, pkgBuildDir, incompatible)
+		valOut <- sourceReport{dir: pkgBuildDir, checks: checks}
+
 		if err != nil {
 			errOut <- ErrDownloadSource{inner: err, pkgName: pkgBuildDir, errOut: ""}
-		} else {
-			valOut <- pkgBuildDir
 		}
 	}
 
 	wg.Done()
 }
 
+// downloadPKGBUILDSourceFanout downloads the sources of pkgBuildDirs in
+// parallel and returns the verification of the sources of each directory.
 func downloadPKGBUILDSourceFanout(ctx context.Context, cmdBuilder exe.ICmdBuilder, pkgBuildDirs map[string]string,
 	incompatible bool, maxConcurrentDownloads int,
-) error {
+) (map[string][]sourceCheck, error) {
+	checksByDir := make(map[string][]sourceCheck, len(pkgBuildDirs))
+
 	if len(pkgBuildDirs) == 0 {
-		return nil // no work to do
+		return checksByDir, nil // no work to do
 	}
 
 	if len(pkgBuildDirs) == 1 {
 		for _, pkgBuildDir := range pkgBuildDirs {
-			return downloadPKGBUILDSource(ctx, cmdBuilder, pkgBuildDir, incompatible)
+			checks, err := downloadPKGBUILDSource(ctx, cmdBuilder, pkgBuildDir, incompatible)
+			checksByDir[pkgBuildDir] = checks
+
+			return checksByDir, err
 		}
 	}
 
@@ -84,14 +106,17 @@ func downloadPKGBUILDSourceFanout(ctx context.Context, cmdBuilder exe.ICmdBuilde
 		numOfWorkers    = runtime.NumCPU()
 		wg              = &sync.WaitGroup{}
 		c               = make(chan string)
-		fanInChanValues = make(chan string)
+		fanInChanValues = make(chan sourceReport)
 		fanInChanErrors = make(chan error)
 	)
 
-	if maxConcurrentDownloads != 0 {
+	if maxConcurrentDownloads > 0 {
 		numOfWorkers = maxConcurrentDownloads
 	}
 
+	// no more workers than there are packages to download
+	numOfWorkers = min(numOfWorkers, len(pkgBuildDirs))
+
 	dedupSet := mapset.NewThreadUnsafeSet[string]()
 
 	go func() {
@@ -124,10 +149,12 @@ func downloadPKGBUILDSourceFanout(ctx context.Context, cmdBuilder exe.ICmdBuilde
 receiver:
 	for {
 		select {
-		case _, ok := <-fanInChanValues:
+		case report, ok := <-fanInChanValues:
 			if !ok {
 				break receiver
 			}
+
+			checksByDir[report.dir] = report.checks
 		case err, ok := <-fanInChanErrors:
 			if !ok {
 				break receiver
@@ -136,5 +163,5 @@ receiver:
 		}
 	}
 
-	return returnErr.Return()
+	return checksByDir, returnErr.Return()
 }
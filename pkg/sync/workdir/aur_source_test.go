@@ -93,7 +93,7 @@ func Test_downloadPKGBUILDSource(t *testing.T) {
 				want:    tc.want,
 				wantDir: "/tmp/yippee-bin",
 			}
-			err := downloadPKGBUILDSource(context.Background(), cmdBuilder, filepath.Join("/tmp", "yippee-bin"), false)
+			_, err := downloadPKGBUILDSource(context.Background(), cmdBuilder, filepath.Join("/tmp", "yippee-bin"), false)
 			assert.NoError(t, err)
 			assert.Equal(t, 1, int(cmdBuilder.passes))
 		})
@@ -112,7 +112,7 @@ func Test_downloadPKGBUILDSourceError(t *testing.T) {
 		wantDir:       "/tmp/yippee-bin",
 		showError:     &exec.ExitError{},
 	}
-	err := downloadPKGBUILDSource(context.Background(), cmdBuilder, filepath.Join("/tmp", "yippee-bin"), false)
+	_, err := downloadPKGBUILDSource(context.Background(), cmdBuilder, filepath.Join("/tmp", "yippee-bin"), false)
 	assert.Error(t, err)
 	assert.EqualError(t, err, "error downloading sources: \x1b[36m/tmp/yippee-bin\x1b[0m \n\t context: <nil> \n\t \n")
 }
@@ -140,7 +140,7 @@ func Test_downloadPKGBUILDSourceFanout(t *testing.T) {
 				test: t,
 			}
 
-			err := downloadPKGBUILDSourceFanout(context.Background(), cmdBuilder, pkgBuildDirs, true, maxConcurrentDownloads)
+			_, err := downloadPKGBUILDSourceFanout(context.Background(), cmdBuilder, pkgBuildDirs, true, maxConcurrentDownloads)
 			assert.NoError(t, err)
 			assert.Equal(t, 5, int(cmdBuilder.passes))
 		})
@@ -162,7 +162,7 @@ func Test_downloadPKGBUILDSourceFanoutNoCC(t *testing.T) {
 
 	pkgBuildDirs := map[string]string{"yippee": "/tmp/yippee"}
 
-	err := downloadPKGBUILDSourceFanout(context.Background(), cmdBuilder, pkgBuildDirs, false, 0)
+	_, err := downloadPKGBUILDSourceFanout(context.Background(), cmdBuilder, pkgBuildDirs, false, 0)
 	assert.NoError(t, err)
 	assert.Equal(t, 1, int(cmdBuilder.passes))
 }
@@ -189,7 +189,7 @@ func Test_downloadPKGBUILDSourceFanoutError(t *testing.T) {
 		"yippee-v12": "/tmp/yippee-v12",
 	}
 
-	err := downloadPKGBUILDSourceFanout(context.Background(), cmdBuilder, pkgBuildDirs, false, 0)
+	_, err := downloadPKGBUILDSourceFanout(context.Background(), cmdBuilder, pkgBuildDirs, false, 0)
 	assert.Error(t, err)
 	assert.Equal(t, 5, int(cmdBuilder.passes))
 	assert.Len(t, err.(*multierror.MultiError).Errors, 5)
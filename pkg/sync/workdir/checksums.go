@@ -0,0 +1,154 @@
+package workdir
+
+import (
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	gosrc "github.com/Morganamilo/go-srcinfo"
+	"github.com/leonelquinteros/gotext"
+
+	"github.com/Jguer/yippee/v12/pkg/text"
+)
+
+// sourceStatus is the outcome of the checksum verification of a source.
+type sourceStatus int
+
+const (
+	sourceSkipped sourceStatus = iota
+	sourceVerified
+	sourceFailed
+)
+
+func (s sourceStatus) String() string {
+	switch s {
+	case sourceVerified:
+		return text.Green("OK")
+	case sourceFailed:
+		return text.Red("FAILED")
+	default:
+		return text.Bold("SKIP")
+	}
+}
+
+// checksumLineRe matches the verification of a source printed by makepkg.
+var checksumLineRe = regexp.MustCompile(`(?m)^\s+(\S.*?) \.\.\. (Passed|Skipped|FAILED|NOT FOUND)`)
+
+// sourceCheck is the checksum verification of one source file.
+type sourceCheck struct {
+	file   string
+	status sourceStatus
+}
+
+// parseChecksumReport reads the verification of each source from the
+// output of makepkg --verifysource. A source checked with several
+// algorithms fails if any of them fails and is verified if any passes.
+func parseChecksumReport(output string) []sourceCheck {
+	checks := make([]sourceCheck, 0)
+	index := make(map[string]int)
+
+	for _, match := range checksumLineRe.FindAllStringSubmatch(text.StripFormatting(output), -1) {
+		status := sourceSkipped
+
+		switch match[2] {
+		case "Passed":
+			status = sourceVerified
+		case "FAILED", "NOT FOUND":
+			status = sourceFailed
+		}
+
+		i, ok := index[match[1]]
+		if !ok {
+			index[match[1]] = len(checks)
+			checks = append(checks, sourceCheck{file: match[1], status: status})
+
+			continue
+		}
+
+		if status > checks[i].status {
+			checks[i].status = status
+		}
+	}
+
+	return checks
+}
+
+// vcsSources returns the files of the repositories among the sources of the
+// PKGBUILD in dir, their checksums are always skipped.
+func vcsSources(dir string) map[string]bool {
+	vcs := make(map[string]bool)
+
+	srcinfo, err := gosrc.ParseFile(filepath.Join(dir, ".SRCINFO"))
+	if err != nil {
+		return vcs
+	}
+
+	for _, source := range srcinfo.Source {
+		if src, ok := parseRemoteSource(source.Value); ok && src.vcs {
+			vcs[src.file] = true
+		}
+	}
+
+	return vcs
+}
+
+type ErrSkippedChecksums struct {
+	sources []string
+}
+
+func (e ErrSkippedChecksums) Error() string {
+	return gotext.Get("sources not verified by a checksum: %s", strings.Join(e.sources, ", "))
+}
+
+// reportChecksums prints how the sources of each PKGBUILD were verified.
+// Sources without a checksum are a warning, or an error when strict is set,
+// unless they are repositories.
+func (preper *Preparer) reportChecksums(pkgBuildDirsByBase map[string]string,
+	checksByDir map[string][]sourceCheck, strict bool,
+) error {
+	bases := make([]string, 0, len(pkgBuildDirsByBase))
+	for base := range pkgBuildDirsByBase {
+		bases = append(bases, base)
+	}
+
+	sort.Strings(bases)
+
+	header := false
+	skipped := make([]string, 0)
+
+	for _, base := range bases {
+		checks := checksByDir[pkgBuildDirsByBase[base]]
+		if len(checks) == 0 {
+			continue
+		}
+
+		vcs := vcsSources(pkgBuildDirsByBase[base])
+
+		for _, check := range checks {
+			if !header {
+				preper.log.OperationInfoln(gotext.Get("Checksum verification:"))
+				header = true
+			}
+
+			preper.log.Println("  " + text.Cyan(base) + " " + check.file + " " + check.status.String())
+
+			if check.status == sourceSkipped && !vcs[check.file] {
+				skipped = append(skipped, base+"/"+check.file)
+			}
+		}
+	}
+
+	if len(skipped) == 0 {
+		return nil
+	}
+
+	if strict {
+		return ErrSkippedChecksums{sources: skipped}
+	}
+
+	preper.log.Warnln(gotext.GetN("%d source is not verified by a checksum",
+		"%d sources are not verified by a checksum", len(skipped), len(skipped)))
+
+	return nil
+}
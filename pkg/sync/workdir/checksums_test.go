@@ -0,0 +1,135 @@
+//go:build !integration
+// +build !integration
+
+package workdir
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jguer/yippee/v12/pkg/settings"
+	"github.com/Jguer/yippee/v12/pkg/settings/exe"
+	"github.com/Jguer/yippee/v12/pkg/text"
+)
+
+const verifyOutput = `==> Making package: foo-git 1.0-1 (Sat 01 Jan 2022 00:00:00 UTC)
+==> Retrieving sources...
+  -> Cloning foo git repo...
+==> Validating source files with sha256sums...
+    foo ... Skipped
+    foo.tar.gz ... Passed
+    foo.service ... Skipped
+    fix.patch ... FAILED
+    missing.patch ... NOT FOUND
+==> Validating source files with b2sums...
+    foo ... Skipped
+    foo.tar.gz ... Passed
+    foo.service ... Skipped
+    fix.patch ... Passed
+    missing.patch ... NOT FOUND
+==> ERROR: One or more files did not pass the validity check!
+`
+
+// verifyMakepkgBuilder runs makepkg as if it printed output.
+type verifyMakepkgBuilder struct {
+	exe.ICmdBuilder
+	output string
+}
+
+func (z *verifyMakepkgBuilder) BuildMakepkgCmd(ctx context.Context, dir string, extraArgs ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "makepkg", extraArgs...)
+	cmd.Dir = dir
+
+	return cmd
+}
+
+func (z *verifyMakepkgBuilder) Show(cmd *exec.Cmd) error {
+	_, err := io.WriteString(cmd.Stderr, z.output)
+	return err
+}
+
+func (z *verifyMakepkgBuilder) GetKeepSrc() bool {
+	return false
+}
+
+func TestParseChecksumReport(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []sourceCheck{
+		{file: "foo", status: sourceSkipped},
+		{file: "foo.tar.gz", status: sourceVerified},
+		{file: "foo.service", status: sourceSkipped},
+		{file: "fix.patch", status: sourceFailed},
+		{file: "missing.patch", status: sourceFailed},
+	}, parseChecksumReport(verifyOutput))
+
+	colored := "\x1b[1m    bar.tar.gz ... \x1b[0mPassed\n"
+	assert.Equal(t, []sourceCheck{{file: "bar.tar.gz", status: sourceVerified}}, parseChecksumReport(colored))
+
+	assert.Empty(t, parseChecksumReport("==> Retrieving sources...\n"))
+}
+
+func TestDownloadSourcesChecksumReport(t *testing.T) {
+	t.Parallel()
+
+	const srcinfo = `pkgbase = foo-git
+	pkgver = 1.0
+	pkgrel = 1
+	arch = any
+	source = git+https://example.org/foo.git
+	source = https://example.org/foo.tar.gz
+	source = foo.service
+	sha256sums = SKIP
+	sha256sums = 0000
+	sha256sums = SKIP
+
+pkgname = foo-git
+`
+
+	testCases := []struct {
+		desc        string
+		output      string
+		strict      bool
+		wantErr     bool
+		wantWarning bool
+	}{
+		{desc: "repositories are not reported", output: "    foo ... Skipped\n    foo.tar.gz ... Passed\n"},
+		{desc: "skipped source warns", output: "    foo.service ... Skipped\n", wantWarning: true},
+		{desc: "skipped source with strict", output: "    foo.service ... Skipped\n", strict: true, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(dir, ".SRCINFO"), []byte(srcinfo), 0o644))
+
+			var stdout strings.Builder
+
+			logger := text.NewLogger(&stdout, io.Discard, strings.NewReader(""), false, "test")
+			preper := NewPreparerWithoutHooks(nil, &verifyMakepkgBuilder{output: tc.output},
+				&settings.Configuration{StrictChecksums: tc.strict}, logger, true)
+
+			err := preper.DownloadSources(context.Background(), map[string]string{"foo-git": dir})
+			if tc.wantErr {
+				assert.True(t, errors.As(err, &ErrSkippedChecksums{}))
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Contains(t, stdout.String(), "Checksum verification:")
+			assert.Equal(t, tc.wantWarning, len(logger.Warnings()) == 1)
+		})
+	}
+}
@@ -17,6 +17,7 @@ func removeMake(ctx context.Context, config *settings.Configuration,
 ) error {
 	removeArguments := cmdArgs.CopyGlobal()
 
+	// --unneeded keeps the ones installed packages still require
 	err := removeArguments.AddArg("R", "s", "u")
 	if err != nil {
 		return err
@@ -42,7 +43,8 @@ func cleanAfter(ctx context.Context, run *runtime.Runtime,
 
 	i := 0
 	for _, dir := range pkgbuildDirs {
-		run.Logger.OperationInfoln(gotext.Get("Cleaning (%d/%d): %s", i+1, len(pkgbuildDirs), text.Cyan(dir)))
+		run.Logger.OperationInfoln(gotext.Get("Cleaning %s: %s",
+			text.Progress(i+1, len(pkgbuildDirs)), text.Cyan(text.Isolate(dir))))
 
 		_, stderr, err := cmdBuilder.Capture(
 			cmdBuilder.BuildGitCmd(
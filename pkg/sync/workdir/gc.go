@@ -0,0 +1,47 @@
+package workdir
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Jguer/yippee/v12/pkg/download"
+	"github.com/Jguer/yippee/v12/pkg/runtime"
+	"github.com/Jguer/yippee/v12/pkg/sync/build"
+)
+
+// gcStampFile records the last time the build dir repositories were collected.
+const gcStampFile = ".yippee-gc"
+
+// gcDue reports whether interval days passed since the stamp was touched.
+func gcDue(stampPath string, interval int, now time.Time) bool {
+	if interval <= 0 {
+		return false
+	}
+
+	info, err := os.Stat(stampPath)
+	if err != nil {
+		return true
+	}
+
+	return now.Sub(info.ModTime()) >= time.Duration(interval)*24*time.Hour
+}
+
+func (preper *Preparer) ShouldGCBuildDir(run *runtime.Runtime) build.PostInstallHookFunc {
+	stampPath := filepath.Join(preper.cfg.BuildDir, gcStampFile)
+	if !gcDue(stampPath, preper.cfg.GCInterval, time.Now()) {
+		return nil
+	}
+
+	preper.log.Debugln("added post install hook to garbage collect", preper.cfg.BuildDir)
+
+	return func(ctx context.Context) error {
+		if err := download.GCRepos(ctx, run.CmdBuilder, preper.log,
+			preper.cfg.BuildDir, preper.cfg.ShallowClone); err != nil {
+			preper.log.Warnln(err)
+		}
+
+		return os.WriteFile(stampPath, []byte{}, 0o644)
+	}
+}
@@ -0,0 +1,27 @@
+package workdir
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGCDue(t *testing.T) {
+	t.Parallel()
+
+	stampPath := filepath.Join(t.TempDir(), gcStampFile)
+	now := time.Now()
+
+	assert.False(t, gcDue(stampPath, 0, now))
+	assert.True(t, gcDue(stampPath, 7, now))
+
+	require.NoError(t, os.WriteFile(stampPath, []byte{}, 0o644))
+	require.NoError(t, os.Chtimes(stampPath, now.Add(-48*time.Hour), now.Add(-48*time.Hour)))
+
+	assert.False(t, gcDue(stampPath, 7, now))
+	assert.True(t, gcDue(stampPath, 2, now))
+}
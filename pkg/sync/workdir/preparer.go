@@ -6,12 +6,16 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/Jguer/yippee/v12/pkg/db"
 	"github.com/Jguer/yippee/v12/pkg/dep"
 	"github.com/Jguer/yippee/v12/pkg/download"
 	"github.com/Jguer/yippee/v12/pkg/menus"
+	"github.com/Jguer/yippee/v12/pkg/query"
 	"github.com/Jguer/yippee/v12/pkg/runtime"
 	"github.com/Jguer/yippee/v12/pkg/settings"
 	"github.com/Jguer/yippee/v12/pkg/settings/exe"
@@ -110,16 +114,32 @@ func (preper *Preparer) ShouldCleanAURDirs(run *runtime.Runtime, pkgBuildDirs ma
 	}
 }
 
-func (preper *Preparer) ShouldCleanMakeDeps(run *runtime.Runtime, cmdArgs *parser.Arguments) build.PostInstallHookFunc {
+func (preper *Preparer) ShouldCleanMakeDeps(run *runtime.Runtime, cmdArgs *parser.Arguments,
+	pkgBuildDirsByBase map[string]string,
+) build.PostInstallHookFunc {
 	if len(preper.makeDeps) == 0 {
 		return nil
 	}
 
+	makeDeps := preper.makeDeps
+
 	switch preper.cfg.RemoveMake {
 	case "yes":
 		break
 	case "no":
 		return nil
+	case "ask-per-package":
+		selected, err := preper.selectMakeDeps(pkgBuildDirsByBase)
+		if err != nil {
+			preper.log.Errorln(err)
+			return nil
+		}
+
+		if len(selected) == 0 {
+			return nil
+		}
+
+		makeDeps = selected
 	default:
 		isYesDefault := preper.cfg.RemoveMake == "askyes"
 		if !preper.log.ContinueTask(gotext.Get("Remove make dependencies after install?"),
@@ -128,10 +148,10 @@ func (preper *Preparer) ShouldCleanMakeDeps(run *runtime.Runtime, cmdArgs *parse
 		}
 	}
 
-	preper.log.Debugln("added post install hook to clean up AUR makedeps", preper.makeDeps)
+	preper.log.Debugln("added post install hook to clean up AUR makedeps", makeDeps)
 
 	return func(ctx context.Context) error {
-		return removeMake(ctx, preper.cfg, run.CmdBuilder, preper.makeDeps, cmdArgs)
+		return removeMake(ctx, preper.cfg, run.CmdBuilder, makeDeps, cmdArgs)
 	}
 }
 
@@ -149,6 +169,10 @@ func (preper *Preparer) Run(ctx context.Context, run *runtime.Runtime,
 }
 
 func (preper *Preparer) Present(targets []map[string]*dep.InstallInfo) {
+	if preper.cfg.VerbosePkgLists {
+		preper.presentTable(targets)
+	}
+
 	pkgsBySourceAndReason := map[string]map[string][]string{}
 
 	for _, layer := range targets {
@@ -169,12 +193,18 @@ func (preper *Preparer) Present(targets []map[string]*dep.InstallInfo) {
 
 			pkgsBySourceAndReason[source][reason] = append(pkgsBySourceAndReason[source][reason], pkgStr)
 
-			if info.Reason == dep.MakeDep {
+			// the graph promotes make dependencies other targets depend on
+			// to dependencies, checkdepends are only resolved when check() runs
+			if info.Reason == dep.MakeDep || info.Reason == dep.CheckDep {
 				preper.makeDeps = append(preper.makeDeps, pkgName)
 			}
 		}
 	}
 
+	if preper.cfg.VerbosePkgLists {
+		return
+	}
+
 	for source, pkgsByReason := range pkgsBySourceAndReason {
 		for reason, pkgs := range pkgsByReason {
 			preper.log.Printf(text.Bold("%s %s (%d):")+" %s\n",
@@ -186,10 +216,72 @@ func (preper *Preparer) Present(targets []map[string]*dep.InstallInfo) {
 	}
 }
 
+// presentTable prints the packages to install as a table, the way pacman does
+// with VerbosePkgLists.
+func (preper *Preparer) presentTable(targets []map[string]*dep.InstallInfo) {
+	infos := map[string]*dep.InstallInfo{}
+	names := make([]string, 0, len(targets))
+
+	for _, layer := range targets {
+		for pkgName, info := range layer {
+			infos[pkgName] = info
+			names = append(names, pkgName)
+		}
+	}
+
+	sort.Strings(names)
+
+	table := text.NewTable(gotext.Get("Name"), gotext.Get("Old Version"), gotext.Get("New Version"),
+		gotext.Get("Source"), gotext.Get("Build Required"))
+
+	for _, pkgName := range names {
+		info := infos[pkgName]
+
+		left, right := info.LocalVersion, info.Version
+		if left != "" {
+			left, right = query.GetVersionDiff(info.LocalVersion, info.Version)
+		}
+
+		source := dep.SourceNames[info.Source]
+		if info.Source == dep.Sync && info.SyncDBName != nil {
+			source = *info.SyncDBName
+		}
+
+		build := gotext.Get("No")
+		if info.Source == dep.AUR || info.Source == dep.SrcInfo {
+			build = gotext.Get("Yes")
+		}
+
+		table.AddRow(text.Cyan(pkgName), left, right, text.Bold(text.ColorHash(source)), build)
+	}
+
+	preper.log.Print(table.String())
+}
+
+// PKGBUILDDirs returns the directory the PKGBUILD of every AUR and .SRCINFO
+// base in targets is built in, without cloning anything.
+func (preper *Preparer) PKGBUILDDirs(targets []map[string]*dep.InstallInfo) map[string]string {
+	pkgBuildDirsByBase := make(map[string]string, len(targets))
+
+	for _, layer := range targets {
+		for _, info := range layer {
+			switch info.Source {
+			case dep.AUR:
+				pkgBuildDirsByBase[*info.AURBase] = filepath.Join(preper.cfg.BuildDir, *info.AURBase)
+			case dep.SrcInfo:
+				pkgBuildDirsByBase[*info.AURBase] = *info.SrcinfoPath
+			}
+		}
+	}
+
+	return pkgBuildDirsByBase
+}
+
 func (preper *Preparer) PrepareWorkspace(ctx context.Context,
 	run *runtime.Runtime, targets []map[string]*dep.InstallInfo,
 ) (map[string]string, error) {
 	aurBasesToClone := mapset.NewThreadUnsafeSet[string]()
+	aurBases := mapset.NewThreadUnsafeSet[string]()
 	pkgBuildDirsByBase := make(map[string]string, len(targets))
 
 	for _, layer := range targets {
@@ -197,10 +289,11 @@ func (preper *Preparer) PrepareWorkspace(ctx context.Context,
 			if info.Source == dep.AUR {
 				pkgBase := *info.AURBase
 				pkgBuildDir := filepath.Join(preper.cfg.BuildDir, pkgBase)
-				if preper.needToCloneAURBase(info, pkgBuildDir) {
+				if preper.needToCloneAURBase(ctx, info, pkgBuildDir) {
 					aurBasesToClone.Add(pkgBase)
 				}
 				pkgBuildDirsByBase[pkgBase] = pkgBuildDir
+				aurBases.Add(pkgBase)
 			} else if info.Source == dep.SrcInfo {
 				pkgBase := *info.AURBase
 				pkgBuildDirsByBase[pkgBase] = *info.SrcinfoPath
@@ -208,10 +301,28 @@ func (preper *Preparer) PrepareWorkspace(ctx context.Context,
 		}
 	}
 
-	if _, errA := download.AURPKGBUILDRepos(ctx,
-		preper.cmdBuilder, preper.log.Child("download"), aurBasesToClone.ToSlice(),
-		preper.cfg.AURURL, preper.cfg.BuildDir, false); errA != nil {
-		return nil, errA
+	// bases are cloned from the AUR compatible backend they were found on
+	basesByURL := make(map[string][]string)
+	for _, base := range aurBasesToClone.ToSlice() {
+		_, aurURL := query.Origin(run.AURClient, base, preper.cfg.AURURL)
+		basesByURL[aurURL] = append(basesByURL[aurURL], base)
+	}
+
+	aurURLs := make([]string, 0, len(basesByURL))
+	for aurURL := range basesByURL {
+		aurURLs = append(aurURLs, aurURL)
+	}
+
+	sort.Strings(aurURLs)
+
+	for _, aurURL := range aurURLs {
+		if _, errA := download.AURPKGBUILDRepos(ctx,
+			preper.cmdBuilder, preper.log.Child("download"), run.Events,
+			&download.SnapshotSource{HTTPClient: run.HTTPClient, Prefer: preper.cfg.PKGBUILDClone == "snapshot"},
+			basesByURL[aurURL],
+			aurURL, preper.cfg.BuildDir, false, preper.cfg.ShallowClone); errA != nil {
+			return nil, errA
+		}
 	}
 
 	if !preper.downloadSources {
@@ -232,15 +343,41 @@ func (preper *Preparer) PrepareWorkspace(ctx context.Context,
 		}
 	}
 
-	if errP := downloadPKGBUILDSourceFanout(ctx, preper.cmdBuilder,
-		pkgBuildDirsByBase, false, preper.cfg.MaxConcurrentDownloads); errP != nil {
-		preper.log.Errorln(errP)
+	switch {
+	case preper.cfg.VersionedBuildDirs:
+		preper.checkoutVersionDirs(ctx, pkgBuildDirsByBase, aurBases.ToSlice())
+	case preper.cfg.Worktrees:
+		preper.checkoutWorktrees(ctx, pkgBuildDirsByBase, aurBases.ToSlice())
+	}
+
+	preper.presentSources(pkgBuildDirsByBase)
+
+	// sources are fetched once every question has been answered
+	if preper.cfg.ConfirmUpfront {
+		return pkgBuildDirsByBase, nil
+	}
+
+	if err := preper.DownloadSources(ctx, pkgBuildDirsByBase); err != nil {
+		return nil, err
 	}
 
 	return pkgBuildDirsByBase, nil
 }
 
-func (preper *Preparer) needToCloneAURBase(installInfo *dep.InstallInfo, pkgbuildDir string) bool {
+// DownloadSources fetches the sources of every PKGBUILD in pkgBuildDirsByBase
+// and reports how they were verified. Failed downloads are only printed, the
+// error returned is for sources not verified under StrictChecksums.
+func (preper *Preparer) DownloadSources(ctx context.Context, pkgBuildDirsByBase map[string]string) error {
+	checksByDir, errP := downloadPKGBUILDSourceFanout(ctx, preper.cmdBuilder,
+		pkgBuildDirsByBase, false, preper.cfg.MaxConcurrentDownloads)
+	if errP != nil {
+		preper.log.Errorln(errP)
+	}
+
+	return preper.reportChecksums(pkgBuildDirsByBase, checksByDir, preper.cfg.StrictChecksums)
+}
+
+func (preper *Preparer) needToCloneAURBase(ctx context.Context, installInfo *dep.InstallInfo, pkgbuildDir string) bool {
 	if preper.cfg.ReDownload == "all" {
 		return true
 	}
@@ -248,6 +385,14 @@ func (preper *Preparer) needToCloneAURBase(installInfo *dep.InstallInfo, pkgbuil
 	srcinfoFile := filepath.Join(pkgbuildDir, ".SRCINFO")
 	if pkgbuild, err := gosrc.ParseFile(srcinfoFile); err == nil {
 		if db.VerCmp(pkgbuild.Version(), installInfo.Version) >= 0 {
+			// a push without a version bump, e.g. a fixed PKGBUILD
+			if preper.cloneOutdated(ctx, installInfo, pkgbuildDir) {
+				preper.log.OperationInfoln(
+					gotext.Get("PKGBUILD changed on the AUR since it was downloaded, pulling: %s",
+						text.Cyan(*installInfo.AURBase)))
+				return true
+			}
+
 			preper.log.OperationInfoln(
 				gotext.Get("PKGBUILD up to date, skipping download: %s",
 					text.Cyan(*installInfo.AURBase)))
@@ -257,3 +402,43 @@ func (preper *Preparer) needToCloneAURBase(installInfo *dep.InstallInfo, pkgbuil
 
 	return true
 }
+
+// cloneOutdated reports whether the AUR was pushed to after the clone in
+// pkgbuildDir was last brought up to date.
+func (preper *Preparer) cloneOutdated(ctx context.Context, installInfo *dep.InstallInfo, pkgbuildDir string) bool {
+	if installInfo.LastModified == 0 {
+		return false
+	}
+
+	updated, err := cloneUpdated(ctx, preper.cmdBuilder, pkgbuildDir)
+	if err != nil {
+		preper.log.Debugln("unable to read the last update of", pkgbuildDir, err)
+		return false
+	}
+
+	return time.Unix(int64(installInfo.LastModified), 0).After(updated)
+}
+
+// cloneUpdated returns when the clone in dir was last brought up to date: the
+// time of its last commit, or of its last fetch when later. Commits are made
+// before being pushed, the fetch time keeps a pulled clone from looking
+// outdated.
+func cloneUpdated(ctx context.Context, cmdBuilder exe.ICmdBuilder, dir string) (time.Time, error) {
+	stdout, stderr, err := cmdBuilder.Capture(cmdBuilder.BuildGitCmd(ctx, dir, "log", "-1", "--format=%ct"))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %s", err, stderr)
+	}
+
+	seconds, err := strconv.ParseInt(strings.TrimSpace(stdout), 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	updated := time.Unix(seconds, 0)
+
+	if info, err := os.Stat(filepath.Join(dir, ".git", "FETCH_HEAD")); err == nil && info.ModTime().After(updated) {
+		updated = info.ModTime()
+	}
+
+	return updated, nil
+}
@@ -4,13 +4,22 @@
 package workdir
 
 import (
+	"context"
 	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/Jguer/yippee/v12/pkg/dep"
 	"github.com/Jguer/yippee/v12/pkg/settings"
+	"github.com/Jguer/yippee/v12/pkg/settings/exe"
 	"github.com/Jguer/yippee/v12/pkg/text"
 )
 
@@ -70,3 +79,77 @@ func TestPreDownloadSourcesHooks(t *testing.T) {
 		})
 	}
 }
+
+func TestNeedToCloneAURBase(t *testing.T) {
+	t.Parallel()
+
+	const srcinfo = "pkgbase = yippee\n\tpkgver = 12.0.0\n\tpkgrel = 1\n\tarch = x86_64\n\npkgname = yippee\n"
+
+	committed := time.Unix(1700000000, 0)
+
+	testCases := []struct {
+		desc         string
+		reDownload   string
+		srcinfo      string
+		version      string
+		lastModified time.Time
+		fetched      time.Time
+		want         bool
+	}{
+		{desc: "redownload all", reDownload: "all", srcinfo: srcinfo, version: "12.0.0-1", want: true},
+		{desc: "no clone", version: "12.0.0-1", want: true},
+		{desc: "newer version", srcinfo: srcinfo, version: "12.0.1-1", want: true},
+		{desc: "up to date", srcinfo: srcinfo, version: "12.0.0-1", lastModified: committed, want: false},
+		{desc: "no last modified", srcinfo: srcinfo, version: "12.0.0-1", want: false},
+		{
+			desc: "pushed after the last commit", srcinfo: srcinfo, version: "12.0.0-1",
+			lastModified: committed.Add(time.Hour), want: true,
+		},
+		{
+			desc: "pushed before the last fetch", srcinfo: srcinfo, version: "12.0.0-1",
+			lastModified: committed.Add(time.Hour), fetched: committed.Add(2 * time.Hour), want: false,
+		},
+		{
+			desc: "pushed after the last fetch", srcinfo: srcinfo, version: "12.0.0-1",
+			lastModified: committed.Add(2 * time.Hour), fetched: committed.Add(time.Hour), want: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			dir := filepath.Join(t.TempDir(), "yippee")
+			require.NoError(t, os.MkdirAll(filepath.Join(dir, ".git"), 0o755))
+
+			if tc.srcinfo != "" {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, ".SRCINFO"), []byte(tc.srcinfo), 0o644))
+			}
+
+			if !tc.fetched.IsZero() {
+				fetchHead := filepath.Join(dir, ".git", "FETCH_HEAD")
+				require.NoError(t, os.WriteFile(fetchHead, nil, 0o644))
+				require.NoError(t, os.Chtimes(fetchHead, tc.fetched, tc.fetched))
+			}
+
+			cmdBuilder := &exe.MockBuilder{Runner: &exe.MockRunner{
+				CaptureFn: func(cmd *exec.Cmd) (stdout, stderr string, err error) {
+					return strconv.FormatInt(committed.Unix(), 10) + "\n", "", nil
+				},
+			}}
+
+			base := "yippee"
+			info := &dep.InstallInfo{Source: dep.AUR, AURBase: &base, Version: tc.version}
+
+			if !tc.lastModified.IsZero() {
+				info.LastModified = int(tc.lastModified.Unix())
+			}
+
+			preper := NewPreparerWithoutHooks(nil, cmdBuilder,
+				&settings.Configuration{ReDownload: tc.reDownload}, newTestLogger(), false)
+
+			assert.Equal(t, tc.want, preper.needToCloneAURBase(context.Background(), info, dir))
+		})
+	}
+}
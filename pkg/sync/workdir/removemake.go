@@ -0,0 +1,190 @@
+package workdir
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	gosrc "github.com/Morganamilo/go-srcinfo"
+	"github.com/leonelquinteros/gotext"
+
+	"github.com/Jguer/yippee/v12/pkg/intrange"
+	"github.com/Jguer/yippee/v12/pkg/settings"
+	"github.com/Jguer/yippee/v12/pkg/text"
+)
+
+// makeDepChoices remembers whether each make dependency is removed after
+// install, for --removemake=ask-per-package.
+type makeDepChoices struct {
+	filePath string
+	remove   map[string]bool
+}
+
+// loadMakeDepChoices reads the remembered answers. A missing file is not an
+// error.
+func loadMakeDepChoices(filePath string) (*makeDepChoices, error) {
+	choices := &makeDepChoices{filePath: filePath, remove: make(map[string]bool)}
+
+	content, err := os.ReadFile(filePath)
+	if os.IsNotExist(err) {
+		return choices, nil
+	}
+
+	if err != nil {
+		return choices, err
+	}
+
+	if err := json.Unmarshal(content, &choices.remove); err != nil {
+		return choices, err
+	}
+
+	return choices, nil
+}
+
+func (c *makeDepChoices) save() error {
+	marshalled, err := json.MarshalIndent(c.remove, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(c.filePath, marshalled, 0o644)
+}
+
+// pulledInBy returns for each of makeDeps the pkgbases whose PKGBUILD make
+// or check depends on it, directly or through a repository provider.
+func (preper *Preparer) pulledInBy(pkgBuildDirsByBase map[string]string, makeDeps []string) map[string][]string {
+	wanted := make(map[string]bool, len(makeDeps))
+	for _, name := range makeDeps {
+		wanted[name] = true
+	}
+
+	bases := make([]string, 0, len(pkgBuildDirsByBase))
+	for base := range pkgBuildDirsByBase {
+		bases = append(bases, base)
+	}
+
+	sort.Strings(bases)
+
+	pulled := make(map[string][]string)
+
+	for _, base := range bases {
+		srcinfo, err := gosrc.ParseFile(filepath.Join(pkgBuildDirsByBase[base], ".SRCINFO"))
+		if err != nil {
+			continue
+		}
+
+		seen := make(map[string]bool)
+
+		for _, depend := range append(srcinfo.MakeDepends, srcinfo.CheckDepends...) {
+			name := depend.Value
+			if end := strings.IndexAny(name, "<>="); end != -1 {
+				name = name[:end]
+			}
+
+			if !wanted[name] {
+				if pkg := preper.dbExecutor.SyncSatisfier(depend.Value); pkg != nil {
+					name = pkg.Name()
+				}
+			}
+
+			if wanted[name] && !seen[name] {
+				seen[name] = true
+				pulled[name] = append(pulled[name], base)
+			}
+		}
+	}
+
+	return pulled
+}
+
+// selectMakeDeps asks which make dependencies to remove after install,
+// listing the packages that pulled each in. Remembered answers are not asked
+// again and the new ones are remembered on request.
+func (preper *Preparer) selectMakeDeps(pkgBuildDirsByBase map[string]string) ([]string, error) {
+	choices, err := loadMakeDepChoices(preper.cfg.RemoveMakeFilePath)
+	if err != nil {
+		preper.log.Warnln(gotext.Get("unable to read remembered make dependency removals: %s", err))
+	}
+
+	selected := make([]string, 0, len(preper.makeDeps))
+	asked := make([]string, 0, len(preper.makeDeps))
+
+	for _, name := range preper.makeDeps {
+		remove, ok := choices.remove[name]
+		switch {
+		case !ok:
+			asked = append(asked, name)
+		case remove:
+			selected = append(selected, name)
+		}
+	}
+
+	if len(asked) == 0 {
+		return selected, nil
+	}
+
+	sort.Strings(asked)
+
+	pulled := preper.pulledInBy(pkgBuildDirsByBase, asked)
+	toPrint := ""
+
+	for n, name := range asked {
+		toPrint += fmt.Sprintf(text.Magenta("%3d")+" %s", len(asked)-n, text.Bold(name))
+
+		if bases := pulled[name]; len(bases) != 0 {
+			toPrint += " " + gotext.Get("(pulled in by %s)", strings.Join(bases, ", "))
+		}
+
+		toPrint += "\n"
+	}
+
+	preper.log.Print(toPrint)
+	preper.log.Infoln(gotext.Get("Make dependencies to remove after install?"))
+	preper.log.Infoln(gotext.Get("%s [A]ll or (1 2 3, 1-3, ^4)", text.Cyan(gotext.Get("[N]one"))))
+
+	selectInput, err := preper.log.GetInput("", settings.NoConfirm)
+	if err != nil {
+		return nil, err
+	}
+
+	include, exclude, otherInclude, _ := intrange.ParseNumberMenu(selectInput)
+	all := otherInclude.Contains("a") || otherInclude.Contains("all")
+	none := strings.TrimSpace(selectInput) == "" || otherInclude.Contains("n") || otherInclude.Contains("none")
+	answers := make(map[string]bool, len(asked))
+
+	for n, name := range asked {
+		var remove bool
+
+		switch {
+		case all:
+			remove = true
+		case none:
+			remove = false
+		case len(exclude) != 0:
+			remove = !exclude.Get(len(asked) - n)
+		default:
+			remove = include.Get(len(asked) - n)
+		}
+
+		answers[name] = remove
+
+		if remove {
+			selected = append(selected, name)
+		}
+	}
+
+	if preper.log.ContinueTask(gotext.Get("Remember these answers?"), false, settings.NoConfirm) {
+		for name, remove := range answers {
+			choices.remove[name] = remove
+		}
+
+		if err := choices.save(); err != nil {
+			preper.log.Warnln(gotext.Get("unable to remember make dependency removals: %s", err))
+		}
+	}
+
+	return selected, nil
+}
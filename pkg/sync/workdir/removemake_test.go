@@ -0,0 +1,109 @@
+//go:build !integration
+// +build !integration
+
+package workdir
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jguer/yippee/v12/pkg/db/mock"
+	"github.com/Jguer/yippee/v12/pkg/settings"
+	"github.com/Jguer/yippee/v12/pkg/text"
+)
+
+func TestSelectMakeDeps(t *testing.T) {
+	t.Parallel()
+
+	const srcinfo = `pkgbase = foo
+	pkgver = 1.0
+	pkgrel = 1
+	arch = any
+	makedepends = cmake>=3
+	makedepends = java-environment
+	checkdepends = cmake
+
+pkgname = foo
+`
+
+	testCases := []struct {
+		desc       string
+		input      string
+		remembered string
+		want       []string
+		wantSaved  string
+	}{
+		{desc: "none by default", input: "\n\n", want: []string{}},
+		{desc: "all", input: "a\n\n", want: []string{"cmake", "jdk-openjdk", "ninja"}},
+		{desc: "by number", input: "1 3\n\n", want: []string{"cmake", "ninja"}},
+		{desc: "by exclusion", input: "^2\n\n", want: []string{"cmake", "ninja"}},
+		{
+			desc:      "answers remembered",
+			input:     "3\ny\n",
+			want:      []string{"cmake"},
+			wantSaved: `{"cmake":true,"jdk-openjdk":false,"ninja":false}`,
+		},
+		{
+			desc:       "remembered answers not asked",
+			input:      "a\n\n",
+			remembered: `{"cmake":false,"ninja":true}`,
+			want:       []string{"ninja", "jdk-openjdk"},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(dir, ".SRCINFO"), []byte(srcinfo), 0o644))
+
+			choicesPath := filepath.Join(t.TempDir(), "removemake.json")
+			if tc.remembered != "" {
+				require.NoError(t, os.WriteFile(choicesPath, []byte(tc.remembered), 0o644))
+			}
+
+			var out strings.Builder
+
+			logger := text.NewLogger(&out, io.Discard, iotest.OneByteReader(strings.NewReader(tc.input)), false, "test")
+			dbExecutor := mock.NewExecutor().Sync(
+				mock.NewPackage("cmake", "3.28-1"),
+				mock.NewPackage("jdk-openjdk", "21-1").WithProvides("java-environment=21"),
+			).Build()
+
+			preper := NewPreparerWithoutHooks(dbExecutor, nil,
+				&settings.Configuration{RemoveMakeFilePath: choicesPath}, logger, true)
+			preper.makeDeps = []string{"ninja", "cmake", "jdk-openjdk"}
+
+			got, err := preper.selectMakeDeps(map[string]string{"foo": dir})
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+
+			if tc.remembered == "" {
+				assert.Contains(t, out.String(), text.Bold("cmake")+" (pulled in by foo)")
+				assert.Contains(t, out.String(), text.Bold("jdk-openjdk")+" (pulled in by foo)")
+				assert.NotContains(t, out.String(), text.Bold("ninja")+" (pulled in by")
+			}
+
+			saved, err := os.ReadFile(choicesPath)
+			if tc.wantSaved == "" {
+				if tc.remembered == "" {
+					assert.True(t, os.IsNotExist(err))
+				}
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.wantSaved, string(saved))
+		})
+	}
+}
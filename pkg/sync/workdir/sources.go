@@ -0,0 +1,137 @@
+package workdir
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	gosrc "github.com/Morganamilo/go-srcinfo"
+	"github.com/leonelquinteros/gotext"
+
+	"github.com/Jguer/yippee/v12/pkg/db"
+	"github.com/Jguer/yippee/v12/pkg/text"
+)
+
+// remoteSource is a source makepkg downloads, as opposed to the files
+// shipped next to the PKGBUILD.
+type remoteSource struct {
+	url    string // as written, without the file name prefix
+	scheme string // transport used for the download, https for git+https
+	host   string
+	file   string // name makepkg saves the source as
+	vcs    bool   // a repository, which can not have a checksum
+}
+
+var vcsProtocols = map[string]bool{"bzr": true, "fossil": true, "git": true, "hg": true, "svn": true}
+
+// parseRemoteSource parses a source entry of a .SRCINFO, ok is false for
+// local files.
+func parseRemoteSource(source string) (src remoteSource, ok bool) {
+	name, url, named := strings.Cut(source, "::")
+	if !named {
+		url = source
+	}
+
+	src.url = url
+
+	scheme, rest, found := strings.Cut(src.url, "://")
+	if !found {
+		return remoteSource{}, false
+	}
+
+	protocols := strings.Split(scheme, "+")
+	src.scheme = protocols[len(protocols)-1]
+	src.vcs = vcsProtocols[protocols[0]]
+
+	src.host = rest
+	if end := strings.IndexAny(rest, "/?#"); end != -1 {
+		src.host = rest[:end]
+	}
+
+	src.file = name
+	if !named {
+		path, _, _ := strings.Cut(rest, "#")
+		path, _, _ = strings.Cut(path, "?")
+		path = strings.TrimRight(path, "/")
+		src.file = path[strings.LastIndex(path, "/")+1:]
+
+		if protocols[0] == "git" {
+			src.file = strings.TrimSuffix(src.file, ".git")
+		}
+	}
+
+	return src, true
+}
+
+func (src remoteSource) secure() bool {
+	return src.scheme == "https"
+}
+
+// String highlights the host of the source and flags the ones not fetched
+// over HTTPS.
+func (src remoteSource) String() string {
+	prefix, suffix, _ := strings.Cut(src.url, "://"+src.host)
+	str := prefix + "://" + text.Bold(src.host) + suffix
+
+	if !src.secure() {
+		str += " " + text.Red(gotext.Get("(not HTTPS)"))
+	}
+
+	return str
+}
+
+// remoteSources returns the remote sources of srcinfo for the architectures
+// supported by alpm.
+func remoteSources(alpmArch []string, srcinfo *gosrc.Srcinfo) []remoteSource {
+	sources := make([]remoteSource, 0, len(srcinfo.Source))
+
+	for _, source := range srcinfo.Source {
+		if !db.ArchIsSupported(alpmArch, source.Arch) {
+			continue
+		}
+
+		if src, ok := parseRemoteSource(source.Value); ok {
+			sources = append(sources, src)
+		}
+	}
+
+	return sources
+}
+
+// presentSources lists the remote sources of every PKGBUILD before makepkg
+// downloads them, so a suspicious location can be spotted before building.
+func (preper *Preparer) presentSources(pkgBuildDirsByBase map[string]string) {
+	alpmArch, err := preper.dbExecutor.AlpmArchitectures()
+	if err != nil {
+		preper.log.Debugln("unable to list sources:", err)
+		return
+	}
+
+	alpmArch = append(alpmArch, "") // srcinfo assumes no value as ""
+
+	bases := make([]string, 0, len(pkgBuildDirsByBase))
+	for base := range pkgBuildDirsByBase {
+		bases = append(bases, base)
+	}
+
+	sort.Strings(bases)
+
+	header := false
+
+	for _, base := range bases {
+		srcinfo, err := gosrc.ParseFile(filepath.Join(pkgBuildDirsByBase[base], ".SRCINFO"))
+		if err != nil {
+			// reported once the .SRCINFO files are parsed for the build
+			continue
+		}
+
+		for _, src := range remoteSources(alpmArch, srcinfo) {
+			if !header {
+				preper.log.OperationInfoln(gotext.Get("Sources to download:"))
+				header = true
+			}
+
+			preper.log.Println("  " + text.Cyan(base) + " " + src.String())
+		}
+	}
+}
@@ -0,0 +1,103 @@
+//go:build !integration
+// +build !integration
+
+package workdir
+
+import (
+	"testing"
+
+	gosrc "github.com/Morganamilo/go-srcinfo"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Jguer/yippee/v12/pkg/text"
+)
+
+func TestParseRemoteSource(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		source string
+		want   remoteSource
+		ok     bool
+	}{
+		{
+			source: "https://example.org/foo-1.0.tar.gz",
+			want: remoteSource{
+				url: "https://example.org/foo-1.0.tar.gz", scheme: "https", host: "example.org",
+				file: "foo-1.0.tar.gz",
+			},
+			ok: true,
+		},
+		{
+			source: "foo.tar.gz::http://mirror.example.org:8080/foo?dl=1",
+			want: remoteSource{
+				url: "http://mirror.example.org:8080/foo?dl=1", scheme: "http", host: "mirror.example.org:8080",
+				file: "foo.tar.gz",
+			},
+			ok: true,
+		},
+		{
+			source: "git+https://github.com/Jguer/yippee.git#tag=v12.0.0",
+			want: remoteSource{
+				url: "git+https://github.com/Jguer/yippee.git#tag=v12.0.0", scheme: "https", host: "github.com",
+				file: "yippee", vcs: true,
+			},
+			ok: true,
+		},
+		{
+			source: "git://git.example.org/foo.git",
+			want: remoteSource{
+				url: "git://git.example.org/foo.git", scheme: "git", host: "git.example.org",
+				file: "foo", vcs: true,
+			},
+			ok: true,
+		},
+		{source: "foo.install"},
+		{source: "fix.patch::fix-build.patch"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.source, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := parseRemoteSource(tc.source)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestRemoteSourceString(t *testing.T) {
+	t.Parallel()
+
+	secure, _ := parseRemoteSource("git+https://github.com/Jguer/yippee.git")
+	assert.Equal(t, "git+https://"+text.Bold("github.com")+"/Jguer/yippee.git", secure.String())
+
+	insecure, _ := parseRemoteSource("http://example.org/foo.tar.gz")
+	assert.Equal(t, "http://"+text.Bold("example.org")+"/foo.tar.gz "+text.Red("(not HTTPS)"), insecure.String())
+}
+
+func TestRemoteSources(t *testing.T) {
+	t.Parallel()
+
+	srcinfo := &gosrc.Srcinfo{
+		PackageBase: gosrc.PackageBase{
+			Source: []gosrc.ArchString{
+				{Value: "https://example.org/foo.tar.gz"},
+				{Value: "foo.service"},
+				{Arch: "x86_64", Value: "https://example.org/foo-x86_64.bin"},
+				{Arch: "aarch64", Value: "https://example.org/foo-aarch64.bin"},
+			},
+		},
+	}
+
+	sources := remoteSources([]string{"x86_64", ""}, srcinfo)
+
+	urls := make([]string, 0, len(sources))
+	for _, src := range sources {
+		urls = append(urls, src.url)
+	}
+
+	assert.Equal(t, []string{"https://example.org/foo.tar.gz", "https://example.org/foo-x86_64.bin"}, urls)
+}
@@ -0,0 +1,131 @@
+package workdir
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/leonelquinteros/gotext"
+
+	"github.com/Jguer/yippee/v12/pkg/settings/exe"
+)
+
+// versionDir is a versioned build dir kept at the top of a PKGBUILD clone.
+type versionDir struct {
+	name     string
+	lastUsed time.Time
+}
+
+// versionDirs returns the versioned build dirs of the PKGBUILD clone in dir.
+// They are the worktrees checked out at its top level.
+func versionDirs(dir string) ([]versionDir, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	dirs := make([]versionDir, 0)
+
+	for _, entry := range entries {
+		if !entry.IsDir() || entry.Name() == ".git" {
+			continue
+		}
+
+		// linked worktrees have a .git file pointing back to the clone
+		info, err := os.Stat(filepath.Join(dir, entry.Name(), ".git"))
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+
+		dirInfo, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		dirs = append(dirs, versionDir{name: entry.Name(), lastUsed: dirInfo.ModTime()})
+	}
+
+	return dirs, nil
+}
+
+// expiredVersionDirs returns the names of the dirs to remove so that at most
+// keep of them remain and none was last used more than maxAgeDays ago. The
+// current version is always kept. A zero limit disables it.
+func expiredVersionDirs(dirs []versionDir, current string, keep, maxAgeDays int, now time.Time) []string {
+	sorted := make([]versionDir, 0, len(dirs))
+	for _, dir := range dirs {
+		if dir.name != current {
+			sorted = append(sorted, dir)
+		}
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].lastUsed.After(sorted[j].lastUsed)
+	})
+
+	maxAge := time.Duration(maxAgeDays) * 24 * time.Hour
+	expired := make([]string, 0)
+
+	for i, dir := range sorted {
+		// the current version takes up one of the slots
+		if (keep > 0 && i+1 >= keep) || (maxAgeDays > 0 && now.Sub(dir.lastUsed) > maxAge) {
+			expired = append(expired, dir.name)
+		}
+	}
+
+	return expired
+}
+
+// checkoutVersionDir checks out the PKGBUILD clone in dir into a build dir
+// named after the package version, pkgbase/1.2.3-1/, and returns its path.
+// Build dirs of previous versions are kept until they expire.
+func checkoutVersionDir(ctx context.Context, cmdBuilder exe.GitCmdBuilder,
+	dir string, keep, maxAgeDays int,
+) (string, error) {
+	versionDir, err := checkoutVersionWorktree(ctx, cmdBuilder, dir, dir)
+	if err != nil {
+		return "", err
+	}
+
+	// mark the version as used so age based expiry counts from the last build
+	now := time.Now()
+	if err := os.Chtimes(versionDir, now, now); err != nil {
+		return "", err
+	}
+
+	dirs, err := versionDirs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	git := worktreeGit(ctx, cmdBuilder, dir)
+
+	for _, name := range expiredVersionDirs(dirs, filepath.Base(versionDir), keep, maxAgeDays, now) {
+		if _, err := git(dir, "worktree", "remove", "--force", filepath.Join(dir, name)); err != nil {
+			return "", err
+		}
+	}
+
+	return versionDir, nil
+}
+
+// checkoutVersionDirs replaces the build dir of every AUR base by the build
+// dir of its version. Bases whose version dir can not be created are built
+// in their clone.
+func (preper *Preparer) checkoutVersionDirs(ctx context.Context,
+	pkgBuildDirsByBase map[string]string, aurBases []string,
+) {
+	for _, base := range aurBases {
+		versionDir, err := checkoutVersionDir(ctx, preper.cmdBuilder, pkgBuildDirsByBase[base],
+			preper.cfg.KeepVersions, preper.cfg.KeepVersionsDays)
+		if err != nil {
+			preper.log.Warnln(gotext.Get("unable to use a versioned build dir for %s, building in the clone: %s", base, err))
+			continue
+		}
+
+		preper.log.Debugln("building", base, "in", versionDir)
+		pkgBuildDirsByBase[base] = versionDir
+	}
+}
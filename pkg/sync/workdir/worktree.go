@@ -0,0 +1,157 @@
+package workdir
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	gosrc "github.com/Morganamilo/go-srcinfo"
+	"github.com/leonelquinteros/gotext"
+
+	"github.com/Jguer/yippee/v12/pkg/settings/exe"
+)
+
+// worktreesDir holds the build worktrees inside a PKGBUILD clone.
+const worktreesDir = ".worktrees"
+
+// worktreeName returns the directory name of the worktree for version.
+func worktreeName(version string) string {
+	return strings.ReplaceAll(version, ":", "_")
+}
+
+// checkoutWorktree checks out the PKGBUILD clone in dir, including changes
+// made by the edit menu, into a detached worktree named after the package
+// version and returns its path. Worktrees of other versions are removed.
+func checkoutWorktree(ctx context.Context, cmdBuilder exe.GitCmdBuilder, dir string) (string, error) {
+	worktree, err := checkoutVersionWorktree(ctx, cmdBuilder, dir, filepath.Join(dir, worktreesDir))
+	if err != nil {
+		return "", err
+	}
+
+	entries, err := os.ReadDir(filepath.Join(dir, worktreesDir))
+	if err != nil {
+		return "", err
+	}
+
+	for _, entry := range entries {
+		if entry.Name() == filepath.Base(worktree) {
+			continue
+		}
+
+		if _, err := worktreeGit(ctx, cmdBuilder, dir)(dir, "worktree", "remove", "--force",
+			filepath.Join(dir, worktreesDir, entry.Name())); err != nil {
+			return "", err
+		}
+	}
+
+	return worktree, nil
+}
+
+// worktreeGit returns a helper running git for the worktrees of the clone in dir.
+func worktreeGit(ctx context.Context, cmdBuilder exe.GitCmdBuilder, dir string) func(string, ...string) (string, error) {
+	return func(gitDir string, args ...string) (string, error) {
+		stdout, stderr, err := cmdBuilder.Capture(cmdBuilder.BuildGitCmd(ctx, gitDir, args...))
+		if err != nil {
+			return "", errors.New(gotext.Get("error checking out worktree of %s: %s", dir, stderr))
+		}
+
+		return strings.TrimSpace(stdout), nil
+	}
+}
+
+// checkoutVersionWorktree checks out the PKGBUILD clone in dir, including
+// changes made by the edit menu, into a detached worktree named after the
+// package version inside root and returns its path.
+func checkoutVersionWorktree(ctx context.Context, cmdBuilder exe.GitCmdBuilder, dir, root string) (string, error) {
+	srcinfo, err := gosrc.ParseFile(filepath.Join(dir, ".SRCINFO"))
+	if err != nil {
+		return "", err
+	}
+
+	name := worktreeName(srcinfo.Version())
+	worktree := filepath.Join(root, name)
+
+	pattern := "/" + filepath.Base(root) + "/"
+	if root == dir {
+		pattern = "/" + name + "/"
+	}
+
+	if err := excludeWorktrees(dir, pattern); err != nil {
+		return "", err
+	}
+
+	git := worktreeGit(ctx, cmdBuilder, dir)
+
+	// stash create records uncommitted edits without touching the clone
+	commit, err := git(dir, "stash", "create")
+	if err != nil {
+		return "", err
+	}
+
+	// without edits the commit of the clone is checked out, HEAD would name
+	// the one of an existing worktree
+	if commit == "" {
+		commit, err = git(dir, "rev-parse", "HEAD")
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if _, errStat := os.Stat(filepath.Join(worktree, ".git")); errStat == nil {
+		_, err = git(worktree, "checkout", "--quiet", "--force", "--detach", commit)
+	} else {
+		_, err = git(dir, "worktree", "add", "--quiet", "--force", "--detach", worktree, commit)
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return worktree, nil
+}
+
+// excludeWorktrees keeps the worktrees matched by pattern from showing up as
+// untracked files of the clone.
+func excludeWorktrees(dir, pattern string) error {
+	excludePath := filepath.Join(dir, ".git", "info", "exclude")
+
+	content, err := os.ReadFile(excludePath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	for _, line := range strings.Split(string(content), "\n") {
+		if line == pattern {
+			return nil
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(excludePath), 0o755); err != nil {
+		return err
+	}
+
+	if len(content) > 0 && !strings.HasSuffix(string(content), "\n") {
+		content = append(content, '\n')
+	}
+
+	return os.WriteFile(excludePath, append(content, []byte(pattern+"\n")...), 0o644)
+}
+
+// checkoutWorktrees replaces the build dir of every AUR base by its worktree.
+// Bases whose worktree can not be created are built in their clone.
+func (preper *Preparer) checkoutWorktrees(ctx context.Context,
+	pkgBuildDirsByBase map[string]string, aurBases []string,
+) {
+	for _, base := range aurBases {
+		worktree, err := checkoutWorktree(ctx, preper.cmdBuilder, pkgBuildDirsByBase[base])
+		if err != nil {
+			preper.log.Warnln(gotext.Get("unable to use a worktree for %s, building in the clone: %s", base, err))
+			continue
+		}
+
+		preper.log.Debugln("building", base, "in worktree", worktree)
+		pkgBuildDirsByBase[base] = worktree
+	}
+}
@@ -0,0 +1,166 @@
+//go:build !integration
+// +build !integration
+
+package workdir
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type worktreeGitBuilder struct {
+	cmds      []string
+	stashHash string
+	headHash  string
+}
+
+func (w *worktreeGitBuilder) BuildGitCmd(ctx context.Context, dir string, extraArgs ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "git", extraArgs...)
+	cmd.Dir = dir
+
+	return cmd
+}
+
+func (w *worktreeGitBuilder) Show(cmd *exec.Cmd) error {
+	return nil
+}
+
+func (w *worktreeGitBuilder) Capture(cmd *exec.Cmd) (stdout, stderr string, err error) {
+	args := strings.Join(cmd.Args[1:], " ")
+	w.cmds = append(w.cmds, filepath.Base(cmd.Dir)+": "+args)
+
+	switch args {
+	case "stash create":
+		return w.stashHash + "\n", "", nil
+	case "rev-parse HEAD":
+		return w.headHash + "\n", "", nil
+	}
+
+	return "", "", nil
+}
+
+func TestCheckoutWorktree(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "yippee")
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".git", "info"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".SRCINFO"),
+		[]byte("pkgbase = yippee\n\tpkgver = 12.0.4\n\tpkgrel = 1\n\tepoch = 1\n\tarch = x86_64\n\npkgname = yippee\n"), 0o600))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, worktreesDir, "1_12.0.3-1"), 0o755))
+
+	cmdBuilder := &worktreeGitBuilder{stashHash: "aaaaaaa"}
+
+	worktree, err := checkoutWorktree(context.Background(), cmdBuilder, dir)
+	require.NoError(t, err)
+
+	assert.Equal(t, filepath.Join(dir, worktreesDir, "1_12.0.4-1"), worktree)
+	assert.Equal(t, []string{
+		"yippee: stash create",
+		"yippee: worktree add --quiet --force --detach " + worktree + " aaaaaaa",
+		"yippee: worktree remove --force " + filepath.Join(dir, worktreesDir, "1_12.0.3-1"),
+	}, cmdBuilder.cmds)
+
+	exclude, err := os.ReadFile(filepath.Join(dir, ".git", "info", "exclude"))
+	require.NoError(t, err)
+	assert.Equal(t, "/.worktrees/\n", string(exclude))
+
+	// second run reuses the worktree and the exclude entry, checking out the
+	// commit of the clone rather than the HEAD of the worktree
+	require.NoError(t, os.MkdirAll(filepath.Join(worktree, ".git"), 0o755))
+	require.NoError(t, os.RemoveAll(filepath.Join(dir, worktreesDir, "1_12.0.3-1")))
+	cmdBuilder = &worktreeGitBuilder{headHash: "bbbbbbb"}
+
+	_, err = checkoutWorktree(context.Background(), cmdBuilder, dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"yippee: stash create",
+		"yippee: rev-parse HEAD",
+		"1_12.0.4-1: checkout --quiet --force --detach bbbbbbb",
+	}, cmdBuilder.cmds)
+
+	exclude, err = os.ReadFile(filepath.Join(dir, ".git", "info", "exclude"))
+	require.NoError(t, err)
+	assert.Equal(t, "/.worktrees/\n", string(exclude))
+}
+
+func TestCheckoutVersionDir(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "yippee")
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".git", "info"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".SRCINFO"),
+		[]byte("pkgbase = yippee\n\tpkgver = 12.0.4\n\tpkgrel = 1\n\tarch = x86_64\n\npkgname = yippee\n"), 0o600))
+
+	old := time.Now().Add(-48 * time.Hour)
+	for i, version := range []string{"12.0.1-1", "12.0.2-1", "12.0.3-1"} {
+		versionDir := filepath.Join(dir, version)
+		require.NoError(t, os.MkdirAll(versionDir, 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(versionDir, ".git"), []byte("gitdir: ../.git"), 0o600))
+
+		used := old.Add(time.Duration(i) * time.Hour)
+		require.NoError(t, os.Chtimes(versionDir, used, used))
+	}
+
+	// regular directories of the clone are not build dirs
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "patches"), 0o755))
+	// the fake git does not create the worktree
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "12.0.4-1"), 0o755))
+
+	cmdBuilder := &worktreeGitBuilder{headHash: "bbbbbbb"}
+
+	versionDir, err := checkoutVersionDir(context.Background(), cmdBuilder, dir, 3, 0)
+	require.NoError(t, err)
+
+	assert.Equal(t, filepath.Join(dir, "12.0.4-1"), versionDir)
+	assert.Equal(t, []string{
+		"yippee: stash create",
+		"yippee: rev-parse HEAD",
+		"yippee: worktree add --quiet --force --detach " + versionDir + " bbbbbbb",
+		"yippee: worktree remove --force " + filepath.Join(dir, "12.0.1-1"),
+	}, cmdBuilder.cmds)
+
+	exclude, err := os.ReadFile(filepath.Join(dir, ".git", "info", "exclude"))
+	require.NoError(t, err)
+	assert.Equal(t, "/12.0.4-1/\n", string(exclude))
+}
+
+func TestExpiredVersionDirs(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	dirs := []versionDir{
+		{name: "1.0-1", lastUsed: now.Add(-72 * time.Hour)},
+		{name: "1.2-1", lastUsed: now.Add(-1 * time.Hour)},
+		{name: "1.1-1", lastUsed: now.Add(-48 * time.Hour)},
+		{name: "1.3-1", lastUsed: now.Add(-96 * time.Hour)},
+	}
+
+	testCases := []struct {
+		desc       string
+		keep       int
+		maxAgeDays int
+		want       []string
+	}{
+		{desc: "keep count", keep: 2, want: []string{"1.1-1", "1.0-1"}},
+		{desc: "keep only current", keep: 1, want: []string{"1.2-1", "1.1-1", "1.0-1"}},
+		{desc: "max age", maxAgeDays: 2, want: []string{"1.0-1"}},
+		{desc: "count and age", keep: 3, maxAgeDays: 1, want: []string{"1.1-1", "1.0-1"}},
+		{desc: "no limits", want: []string{}},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.want, expiredVersionDirs(dirs, "1.3-1", tc.keep, tc.maxAgeDays, now))
+		})
+	}
+}
@@ -14,9 +14,34 @@ const (
 	ResetCode = "\x1b[0m"
 )
 
+// Values accepted by --color, matching pacman.
+const (
+	ColorAuto   = "auto"
+	ColorAlways = "always"
+	ColorNever  = "never"
+)
+
 // UseColor determines if package will emit colors.
 var UseColor = true
 
+// ShouldUseColor resolves a --color setting for an output that may or may
+// not be a terminal. An empty setting follows the Color option of
+// pacman.conf, only coloring terminals.
+func ShouldUseColor(when string, pacmanColor, terminal bool) (bool, error) {
+	switch when {
+	case "":
+		return pacmanColor && terminal, nil
+	case ColorAuto:
+		return terminal, nil
+	case ColorAlways:
+		return true, nil
+	case ColorNever:
+		return false, nil
+	}
+
+	return false, ErrInvalidColor{When: when}
+}
+
 func stylize(startCode, in string) string {
 	if UseColor {
 		return startCode + in + ResetCode
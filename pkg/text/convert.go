@@ -2,6 +2,8 @@ package text
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 )
 
 // Human method returns results in human readable format.
@@ -11,7 +13,7 @@ func Human(size int64) string {
 	units := [...]string{"", "Ki", "Mi", "Gi", "Ti", "Pi", "Ei", "Zi", "Yi"}
 	for _, unit := range units {
 		if floatsize < 1024 {
-			return fmt.Sprintf("%.1f %sB", floatsize, unit)
+			return localizeDecimal(fmt.Sprintf("%.1f", floatsize)) + " " + unit + "B"
 		}
 
 		floatsize /= 1024
@@ -19,3 +21,43 @@ func Human(size int64) string {
 
 	return fmt.Sprintf("%d%s", size, "B")
 }
+
+// FormatNumber formats n with the thousands separator of the locale.
+func FormatNumber(n int) string {
+	digits := strconv.Itoa(n)
+	if locale.thousands == "" {
+		return digits
+	}
+
+	sign := ""
+	if n < 0 {
+		sign, digits = "-", digits[1:]
+	}
+
+	var sb strings.Builder
+
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			sb.WriteString(locale.thousands)
+		}
+
+		sb.WriteRune(digit)
+	}
+
+	return sign + sb.String()
+}
+
+// FormatFloat formats f with prec decimals and the decimal separator of the locale.
+func FormatFloat(f float64, prec int) string {
+	return localizeDecimal(strconv.FormatFloat(f, 'f', prec, 64))
+}
+
+// localizeDecimal replaces the decimal point of a formatted number with the
+// one of the locale.
+func localizeDecimal(number string) string {
+	if locale.decimal == "." {
+		return number
+	}
+
+	return strings.Replace(number, ".", locale.decimal, 1)
+}
@@ -7,3 +7,17 @@ type ErrInputOverflow struct{}
 func (e ErrInputOverflow) Error() string {
 	return gotext.Get("input too long")
 }
+
+type ErrNonInteractive struct{}
+
+func (e ErrNonInteractive) Error() string {
+	return gotext.Get("input required but stdin is not a terminal, configure an answer or pass %s", "--confirm")
+}
+
+type ErrInvalidColor struct {
+	When string
+}
+
+func (e ErrInvalidColor) Error() string {
+	return gotext.Get("invalid argument '%s' for %s", e.When, "--color")
+}
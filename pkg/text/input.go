@@ -18,6 +18,10 @@ func (l *Logger) GetInput(defaultValue string, noConfirm bool) (string, error) {
 		return defaultValue, nil
 	}
 
+	if l.NonInteractive {
+		return "", ErrNonInteractive{}
+	}
+
 	reader := bufio.NewReader(l.r)
 
 	buf, overflow, err := reader.ReadLine()
@@ -0,0 +1,165 @@
+package text
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	isolateCode    = "\u2068" // first strong isolate
+	popIsolateCode = "\u2069"
+)
+
+// rtlLanguages are the languages written right to left.
+var rtlLanguages = map[string]bool{
+	"ar": true, "ckb": true, "dv": true, "fa": true, "he": true,
+	"ps": true, "sd": true, "ug": true, "ur": true, "yi": true,
+}
+
+// localeFormat holds the date and number conventions of a locale.
+type localeFormat struct {
+	date      string // time.Format layout of dates
+	time      string // time.Format layout of the time of day
+	decimal   string
+	thousands string
+}
+
+// defaultFormat is used for unknown locales and keeps ISO 8601 dates.
+var defaultFormat = localeFormat{
+	date: "2006-01-02", time: "03:04:05 PM", decimal: ".",
+}
+
+// localeFormats maps languages, optionally with a territory, to their format.
+var localeFormats = map[string]localeFormat{
+	"ca":    {date: "02/01/2006", time: "15:04:05", decimal: ",", thousands: "."},
+	"cs":    {date: "02.01.2006", time: "15:04:05", decimal: ",", thousands: " "},
+	"de":    {date: "02.01.2006", time: "15:04:05", decimal: ",", thousands: "."},
+	"en":    {date: "2006-01-02", time: "03:04:05 PM", decimal: ".", thousands: ","},
+	"en_gb": {date: "02/01/2006", time: "15:04:05", decimal: ".", thousands: ","},
+	"en_us": {date: "01/02/2006", time: "03:04:05 PM", decimal: ".", thousands: ","},
+	"es":    {date: "02/01/2006", time: "15:04:05", decimal: ",", thousands: "."},
+	"eu":    {date: "2006/01/02", time: "15:04:05", decimal: ",", thousands: "."},
+	"fr":    {date: "02/01/2006", time: "15:04:05", decimal: ",", thousands: " "},
+	"he":    {date: "02.01.2006", time: "15:04:05", decimal: ".", thousands: ","},
+	"hu":    {date: "2006. 01. 02.", time: "15:04:05", decimal: ",", thousands: " "},
+	"id":    {date: "02/01/2006", time: "15.04.05", decimal: ",", thousands: "."},
+	"it":    {date: "02/01/2006", time: "15:04:05", decimal: ",", thousands: "."},
+	"ja":    {date: "2006/01/02", time: "15:04:05", decimal: ".", thousands: ","},
+	"ko":    {date: "2006. 01. 02.", time: "15:04:05", decimal: ".", thousands: ","},
+	"nl":    {date: "02-01-2006", time: "15:04:05", decimal: ",", thousands: "."},
+	"pl":    {date: "02.01.2006", time: "15:04:05", decimal: ",", thousands: " "},
+	"pt":    {date: "02/01/2006", time: "15:04:05", decimal: ",", thousands: "."},
+	"ru":    {date: "02.01.2006", time: "15:04:05", decimal: ",", thousands: " "},
+	"sv":    {date: "2006-01-02", time: "15:04:05", decimal: ",", thousands: " "},
+	"tr":    {date: "02.01.2006", time: "15:04:05", decimal: ",", thousands: "."},
+	"uk":    {date: "02.01.2006", time: "15:04:05", decimal: ",", thousands: " "},
+	"zh":    {date: "2006/01/02", time: "15:04:05", decimal: ".", thousands: ","},
+}
+
+// candyWidth is the amount of cells of the candy progress bar.
+const candyWidth = 12
+
+var (
+	// UseCandy draws progress like pacman with ILoveCandy set.
+	UseCandy = false
+
+	// rtl is set when the messages are in a right to left language.
+	rtl = false
+
+	locale = defaultFormat
+)
+
+// IsRTL reports whether lang, a locale such as he_IL.UTF-8 or a LANGUAGE
+// list such as he:en, is written right to left.
+func IsRTL(lang string) bool {
+	lang, _, _ = strings.Cut(trimLocale(lang), "_")
+
+	return rtlLanguages[lang]
+}
+
+// trimLocale returns the lowercase language and territory of lang.
+func trimLocale(lang string) string {
+	lang, _, _ = strings.Cut(lang, ":")
+	lang, _, _ = strings.Cut(lang, ".")
+	lang, _, _ = strings.Cut(lang, "@")
+
+	return strings.ToLower(lang)
+}
+
+// lookupFormat returns the format of lang, falling back to its language
+// without the territory and then to the default format.
+func lookupFormat(lang string) localeFormat {
+	lang = trimLocale(lang)
+	if format, ok := localeFormats[lang]; ok {
+		return format
+	}
+
+	lang, _, _ = strings.Cut(lang, "_")
+	if format, ok := localeFormats[lang]; ok {
+		return format
+	}
+
+	return defaultFormat
+}
+
+// SetLanguage sets the language used for the messages, as configured for
+// gotext, along with the matching date and number formats.
+func SetLanguage(lang string) {
+	rtl = IsRTL(lang)
+	locale = lookupFormat(lang)
+}
+
+// Isolate keeps s, usually a package name or version, from being reordered
+// with the surrounding text when the language is written right to left.
+func Isolate(s string) string {
+	if !rtl || s == "" {
+		return s
+	}
+
+	return isolateCode + s + popIsolateCode
+}
+
+// Progress returns a (current/total) counter with current padded to the
+// width of total, so consecutive progress lines align. With UseCandy the
+// counter is followed by a pacman eating its way through the pellets.
+func Progress(current, total int) string {
+	width := len(strconv.Itoa(total))
+	counter := fmt.Sprintf("(%*d/%d)", width, current, total)
+
+	if UseCandy {
+		counter += " " + candyBar(current, total, candyWidth)
+	}
+
+	return Isolate(counter)
+}
+
+// candyBar draws the ILoveCandy progress bar of pacman.
+func candyBar(current, total, width int) string {
+	done := width
+	if total > 0 && current < total {
+		done = current * width / total
+	}
+
+	var sb strings.Builder
+
+	sb.WriteString("[")
+	sb.WriteString(strings.Repeat("-", done))
+
+	for i := done; i < width; i++ {
+		switch {
+		case i == done && current%2 == 0:
+			sb.WriteString("C")
+		case i == done:
+			sb.WriteString("c")
+		case i%3 == 0:
+			sb.WriteString("o")
+		default:
+			sb.WriteString(" ")
+		}
+	}
+
+	sb.WriteString("]")
+
+	return sb.String()
+}
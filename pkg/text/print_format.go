@@ -0,0 +1,31 @@
+package text
+
+import "strings"
+
+// PrintFields are the values --print-format placeholders expand to, by the
+// letter following the %.
+type PrintFields map[rune]string
+
+// PrintFormat expands the pacman style --print-format placeholders of
+// format with fields, e.g. "%n %v" to the name and the version. Placeholders
+// with no field are left as they are.
+func PrintFormat(format string, fields PrintFields) string {
+	var sb strings.Builder
+
+	runes := []rune(format)
+
+	for i := 0; i < len(runes); i++ {
+		if runes[i] == '%' && i+1 < len(runes) {
+			if value, ok := fields[runes[i+1]]; ok {
+				sb.WriteString(value)
+				i++
+
+				continue
+			}
+		}
+
+		sb.WriteRune(runes[i])
+	}
+
+	return sb.String()
+}
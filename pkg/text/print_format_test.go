@@ -0,0 +1,37 @@
+//go:build !integration
+// +build !integration
+
+package text
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrintFormat(t *testing.T) {
+	t.Parallel()
+
+	fields := PrintFields{'n': "yippee", 'v': "12.0.0-1", 'r': "aur"}
+
+	testCases := []struct {
+		desc   string
+		format string
+		want   string
+	}{
+		{desc: "placeholders", format: "%r/%n %v", want: "aur/yippee 12.0.0-1"},
+		{desc: "unknown placeholder", format: "%n %s", want: "yippee %s"},
+		{desc: "trailing percent", format: "%n 100%", want: "yippee 100%"},
+		{desc: "repeated placeholder", format: "%n-%n", want: "yippee-yippee"},
+		{desc: "no placeholders", format: "packages", want: "packages"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.want, PrintFormat(tc.format, fields))
+		})
+	}
+}
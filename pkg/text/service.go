@@ -3,6 +3,9 @@ package text
 import (
 	"fmt"
 	"io"
+	"regexp"
+	"strings"
+	"sync"
 )
 
 const (
@@ -11,26 +14,101 @@ const (
 	opSymbol   = "::"
 )
 
+// Level sets how much non-essential output a Logger prints.
+type Level int
+
+const (
+	// LevelQuiet drops progress messages. Results, prompts, warnings and
+	// errors are still printed.
+	LevelQuiet Level = iota - 1
+	// LevelNormal is the default level.
+	LevelNormal
+	// LevelVerbose additionally prints the commands being run.
+	LevelVerbose
+	// LevelTrace additionally prints captured commands and AUR requests
+	// with their timings.
+	LevelTrace
+)
+
 type Logger struct {
-	name   string
-	Debug  bool
-	stdout io.Writer
-	stderr io.Writer
-	r      io.Reader
+	name  string
+	Debug bool
+	Level Level
+	// NonInteractive loggers fail prompts that have no default answer
+	// instead of waiting for input.
+	NonInteractive bool
+	stdout         io.Writer
+	stderr         io.Writer
+	r              io.Reader
+	warnings       *warningLog
+	// diag receives the leveled and debug messages when set.
+	diag     io.Writer
+	children map[string]ChildConfig
+}
+
+// ChildConfig overrides the level of the children of a Logger with a given
+// name, and of their own children.
+type ChildConfig struct {
+	Level Level
+	Debug bool
+	// Output receives the leveled and debug messages of the child instead of
+	// stderr and stdout when set.
+	Output io.Writer
+}
+
+// warningLog collects the warnings printed by a logger and its children.
+type warningLog struct {
+	mux      sync.Mutex
+	messages []string
+}
+
+// formatting is the color codes and direction isolates left out of the
+// collected warnings.
+var formatting = regexp.MustCompile("\x1b\\[[0-9;]*m|" + isolateCode + "|" + popIsolateCode)
+
+// StripFormatting removes color codes and direction isolates from s, for
+// output read by yippee rather than by the user.
+func StripFormatting(s string) string {
+	return formatting.ReplaceAllString(s, "")
 }
 
 func NewLogger(stdout, stderr io.Writer, r io.Reader, debug bool, name string) *Logger {
 	return &Logger{
-		Debug:  debug,
-		name:   name,
-		r:      r,
-		stderr: stderr,
-		stdout: stdout,
+		Debug:    debug,
+		name:     name,
+		r:        r,
+		stderr:   stderr,
+		stdout:   stdout,
+		warnings: &warningLog{},
+		children: map[string]ChildConfig{},
 	}
 }
 
+// ConfigureChild sets the level and the output of the children named name
+// created from now on by the logger or its descendants, to debug one part of
+// yippee without the output of the others.
+func (l *Logger) ConfigureChild(name string, config ChildConfig) {
+	l.children[name] = config
+}
+
 func (l *Logger) Child(name string) *Logger {
-	return NewLogger(l.stdout, l.stderr, l.r, l.Debug, name)
+	child := NewLogger(l.stdout, l.stderr, l.r, l.Debug, name)
+	child.Level = l.Level
+	child.NonInteractive = l.NonInteractive
+	child.warnings = l.warnings
+	child.diag = l.diag
+	child.children = l.children
+
+	if config, ok := l.children[name]; ok {
+		child.Level = config.Level
+		child.Debug = config.Debug
+
+		if config.Output != nil {
+			child.diag = config.Output
+		}
+	}
+
+	return child
 }
 
 func (l *Logger) Debugln(a ...any) {
@@ -38,16 +116,55 @@ func (l *Logger) Debugln(a ...any) {
 		return
 	}
 
-	l.Println(append([]interface{}{
+	out := l.stdout
+	if l.diag != nil {
+		out = l.diag
+	}
+
+	fmt.Fprintln(out, append([]interface{}{
 		Bold(yellow(fmt.Sprintf("[DEBUG:%s]", l.name))),
 	}, a...)...)
 }
 
+// Verboseln prints to stderr from LevelVerbose on, or as a debug message
+// when debugging.
+func (l *Logger) Verboseln(a ...any) {
+	l.leveledln(LevelVerbose, a...)
+}
+
+// Traceln prints to stderr from LevelTrace on, or as a debug message when
+// debugging.
+func (l *Logger) Traceln(a ...any) {
+	l.leveledln(LevelTrace, a...)
+}
+
+func (l *Logger) leveledln(level Level, a ...any) {
+	switch {
+	case l.Debug:
+		l.Debugln(a...)
+	case l.Level >= level:
+		out := l.stderr
+		if l.diag != nil {
+			out = l.diag
+		}
+
+		fmt.Fprintln(out, append([]interface{}{Bold(Blue(smallArrow))}, a...)...)
+	}
+}
+
 func (l *Logger) OperationInfoln(a ...any) {
+	if l.Level <= LevelQuiet {
+		return
+	}
+
 	l.Println(l.SprintOperationInfo(a...))
 }
 
 func (l *Logger) OperationInfo(a ...any) {
+	if l.Level <= LevelQuiet {
+		return
+	}
+
 	l.Print(l.SprintOperationInfo(a...))
 }
 
@@ -64,13 +181,31 @@ func (l *Logger) Infoln(a ...any) {
 }
 
 func (l *Logger) Warn(a ...any) {
+	l.recordWarning(a...)
 	l.Print(l.SprintWarn(a...))
 }
 
 func (l *Logger) Warnln(a ...any) {
+	l.recordWarning(a...)
 	l.Println(l.SprintWarn(a...))
 }
 
+func (l *Logger) recordWarning(a ...any) {
+	l.warnings.mux.Lock()
+	defer l.warnings.mux.Unlock()
+
+	l.warnings.messages = append(l.warnings.messages, strings.TrimSpace(StripFormatting(fmt.Sprint(a...))))
+}
+
+// Warnings returns the warnings printed so far by the logger, its parent
+// and their children, without formatting.
+func (l *Logger) Warnings() []string {
+	l.warnings.mux.Lock()
+	defer l.warnings.mux.Unlock()
+
+	return append([]string{}, l.warnings.messages...)
+}
+
 func (l *Logger) SprintWarn(a ...any) string {
 	return fmt.Sprint(append([]interface{}{Bold(yellow(smallArrow + " "))}, a...)...)
 }
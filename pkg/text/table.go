@@ -0,0 +1,77 @@
+package text
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// columnGap separates the columns of a Table.
+const columnGap = "  "
+
+// Table lays rows out in columns padded to their widest cell, like the
+// package lists of pacman with VerbosePkgLists set.
+type Table struct {
+	header []string
+	rows   [][]string
+}
+
+// NewTable returns a table with a column for every title of header.
+func NewTable(header ...string) *Table {
+	return &Table{header: header}
+}
+
+// AddRow adds a row of cells, one per column.
+func (t *Table) AddRow(cells ...string) {
+	t.rows = append(t.rows, cells)
+}
+
+// String renders the table under a bold header. Cells are padded to the
+// width they are printed with, so colored cells line up with plain ones.
+func (t *Table) String() string {
+	widths := make([]int, len(t.header))
+
+	for _, row := range append([][]string{t.header}, t.rows...) {
+		for i, cell := range row {
+			if i < len(widths) {
+				widths[i] = max(widths[i], cellWidth(cell))
+			}
+		}
+	}
+
+	var sb strings.Builder
+
+	header := make([]string, len(t.header))
+	for i, title := range t.header {
+		header[i] = Bold(title)
+	}
+
+	writeRow(&sb, header, widths)
+
+	for _, row := range t.rows {
+		writeRow(&sb, row, widths)
+	}
+
+	return sb.String()
+}
+
+func writeRow(sb *strings.Builder, row []string, widths []int) {
+	for i, cell := range row {
+		if i > 0 {
+			sb.WriteString(columnGap)
+		}
+
+		sb.WriteString(cell)
+
+		// the last column is not padded, to leave no trailing spaces
+		if i < len(row)-1 && i < len(widths) {
+			sb.WriteString(strings.Repeat(" ", widths[i]-cellWidth(cell)))
+		}
+	}
+
+	sb.WriteString("\n")
+}
+
+// cellWidth returns the number of cells s takes on a terminal.
+func cellWidth(s string) int {
+	return utf8.RuneCountInString(StripFormatting(s))
+}
@@ -4,11 +4,13 @@
 package text
 
 import (
+	"fmt"
 	"io"
 	"os"
 	"path"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/leonelquinteros/gotext"
 	"github.com/stretchr/testify/assert"
@@ -82,6 +84,25 @@ func TestContinueTask(t *testing.T) {
 	}
 }
 
+func TestGetInputNonInteractive(t *testing.T) {
+	t.Parallel()
+
+	logger := NewLogger(io.Discard, io.Discard, strings.NewReader("1\n"), false, "test")
+	logger.NonInteractive = true
+	logger = logger.Child("child")
+
+	got, err := logger.GetInput("2", false)
+	require.NoError(t, err)
+	assert.Equal(t, "2", got)
+
+	got, err = logger.GetInput("", true)
+	require.NoError(t, err)
+	assert.Equal(t, "", got)
+
+	_, err = logger.GetInput("", false)
+	assert.ErrorIs(t, err, ErrNonInteractive{})
+}
+
 func TestContinueTaskRU(t *testing.T) {
 	strCustom := `
 msgid "yes"
@@ -178,3 +199,268 @@ msgstr "ja"
 	}
 	gotext.SetLanguage("")
 }
+
+func TestShouldUseColor(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		when        string
+		pacmanColor bool
+		terminal    bool
+		want        bool
+	}{
+		{when: "", pacmanColor: true, terminal: true, want: true},
+		{when: "", pacmanColor: true, terminal: false, want: false},
+		{when: "", pacmanColor: false, terminal: true, want: false},
+		{when: ColorAuto, pacmanColor: false, terminal: true, want: true},
+		{when: ColorAuto, pacmanColor: true, terminal: false, want: false},
+		{when: ColorAlways, pacmanColor: false, terminal: false, want: true},
+		{when: ColorNever, pacmanColor: true, terminal: true, want: false},
+	}
+
+	for _, tc := range testCases {
+		got, err := ShouldUseColor(tc.when, tc.pacmanColor, tc.terminal)
+		require.NoError(t, err)
+		assert.Equal(t, tc.want, got, "%q pacman=%v terminal=%v", tc.when, tc.pacmanColor, tc.terminal)
+	}
+
+	_, err := ShouldUseColor("sometimes", true, true)
+	assert.ErrorIs(t, err, ErrInvalidColor{When: "sometimes"})
+}
+
+func TestLoggerLevels(t *testing.T) {
+	t.Parallel()
+
+	progress := fmt.Sprintln(Bold(Cyan(opSymbol+" ")) + boldCode + "progress" + ResetCode)
+	command := fmt.Sprintln(Bold(Blue(smallArrow)), "command")
+	request := fmt.Sprintln(Bold(Blue(smallArrow)), "request")
+
+	testCases := []struct {
+		level      Level
+		wantStdout string
+		wantStderr string
+	}{
+		{level: LevelQuiet, wantStdout: "result\n"},
+		{level: LevelNormal, wantStdout: progress + "result\n"},
+		{level: LevelVerbose, wantStdout: progress + "result\n", wantStderr: command},
+		{level: LevelTrace, wantStdout: progress + "result\n", wantStderr: command + request},
+	}
+
+	for _, tc := range testCases {
+		var stdout, stderr strings.Builder
+
+		logger := NewLogger(&stdout, &stderr, strings.NewReader(""), false, "test")
+		logger.Level = tc.level
+		logger = logger.Child("child")
+
+		logger.OperationInfoln("progress")
+		logger.Println("result")
+		logger.Verboseln("command")
+		logger.Traceln("request")
+
+		assert.Equal(t, tc.wantStdout, stdout.String(), "level %d", tc.level)
+		assert.Equal(t, tc.wantStderr, stderr.String(), "level %d", tc.level)
+	}
+}
+
+func TestLoggerConfigureChild(t *testing.T) {
+	t.Parallel()
+
+	var stdout, stderr, grapherLog strings.Builder
+
+	logger := NewLogger(&stdout, &stderr, strings.NewReader(""), false, "test")
+	logger.ConfigureChild("db", ChildConfig{Level: LevelVerbose})
+	logger.ConfigureChild("grapher", ChildConfig{Level: LevelTrace, Debug: true, Output: &grapherLog})
+
+	db := logger.Child("db")
+	db.Verboseln("db command")
+	db.Debugln("db debug")
+
+	grapher := logger.Child("grapher")
+	grapher.Debugln("grapher debug")
+	grapher.Println("grapher result")
+
+	// descendants inherit the configuration of their parent
+	grapher.Child("srcinfo").Verboseln("srcinfo command")
+
+	logger.Child("upgrade").Verboseln("upgrade command")
+	logger.Verboseln("root command")
+
+	assert.Equal(t, "grapher result\n", stdout.String())
+	assert.Equal(t, fmt.Sprintln(Bold(Blue(smallArrow)), "db command"), stderr.String())
+	assert.Equal(t, fmt.Sprintln(Bold(yellow("[DEBUG:grapher]")), "grapher debug")+
+		fmt.Sprintln(Bold(yellow("[DEBUG:srcinfo]")), "srcinfo command"), grapherLog.String())
+}
+
+// FuzzSplitDBFromName checks that splitting a target loses nothing and that
+// only the first slash separates the database.
+func FuzzSplitDBFromName(f *testing.F) {
+	for _, seed := range []string{"yippee", "aur/yippee", "core/", "/yippee", "a/b/c", ""} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, target string) {
+		db, name := SplitDBFromName(target)
+
+		assert.NotContains(t, db, "/")
+
+		if strings.Contains(target, "/") {
+			assert.Equal(t, target, db+"/"+name)
+		} else {
+			assert.Empty(t, db)
+			assert.Equal(t, target, name)
+		}
+	})
+}
+
+func TestIsRTL(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		lang string
+		want bool
+	}{
+		{lang: "he_IL.UTF-8", want: true},
+		{lang: "ar", want: true},
+		{lang: "fa_IR@persian", want: true},
+		{lang: "he:en_US", want: true},
+		{lang: "en_US.UTF-8", want: false},
+		{lang: "pt_BR", want: false},
+		{lang: "", want: false},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.lang, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.want, IsRTL(tc.lang))
+		})
+	}
+}
+
+func TestProgress(t *testing.T) {
+	assert.Equal(t, "( 1/10)", Progress(1, 10))
+	assert.Equal(t, "(10/10)", Progress(10, 10))
+	assert.Equal(t, "(3/3)", Progress(3, 3))
+
+	SetLanguage("he_IL.UTF-8")
+	t.Cleanup(func() { SetLanguage("") })
+
+	assert.Equal(t, "⁨( 1/10)⁩", Progress(1, 10))
+	assert.Equal(t, "⁨yippee⁩", Isolate("yippee"))
+	assert.Equal(t, "", Isolate(""))
+}
+
+func TestLocaleFormats(t *testing.T) {
+	date := time.Date(2024, time.March, 7, 18, 5, 9, 0, time.UTC)
+
+	t.Cleanup(func() {
+		SetLanguage("")
+		SetDateFormat("")
+	})
+
+	testCases := []struct {
+		lang       string
+		dateFormat string
+		date       string
+		dateTime   string
+		number     string
+		size       string
+	}{
+		{
+			lang: "", date: "2024-03-07", dateTime: "Thu 07 Mar 2024 06:05:09 PM UTC",
+			number: "1234567", size: "1.5 KiB",
+		},
+		{
+			lang: "en_US.UTF-8", date: "03/07/2024", dateTime: "03/07/2024 06:05:09 PM UTC",
+			number: "1,234,567", size: "1.5 KiB",
+		},
+		{
+			lang: "de_DE.UTF-8", date: "07.03.2024", dateTime: "07.03.2024 18:05:09 UTC",
+			number: "1.234.567", size: "1,5 KiB",
+		},
+		{
+			lang: "de_DE.UTF-8", dateFormat: DateFormatISO, date: "2024-03-07", dateTime: "2024-03-07T18:05:09Z",
+			number: "1.234.567", size: "1,5 KiB",
+		},
+		{
+			lang: "fr_FR", dateFormat: "02 Jan 2006", date: "07 Mar 2024", dateTime: "07 Mar 2024",
+			number: "1 234 567", size: "1,5 KiB",
+		},
+	}
+
+	for _, tc := range testCases {
+		SetLanguage(tc.lang)
+		SetDateFormat(tc.dateFormat)
+
+		assert.Equal(t, tc.date, FormatDate(date), tc.lang)
+		assert.Equal(t, tc.dateTime, FormatDateTime(date), tc.lang)
+		assert.Equal(t, tc.number, FormatNumber(1234567), tc.lang)
+		assert.Equal(t, "-"+tc.number, FormatNumber(-1234567), tc.lang)
+		assert.Equal(t, tc.size, Human(1536), tc.lang)
+	}
+}
+
+func TestCandyBar(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "[C  o  o  o  ]", candyBar(0, 4, 12))
+	assert.Equal(t, "[---c  o  o  ]", candyBar(1, 4, 12))
+	assert.Equal(t, "[------C  o  ]", candyBar(2, 4, 12))
+	assert.Equal(t, "[------------]", candyBar(4, 4, 12))
+}
+
+func TestLoggerWarnings(t *testing.T) {
+	t.Parallel()
+
+	var out strings.Builder
+
+	logger := NewLogger(&out, io.Discard, strings.NewReader(""), false, "test")
+	child := logger.Child("child")
+
+	logger.Warnln("first", " ", Bold(Cyan("foo")))
+	child.Warn("second\n")
+	logger.Errorln("not a warning")
+
+	assert.Equal(t, []string{"first foo", "second"}, logger.Warnings())
+	assert.Equal(t, logger.Warnings(), child.Warnings())
+	assert.Contains(t, out.String(), "first")
+
+	assert.Empty(t, NewLogger(io.Discard, io.Discard, strings.NewReader(""), false, "other").Warnings())
+}
+
+func TestFormatAge(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		age  time.Duration
+		want string
+	}{
+		{age: 10 * time.Minute, want: "less than an hour ago"},
+		{age: 90 * time.Minute, want: "1 hour ago"},
+		{age: 47 * time.Hour, want: "47 hours ago"},
+		{age: 50 * time.Hour, want: "2 days ago"},
+		{age: 30 * 24 * time.Hour, want: "30 days ago"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.want, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.want, FormatAge(tc.age))
+		})
+	}
+}
+
+func TestTable(t *testing.T) {
+	t.Parallel()
+
+	table := NewTable("Name", "Old Version", "New Version")
+	table.AddRow(Cyan("yippee"), Red("12.1.0-1"), Green("12.2.0-1"))
+	table.AddRow("pacman-contrib", "", "1.10.6-1")
+
+	assert.Equal(t, `Name            Old Version  New Version
+yippee          12.1.0-1     12.2.0-1
+pacman-contrib               1.10.6-1
+`, StripFormatting(table.String()))
+}
@@ -1,15 +1,72 @@
 package text
 
-import "time"
+import (
+	"time"
 
-// Formats a unix timestamp to ISO 8601 date (yyyy-mm-dd).
+	"github.com/leonelquinteros/gotext"
+)
+
+// DateFormatISO selects ISO 8601 dates regardless of the locale.
+const DateFormatISO = "iso"
+
+// dateFormat overrides the date layout of the locale when set.
+var dateFormat = ""
+
+// SetDateFormat overrides the date format of the locale with
+// DateFormatISO or a time.Format layout. An empty format follows the locale.
+func SetDateFormat(format string) {
+	dateFormat = format
+}
+
+// FormatDate formats t as a date.
+func FormatDate(t time.Time) string {
+	switch dateFormat {
+	case "":
+		return t.Format(locale.date)
+	case DateFormatISO:
+		return t.Format("2006-01-02")
+	default:
+		return t.Format(dateFormat)
+	}
+}
+
+// FormatDateTime formats t as a date and time of day.
+func FormatDateTime(t time.Time) string {
+	switch dateFormat {
+	case "":
+		if locale == defaultFormat {
+			return t.Format("Mon 02 Jan 2006 03:04:05 PM MST")
+		}
+
+		return t.Format(locale.date + " " + locale.time + " MST")
+	case DateFormatISO:
+		return t.Format(time.RFC3339)
+	default:
+		return t.Format(dateFormat)
+	}
+}
+
+// Formats a unix timestamp to a date, see FormatDate.
 func FormatTime(i int) string {
-	t := time.Unix(int64(i), 0)
-	return t.Format("2006-01-02")
+	return FormatDate(time.Unix(int64(i), 0))
 }
 
-// Formats a unix timestamp to ISO 8601 date (Mon 02 Jan 2006 03:04:05 PM MST).
+// Formats a unix timestamp to a date and time, see FormatDateTime.
 func FormatTimeQuery(i int) string {
-	t := time.Unix(int64(i), 0)
-	return t.Format("Mon 02 Jan 2006 03:04:05 PM MST")
+	return FormatDateTime(time.Unix(int64(i), 0))
+}
+
+// FormatAge formats how long ago something happened, in hours up to two
+// days and in days after.
+func FormatAge(age time.Duration) string {
+	hours := int(age / time.Hour)
+
+	switch {
+	case hours < 1:
+		return gotext.Get("less than an hour ago")
+	case hours < 48:
+		return gotext.GetN("%d hour ago", "%d hours ago", hours, hours)
+	default:
+		return gotext.GetN("%d day ago", "%d days ago", hours/24, hours/24)
+	}
 }
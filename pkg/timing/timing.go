@@ -0,0 +1,109 @@
+package timing
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/leonelquinteros/gotext"
+
+	"github.com/Jguer/yippee/v12/pkg/text"
+)
+
+// Phase is the accumulated time spent in one step of a run.
+type Phase struct {
+	Name     string
+	Duration time.Duration
+}
+
+// Tracer records how long each phase of a run takes.
+// A nil Tracer is valid and records nothing, so callers don't have to check
+// whether timing is enabled.
+type Tracer struct {
+	phases []Phase
+	index  map[string]int
+	now    func() time.Time
+	mux    sync.Mutex
+}
+
+func NewTracer() *Tracer {
+	return &Tracer{
+		phases: []Phase{},
+		index:  map[string]int{},
+		now:    time.Now,
+	}
+}
+
+// Start begins timing the phase name and returns the function ending it;
+// only the first call of that function counts. Time spent in a phase started
+// several times is summed up.
+func (t *Tracer) Start(name string) func() {
+	if t == nil {
+		return func() {}
+	}
+
+	start := t.now()
+
+	var once sync.Once
+
+	return func() {
+		once.Do(func() { t.add(name, t.now().Sub(start)) })
+	}
+}
+
+func (t *Tracer) add(name string, duration time.Duration) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	if i, ok := t.index[name]; ok {
+		t.phases[i].Duration += duration
+		return
+	}
+
+	t.index[name] = len(t.phases)
+	t.phases = append(t.phases, Phase{Name: name, Duration: duration})
+}
+
+// Phases returns the recorded phases in the order they were first started.
+func (t *Tracer) Phases() []Phase {
+	if t == nil {
+		return nil
+	}
+
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	return append([]Phase{}, t.phases...)
+}
+
+// Report prints the recorded phases and their total.
+func (t *Tracer) Report(logger *text.Logger) {
+	phases := t.Phases()
+	if len(phases) == 0 {
+		return
+	}
+
+	width := len(gotext.Get("Total"))
+	for _, phase := range phases {
+		width = max(width, len(phase.Name))
+	}
+
+	var total time.Duration
+
+	logger.OperationInfoln(gotext.Get("Timings:"))
+
+	for _, phase := range phases {
+		total += phase.Duration
+		logger.Println(fmt.Sprintf("    %-*s  %s", width, phase.Name, round(phase.Duration)))
+	}
+
+	logger.Println(fmt.Sprintf("    %s  %s", text.Bold(fmt.Sprintf("%-*s", width, gotext.Get("Total"))), round(total)))
+}
+
+func round(duration time.Duration) time.Duration {
+	if duration < time.Second {
+		return duration.Round(time.Millisecond)
+	}
+
+	return duration.Round(100 * time.Millisecond)
+}
@@ -0,0 +1,72 @@
+//go:build !integration
+// +build !integration
+
+package timing
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Jguer/yippee/v12/pkg/text"
+)
+
+func TestTracer(t *testing.T) {
+	t.Parallel()
+
+	clock := time.Unix(0, 0)
+	tracer := NewTracer()
+	tracer.now = func() time.Time { return clock }
+
+	done := tracer.Start("resolution")
+	clock = clock.Add(2 * time.Second)
+	done()
+
+	done = tracer.Start("build foo")
+	clock = clock.Add(90 * time.Second)
+	done()
+
+	done = tracer.Start("resolution")
+	clock = clock.Add(500 * time.Millisecond)
+	done()
+	clock = clock.Add(time.Second)
+	done()
+
+	assert.Equal(t, []Phase{
+		{Name: "resolution", Duration: 2500 * time.Millisecond},
+		{Name: "build foo", Duration: 90 * time.Second},
+	}, tracer.Phases())
+}
+
+func TestNilTracer(t *testing.T) {
+	t.Parallel()
+
+	var tracer *Tracer
+
+	var stdout strings.Builder
+
+	tracer.Start("resolution")()
+	tracer.Report(text.NewLogger(&stdout, io.Discard, strings.NewReader(""), false, "test"))
+
+	assert.Empty(t, tracer.Phases())
+	assert.Empty(t, stdout.String())
+}
+
+func TestReport(t *testing.T) {
+	t.Parallel()
+
+	tracer := NewTracer()
+	tracer.add("resolution", 345678*time.Microsecond)
+	tracer.add("build foo", 61*time.Second+220*time.Millisecond)
+
+	var stdout strings.Builder
+
+	tracer.Report(text.NewLogger(&stdout, io.Discard, strings.NewReader(""), false, "test"))
+
+	assert.Contains(t, stdout.String(), "    resolution  346ms\n")
+	assert.Contains(t, stdout.String(), "    build foo   1m1.2s\n")
+	assert.Contains(t, stdout.String(), "  1m1.6s\n")
+}
@@ -0,0 +1,214 @@
+package upgrade
+
+import (
+	"context"
+	"sort"
+
+	"github.com/Jguer/aur"
+	mapset "github.com/deckarep/golang-set/v2"
+
+	"github.com/Jguer/yippee/v12/pkg/db"
+	"github.com/Jguer/yippee/v12/pkg/multierror"
+	"github.com/Jguer/yippee/v12/pkg/vcs"
+)
+
+// Options selects the upgrades Check looks for.
+type Options struct {
+	// Repo checks the sync databases.
+	Repo bool
+	// AUR checks the AUR for the installed foreign packages.
+	AUR bool
+	// Devel checks development packages for new upstream commits. With
+	// DevelStrict their AUR version is ignored in favor of the commits.
+	Devel       bool
+	DevelStrict bool
+	// TimeUpdate counts an AUR package pushed after the installed one was
+	// built as an upgrade.
+	TimeUpdate      bool
+	EnableDowngrade bool
+	// IgnoreRepos are repositories whose upgrades are skipped, IgnoreGroups
+	// groups that AUR packages are skipped for joining.
+	IgnoreRepos  []string
+	IgnoreGroups []string
+}
+
+// SkipReason tells why an upgrade is left out of a Result.
+type SkipReason int
+
+const (
+	// SkipIgnored is an upgrade of a package in IgnorePkg or IgnoreGroup.
+	SkipIgnored SkipReason = iota
+	// SkipIgnoredGroup is an AUR upgrade into a group in Options.IgnoreGroups.
+	SkipIgnoredGroup
+	// SkipIgnoredRepo is an upgrade from a repository in Options.IgnoreRepos.
+	SkipIgnoredRepo
+	// SkipReplaced is an AUR upgrade of a package that a repository package
+	// now replaces or provides.
+	SkipReplaced
+	// SkipNoAURInfo is a development upgrade of a package not on the AUR.
+	SkipNoAURInfo
+)
+
+// Skipped is an upgrade left out of a Result.
+type Skipped struct {
+	Upgrade
+	Reason SkipReason
+	// Replacer is the repository package, as repo/name, of an upgrade
+	// skipped with SkipReplaced.
+	Replacer string
+}
+
+// Result is the outcome of Check. Every list is sorted by name.
+type Result struct {
+	Repo  []Upgrade
+	AUR   []Upgrade
+	Devel []Upgrade
+	// Skipped are the upgrades found but left out, and why.
+	Skipped []Skipped
+	// AURInfo is the AUR info of the installed foreign packages found on the
+	// AUR, nil when the AUR was not checked or could not be reached.
+	AURInfo map[string]*aur.Pkg
+
+	syncUpgrades map[string]db.SyncUpgrade
+}
+
+// Check computes the upgrades available for the installed packages from the
+// sync databases, the AUR and the upstream repositories of development
+// packages. It prints and asks nothing, rendering is left to the caller.
+//
+// On error the result still holds the upgrades of the checks that succeeded.
+// An unreachable AUR is reported with aur.ErrServiceUnavailable.
+func Check(ctx context.Context, dbExecutor db.Executor, aurClient aur.QueryClient,
+	vcsStore vcs.Store, opts *Options,
+) (*Result, error) {
+	var errs multierror.MultiError
+
+	result := &Result{
+		Repo:    []Upgrade{},
+		AUR:     []Upgrade{},
+		Devel:   []Upgrade{},
+		Skipped: []Skipped{},
+	}
+
+	if opts.AUR {
+		errs.Add(result.checkAUR(ctx, dbExecutor, aurClient, vcsStore, opts))
+	}
+
+	if opts.Repo {
+		errs.Add(result.checkRepo(dbExecutor, opts))
+	}
+
+	for _, ups := range [][]Upgrade{result.Repo, result.AUR, result.Devel} {
+		sort.Slice(ups, func(i, j int) bool { return ups[i].Name < ups[j].Name })
+	}
+
+	sort.SliceStable(result.Skipped, func(i, j int) bool { return result.Skipped[i].Name < result.Skipped[j].Name })
+
+	return result, errs.Return()
+}
+
+func (r *Result) checkAUR(ctx context.Context, dbExecutor db.Executor, aurClient aur.QueryClient,
+	vcsStore vcs.Store, opts *Options,
+) error {
+	remote := dbExecutor.InstalledRemotePackages()
+
+	aurPkgs, err := aurClient.Get(ctx, &aur.Query{Needles: dbExecutor.InstalledRemotePackageNames(), By: aur.Name})
+	if err != nil {
+		return err
+	}
+
+	r.AURInfo = make(map[string]*aur.Pkg, len(aurPkgs))
+	for i := range aurPkgs {
+		r.AURInfo[aurPkgs[i].Name] = &aurPkgs[i]
+	}
+
+	versioned := remote
+
+	var isDevel func(pkgName string) bool
+
+	if opts.Devel {
+		isDevel = vcsStore.Tracked
+
+		if opts.DevelStrict {
+			versioned = make(map[string]db.IPackage, len(remote))
+
+			for name, pkg := range remote {
+				if !vcsStore.Tracked(name) {
+					versioned[name] = pkg
+				}
+			}
+		}
+	}
+
+	aurUp, skipped := checkAUR(versioned, r.AURInfo, opts.TimeUpdate, opts.EnableDowngrade, isDevel)
+	r.Skipped = append(r.Skipped, skipped...)
+	r.AUR = r.skipAURUpgrades(dbExecutor, aurUp.Up, opts.IgnoreGroups)
+
+	if opts.Devel {
+		develUp, skipped := checkDevel(ctx, remote, r.AURInfo, vcsStore)
+		r.Skipped = append(r.Skipped, skipped...)
+		r.Devel = r.skipAURUpgrades(dbExecutor, develUp.Up, opts.IgnoreGroups)
+	}
+
+	return nil
+}
+
+// skipAURUpgrades drops the AUR upgrades of packages that a repository package
+// now replaces or provides, rebuilding those is pointless, and of packages
+// that the new version adds to an ignored group. Groups of the installed
+// version are already honored by alpm.
+func (r *Result) skipAURUpgrades(dbExecutor db.Executor, ups []Upgrade, ignoreGroups []string) []Upgrade {
+	ignored := mapset.NewThreadUnsafeSet(ignoreGroups...)
+	kept := make([]Upgrade, 0, len(ups))
+
+	for i := range ups {
+		up := &ups[i]
+
+		if replacer := dbExecutor.SyncReplacer(up.Name); replacer != nil {
+			r.Skipped = append(r.Skipped, Skipped{
+				Upgrade:  *up,
+				Reason:   SkipReplaced,
+				Replacer: replacer.DB().Name() + "/" + replacer.Name(),
+			})
+
+			continue
+		}
+
+		if aurPkg, ok := r.AURInfo[up.Name]; ok && ignored.ContainsAny(aurPkg.Groups...) {
+			r.Skipped = append(r.Skipped, Skipped{Upgrade: *up, Reason: SkipIgnoredGroup})
+			continue
+		}
+
+		kept = append(kept, *up)
+	}
+
+	return kept
+}
+
+func (r *Result) checkRepo(dbExecutor db.Executor, opts *Options) error {
+	ignoredRepos := mapset.NewThreadUnsafeSet(opts.IgnoreRepos...)
+
+	syncUpgrades, err := dbExecutor.SyncUpgrades(opts.EnableDowngrade)
+	r.syncUpgrades = make(map[string]db.SyncUpgrade, len(syncUpgrades))
+
+	for _, syncUp := range syncUpgrades {
+		up := Upgrade{
+			Name:          syncUp.Package.Name(),
+			RemoteVersion: syncUp.Package.Version(),
+			Repository:    syncUp.Package.DB().Name(),
+			Base:          syncUp.Package.Base(),
+			LocalVersion:  syncUp.LocalVersion,
+			Reason:        syncUp.Reason,
+		}
+
+		if ignoredRepos.Contains(up.Repository) {
+			r.Skipped = append(r.Skipped, Skipped{Upgrade: up, Reason: SkipIgnoredRepo})
+			continue
+		}
+
+		r.Repo = append(r.Repo, up)
+		r.syncUpgrades[up.Name] = syncUp
+	}
+
+	return err
+}
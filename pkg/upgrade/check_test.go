@@ -0,0 +1,144 @@
+//go:build !integration
+// +build !integration
+
+package upgrade
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Jguer/aur"
+	"github.com/Jguer/go-alpm/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jguer/yippee/v12/pkg/db"
+	"github.com/Jguer/yippee/v12/pkg/db/mock"
+	"github.com/Jguer/yippee/v12/pkg/vcs"
+
+	mockaur "github.com/Jguer/yippee/v12/pkg/dep/mock"
+)
+
+func TestCheck(t *testing.T) {
+	t.Parallel()
+
+	dbExe := &mock.DBExecutor{
+		SyncReplacerFn: func(name string) mock.IPackage {
+			if name == "paru" {
+				return &mock.Package{PName: "paru", PVersion: "2.0.0-1", PDB: mock.NewDB("extra")}
+			}
+
+			return nil
+		},
+		InstalledRemotePackageNamesFn: func() []string {
+			return []string{"yippee", "paru", "pinned", "yippee-git", "gone-git"}
+		},
+		InstalledRemotePackagesFn: func() map[string]mock.IPackage {
+			return map[string]mock.IPackage{
+				"yippee":     &mock.Package{PName: "yippee", PBase: "yippee", PVersion: "12.0.0-1"},
+				"paru":       &mock.Package{PName: "paru", PBase: "paru", PVersion: "1.0.0-1"},
+				"pinned":     &mock.Package{PName: "pinned", PBase: "pinned", PVersion: "1.0.0-1", PShouldIgnore: true},
+				"yippee-git": &mock.Package{PName: "yippee-git", PBase: "yippee-git", PVersion: "r10.abc-1"},
+				"gone-git":   &mock.Package{PName: "gone-git", PBase: "gone-git", PVersion: "r1.abc-1"},
+			}
+		},
+		SyncUpgradesFn: func(bool) (map[string]db.SyncUpgrade, error) {
+			return map[string]db.SyncUpgrade{
+				"linux": {
+					Package:      &mock.Package{PName: "linux", PVersion: "6.2-1", PDB: mock.NewDB("core")},
+					LocalVersion: "6.1-1",
+					Reason:       alpm.PkgReasonExplicit,
+				},
+				"mesa": {
+					Package:      &mock.Package{PName: "mesa", PVersion: "24.1-1", PDB: mock.NewDB("extra-testing")},
+					LocalVersion: "24.0-1",
+					Reason:       alpm.PkgReasonDepend,
+				},
+			}, nil
+		},
+	}
+
+	mockAUR := &mockaur.MockAUR{
+		GetFn: func(ctx context.Context, query *aur.Query) ([]aur.Pkg, error) {
+			return []aur.Pkg{
+				{Name: "yippee", PackageBase: "yippee", Version: "12.1.0-1"},
+				{Name: "paru", PackageBase: "paru", Version: "1.1.0-1"},
+				{Name: "pinned", PackageBase: "pinned", Version: "2.0.0-1"},
+				{Name: "yippee-git", PackageBase: "yippee-git", Version: "r5.def-1"},
+			}, nil
+		},
+	}
+
+	vcsStore := &vcs.Mock{
+		OriginsByPackage: map[string]vcs.OriginInfoByURL{"yippee-git": {}, "gone-git": {}},
+		ToUpgradeReturn:  []string{"yippee-git", "gone-git"},
+	}
+
+	got, err := Check(context.Background(), dbExe, mockAUR, vcsStore, &Options{
+		Repo:        true,
+		AUR:         true,
+		Devel:       true,
+		IgnoreRepos: []string{"extra-testing"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []Upgrade{{
+		Name: "linux", Repository: "core", LocalVersion: "6.1-1", RemoteVersion: "6.2-1", Reason: alpm.PkgReasonExplicit,
+	}}, got.Repo)
+	assert.Equal(t, []Upgrade{{
+		Name: "yippee", Base: "yippee", Repository: "aur", LocalVersion: "12.0.0-1", RemoteVersion: "12.1.0-1",
+	}}, got.AUR)
+	assert.Equal(t, []Upgrade{{
+		Name: "yippee-git", Base: "yippee-git", Repository: "devel", LocalVersion: "r10.abc-1", RemoteVersion: "latest-commit",
+	}}, got.Devel)
+	assert.Len(t, got.AURInfo, 4)
+
+	reasons := map[string]SkipReason{}
+	for _, skip := range got.Skipped {
+		reasons[skip.Name] = skip.Reason
+
+		if skip.Reason == SkipReplaced {
+			assert.Equal(t, "extra/paru", skip.Replacer)
+		}
+	}
+
+	assert.Equal(t, map[string]SkipReason{
+		"gone-git": SkipNoAURInfo,
+		"mesa":     SkipIgnoredRepo,
+		"paru":     SkipReplaced,
+		"pinned":   SkipIgnored,
+	}, reasons)
+}
+
+func TestCheckAURUnavailable(t *testing.T) {
+	t.Parallel()
+
+	dbExe := &mock.DBExecutor{
+		InstalledRemotePackageNamesFn: func() []string { return []string{"yippee"} },
+		InstalledRemotePackagesFn: func() map[string]mock.IPackage {
+			return map[string]mock.IPackage{"yippee": &mock.Package{PName: "yippee", PVersion: "12.0.0-1"}}
+		},
+		SyncUpgradesFn: func(bool) (map[string]db.SyncUpgrade, error) {
+			return map[string]db.SyncUpgrade{
+				"linux": {
+					Package:      &mock.Package{PName: "linux", PVersion: "6.2-1", PDB: mock.NewDB("core")},
+					LocalVersion: "6.1-1",
+				},
+			}, nil
+		},
+	}
+
+	mockAUR := &mockaur.MockAUR{
+		GetFn: func(ctx context.Context, query *aur.Query) ([]aur.Pkg, error) {
+			return nil, aur.ErrServiceUnavailable
+		},
+	}
+
+	got, err := Check(context.Background(), dbExe, mockAUR, &vcs.Mock{}, &Options{Repo: true, AUR: true})
+	require.ErrorIs(t, err, aur.ErrServiceUnavailable)
+
+	assert.Nil(t, got.AURInfo)
+	assert.Empty(t, got.AUR)
+	require.Len(t, got.Repo, 1)
+	assert.Equal(t, "linux", got.Repo[0].Name)
+}
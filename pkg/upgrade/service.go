@@ -2,6 +2,7 @@ package upgrade
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sort"
 	"strings"
@@ -34,6 +35,17 @@ type UpgradeService struct {
 	noConfirm  bool
 
 	AURWarnings *query.AURWarnings
+	// AURUnavailable is set when the AUR could not be reached and the user
+	// chose to go on with the repository upgrades only.
+	AURUnavailable bool
+	// IgnoreGroups are pacman's IgnoreGroup entries. AUR upgrades into one
+	// of those groups are skipped like pacman does for repository packages.
+	IgnoreGroups []string
+	// IgnoredRepoUpgrades lists the repository upgrades skipped because their
+	// repository is in IgnoreRepo. pacman must be told to ignore them too.
+	IgnoredRepoUpgrades []string
+	// Notes are shown for the packages to upgrade in the upgrade menu.
+	Notes *db.PackageNotes
 }
 
 func NewUpgradeService(grapher *dep.Grapher, aurCache aur.QueryClient,
@@ -58,10 +70,10 @@ func (u *UpgradeService) upGraph(ctx context.Context, graph *topo.Graph[string,
 	filter Filter,
 ) (err error) {
 	var (
-		develUp UpSlice
+		develUp []Upgrade
 		errs    multierror.MultiError
 		aurdata = make(map[string]*aur.Pkg)
-		aurUp   UpSlice
+		aurUp   []Upgrade
 	)
 
 	remote := u.dbExecutor.InstalledRemotePackages()
@@ -70,26 +82,45 @@ func (u *UpgradeService) upGraph(ctx context.Context, graph *topo.Graph[string,
 	if u.cfg.Mode.AtLeastAUR() {
 		u.log.OperationInfoln(gotext.Get("Searching AUR for updates..."))
 
-		_aurdata, err := u.aurCache.Get(ctx, &aur.Query{Needles: remoteNames, By: aur.Name})
+		if u.cfg.Devel {
+			u.log.OperationInfoln(gotext.Get("Checking development packages..."))
+		}
 
-		errs.Add(err)
+		result, err := Check(ctx, u.dbExecutor, u.aurCache, u.vcsStore, &Options{
+			AUR:             true,
+			Devel:           u.cfg.Devel,
+			DevelStrict:     u.cfg.DevelStrict,
+			TimeUpdate:      u.cfg.TimeUpdate,
+			EnableDowngrade: enableDowngrade,
+			IgnoreGroups:    u.IgnoreGroups,
+		})
+		if errors.Is(err, aur.ErrServiceUnavailable) && u.cfg.Mode.AtLeastRepo() && u.continueWithoutAUR() {
+			u.AURUnavailable = true
+		} else {
+			errs.Add(err)
+		}
 
 		if err == nil {
-			for i := range _aurdata {
-				pkg := &_aurdata[i]
-				aurdata[pkg.Name] = pkg
-				u.AURWarnings.AddToWarnings(remote, pkg)
+			aurdata = result.AURInfo
+
+			aurNames := make([]string, 0, len(aurdata))
+			for name := range aurdata {
+				aurNames = append(aurNames, name)
 			}
 
-			u.AURWarnings.CalculateMissing(remoteNames, remote, aurdata)
+			sort.Strings(aurNames)
 
-			aurUp = UpAUR(u.log, remote, aurdata, u.cfg.TimeUpdate, enableDowngrade)
+			for _, name := range aurNames {
+				u.AURWarnings.AddToWarnings(remote, aurdata[name])
+			}
 
-			if u.cfg.Devel {
-				u.log.OperationInfoln(gotext.Get("Checking development packages..."))
+			u.AURWarnings.CalculateMissing(remoteNames, remote, aurdata)
+
+			printSkipped(u.log, result.Skipped)
 
-				develUp = UpDevel(ctx, u.log, remote, aurdata, u.vcsStore)
+			aurUp, develUp = result.AUR, result.Devel
 
+			if u.cfg.Devel {
 				u.vcsStore.CleanOrphans(remote)
 			}
 		}
@@ -98,8 +129,8 @@ func (u *UpgradeService) upGraph(ctx context.Context, graph *topo.Graph[string,
 	aurPkgsAdded := []*aur.Pkg{}
 
 	names := mapset.NewThreadUnsafeSet[string]()
-	for i := range develUp.Up {
-		up := &develUp.Up[i]
+	for i := range develUp {
+		up := &develUp[i]
 		// check if deps are satisfied for aur packages
 		reason := dep.Explicit
 		if up.Reason == alpm.PkgReasonDepend {
@@ -124,8 +155,8 @@ func (u *UpgradeService) upGraph(ctx context.Context, graph *topo.Graph[string,
 		aurPkgsAdded = append(aurPkgsAdded, aurPkg)
 	}
 
-	for i := range aurUp.Up {
-		up := &aurUp.Up[i]
+	for i := range aurUp {
+		up := &aurUp[i]
 		// add devel packages if they are not already in the list
 		if names.Contains(up.Name) {
 			continue
@@ -158,21 +189,27 @@ func (u *UpgradeService) upGraph(ctx context.Context, graph *topo.Graph[string,
 	if u.cfg.Mode.AtLeastRepo() {
 		u.log.OperationInfoln(gotext.Get("Searching databases for updates..."))
 
-		syncUpgrades, err := u.dbExecutor.SyncUpgrades(enableDowngrade)
-		for _, up := range syncUpgrades {
-			if filter != nil && !filter(&db.Upgrade{
-				Name:          up.Package.Name(),
-				RemoteVersion: up.Package.Version(),
-				Repository:    up.Package.DB().Name(),
-				Base:          up.Package.Base(),
-				LocalVersion:  up.LocalVersion,
-				Reason:        up.Reason,
-			}) {
+		result, err := Check(ctx, u.dbExecutor, u.aurCache, u.vcsStore, &Options{
+			Repo:            true,
+			EnableDowngrade: enableDowngrade,
+			IgnoreRepos:     u.cfg.IgnoredRepos(),
+		})
+
+		printSkipped(u.log, result.Skipped)
+
+		for i := range result.Skipped {
+			if result.Skipped[i].Reason == SkipIgnoredRepo {
+				u.IgnoredRepoUpgrades = append(u.IgnoredRepoUpgrades, result.Skipped[i].Name)
+			}
+		}
+
+		for i := range result.Repo {
+			if filter != nil && !filter(&result.Repo[i]) {
 				continue
 			}
 
-			upgradeInfo := up
-			graph = u.grapher.GraphSyncPkg(ctx, graph, up.Package, &upgradeInfo)
+			upgradeInfo := result.syncUpgrades[result.Repo[i].Name]
+			graph = u.grapher.GraphSyncPkg(ctx, graph, upgradeInfo.Package, &upgradeInfo)
 		}
 
 		errs.Add(err)
@@ -181,6 +218,15 @@ func (u *UpgradeService) upGraph(ctx context.Context, graph *topo.Graph[string,
 	return errs.Return()
 }
 
+// continueWithoutAUR asks whether to upgrade the repository packages while the
+// AUR is unavailable, leaving the AUR upgrades to be retried afterwards.
+func (u *UpgradeService) continueWithoutAUR() bool {
+	u.log.Warnln(gotext.Get("The AUR is unavailable, it may be undergoing maintenance."))
+
+	return u.log.ContinueTask(gotext.Get("Upgrade repository packages now and retry the AUR afterwards?"),
+		true, u.noConfirm)
+}
+
 func (u *UpgradeService) graphToUpSlice(graph *topo.Graph[string, *dep.InstallInfo]) (aurUp, repoUp UpSlice) {
 	aurUp = UpSlice{Up: make([]Upgrade, 0, graph.Len())}
 	repoUp = UpSlice{Up: make([]Upgrade, 0, graph.Len()), Repos: u.dbExecutor.Repos()}
@@ -252,9 +298,42 @@ func (u *UpgradeService) GraphUpgrades(ctx context.Context,
 	return graph, nil
 }
 
+// printDevelLogs prints the new upstream commits of devel upgrades.
+func (u *UpgradeService) printDevelLogs(ctx context.Context, allUp *UpSlice) {
+	for i := range allUp.Up {
+		up := &allUp.Up[i]
+		if up.Repository != "devel" {
+			continue
+		}
+
+		lines := u.vcsStore.CommitLog(ctx, up.Name)
+		if len(lines) == 0 {
+			continue
+		}
+
+		u.log.Println()
+		u.log.Println(text.Bold(gotext.Get("New commits in %s:", text.Cyan(up.Name))))
+
+		for _, line := range lines {
+			u.log.Println("    " + line)
+		}
+	}
+
+	u.log.Println()
+}
+
+// printNotes prints the notes kept on the packages to upgrade.
+func (u *UpgradeService) printNotes(allUp *UpSlice) {
+	for i := range allUp.Up {
+		if note, ok := u.Notes.Get(allUp.Up[i].Name); ok {
+			u.log.Infoln(gotext.Get("Note on %s: %s", text.Cyan(allUp.Up[i].Name), note))
+		}
+	}
+}
+
 // userExcludeUpgrades asks the user which packages to exclude from the upgrade and
 // removes them from the graph
-func (u *UpgradeService) UserExcludeUpgrades(graph *topo.Graph[string, *dep.InstallInfo]) ([]string, error) {
+func (u *UpgradeService) UserExcludeUpgrades(ctx context.Context, graph *topo.Graph[string, *dep.InstallInfo]) ([]string, error) {
 	if graph.Len() == 0 {
 		return []string{}, nil
 	}
@@ -284,12 +363,26 @@ func (u *UpgradeService) UserExcludeUpgrades(graph *topo.Graph[string, *dep.Inst
 		u.log.Printf("%s"+text.Bold(" %d ")+"%s\n", text.Bold(text.Cyan("::")),
 			len(allUp.PulledDeps), text.Bold(gotext.Get("%s will also be installed for this operation.",
 				gotext.GetN("dependency", "dependencies", len(allUp.PulledDeps)))))
-		allUp.PrintDeps(u.log)
+		if u.cfg.VerbosePkgLists {
+			allUp.PrintDepsTable(u.log)
+		} else {
+			allUp.PrintDeps(u.log)
+		}
 	}
 
 	u.log.Printf("%s"+text.Bold(" %d ")+"%s\n", text.Bold(text.Cyan("::")),
 		len(allUp.Up), text.Bold(gotext.Get("%s to upgrade/install.", gotext.GetN("package", "packages", len(allUp.Up)))))
-	allUp.Print(u.log)
+	if u.cfg.VerbosePkgLists {
+		allUp.PrintTable(u.log)
+	} else {
+		allUp.Print(u.log)
+	}
+
+	if u.cfg.DevelLog {
+		u.printDevelLogs(ctx, &allUp)
+	}
+
+	u.printNotes(&allUp)
 
 	u.log.Infoln(gotext.Get("Packages to exclude: (eg: \"1 2 3\", \"1-3\", \"^4\" or repo name)"))
 	u.log.Warnln(gotext.Get("Excluding packages may cause partial upgrades and break systems"))
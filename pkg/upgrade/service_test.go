@@ -87,6 +87,7 @@ func TestUpgradeService_GraphUpgrades(t *testing.T) {
 
 	coreDB := mock.NewDB("core")
 	dbExe := &mock.DBExecutor{
+		SyncReplacerFn: func(string) mock.IPackage { return nil },
 		InstalledRemotePackageNamesFn: func() []string {
 			return []string{"yippee", "example-git"}
 		},
@@ -365,7 +366,7 @@ func TestUpgradeService_GraphUpgrades(t *testing.T) {
 				return
 			}
 
-			excluded, err := u.UserExcludeUpgrades(got)
+			excluded, err := u.UserExcludeUpgrades(context.Background(), got)
 			require.NoError(t, err)
 
 			for node, info := range tt.mustExist {
@@ -411,6 +412,7 @@ func TestUpgradeService_GraphUpgradesMissingDep(t *testing.T) {
 	}
 
 	dbExe := &mock.DBExecutor{
+		SyncReplacerFn: func(string) mock.IPackage { return nil },
 		InstalledRemotePackageNamesFn: func() []string {
 			return []string{"yippee", "example-git"}
 		},
@@ -547,7 +549,7 @@ func TestUpgradeService_GraphUpgradesMissingDep(t *testing.T) {
 				return
 			}
 
-			excluded, err := u.UserExcludeUpgrades(got)
+			excluded, err := u.UserExcludeUpgrades(context.Background(), got)
 			require.NoError(t, err)
 
 			for node, info := range tt.mustExist {
@@ -567,6 +569,7 @@ func TestUpgradeService_GraphUpgradesMissingDep(t *testing.T) {
 func TestUpgradeService_GraphUpgradesNoUpdates(t *testing.T) {
 	t.Parallel()
 	dbExe := &mock.DBExecutor{
+		SyncReplacerFn: func(string) mock.IPackage { return nil },
 		InstalledRemotePackageNamesFn: func() []string {
 			return []string{"yippee", "example-git"}
 		},
@@ -669,7 +672,7 @@ func TestUpgradeService_GraphUpgradesNoUpdates(t *testing.T) {
 				return
 			}
 
-			excluded, err := u.UserExcludeUpgrades(got)
+			excluded, err := u.UserExcludeUpgrades(context.Background(), got)
 			require.NoError(t, err)
 
 			for node, info := range tt.mustExist {
@@ -689,6 +692,7 @@ func TestUpgradeService_GraphUpgradesNoUpdates(t *testing.T) {
 func TestUpgradeService_Warnings(t *testing.T) {
 	t.Parallel()
 	dbExe := &mock.DBExecutor{
+		SyncReplacerFn: func(string) mock.IPackage { return nil },
 		InstalledRemotePackageNamesFn: func() []string {
 			return []string{"orphan", "outdated", "missing", "orphan-ignored"}
 		},
@@ -889,6 +893,7 @@ func TestUpgradeService_GraphUpgrades_zfs_dkms(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 			dbExe := &mock.DBExecutor{
+				SyncReplacerFn: func(string) mock.IPackage { return nil },
 				InstalledRemotePackageNamesFn: func() []string {
 					return tt.remotePackages
 				},
@@ -950,7 +955,7 @@ func TestUpgradeService_GraphUpgrades_zfs_dkms(t *testing.T) {
 				return
 			}
 
-			excluded, err := u.UserExcludeUpgrades(got)
+			excluded, err := u.UserExcludeUpgrades(context.Background(), got)
 			require.NoError(t, err)
 
 			for node, info := range tt.mustExist {
@@ -966,3 +971,181 @@ func TestUpgradeService_GraphUpgrades_zfs_dkms(t *testing.T) {
 		})
 	}
 }
+
+func TestUpgradeService_GraphUpgradesAURUnavailable(t *testing.T) {
+	t.Parallel()
+	dbExe := &mock.DBExecutor{
+		SyncReplacerFn: func(string) mock.IPackage { return nil },
+		InstalledRemotePackageNamesFn: func() []string {
+			return []string{"yippee"}
+		},
+		InstalledRemotePackagesFn: func() map[string]mock.IPackage {
+			return map[string]mock.IPackage{
+				"yippee": &mock.Package{PName: "yippee", PBase: "yippee", PVersion: "10.2.3"},
+			}
+		},
+		SyncUpgradesFn: func(bool) (map[string]db.SyncUpgrade, error) {
+			return map[string]db.SyncUpgrade{}, nil
+		},
+		ReposFn: func() []string { return []string{"core"} },
+	}
+
+	mockAUR := &mockaur.MockAUR{
+		GetFn: func(ctx context.Context, query *aur.Query) ([]aur.Pkg, error) {
+			return nil, aur.ErrServiceUnavailable
+		},
+	}
+
+	tests := []struct {
+		name            string
+		mode            parser.TargetMode
+		wantUnavailable bool
+		wantErr         bool
+	}{
+		{name: "repo upgrade goes on", mode: parser.ModeAny, wantUnavailable: true},
+		{name: "aur only upgrade fails", mode: parser.ModeAUR, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			logger := text.NewLogger(io.Discard, io.Discard, strings.NewReader(""), false, "test")
+			grapher := dep.NewGrapher(dbExe, mockAUR, false, true, false, false, false, logger)
+
+			u := &UpgradeService{
+				log:         logger,
+				grapher:     grapher,
+				aurCache:    mockAUR,
+				dbExecutor:  dbExe,
+				vcsStore:    &vcs.Mock{},
+				cfg:         &settings.Configuration{Mode: tt.mode},
+				noConfirm:   true,
+				AURWarnings: query.NewWarnings(logger),
+			}
+
+			_, err := u.GraphUpgrades(context.Background(), nil, false, nil)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, aur.ErrServiceUnavailable)
+			} else {
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, tt.wantUnavailable, u.AURUnavailable)
+			assert.Empty(t, u.AURWarnings.Missing)
+		})
+	}
+}
+
+func TestUpgradeService_GraphUpgradesReplaced(t *testing.T) {
+	t.Parallel()
+	dbExe := &mock.DBExecutor{
+		SyncReplacerFn: func(name string) mock.IPackage {
+			if name == "yippee" {
+				return &mock.Package{PName: "yippee", PVersion: "12.0.0", PDB: mock.NewDB("extra")}
+			}
+
+			return nil
+		},
+		InstalledRemotePackageNamesFn: func() []string {
+			return []string{"yippee"}
+		},
+		InstalledRemotePackagesFn: func() map[string]mock.IPackage {
+			return map[string]mock.IPackage{
+				"yippee": &mock.Package{PName: "yippee", PBase: "yippee", PVersion: "10.2.3"},
+			}
+		},
+		SyncUpgradesFn: func(bool) (map[string]db.SyncUpgrade, error) {
+			return map[string]db.SyncUpgrade{}, nil
+		},
+		ReposFn: func() []string { return []string{"core", "extra"} },
+	}
+
+	mockAUR := &mockaur.MockAUR{
+		GetFn: func(ctx context.Context, query *aur.Query) ([]aur.Pkg, error) {
+			return []aur.Pkg{{Name: "yippee", Version: "11.0.1", PackageBase: "yippee"}}, nil
+		},
+	}
+
+	out := &strings.Builder{}
+	logger := text.NewLogger(out, io.Discard, strings.NewReader(""), false, "test")
+	grapher := dep.NewGrapher(dbExe, mockAUR, false, true, false, false, false, logger)
+
+	u := &UpgradeService{
+		log:         logger,
+		grapher:     grapher,
+		aurCache:    mockAUR,
+		dbExecutor:  dbExe,
+		vcsStore:    &vcs.Mock{},
+		cfg:         &settings.Configuration{Mode: parser.ModeAny},
+		noConfirm:   true,
+		AURWarnings: query.NewWarnings(logger),
+	}
+
+	got, err := u.GraphUpgrades(context.Background(), nil, false, nil)
+	require.NoError(t, err)
+
+	assert.False(t, got.Exists("yippee"))
+	assert.Contains(t, out.String(), "yippee -S extra/yippee")
+}
+
+func TestUpgradeService_GraphUpgradesIgnored(t *testing.T) {
+	t.Parallel()
+	dbExe := &mock.DBExecutor{
+		SyncReplacerFn: func(string) mock.IPackage { return nil },
+		InstalledRemotePackageNamesFn: func() []string {
+			return []string{"yippee", "linux-zen-headers-git"}
+		},
+		InstalledRemotePackagesFn: func() map[string]mock.IPackage {
+			return map[string]mock.IPackage{
+				"yippee":                &mock.Package{PName: "yippee", PBase: "yippee", PVersion: "10.2.3"},
+				"linux-zen-headers-git": &mock.Package{PName: "linux-zen-headers-git", PVersion: "6.1-1"},
+			}
+		},
+		SyncUpgradesFn: func(bool) (map[string]db.SyncUpgrade, error) {
+			return map[string]db.SyncUpgrade{
+				"linux": {
+					Package:      &mock.Package{PName: "linux", PVersion: "6.2-1", PDB: mock.NewDB("core-testing")},
+					LocalVersion: "6.1-1",
+					Reason:       alpm.PkgReasonExplicit,
+				},
+			}, nil
+		},
+		ReposFn: func() []string { return []string{"core-testing", "core", "extra"} },
+	}
+
+	mockAUR := &mockaur.MockAUR{
+		GetFn: func(ctx context.Context, query *aur.Query) ([]aur.Pkg, error) {
+			return []aur.Pkg{
+				{Name: "yippee", Version: "11.0.1", PackageBase: "yippee", Groups: []string{"pacman-helpers"}},
+				{Name: "linux-zen-headers-git", Version: "6.2-1", PackageBase: "linux-zen-headers-git"},
+			}, nil
+		},
+	}
+
+	out := &strings.Builder{}
+	logger := text.NewLogger(out, out, strings.NewReader(""), false, "test")
+	grapher := dep.NewGrapher(dbExe, mockAUR, false, true, false, false, false, logger)
+
+	u := &UpgradeService{
+		log:          logger,
+		grapher:      grapher,
+		aurCache:     mockAUR,
+		dbExecutor:   dbExe,
+		vcsStore:     &vcs.Mock{},
+		cfg:          &settings.Configuration{Mode: parser.ModeAny, IgnoreRepo: "core-testing,extra-testing"},
+		noConfirm:    true,
+		AURWarnings:  query.NewWarnings(logger),
+		IgnoreGroups: []string{"pacman-helpers"},
+	}
+
+	got, err := u.GraphUpgrades(context.Background(), nil, false, nil)
+	require.NoError(t, err)
+
+	assert.False(t, got.Exists("yippee"))
+	assert.False(t, got.Exists("linux"))
+	assert.True(t, got.Exists("linux-zen-headers-git"))
+	assert.Equal(t, []string{"linux"}, u.IgnoredRepoUpgrades)
+	assert.Contains(t, out.String(), "ignoring package upgrade")
+}
@@ -2,6 +2,7 @@ package upgrade
 
 import (
 	"context"
+	"strings"
 
 	"github.com/leonelquinteros/gotext"
 
@@ -11,6 +12,8 @@ import (
 	"github.com/Jguer/yippee/v12/pkg/vcs"
 )
 
+// UpDevel gathers the development packages with new upstream commits and
+// prints the ones skipped.
 func UpDevel(
 	ctx context.Context,
 	log *text.Logger,
@@ -18,54 +21,142 @@ func UpDevel(
 	aurdata map[string]*query.Pkg,
 	localCache vcs.Store,
 ) UpSlice {
+	toUpgrade, skipped := checkDevel(ctx, remote, aurdata, localCache)
+	printSkipped(log, skipped)
+
+	return toUpgrade
+}
+
+func checkDevel(
+	ctx context.Context,
+	remote map[string]db.IPackage,
+	aurdata map[string]*query.Pkg,
+	localCache vcs.Store,
+) (toUpgrade UpSlice, skipped []Skipped) {
 	toRemove := make([]string, 0)
-	toUpgrade := UpSlice{Up: make([]Upgrade, 0), Repos: []string{"devel"}}
+	toUpgrade = UpSlice{Up: make([]Upgrade, 0), Repos: []string{"devel"}}
+
+	pkgNames := make([]string, 0, len(remote))
+	for pkgName := range remote {
+		pkgNames = append(pkgNames, pkgName)
+	}
+
+	outdated := localCache.ToUpgradeAll(ctx, pkgNames)
 
 	for pkgName, pkg := range remote {
-		if localCache.ToUpgrade(ctx, pkgName) {
+		if outdated[pkgName] {
+			up := Upgrade{
+				Name:          pkg.Name(),
+				Base:          pkg.Base(),
+				Repository:    "devel",
+				LocalVersion:  pkg.Version(),
+				RemoteVersion: "latest-commit",
+				Reason:        pkg.Reason(),
+			}
+
 			if _, ok := aurdata[pkgName]; !ok {
-				log.Warnln(gotext.Get("ignoring package devel upgrade (no AUR info found):"), pkgName)
+				skipped = append(skipped, Skipped{Upgrade: up, Reason: SkipNoAURInfo})
 				continue
 			}
 
 			if pkg.ShouldIgnore() {
-				printIgnoringPackage(log, pkg, "latest-commit")
+				skipped = append(skipped, Skipped{Upgrade: up, Reason: SkipIgnored})
 				continue
 			}
 
-			toUpgrade.Up = append(toUpgrade.Up,
-				Upgrade{
-					Name:          pkg.Name(),
-					Base:          pkg.Base(),
-					Repository:    "devel",
-					LocalVersion:  pkg.Version(),
-					RemoteVersion: "latest-commit",
-					Reason:        pkg.Reason(),
-				})
+			toUpgrade.Up = append(toUpgrade.Up, up)
 		}
 	}
 
 	localCache.RemovePackages(toRemove)
 
-	return toUpgrade
+	return toUpgrade, skipped
+}
+
+// printSkipped prints why each of skipped is left out of the upgrade.
+func printSkipped(log *text.Logger, skipped []Skipped) {
+	for i := range skipped {
+		skip := &skipped[i]
+
+		switch skip.Reason {
+		case SkipNoAURInfo:
+			log.Warnln(gotext.Get("ignoring package devel upgrade (no AUR info found):"), skip.Name)
+		case SkipReplaced:
+			log.Warnln(gotext.Get("%s is replaced by %s, skipping its AUR upgrade. Migrate with: %s",
+				text.Cyan(skip.Name), text.Cyan(skip.Replacer), "yippee -S "+skip.Replacer))
+		default:
+			printIgnoringUpgrade(log, skip.Name, skip.LocalVersion, skip.RemoteVersion)
+		}
+	}
 }
 
-func printIgnoringPackage(log *text.Logger, pkg db.IPackage, newPkgVersion string) {
-	left, right := query.GetVersionDiff(pkg.Version(), newPkgVersion)
+func printIgnoringUpgrade(log *text.Logger, pkgName, oldVersion, newVersion string) {
+	left, right := query.GetVersionDiff(oldVersion, newVersion)
 
-	pkgName := pkg.Name()
 	log.Warnln(gotext.Get("%s: ignoring package upgrade (%s => %s)",
 		text.Cyan(pkgName),
 		left, right,
 	))
 }
 
+// splitVersion splits a full version into its epoch, pkgver and pkgrel.
+// A missing epoch is reported as "0".
+func splitVersion(version string) (epoch, pkgver, pkgrel string) {
+	epoch = "0"
+	if e, rest, ok := strings.Cut(version, ":"); ok {
+		epoch, version = e, rest
+	}
+
+	if i := strings.LastIndexByte(version, '-'); i >= 0 {
+		return epoch, version[:i], version[i+1:]
+	}
+
+	return epoch, version, ""
+}
+
+// compareVersions returns 1 if remote is an upgrade over local, -1 if it is a
+// downgrade and 0 otherwise, following vercmp semantics.
+//
+// The AUR pkgver of a development package is the one of the last PKGBUILD
+// push and usually lags behind the installed build. For those an older pkgver
+// is not a downgrade, only an epoch change or, for the same epoch, a pkgrel
+// bump is taken into account.
+func compareVersions(local, remote string, devel bool) int {
+	cmp := db.VerCmp(remote, local)
+	if !devel || cmp >= 0 {
+		return cmp
+	}
+
+	localEpoch, _, localRel := splitVersion(local)
+	remoteEpoch, _, remoteRel := splitVersion(remote)
+
+	if cmp := db.VerCmp(remoteEpoch, localEpoch); cmp != 0 {
+		return cmp
+	}
+
+	if db.VerCmp(remoteRel, localRel) > 0 {
+		return 1
+	}
+
+	return 0
+}
+
 // UpAUR gathers foreign packages and checks if they have new versions.
+// isDevel reports the development packages, it may be nil.
 // Output: Upgrade type package list.
 func UpAUR(log *text.Logger, remote map[string]db.IPackage, aurdata map[string]*query.Pkg,
-	timeUpdate, enableDowngrade bool,
+	timeUpdate, enableDowngrade bool, isDevel func(pkgName string) bool,
 ) UpSlice {
-	toUpgrade := UpSlice{Up: make([]Upgrade, 0), Repos: []string{"aur"}}
+	toUpgrade, skipped := checkAUR(remote, aurdata, timeUpdate, enableDowngrade, isDevel)
+	printSkipped(log, skipped)
+
+	return toUpgrade
+}
+
+func checkAUR(remote map[string]db.IPackage, aurdata map[string]*query.Pkg,
+	timeUpdate, enableDowngrade bool, isDevel func(pkgName string) bool,
+) (toUpgrade UpSlice, skipped []Skipped) {
+	toUpgrade = UpSlice{Up: make([]Upgrade, 0), Repos: []string{"aur"}}
 
 	for name, pkg := range remote {
 		aurPkg, ok := aurdata[name]
@@ -73,24 +164,27 @@ func UpAUR(log *text.Logger, remote map[string]db.IPackage, aurdata map[string]*
 			continue
 		}
 
+		devel := isDevel != nil && isDevel(name)
+		cmp := compareVersions(pkg.Version(), aurPkg.Version, devel)
+
 		if (timeUpdate && (int64(aurPkg.LastModified) > pkg.BuildDate().Unix())) ||
-			(db.VerCmp(pkg.Version(), aurPkg.Version) < 0) ||
-			(enableDowngrade && (db.VerCmp(pkg.Version(), aurPkg.Version) > 0)) {
+			cmp > 0 || (enableDowngrade && cmp < 0) {
+			up := Upgrade{
+				Name:          aurPkg.Name,
+				Base:          aurPkg.PackageBase,
+				Repository:    "aur",
+				LocalVersion:  pkg.Version(),
+				RemoteVersion: aurPkg.Version,
+				Reason:        pkg.Reason(),
+			}
+
 			if pkg.ShouldIgnore() {
-				printIgnoringPackage(log, pkg, aurPkg.Version)
+				skipped = append(skipped, Skipped{Upgrade: up, Reason: SkipIgnored})
 			} else {
-				toUpgrade.Up = append(toUpgrade.Up,
-					Upgrade{
-						Name:          aurPkg.Name,
-						Base:          aurPkg.PackageBase,
-						Repository:    "aur",
-						LocalVersion:  pkg.Version(),
-						RemoteVersion: aurPkg.Version,
-						Reason:        pkg.Reason(),
-					})
+				toUpgrade.Up = append(toUpgrade.Up, up)
 			}
 		}
 	}
 
-	return toUpgrade
+	return toUpgrade, skipped
 }
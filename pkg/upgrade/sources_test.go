@@ -7,6 +7,7 @@ import (
 	"context"
 	"io"
 	"os"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -29,6 +30,7 @@ func Test_upAUR(t *testing.T) {
 		aurdata         map[string]*aur.Pkg
 		timeUpdate      bool
 		enableDowngrade bool
+		devel           []string
 	}
 	tests := []struct {
 		name string
@@ -120,6 +122,30 @@ func Test_upAUR(t *testing.T) {
 			},
 			want: UpSlice{Repos: []string{"aur"}, Up: []Upgrade{{Name: "hello", Repository: "aur", LocalVersion: "2.0.0", RemoteVersion: "2.0.0"}}},
 		},
+		{
+			name: "Devel Epoch And Pkgrel Bumps",
+			args: args{
+				enableDowngrade: true,
+				remote: map[string]alpm.IPackage{
+					"epoch-git":  &mock.Package{PName: "epoch-git", PVersion: "r120.abc-1"},
+					"pkgrel-git": &mock.Package{PName: "pkgrel-git", PVersion: "r120.abc-1"},
+					"stale-git":  &mock.Package{PName: "stale-git", PVersion: "r120.abc-2"},
+					"down-git":   &mock.Package{PName: "down-git", PVersion: "1:r120.abc-1"},
+				},
+				aurdata: map[string]*aur.Pkg{
+					"epoch-git":  {Version: "1:r100.def-1", Name: "epoch-git"},
+					"pkgrel-git": {Version: "r100.def-2", Name: "pkgrel-git"},
+					"stale-git":  {Version: "r100.def-2", Name: "stale-git"},
+					"down-git":   {Version: "r130.def-1", Name: "down-git"},
+				},
+				devel: []string{"epoch-git", "pkgrel-git", "stale-git", "down-git"},
+			},
+			want: UpSlice{Repos: []string{"aur"}, Up: []Upgrade{
+				{Name: "epoch-git", Repository: "aur", LocalVersion: "r120.abc-1", RemoteVersion: "1:r100.def-1"},
+				{Name: "pkgrel-git", Repository: "aur", LocalVersion: "r120.abc-1", RemoteVersion: "r100.def-2"},
+				{Name: "down-git", Repository: "aur", LocalVersion: "1:r120.abc-1", RemoteVersion: "r130.def-1"},
+			}},
+		},
 	}
 	for _, tt := range tests {
 		tt := tt
@@ -127,7 +153,8 @@ func Test_upAUR(t *testing.T) {
 			t.Parallel()
 
 			got := UpAUR(text.NewLogger(io.Discard, os.Stderr, strings.NewReader(""), false, "test"),
-				tt.args.remote, tt.args.aurdata, tt.args.timeUpdate, tt.args.enableDowngrade)
+				tt.args.remote, tt.args.aurdata, tt.args.timeUpdate, tt.args.enableDowngrade,
+				func(pkgName string) bool { return slices.Contains(tt.args.devel, pkgName) })
 			assert.ElementsMatch(t, tt.want.Repos, got.Repos)
 			assert.ElementsMatch(t, tt.want.Up, got.Up)
 			assert.Equal(t, tt.want.Len(), got.Len())
@@ -135,6 +162,30 @@ func Test_upAUR(t *testing.T) {
 	}
 }
 
+func Test_compareVersions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		local, remote string
+		devel         bool
+		want          int
+	}{
+		{local: "1.0-1", remote: "1.1-1", want: 1},
+		{local: "1.1-1", remote: "1.0-1", want: -1},
+		{local: "1.0-1", remote: "1.0-2", want: 1},
+		{local: "2.0-1", remote: "1:1.0-1", want: 1},
+		{local: "r120.abc-1", remote: "r100.def-1", want: -1},
+		{local: "r120.abc-1", remote: "r100.def-1", devel: true, want: 0},
+		{local: "r120.abc-1", remote: "r100.def-2", devel: true, want: 1},
+		{local: "r120.abc-1", remote: "r130.def-1", devel: true, want: 1},
+		{local: "2:r120.abc-1", remote: "1:r130.def-1", devel: true, want: -1},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, compareVersions(tt.local, tt.remote, tt.devel), "%s => %s", tt.local, tt.remote)
+	}
+}
+
 func Test_upDevel(t *testing.T) {
 	t.Parallel()
 
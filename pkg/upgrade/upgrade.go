@@ -2,8 +2,11 @@ package upgrade
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
+	"github.com/leonelquinteros/gotext"
+
 	"github.com/Jguer/yippee/v12/pkg/db"
 	"github.com/Jguer/yippee/v12/pkg/query"
 	"github.com/Jguer/yippee/v12/pkg/text"
@@ -115,3 +118,49 @@ func (u UpSlice) PrintDeps(logger *text.Logger) {
 
 	logger.Println()
 }
+
+// PrintTable prints the packages to upgrade as a table, the way pacman does
+// with VerbosePkgLists. The numbers are the ones the exclusion menu takes.
+func (u UpSlice) PrintTable(logger *text.Logger) {
+	table := newUpgradeTable("#")
+
+	for k := range u.Up {
+		addUpgradeRow(table, &u.Up[k], text.Magenta(strconv.Itoa(len(u.Up)-k)))
+	}
+
+	logger.Print(table.String())
+}
+
+// PrintDepsTable prints the dependencies pulled by the upgrade as a table.
+func (u UpSlice) PrintDepsTable(logger *text.Logger) {
+	table := newUpgradeTable()
+
+	for k := range u.PulledDeps {
+		addUpgradeRow(table, &u.PulledDeps[k])
+	}
+
+	logger.Print(table.String())
+	logger.Println()
+}
+
+// newUpgradeTable returns a table of packages to upgrade, with the columns
+// of first before the package ones.
+func newUpgradeTable(first ...string) *text.Table {
+	return text.NewTable(append(first, gotext.Get("Name"), gotext.Get("Old Version"),
+		gotext.Get("New Version"), gotext.Get("Source"), gotext.Get("Build Required"))...)
+}
+
+func addUpgradeRow(table *text.Table, upgrade *Upgrade, first ...string) {
+	left, right := upgrade.LocalVersion, upgrade.RemoteVersion
+	if left != "" {
+		left, right = query.GetVersionDiff(upgrade.LocalVersion, upgrade.RemoteVersion)
+	}
+
+	build := gotext.Get("No")
+	if upgrade.Repository == "aur" || upgrade.Repository == "devel" {
+		build = gotext.Get("Yes")
+	}
+
+	table.AddRow(append(first, text.Bold(upgrade.Name), left, right,
+		text.Bold(text.ColorHash(upgrade.Repository)), build)...)
+}
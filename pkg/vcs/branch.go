@@ -0,0 +1,96 @@
+package vcs
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/leonelquinteros/gotext"
+
+	"github.com/Jguer/yippee/v12/pkg/text"
+)
+
+const headRef = "HEAD"
+
+// DefaultBranch resolves the branch HEAD of the remote points to and its commit.
+func (g *GitChecker) DefaultBranch(ctx context.Context, url string, protocols []string) (branch, commit string) {
+	if len(protocols) == 0 {
+		return "", ""
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	cmd := g.CmdBuilder.BuildGitCmd(ctxTimeout, "", "ls-remote", "--symref",
+		protocols[len(protocols)-1]+"://"+url, headRef)
+
+	stdout, stderr, err := g.CmdBuilder.Capture(cmd)
+	if err != nil {
+		g.logger.Debugln("unable to resolve default branch of", url, ":", stderr, err)
+		return "", ""
+	}
+
+	return parseSymref(stdout)
+}
+
+// parseSymref parses the output of git ls-remote --symref <url> HEAD.
+func parseSymref(stdout string) (branch, commit string) {
+	for _, line := range strings.Split(stdout, "\n") {
+		fields := strings.Fields(line)
+
+		switch {
+		case len(fields) == 3 && fields[0] == "ref:" && fields[2] == headRef:
+			branch = strings.TrimPrefix(fields[1], "refs/heads/")
+		case len(fields) == 2 && fields[1] == headRef:
+			commit = fields[0]
+		}
+	}
+
+	return branch, commit
+}
+
+// resolveDefaultBranch returns the default branch of url and its commit,
+// falling back to tracking HEAD when the branch can not be resolved.
+func (v *InfoStore) resolveDefaultBranch(ctx context.Context, url string, protocols []string) (branch, commit string) {
+	branch, commit = v.checker().DefaultBranch(ctx, url, protocols)
+	if branch == "" || commit == "" {
+		return headRef, v.getCommit(ctx, url, headRef, protocols)
+	}
+
+	return branch, commit
+}
+
+// followDefaultBranch is called when the branch tracked by an origin can no
+// longer be found upstream. Origins following the default branch are moved
+// to the new default branch, e.g. after a master to main rename.
+// It returns the commit of the branch now tracked.
+func (v *InfoStore) followDefaultBranch(ctx context.Context, infos OriginInfoByURL, url string) string {
+	v.mux.Lock()
+	info := infos[url]
+	v.mux.Unlock()
+
+	if !info.Default {
+		v.logger.Debugln("branch", info.Branch, "not found upstream for", url)
+		return ""
+	}
+
+	branch, commit := v.checker().DefaultBranch(ctx, url, info.Protocols)
+	if branch == "" || branch == info.Branch {
+		return commit
+	}
+
+	v.logger.Warnln(gotext.Get("default branch of %s was renamed from %s to %s",
+		text.Cyan(url), info.Branch, branch))
+
+	v.mux.Lock()
+	info.Branch = branch
+	infos[url] = info
+
+	if err := v.Save(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	v.mux.Unlock()
+
+	return commit
+}
@@ -0,0 +1,56 @@
+package vcs
+
+import (
+	"context"
+	"sync"
+)
+
+// remoteCache memoizes remote commits for the duration of a run so origins
+// shared between packages, such as split packages, are queried only once.
+// Long running services reset it before each check.
+type remoteCache struct {
+	mux     sync.Mutex
+	entries map[remote]*cachedCommit
+}
+
+type cachedCommit struct {
+	once   sync.Once
+	commit string
+}
+
+// get returns the cached commit of r, resolving it with query on first use.
+// Concurrent callers for the same remote wait on a single query.
+func (c *remoteCache) get(r remote, query func() string) string {
+	c.mux.Lock()
+	if c.entries == nil {
+		c.entries = make(map[remote]*cachedCommit)
+	}
+
+	entry, ok := c.entries[r]
+	if !ok {
+		entry = &cachedCommit{}
+		c.entries[r] = entry
+	}
+	c.mux.Unlock()
+
+	entry.once.Do(func() {
+		entry.commit = query()
+	})
+
+	return entry.commit
+}
+
+// reset forgets every cached commit.
+func (c *remoteCache) reset() {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	c.entries = nil
+}
+
+// getCommit returns the commit of branch from url, memoized per run.
+func (v *InfoStore) getCommit(ctx context.Context, url, branch string, protocols []string) string {
+	return v.cache.get(remote{url, branch}, func() string {
+		return v.checker().RemoteCommit(ctx, url, branch, protocols)
+	})
+}
@@ -0,0 +1,149 @@
+package vcs
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/leonelquinteros/gotext"
+
+	"github.com/Jguer/yippee/v12/pkg/settings/exe"
+	"github.com/Jguer/yippee/v12/pkg/text"
+)
+
+// defaultWorkers is the amount of remotes queried concurrently.
+const defaultWorkers = 16
+
+// Checker resolves the commit a remote branch currently points to.
+// It returns an empty string if the commit could not be resolved.
+type Checker interface {
+	RemoteCommit(ctx context.Context, url, branch string, protocols []string) string
+	// DefaultBranch resolves the default branch of the remote and its commit.
+	DefaultBranch(ctx context.Context, url string, protocols []string) (branch, commit string)
+}
+
+// GitChecker resolves remote commits using git ls-remote.
+type GitChecker struct {
+	CmdBuilder exe.GitCmdBuilder
+	logger     *text.Logger
+}
+
+func NewGitChecker(cmdBuilder exe.GitCmdBuilder, logger *text.Logger) *GitChecker {
+	return &GitChecker{CmdBuilder: cmdBuilder, logger: logger}
+}
+
+func (g *GitChecker) RemoteCommit(ctx context.Context, url, branch string, protocols []string) string {
+	if len(protocols) == 0 {
+		return ""
+	}
+
+	protocol := protocols[len(protocols)-1]
+
+	ctxTimeout, cancel := context.WithTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	cmd := g.CmdBuilder.BuildGitCmd(ctxTimeout, "", "ls-remote", protocol+"://"+url, branch)
+
+	stdout, stderr, err := g.CmdBuilder.Capture(cmd)
+	if err != nil {
+		exitError := &exec.ExitError{}
+		if ok := errors.As(err, &exitError); ok && exitError.ExitCode() == 128 {
+			g.logger.Warnln(gotext.Get("devel check for package failed: '%s' encountered an error", cmd.String()), ": ", stderr)
+			return ""
+		}
+
+		g.logger.Warnln(gotext.Get("devel check for package failed: '%s' encountered an error", cmd.String()), ": ", err)
+
+		return ""
+	}
+
+	split := strings.Fields(stdout)
+
+	if len(split) < 2 {
+		return ""
+	}
+
+	return split[0]
+}
+
+// remote identifies a branch of an origin.
+type remote struct {
+	url    string
+	branch string
+}
+
+// queryRemotes resolves the commit of every remote once using a bounded
+// pool of workers.
+func (v *InfoStore) queryRemotes(ctx context.Context, protocols map[remote][]string) map[remote]string {
+	workers := v.Workers
+	if workers <= 0 {
+		workers = defaultWorkers
+	}
+
+	jobs := make(chan remote)
+	commits := make(map[remote]string, len(protocols))
+
+	var (
+		mux sync.Mutex
+		wg  sync.WaitGroup
+	)
+
+	for i := 0; i < min(workers, len(protocols)); i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for r := range jobs {
+				commit := v.getCommit(ctx, r.url, r.branch, protocols[r])
+
+				mux.Lock()
+				commits[r] = commit
+				mux.Unlock()
+			}
+		}()
+	}
+
+	for r := range protocols {
+		jobs <- r
+	}
+
+	close(jobs)
+	wg.Wait()
+
+	return commits
+}
+
+// ToUpgradeAll returns the packages out of pkgNames that need to be updated.
+// Remotes shared between packages are only queried once.
+func (v *InfoStore) ToUpgradeAll(ctx context.Context, pkgNames []string) map[string]bool {
+	protocols := make(map[remote][]string)
+
+	for _, pkgName := range pkgNames {
+		for url, info := range v.OriginsByPackage[pkgName] {
+			protocols[remote{url, info.Branch}] = info.Protocols
+		}
+	}
+
+	commits := v.queryRemotes(ctx, protocols)
+	toUpgrade := make(map[string]bool)
+
+	for _, pkgName := range pkgNames {
+		infos := v.OriginsByPackage[pkgName]
+		for url, info := range infos {
+			commit := commits[remote{url, info.Branch}]
+			if commit == "" {
+				commit = v.followDefaultBranch(ctx, infos, url)
+			}
+
+			if commit != "" && commit != info.SHA {
+				toUpgrade[pkgName] = true
+				break
+			}
+		}
+	}
+
+	return toUpgrade
+}
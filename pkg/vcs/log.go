@@ -0,0 +1,87 @@
+package vcs
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// commitLogDepth is the amount of commits fetched to build a commit log.
+const commitLogDepth = 20
+
+// CommitLog returns the abbreviated commits between the pinned and the
+// remote commit of every outdated origin of pkgName, newest first.
+// Logs truncated at commitLogDepth end with "...".
+func (v *InfoStore) CommitLog(ctx context.Context, pkgName string) []string {
+	lines := make([]string, 0)
+
+	for url, info := range v.OriginsByPackage[pkgName] {
+		commit := v.getCommit(ctx, url, info.Branch, info.Protocols)
+		if commit == "" || commit == info.SHA || len(info.Protocols) == 0 {
+			continue
+		}
+
+		lines = append(lines, v.originLog(ctx, info.Protocols[len(info.Protocols)-1]+"://"+url, info)...)
+	}
+
+	return lines
+}
+
+// originLog shallow fetches the branch of origin into a temporary repository
+// and lists the commits made since the pinned one.
+func (v *InfoStore) originLog(ctx context.Context, origin string, info OriginInfo) []string {
+	dir, err := os.MkdirTemp("", "yippee-vcs-log-*")
+	if err != nil {
+		v.logger.Debugln("unable to create commit log dir:", err)
+		return nil
+	}
+	defer os.RemoveAll(dir)
+
+	ctxTimeout, cancel := context.WithTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	for _, args := range [][]string{
+		{"init", "--bare", "--quiet"},
+		{"fetch", "--quiet", "--depth=" + strconv.Itoa(commitLogDepth), origin, info.Branch},
+	} {
+		if _, stderr, err := v.CmdBuilder.Capture(v.CmdBuilder.BuildGitCmd(ctxTimeout, dir, args...)); err != nil {
+			v.logger.Debugln("unable to fetch commit log of", origin, ":", stderr, err)
+			return nil
+		}
+	}
+
+	stdout, _, err := v.CmdBuilder.Capture(v.CmdBuilder.BuildGitCmd(ctxTimeout, dir,
+		"log", "--format=%H %h %s", "FETCH_HEAD"))
+	if err != nil {
+		v.logger.Debugln("unable to read commit log of", origin, ":", err)
+		return nil
+	}
+
+	return parseCommitLog(stdout, info.SHA)
+}
+
+// parseCommitLog keeps the "<short hash> <subject>" of every commit newer
+// than pinned from a "%H %h %s" formatted git log.
+func parseCommitLog(stdout, pinned string) []string {
+	lines := make([]string, 0)
+
+	for _, line := range strings.Split(strings.TrimSpace(stdout), "\n") {
+		hash, oneline, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+
+		if hash == pinned {
+			return lines
+		}
+
+		lines = append(lines, oneline)
+	}
+
+	if len(lines) >= commitLogDepth {
+		lines = append(lines, "...")
+	}
+
+	return lines
+}
@@ -10,6 +10,12 @@ import (
 type Mock struct {
 	OriginsByPackage map[string]OriginInfoByURL
 	ToUpgradeReturn  []string
+	CommitLogReturn  map[string][]string
+}
+
+func (m *Mock) Tracked(pkgName string) bool {
+	_, ok := m.OriginsByPackage[pkgName]
+	return ok
 }
 
 func (m *Mock) ToUpgrade(ctx context.Context, pkgName string) bool {
@@ -21,6 +27,22 @@ func (m *Mock) ToUpgrade(ctx context.Context, pkgName string) bool {
 	return false
 }
 
+func (m *Mock) ToUpgradeAll(ctx context.Context, pkgNames []string) map[string]bool {
+	toUpgrade := make(map[string]bool)
+
+	for _, pkgName := range pkgNames {
+		if m.ToUpgrade(ctx, pkgName) {
+			toUpgrade[pkgName] = true
+		}
+	}
+
+	return toUpgrade
+}
+
+func (m *Mock) CommitLog(ctx context.Context, pkgName string) []string {
+	return m.CommitLogReturn[pkgName]
+}
+
 func (m *Mock) Update(ctx context.Context, pkgName string, sources []gosrc.ArchString) {
 }
 
@@ -37,3 +59,6 @@ func (m *Mock) Load() error {
 
 func (m *Mock) CleanOrphans(pkgs map[string]alpm.IPackage) {
 }
+
+func (m *Mock) ResetCache() {
+}
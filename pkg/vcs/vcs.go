@@ -3,10 +3,8 @@ package vcs
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"os"
-	"os/exec"
 	"strings"
 	"sync"
 	"time"
@@ -22,8 +20,14 @@ import (
 const defaultTimeout = 15 * time.Second
 
 type Store interface {
+	// Tracked returns true if the store holds VCS info for the package.
+	Tracked(pkgName string) bool
 	// ToUpgrade returns true if the package needs to be updated.
 	ToUpgrade(ctx context.Context, pkgName string) bool
+	// ToUpgradeAll returns the packages that need to be updated, querying remotes in parallel.
+	ToUpgradeAll(ctx context.Context, pkgNames []string) map[string]bool
+	// CommitLog returns the commits made upstream since the package was installed.
+	CommitLog(ctx context.Context, pkgName string) []string
 	// Update updates the VCS info of a package.
 	Update(ctx context.Context, pkgName string, sources []gosrc.ArchString)
 	// RemovePackages removes the VCS info of the packages given as arg if they exist.
@@ -34,6 +38,9 @@ type Store interface {
 	Load() error
 	// Save saves the VCS info to disk.
 	Save() error
+	// ResetCache forgets the remote commits queried so far, failed queries
+	// included, so the next checks ask the remotes again.
+	ResetCache()
 }
 
 // InfoStore is a collection of OriginInfoByURL by Package.
@@ -42,7 +49,10 @@ type InfoStore struct {
 	OriginsByPackage map[string]OriginInfoByURL
 	FilePath         string
 	CmdBuilder       exe.GitCmdBuilder
+	Checker          Checker
+	Workers          int
 	mux              sync.Mutex
+	cache            remoteCache
 	logger           *text.Logger
 }
 
@@ -63,6 +73,8 @@ type OriginInfo struct {
 	Protocols []string `json:"protocols"`
 	Branch    string   `json:"branch"`
 	SHA       string   `json:"sha"`
+	// Default is set when Branch was resolved from the remote HEAD.
+	Default bool `json:"default,omitempty"`
 }
 
 func NewInfoStore(filePath string, cmdBuilder exe.GitCmdBuilder,
@@ -70,6 +82,8 @@ func NewInfoStore(filePath string, cmdBuilder exe.GitCmdBuilder,
 ) *InfoStore {
 	infoStore := &InfoStore{
 		CmdBuilder:       cmdBuilder,
+		Checker:          NewGitChecker(cmdBuilder, logger),
+		Workers:          defaultWorkers,
 		FilePath:         filePath,
 		OriginsByPackage: map[string]OriginInfoByURL{},
 		mux:              sync.Mutex{},
@@ -79,41 +93,13 @@ func NewInfoStore(filePath string, cmdBuilder exe.GitCmdBuilder,
 	return infoStore
 }
 
-// GetCommit parses HEAD commit from url and branch.
-func (v *InfoStore) getCommit(ctx context.Context, url, branch string, protocols []string) string {
-	if len(protocols) > 0 {
-		protocol := protocols[len(protocols)-1]
-
-		ctxTimeout, cancel := context.WithTimeout(ctx, defaultTimeout)
-		defer cancel()
-
-		cmd := v.CmdBuilder.BuildGitCmd(ctxTimeout, "", "ls-remote", protocol+"://"+url, branch)
-
-		stdout, stderr, err := v.CmdBuilder.Capture(cmd)
-		if err != nil {
-			exitError := &exec.ExitError{}
-			if ok := errors.As(err, &exitError); ok && exitError.ExitCode() == 128 {
-				v.logger.Warnln(gotext.Get("devel check for package failed: '%s' encountered an error", cmd.String()), ": ", stderr)
-				return ""
-			}
-
-			v.logger.Warnln(gotext.Get("devel check for package failed: '%s' encountered an error", cmd.String()), ": ", err)
-
-			return ""
-		}
-
-		split := strings.Fields(stdout)
-
-		if len(split) < 2 {
-			return ""
-		}
-
-		commit := split[0]
-
-		return commit
+// checker returns the Checker used to query remotes, git by default.
+func (v *InfoStore) checker() Checker {
+	if v.Checker != nil {
+		return v.Checker
 	}
 
-	return ""
+	return NewGitChecker(v.CmdBuilder, v.logger)
 }
 
 func (v *InfoStore) Update(ctx context.Context, pkgName string, sources []gosrc.ArchString) {
@@ -127,16 +113,25 @@ func (v *InfoStore) Update(ctx context.Context, pkgName string, sources []gosrc.
 			return
 		}
 
-		commit := v.getCommit(ctx, url, branch, protocols)
+		isDefault := branch == headRef
+
+		var commit string
+		if isDefault {
+			branch, commit = v.resolveDefaultBranch(ctx, url, protocols)
+		} else {
+			commit = v.getCommit(ctx, url, branch, protocols)
+		}
+
 		if commit == "" {
 			return
 		}
 
 		v.mux.Lock()
 		info[url] = OriginInfo{
-			protocols,
-			branch,
-			commit,
+			Protocols: protocols,
+			Branch:    branch,
+			SHA:       commit,
+			Default:   isDefault && branch != headRef,
 		}
 
 		v.OriginsByPackage[pkgName] = info
@@ -200,7 +195,7 @@ func parseSource(source string) (url, branch string, protocols []string) {
 		}
 	} else {
 		url = split[0]
-		branch = "HEAD"
+		branch = headRef
 	}
 
 	url = strings.Split(url, "?")[0]
@@ -209,6 +204,12 @@ func parseSource(source string) (url, branch string, protocols []string) {
 	return url, branch, protocols
 }
 
+func (v *InfoStore) Tracked(pkgName string) bool {
+	_, ok := v.OriginsByPackage[pkgName]
+
+	return ok
+}
+
 func (v *InfoStore) ToUpgrade(ctx context.Context, pkgName string) bool {
 	if infos, ok := v.OriginsByPackage[pkgName]; ok {
 		return v.needsUpdate(ctx, infos)
@@ -230,6 +231,9 @@ func (v *InfoStore) needsUpdate(ctx context.Context, infos OriginInfoByURL) bool
 
 	checkHash := func(url string, info OriginInfo) {
 		hash := v.getCommit(ctx, url, info.Branch, info.Protocols)
+		if hash == "" {
+			hash = v.followDefaultBranch(ctx, infos, url)
+		}
 
 		var sendTo chan<- struct{}
 		if hash != "" && hash != info.SHA {
@@ -244,7 +248,15 @@ func (v *InfoStore) needsUpdate(ctx context.Context, infos OriginInfoByURL) bool
 		}
 	}
 
+	// checkHash may update infos when following a renamed default branch
+	v.mux.Lock()
+	origins := make(OriginInfoByURL, len(infos))
 	for url, info := range infos {
+		origins[url] = info
+	}
+	v.mux.Unlock()
+
+	for url, info := range origins {
 		alive++
 
 		go checkHash(url, info)
@@ -321,6 +333,10 @@ func (v *InfoStore) Load() error {
 	return nil
 }
 
+func (v *InfoStore) ResetCache() {
+	v.cache.reset()
+}
+
 func (v *InfoStore) CleanOrphans(pkgs map[string]alpm.IPackage) {
 	missing := make([]string, 0)
 
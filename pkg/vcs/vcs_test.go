@@ -11,6 +11,7 @@ import (
 	"os"
 	"os/exec"
 	"strings"
+	"sync"
 	"testing"
 
 	gosrc "github.com/Morganamilo/go-srcinfo"
@@ -539,3 +540,194 @@ func TestInfoStore_CleanOrphans(t *testing.T) {
 
 	require.NoError(t, os.Remove(filePath))
 }
+
+type countingChecker struct {
+	mux      sync.Mutex
+	calls    map[string]int
+	commits  map[string]string
+	branches map[string]string
+}
+
+func (c *countingChecker) RemoteCommit(ctx context.Context, url, branch string, protocols []string) string {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	c.calls[url+"#"+branch]++
+
+	return c.commits[url]
+}
+
+func (c *countingChecker) DefaultBranch(ctx context.Context, url string, protocols []string) (branch, commit string) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	c.calls[url+"#symref"]++
+
+	return c.branches[url], c.commits[url]
+}
+
+func TestInfoStore_ToUpgradeAll(t *testing.T) {
+	t.Parallel()
+
+	checker := &countingChecker{
+		calls: map[string]int{},
+		commits: map[string]string{
+			"github.com/Jguer/shared.git": "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+			"github.com/Jguer/z.git":      "991c5b4146fd27f4aacf4e3111258a848934aaa1",
+		},
+	}
+
+	shared := OriginInfo{
+		Protocols: []string{"https"},
+		Branch:    "HEAD",
+		SHA:       "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+	}
+
+	v := &InfoStore{
+		logger:  newTestLogger(),
+		Checker: checker,
+		Workers: 2,
+		OriginsByPackage: map[string]OriginInfoByURL{
+			"split-a": {"github.com/Jguer/shared.git": shared},
+			"split-b": {"github.com/Jguer/shared.git": shared},
+			"outdated": {
+				"github.com/Jguer/z.git": OriginInfo{
+					Protocols: []string{"https"},
+					Branch:    "HEAD",
+					SHA:       "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb",
+				},
+			},
+			"unreachable": {
+				"github.com/Jguer/gone.git": OriginInfo{
+					Protocols: []string{"https"},
+					Branch:    "HEAD",
+					SHA:       "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb",
+				},
+			},
+		},
+	}
+
+	got := v.ToUpgradeAll(context.Background(), []string{"split-a", "split-b", "outdated", "unreachable", "unknown"})
+
+	assert.Equal(t, map[string]bool{"outdated": true}, got)
+	assert.Equal(t, map[string]int{
+		"github.com/Jguer/shared.git#HEAD": 1,
+		"github.com/Jguer/z.git#HEAD":      1,
+		"github.com/Jguer/gone.git#HEAD":   1,
+	}, checker.calls)
+}
+
+func TestInfoStore_GetCommitCached(t *testing.T) {
+	t.Parallel()
+
+	checker := &countingChecker{
+		calls:   map[string]int{},
+		commits: map[string]string{"github.com/Jguer/z.git": "991c5b4146fd27f4aacf4e3111258a848934aaa1"},
+	}
+
+	v := &InfoStore{logger: newTestLogger(), Checker: checker}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			assert.Equal(t, "991c5b4146fd27f4aacf4e3111258a848934aaa1",
+				v.getCommit(context.Background(), "github.com/Jguer/z.git", "HEAD", []string{"https"}))
+		}()
+	}
+
+	wg.Wait()
+
+	assert.Empty(t, v.getCommit(context.Background(), "github.com/Jguer/gone.git", "HEAD", []string{"https"}))
+	assert.Empty(t, v.getCommit(context.Background(), "github.com/Jguer/gone.git", "HEAD", []string{"https"}))
+
+	assert.Equal(t, map[string]int{
+		"github.com/Jguer/z.git#HEAD":    1,
+		"github.com/Jguer/gone.git#HEAD": 1,
+	}, checker.calls)
+
+	v.ResetCache()
+
+	assert.Empty(t, v.getCommit(context.Background(), "github.com/Jguer/gone.git", "HEAD", []string{"https"}))
+	assert.Equal(t, 2, checker.calls["github.com/Jguer/gone.git#HEAD"])
+}
+
+func TestParseCommitLog(t *testing.T) {
+	t.Parallel()
+
+	stdout := "cccccccccccccccccccccccccccccccccccccccc ccccccc fix build\n" +
+		"bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb bbbbbbb add feature\n" +
+		"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa aaaaaaa release 1.0\n"
+
+	assert.Equal(t, []string{"ccccccc fix build", "bbbbbbb add feature"},
+		parseCommitLog(stdout, "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"))
+	assert.Equal(t, []string{"ccccccc fix build", "bbbbbbb add feature", "aaaaaaa release 1.0"},
+		parseCommitLog(stdout, "dddddddddddddddddddddddddddddddddddddddd"))
+	assert.Empty(t, parseCommitLog("", "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"))
+}
+
+func TestParseSymref(t *testing.T) {
+	t.Parallel()
+
+	branch, commit := parseSymref("ref: refs/heads/main\tHEAD\n991c5b4146fd27f4aacf4e3111258a848934aaa1\tHEAD\n")
+	assert.Equal(t, "main", branch)
+	assert.Equal(t, "991c5b4146fd27f4aacf4e3111258a848934aaa1", commit)
+
+	branch, commit = parseSymref("")
+	assert.Empty(t, branch)
+	assert.Empty(t, commit)
+}
+
+// renamedChecker only knows the main branch, the default branch.
+type renamedChecker struct {
+	commit string
+}
+
+func (c *renamedChecker) RemoteCommit(ctx context.Context, url, branch string, protocols []string) string {
+	if branch == "main" {
+		return c.commit
+	}
+
+	return ""
+}
+
+func (c *renamedChecker) DefaultBranch(ctx context.Context, url string, protocols []string) (branch, commit string) {
+	return "main", c.commit
+}
+
+func TestInfoStore_FollowRenamedDefaultBranch(t *testing.T) {
+	t.Parallel()
+
+	file, err := os.CreateTemp("/tmp", "yippee-vcs-rename-*-test")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.Remove(file.Name()) })
+
+	v := &InfoStore{
+		logger:   newTestLogger(),
+		Checker:  &renamedChecker{commit: "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"},
+		FilePath: file.Name(),
+		OriginsByPackage: map[string]OriginInfoByURL{
+			"renamed-git": {
+				"github.com/Jguer/renamed.git": OriginInfo{
+					Protocols: []string{"https"},
+					Branch:    "master",
+					SHA:       "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+					Default:   true,
+				},
+			},
+			"pinned-git": {
+				"github.com/Jguer/pinned.git": OriginInfo{
+					Protocols: []string{"https"},
+					Branch:    "master",
+					SHA:       "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb",
+				},
+			},
+		},
+	}
+
+	assert.Empty(t, v.ToUpgradeAll(context.Background(), []string{"renamed-git", "pinned-git"}))
+	assert.Equal(t, "main", v.OriginsByPackage["renamed-git"]["github.com/Jguer/renamed.git"].Branch)
+	assert.Equal(t, "master", v.OriginsByPackage["pinned-git"]["github.com/Jguer/pinned.git"].Branch)
+}
@@ -2,12 +2,16 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"syscall"
+	"time"
 	"unicode"
 
 	aur "github.com/Jguer/aur"
@@ -20,19 +24,24 @@ import (
 	"github.com/Jguer/yippee/v12/pkg/query"
 	"github.com/Jguer/yippee/v12/pkg/runtime"
 	"github.com/Jguer/yippee/v12/pkg/settings"
+	"github.com/Jguer/yippee/v12/pkg/settings/exe"
 	"github.com/Jguer/yippee/v12/pkg/settings/parser"
 	"github.com/Jguer/yippee/v12/pkg/text"
 	"github.com/Jguer/yippee/v12/pkg/upgrade"
 )
 
-// printInfo prints package info like pacman -Si.
-func printInfo(logger *text.Logger, config *settings.Configuration, a *aur.Pkg, extendedInfo bool) {
-	printInfoValue(logger, gotext.Get("Repository"), "aur")
+// printInfo prints package info like pacman -Si, repo being the AUR backend
+// the package came from and aurURL its web interface. Licenses missing from
+// knownLicenses are marked when it is not empty.
+func printInfo(logger *text.Logger, repo, aurURL string, a *aur.Pkg, extendedInfo bool,
+	knownLicenses mapset.Set[string],
+) {
+	printInfoValue(logger, gotext.Get("Repository"), repo)
 	printInfoValue(logger, gotext.Get("Name"), a.Name)
 	printInfoValue(logger, gotext.Get("Version"), a.Version)
 	printInfoValue(logger, gotext.Get("Description"), a.Description)
 	printInfoValue(logger, gotext.Get("URL"), a.URL)
-	printInfoValue(logger, gotext.Get("Licenses"), a.License...)
+	printInfoValue(logger, gotext.Get("Licenses"), annotateLicenses(a.License, knownLicenses)...)
 	printInfoValue(logger, gotext.Get("Groups"), a.Groups...)
 	printInfoValue(logger, gotext.Get("Provides"), a.Provides...)
 	printInfoValue(logger, gotext.Get("Depends On"), a.Depends...)
@@ -41,13 +50,15 @@ func printInfo(logger *text.Logger, config *settings.Configuration, a *aur.Pkg,
 	printInfoValue(logger, gotext.Get("Check Deps"), a.CheckDepends...)
 	printInfoValue(logger, gotext.Get("Conflicts With"), a.Conflicts...)
 	printInfoValue(logger, gotext.Get("Replaces"), a.Replaces...)
-	printInfoValue(logger, gotext.Get("AUR URL"), config.AURURL+"/packages/"+a.Name)
+	printInfoValue(logger, gotext.Get("AUR URL"), aurURL+"/packages/"+a.Name)
 	printInfoValue(logger, gotext.Get("First Submitted"), text.FormatTimeQuery(a.FirstSubmitted))
 	printInfoValue(logger, gotext.Get("Keywords"), a.Keywords...)
 	printInfoValue(logger, gotext.Get("Last Modified"), text.FormatTimeQuery(a.LastModified))
 	printInfoValue(logger, gotext.Get("Maintainer"), a.Maintainer)
-	printInfoValue(logger, gotext.Get("Popularity"), fmt.Sprintf("%f", a.Popularity))
-	printInfoValue(logger, gotext.Get("Votes"), fmt.Sprintf("%d", a.NumVotes))
+	printInfoValue(logger, gotext.Get("Co-Maintainers"), a.CoMaintainers...)
+	printInfoValue(logger, gotext.Get("Submitter"), a.Submitter)
+	printInfoValue(logger, gotext.Get("Popularity"), text.FormatFloat(a.Popularity, 6))
+	printInfoValue(logger, gotext.Get("Votes"), text.FormatNumber(a.NumVotes))
 
 	if a.OutOfDate != 0 {
 		printInfoValue(logger, gotext.Get("Out-of-date"), text.FormatTimeQuery(a.OutOfDate))
@@ -59,12 +70,168 @@ func printInfo(logger *text.Logger, config *settings.Configuration, a *aur.Pkg,
 		printInfoValue(logger, "ID", fmt.Sprintf("%d", a.ID))
 		printInfoValue(logger, gotext.Get("Package Base ID"), fmt.Sprintf("%d", a.PackageBaseID))
 		printInfoValue(logger, gotext.Get("Package Base"), a.PackageBase)
-		printInfoValue(logger, gotext.Get("Snapshot URL"), config.AURURL+a.URLPath)
+		printInfoValue(logger, gotext.Get("Snapshot URL"), aurURL+a.URLPath)
 	}
 
 	logger.Println()
 }
 
+// pkgInfo is the package info printed by -Si --json, alike for repository and
+// AUR packages. The fields only one of them has are grouped in Repo and AUR.
+type pkgInfo struct {
+	Repository  string       `json:"repository"`
+	Name        string       `json:"name"`
+	Base        string       `json:"base"`
+	Version     string       `json:"version"`
+	Description string       `json:"description"`
+	URL         string       `json:"url"`
+	Licenses    []string     `json:"licenses"`
+	Groups      []string     `json:"groups"`
+	Provides    []string     `json:"provides"`
+	Depends     []string     `json:"depends"`
+	OptDepends  []string     `json:"optDepends"`
+	Conflicts   []string     `json:"conflicts"`
+	Replaces    []string     `json:"replaces"`
+	Repo        *repoPkgInfo `json:"repo,omitempty"`
+	AUR         *aurPkgInfo  `json:"aur,omitempty"`
+}
+
+type repoPkgInfo struct {
+	Architecture  string `json:"architecture"`
+	DownloadSize  int64  `json:"downloadSize"`
+	InstalledSize int64  `json:"installedSize"`
+	BuildDate     int64  `json:"buildDate"`
+}
+
+// aurPkgInfo holds the AUR only fields of a pkgInfo, dates are unix times.
+type aurPkgInfo struct {
+	ID             int      `json:"id"`
+	PackageBaseID  int      `json:"packageBaseId"`
+	MakeDepends    []string `json:"makeDepends"`
+	CheckDepends   []string `json:"checkDepends"`
+	Keywords       []string `json:"keywords"`
+	Maintainer     string   `json:"maintainer"`
+	CoMaintainers  []string `json:"coMaintainers"`
+	Submitter      string   `json:"submitter"`
+	Votes          int      `json:"votes"`
+	Popularity     float64  `json:"popularity"`
+	FirstSubmitted int      `json:"firstSubmitted"`
+	LastModified   int      `json:"lastModified"`
+	OutOfDate      int      `json:"outOfDate,omitempty"`
+	AURURL         string   `json:"aurUrl"`
+	SnapshotURL    string   `json:"snapshotUrl"`
+}
+
+func newRepoPkgInfo(dbExecutor db.Executor, pkg db.IPackage) pkgInfo {
+	return pkgInfo{
+		Repository:  pkg.DB().Name(),
+		Name:        pkg.Name(),
+		Base:        pkg.Base(),
+		Version:     pkg.Version(),
+		Description: pkg.Description(),
+		URL:         pkg.URL(),
+		Licenses:    nonNil(dbExecutor.PackageLicenses(pkg)),
+		Groups:      nonNil(dbExecutor.PackageGroups(pkg)),
+		Provides:    dependStrings(dbExecutor.PackageProvides(pkg)),
+		Depends:     dependStrings(dbExecutor.PackageDepends(pkg)),
+		OptDepends:  dependStrings(dbExecutor.PackageOptionalDepends(pkg)),
+		Conflicts:   dependStrings(dbExecutor.PackageConflicts(pkg)),
+		Replaces:    dependStrings(pkg.Replaces().Slice()),
+		Repo: &repoPkgInfo{
+			Architecture:  pkg.Architecture(),
+			DownloadSize:  pkg.Size(),
+			InstalledSize: pkg.ISize(),
+			BuildDate:     pkg.BuildDate().Unix(),
+		},
+	}
+}
+
+func newAURPkgInfo(repo, aurURL string, a *aur.Pkg) pkgInfo {
+	return pkgInfo{
+		Repository:  repo,
+		Name:        a.Name,
+		Base:        a.PackageBase,
+		Version:     a.Version,
+		Description: a.Description,
+		URL:         a.URL,
+		Licenses:    nonNil(a.License),
+		Groups:      nonNil(a.Groups),
+		Provides:    nonNil(a.Provides),
+		Depends:     nonNil(a.Depends),
+		OptDepends:  nonNil(a.OptDepends),
+		Conflicts:   nonNil(a.Conflicts),
+		Replaces:    nonNil(a.Replaces),
+		AUR: &aurPkgInfo{
+			ID:             a.ID,
+			PackageBaseID:  a.PackageBaseID,
+			MakeDepends:    nonNil(a.MakeDepends),
+			CheckDepends:   nonNil(a.CheckDepends),
+			Keywords:       nonNil(a.Keywords),
+			Maintainer:     a.Maintainer,
+			CoMaintainers:  nonNil(a.CoMaintainers),
+			Submitter:      a.Submitter,
+			Votes:          a.NumVotes,
+			Popularity:     a.Popularity,
+			FirstSubmitted: a.FirstSubmitted,
+			LastModified:   a.LastModified,
+			OutOfDate:      a.OutOfDate,
+			AURURL:         aurURL + "/packages/" + a.Name,
+			SnapshotURL:    aurURL + a.URLPath,
+		},
+	}
+}
+
+func dependStrings(depends []db.Depend) []string {
+	strs := make([]string, 0, len(depends))
+	for i := range depends {
+		strs = append(strs, depends[i].String())
+	}
+
+	return strs
+}
+
+// nonNil returns list, or an empty list for a nil one so it is printed as []
+// rather than null.
+func nonNil(list []string) []string {
+	if list == nil {
+		return []string{}
+	}
+
+	return list
+}
+
+// printDependencyGraph prints the dependency graph resolved for the targets,
+// as it would be for -S, in the format of --graph-format.
+func printDependencyGraph(ctx context.Context, run *runtime.Runtime, cmdArgs *parser.Arguments,
+	dbExecutor db.Executor,
+) error {
+	if len(cmdArgs.Targets) == 0 {
+		return errors.New(gotext.Get("no targets specified"))
+	}
+
+	format, _, _ := cmdArgs.GetArg("graph-format")
+	noCheck := !exe.MakepkgRunsCheck(strings.Fields(run.Cfg.MFlags), run.Cfg.MakepkgConf, run.Cfg.MakepkgConfExtra)
+
+	grapher := dep.NewGrapher(dbExecutor, run.AURClient, false, settings.NoConfirm,
+		cmdArgs.ExistsArg("d", "nodeps"), noCheck, cmdArgs.ExistsArg("needed"), run.Logger.Child("grapher"))
+	grapher.SetProviderChoices(run.Providers)
+	grapher.SetProviderPolicy(dep.ProviderPolicy(run.Cfg.ProviderPolicy))
+
+	graph, err := grapher.GraphFromTargets(ctx, nil, cmdArgs.Targets)
+	if err != nil {
+		return err
+	}
+
+	rendered, err := dep.RenderGraph(graph, dep.GraphFormat(format))
+	if err != nil {
+		return err
+	}
+
+	run.Logger.Println(rendered)
+
+	return nil
+}
+
 // BiggestPackages prints the name of the ten biggest packages in the system.
 func biggestPackages(logger *text.Logger, dbExecutor db.Executor) {
 	pkgS := dbExecutor.BiggestPackages()
@@ -78,17 +245,89 @@ func biggestPackages(logger *text.Logger, dbExecutor db.Executor) {
 	}
 }
 
+// printSizesByBase prints the size of every package base directory, biggest
+// first.
+func printSizesByBase(logger *text.Logger, sizes map[string]int64) {
+	bases := make([]string, 0, len(sizes))
+	for base := range sizes {
+		bases = append(bases, base)
+	}
+
+	sort.Slice(bases, func(i, j int) bool {
+		if sizes[bases[i]] != sizes[bases[j]] {
+			return sizes[bases[i]] > sizes[bases[j]]
+		}
+
+		return bases[i] < bases[j]
+	})
+
+	for _, base := range bases {
+		logger.Printf("  %s: %s\n", text.Bold(base), text.Cyan(text.Human(sizes[base])))
+	}
+}
+
+// printSyncDBRefreshes prints how long ago each sync database was refreshed.
+func printSyncDBRefreshes(logger *text.Logger, refreshes []db.SyncDBRefresh, now time.Time) {
+	for _, refresh := range refreshes {
+		age := gotext.Get("never refreshed")
+		if !refresh.Refreshed.IsZero() {
+			age = text.FormatAge(now.Sub(refresh.Refreshed))
+		}
+
+		logger.Printf("  %s: %s\n", text.Bold(refresh.Repo), text.Cyan(age))
+	}
+}
+
+// statsInfo is the JSON form of -P --stats, sizes are in bytes.
+type statsInfo struct {
+	Version          string           `json:"version"`
+	TotalPackages    int              `json:"totalPackages"`
+	ForeignPackages  int              `json:"foreignPackages"`
+	ExplicitPackages int              `json:"explicitPackages"`
+	TotalSize        int64            `json:"totalSize"`
+	PacmanCaches     map[string]int64 `json:"pacmanCaches"`
+	YippeeCacheDir   string           `json:"yippeeCacheDir"`
+	YippeeCache      int64            `json:"yippeeCache"`
+	// YippeeCacheByBase is the size of every directory of the yippee
+	// cache, by package base.
+	YippeeCacheByBase map[string]int64 `json:"yippeeCacheByBase"`
+}
+
 // localStatistics prints installed packages statistics.
-func localStatistics(ctx context.Context, run *runtime.Runtime, dbExecutor db.Executor) error {
+// With check the files of the foreign packages are inspected too.
+func localStatistics(ctx context.Context, run *runtime.Runtime, dbExecutor db.Executor, check bool) error {
 	info := statistics(run, dbExecutor)
 
 	remoteNames := dbExecutor.InstalledRemotePackageNames()
 	remote := dbExecutor.InstalledRemotePackages()
+
+	// dashboards only track the counts and sizes, the AUR is not queried
+	if run.Cfg.JSON {
+		marshalled, err := json.Marshal(statsInfo{
+			Version:           yippeeVersion,
+			TotalPackages:     info.Totaln,
+			ForeignPackages:   len(remoteNames),
+			ExplicitPackages:  info.Expln,
+			TotalSize:         info.TotalSize,
+			PacmanCaches:      info.pacmanCaches,
+			YippeeCacheDir:    run.Cfg.BuildDir,
+			YippeeCache:       info.yippeeCache,
+			YippeeCacheByBase: info.yippeeCacheByBase,
+		})
+		if err != nil {
+			return err
+		}
+
+		run.Logger.Println(string(marshalled))
+
+		return nil
+	}
+
 	run.Logger.Infoln(gotext.Get("Yippee version v%s", yippeeVersion))
 	run.Logger.Println(text.Bold(text.Cyan("===========================================")))
-	run.Logger.Infoln(gotext.Get("Total installed packages: %s", text.Cyan(strconv.Itoa(info.Totaln))))
-	run.Logger.Infoln(gotext.Get("Foreign installed packages: %s", text.Cyan(strconv.Itoa(len(remoteNames)))))
-	run.Logger.Infoln(gotext.Get("Explicitly installed packages: %s", text.Cyan(strconv.Itoa(info.Expln))))
+	run.Logger.Infoln(gotext.Get("Total installed packages: %s", text.Cyan(text.FormatNumber(info.Totaln))))
+	run.Logger.Infoln(gotext.Get("Foreign installed packages: %s", text.Cyan(text.FormatNumber(len(remoteNames)))))
+	run.Logger.Infoln(gotext.Get("Explicitly installed packages: %s", text.Cyan(text.FormatNumber(info.Expln))))
 	run.Logger.Infoln(gotext.Get("Total Size occupied by packages: %s", text.Cyan(text.Human(info.TotalSize))))
 
 	for path, size := range info.pacmanCaches {
@@ -96,6 +335,15 @@ func localStatistics(ctx context.Context, run *runtime.Runtime, dbExecutor db.Ex
 	}
 
 	run.Logger.Infoln(gotext.Get("Size of yippee cache %s: %s", run.Cfg.BuildDir, text.Cyan(text.Human(info.yippeeCache))))
+	printSizesByBase(run.Logger, info.yippeeCacheByBase)
+	run.Logger.Println(text.Bold(text.Cyan("===========================================")))
+	run.Logger.Infoln(gotext.Get("Sync databases:"))
+	printSyncDBRefreshes(run.Logger, db.SyncDBRefreshes(run.PacmanConf.DBPath, dbExecutor.Repos()), time.Now())
+
+	if lastBuild := dbExecutor.LastBuildTime(); !lastBuild.IsZero() {
+		run.Logger.Infoln(gotext.Get("Newest repository package built: %s", text.Cyan(text.FormatDateTime(lastBuild))))
+	}
+
 	run.Logger.Println(text.Bold(text.Cyan("===========================================")))
 	run.Logger.Infoln(gotext.Get("Ten biggest packages:"))
 	biggestPackages(run.Logger, dbExecutor)
@@ -116,6 +364,11 @@ func localStatistics(ctx context.Context, run *runtime.Runtime, dbExecutor db.Ex
 
 	warnings.Print()
 
+	if check {
+		run.Logger.Println(text.Bold(text.Cyan("===========================================")))
+		printPackageHealth(run.Logger, checkForeignPackages(run.PacmanConf.RootDir, dbExecutor))
+	}
+
 	return nil
 }
 
@@ -136,9 +389,17 @@ func printUpdateList(ctx context.Context, run *runtime.Runtime, cmdArgs *parser.
 	grapher := dep.NewGrapher(dbExecutor, run.AURClient, false, true,
 		false, false, cmdArgs.ExistsArg("needed"), logger.Child("grapher"))
 
+	cfg := run.Cfg
+	if cmdArgs.ExistsArg("devel-only") && !cfg.Devel {
+		develCfg := *run.Cfg
+		develCfg.Devel = true
+		cfg = &develCfg
+	}
+
 	upService := upgrade.NewUpgradeService(
 		grapher, run.AURClient, dbExecutor, run.VCSStore,
-		run.Cfg, true, logger.Child("upgrade"))
+		cfg, true, logger.Child("upgrade"))
+	upService.IgnoreGroups = pacmanIgnoreGroups(run)
 
 	graph, errSysUp := upService.GraphUpgrades(ctx, nil,
 		enableDowngrade, filter)
@@ -146,15 +407,16 @@ func printUpdateList(ctx context.Context, run *runtime.Runtime, cmdArgs *parser.
 		return errSysUp
 	}
 
-	if graph.Len() == 0 {
-		return fmt.Errorf("")
+	printFormat, _, hasPrintFormat := cmdArgs.GetArg("print-format")
+	if graph.Len() == 0 && !run.Cfg.JSON && !hasPrintFormat {
+		return settings.ErrNothingToDo{}
 	}
 
 	noTargets := targets.Cardinality() == 0
 	foreignFilter := cmdArgs.ExistsArg("m", "foreign")
 	nativeFilter := cmdArgs.ExistsArg("n", "native")
 
-	noUpdates := true
+	updates := make([]updateListEntry, 0, graph.Len())
 	_ = graph.ForEach(func(pkgName string, ii *dep.InstallInfo) error {
 		if !ii.Upgrade {
 			return nil
@@ -167,20 +429,40 @@ func printUpdateList(ctx context.Context, run *runtime.Runtime, cmdArgs *parser.
 				return nil
 			}
 
-			if quietMode {
-				run.Logger.Printf("%s\n", pkgName)
-			} else {
-				run.Logger.Printf("%s %s -> %s\n", text.Bold(pkgName), text.Bold(text.Green(ii.LocalVersion)),
-					text.Bold(text.Green(ii.Version)))
-			}
-
+			updates = append(updates, newUpdateListEntry(pkgName, ii))
 			targets.Remove(pkgName)
-			noUpdates = false
 		}
 
 		return nil
 	})
 
+	switch {
+	case run.Cfg.JSON:
+		sort.Slice(updates, func(i, j int) bool { return updates[i].Name < updates[j].Name })
+
+		marshalled, err := json.Marshal(updates)
+		if err != nil {
+			return err
+		}
+
+		run.Logger.Println(string(marshalled))
+	case hasPrintFormat:
+		sort.Slice(updates, func(i, j int) bool { return updates[i].Name < updates[j].Name })
+
+		for i := range updates {
+			run.Logger.Println(text.PrintFormat(printFormat, updates[i].printFields()))
+		}
+	default:
+		for i := range updates {
+			if quietMode {
+				run.Logger.Printf("%s\n", updates[i].Name)
+			} else {
+				run.Logger.Printf("%s %s -> %s\n", text.Bold(updates[i].Name),
+					text.Bold(text.Green(updates[i].LocalVersion)), text.Bold(text.Green(updates[i].Version)))
+			}
+		}
+	}
+
 	missing := false
 	targets.Each(func(pkgName string) bool {
 		if dbExecutor.LocalPackage(pkgName) == nil {
@@ -190,13 +472,60 @@ func printUpdateList(ctx context.Context, run *runtime.Runtime, cmdArgs *parser.
 		return false
 	})
 
-	if missing || noUpdates {
+	if missing {
 		return fmt.Errorf("")
 	}
 
+	if len(updates) == 0 {
+		return settings.ErrNothingToDo{}
+	}
+
 	return nil
 }
 
+// updateListEntry is an update listed by -Qu, as printed with --json.
+type updateListEntry struct {
+	Name         string `json:"name"`
+	Base         string `json:"base"`
+	Repository   string `json:"repository"`
+	LocalVersion string `json:"oldVersion"`
+	Version      string `json:"newVersion"`
+	Devel        bool   `json:"devel"`
+}
+
+func newUpdateListEntry(pkgName string, ii *dep.InstallInfo) updateListEntry {
+	entry := updateListEntry{
+		Name:         pkgName,
+		Base:         pkgName,
+		Repository:   "aur",
+		LocalVersion: ii.LocalVersion,
+		Version:      ii.Version,
+		Devel:        ii.Devel,
+	}
+
+	if ii.AURBase != nil {
+		entry.Base = *ii.AURBase
+	}
+
+	if ii.SyncDBName != nil {
+		entry.Repository = *ii.SyncDBName
+	}
+
+	return entry
+}
+
+// printFields are the --print-format fields of -Qu: %n is the name, %e the
+// base, %r the repository, %o the installed version and %v the new one.
+func (e *updateListEntry) printFields() text.PrintFields {
+	return text.PrintFields{
+		'n': e.Name,
+		'e': e.Base,
+		'r': e.Repository,
+		'o': e.LocalVersion,
+		'v': e.Version,
+	}
+}
+
 func printInfoValue(logger *text.Logger, key string, values ...string) {
 	const (
 		keyLength  = 32
@@ -7,12 +7,15 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/Jguer/aur"
 	alpm "github.com/Jguer/go-alpm/v2"
+	"github.com/Morganamilo/go-pacmanconf"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -39,6 +42,7 @@ func TestPrintUpdateList(t *testing.T) {
 
 	mockDBName := mock.NewDB("core")
 	mockDB := &mock.DBExecutor{
+		SyncReplacerFn: func(string) mock.IPackage { return nil },
 		AlpmArchitecturesFn: func() ([]string, error) {
 			return []string{"x86_64"}, nil
 		},
@@ -99,6 +103,7 @@ func TestPrintUpdateList(t *testing.T) {
 	}
 
 	mockDBNoUpdates := &mock.DBExecutor{
+		SyncReplacerFn: func(string) mock.IPackage { return nil },
 		AlpmArchitecturesFn: func() ([]string, error) {
 			return []string{"x86_64"}, nil
 		},
@@ -166,6 +171,9 @@ func TestPrintUpdateList(t *testing.T) {
 		mockData mockData
 		args     []string
 		targets  []string
+		devel    []string
+		json     bool
+		format   string
 		wantPkgs []string
 		wantErr  bool
 	}{
@@ -236,6 +244,65 @@ func TestPrintUpdateList(t *testing.T) {
 			wantPkgs: []string{},
 			wantErr:  true,
 		},
+		{
+			name:     "Quq aur-only",
+			mockData: mockData{mockDB, mockAUR},
+			args:     []string{"Q", "u", "q", "aur-only"},
+			targets:  []string{},
+			wantPkgs: []string{"vosk-api"},
+		},
+		{
+			name:     "Quq devel-only",
+			mockData: mockData{mockDB, mockAURNoUpdates},
+			args:     []string{"Q", "u", "q", "devel-only"},
+			targets:  []string{},
+			devel:    []string{"vosk-api"},
+			wantPkgs: []string{"vosk-api"},
+		},
+		{
+			name:     "Quq aur-only devel upgrade",
+			mockData: mockData{mockDB, mockAURNoUpdates},
+			args:     []string{"Q", "u", "q", "aur-only", "devel-only", "e"},
+			targets:  []string{},
+			devel:    []string{"vosk-api"},
+			wantPkgs: []string{"vosk-api"},
+		},
+		{
+			name:     "Qu devel-only no-updates-devel",
+			mockData: mockData{mockDB, mockAUR},
+			args:     []string{"Q", "u", "devel-only"},
+			targets:  []string{},
+			wantPkgs: []string{},
+			wantErr:  true,
+		},
+		{
+			name:     "Qu json",
+			mockData: mockData{mockDB, mockAUR},
+			args:     []string{"Q", "u"},
+			targets:  []string{},
+			json:     true,
+			wantPkgs: []string{`[` +
+				`{"name":"go","base":"go","repository":"core","oldVersion":"2:1.20.3-1","newVersion":"2:1.20.4-1","devel":false},` +
+				`{"name":"linux","base":"linux","repository":"core","oldVersion":"4.3.0","newVersion":"5.10.0","devel":false},` +
+				`{"name":"vosk-api","base":"vosk-api","repository":"aur","oldVersion":"0.3.43-1","newVersion":"0.3.45-1","devel":false}]`},
+		},
+		{
+			name:     "Qu json no-updates-any",
+			mockData: mockData{mockDBNoUpdates, mockAURNoUpdates},
+			args:     []string{"Q", "u"},
+			targets:  []string{},
+			json:     true,
+			wantPkgs: []string{"[]"},
+			wantErr:  true,
+		},
+		{
+			name:     "Qum print-format",
+			mockData: mockData{mockDB, mockAUR},
+			args:     []string{"Q", "u", "m"},
+			targets:  []string{},
+			format:   "%r/%n %o %v",
+			wantPkgs: []string{"aur/vosk-api 0.3.43-1 0.3.45-1"},
+		},
 		{
 			name:     "Qu no-updates-any",
 			mockData: mockData{mockDBNoUpdates, mockAURNoUpdates},
@@ -279,10 +346,11 @@ func TestPrintUpdateList(t *testing.T) {
 			run := &runtime.Runtime{
 				Cfg: &settings.Configuration{
 					RemoveMake: "no",
+					JSON:       tc.json,
 				},
 				Logger:     logger,
 				CmdBuilder: cmdBuilder,
-				VCSStore:   &vcs.Mock{},
+				VCSStore:   &vcs.Mock{ToUpgradeReturn: tc.devel},
 				AURClient:  tc.mockData.aurCache,
 			}
 
@@ -290,6 +358,10 @@ func TestPrintUpdateList(t *testing.T) {
 			cmdArgs.AddArg(tc.args...)
 			cmdArgs.AddTarget(tc.targets...)
 
+			if tc.format != "" {
+				cmdArgs.CreateOrAppendOption("print-format", tc.format)
+			}
+
 			err = handleCmd(context.Background(), run, cmdArgs, tc.mockData.db)
 
 			w.Close()
@@ -312,3 +384,69 @@ func TestPrintUpdateList(t *testing.T) {
 		})
 	}
 }
+
+func TestLocalStatisticsJSON(t *testing.T) {
+	t.Parallel()
+
+	cacheDir, buildDir := t.TempDir(), t.TempDir()
+	require.NoError(t, os.WriteFile(cacheDir+"/foo-1.0-1-any.pkg.tar.zst", make([]byte, 100), 0o644))
+	require.NoError(t, os.WriteFile(buildDir+"/PKGBUILD", make([]byte, 10), 0o644))
+	require.NoError(t, os.Mkdir(buildDir+"/foo", 0o755))
+	require.NoError(t, os.WriteFile(buildDir+"/foo/PKGBUILD", make([]byte, 20), 0o644))
+
+	dbExecutor := mock.NewExecutor().
+		Sync(mock.NewPackage("foo", "1.0-1").WithDB("core")).
+		Local(
+			mock.NewPackage("foo", "1.0-1").WithSize(0, 1000),
+			mock.NewPackage("bar", "2.0-1").WithSize(0, 500).AsDependency(),
+			mock.NewPackage("yippee", "12.0.0-1").WithSize(0, 2000)).
+		Build()
+
+	var out strings.Builder
+
+	run := &runtime.Runtime{
+		Cfg:        &settings.Configuration{BuildDir: buildDir, JSON: true},
+		Logger:     text.NewLogger(&out, io.Discard, strings.NewReader(""), false, "test"),
+		PacmanConf: &pacmanconf.Config{CacheDir: []string{cacheDir}},
+		AURClient: &mockaur.MockAUR{GetFn: func(ctx context.Context, query *aur.Query) ([]aur.Pkg, error) {
+			return nil, fmt.Errorf("the AUR should not be queried")
+		}},
+	}
+
+	require.NoError(t, localStatistics(context.Background(), run, dbExecutor, false))
+
+	assert.JSONEq(t, fmt.Sprintf(`{
+		"version": %q,
+		"totalPackages": 3,
+		"foreignPackages": 2,
+		"explicitPackages": 2,
+		"totalSize": 3500,
+		"pacmanCaches": {%q: %d},
+		"yippeeCacheDir": %q,
+		"yippeeCache": %d,
+		"yippeeCacheByBase": {"foo": %d}
+	}`, yippeeVersion, cacheDir, walkSize(t, cacheDir), buildDir, walkSize(t, buildDir),
+		walkSize(t, buildDir+"/foo")), out.String())
+}
+
+// walkSize sums the sizes of every entry of the tree at path.
+func walkSize(t *testing.T, path string) (size int64) {
+	t.Helper()
+
+	require.NoError(t, filepath.WalkDir(path, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		size += info.Size()
+
+		return nil
+	}))
+
+	return size
+}
@@ -2,15 +2,16 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
-	"io/fs"
-	"path/filepath"
 
 	aur "github.com/Jguer/aur"
 	alpm "github.com/Jguer/go-alpm/v2"
 	mapset "github.com/deckarep/golang-set/v2"
+	"github.com/leonelquinteros/gotext"
 
 	"github.com/Jguer/yippee/v12/pkg/db"
+	"github.com/Jguer/yippee/v12/pkg/dirsize"
 	"github.com/Jguer/yippee/v12/pkg/query"
 	"github.com/Jguer/yippee/v12/pkg/runtime"
 	"github.com/Jguer/yippee/v12/pkg/settings"
@@ -20,10 +21,15 @@ import (
 
 // SyncSearch presents a query to the local repos and to the AUR.
 func syncSearch(ctx context.Context, pkgS []string,
-	dbExecutor db.Executor, queryBuilder query.Builder, verbose bool,
+	dbExecutor db.Executor, queryBuilder query.Builder, verbose bool, printFormat string,
 ) error {
 	queryBuilder.Execute(ctx, dbExecutor, pkgS)
 
+	if printFormat != "" {
+		queryBuilder.FormatResults(printFormat)
+		return nil
+	}
+
 	searchMode := query.Minimal
 	if verbose {
 		searchMode = query.Detailed
@@ -53,15 +59,66 @@ func syncInfo(ctx context.Context, run *runtime.Runtime,
 			noDB = append(noDB, name)
 		}
 
-		info, err = run.AURClient.Get(ctx, &aur.Query{
-			Needles: noDB,
-			By:      aur.Name,
-		})
+		info, err = query.AURInfo(ctx, run.AURClient, noDB, run.Cfg.RequestSplitN)
 		if err != nil {
 			missing = true
 
 			run.Logger.Errorln(err)
 		}
+
+		if len(info) != mapset.NewThreadUnsafeSet(noDB...).Cardinality() {
+			missing = true
+		}
+	}
+
+	printFormat, _, hasPrintFormat := cmdArgs.GetArg("print-format")
+
+	switch {
+	case run.Cfg.JSON:
+		repoPkgs := syncInfoPackages(run.Logger, dbExecutor, repoS)
+		missing = missing || len(repoPkgs) != len(repoS)
+
+		infos := make([]pkgInfo, 0, len(repoPkgs)+len(info))
+
+		for _, pkg := range repoPkgs {
+			infos = append(infos, newRepoPkgInfo(dbExecutor, pkg))
+		}
+
+		for i := range info {
+			repo, aurURL := query.Origin(run.AURClient, info[i].Name, run.Cfg.AURURL)
+			infos = append(infos, newAURPkgInfo(repo, aurURL, &info[i]))
+		}
+
+		marshalled, errJSON := json.Marshal(infos)
+		if errJSON != nil {
+			return errJSON
+		}
+
+		run.Logger.Println(string(marshalled))
+
+		if missing {
+			return fmt.Errorf("")
+		}
+
+		return err
+	case hasPrintFormat:
+		repoPkgs := syncInfoPackages(run.Logger, dbExecutor, repoS)
+		missing = missing || len(repoPkgs) != len(repoS)
+
+		for _, pkg := range repoPkgs {
+			run.Logger.Println(text.PrintFormat(printFormat, query.SyncPrintFields(pkg)))
+		}
+
+		for i := range info {
+			origin, _ := query.Origin(run.AURClient, info[i].Name, run.Cfg.AURURL)
+			run.Logger.Println(text.PrintFormat(printFormat, query.AURPrintFields(&info[i], origin)))
+		}
+
+		if missing {
+			return fmt.Errorf("")
+		}
+
+		return err
 	}
 
 	if len(repoS) != 0 {
@@ -76,12 +133,11 @@ func syncInfo(ctx context.Context, run *runtime.Runtime,
 		}
 	}
 
-	if len(aurS) != len(info) {
-		missing = true
-	}
+	knownLicenses := readSPDXLicenses(spdxLicenseDir)
 
 	for i := range info {
-		printInfo(run.Logger, run.Cfg, &info[i], cmdArgs.ExistsDouble("i"))
+		repo, aurURL := query.Origin(run.AURClient, info[i].Name, run.Cfg.AURURL)
+		printInfo(run.Logger, repo, aurURL, &info[i], cmdArgs.ExistsDouble("i"), knownLicenses)
 	}
 
 	if missing {
@@ -91,6 +147,30 @@ func syncInfo(ctx context.Context, run *runtime.Runtime,
 	return err
 }
 
+// syncInfoPackages returns the repository packages named by targets, either
+// name or repo/name, reporting the ones not found.
+func syncInfoPackages(logger *text.Logger, dbExecutor db.Executor, targets []string) []db.IPackage {
+	pkgs := make([]db.IPackage, 0, len(targets))
+
+	for _, target := range targets {
+		dbName, name := text.SplitDBFromName(target)
+
+		pkg := dbExecutor.SyncPackage(name)
+		if dbName != "" {
+			pkg = dbExecutor.SyncPackageFromDB(name, dbName)
+		}
+
+		if pkg == nil {
+			logger.Errorln(gotext.Get("package '%s' was not found", target))
+			continue
+		}
+
+		pkgs = append(pkgs, pkg)
+	}
+
+	return pkgs
+}
+
 // PackageSlices separates an input slice into aur and repo slices.
 func packageSlices(toCheck []string, config *settings.Configuration, dbExecutor db.Executor) (aurNames, repoNames []string) {
 	for _, _pkg := range toCheck {
@@ -208,23 +288,14 @@ func hangingPackages(removeOptional bool, dbExecutor db.Executor) (hanging []str
 	return hanging
 }
 
-func getFolderSize(path string) (size int64) {
-	_ = filepath.WalkDir(path, func(p string, entry fs.DirEntry, err error) error {
-		info, _ := entry.Info()
-		size += info.Size()
-		return nil
-	})
-
-	return size
-}
-
 // Statistics returns statistics about packages installed in system.
 func statistics(run *runtime.Runtime, dbExecutor db.Executor) (res struct {
-	Totaln       int
-	Expln        int
-	TotalSize    int64
-	pacmanCaches map[string]int64
-	yippeeCache     int64
+	Totaln            int
+	Expln             int
+	TotalSize         int64
+	pacmanCaches      map[string]int64
+	yippeeCache       int64
+	yippeeCacheByBase map[string]int64
 },
 ) {
 	for _, pkg := range dbExecutor.LocalPackages() {
@@ -236,12 +307,25 @@ func statistics(run *runtime.Runtime, dbExecutor db.Executor) (res struct {
 		}
 	}
 
-	res.pacmanCaches = make(map[string]int64)
-	for _, path := range run.PacmanConf.CacheDir {
-		res.pacmanCaches[path] = getFolderSize(path)
+	sizes := dirsize.NewCache(run.Cfg.DirSizesFilePath)
+	if err := sizes.Load(); err != nil {
+		run.Logger.Debugln(err)
 	}
 
-	res.yippeeCache = getFolderSize(run.Cfg.BuildDir)
+	// the caches are walked while the build directory is broken down
+	done := make(chan struct{})
+
+	go func() {
+		res.pacmanCaches = sizes.Sizes(run.PacmanConf.CacheDir)
+		close(done)
+	}()
+
+	res.yippeeCache, res.yippeeCacheByBase = sizes.Breakdown(run.Cfg.BuildDir)
+	<-done
+
+	if err := sizes.Save(); err != nil {
+		run.Logger.Debugln(err)
+	}
 
 	return
 }
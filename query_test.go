@@ -12,6 +12,7 @@ import (
 	"os/exec"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/Jguer/yippee/v12/pkg/db/mock"
 	mockaur "github.com/Jguer/yippee/v12/pkg/dep/mock"
@@ -20,6 +21,7 @@ import (
 	"github.com/Jguer/yippee/v12/pkg/settings"
 	"github.com/Jguer/yippee/v12/pkg/settings/exe"
 	"github.com/Jguer/yippee/v12/pkg/settings/parser"
+	"github.com/Jguer/yippee/v12/pkg/text"
 
 	"github.com/Jguer/aur"
 	"github.com/stretchr/testify/assert"
@@ -165,6 +167,93 @@ func TestSyncInfo(t *testing.T) {
 	}
 }
 
+func TestSyncInfoJSON(t *testing.T) {
+	t.Parallel()
+
+	dbExc := mock.NewExecutor().Sync(
+		mock.NewPackage("linux", "6.1.1-1").WithDB("core").WithLicenses("GPL-2.0-only").
+			WithDepends("coreutils", "kmod").WithProvides("VIRTUALBOX-GUEST-MODULES").WithArch("x86_64").
+			WithSize(100, 200).WithBuildDate(time.Unix(1700000000, 0)),
+	).Build()
+
+	mockAUR := &mockaur.MockAUR{GetFn: func(ctx context.Context, query *aur.Query) ([]aur.Pkg, error) {
+		return []aur.Pkg{{
+			ID: 1, Name: "yippee", PackageBaseID: 2, PackageBase: "yippee", Version: "12.0.0-1",
+			MakeDepends: []string{"go"}, Maintainer: "jguer", NumVotes: 42, Popularity: 1.5,
+			OutOfDate: 1710000000, URLPath: "/cgit/aur.git/snapshot/yippee.tar.gz",
+		}}, nil
+	}}
+
+	testCases := []struct {
+		name    string
+		targets []string
+		want    []pkgInfo
+		wantErr bool
+	}{
+		{
+			name:    "repo and aur",
+			targets: []string{"core/linux", "yippee"},
+			want: []pkgInfo{
+				{
+					Repository: "core", Name: "linux", Base: "linux", Version: "6.1.1-1",
+					Licenses: []string{"GPL-2.0-only"}, Groups: []string{},
+					Provides: []string{"VIRTUALBOX-GUEST-MODULES"}, Depends: []string{"coreutils", "kmod"},
+					OptDepends: []string{}, Conflicts: []string{}, Replaces: []string{},
+					Repo: &repoPkgInfo{Architecture: "x86_64", DownloadSize: 100, InstalledSize: 200, BuildDate: 1700000000},
+				},
+				{
+					Repository: "aur", Name: "yippee", Base: "yippee", Version: "12.0.0-1",
+					Licenses: []string{}, Groups: []string{}, Provides: []string{}, Depends: []string{},
+					OptDepends: []string{}, Conflicts: []string{}, Replaces: []string{},
+					AUR: &aurPkgInfo{
+						ID: 1, PackageBaseID: 2, MakeDepends: []string{"go"}, CheckDepends: []string{},
+						Keywords: []string{}, Maintainer: "jguer", CoMaintainers: []string{}, Votes: 42,
+						Popularity: 1.5, OutOfDate: 1710000000, AURURL: "https://aur.archlinux.org/packages/yippee",
+						SnapshotURL: "https://aur.archlinux.org/cgit/aur.git/snapshot/yippee.tar.gz",
+					},
+				},
+			},
+		},
+		{
+			name:    "missing repo package",
+			targets: []string{"extra/linux"},
+			want:    []pkgInfo{},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var stdout strings.Builder
+
+			run := &runtime.Runtime{
+				CmdBuilder: &exe.MockBuilder{Runner: &exe.MockRunner{}},
+				AURClient:  mockAUR,
+				Logger:     text.NewLogger(&stdout, io.Discard, strings.NewReader(""), false, "test"),
+				Cfg:        &settings.Configuration{JSON: true, AURURL: "https://aur.archlinux.org"},
+			}
+
+			cmdArgs := parser.MakeArguments()
+			cmdArgs.AddArg("S", "i")
+			cmdArgs.AddTarget(tc.targets...)
+
+			err := handleCmd(context.Background(), run, cmdArgs, dbExc)
+			if tc.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			got := []pkgInfo{}
+			require.NoError(t, json.Unmarshal([]byte(stdout.String()), &got))
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
 // Should not error when there is a DB called aur
 func TestSyncSearchAURDB(t *testing.T) {
 	t.Parallel()
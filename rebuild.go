@@ -0,0 +1,238 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	mapset "github.com/deckarep/golang-set/v2"
+	"github.com/leonelquinteros/gotext"
+
+	"github.com/Jguer/yippee/v12/pkg/db"
+	"github.com/Jguer/yippee/v12/pkg/dep"
+	"github.com/Jguer/yippee/v12/pkg/dep/topo"
+	"github.com/Jguer/yippee/v12/pkg/runtime"
+	"github.com/Jguer/yippee/v12/pkg/settings"
+	"github.com/Jguer/yippee/v12/pkg/settings/parser"
+	"github.com/Jguer/yippee/v12/pkg/text"
+)
+
+// rebuildQueueFile lists the AUR packages queued for a rebuild, one per line.
+const rebuildQueueFile = ".yippee-rebuild"
+
+// libraryBump is a shared library whose soname changed in a repository upgrade.
+type libraryBump struct {
+	pkgName    string // repository package shipping the library
+	soname     string
+	oldVersion string
+	newVersion string // empty when the library was dropped
+}
+
+func (b libraryBump) String() string {
+	newVersion := b.newVersion
+	if newVersion == "" {
+		newVersion = gotext.Get("removed")
+	}
+
+	return b.soname + " " + b.oldVersion + " -> " + newVersion + " (" + b.pkgName + ")"
+}
+
+// sonames returns the versions of the shared libraries provided by pkg.
+func sonames(dbExecutor db.Executor, pkg db.IPackage) map[string]string {
+	libs := make(map[string]string)
+
+	for _, provide := range dbExecutor.PackageProvides(pkg) {
+		if strings.HasSuffix(provide.Name, ".so") {
+			libs[provide.Name] = provide.Version
+		}
+	}
+
+	return libs
+}
+
+// libraryBumps compares the sonames of the installed and upgraded versions of
+// the repository upgrades in graph. It must run before the upgrade.
+func libraryBumps(dbExecutor db.Executor, graph *topo.Graph[string, *dep.InstallInfo]) []libraryBump {
+	if len(dbExecutor.InstalledRemotePackages()) == 0 {
+		return nil
+	}
+
+	bumps := make([]libraryBump, 0)
+
+	_ = graph.ForEach(func(name string, info *dep.InstallInfo) error {
+		if info.Source != dep.Sync || !info.Upgrade {
+			return nil
+		}
+
+		localPkg := dbExecutor.LocalPackage(name)
+		syncPkg := dbExecutor.SyncPackage(name)
+		if localPkg == nil || syncPkg == nil {
+			return nil
+		}
+
+		newLibs := sonames(dbExecutor, syncPkg)
+
+		for soname, oldVersion := range sonames(dbExecutor, localPkg) {
+			if newVersion := newLibs[soname]; newVersion != oldVersion {
+				bumps = append(bumps, libraryBump{
+					pkgName: name, soname: soname,
+					oldVersion: oldVersion, newVersion: newVersion,
+				})
+			}
+		}
+
+		return nil
+	})
+
+	sort.Slice(bumps, func(i, j int) bool { return bumps[i].soname < bumps[j].soname })
+
+	return bumps
+}
+
+// rebuildSuggestions maps the foreign packages depending on a bumped library,
+// by soname or by the name of the package shipping it, to the bumps that
+// affect them. Packages in skip were just built and are left out.
+func rebuildSuggestions(dbExecutor db.Executor, bumps []libraryBump, skip mapset.Set[string]) map[string][]libraryBump {
+	suggestions := make(map[string][]libraryBump)
+	if len(bumps) == 0 {
+		return suggestions
+	}
+
+	bySoname := make(map[string]libraryBump, len(bumps))
+	byPkg := make(map[string][]libraryBump)
+
+	for _, bump := range bumps {
+		bySoname[bump.soname] = bump
+		byPkg[bump.pkgName] = append(byPkg[bump.pkgName], bump)
+	}
+
+	for name, pkg := range dbExecutor.InstalledRemotePackages() {
+		if skip.Contains(name) {
+			continue
+		}
+
+		seen := mapset.NewThreadUnsafeSet[string]()
+
+		for _, depend := range dbExecutor.PackageDepends(pkg) {
+			matched := byPkg[depend.Name]
+			if bump, ok := bySoname[depend.Name]; ok {
+				matched = append(matched, bump)
+			}
+
+			for _, bump := range matched {
+				if seen.Add(bump.soname) {
+					suggestions[name] = append(suggestions[name], bump)
+				}
+			}
+		}
+	}
+
+	return suggestions
+}
+
+// readRebuildQueue returns the packages queued for a rebuild.
+func readRebuildQueue(path string) []string {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	return strings.Fields(string(content))
+}
+
+// writeRebuildQueue replaces the rebuild queue, removing it when empty.
+func writeRebuildQueue(path string, pkgNames []string) error {
+	if len(pkgNames) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+
+		return nil
+	}
+
+	return os.WriteFile(path, []byte(strings.Join(pkgNames, "\n")+"\n"), 0o644)
+}
+
+// offerRebuilds lists the foreign packages linked against libraries changed
+// by a sysupgrade, along with the ones queued earlier, and offers to rebuild
+// them now. Declined rebuilds are queued and offered after the next sysupgrade.
+func offerRebuilds(ctx context.Context, run *runtime.Runtime, cmdArgs *parser.Arguments,
+	dbExecutor db.Executor, bumps []libraryBump, built mapset.Set[string],
+) error {
+	queuePath := filepath.Join(run.Cfg.BuildDir, rebuildQueueFile)
+	queued := readRebuildQueue(queuePath)
+
+	if len(bumps) == 0 && len(queued) == 0 {
+		return nil
+	}
+
+	suggestions := rebuildSuggestions(dbExecutor, bumps, built)
+	remote := dbExecutor.InstalledRemotePackages()
+
+	for _, name := range queued {
+		if _, ok := remote[name]; ok && !built.Contains(name) {
+			if _, ok := suggestions[name]; !ok {
+				suggestions[name] = nil
+			}
+		}
+	}
+
+	pkgNames := make([]string, 0, len(suggestions))
+	for name := range suggestions {
+		pkgNames = append(pkgNames, name)
+	}
+
+	sort.Strings(pkgNames)
+
+	if len(pkgNames) == 0 {
+		return writeRebuildQueue(queuePath, nil)
+	}
+
+	run.Logger.Println()
+	run.Logger.Warnln(gotext.Get("Repository upgrades changed libraries used by these AUR packages:"))
+
+	for _, name := range pkgNames {
+		reasons := make([]string, 0, len(suggestions[name]))
+		for _, bump := range suggestions[name] {
+			reasons = append(reasons, bump.String())
+		}
+
+		if len(reasons) == 0 {
+			reasons = append(reasons, gotext.Get("queued for rebuild"))
+		}
+
+		run.Logger.Println("   ", text.Cyan(name), strings.Join(reasons, ", "))
+	}
+
+	if !run.Logger.ContinueTask(gotext.Get("Rebuild them now?"), false, settings.NoConfirm) {
+		run.Logger.Println(gotext.Get("Rebuilds queued, they will be offered again after the next sysupgrade."))
+
+		return writeRebuildQueue(queuePath, pkgNames)
+	}
+
+	cfg := *run.Cfg
+	cfg.Mode = parser.ModeAUR
+	cfg.ReBuild = parser.RebuildModeYes
+	rebuildRun := *run
+	rebuildRun.Cfg = &cfg
+
+	arguments := cmdArgs.Copy()
+	arguments.DelArg("u", "sysupgrade")
+	arguments.DelArg("y", "refresh")
+	arguments.ClearTargets()
+	arguments.AddTarget(pkgNames...)
+
+	if err := syncInstall(ctx, &rebuildRun, arguments, dbExecutor); err != nil &&
+		!errors.Is(err, settings.ErrNothingToDo{}) {
+		if errQ := writeRebuildQueue(queuePath, pkgNames); errQ != nil {
+			run.Logger.Warnln(errQ)
+		}
+
+		return err
+	}
+
+	return writeRebuildQueue(queuePath, nil)
+}
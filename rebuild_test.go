@@ -0,0 +1,98 @@
+//go:build !integration
+// +build !integration
+
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	mapset "github.com/deckarep/golang-set/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jguer/yippee/v12/pkg/db/mock"
+	"github.com/Jguer/yippee/v12/pkg/dep"
+	"github.com/Jguer/yippee/v12/pkg/dep/topo"
+)
+
+func TestRebuildSuggestions(t *testing.T) {
+	t.Parallel()
+
+	localICU := &mock.Package{PName: "icu", PVersion: "73.2-2"}
+	syncICU := &mock.Package{PName: "icu", PVersion: "74.1-1"}
+	localZstd := &mock.Package{PName: "zstd", PVersion: "1.5.5-1"}
+	syncZstd := &mock.Package{PName: "zstd", PVersion: "1.5.6-1"}
+
+	provides := map[mock.IPackage][]mock.Depend{
+		localICU:  {{Name: "libicuuc.so", Version: "73-64"}, {Name: "libicudata.so", Version: "73-64"}},
+		syncICU:   {{Name: "libicuuc.so", Version: "74-64"}},
+		localZstd: {{Name: "libzstd.so", Version: "1-64"}},
+		syncZstd:  {{Name: "libzstd.so", Version: "1-64"}},
+	}
+
+	remote := map[string]mock.IPackage{
+		"foo-git":   &mock.Package{PName: "foo-git"},
+		"bar":       &mock.Package{PName: "bar"},
+		"baz":       &mock.Package{PName: "baz"},
+		"qux-built": &mock.Package{PName: "qux-built"},
+	}
+
+	depends := map[string][]mock.Depend{
+		"foo-git":   {{Name: "icu"}, {Name: "libicuuc.so"}},
+		"bar":       {{Name: "libicudata.so"}},
+		"baz":       {{Name: "zstd"}},
+		"qux-built": {{Name: "icu"}},
+	}
+
+	dbExe := &mock.DBExecutor{
+		InstalledRemotePackagesFn: func() map[string]mock.IPackage { return remote },
+		LocalPackageFn: func(name string) mock.IPackage {
+			return map[string]mock.IPackage{"icu": localICU, "zstd": localZstd}[name]
+		},
+		SyncPackageFn: func(name string) mock.IPackage {
+			return map[string]mock.IPackage{"icu": syncICU, "zstd": syncZstd}[name]
+		},
+		PackageProvidesFn: func(pkg mock.IPackage) []mock.Depend { return provides[pkg] },
+		PackageDependsFn: func(pkg mock.IPackage) []mock.Depend {
+			return depends[pkg.Name()]
+		},
+	}
+
+	db := "extra"
+	graph := topo.New[string, *dep.InstallInfo]()
+
+	for _, name := range []string{"icu", "zstd"} {
+		graph.AddNode(name)
+		graph.SetNodeInfo(name, &topo.NodeInfo[*dep.InstallInfo]{
+			Value: &dep.InstallInfo{Source: dep.Sync, Upgrade: true, SyncDBName: &db},
+		})
+	}
+
+	bumps := libraryBumps(dbExe, graph)
+	assert.Equal(t, []libraryBump{
+		{pkgName: "icu", soname: "libicudata.so", oldVersion: "73-64"},
+		{pkgName: "icu", soname: "libicuuc.so", oldVersion: "73-64", newVersion: "74-64"},
+	}, bumps)
+
+	suggestions := rebuildSuggestions(dbExe, bumps, mapset.NewThreadUnsafeSet("qux-built"))
+	assert.Equal(t, map[string][]libraryBump{
+		"foo-git": bumps,
+		"bar":     {bumps[0]},
+	}, suggestions)
+	assert.Equal(t, "libicuuc.so 73-64 -> 74-64 (icu)", bumps[1].String())
+}
+
+func TestRebuildQueue(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), rebuildQueueFile)
+	assert.Empty(t, readRebuildQueue(path))
+
+	require.NoError(t, writeRebuildQueue(path, []string{"bar", "foo-git"}))
+	assert.Equal(t, []string{"bar", "foo-git"}, readRebuildQueue(path))
+
+	require.NoError(t, writeRebuildQueue(path, nil))
+	assert.NoFileExists(t, path)
+	require.NoError(t, writeRebuildQueue(path, nil))
+}
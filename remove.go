@@ -0,0 +1,86 @@
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/leonelquinteros/gotext"
+
+	"github.com/Jguer/yippee/v12/pkg/db"
+	"github.com/Jguer/yippee/v12/pkg/text"
+)
+
+// foreignTargets keeps the removal targets that are foreign packages,
+// warning about the others.
+func foreignTargets(logger *text.Logger, dbExecutor db.Executor, targets []string) []string {
+	remote := dbExecutor.InstalledRemotePackages()
+	foreign := make([]string, 0, len(targets))
+
+	for _, target := range targets {
+		if _, ok := remote[target]; !ok {
+			logger.Warnln(gotext.Get("%s is not a foreign package, skipping", text.Cyan(target)))
+			continue
+		}
+
+		foreign = append(foreign, target)
+	}
+
+	return foreign
+}
+
+// foreignDependents returns, for each foreign target, the other foreign
+// packages that depend on it or on something it provides.
+func foreignDependents(dbExecutor db.Executor, targets []string) map[string][]string {
+	remote := dbExecutor.InstalledRemotePackages()
+	removed := make(map[string]bool, len(targets))
+
+	// satisfiedBy maps what the foreign targets provide to the target
+	satisfiedBy := make(map[string]string)
+
+	for _, target := range targets {
+		pkg, ok := remote[target]
+		if !ok {
+			continue
+		}
+
+		removed[target] = true
+		satisfiedBy[target] = target
+
+		for _, provide := range dbExecutor.PackageProvides(pkg) {
+			satisfiedBy[provide.Name] = target
+		}
+	}
+
+	dependents := make(map[string][]string)
+
+	for name, pkg := range remote {
+		if removed[name] {
+			continue
+		}
+
+		for _, depend := range dbExecutor.PackageDepends(pkg) {
+			if target, ok := satisfiedBy[depend.Name]; ok {
+				dependents[target] = append(dependents[target], name)
+			}
+		}
+	}
+
+	for target := range dependents {
+		sort.Strings(dependents[target])
+	}
+
+	return dependents
+}
+
+// warnForeignDependents warns about foreign targets that are still required
+// by other foreign packages.
+func warnForeignDependents(logger *text.Logger, dbExecutor db.Executor, targets []string) {
+	dependents := foreignDependents(dbExecutor, targets)
+
+	for _, target := range targets {
+		if names, ok := dependents[target]; ok {
+			logger.Warnln(gotext.Get("%s is required by foreign packages: %s",
+				text.Cyan(target), strings.Join(names, ", ")))
+		}
+	}
+}
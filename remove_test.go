@@ -0,0 +1,60 @@
+//go:build !integration
+// +build !integration
+
+package main
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Jguer/yippee/v12/pkg/db/mock"
+	"github.com/Jguer/yippee/v12/pkg/text"
+)
+
+func TestForeignRemoval(t *testing.T) {
+	t.Parallel()
+
+	remote := map[string]mock.IPackage{
+		"yippee-bin":  &mock.Package{PName: "yippee-bin"},
+		"libfoo-git":  &mock.Package{PName: "libfoo-git"},
+		"foo-gui":     &mock.Package{PName: "foo-gui"},
+		"foo-plugins": &mock.Package{PName: "foo-plugins"},
+	}
+
+	depends := map[string][]mock.Depend{
+		"foo-gui":     {{Name: "libfoo"}, {Name: "gtk3"}},
+		"foo-plugins": {{Name: "foo-gui"}, {Name: "libfoo"}},
+	}
+
+	dbExecutor := &mock.DBExecutor{
+		InstalledRemotePackagesFn: func() map[string]mock.IPackage { return remote },
+		PackageProvidesFn: func(pkg mock.IPackage) []mock.Depend {
+			if pkg.Name() == "libfoo-git" {
+				return []mock.Depend{{Name: "libfoo"}}
+			}
+
+			return nil
+		},
+		PackageDependsFn: func(pkg mock.IPackage) []mock.Depend { return depends[pkg.Name()] },
+	}
+
+	var stdout strings.Builder
+
+	logger := text.NewLogger(&stdout, io.Discard, strings.NewReader(""), false, "test")
+
+	targets := foreignTargets(logger, dbExecutor, []string{"yippee-bin", "gtk3", "libfoo-git"})
+	assert.Equal(t, []string{"yippee-bin", "libfoo-git"}, targets)
+	assert.Contains(t, stdout.String(), text.Cyan("gtk3")+" is not a foreign package")
+
+	assert.Equal(t, map[string][]string{
+		"libfoo-git": {"foo-gui", "foo-plugins"},
+	}, foreignDependents(dbExecutor, targets))
+
+	assert.Equal(t, map[string][]string{
+		"libfoo-git": {"foo-plugins"},
+		"foo-gui":    {"foo-plugins"},
+	}, foreignDependents(dbExecutor, []string{"libfoo-git", "foo-gui"}))
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"syscall"
+
+	"github.com/Jguer/aur"
+	"github.com/leonelquinteros/gotext"
+
+	"github.com/Jguer/yippee/v12/pkg/db"
+	"github.com/Jguer/yippee/v12/pkg/dep"
+	"github.com/Jguer/yippee/v12/pkg/runtime"
+	"github.com/Jguer/yippee/v12/pkg/settings"
+	"github.com/Jguer/yippee/v12/pkg/settings/parser"
+	"github.com/Jguer/yippee/v12/pkg/sync"
+	"github.com/Jguer/yippee/v12/pkg/text"
+)
+
+// selfUpgradedEnv is set for the yippee started again after upgrading
+// itself, so it goes on with the sysupgrade instead of checking again.
+const selfUpgradedEnv = "YIPPEE_SELF_UPGRADED"
+
+// selfPackages are the AUR packages yippee is installed from.
+var selfPackages = []string{"yippee", "yippee-bin", "yippee-git"}
+
+// reexec replaces the running yippee with the one installed now, started
+// with args.
+var reexec = func(args []string) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	return syscall.Exec(executable, args, append(os.Environ(), selfUpgradedEnv+"=1"))
+}
+
+// selfUpdate returns the installed package yippee comes from and its AUR
+// version when that one is newer.
+func selfUpdate(ctx context.Context, aurClient aur.QueryClient, dbExecutor db.Executor) (name, version string, err error) {
+	remote := dbExecutor.InstalledRemotePackages()
+
+	for _, candidate := range selfPackages {
+		local, ok := remote[candidate]
+		if !ok {
+			continue
+		}
+
+		pkgs, err := aurClient.Get(ctx, &aur.Query{Needles: []string{candidate}, By: aur.Name})
+		if err != nil {
+			return "", "", err
+		}
+
+		for i := range pkgs {
+			if pkgs[i].Name == candidate && db.VerCmp(pkgs[i].Version, local.Version()) > 0 {
+				return candidate, pkgs[i].Version, nil
+			}
+		}
+
+		return "", "", nil
+	}
+
+	return "", "", nil
+}
+
+// selfUpgradeFirst offers to upgrade yippee on its own before a sysupgrade,
+// like pacman's SyncFirst did for pacman, so the AUR packages are built by
+// the new version. Once upgraded yippee is started again to carry on with
+// the sysupgrade, otherwise it goes on in this process.
+func selfUpgradeFirst(ctx context.Context, run *runtime.Runtime,
+	cmdArgs *parser.Arguments, dbExecutor db.Executor, grapher *dep.Grapher,
+) error {
+	if os.Getenv(selfUpgradedEnv) != "" {
+		return nil
+	}
+
+	name, version, err := selfUpdate(ctx, run.AURClient, dbExecutor)
+	if err != nil {
+		run.Logger.Warnln(gotext.Get("unable to check for a yippee update: %s", err))
+		return nil
+	}
+
+	if name == "" {
+		return nil
+	}
+
+	if !run.Logger.ContinueTask(gotext.Get("%s %s is available, upgrade it before the other packages?",
+		text.Cyan(name), text.Bold(version)), true, settings.NoConfirm) {
+		return nil
+	}
+
+	graph, err := grapher.GraphFromTargets(ctx, nil, []string{name})
+	if err != nil {
+		return err
+	}
+
+	targets := graph.TopoSortedLayerMap(nil)
+
+	// the databases were refreshed already, the rest is left to the
+	// sysupgrade after starting again
+	selfArgs := cmdArgs.Copy()
+	selfArgs.DelArg("u", "sysupgrade")
+	selfArgs.DelArg("y", "refresh")
+	selfArgs.ClearTargets()
+	selfArgs.AddTarget(name)
+
+	if err := sync.NewOperationService(ctx, dbExecutor, run).Run(ctx, run, selfArgs, targets, nil); err != nil {
+		return err
+	}
+
+	run.Logger.OperationInfoln(gotext.Get("Starting the upgraded yippee to carry on with the sysupgrade"))
+
+	// the deferred cleanup of main does not run past exec, and the new
+	// process has no reason to refresh the databases again
+	cleanup(run, dbExecutor)
+
+	args := append([]string{os.Args[0]}, parser.WithoutArg(os.Args[1:], "y", "refresh")...)
+	if err := reexec(args); err != nil {
+		return errors.New(gotext.Get("unable to start the upgraded yippee: %s", err))
+	}
+
+	return nil
+}
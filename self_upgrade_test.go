@@ -0,0 +1,90 @@
+//go:build !integration
+// +build !integration
+
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Jguer/aur"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jguer/yippee/v12/pkg/db/mock"
+	mockaur "github.com/Jguer/yippee/v12/pkg/dep/mock"
+)
+
+func TestSelfUpdate(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc        string
+		local       []*mock.Package
+		aurVersion  string
+		aurErr      error
+		wantName    string
+		wantVersion string
+		wantErr     bool
+	}{
+		{
+			desc:        "newer version in the AUR",
+			local:       []*mock.Package{mock.NewPackage("yippee", "12.0.0-1")},
+			aurVersion:  "12.1.0-1",
+			wantName:    "yippee",
+			wantVersion: "12.1.0-1",
+		},
+		{
+			desc:       "up to date",
+			local:      []*mock.Package{mock.NewPackage("yippee-bin", "12.1.0-1")},
+			aurVersion: "12.1.0-1",
+		},
+		{
+			desc:        "installed from yippee-bin",
+			local:       []*mock.Package{mock.NewPackage("yippee-bin", "12.0.0-1")},
+			aurVersion:  "12.1.0-1",
+			wantName:    "yippee-bin",
+			wantVersion: "12.1.0-1",
+		},
+		{
+			desc:       "not installed from the AUR",
+			local:      []*mock.Package{mock.NewPackage("glibc", "2.40-1")},
+			aurVersion: "12.1.0-1",
+		},
+		{
+			desc:    "AUR unavailable",
+			local:   []*mock.Package{mock.NewPackage("yippee", "12.0.0-1")},
+			aurErr:  errors.New("unavailable"),
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			dbExecutor := mock.NewExecutor().Local(tc.local...).Build()
+			aurClient := &mockaur.MockAUR{
+				GetFn: func(ctx context.Context, query *aur.Query) ([]aur.Pkg, error) {
+					if tc.aurErr != nil {
+						return nil, tc.aurErr
+					}
+
+					return []aur.Pkg{{Name: query.Needles[0], PackageBase: query.Needles[0], Version: tc.aurVersion}}, nil
+				},
+			}
+
+			name, version, err := selfUpdate(context.Background(), aurClient, dbExecutor)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantName, name)
+			assert.Equal(t, tc.wantVersion, version)
+		})
+	}
+}
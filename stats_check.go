@@ -0,0 +1,136 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/leonelquinteros/gotext"
+
+	"github.com/Jguer/yippee/v12/pkg/db"
+	"github.com/Jguer/yippee/v12/pkg/text"
+)
+
+// packageHealth lists the problems found with an installed foreign package.
+type packageHealth struct {
+	name       string
+	missing    []string // files of the package absent from the system
+	broken     []string // symlinks of the package pointing nowhere
+	zeroSize   bool
+	requiredBy []string // packages recorded as requiring it that are not installed
+}
+
+func (h *packageHealth) healthy() bool {
+	return len(h.missing) == 0 && len(h.broken) == 0 && !h.zeroSize && len(h.requiredBy) == 0
+}
+
+// checkPackageFiles returns the files of pkg missing below root and the
+// symlinks whose target does not exist.
+func checkPackageFiles(root string, pkg db.IPackage) (missing, broken []string) {
+	for _, file := range pkg.Files() {
+		path := filepath.Join(root, file.Name)
+
+		info, err := os.Lstat(path)
+		if err != nil {
+			missing = append(missing, "/"+strings.TrimSuffix(file.Name, "/"))
+			continue
+		}
+
+		if info.Mode()&os.ModeSymlink != 0 {
+			if _, err := os.Stat(path); err != nil {
+				broken = append(broken, "/"+file.Name)
+			}
+		}
+	}
+
+	return missing, broken
+}
+
+// checkForeignPackages inspects the files and reverse dependencies of the
+// installed foreign packages, returning the unhealthy ones sorted by name.
+func checkForeignPackages(root string, dbExecutor db.Executor) []packageHealth {
+	remote := dbExecutor.InstalledRemotePackages()
+	names := make([]string, 0, len(remote))
+
+	for name := range remote {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	unhealthy := make([]packageHealth, 0)
+
+	for _, name := range names {
+		pkg := remote[name]
+		health := packageHealth{name: name, zeroSize: pkg.ISize() == 0}
+		health.missing, health.broken = checkPackageFiles(root, pkg)
+
+		for _, dependent := range pkg.ComputeRequiredBy() {
+			if dbExecutor.LocalPackage(dependent) == nil {
+				health.requiredBy = append(health.requiredBy, dependent)
+			}
+		}
+
+		if !health.healthy() {
+			unhealthy = append(unhealthy, health)
+		}
+	}
+
+	return unhealthy
+}
+
+// printPackageHealth prints the problems found by checkForeignPackages,
+// followed by a count of the affected packages per problem.
+func printPackageHealth(logger *text.Logger, unhealthy []packageHealth) {
+	if len(unhealthy) == 0 {
+		logger.Infoln(gotext.Get("No problems found with foreign packages."))
+		return
+	}
+
+	var missing, broken, zeroSize, requiredBy int
+
+	for i := range unhealthy {
+		health := &unhealthy[i]
+		logger.Warnln(text.Cyan(health.name))
+
+		for _, file := range health.missing {
+			logger.Println("   ", gotext.Get("missing file:"), file)
+		}
+
+		for _, file := range health.broken {
+			logger.Println("   ", gotext.Get("broken symlink:"), file)
+		}
+
+		if health.zeroSize {
+			logger.Println("   ", gotext.Get("installed size is zero"))
+		}
+
+		if len(health.requiredBy) > 0 {
+			logger.Println("   ", gotext.Get("required by packages that are not installed:"),
+				strings.Join(health.requiredBy, ", "))
+		}
+
+		if len(health.missing) > 0 {
+			missing++
+		}
+
+		if len(health.broken) > 0 {
+			broken++
+		}
+
+		if health.zeroSize {
+			zeroSize++
+		}
+
+		if len(health.requiredBy) > 0 {
+			requiredBy++
+		}
+	}
+
+	logger.Infoln(gotext.Get("Foreign packages with missing files: %s", text.Cyan(text.FormatNumber(missing))))
+	logger.Infoln(gotext.Get("Foreign packages with broken symlinks: %s", text.Cyan(text.FormatNumber(broken))))
+	logger.Infoln(gotext.Get("Foreign packages with a zero installed size: %s", text.Cyan(text.FormatNumber(zeroSize))))
+	logger.Infoln(gotext.Get("Foreign packages with dangling required by entries: %s",
+		text.Cyan(text.FormatNumber(requiredBy))))
+}
@@ -0,0 +1,55 @@
+//go:build !integration
+// +build !integration
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	alpm "github.com/Jguer/go-alpm/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jguer/yippee/v12/pkg/db/mock"
+)
+
+func TestCheckForeignPackages(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "usr/bin"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "usr/bin/healthy"), []byte("#!/bin/sh"), 0o755))
+	require.NoError(t, os.Symlink("/nonexistent", filepath.Join(root, "usr/bin/dangling")))
+
+	healthy := mock.NewPackage("healthy", "1.0-1").WithSize(10, 10)
+	healthy.PFiles = []alpm.File{{Name: "usr/"}, {Name: "usr/bin/"}, {Name: "usr/bin/healthy"}}
+
+	broken := mock.NewPackage("broken", "1.0-1").WithSize(10, 10)
+	broken.PFiles = []alpm.File{{Name: "usr/bin/dangling"}, {Name: "usr/bin/gone"}, {Name: "usr/share/broken/"}}
+
+	empty := mock.NewPackage("empty-git", "r1.abc-1")
+	empty.PRequiredBy = []string{"healthy", "removed"}
+
+	dbExecutor := mock.NewExecutor().
+		Local(healthy, broken, empty).
+		Local(mock.NewPackage("pacman", "6.1.0-1")).
+		Sync(mock.NewPackage("pacman", "6.1.0-1").WithDB("core")).
+		Build()
+
+	unhealthy := checkForeignPackages(root, dbExecutor)
+
+	assert.Equal(t, []packageHealth{
+		{
+			name:    "broken",
+			missing: []string{"/usr/bin/gone", "/usr/share/broken"},
+			broken:  []string{"/usr/bin/dangling"},
+		},
+		{
+			name:       "empty-git",
+			zeroSize:   true,
+			requiredBy: []string{"removed"},
+		},
+	}, unhealthy)
+}
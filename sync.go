@@ -2,9 +2,13 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/Jguer/aur"
+	mapset "github.com/deckarep/golang-set/v2"
 	"github.com/leonelquinteros/gotext"
 
 	"github.com/Jguer/yippee/v12/pkg/db"
@@ -18,6 +22,12 @@ import (
 	"github.com/Jguer/yippee/v12/pkg/upgrade"
 )
 
+const aurRetryAttempts = 3
+
+// aurRetryDelay is the wait before the first AUR retry, it grows with every
+// attempt.
+var aurRetryDelay = time.Minute
+
 func syncInstall(ctx context.Context,
 	run *runtime.Runtime,
 	cmdArgs *parser.Arguments,
@@ -26,12 +36,13 @@ func syncInstall(ctx context.Context,
 	aurCache := run.AURClient
 	refreshArg := cmdArgs.ExistsArg("y", "refresh")
 	noDeps := cmdArgs.ExistsArg("d", "nodeps")
-	noCheck := strings.Contains(run.Cfg.MFlags, "--nocheck")
+	noCheck := !exe.MakepkgRunsCheck(strings.Fields(run.Cfg.MFlags), run.Cfg.MakepkgConf, run.Cfg.MakepkgConfExtra)
 	if noDeps {
 		run.CmdBuilder.AddMakepkgFlag("-d")
 	}
 
-	if refreshArg && run.Cfg.Mode.AtLeastRepo() {
+	// a dry run leaves the databases alone, the refresh shows in the plan
+	if refreshArg && run.Cfg.Mode.AtLeastRepo() && !run.Cfg.DryRun {
 		if errR := earlyRefresh(ctx, run.Cfg, run.CmdBuilder, cmdArgs); errR != nil {
 			return fmt.Errorf("%s - %w", gotext.Get("error refreshing databases"), errR)
 		}
@@ -43,8 +54,31 @@ func syncInstall(ctx context.Context,
 		}
 	}
 
+	sysupgrade := cmdArgs.ExistsArg("u", "sysupgrade")
+	// a download only upgrade installs nothing the news could be about
+	upgrading := sysupgrade && !cmdArgs.ExistsArg("w", "downloadonly") && !run.Cfg.DryRun
+
+	// news published while upgrading is shown on the next upgrade
+	upgradeStart := time.Now()
+
 	grapher := dep.NewGrapher(dbExecutor, aurCache, false, settings.NoConfirm,
 		noDeps, noCheck, cmdArgs.ExistsArg("needed"), run.Logger.Child("grapher"))
+	grapher.SetProviderChoices(run.Providers)
+	grapher.SetProviderPolicy(dep.ProviderPolicy(run.Cfg.ProviderPolicy))
+
+	if upgrading && run.Cfg.SelfUpgradeFirst && run.Cfg.Mode.AtLeastAUR() && run.Cfg.Replay == "" {
+		if errSelf := selfUpgradeFirst(ctx, run, cmdArgs, dbExecutor, grapher); errSelf != nil {
+			return errSelf
+		}
+	}
+
+	if upgrading {
+		if errNews := newsGate(ctx, run, dbExecutor); errNews != nil {
+			return errNews
+		}
+	}
+
+	doneResolution := run.Tracer.Start(gotext.Get("resolution"))
 
 	graph, err := grapher.GraphFromTargets(ctx, nil, cmdArgs.Targets)
 	if err != nil {
@@ -52,12 +86,18 @@ func syncInstall(ctx context.Context,
 	}
 
 	excluded := []string{}
-	if cmdArgs.ExistsArg("u", "sysupgrade") {
+	aurUnavailable := false
+
+	var bumps []libraryBump
+
+	if sysupgrade {
 		var errSysUp error
 
 		upService := upgrade.NewUpgradeService(
 			grapher, aurCache, dbExecutor, run.VCSStore,
 			run.Cfg, settings.NoConfirm, run.Logger.Child("upgrade"))
+		upService.IgnoreGroups = pacmanIgnoreGroups(run)
+		upService.Notes = run.Notes
 
 		graph, errSysUp = upService.GraphUpgrades(ctx,
 			graph, cmdArgs.ExistsDouble("u", "sysupgrade"),
@@ -67,13 +107,24 @@ func syncInstall(ctx context.Context,
 		}
 
 		upService.AURWarnings.Print()
+		doneResolution()
+
+		aurUnavailable = upService.AURUnavailable
 
-		excluded, errSysUp = upService.UserExcludeUpgrades(graph)
+		excluded, errSysUp = upService.UserExcludeUpgrades(ctx, graph)
 		if errSysUp != nil {
 			return errSysUp
 		}
+
+		excluded = append(excluded, upService.IgnoredRepoUpgrades...)
+
+		if run.Cfg.Mode.AtLeastAUR() {
+			bumps = libraryBumps(dbExecutor, graph)
+		}
 	}
 
+	doneResolution()
+
 	opService := sync.NewOperationService(ctx, dbExecutor, run)
 	multiErr := &multierror.MultiError{}
 	targets := graph.TopoSortedLayerMap(func(s string, ii *dep.InstallInfo) error {
@@ -87,7 +138,80 @@ func syncInstall(ctx context.Context,
 		return err
 	}
 
-	return opService.Run(ctx, run, cmdArgs, targets, excluded)
+	err = opService.Run(ctx, run, cmdArgs, targets, excluded)
+
+	if upgrading && newsOnUpgrade(run.Cfg.NewsOnUpgrade) && (err == nil || errors.Is(err, settings.ErrNothingToDo{})) {
+		if errRecord := recordSysupgrade(run.Cfg.SysupgradeFilePath, upgradeStart); errRecord != nil {
+			run.Logger.Warnln(errRecord)
+		}
+	}
+
+	if sysupgrade && run.Cfg.Mode.AtLeastAUR() && !aurUnavailable && !run.Cfg.DryRun &&
+		(err == nil || errors.Is(err, settings.ErrNothingToDo{})) {
+		built := mapset.NewThreadUnsafeSet[string]()
+		_ = graph.ForEach(func(name string, info *dep.InstallInfo) error {
+			if info.Source == dep.AUR {
+				built.Add(name)
+			}
+
+			return nil
+		})
+
+		if errR := offerRebuilds(ctx, run, cmdArgs, dbExecutor, bumps, built); errR != nil {
+			return errR
+		}
+	}
+
+	if !aurUnavailable || run.Cfg.DryRun || (err != nil && !errors.Is(err, settings.ErrNothingToDo{})) {
+		return err
+	}
+
+	return retryAURUpgrade(ctx, run, cmdArgs, dbExecutor)
+}
+
+// retryAURUpgrade performs the AUR part of a sysupgrade that was skipped
+// because the AUR was unavailable, waiting longer between each attempt for
+// it to come back.
+func retryAURUpgrade(ctx context.Context, run *runtime.Runtime,
+	cmdArgs *parser.Arguments, dbExecutor db.Executor,
+) error {
+	// the repository upgrade changed the local database
+	if err := dbExecutor.RefreshHandle(); err != nil {
+		return err
+	}
+
+	cfg := *run.Cfg
+	cfg.Mode = parser.ModeAUR
+	aurRun := *run
+	aurRun.Cfg = &cfg
+
+	arguments := cmdArgs.Copy()
+	arguments.DelArg("y", "refresh")
+	arguments.ClearTargets()
+
+	var err error
+
+	for attempt := 1; attempt <= aurRetryAttempts; attempt++ {
+		delay := time.Duration(attempt) * aurRetryDelay
+		run.Logger.OperationInfoln(gotext.Get("Retrying the AUR upgrade in %s...", delay))
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+
+		err = syncInstall(ctx, &aurRun, arguments, dbExecutor)
+		if errors.Is(err, settings.ErrNothingToDo{}) {
+			return nil
+		}
+
+		if !errors.Is(err, aur.ErrServiceUnavailable) {
+			return err
+		}
+	}
+
+	return fmt.Errorf("%s - %w", gotext.Get("AUR packages were not upgraded"), err)
 }
 
 func earlyRefresh(ctx context.Context, cfg *settings.Configuration, cmdBuilder exe.ICmdBuilder, cmdArgs *parser.Arguments) error {
@@ -103,3 +227,12 @@ func earlyRefresh(ctx context.Context, cfg *settings.Configuration, cmdBuilder e
 	return cmdBuilder.Show(cmdBuilder.BuildPacmanCmd(ctx,
 		arguments, cfg.Mode, settings.NoConfirm))
 }
+
+// pacmanIgnoreGroups returns the groups ignored by pacman.conf and --ignoregroup.
+func pacmanIgnoreGroups(run *runtime.Runtime) []string {
+	if run.PacmanConf == nil {
+		return nil
+	}
+
+	return run.PacmanConf.IgnoreGroup
+}
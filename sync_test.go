@@ -75,6 +75,7 @@ func TestSyncUpgrade(t *testing.T) {
 	dbName := mock.NewDB("core")
 
 	db := &mock.DBExecutor{
+		SyncReplacerFn: func(string) mock.IPackage { return nil },
 		AlpmArchitecturesFn: func() ([]string, error) {
 			return []string{"x86_64"}, nil
 		},
@@ -106,6 +107,8 @@ func TestSyncUpgrade(t *testing.T) {
 			}, nil
 		},
 	}
+	withoutBaseDevel(db)
+	withoutLocalPackages(db)
 
 	run := &runtime.Runtime{
 		Cfg: &settings.Configuration{
@@ -187,6 +190,7 @@ func TestSyncUpgrade_IgnoreAll(t *testing.T) {
 	dbName := mock.NewDB("core")
 
 	db := &mock.DBExecutor{
+		SyncReplacerFn: func(string) mock.IPackage { return nil },
 		AlpmArchitecturesFn: func() ([]string, error) {
 			return []string{"x86_64"}, nil
 		},
@@ -233,7 +237,7 @@ func TestSyncUpgrade_IgnoreAll(t *testing.T) {
 		},
 	}
 	err = handleCmd(context.Background(), run, cmdArgs, db)
-	require.NoError(t, err)
+	require.ErrorIs(t, err, settings.ErrNothingToDo{})
 
 	wantCapture := []string{}
 	wantShow := []string{
@@ -298,6 +302,7 @@ func TestSyncUpgrade_IgnoreOne(t *testing.T) {
 	dbName := mock.NewDB("core")
 
 	db := &mock.DBExecutor{
+		SyncReplacerFn: func(string) mock.IPackage { return nil },
 		AlpmArchitecturesFn: func() ([]string, error) {
 			return []string{"x86_64"}, nil
 		},
@@ -347,6 +352,8 @@ func TestSyncUpgrade_IgnoreOne(t *testing.T) {
 			}, nil
 		},
 	}
+	withoutBaseDevel(db)
+	withoutLocalPackages(db)
 
 	run := &runtime.Runtime{
 		Cfg: &settings.Configuration{
@@ -469,6 +476,7 @@ pkgname = python-vosk
 	cmdArgs.AddArg("u")
 
 	db := &mock.DBExecutor{
+		SyncReplacerFn: func(string) mock.IPackage { return nil },
 		AlpmArchitecturesFn: func() ([]string, error) {
 			return []string{"x86_64"}, nil
 		},
@@ -510,6 +518,8 @@ pkgname = python-vosk
 			return map[string]db.SyncUpgrade{}, nil
 		},
 	}
+	withoutBaseDevel(db)
+	withoutLocalPackages(db)
 
 	run := &runtime.Runtime{
 		Cfg: &settings.Configuration{
@@ -674,6 +684,7 @@ func TestSyncUpgrade_NoCombinedUpgrade(t *testing.T) {
 			cmdArgs.AddArg("u")
 
 			db := &mock.DBExecutor{
+				SyncReplacerFn: func(string) mock.IPackage { return nil },
 				AlpmArchitecturesFn: func() ([]string, error) {
 					return []string{"x86_64"}, nil
 				},
@@ -712,7 +723,7 @@ func TestSyncUpgrade_NoCombinedUpgrade(t *testing.T) {
 			}
 
 			err = handleCmd(context.Background(), run, cmdArgs, db)
-			require.NoError(t, err)
+			require.ErrorIs(t, err, settings.ErrNothingToDo{})
 
 			require.Len(t, mockRunner.ShowCalls, len(tc.want))
 			require.Len(t, mockRunner.CaptureCalls, 0)
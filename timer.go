@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/leonelquinteros/gotext"
+
+	"github.com/Jguer/yippee/v12/pkg/runtime"
+	"github.com/Jguer/yippee/v12/pkg/settings/exe"
+	"github.com/Jguer/yippee/v12/pkg/text"
+)
+
+const (
+	timerUnitName   = "yippee-update"
+	metricsFileName = "updates.prom"
+)
+
+// timerEnv lists the variables locating the user's configuration and cache,
+// passed on to the service so it runs with the same settings.
+var timerEnv = []string{"XDG_CONFIG_HOME", "XDG_CACHE_HOME"}
+
+// systemdQuote quotes arg for a systemd Environment line, escaping
+// backslashes and quotes as well as the specifier character.
+func systemdQuote(arg string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "%", "%%")
+
+	return `"` + replacer.Replace(arg) + `"`
+}
+
+// systemdExecQuote quotes arg for a systemd Exec line, which unlike
+// Environment also expands variables.
+func systemdExecQuote(arg string) string {
+	return systemdQuote(strings.ReplaceAll(arg, "$", "$$"))
+}
+
+// serviceUnit returns a oneshot service running argv with env, a list of
+// KEY=value entries.
+func serviceUnit(argv, env []string) string {
+	var sb strings.Builder
+
+	sb.WriteString("[Unit]\n")
+	sb.WriteString("Description=Check for repository and AUR updates\n")
+	sb.WriteString("Wants=network-online.target\n")
+	sb.WriteString("After=network-online.target\n\n")
+	sb.WriteString("[Service]\n")
+	sb.WriteString("Type=oneshot\n")
+
+	for _, entry := range env {
+		sb.WriteString("Environment=" + systemdQuote(entry) + "\n")
+	}
+
+	quoted := make([]string, 0, len(argv))
+	for _, arg := range argv {
+		quoted = append(quoted, systemdExecQuote(arg))
+	}
+
+	sb.WriteString("ExecStart=" + strings.Join(quoted, " ") + "\n")
+
+	return sb.String()
+}
+
+// timerUnit returns a timer starting the update service daily.
+func timerUnit() string {
+	return "[Unit]\n" +
+		"Description=Check for repository and AUR updates daily\n\n" +
+		"[Timer]\n" +
+		"OnCalendar=daily\n" +
+		"RandomizedDelaySec=1h\n" +
+		"Persistent=true\n\n" +
+		"[Install]\n" +
+		"WantedBy=timers.target\n"
+}
+
+// userUnitDir returns the directory holding the systemd units of the user.
+func userUnitDir() (string, error) {
+	if configHome := os.Getenv("XDG_CONFIG_HOME"); configHome != "" {
+		return filepath.Join(configHome, "systemd", "user"), nil
+	}
+
+	if home := os.Getenv("HOME"); home != "" {
+		return filepath.Join(home, ".config", "systemd", "user"), nil
+	}
+
+	return "", errors.New(gotext.Get("unable to find the systemd user unit directory, set HOME"))
+}
+
+func systemctlUser(ctx context.Context, cmdBuilder exe.ICmdBuilder, args ...string) error {
+	return cmdBuilder.Show(exec.CommandContext(ctx, "systemctl", append([]string{"--user"}, args...)...))
+}
+
+// installTimer writes a user service and timer that export the update
+// status with -P --metrics to the build directory, then enables the timer.
+func installTimer(ctx context.Context, run *runtime.Runtime, cmdBuilder exe.ICmdBuilder) error {
+	if os.Geteuid() == 0 {
+		return errors.New(gotext.Get("the update timer is a user unit, run this without root"))
+	}
+
+	unitDir, err := userUnitDir()
+	if err != nil {
+		return err
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	env := make([]string, 0, len(timerEnv))
+	for _, key := range timerEnv {
+		if value, ok := os.LookupEnv(key); ok {
+			env = append(env, key+"="+value)
+		}
+	}
+
+	metricsPath := filepath.Join(run.Cfg.BuildDir, metricsFileName)
+	argv := []string{executable, "-P", "--metrics", metricsPath}
+
+	if err := os.MkdirAll(unitDir, 0o755); err != nil {
+		return err
+	}
+
+	units := map[string]string{
+		timerUnitName + ".service": serviceUnit(argv, env),
+		timerUnitName + ".timer":   timerUnit(),
+	}
+
+	for name, content := range units {
+		if err := os.WriteFile(filepath.Join(unitDir, name), []byte(content), 0o644); err != nil {
+			return err
+		}
+	}
+
+	if err := systemctlUser(ctx, cmdBuilder, "daemon-reload"); err != nil {
+		return err
+	}
+
+	if err := systemctlUser(ctx, cmdBuilder, "enable", "--now", timerUnitName+".timer"); err != nil {
+		return err
+	}
+
+	run.Logger.OperationInfoln(gotext.Get("Installed %s, update metrics are written to %s",
+		text.Cyan(timerUnitName+".timer"), text.Cyan(metricsPath)))
+
+	return nil
+}
+
+// removeTimer disables and deletes the units written by installTimer.
+func removeTimer(ctx context.Context, run *runtime.Runtime, cmdBuilder exe.ICmdBuilder) error {
+	unitDir, err := userUnitDir()
+	if err != nil {
+		return err
+	}
+
+	if err := systemctlUser(ctx, cmdBuilder, "disable", "--now", timerUnitName+".timer"); err != nil {
+		run.Logger.Debugln("unable to disable timer:", err)
+	}
+
+	for _, name := range []string{timerUnitName + ".timer", timerUnitName + ".service"} {
+		if err := os.Remove(filepath.Join(unitDir, name)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	if err := systemctlUser(ctx, cmdBuilder, "daemon-reload"); err != nil {
+		return err
+	}
+
+	run.Logger.OperationInfoln(gotext.Get("Removed %s", text.Cyan(timerUnitName+".timer")))
+
+	return nil
+}
@@ -0,0 +1,50 @@
+//go:build !integration
+// +build !integration
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSystemdQuote(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		arg      string
+		want     string
+		wantExec string
+	}{
+		{arg: "/usr/bin/yippee", want: `"/usr/bin/yippee"`, wantExec: `"/usr/bin/yippee"`},
+		{arg: "/home/me/my cache", want: `"/home/me/my cache"`, wantExec: `"/home/me/my cache"`},
+		{arg: `say "hi"`, want: `"say \"hi\""`, wantExec: `"say \"hi\""`},
+		{arg: `C:\path`, want: `"C:\\path"`, wantExec: `"C:\\path"`},
+		{arg: "100%", want: `"100%%"`, wantExec: `"100%%"`},
+		{arg: "$HOME", want: `"$HOME"`, wantExec: `"$$HOME"`},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.arg, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.want, systemdQuote(tc.arg))
+			assert.Equal(t, tc.wantExec, systemdExecQuote(tc.arg))
+		})
+	}
+}
+
+func TestServiceUnit(t *testing.T) {
+	t.Parallel()
+
+	unit := serviceUnit(
+		[]string{"/usr/bin/yippee", "-P", "--metrics", "/home/me/.cache/yippee/$1.prom"},
+		[]string{"XDG_CONFIG_HOME=/home/me/my config", "XDG_CACHE_HOME=/home/me/$cache"})
+
+	assert.Contains(t, unit, "Type=oneshot\n")
+	assert.Contains(t, unit, `Environment="XDG_CONFIG_HOME=/home/me/my config"`+"\n")
+	assert.Contains(t, unit, `Environment="XDG_CACHE_HOME=/home/me/$cache"`+"\n")
+	assert.Contains(t, unit,
+		`ExecStart="/usr/bin/yippee" "-P" "--metrics" "/home/me/.cache/yippee/$$1.prom"`+"\n")
+}
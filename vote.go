@@ -5,9 +5,9 @@ import (
 	"errors"
 
 	"github.com/Jguer/aur"
-	"github.com/Jguer/votar/pkg/vote"
 	"github.com/leonelquinteros/gotext"
 
+	"github.com/Jguer/yippee/v12/pkg/aurweb"
 	"github.com/Jguer/yippee/v12/pkg/text"
 )
 
@@ -22,7 +22,7 @@ func (e *ErrAURVote) Error() string {
 
 func handlePackageVote(ctx context.Context,
 	targets []string, aurClient aur.QueryClient, logger *text.Logger,
-	voteClient *vote.Client, upvote bool,
+	webClient *aurweb.Client, upvote bool,
 ) error {
 	infos, err := aurClient.Get(ctx, &aur.Query{
 		Needles: targets,
@@ -40,16 +40,14 @@ func handlePackageVote(ctx context.Context,
 	for i := range infos {
 		var err error
 		if upvote {
-			err = voteClient.Vote(ctx, infos[i].PackageBase)
+			err = webClient.Vote(ctx, infos[i].PackageBase)
 		} else {
-			err = voteClient.Unvote(ctx, infos[i].PackageBase)
+			err = webClient.Unvote(ctx, infos[i].PackageBase)
 		}
 
 		if err != nil {
-			if errors.Is(err, vote.ErrNoCredentials) {
-				return errors.New(
-					gotext.Get("%s: please set AUR_USERNAME and AUR_PASSWORD environment variables for voting",
-						err.Error()))
+			if errors.Is(err, aurweb.ErrNoCredentials) {
+				return errNoAURSession(err, webClient)
 			}
 
 			return &ErrAURVote{inner: err, pkgName: infos[i].Name}
@@ -58,3 +56,10 @@ func handlePackageVote(ctx context.Context,
 
 	return nil
 }
+
+// errNoAURSession tells how to log in when there is no AUR session.
+func errNoAURSession(err error, webClient *aurweb.Client) error {
+	return errors.New(
+		gotext.Get("%s: please set AUR_USERNAME and AUR_PASSWORD to log in, the session is then kept in %s",
+			err.Error(), webClient.SessionPath))
+}